@@ -0,0 +1,95 @@
+// Package ahocorasick finds which of many literals occur in a text in a
+// single pass, to tell which of several regular expressions can match a line
+// before running any of them.
+package ahocorasick
+
+import "fmt"
+
+// Matcher reports the groups of literals found in a text. Literals are
+// matched ignoring ASCII case.
+type Matcher struct {
+	// next is the complete transition table, failure links resolved
+	next [][256]int32
+	// groups holds, for each state, the groups of the literals ending there
+	// or at any of its suffixes
+	groups []uint64
+}
+
+// New builds a matcher for up to 64 groups of literals. Empty literals are
+// ignored.
+func New(groups [][]string) *Matcher {
+	if len(groups) > 64 {
+		panic(fmt.Sprintf("ahocorasick: %d groups, at most 64 are supported", len(groups)))
+	}
+
+	m := &Matcher{next: make([][256]int32, 1), groups: make([]uint64, 1)}
+	for i := range m.next[0] {
+		m.next[0][i] = -1
+	}
+	for group, literals := range groups {
+		for _, literal := range literals {
+			if literal == "" {
+				continue
+			}
+			state := int32(0)
+			for j := 0; j < len(literal); j++ {
+				c := lower(literal[j])
+				if m.next[state][c] == -1 {
+					m.next = append(m.next, [256]int32{})
+					m.groups = append(m.groups, 0)
+					for k := range m.next[len(m.next)-1] {
+						m.next[len(m.next)-1][k] = -1
+					}
+					m.next[state][c] = int32(len(m.next) - 1)
+				}
+				state = m.next[state][c]
+			}
+			m.groups[state] |= 1 << group
+		}
+	}
+
+	// Breadth-first, so the failure state of a state is complete before the
+	// state itself is
+	fail := make([]int32, len(m.next))
+	var queue []int32
+	for c := 0; c < 256; c++ {
+		if s := m.next[0][c]; s == -1 {
+			m.next[0][c] = 0
+		} else {
+			queue = append(queue, s)
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		m.groups[state] |= m.groups[fail[state]]
+		for c := 0; c < 256; c++ {
+			s := m.next[state][c]
+			if s == -1 {
+				m.next[state][c] = m.next[fail[state]][c]
+				continue
+			}
+			fail[s] = m.next[fail[state]][c]
+			queue = append(queue, s)
+		}
+	}
+	return m
+}
+
+// Match returns the groups with a literal in text, group i as bit i.
+func (m *Matcher) Match(text string) uint64 {
+	var found uint64
+	state := int32(0)
+	for i := 0; i < len(text); i++ {
+		state = m.next[state][lower(text[i])]
+		found |= m.groups[state]
+	}
+	return found
+}
+
+func lower(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
@@ -0,0 +1,31 @@
+package ahocorasick
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	m := New([][]string{
+		{"he", "she"},
+		{"his", "hers"},
+		{"TODO"},
+		{""},
+	})
+
+	tests := map[string]uint64{
+		"":                   0,
+		"ushers":             0b011,
+		"this":               0b010,
+		"she":                0b001,
+		"// todo: fix":       0b100,
+		"// ToDo and HERS":   0b111,
+		"nothing to see":     0,
+		"tod o":              0,
+		"xhxhxhxhishe":       0b011,
+		"\xff\x00he\x80":     0b001,
+		"TODOTODOTODO ha hi": 0b100,
+	}
+	for text, want := range tests {
+		if got := m.Match(text); got != want {
+			t.Errorf("Match(%q) = %b, want %b", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,811 @@
+package classgraph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/registry"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
+)
+
+type Config struct {
+	Language       string
+	Include        []string
+	Exclude        []string
+	Recursive      bool
+	Depth          int
+	Jobs           int
+	Verbose        bool
+	OutputFile     string
+	Format         string
+	Focus          string
+	ShowOverrides  bool
+	Render         string
+	RenderMaxNodes int
+	Namespace      string
+	Module         string
+	SkipGenerated  bool
+	SkipVendor     bool
+	MaxFileSize    int64
+	FollowSymlinks bool
+	IncludeHidden  bool
+	NoProgress     bool
+	Manifest       *manifest.Manifest
+}
+
+type Node struct {
+	Name      string      `json:"name"`
+	Kind      string      `json:"kind"`
+	Namespace string      `json:"namespace,omitempty"`
+	File      string      `json:"file,omitempty"`
+	Line      int         `json:"line,omitempty"`
+	Overrides []string    `json:"overrides,omitempty"`
+	Fields    []Attribute `json:"fields,omitempty"`
+	Methods   []Attribute `json:"methods,omitempty"`
+}
+
+// Attribute is a field, enumerator or method listed in a class box.
+type Attribute struct {
+	Name       string `json:"name"`
+	Type       string `json:"type,omitempty"`
+	Parameters string `json:"parameters,omitempty"`
+	Visibility string `json:"visibility,omitempty"`
+}
+
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+var identRegex = regexp.MustCompile(`[A-Za-z_]\w*`)
+
+func Run(config Config) error {
+	logInfo(config.Verbose, "Starting class graph generation")
+
+	parser := registry.GetParser(config.Language)
+	memberParser, ok := parser.(registry.MemberParser)
+	if !ok {
+		return fmt.Errorf("class graph is not supported for language: %s", config.Language)
+	}
+
+	extensions := parser.GetExtensions()
+	opts := utils.ScanOptions{
+		Include:        config.Include,
+		Exclude:        config.Exclude,
+		Recursive:      config.Recursive,
+		Depth:          config.Depth,
+		SkipGenerated:  config.SkipGenerated,
+		SkipVendor:     config.SkipVendor,
+		MaxFileSize:    config.MaxFileSize,
+		FollowSymlinks: config.FollowSymlinks,
+		IncludeHidden:  config.IncludeHidden,
+		Verbose:        config.Verbose,
+	}
+
+	files, err := utils.GetFilesToProcess(opts, func(path string) bool {
+		ext := filepath.Ext(path)
+		for _, validExt := range extensions {
+			if ext == validExt {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		logError(fmt.Sprintf("Failed to collect files: %v", err))
+		return err
+	}
+
+	if len(files) == 0 {
+		logWarning("No files found matching criteria")
+		return nil
+	}
+
+	logInfo(config.Verbose, fmt.Sprintf("Found %d files to analyze", len(files)))
+	config.Manifest.AddFiles(files)
+
+	// PlantUML class boxes list the methods, which come from the function parser
+	withMethods := outputFormat(config) == "plantuml"
+
+	type extracted struct {
+		members   []registry.Member
+		functions []registry.Function
+	}
+
+	reporter := progress.New("Extracting classes", len(files), config.NoProgress)
+	results := worker.Run(files, config.Jobs, reporter, func(ctx context.Context, idx int, filePath string) extracted {
+		fileMembers, err := memberParser.ParseMembers(filePath)
+		if err != nil {
+			failures.Record(filePath, err)
+			return extracted{}
+		}
+
+		var fileFunctions []registry.Function
+		if config.ShowOverrides || withMethods {
+			fileFunctions, err = parser.ParseFile(filePath)
+			if err != nil {
+				failures.Record(filePath, err)
+			}
+		}
+		return extracted{members: fileMembers, functions: fileFunctions}
+	})
+
+	var members []registry.Member
+	var functions []registry.Function
+	for _, result := range results {
+		members = append(members, result.members...)
+		functions = append(functions, result.functions...)
+	}
+
+	graph := BuildGraph(members, functions, config.Language)
+
+	if config.Namespace != "" || config.Module != "" {
+		graph = graph.Scope(func(node Node) bool {
+			return inNamespace(node, config.Namespace) && inModule(node, config.Module)
+		})
+	}
+
+	if config.Focus != "" {
+		if !graph.has(config.Focus) {
+			return fmt.Errorf("class not found: %s", config.Focus)
+		}
+		graph = graph.Focus(config.Focus)
+	}
+
+	if config.Render != "" {
+		if err := renderGraph(graph, config); err != nil {
+			logError(fmt.Sprintf("Failed to render graph: %v", err))
+			return err
+		}
+	}
+
+	// A rendered image replaces the DOT on stdout unless an output file is asked for too
+	if config.Render == "" || config.OutputFile != "" {
+		if err := writeOutput(graph, config); err != nil {
+			logError(fmt.Sprintf("Failed to write output: %v", err))
+			return err
+		}
+	}
+
+	logSuccess(fmt.Sprintf("Class graph generated: %d classes, %d relations", len(graph.Nodes), len(graph.Edges)))
+	return nil
+}
+
+// BuildGraph links types to their bases and to the types their fields hold.
+// Types are keyed by their unqualified name without template arguments, so
+// "ns::Base<T>" in a base clause resolves to the declaration of Base.
+// When functions are given, methods that redefine a method of an ancestor are
+// recorded as overrides on the derived node.
+func BuildGraph(members []registry.Member, functions []registry.Function, language string) *Graph {
+	nodes := make(map[string]*Node)
+	var order []string
+
+	addNode := func(name, kind, file string, line int) *Node {
+		if node, exists := nodes[name]; exists {
+			if node.File == "" && file != "" {
+				node.Kind, node.File, node.Line = kind, file, line
+			}
+			return node
+		}
+		node := &Node{Name: name, Kind: kind, File: file, Line: line}
+		nodes[name] = node
+		order = append(order, name)
+		return node
+	}
+
+	for _, member := range members {
+		if isTypeKind(member.Kind) {
+			node := addNode(typeKey(member.Name), member.Kind, member.File, member.Line)
+			if node.Namespace == "" {
+				node.Namespace = member.Scope
+			}
+		}
+	}
+
+	edgeSeen := make(map[Edge]bool)
+	var edges []Edge
+	addEdge := func(edge Edge) {
+		if edge.From == edge.To || edgeSeen[edge] {
+			return
+		}
+		edgeSeen[edge] = true
+		edges = append(edges, edge)
+	}
+
+	for _, member := range members {
+		switch {
+		case member.Kind == "impl":
+			for _, base := range member.Bases {
+				addNode(typeKey(base), "trait", "", 0)
+				addEdge(Edge{From: typeKey(member.Name), To: typeKey(base), Kind: "implements"})
+			}
+		case isTypeKind(member.Kind):
+			kind := "inherits"
+			if language == "go" {
+				kind = "embeds"
+			}
+			for _, base := range member.Bases {
+				addNode(typeKey(base), "external", "", 0)
+				addEdge(Edge{From: typeKey(member.Name), To: typeKey(base), Kind: kind})
+			}
+		case member.Kind == "enumerator" && member.Scope != "":
+			if node, known := nodes[typeKey(member.Scope)]; known {
+				node.Fields = append(node.Fields, Attribute{Name: member.Name})
+			}
+		case member.Kind == "method" && member.Scope != "":
+			// Go interface methods, their type is the func signature
+			if node, known := nodes[typeKey(member.Scope)]; known {
+				params, results := splitFuncType(member.Type)
+				node.Methods = append(node.Methods, Attribute{Name: member.Name, Type: results, Parameters: params, Visibility: member.Visibility})
+			}
+		case member.Kind == "field" && member.Scope != "":
+			owner := typeKey(member.Scope)
+			node, known := nodes[owner]
+			if !known {
+				continue
+			}
+			node.Fields = append(node.Fields, Attribute{Name: member.Name, Type: member.Type, Visibility: member.Visibility})
+			for _, ident := range identRegex.FindAllString(member.Type, -1) {
+				if _, known := nodes[ident]; known && !isEmbedded(member, ident) {
+					addEdge(Edge{From: owner, To: ident, Kind: "composes"})
+				}
+			}
+		}
+	}
+
+	if len(functions) > 0 {
+		markOverrides(nodes, edges, functions)
+		addMethods(nodes, functions)
+	}
+
+	graph := &Graph{Edges: edges}
+	for _, name := range order {
+		graph.Nodes = append(graph.Nodes, *nodes[name])
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].Name < graph.Nodes[j].Name
+	})
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+
+	return graph
+}
+
+// markOverrides walks the base chain of every type and records each method
+// that an ancestor also declares. C++ methods marked override and Rust trait
+// implementations are recorded even when the base lives outside the scanned files.
+func markOverrides(nodes map[string]*Node, edges []Edge, functions []registry.Function) {
+	methods := make(map[string]map[string]registry.Function)
+	for _, fn := range functions {
+		if fn.Scope == "" {
+			continue
+		}
+		owner := typeKey(fn.Scope)
+		if methods[owner] == nil {
+			methods[owner] = make(map[string]registry.Function)
+		}
+		methods[owner][shortName(fn.Name)] = fn
+	}
+
+	bases := make(map[string][]string)
+	for _, edge := range edges {
+		if edge.Kind != "composes" {
+			bases[edge.From] = append(bases[edge.From], edge.To)
+		}
+	}
+
+	for name, node := range nodes {
+		ancestors := ancestorsOf(name, bases)
+		for method, fn := range methods[name] {
+			overridden := fn.Metadata["override"] == "true" || fn.Metadata["trait"] != ""
+			for _, ancestor := range ancestors {
+				if _, declared := methods[ancestor][method]; declared {
+					overridden = true
+					break
+				}
+			}
+			if overridden {
+				node.Overrides = append(node.Overrides, method)
+			}
+		}
+		sort.Strings(node.Overrides)
+	}
+}
+
+// addMethods lists the methods of each type in declaration order, once even
+// when both a declaration and a definition are found.
+func addMethods(nodes map[string]*Node, functions []registry.Function) {
+	sorted := make([]registry.Function, len(functions))
+	copy(sorted, functions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].File != sorted[j].File {
+			return sorted[i].File < sorted[j].File
+		}
+		return sorted[i].Line < sorted[j].Line
+	})
+
+	seen := make(map[string]bool)
+	for _, fn := range sorted {
+		if fn.Scope == "" {
+			continue
+		}
+		owner := typeKey(fn.Scope)
+		node, known := nodes[owner]
+		if !known {
+			continue
+		}
+
+		params := fn.ParamTypes
+		if len(params) == 0 {
+			params = fn.Parameters
+		}
+		method := Attribute{
+			Name:       shortName(fn.Name),
+			Type:       fn.ReturnType,
+			Parameters: strings.Join(params, ", "),
+			Visibility: fn.Visibility,
+		}
+
+		key := owner + "." + method.Name + "(" + method.Parameters + ")"
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		node.Methods = append(node.Methods, method)
+	}
+}
+
+// splitFuncType reads "func(p []byte) (int, error)" as its parameter and
+// result lists.
+func splitFuncType(funcType string) (string, string) {
+	signature := strings.TrimPrefix(funcType, "func")
+	if !strings.HasPrefix(signature, "(") {
+		return "", ""
+	}
+
+	depth := 0
+	for i, r := range signature {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return signature[1:i], strings.TrimSpace(signature[i+1:])
+			}
+		}
+	}
+	return "", ""
+}
+
+func ancestorsOf(name string, bases map[string][]string) []string {
+	var ancestors []string
+	visited := map[string]bool{name: true}
+	queue := []string{name}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, base := range bases[current] {
+			if !visited[base] {
+				visited[base] = true
+				ancestors = append(ancestors, base)
+				queue = append(queue, base)
+			}
+		}
+	}
+
+	return ancestors
+}
+
+// Focus keeps a class, everything it derives from, everything derived from it
+// and the types it directly composes with.
+func (g *Graph) Focus(name string) *Graph {
+	keep := map[string]bool{name: true}
+
+	up := make(map[string][]string)
+	down := make(map[string][]string)
+	for _, edge := range g.Edges {
+		if edge.Kind == "composes" {
+			if edge.From == name || edge.To == name {
+				keep[edge.From] = true
+				keep[edge.To] = true
+			}
+			continue
+		}
+		up[edge.From] = append(up[edge.From], edge.To)
+		down[edge.To] = append(down[edge.To], edge.From)
+	}
+
+	for _, related := range append(ancestorsOf(name, up), ancestorsOf(name, down)...) {
+		keep[related] = true
+	}
+
+	focused := &Graph{}
+	for _, node := range g.Nodes {
+		if keep[node.Name] {
+			focused.Nodes = append(focused.Nodes, node)
+		}
+	}
+	for _, edge := range g.Edges {
+		if !keep[edge.From] || !keep[edge.To] {
+			continue
+		}
+		if edge.Kind == "composes" && edge.From != name && edge.To != name {
+			continue
+		}
+		focused.Edges = append(focused.Edges, edge)
+	}
+
+	return focused
+}
+
+// Scope keeps the types accepted by keep and the types they derive from or
+// compose, so the arrows leaving the scope still have an end.
+func (g *Graph) Scope(keep func(Node) bool) *Graph {
+	kept := make(map[string]bool)
+	for _, node := range g.Nodes {
+		if keep(node) {
+			kept[node.Name] = true
+		}
+	}
+
+	scoped := &Graph{}
+	related := make(map[string]bool)
+	for _, edge := range g.Edges {
+		if kept[edge.From] {
+			related[edge.To] = true
+			scoped.Edges = append(scoped.Edges, edge)
+		}
+	}
+	for _, node := range g.Nodes {
+		if kept[node.Name] || related[node.Name] {
+			scoped.Nodes = append(scoped.Nodes, node)
+		}
+	}
+
+	return scoped
+}
+
+func inNamespace(node Node, namespace string) bool {
+	if namespace == "" {
+		return true
+	}
+	return node.Namespace == namespace || strings.HasPrefix(node.Namespace, namespace+"::") || strings.HasPrefix(node.Namespace, namespace+".")
+}
+
+// inModule reports whether the node is declared in the module directory or file.
+func inModule(node Node, module string) bool {
+	if module == "" {
+		return true
+	}
+	if node.File == "" {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Clean(module), filepath.Clean(node.File))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (g *Graph) has(name string) bool {
+	for _, node := range g.Nodes {
+		if node.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func outputFormat(config Config) string {
+	if config.Format != "" {
+		return config.Format
+	}
+	switch filepath.Ext(config.OutputFile) {
+	case ".json":
+		return "json"
+	case ".mmd", ".mermaid":
+		return "mermaid"
+	case ".puml", ".plantuml", ".pu":
+		return "plantuml"
+	default:
+		return "dot"
+	}
+}
+
+func writeOutput(graph *Graph, config Config) error {
+	graph = displayPaths(graph)
+	format := outputFormat(config)
+
+	var output []byte
+	var err error
+
+	switch format {
+	case "dot":
+		output = []byte(formatDot(graph))
+	case "mermaid":
+		output = []byte(formatMermaid(graph))
+	case "plantuml":
+		output = []byte(formatPlantUML(graph))
+	case "json":
+		output, err = json.MarshalIndent(graph, "", "  ")
+	default:
+		return fmt.Errorf("unsupported format: %s (expected dot, mermaid, plantuml or json)", format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if config.OutputFile != "" {
+		config.Manifest.AddResult(config.OutputFile, output)
+		return os.WriteFile(config.OutputFile, output, 0644)
+	}
+
+	config.Manifest.AddResult("stdout", output)
+	fmt.Print(string(output))
+	return nil
+}
+
+// displayPaths returns a copy of the graph with the node files in the
+// selected path style.
+func displayPaths(graph *Graph) *Graph {
+	nodes := make([]Node, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		node.File = utils.DisplayPath(node.File)
+		nodes[i] = node
+	}
+	return &Graph{Nodes: nodes, Edges: graph.Edges}
+}
+
+func formatDot(graph *Graph) string {
+	var sb strings.Builder
+
+	sb.WriteString("digraph classes {\n")
+	sb.WriteString("  rankdir=BT;\n")
+	sb.WriteString("  node [shape=record, fontname=\"Helvetica\"];\n\n")
+
+	for _, node := range graph.Nodes {
+		label := node.Name
+		if len(node.Overrides) > 0 {
+			label = fmt.Sprintf("{%s|%s\\l}", node.Name, strings.Join(node.Overrides, "()\\l")+"()")
+		}
+		style := ""
+		if node.File == "" {
+			style = ", style=dashed"
+		}
+		sb.WriteString(fmt.Sprintf("  %q [label=\"%s\"%s];\n", node.Name, label, style))
+	}
+
+	if len(graph.Edges) > 0 {
+		sb.WriteString("\n")
+	}
+
+	for _, edge := range graph.Edges {
+		switch edge.Kind {
+		case "composes":
+			sb.WriteString(fmt.Sprintf("  %q -> %q [arrowhead=none, arrowtail=diamond, dir=both];\n", edge.From, edge.To))
+		case "implements":
+			sb.WriteString(fmt.Sprintf("  %q -> %q [arrowhead=empty, style=dashed];\n", edge.From, edge.To))
+		default:
+			sb.WriteString(fmt.Sprintf("  %q -> %q [arrowhead=empty];\n", edge.From, edge.To))
+		}
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func formatMermaid(graph *Graph) string {
+	var sb strings.Builder
+
+	sb.WriteString("classDiagram\n")
+
+	for _, node := range graph.Nodes {
+		if len(node.Overrides) == 0 {
+			sb.WriteString(fmt.Sprintf("  class %s\n", node.Name))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("  class %s {\n", node.Name))
+		for _, method := range node.Overrides {
+			sb.WriteString(fmt.Sprintf("    +%s()\n", method))
+		}
+		sb.WriteString("  }\n")
+	}
+
+	for _, edge := range graph.Edges {
+		switch edge.Kind {
+		case "composes":
+			sb.WriteString(fmt.Sprintf("  %s *-- %s\n", edge.From, edge.To))
+		case "implements":
+			sb.WriteString(fmt.Sprintf("  %s <|.. %s\n", edge.To, edge.From))
+		default:
+			sb.WriteString(fmt.Sprintf("  %s <|-- %s\n", edge.To, edge.From))
+		}
+	}
+
+	return sb.String()
+}
+
+// formatPlantUML writes a class diagram with the fields and methods of each
+// type, grouping types by namespace into packages.
+func formatPlantUML(graph *Graph) string {
+	var sb strings.Builder
+
+	sb.WriteString("@startuml\n")
+	sb.WriteString("hide empty members\n")
+
+	var namespaces []string
+	byNamespace := make(map[string][]Node)
+	for _, node := range graph.Nodes {
+		if _, exists := byNamespace[node.Namespace]; !exists {
+			namespaces = append(namespaces, node.Namespace)
+		}
+		byNamespace[node.Namespace] = append(byNamespace[node.Namespace], node)
+	}
+	sort.Strings(namespaces)
+
+	for _, namespace := range namespaces {
+		indent := ""
+		sb.WriteString("\n")
+		if namespace != "" {
+			sb.WriteString(fmt.Sprintf("package \"%s\" {\n", namespace))
+			indent = "  "
+		}
+		for _, node := range byNamespace[namespace] {
+			writePlantUMLNode(&sb, node, indent)
+		}
+		if namespace != "" {
+			sb.WriteString("}\n")
+		}
+	}
+
+	if len(graph.Edges) > 0 {
+		sb.WriteString("\n")
+	}
+
+	for _, edge := range graph.Edges {
+		switch edge.Kind {
+		case "composes":
+			sb.WriteString(fmt.Sprintf("%s *-- %s\n", edge.From, edge.To))
+		case "implements":
+			sb.WriteString(fmt.Sprintf("%s <|.. %s\n", edge.To, edge.From))
+		default:
+			sb.WriteString(fmt.Sprintf("%s <|-- %s\n", edge.To, edge.From))
+		}
+	}
+
+	sb.WriteString("@enduml\n")
+	return sb.String()
+}
+
+func writePlantUMLNode(sb *strings.Builder, node Node, indent string) {
+	keyword, stereotype := "class", ""
+	switch node.Kind {
+	case "interface", "trait":
+		keyword = "interface"
+	case "enum":
+		keyword = "enum"
+	case "struct", "union":
+		stereotype = node.Kind
+	}
+	if node.File == "" {
+		stereotype = "external"
+	}
+
+	sb.WriteString(fmt.Sprintf("%s%s %s", indent, keyword, node.Name))
+	if stereotype != "" {
+		sb.WriteString(fmt.Sprintf(" <<%s>>", stereotype))
+	}
+	if len(node.Fields) == 0 && len(node.Methods) == 0 {
+		sb.WriteString("\n")
+		return
+	}
+
+	sb.WriteString(" {\n")
+	for _, field := range node.Fields {
+		line := umlVisibility(field.Visibility) + field.Name
+		if field.Type != "" {
+			line += " : " + field.Type
+		}
+		sb.WriteString(fmt.Sprintf("%s  %s\n", indent, line))
+	}
+	for _, method := range node.Methods {
+		line := fmt.Sprintf("%s%s(%s)", umlVisibility(method.Visibility), method.Name, method.Parameters)
+		if method.Type != "" {
+			line += " : " + method.Type
+		}
+		sb.WriteString(fmt.Sprintf("%s  %s\n", indent, line))
+	}
+	sb.WriteString(indent + "}\n")
+}
+
+func umlVisibility(visibility string) string {
+	switch visibility {
+	case "public":
+		return "+"
+	case "private":
+		return "-"
+	case "protected":
+		return "#"
+	}
+	return ""
+}
+
+func isTypeKind(kind string) bool {
+	switch kind {
+	case "class", "struct", "union", "interface", "trait", "enum", "type":
+		return true
+	}
+	return false
+}
+
+// isEmbedded reports whether a field is the embedded base itself, which is
+// already drawn as an inheritance edge.
+func isEmbedded(member registry.Member, ident string) bool {
+	return member.Name == ident && strings.TrimPrefix(member.Type, "*") == ident
+}
+
+// typeKey strips qualifiers, template arguments and pointer markers so a type
+// mentioned in a base clause matches the name it was declared under.
+func typeKey(name string) string {
+	name = strings.TrimSpace(strings.TrimLeft(name, "*&"))
+	if idx := strings.IndexAny(name, "<["); idx != -1 {
+		name = name[:idx]
+	}
+	return shortName(name)
+}
+
+func shortName(name string) string {
+	if idx := strings.LastIndex(name, "::"); idx != -1 {
+		name = name[idx+2:]
+	}
+	if idx := strings.LastIndex(name, "."); idx != -1 {
+		name = name[idx+1:]
+	}
+	return name
+}
+
+func logInfo(verbose bool, msg string) {
+	if verbose {
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
+	}
+}
+
+func logSuccess(msg string) {
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
+}
+
+func logWarning(msg string) {
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
+}
+
+func logError(msg string) {
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
@@ -0,0 +1,145 @@
+package classgraph
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vitruves/gop/internal/registry"
+)
+
+func TestBuildGraphCpp(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "shapes.hpp")
+	content := `
+class Shape {
+public:
+    virtual double area() const;
+};
+
+class Circle
+    : public Shape {
+public:
+    double area() const override;
+private:
+    Point center;
+};
+
+struct Point { int x; };
+
+class Unrelated {};
+`
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	parser := &registry.CppParser{}
+	members, err := parser.ParseMembers(testFile)
+	if err != nil {
+		t.Fatalf("Failed to parse members: %v", err)
+	}
+	functions, err := parser.ParseFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	graph := BuildGraph(members, functions, "cpp")
+
+	if !hasEdge(graph, Edge{From: "Circle", To: "Shape", Kind: "inherits"}) {
+		t.Errorf("Expected Circle to inherit from Shape, got %+v", graph.Edges)
+	}
+	if !hasEdge(graph, Edge{From: "Circle", To: "Point", Kind: "composes"}) {
+		t.Errorf("Expected Circle to compose Point, got %+v", graph.Edges)
+	}
+
+	for _, node := range graph.Nodes {
+		if node.Name == "Circle" && (len(node.Overrides) != 1 || node.Overrides[0] != "area") {
+			t.Errorf("Expected Circle to override area, got %v", node.Overrides)
+		}
+	}
+
+	focused := graph.Focus("Shape")
+	if focused.has("Unrelated") {
+		t.Error("Focus should drop classes unrelated to the focused one")
+	}
+	if !focused.has("Circle") {
+		t.Error("Focus should keep derived classes")
+	}
+}
+
+func hasEdge(graph *Graph, want Edge) bool {
+	for _, edge := range graph.Edges {
+		if edge == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFormatPlantUML(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "shapes.hpp")
+	content := `
+namespace geo {
+class Shape {
+public:
+    virtual double area() const;
+protected:
+    int id;
+};
+
+class Circle : public Shape {
+public:
+    double area() const override;
+private:
+    double radius;
+};
+
+enum class Kind { CIRCLE, SQUARE };
+}
+
+class Widget : public geo::Shape {};
+`
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	parser := &registry.CppParser{}
+	members, err := parser.ParseMembers(testFile)
+	if err != nil {
+		t.Fatalf("Failed to parse members: %v", err)
+	}
+	functions, err := parser.ParseFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	graph := BuildGraph(members, functions, "cpp")
+	output := formatPlantUML(graph)
+
+	for _, want := range []string{
+		"package \"geo\" {\n  class Circle {\n    -radius : double\n    +area() : double\n  }\n",
+		"  enum Kind {\n    CIRCLE\n    SQUARE\n  }\n",
+		"    #id : int\n",
+		"\nclass Widget\n",
+		"Shape <|-- Circle\n",
+		"Shape <|-- Widget\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected PlantUML output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	scoped := graph.Scope(func(node Node) bool { return inNamespace(node, "geo") })
+	if scoped.has("Widget") || !scoped.has("Kind") {
+		t.Errorf("Unexpected classes in namespace geo: %+v", scoped.Nodes)
+	}
+
+	outside := graph.Scope(func(node Node) bool { return !inNamespace(node, "geo") })
+	if !outside.has("Widget") || !outside.has("Shape") || outside.has("Circle") {
+		t.Errorf("Scope should keep the bases of kept classes, got %+v", outside.Nodes)
+	}
+}
@@ -0,0 +1,57 @@
+package classgraph
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var renderFormats = map[string]string{
+	".svg":  "svg",
+	".png":  "png",
+	".pdf":  "pdf",
+	".jpg":  "jpg",
+	".jpeg": "jpg",
+}
+
+// renderGraph lays the graph out with a local Graphviz installation. When
+// dot is missing or the graph is too large to produce a readable image, the
+// DOT source is written next to the requested image instead.
+func renderGraph(graph *Graph, config Config) error {
+	format, ok := renderFormats[strings.ToLower(filepath.Ext(config.Render))]
+	if !ok {
+		return fmt.Errorf("unsupported render format: %s (expected .svg, .png, .pdf or .jpg)", config.Render)
+	}
+
+	source := []byte(formatDot(graph))
+	fallback := strings.TrimSuffix(config.Render, filepath.Ext(config.Render)) + ".dot"
+
+	if config.RenderMaxNodes > 0 && len(graph.Nodes) > config.RenderMaxNodes {
+		logWarning(fmt.Sprintf("Graph has %d classes, more than --render-max-nodes %d; use --focus to narrow it. Wrote %s instead",
+			len(graph.Nodes), config.RenderMaxNodes, fallback))
+		return os.WriteFile(fallback, source, 0644)
+	}
+
+	dot, err := exec.LookPath("dot")
+	if err != nil {
+		logWarning(fmt.Sprintf("Graphviz dot not found in PATH, install Graphviz to render images. Wrote %s instead", fallback))
+		return os.WriteFile(fallback, source, 0644)
+	}
+
+	var stderr bytes.Buffer
+	render := exec.Command(dot, "-T"+format, "-o", config.Render)
+	render.Stdin = bytes.NewReader(source)
+	render.Stderr = &stderr
+	if err := render.Run(); err != nil {
+		return fmt.Errorf("dot: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	if data, err := os.ReadFile(config.Render); err == nil {
+		config.Manifest.AddResult(config.Render, data)
+	}
+	logInfo(config.Verbose, fmt.Sprintf("Rendered %s with %s", config.Render, dot))
+	return nil
+}
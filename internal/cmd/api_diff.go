@@ -0,0 +1,471 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/registry"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
+)
+
+// APIElement is a public function or type as it appears in one version.
+type APIElement struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Language  string `json:"language"`
+	File      string `json:"file"`
+	Signature string `json:"signature"`
+}
+
+type APIChange struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Language string `json:"language"`
+	File     string `json:"file"`
+	Before   string `json:"before,omitempty"`
+	After    string `json:"after,omitempty"`
+}
+
+type APIDiff struct {
+	Old     string      `json:"old"`
+	New     string      `json:"new"`
+	Removed []APIChange `json:"removed"`
+	Changed []APIChange `json:"changed"`
+	Added   []APIChange `json:"added"`
+}
+
+var (
+	apiDiffOld        string
+	apiDiffNew        string
+	apiDiffOutputFile string
+	apiDiffFormat     string
+)
+
+var apiDiffCmd = &cobra.Command{
+	Use:   "api-diff",
+	Short: "Report public API changes between two versions",
+	Long: `Build the registry of public functions and types for two versions of the codebase, given as
+directories or git refs, and report what was removed, changed and added. Removals and signature
+changes are the breaking part of a release.`,
+	RunE: runAPIDiff,
+}
+
+func init() {
+	apiDiffCmd.Flags().StringVar(&apiDiffOld, "old", "", "Directory or git ref of the previous version")
+	apiDiffCmd.Flags().StringVar(&apiDiffNew, "new", ".", "Directory or git ref of the new version")
+	apiDiffCmd.Flags().StringVarP(&apiDiffOutputFile, "output", "o", "", "Output file (.md or .json)")
+	apiDiffCmd.Flags().StringVarP(&apiDiffFormat, "format", "f", "", "Output format (markdown, json), defaults to the output file extension")
+	apiDiffCmd.MarkFlagRequired("old")
+}
+
+func runAPIDiff(cmd *cobra.Command, args []string) error {
+	oldRoot, cleanupOld, err := resolveTree(apiDiffOld)
+	if err != nil {
+		return err
+	}
+	defer cleanupOld()
+
+	newRoot, cleanupNew, err := resolveTree(apiDiffNew)
+	if err != nil {
+		return err
+	}
+	defer cleanupNew()
+
+	oldAPI, err := collectAPI(oldRoot, "Analyzing old version")
+	if err != nil {
+		logError(fmt.Sprintf("Failed to analyze %s: %v", apiDiffOld, err))
+		return err
+	}
+
+	newAPI, err := collectAPI(newRoot, "Analyzing new version")
+	if err != nil {
+		logError(fmt.Sprintf("Failed to analyze %s: %v", apiDiffNew, err))
+		return err
+	}
+
+	diff := diffAPI(oldAPI, newAPI)
+	diff.Old = apiDiffOld
+	diff.New = apiDiffNew
+
+	if err := writeAPIDiff(diff); err != nil {
+		logError(fmt.Sprintf("Failed to write output: %v", err))
+		return err
+	}
+
+	logSuccess(fmt.Sprintf("API diff completed: %d removed, %d changed, %d added", len(diff.Removed), len(diff.Changed), len(diff.Added)))
+	return nil
+}
+
+// collectAPI returns the public elements of a tree grouped by qualified
+// name, so that overloads are compared together and moves between files are
+// not reported.
+func collectAPI(root, description string) (map[string][]APIElement, error) {
+	opts := scanOptions()
+	opts.Root = root
+
+	files, err := utils.GetFilesToProcess(opts, func(path string) bool {
+		return statsParser(detectLanguage(path)) != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if language != "" {
+		parser := registry.GetParser(language)
+		if parser == nil {
+			return nil, fmt.Errorf("unsupported language: %s", language)
+		}
+		files = filterExtensions(files, parser.GetExtensions())
+	}
+
+	api := make(map[string][]APIElement)
+
+	worker.Each(files, jobs, progress.New(description, len(files), noProgress), func(ctx context.Context, idx int, filePath string) []APIElement {
+		relPath, _ := filepath.Rel(root, filePath)
+		relPath = filepath.ToSlash(relPath)
+
+		parser := statsParser(detectLanguage(filePath))
+		elements, err := publicElements(parser, filePath, relPath)
+		if err != nil {
+			failures.Record(filePath, err)
+			return nil
+		}
+		return elements
+	}, func(idx int, elements []APIElement) {
+		for _, element := range elements {
+			key := element.Language + "|" + element.Kind + "|" + element.Name
+			api[key] = append(api[key], element)
+		}
+	})
+
+	return api, nil
+}
+
+func publicElements(parser registry.LanguageParser, filePath, relPath string) ([]APIElement, error) {
+	functions, err := parser.ParseFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var elements []APIElement
+	for _, fn := range functions {
+		if fn.Visibility != "public" || fn.IsTest || fn.IsMain {
+			continue
+		}
+		elements = append(elements, APIElement{
+			Kind:      "function",
+			Name:      qualifiedName(fn.Scope, fn.Name),
+			Language:  fn.Language,
+			File:      relPath,
+			Signature: apiSignature(fn),
+		})
+	}
+
+	memberParser, ok := parser.(registry.MemberParser)
+	if !ok {
+		return elements, nil
+	}
+
+	members, err := memberParser.ParseMembers(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	language := detectLanguage(filePath)
+	for _, member := range members {
+		if member.Visibility != "public" || member.Kind == "namespace" || member.Kind == "impl" || member.Kind == "extern" {
+			continue
+		}
+
+		name := qualifiedName(member.Scope, member.Name)
+		signature := member.Kind + " " + name
+		switch {
+		case member.Kind == "field":
+			signature = strings.TrimSpace(member.Type + " " + name)
+		case len(member.Bases) > 0:
+			signature += " : " + strings.Join(member.Bases, ", ")
+		}
+
+		kind := "type"
+		if member.Kind == "field" || member.Kind == "enumerator" {
+			kind = member.Kind
+		}
+		elements = append(elements, APIElement{
+			Kind:      kind,
+			Name:      name,
+			Language:  language,
+			File:      relPath,
+			Signature: signature,
+		})
+	}
+
+	return elements, nil
+}
+
+func qualifiedName(scope, name string) string {
+	if scope == "" || strings.HasPrefix(name, scope) {
+		return name
+	}
+	return scope + "::" + name
+}
+
+// apiSignature is what a public function is compared on: its declaration up
+// to the end of the parameter list, with the qualifiers or return type that
+// follow, but no body. Whitespace is normalized so that reformatting is not
+// an API change, and C and C++ parameters are reduced to their types so
+// that renaming one is not either.
+func apiSignature(fn registry.Function) string {
+	signature := strings.Join(strings.Fields(fn.Signature), " ")
+
+	open := parameterListStart(signature, fn.Name[strings.LastIndexAny(fn.Name, ":.")+1:])
+	closing := matchingParen(signature, open)
+	if closing == -1 {
+		return strings.TrimRight(signature, "{;: ")
+	}
+
+	params := signature[open+1 : closing]
+	if fn.Language == "c" || fn.Language == "cpp" {
+		params = strings.Join(registry.ParameterTypes(params), ", ")
+	}
+
+	// Qualifiers and return types end at the body, or at the colon of a
+	// Python body or of a constructor initializer list
+	rest := signature[closing+1:]
+	if idx := strings.IndexAny(rest, "{;"); idx != -1 {
+		rest = rest[:idx]
+	}
+	if idx := singleColon(rest); idx != -1 {
+		rest = rest[:idx]
+	}
+
+	return strings.TrimSpace(strings.TrimSpace(signature[:open]) + "(" + params + ") " + strings.TrimSpace(rest))
+}
+
+// parameterListStart returns the index of the parenthesis following the
+// function name in its declaration, or -1.
+func parameterListStart(signature, name string) int {
+	for from := 0; name != ""; {
+		idx := strings.Index(signature[from:], name)
+		if idx == -1 {
+			break
+		}
+		start, end := from+idx, from+idx+len(name)
+		rest := strings.TrimLeft(signature[end:], " ")
+		if (start == 0 || !isIdentChar(signature[start-1])) && strings.HasPrefix(rest, "(") {
+			return len(signature) - len(rest)
+		}
+		from = end
+	}
+	return -1
+}
+
+func isIdentChar(char byte) bool {
+	return char == '_' || char >= '0' && char <= '9' || char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z'
+}
+
+// matchingParen returns the index of the parenthesis closing the one at
+// open, or -1.
+func matchingParen(s string, open int) int {
+	if open < 0 || open >= len(s) || s[open] != '(' {
+		return -1
+	}
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// singleColon returns the index of the first colon that is not part of a
+// scope operator, or -1.
+func singleColon(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] != ':' {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == ':' {
+			i++
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func filterExtensions(files, extensions []string) []string {
+	var filtered []string
+	for _, file := range files {
+		if isValidSourceFile(file, extensions) {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered
+}
+
+func diffAPI(oldAPI, newAPI map[string][]APIElement) *APIDiff {
+	diff := &APIDiff{}
+
+	keys := make(map[string]bool)
+	for key := range oldAPI {
+		keys[key] = true
+	}
+	for key := range newAPI {
+		keys[key] = true
+	}
+
+	for key := range keys {
+		removed := missingSignatures(oldAPI[key], newAPI[key])
+		added := missingSignatures(newAPI[key], oldAPI[key])
+
+		// A single signature replaced by another is a change, anything else
+		// is an overload set gaining or losing entries
+		if len(removed) == 1 && len(added) == 1 {
+			diff.Changed = append(diff.Changed, APIChange{
+				Kind:     added[0].Kind,
+				Name:     added[0].Name,
+				Language: added[0].Language,
+				File:     added[0].File,
+				Before:   removed[0].Signature,
+				After:    added[0].Signature,
+			})
+			continue
+		}
+
+		for _, element := range removed {
+			diff.Removed = append(diff.Removed, APIChange{Kind: element.Kind, Name: element.Name, Language: element.Language, File: element.File, Before: element.Signature})
+		}
+		for _, element := range added {
+			diff.Added = append(diff.Added, APIChange{Kind: element.Kind, Name: element.Name, Language: element.Language, File: element.File, After: element.Signature})
+		}
+	}
+
+	sortAPIChanges(diff.Removed)
+	sortAPIChanges(diff.Changed)
+	sortAPIChanges(diff.Added)
+
+	return diff
+}
+
+// missingSignatures returns the elements of from whose signature is not in to.
+func missingSignatures(from, to []APIElement) []APIElement {
+	present := make(map[string]bool)
+	for _, element := range to {
+		present[element.Signature] = true
+	}
+
+	var missing []APIElement
+	seen := make(map[string]bool)
+	for _, element := range from {
+		if present[element.Signature] || seen[element.Signature] {
+			continue
+		}
+		seen[element.Signature] = true
+		missing = append(missing, element)
+	}
+	return missing
+}
+
+func sortAPIChanges(changes []APIChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].File != changes[j].File {
+			return changes[i].File < changes[j].File
+		}
+		if changes[i].Name != changes[j].Name {
+			return changes[i].Name < changes[j].Name
+		}
+		return changes[i].Before+changes[i].After < changes[j].Before+changes[j].After
+	})
+}
+
+func writeAPIDiff(diff *APIDiff) error {
+	format := apiDiffFormat
+	if format == "" {
+		format = "markdown"
+		if filepath.Ext(apiDiffOutputFile) == ".json" {
+			format = "json"
+		}
+	}
+
+	var output []byte
+	var err error
+
+	switch format {
+	case "markdown", "md":
+		output = []byte(formatAPIDiff(diff))
+	case "json":
+		output, err = json.MarshalIndent(diff, "", "  ")
+	default:
+		return fmt.Errorf("unsupported format: %s (expected markdown or json)", format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if apiDiffOutputFile != "" {
+		runManifest.AddResult(apiDiffOutputFile, output)
+		return os.WriteFile(apiDiffOutputFile, output, 0644)
+	}
+
+	runManifest.AddResult("stdout", output)
+	fmt.Print(string(output))
+	return nil
+}
+
+func formatAPIDiff(diff *APIDiff) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# API Changes: %s → %s\n\n", diff.Old, diff.New))
+
+	if len(diff.Removed) == 0 && len(diff.Changed) == 0 && len(diff.Added) == 0 {
+		sb.WriteString("No public API changes.\n")
+		return sb.String()
+	}
+
+	writeAPIChanges(&sb, "Removed (breaking)", diff.Removed, func(change APIChange) string {
+		return fmt.Sprintf("- `%s`", change.Before)
+	})
+	writeAPIChanges(&sb, "Changed (breaking)", diff.Changed, func(change APIChange) string {
+		return fmt.Sprintf("- %s\n  - before: `%s`\n  - after: `%s`", change.Name, change.Before, change.After)
+	})
+	writeAPIChanges(&sb, "Added", diff.Added, func(change APIChange) string {
+		return fmt.Sprintf("- `%s`", change.After)
+	})
+
+	return sb.String()
+}
+
+func writeAPIChanges(sb *strings.Builder, title string, changes []APIChange, format func(APIChange) string) {
+	if len(changes) == 0 {
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("## %s (%d)\n", title, len(changes)))
+
+	file := ""
+	for _, change := range changes {
+		if change.File != file {
+			file = change.File
+			sb.WriteString(fmt.Sprintf("### %s\n", file))
+		}
+		sb.WriteString(format(change) + "\n")
+	}
+	sb.WriteString("\n")
+}
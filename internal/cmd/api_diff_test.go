@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vitruves/gop/internal/registry"
+)
+
+func TestAPIDiffRefs(t *testing.T) {
+	gitRepo(t,
+		map[string]string{"api.h": `int add(int a, int b);
+int sub(int a, int b);
+int scale(int x, int factor);
+int resize(int width,
+           int height);
+inline int half(int x) { return x / 2; }
+`},
+		map[string]string{"api.h": `int add(int left, int right);
+int scale(int x, double factor);
+int mul(int a, int b);
+int resize(int width,
+           long height);
+inline int half(int x) { return x >> 1; }
+`},
+	)
+	output := filepath.Join(t.TempDir(), "api.json")
+
+	if err := runCommand(t, "api-diff", "--old", "v1", "--new", "v2", "-o", output, "--no-progress"); err != nil {
+		t.Fatalf("api-diff failed: %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var diff APIDiff
+	if err := json.Unmarshal(data, &diff); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diff.Removed) != 1 || diff.Removed[0].Name != "sub" || diff.Removed[0].Before != "int sub(int, int)" {
+		t.Errorf("Expected sub to be removed, got %+v", diff.Removed)
+	}
+
+	// Renamed parameters and edited one-line bodies leave the API as it
+	// was, parameters declared on several lines are compared in full
+	if len(diff.Changed) != 2 {
+		t.Fatalf("Expected the signatures of resize and scale to change, got %+v", diff.Changed)
+	}
+	if resize := diff.Changed[0]; resize.Name != "resize" || resize.Before != "int resize(int, int)" || resize.After != "int resize(int, long)" {
+		t.Errorf("Expected the height of resize to become long, got %+v", resize)
+	}
+	if scale := diff.Changed[1]; scale.Name != "scale" || scale.Before != "int scale(int, int)" || scale.After != "int scale(int, double)" {
+		t.Errorf("Expected the factor of scale to become double, got %+v", scale)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].Name != "mul" || diff.Added[0].File != "api.h" {
+		t.Errorf("Expected mul to be added, got %+v", diff.Added)
+	}
+}
+
+func TestAPISignature(t *testing.T) {
+	tests := []struct {
+		fn   registry.Function
+		want string
+	}{
+		{registry.Function{Name: "half", Language: "c", Signature: "inline int half(int x) { return x / 2; }"}, "inline int half(int)"},
+		{registry.Function{Name: "draw", Language: "c", Signature: "void draw(const char *name, void (*done)(int));"}, "void draw(const char*, void(*)(int))"},
+		{registry.Function{Name: "Shape::area", Language: "cpp", Signature: "double Shape::area(int precision) const override {"}, "double Shape::area(int) const override"},
+		{registry.Function{Name: "Shape::Shape", Language: "cpp", Signature: "Shape::Shape(int sides) : sides_(sides) {}"}, "Shape::Shape(int)"},
+		{registry.Function{Name: "Read", Language: "go", Signature: "func (r *Reader) Read(p []byte) (n int, err error) {"}, "func (r *Reader) Read(p []byte) (n int, err error)"},
+		{registry.Function{Name: "parse", Language: "python", Signature: "def parse(text: str, strict=False) -> dict: return {}"}, "def parse(text: str, strict=False) -> dict"},
+	}
+	for _, tt := range tests {
+		if got := apiSignature(tt.fn); got != tt.want {
+			t.Errorf("apiSignature(%q) = %q, want %q", tt.fn.Signature, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeBenchmarkFile(b *testing.B) (string, int64) {
+	var sb strings.Builder
+	for i := 0; i < 20; i++ {
+		sb.WriteString(fmt.Sprintf("// TODO: validate input %d\nint handler_%d(const char *host) {\n    if (host && debug = true) {\n        connect(\"localhost\", %d);\n    }\n    return 0;\n}\n\n", i, i, i))
+	}
+	path := filepath.Join(b.TempDir(), "bench.c")
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		b.Fatal(err)
+	}
+	return path, int64(sb.Len())
+}
+
+// The benchmarks scan one file per iteration from parallel workers, as the
+// commands do, to measure the throughput of the shared patterns.
+
+func BenchmarkScanFileForPlaceholders(b *testing.B) {
+	path, size := writeBenchmarkFile(b)
+	defer func(engine string) { placeholdersEngine = engine }(placeholdersEngine)
+	for _, engine := range []string{engineRegex, engineAhoCorasick} {
+		b.Run(engine, func(b *testing.B) {
+			placeholdersEngine = engine
+			b.SetBytes(size)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := scanFileForPlaceholders(path); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkAnalyzeFile(b *testing.B) {
+	path, size := writeBenchmarkFile(b)
+	b.SetBytes(size)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := analyzeFile(path); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,378 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"gopkg.in/yaml.v3"
+)
+
+// CIConfig is the ci section of .gop.yaml, the defaults of gop ci init.
+type CIConfig struct {
+	Provider  string   `yaml:"provider"`
+	Analyzers []string `yaml:"analyzers"`
+	Language  string   `yaml:"language"`
+	FailOn    string   `yaml:"fail_on"`
+	// MaxFindings is the number of findings at or above FailOn tolerated
+	MaxFindings int    `yaml:"max_findings"`
+	ChangedOnly bool   `yaml:"changed_only"`
+	Branch      string `yaml:"branch"`
+	// LicenseHeader is the template license-check compares headers with
+	LicenseHeader string `yaml:"license_header"`
+}
+
+// ciAnalyzers are the commands a generated job can run: the ones reporting
+// diagnostics from the sources alone, without a build.
+var ciAnalyzers = []string{"placeholders", "error-check", "enum-check", "forward-decl-check", "header-check", "license-check", "plugins"}
+
+// ciSeverities ranks the severities --fail-on accepts.
+var ciSeverities = map[string]int{"info": 1, "warning": 2, "error": 3, "none": 4}
+
+const ciResultsDir = "gop-results"
+
+var (
+	ciConfigFile    string
+	ciProvider      string
+	ciAnalyzerNames []string
+	ciFailOn        string
+	ciMaxFindings   int
+	ciChangedOnly   bool
+	ciBranch        string
+	ciLicenseHeader string
+	ciOutputFile    string
+	ciForce         bool
+	ciGateFailOn    string
+	ciGateMax       int
+)
+
+var ciCmd = &cobra.Command{
+	Use:   "ci",
+	Short: "Run gop in continuous integration",
+}
+
+var ciInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Generate a GitHub Actions or GitLab CI job running gop",
+	Long: `Write a GitHub Actions workflow or a GitLab CI job that installs gop, runs the selected
+analyzers and fails the pipeline through gop ci gate. GitHub jobs upload SARIF to code
+scanning, GitLab jobs publish codeclimate reports to the code quality widget.
+
+Defaults are read from the ci section of .gop.yaml, flags take precedence:
+
+  ci:
+    provider: github
+    analyzers: [placeholders, error-check]
+    language: cpp
+    fail_on: error
+    max_findings: 0
+    changed_only: true
+    branch: main
+    license_header: LICENSE_HEADER.txt`,
+	Args: cobra.NoArgs,
+	RunE: runCIInit,
+}
+
+var ciGateCmd = &cobra.Command{
+	Use:   "gate REPORT...",
+	Short: "Fail when reports hold too many findings",
+	Long: `Read checkstyle, codeclimate, json or sarif reports, or every report in a directory, count
+the findings at or above --fail-on and fail when there are more than --max-findings.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runCIGate,
+}
+
+func init() {
+	ciInitCmd.Flags().StringVar(&ciConfigFile, "config", ".gop.yaml", "YAML file whose ci section holds the defaults, optional unless given")
+	ciInitCmd.Flags().StringVar(&ciProvider, "provider", "github", "CI service: github or gitlab")
+	ciInitCmd.Flags().StringSliceVar(&ciAnalyzerNames, "analyzers", []string{"placeholders"}, "Analyzers to run: "+strings.Join(ciAnalyzers, ", "))
+	ciInitCmd.Flags().StringVar(&ciFailOn, "fail-on", "error", "Lowest severity that counts against --max-findings: info, warning, error or none")
+	ciInitCmd.Flags().IntVar(&ciMaxFindings, "max-findings", 0, "Findings at or above --fail-on tolerated before the job fails")
+	ciInitCmd.Flags().BoolVar(&ciChangedOnly, "changed-only", false, "Only analyze the files a pull or merge request changes")
+	ciInitCmd.Flags().StringVar(&ciBranch, "branch", "main", "Branch whose pushes are analyzed in full")
+	ciInitCmd.Flags().StringVar(&ciLicenseHeader, "license-header", "", "License header template for license-check")
+	ciInitCmd.Flags().StringVarP(&ciOutputFile, "output", "o", "", "Output file (default: standard output)")
+	ciInitCmd.Flags().BoolVar(&ciForce, "force", false, "Overwrite an existing output file")
+
+	ciGateCmd.Flags().StringVar(&ciGateFailOn, "fail-on", "error", "Lowest severity that counts against --max-findings: info, warning, error or none")
+	ciGateCmd.Flags().IntVar(&ciGateMax, "max-findings", 0, "Findings at or above --fail-on tolerated")
+
+	ciCmd.AddCommand(ciInitCmd)
+	ciCmd.AddCommand(ciGateCmd)
+}
+
+func runCIInit(cmd *cobra.Command, args []string) error {
+	config := &CIConfig{}
+	data, err := os.ReadFile(ciConfigFile)
+	if err != nil && (cmd.Flags().Changed("config") || !errors.Is(err, os.ErrNotExist)) {
+		return err
+	}
+	if err == nil {
+		var file struct {
+			CI CIConfig `yaml:"ci"`
+		}
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", ciConfigFile, err)
+		}
+		config = &file.CI
+	}
+
+	overrides := []struct {
+		flag   string
+		value  *string
+		config *string
+	}{
+		{"provider", &ciProvider, &config.Provider},
+		{"fail-on", &ciFailOn, &config.FailOn},
+		{"branch", &ciBranch, &config.Branch},
+		{"license-header", &ciLicenseHeader, &config.LicenseHeader},
+	}
+	for _, o := range overrides {
+		if cmd.Flags().Changed(o.flag) || *o.config == "" {
+			*o.config = *o.value
+		}
+	}
+	if cmd.Flags().Changed("analyzers") || len(config.Analyzers) == 0 {
+		config.Analyzers = ciAnalyzerNames
+	}
+	if cmd.Flags().Changed("max-findings") {
+		config.MaxFindings = ciMaxFindings
+	}
+	if cmd.Flags().Changed("changed-only") {
+		config.ChangedOnly = ciChangedOnly
+	}
+	if language != "" {
+		config.Language = language
+	}
+
+	workflow, err := ciWorkflow(config)
+	if err != nil {
+		return err
+	}
+
+	if ciOutputFile == "" {
+		runManifest.AddResult("stdout", []byte(workflow))
+		fmt.Print(workflow)
+		return nil
+	}
+	if _, err := os.Stat(ciOutputFile); err == nil && !ciForce {
+		return fmt.Errorf("%s already exists, use --force to overwrite it", ciOutputFile)
+	}
+	if dir := filepath.Dir(ciOutputFile); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	runManifest.AddResult(ciOutputFile, []byte(workflow))
+	if err := os.WriteFile(ciOutputFile, []byte(workflow), 0644); err != nil {
+		return err
+	}
+	logSuccess(fmt.Sprintf("%s job written to %s", config.Provider, ciOutputFile))
+	return nil
+}
+
+// ciWorkflow renders the job of config.Provider.
+func ciWorkflow(config *CIConfig) (string, error) {
+	if config.Provider != "github" && config.Provider != "gitlab" {
+		return "", fmt.Errorf("unsupported provider: %s (expected github or gitlab)", config.Provider)
+	}
+	if _, ok := ciSeverities[config.FailOn]; !ok {
+		return "", fmt.Errorf("unsupported --fail-on: %s (expected info, warning, error or none)", config.FailOn)
+	}
+	if config.MaxFindings < 0 {
+		return "", fmt.Errorf("--max-findings must not be negative")
+	}
+	if config.Language != "" && config.Language != "c" && config.Language != "cpp" {
+		for _, analyzer := range config.Analyzers {
+			if analyzer != "placeholders" && analyzer != "license-check" && analyzer != "plugins" {
+				return "", fmt.Errorf("%s only analyzes C and C++, not %s", analyzer, config.Language)
+			}
+		}
+	}
+	if len(config.Analyzers) == 0 {
+		return "", fmt.Errorf("no analyzers selected")
+	}
+
+	format, extension := "sarif", ".sarif"
+	if config.Provider == "gitlab" {
+		format, extension = "codeclimate", ".json"
+	}
+	var commands, reports []string
+	seen := make(map[string]bool)
+	for _, analyzer := range config.Analyzers {
+		known := false
+		for _, name := range ciAnalyzers {
+			known = known || name == analyzer
+		}
+		if !known {
+			return "", fmt.Errorf("unknown analyzer: %s (expected %s)", analyzer, strings.Join(ciAnalyzers, ", "))
+		}
+		if seen[analyzer] {
+			continue
+		}
+		seen[analyzer] = true
+
+		report := ciResultsDir + "/" + analyzer + extension
+		command := "gop " + analyzer
+		if config.Language != "" {
+			command += " -l " + config.Language
+		}
+		command += " -R"
+		if config.ChangedOnly {
+			command += ` $(sed 's/^/-i /' gop-changed.txt)`
+		}
+		if analyzer == "license-check" {
+			if config.LicenseHeader == "" {
+				return "", fmt.Errorf("license-check needs a license header template (--license-header or license_header)")
+			}
+			command += " --header " + config.LicenseHeader
+		}
+		command = fmt.Sprintf("%s -f %s -o %s", command, format, report)
+		if analyzer == "license-check" {
+			// license-check fails on findings, which the gate judges instead
+			command += " || test -s " + report
+		}
+		commands = append(commands, command)
+		reports = append(reports, report)
+	}
+	gate := fmt.Sprintf("gop ci gate --fail-on %s --max-findings %d %s", config.FailOn, config.MaxFindings, ciResultsDir)
+
+	if config.Provider == "gitlab" {
+		return gitlabJob(config, commands, reports, gate), nil
+	}
+	return githubWorkflow(config, commands, gate), nil
+}
+
+func githubWorkflow(config *CIConfig, commands []string, gate string) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Generated by gop ci init\n")
+	sb.WriteString("name: gop\n\n")
+	sb.WriteString("on:\n  pull_request:\n  push:\n")
+	sb.WriteString(fmt.Sprintf("    branches: [%s]\n\n", config.Branch))
+	sb.WriteString("permissions:\n  contents: read\n  security-events: write\n\n")
+	sb.WriteString("jobs:\n  gop:\n    runs-on: ubuntu-latest\n    steps:\n")
+	sb.WriteString("      - uses: actions/checkout@v4\n")
+	if config.ChangedOnly {
+		sb.WriteString("        with:\n          fetch-depth: 0\n")
+	}
+	sb.WriteString("      - uses: actions/setup-go@v5\n        with:\n          go-version: stable\n")
+	sb.WriteString("      - name: Install gop\n        run: go install github.com/vitruves/gop@latest\n")
+	if config.ChangedOnly {
+		sb.WriteString("      - name: List changed files\n        run: |\n")
+		sb.WriteString("          if [ \"${{ github.event_name }}\" = pull_request ]; then\n")
+		sb.WriteString("            git diff --name-only --diff-filter=d \"${{ github.event.pull_request.base.sha }}\" HEAD > gop-changed.txt\n")
+		sb.WriteString("          else\n            : > gop-changed.txt\n          fi\n")
+	}
+	sb.WriteString("      - name: Analyze\n        run: |\n")
+	sb.WriteString("          mkdir -p " + ciResultsDir + "\n")
+	for _, command := range commands {
+		sb.WriteString("          " + command + "\n")
+	}
+	sb.WriteString("      - name: Upload SARIF\n        if: always() && hashFiles('" + ciResultsDir + "/*.sarif') != ''\n")
+	sb.WriteString("        uses: github/codeql-action/upload-sarif@v3\n")
+	sb.WriteString("        with:\n          sarif_file: " + ciResultsDir + "\n          category: gop\n")
+	sb.WriteString("      - name: Gate\n        run: " + gate + "\n")
+	return sb.String()
+}
+
+func gitlabJob(config *CIConfig, commands, reports []string, gate string) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Generated by gop ci init\n")
+	sb.WriteString("gop:\n  stage: test\n  image: golang:latest\n")
+	if config.ChangedOnly {
+		sb.WriteString("  variables:\n    GIT_DEPTH: 0\n")
+	}
+	sb.WriteString("  rules:\n")
+	sb.WriteString("    - if: $CI_PIPELINE_SOURCE == \"merge_request_event\"\n")
+	sb.WriteString(fmt.Sprintf("    - if: $CI_COMMIT_BRANCH == \"%s\"\n", config.Branch))
+	sb.WriteString("  before_script:\n    - go install github.com/vitruves/gop@latest\n")
+	sb.WriteString("    - mkdir -p " + ciResultsDir + "\n")
+	sb.WriteString("  script:\n")
+	if config.ChangedOnly {
+		sb.WriteString("    - |\n")
+		sb.WriteString("      if [ -n \"$CI_MERGE_REQUEST_DIFF_BASE_SHA\" ]; then\n")
+		sb.WriteString("        git diff --name-only --diff-filter=d \"$CI_MERGE_REQUEST_DIFF_BASE_SHA\" HEAD > gop-changed.txt\n")
+		sb.WriteString("      else\n        : > gop-changed.txt\n      fi\n")
+	}
+	for _, command := range commands {
+		sb.WriteString("    - " + command + "\n")
+	}
+	sb.WriteString("    - " + gate + "\n")
+	sb.WriteString("  artifacts:\n    when: always\n    reports:\n      codequality:\n")
+	for _, report := range reports {
+		sb.WriteString("        - " + report + "\n")
+	}
+	return sb.String()
+}
+
+func runCIGate(cmd *cobra.Command, args []string) error {
+	threshold, ok := ciSeverities[ciGateFailOn]
+	if !ok {
+		return fmt.Errorf("unsupported --fail-on: %s (expected info, warning, error or none)", ciGateFailOn)
+	}
+
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+		entries, err := os.ReadDir(arg)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				paths = append(paths, filepath.Join(arg, entry.Name()))
+			}
+		}
+	}
+
+	counts := make(map[diagnostics.Severity]int)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		findings, err := diagnostics.Parse(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for _, finding := range findings {
+			counts[finding.Severity]++
+		}
+	}
+
+	failing := ciFailing(counts, threshold)
+	var parts []string
+	for _, severity := range []diagnostics.Severity{diagnostics.SeverityError, diagnostics.SeverityWarning, diagnostics.SeverityInfo} {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[severity], severity))
+	}
+	summary := fmt.Sprintf("%d reports: %s", len(paths), strings.Join(parts, ", "))
+	if failing > ciGateMax {
+		return fmt.Errorf("%s; %d findings at or above %s exceed the limit of %d", summary, failing, ciGateFailOn, ciGateMax)
+	}
+	logSuccess(summary)
+	return nil
+}
+
+// ciFailing counts the findings whose severity reaches threshold.
+func ciFailing(counts map[diagnostics.Severity]int, threshold int) int {
+	failing := 0
+	for severity, count := range counts {
+		if ciSeverities[string(severity)] >= threshold {
+			failing += count
+		}
+	}
+	return failing
+}
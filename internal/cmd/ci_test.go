@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vitruves/gop/internal/diagnostics"
+)
+
+func TestCIWorkflow(t *testing.T) {
+	config := &CIConfig{
+		Provider:      "github",
+		Analyzers:     []string{"error-check", "license-check", "error-check"},
+		Language:      "c",
+		FailOn:        "warning",
+		MaxFindings:   3,
+		ChangedOnly:   true,
+		Branch:        "main",
+		LicenseHeader: "HEADER.txt",
+	}
+	workflow, err := ciWorkflow(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"gop error-check -l c -R $(sed 's/^/-i /' gop-changed.txt) -f sarif -o gop-results/error-check.sarif\n",
+		"--header HEADER.txt -f sarif -o gop-results/license-check.sarif || test -s gop-results/license-check.sarif\n",
+		"uses: github/codeql-action/upload-sarif@v3\n",
+		"run: gop ci gate --fail-on warning --max-findings 3 gop-results\n",
+		"fetch-depth: 0\n",
+	} {
+		if !strings.Contains(workflow, want) {
+			t.Errorf("Expected %q in workflow:\n%s", want, workflow)
+		}
+	}
+	if strings.Count(workflow, "gop error-check") != 1 {
+		t.Errorf("Expected a repeated analyzer to run once:\n%s", workflow)
+	}
+
+	config.Provider = "gitlab"
+	job, err := ciWorkflow(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(job, "-f codeclimate -o gop-results/error-check.json") || !strings.Contains(job, "codequality:\n        - gop-results/error-check.json\n") {
+		t.Errorf("Expected codeclimate reports in the GitLab job:\n%s", job)
+	}
+
+	for _, broken := range []CIConfig{
+		{Provider: "jenkins", Analyzers: []string{"placeholders"}, FailOn: "error"},
+		{Provider: "github", Analyzers: []string{"stats"}, FailOn: "error"},
+		{Provider: "github", Analyzers: []string{"license-check"}, FailOn: "error"},
+		{Provider: "github", Analyzers: []string{"error-check"}, FailOn: "error", Language: "python"},
+	} {
+		if _, err := ciWorkflow(&broken); err == nil {
+			t.Errorf("Expected %+v to be rejected", broken)
+		}
+	}
+}
+
+func TestCIFailing(t *testing.T) {
+	counts := map[diagnostics.Severity]int{diagnostics.SeverityError: 1, diagnostics.SeverityWarning: 2, diagnostics.SeverityInfo: 4}
+	for failOn, want := range map[string]int{"error": 1, "warning": 3, "info": 7, "none": 0} {
+		if got := ciFailing(counts, ciSeverities[failOn]); got != want {
+			t.Errorf("ciFailing(%s) = %d, want %d", failOn, got, want)
+		}
+	}
+}
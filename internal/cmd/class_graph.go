@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/classgraph"
+)
+
+var (
+	classGraphOutputFile    string
+	classGraphFormat        string
+	classGraphFocus         string
+	classGraphShowOverrides bool
+	classGraphRender        string
+	classGraphRenderMax     int
+	classGraphNamespace     string
+	classGraphModule        string
+)
+
+var classGraphCmd = &cobra.Command{
+	Use:   "class-graph",
+	Short: "Render the inheritance and composition graph of classes",
+	Long: `Extract base-class relationships from class, struct and trait declarations and render
+an inheritance/composition diagram as Graphviz dot, Mermaid, PlantUML or JSON. PlantUML
+class diagrams also list the fields and methods of each class.`,
+	RunE: runClassGraph,
+}
+
+func init() {
+	classGraphCmd.Flags().StringVarP(&classGraphOutputFile, "output", "o", "", "Output file (.dot, .mmd, .puml, or .json)")
+	classGraphCmd.Flags().StringVarP(&classGraphFormat, "format", "f", "", "Output format (dot, mermaid, plantuml, json), defaults to the output file extension")
+	classGraphCmd.Flags().StringVar(&classGraphFocus, "focus", "", "Only show this class with its ancestors, descendants and direct compositions")
+	classGraphCmd.Flags().StringVar(&classGraphNamespace, "namespace", "", "Only show classes in this namespace (and nested ones), plus the types they derive from or compose")
+	classGraphCmd.Flags().StringVar(&classGraphModule, "module", "", "Only show classes declared in this directory or file, plus the types they derive from or compose")
+	classGraphCmd.Flags().BoolVar(&classGraphShowOverrides, "show-overrides", false, "List methods each class overrides from its bases")
+	classGraphCmd.Flags().StringVar(&classGraphRender, "render", "", "Render an image with Graphviz dot (.svg, .png, .pdf)")
+	classGraphCmd.Flags().IntVar(&classGraphRenderMax, "render-max-nodes", 500, "Write DOT instead of rendering graphs with more classes, 0 for no limit")
+}
+
+func runClassGraph(cmd *cobra.Command, args []string) error {
+	config := classgraph.Config{
+		Language:       language,
+		Include:        include,
+		Exclude:        exclude,
+		Recursive:      recursive,
+		Depth:          depth,
+		Jobs:           jobs,
+		Verbose:        verbose,
+		OutputFile:     classGraphOutputFile,
+		Format:         classGraphFormat,
+		Focus:          classGraphFocus,
+		Namespace:      classGraphNamespace,
+		Module:         classGraphModule,
+		ShowOverrides:  classGraphShowOverrides,
+		Render:         classGraphRender,
+		RenderMaxNodes: classGraphRenderMax,
+		SkipGenerated:  skipGenerated,
+		SkipVendor:     skipVendor,
+		MaxFileSize:    maxFileSizeBytes,
+		FollowSymlinks: followSymlinks,
+		IncludeHidden:  includeHidden,
+		NoProgress:     noProgress,
+		Manifest:       runManifest,
+	}
+
+	return classgraph.Run(config)
+}
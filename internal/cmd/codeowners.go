@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/vitruves/gop/internal/codeowners"
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/diagnostics"
+)
+
+// openCodeOwners loads the given CODEOWNERS file, or the one of the
+// repository around the current directory. It returns nil when there is
+// none.
+func openCodeOwners(path string) (*codeowners.Resolver, error) {
+	root, err := gitRoot()
+	if err != nil {
+		root = "."
+	}
+	owners, err := codeowners.Open(path, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CODEOWNERS: %w", err)
+	}
+	return owners, nil
+}
+
+func displayOwnerCounts(findings []diagnostics.Diagnostic) {
+	fmt.Printf("\n%s\n", color.Wrap(color.BoldCyan, "=== OWNERS ==="))
+	for _, count := range codeowners.Counts(findings) {
+		fmt.Printf("%6d  %s (%d errors, %d warnings)\n", count.Findings, count.Owner, count.Errors, count.Warnings)
+	}
+}
@@ -0,0 +1,506 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/registry"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
+)
+
+// TreeSnapshot is what the analyzers found in one tree, keyed so that the
+// same element can be matched across trees even when line numbers move.
+type TreeSnapshot struct {
+	Files        int
+	Lines        int
+	CodeLines    int
+	Functions    map[string]registry.Function
+	Placeholders map[string]Placeholder
+}
+
+type MetricDelta struct {
+	Name   string `json:"name"`
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+	Delta  int    `json:"delta"`
+}
+
+type FunctionChange struct {
+	Key             string `json:"key"`
+	File            string `json:"file"`
+	Before          string `json:"before,omitempty"`
+	After           string `json:"after,omitempty"`
+	ComplexityDelta int    `json:"complexity_delta,omitempty"`
+}
+
+type Comparison struct {
+	Before               string           `json:"before"`
+	After                string           `json:"after"`
+	Metrics              []MetricDelta    `json:"metrics"`
+	AddedFunctions       []FunctionChange `json:"added_functions,omitempty"`
+	RemovedFunctions     []FunctionChange `json:"removed_functions,omitempty"`
+	ChangedFunctions     []FunctionChange `json:"changed_functions,omitempty"`
+	NewPlaceholders      []Placeholder    `json:"new_placeholders,omitempty"`
+	ResolvedPlaceholders []Placeholder    `json:"resolved_placeholders,omitempty"`
+}
+
+var (
+	compareBefore     string
+	compareAfter      string
+	compareAnalyzers  []string
+	compareOutputFile string
+	compareFormat     string
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare",
+	Short: "Compare two directories or git refs",
+	Long: `Run the analyzers on two trees, given as directories or git refs, and report what changed:
+added, removed and changed functions, new and resolved placeholders, and metric deltas.`,
+	RunE: runCompare,
+}
+
+func init() {
+	compareCmd.Flags().StringVar(&compareBefore, "before", "", "Directory or git ref of the baseline tree")
+	compareCmd.Flags().StringVar(&compareAfter, "after", ".", "Directory or git ref of the changed tree")
+	compareCmd.Flags().StringSliceVar(&compareAnalyzers, "analyzers", []string{"stats", "functions", "placeholders"}, "Analyzers to run (stats, functions, placeholders)")
+	compareCmd.Flags().StringVarP(&compareOutputFile, "output", "o", "", "Output file (.md or .json)")
+	compareCmd.Flags().StringVarP(&compareFormat, "format", "f", "", "Output format (markdown, json), defaults to the output file extension")
+	compareCmd.MarkFlagRequired("before")
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	enabled, err := enabledAnalyzers(compareAnalyzers)
+	if err != nil {
+		return err
+	}
+
+	beforeRoot, cleanupBefore, err := resolveTree(compareBefore)
+	if err != nil {
+		return err
+	}
+	defer cleanupBefore()
+
+	afterRoot, cleanupAfter, err := resolveTree(compareAfter)
+	if err != nil {
+		return err
+	}
+	defer cleanupAfter()
+
+	before, err := snapshotTree(beforeRoot, "Analyzing before", enabled, false)
+	if err != nil {
+		logError(fmt.Sprintf("Failed to analyze %s: %v", compareBefore, err))
+		return err
+	}
+
+	after, err := snapshotTree(afterRoot, "Analyzing after", enabled, false)
+	if err != nil {
+		logError(fmt.Sprintf("Failed to analyze %s: %v", compareAfter, err))
+		return err
+	}
+
+	comparison := compareSnapshots(before, after, enabled)
+	comparison.Before = compareBefore
+	comparison.After = compareAfter
+
+	if err := writeComparison(comparison); err != nil {
+		logError(fmt.Sprintf("Failed to write output: %v", err))
+		return err
+	}
+
+	logSuccess("Comparison completed")
+	return nil
+}
+
+func enabledAnalyzers(analyzers []string) (map[string]bool, error) {
+	enabled := make(map[string]bool)
+	for _, analyzer := range analyzers {
+		switch analyzer {
+		case "stats", "functions", "placeholders":
+			enabled[analyzer] = true
+		default:
+			return nil, fmt.Errorf("unknown analyzer: %s (expected stats, functions or placeholders)", analyzer)
+		}
+	}
+	return enabled, nil
+}
+
+// resolveTree returns a directory holding the tree. A git ref is exported to
+// a temporary directory, which the returned cleanup removes.
+func resolveTree(spec string) (string, func(), error) {
+	noop := func() {}
+
+	if info, err := os.Stat(spec); err == nil && info.IsDir() {
+		return spec, noop, nil
+	}
+
+	if err := exec.Command("git", "rev-parse", "--verify", "--quiet", spec+"^{tree}").Run(); err != nil {
+		return "", noop, fmt.Errorf("%s is neither a directory nor a git ref", spec)
+	}
+
+	dir, err := os.MkdirTemp("", "gop-compare-")
+	if err != nil {
+		return "", noop, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	logInfo(fmt.Sprintf("Exporting %s to %s", spec, dir))
+
+	var stderr bytes.Buffer
+	archive := exec.Command("git", "archive", "--format=tar", spec)
+	archive.Stderr = &stderr
+	data, err := archive.Output()
+	if err != nil {
+		cleanup()
+		return "", noop, fmt.Errorf("git archive %s: %v: %s", spec, err, strings.TrimSpace(stderr.String()))
+	}
+
+	if err := extractTar(bytes.NewReader(data), dir); err != nil {
+		cleanup()
+		return "", noop, err
+	}
+
+	return dir, cleanup, nil
+}
+
+func extractTar(r io.Reader, dir string) error {
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(filepath.Separator)) {
+			return fmt.Errorf("invalid path in archive: %s", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(file, reader)
+			file.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// quiet hides the progress report, for trees analyzed concurrently.
+func snapshotTree(root, description string, enabled map[string]bool, quiet bool) (*TreeSnapshot, error) {
+	opts := scanOptions()
+	opts.Root = root
+
+	files, err := utils.GetFilesToProcess(opts, func(path string) bool {
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &TreeSnapshot{
+		Functions:    make(map[string]registry.Function),
+		Placeholders: make(map[string]Placeholder),
+	}
+	type parsedFile struct {
+		relPath      string
+		stats        FileStats
+		functions    []registry.Function
+		placeholders []Placeholder
+	}
+
+	worker.Each(files, jobs, progress.New(description, len(files), noProgress || quiet), func(ctx context.Context, idx int, filePath string) *parsedFile {
+		relPath, _ := filepath.Rel(root, filePath)
+		relPath = filepath.ToSlash(relPath)
+
+		fileStats, err := analyzeFile(filePath)
+		if err != nil {
+			failures.Record(filePath, err)
+			return nil
+		}
+
+		parser := statsParser(fileStats.Language)
+
+		var functions []registry.Function
+		if enabled["functions"] && parser != nil {
+			functions, err = parser.ParseFile(filePath)
+			if err != nil {
+				failures.Record(filePath, err)
+			}
+		}
+
+		var placeholders []Placeholder
+		if enabled["placeholders"] && parser != nil {
+			placeholders, err = scanFileForPlaceholders(filePath)
+			if err != nil {
+				failures.Record(filePath, err)
+			}
+		}
+
+		return &parsedFile{relPath: relPath, stats: fileStats, functions: functions, placeholders: placeholders}
+	}, func(idx int, file *parsedFile) {
+		if file == nil {
+			return
+		}
+		relPath := file.relPath
+
+		snapshot.Files++
+		snapshot.Lines += file.stats.Lines
+		snapshot.CodeLines += file.stats.CodeLines
+
+		for _, fn := range file.functions {
+			fn.File = relPath
+			snapshot.Functions[functionKey(fn)] = fn
+		}
+
+		// Placeholders are matched by content, their line numbers shift with every edit
+		for _, placeholder := range file.placeholders {
+			placeholder.File = relPath
+			key := fmt.Sprintf("%s|%s|%s", relPath, placeholder.Type, placeholder.Content)
+			for i := 2; ; i++ {
+				if _, exists := snapshot.Placeholders[key]; !exists {
+					break
+				}
+				key = fmt.Sprintf("%s|%s|%s|%d", relPath, placeholder.Type, placeholder.Content, i)
+			}
+			snapshot.Placeholders[key] = placeholder
+		}
+	})
+
+	return snapshot, nil
+}
+
+func functionKey(fn registry.Function) string {
+	key := fn.File + ":" + fn.Name
+	if len(fn.ParamTypes) > 0 {
+		key += "(" + strings.Join(fn.ParamTypes, ", ") + ")"
+	}
+	return key
+}
+
+func compareSnapshots(before, after *TreeSnapshot, enabled map[string]bool) *Comparison {
+	comparison := &Comparison{}
+
+	if enabled["stats"] {
+		comparison.Metrics = append(comparison.Metrics,
+			metricDelta("Files", before.Files, after.Files),
+			metricDelta("Lines", before.Lines, after.Lines),
+			metricDelta("Code Lines", before.CodeLines, after.CodeLines),
+		)
+	}
+
+	if enabled["functions"] {
+		comparison.Metrics = append(comparison.Metrics,
+			metricDelta("Functions", len(before.Functions), len(after.Functions)),
+			metricDelta("Total Complexity", totalComplexity(before.Functions), totalComplexity(after.Functions)),
+		)
+
+		for key, fn := range after.Functions {
+			old, exists := before.Functions[key]
+			if !exists {
+				comparison.AddedFunctions = append(comparison.AddedFunctions, FunctionChange{Key: key, File: fn.File, After: fn.Signature})
+				continue
+			}
+			if old.Signature != fn.Signature || old.Complexity != fn.Complexity {
+				comparison.ChangedFunctions = append(comparison.ChangedFunctions, FunctionChange{
+					Key:             key,
+					File:            fn.File,
+					Before:          old.Signature,
+					After:           fn.Signature,
+					ComplexityDelta: fn.Complexity - old.Complexity,
+				})
+			}
+		}
+
+		for key, fn := range before.Functions {
+			if _, exists := after.Functions[key]; !exists {
+				comparison.RemovedFunctions = append(comparison.RemovedFunctions, FunctionChange{Key: key, File: fn.File, Before: fn.Signature})
+			}
+		}
+
+		sortChanges(comparison.AddedFunctions)
+		sortChanges(comparison.RemovedFunctions)
+		sortChanges(comparison.ChangedFunctions)
+	}
+
+	if enabled["placeholders"] {
+		comparison.Metrics = append(comparison.Metrics,
+			metricDelta("Placeholders", len(before.Placeholders), len(after.Placeholders)))
+
+		for key, placeholder := range after.Placeholders {
+			if _, exists := before.Placeholders[key]; !exists {
+				comparison.NewPlaceholders = append(comparison.NewPlaceholders, placeholder)
+			}
+		}
+		for key, placeholder := range before.Placeholders {
+			if _, exists := after.Placeholders[key]; !exists {
+				comparison.ResolvedPlaceholders = append(comparison.ResolvedPlaceholders, placeholder)
+			}
+		}
+
+		sortPlaceholders(comparison.NewPlaceholders)
+		sortPlaceholders(comparison.ResolvedPlaceholders)
+	}
+
+	return comparison
+}
+
+func metricDelta(name string, before, after int) MetricDelta {
+	return MetricDelta{Name: name, Before: before, After: after, Delta: after - before}
+}
+
+func totalComplexity(functions map[string]registry.Function) int {
+	total := 0
+	for _, fn := range functions {
+		total += fn.Complexity
+	}
+	return total
+}
+
+func sortChanges(changes []FunctionChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+}
+
+func sortPlaceholders(placeholders []Placeholder) {
+	sort.Slice(placeholders, func(i, j int) bool {
+		if placeholders[i].File != placeholders[j].File {
+			return placeholders[i].File < placeholders[j].File
+		}
+		return placeholders[i].Line < placeholders[j].Line
+	})
+}
+
+func writeComparison(comparison *Comparison) error {
+	format := compareFormat
+	if format == "" {
+		format = "markdown"
+		if filepath.Ext(compareOutputFile) == ".json" {
+			format = "json"
+		}
+	}
+
+	var output []byte
+	var err error
+
+	switch format {
+	case "markdown", "md":
+		output = []byte(formatComparison(comparison))
+	case "json":
+		output, err = json.MarshalIndent(comparison, "", "  ")
+	default:
+		return fmt.Errorf("unsupported format: %s (expected markdown or json)", format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if compareOutputFile != "" {
+		runManifest.AddResult(compareOutputFile, output)
+		return os.WriteFile(compareOutputFile, output, 0644)
+	}
+
+	runManifest.AddResult("stdout", output)
+	fmt.Print(string(output))
+	return nil
+}
+
+func formatComparison(comparison *Comparison) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# Comparison: %s → %s\n\n", comparison.Before, comparison.After))
+
+	sb.WriteString("## Metrics\n")
+	sb.WriteString("| Metric | Before | After | Delta |\n")
+	sb.WriteString("|--------|--------|-------|-------|\n")
+	for _, metric := range comparison.Metrics {
+		delta := utils.FormatCount(metric.Delta)
+		if metric.Delta >= 0 {
+			delta = "+" + delta
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", metric.Name, utils.FormatCount(metric.Before), utils.FormatCount(metric.After), delta))
+	}
+	sb.WriteString("\n")
+
+	writeFunctionChanges(&sb, "Added Functions", comparison.AddedFunctions, func(change FunctionChange) string {
+		return fmt.Sprintf("- `%s`", change.After)
+	})
+	writeFunctionChanges(&sb, "Removed Functions", comparison.RemovedFunctions, func(change FunctionChange) string {
+		return fmt.Sprintf("- `%s`", change.Before)
+	})
+	writeFunctionChanges(&sb, "Changed Functions", comparison.ChangedFunctions, func(change FunctionChange) string {
+		line := fmt.Sprintf("- %s", strings.TrimPrefix(change.Key, change.File+":"))
+		if change.Before != change.After {
+			line += fmt.Sprintf("\n  - before: `%s`\n  - after: `%s`", change.Before, change.After)
+		}
+		if change.ComplexityDelta != 0 {
+			line += fmt.Sprintf("\n  - complexity %+d", change.ComplexityDelta)
+		}
+		return line
+	})
+
+	writePlaceholderChanges(&sb, "New Placeholders", comparison.NewPlaceholders)
+	writePlaceholderChanges(&sb, "Resolved Placeholders", comparison.ResolvedPlaceholders)
+
+	return sb.String()
+}
+
+func writeFunctionChanges(sb *strings.Builder, title string, changes []FunctionChange, format func(FunctionChange) string) {
+	if len(changes) == 0 {
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("## %s (%d)\n", title, len(changes)))
+
+	file := ""
+	for _, change := range changes {
+		if change.File != file {
+			file = change.File
+			sb.WriteString(fmt.Sprintf("### %s\n", file))
+		}
+		sb.WriteString(format(change) + "\n")
+	}
+	sb.WriteString("\n")
+}
+
+func writePlaceholderChanges(sb *strings.Builder, title string, placeholders []Placeholder) {
+	if len(placeholders) == 0 {
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("## %s (%d)\n", title, len(placeholders)))
+	for _, placeholder := range placeholders {
+		sb.WriteString(fmt.Sprintf("- %s:%d [%s] %s\n", placeholder.File, placeholder.Line, placeholder.Type, placeholder.Content))
+	}
+	sb.WriteString("\n")
+}
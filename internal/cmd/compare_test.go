@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitRepo creates a repository with one commit per version, tagged v1, v2
+// and so on, and makes it the working directory for the rest of the test.
+func gitRepo(t *testing.T, versions ...map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		command := exec.Command("git", args...)
+		command.Dir = dir
+		command.Env = append(os.Environ(), "GIT_AUTHOR_NAME=gop", "GIT_AUTHOR_EMAIL=gop@example.com",
+			"GIT_COMMITTER_NAME=gop", "GIT_COMMITTER_EMAIL=gop@example.com")
+		if output, err := command.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+
+	git("init", "--quiet")
+	for i, files := range versions {
+		for name, content := range files {
+			path := filepath.Join(dir, name)
+			if content == "" {
+				os.Remove(path)
+				continue
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		git("add", "-A")
+		git("commit", "--quiet", "--allow-empty", "-m", "version")
+		git("tag", "v"+string(rune('1'+i)))
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+func TestCompareRefs(t *testing.T) {
+	gitRepo(t,
+		map[string]string{"lib.c": `int add(int a, int b) {
+    return a + b;
+}
+int sub(int a, int b) {
+    return a - b;
+}
+int clamp(int x) {
+    return x;
+}
+`},
+		map[string]string{"lib.c": `int add(int a, int b) {
+    return a + b;
+}
+int clamp(int x) {
+    if (x < 0) {
+        return 0;
+    }
+    return x;
+}
+int mul(int a, int b) {
+    return a * b;
+}
+`},
+	)
+	output := filepath.Join(t.TempDir(), "compare.json")
+
+	if err := runCommand(t, "compare", "--before", "v1", "--after", "v2", "--analyzers", "functions", "-o", output, "--no-progress"); err != nil {
+		t.Fatalf("compare failed: %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var comparison Comparison
+	if err := json.Unmarshal(data, &comparison); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := func(changes []FunctionChange) []string {
+		var keys []string
+		for _, change := range changes {
+			keys = append(keys, change.Key)
+		}
+		return keys
+	}
+	if added := keys(comparison.AddedFunctions); len(added) != 1 || added[0] != "lib.c:mul" {
+		t.Errorf("Expected mul to be added, got %v", added)
+	}
+	if removed := keys(comparison.RemovedFunctions); len(removed) != 1 || removed[0] != "lib.c:sub" {
+		t.Errorf("Expected sub to be removed, got %v", removed)
+	}
+	changed := comparison.ChangedFunctions
+	if len(changed) != 1 || changed[0].Key != "lib.c:clamp" || changed[0].ComplexityDelta != 1 {
+		t.Errorf("Expected clamp to gain one branch, got %+v", changed)
+	}
+
+	// An unknown ref fails the command
+	err = runCommand(t, "compare", "--before", "v9", "--after", "v2", "-o", output, "--no-progress")
+	if err == nil || !strings.Contains(err.Error(), "neither a directory nor a git ref") || ExitCode(err) != 1 {
+		t.Errorf("Expected an unknown ref to exit with status 1, got %v", err)
+	}
+}
@@ -10,7 +10,9 @@ var (
 	removeComments  bool
 	addLineNumbers  bool
 	addHeaders      bool
+	redactSecrets   bool
 	outputFile      string
+	splitManifest   string
 )
 
 var concatenateCmd = &cobra.Command{
@@ -25,7 +27,9 @@ func init() {
 	concatenateCmd.Flags().BoolVar(&removeComments, "remove-comments", false, "Remove comments from code")
 	concatenateCmd.Flags().BoolVar(&addLineNumbers, "add-line-numbers", false, "Add line numbers to each line")
 	concatenateCmd.Flags().BoolVar(&addHeaders, "add-headers", false, "Add file headers to separate scripts")
+	concatenateCmd.Flags().BoolVar(&redactSecrets, "redact", false, "Replace API keys, private keys, passwords and other secrets with placeholders")
 	concatenateCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file (if not specified, output to console)")
+	concatenateCmd.Flags().StringVar(&splitManifest, "split-manifest", "", "Write the boundaries, offsets and hashes of each file to this JSON file, for gop split (needs --output)")
 }
 
 func runConcatenate(cmd *cobra.Command, args []string) error {
@@ -41,7 +45,16 @@ func runConcatenate(cmd *cobra.Command, args []string) error {
 		RemoveComments: removeComments,
 		AddLineNumbers: addLineNumbers,
 		AddHeaders:     addHeaders,
+		Redact:         redactSecrets,
 		OutputFile:     outputFile,
+		SplitManifest:  splitManifest,
+		SkipGenerated:  skipGenerated,
+		SkipVendor:     skipVendor,
+		MaxFileSize:    maxFileSizeBytes,
+		FollowSymlinks: followSymlinks,
+		IncludeHidden:  includeHidden,
+		NoProgress:     noProgress,
+		Manifest:       runManifest,
 	}
 
 	return concatenate.Run(config)
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/configmatrix"
+)
+
+var (
+	configMatrixConfigFile     string
+	configMatrixCompare        bool
+	configMatrixConfigurations []string
+	configMatrixOutputFile     string
+	configMatrixFormat         string
+)
+
+var configMatrixCmd = &cobra.Command{
+	Use:   "config-matrix",
+	Short: "Report the configuration macros C/C++ files depend on",
+	Long: `List the macros tested by #if, #ifdef, #ifndef and #elif across the tree, how many
+conditionals and files use each one, and which macros every file depends on. Include
+guards are left out.
+
+With --compare every file is parsed once per configuration listed under configurations
+in the configuration file, keeping only the code each configuration compiles, and the
+functions defined by some configurations but not by others are reported.`,
+	RunE: runConfigMatrix,
+}
+
+func init() {
+	configMatrixCmd.Flags().StringVar(&configMatrixConfigFile, "config", ".gop.yaml", "YAML file listing the configurations for --compare")
+	configMatrixCmd.Flags().BoolVar(&configMatrixCompare, "compare", false, "Compare the functions each configuration defines")
+	configMatrixCmd.Flags().StringSliceVar(&configMatrixConfigurations, "configurations", nil, "Configurations to compare (default all)")
+	configMatrixCmd.Flags().StringVarP(&configMatrixOutputFile, "output", "o", "", "Output file")
+	configMatrixCmd.Flags().StringVarP(&configMatrixFormat, "format", "f", "", "Output format (markdown, json; default from the output extension)")
+}
+
+func runConfigMatrix(cmd *cobra.Command, args []string) error {
+	config := configmatrix.Config{
+		Include:        include,
+		Exclude:        exclude,
+		Recursive:      recursive,
+		Depth:          depth,
+		Jobs:           jobs,
+		Verbose:        verbose,
+		ConfigFile:     configMatrixConfigFile,
+		Compare:        configMatrixCompare || len(configMatrixConfigurations) > 0,
+		Configurations: configMatrixConfigurations,
+		OutputFile:     configMatrixOutputFile,
+		Format:         configMatrixFormat,
+		SkipGenerated:  skipGenerated,
+		SkipVendor:     skipVendor,
+		MaxFileSize:    maxFileSizeBytes,
+		FollowSymlinks: followSymlinks,
+		IncludeHidden:  includeHidden,
+		NoProgress:     noProgress,
+		Manifest:       runManifest,
+	}
+
+	return configmatrix.Run(config)
+}
@@ -0,0 +1,583 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/registry"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
+)
+
+// LineCoverage maps the instrumented lines of a file to their execution counts.
+type LineCoverage map[int]int
+
+// FunctionCoverage is a scanned function joined with the coverage of the
+// lines it spans.
+type FunctionCoverage struct {
+	Name         string  `json:"name"`
+	File         string  `json:"file"`
+	Line         int     `json:"line"`
+	Complexity   int     `json:"complexity"`
+	Lines        int     `json:"lines"`
+	CoveredLines int     `json:"covered_lines"`
+	Coverage     float64 `json:"coverage"`
+	// Instrumented is false when the file has no coverage data at all
+	Instrumented bool `json:"instrumented"`
+}
+
+type ModuleCoverage struct {
+	Module            string  `json:"module"`
+	Files             int     `json:"files"`
+	Functions         int     `json:"functions"`
+	UntestedFunctions int     `json:"untested_functions"`
+	Lines             int     `json:"lines"`
+	CoveredLines      int     `json:"covered_lines"`
+	Coverage          float64 `json:"coverage"`
+}
+
+type CoverageReport struct {
+	Input            string  `json:"input"`
+	InputFormat      string  `json:"input_format"`
+	Files            int     `json:"files"`
+	FilesWithoutData int     `json:"files_without_data"`
+	Functions        int     `json:"functions"`
+	TestedFunctions  int     `json:"tested_functions"`
+	Lines            int     `json:"lines"`
+	CoveredLines     int     `json:"covered_lines"`
+	Coverage         float64 `json:"coverage"`
+	MinCoverage      float64 `json:"min_coverage,omitempty"`
+	Complexity       int     `json:"complexity_threshold"`
+	// Untested lists the functions at or above the complexity threshold
+	// without a single executed line, the most complex first
+	Untested []FunctionCoverage `json:"untested"`
+	Modules  []ModuleCoverage   `json:"modules"`
+}
+
+var (
+	coverageInput      string
+	coverageOutputFile string
+	coverageFormat     string
+	coverageMin        float64
+	coverageComplexity int
+	coverageTop        int
+)
+
+var coverageCmd = &cobra.Command{
+	Use:   "coverage",
+	Short: "Join lcov or llvm-cov coverage with complexity to find untested complex functions",
+	Long: `Read a coverage report, either lcov tracefile (gcov/lcov/grcov, coverage.info) or the JSON
+of "llvm-cov export", and join it with the functions and complexity of the scanned sources.
+Complex functions without a single executed line are listed first, followed by the line
+coverage of every directory. With --min-coverage, the run fails when the total line coverage
+is below the threshold.
+
+Coverage paths are matched to the scanned files by absolute path, otherwise by their
+trailing path components, so reports produced in another checkout still apply.`,
+	RunE: runCoverage,
+}
+
+func init() {
+	coverageCmd.Flags().StringVar(&coverageInput, "input", "", "Coverage report: lcov tracefile or llvm-cov export JSON (required)")
+	coverageCmd.Flags().StringVarP(&coverageOutputFile, "output", "o", "", "Output file (.md, .html or .json)")
+	coverageCmd.Flags().StringVarP(&coverageFormat, "format", "f", "", "Output format (markdown, html, json), defaults to the output file extension")
+	coverageCmd.Flags().Float64Var(&coverageMin, "min-coverage", 0, "Fail when the total line coverage is below this percentage")
+	coverageCmd.Flags().IntVar(&coverageComplexity, "complexity", 10, "Cyclomatic complexity from which an untested function is listed")
+	coverageCmd.Flags().IntVar(&coverageTop, "top", 20, "Number of untested functions to list, 0 for all")
+	coverageCmd.MarkFlagRequired("input")
+}
+
+func runCoverage(cmd *cobra.Command, args []string) error {
+	if coverageMin < 0 || coverageMin > 100 {
+		return fmt.Errorf("--min-coverage must be between 0 and 100")
+	}
+
+	data, inputFormat, err := readCoverage(coverageInput)
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read coverage: %v", err))
+		return err
+	}
+	if verbose {
+		logInfo(fmt.Sprintf("Read %s coverage of %d files", inputFormat, len(data)))
+	}
+
+	files, err := utils.GetFilesToProcess(scanOptions(), func(path string) bool {
+		return statsParser(detectLanguage(path)) != nil && !utils.IsTestFile(path, utils.DefaultTestPatterns)
+	})
+	if err != nil {
+		logError(fmt.Sprintf("Failed to collect files: %v", err))
+		return err
+	}
+
+	if len(files) == 0 {
+		logWarning("No source files found")
+		return nil
+	}
+	runManifest.AddFiles(files)
+
+	functions := worker.Run(files, jobs, progress.New("Parsing functions", len(files), noProgress), func(ctx context.Context, idx int, filePath string) []registry.Function {
+		parsed, err := statsParser(detectLanguage(filePath)).ParseFile(filePath)
+		if err != nil {
+			failures.Record(filePath, err)
+			return nil
+		}
+		return parsed
+	})
+
+	report := joinCoverage(files, functions, matchCoverage(files, data), coverageComplexity, coverageTop)
+	report.Input = coverageInput
+	report.InputFormat = inputFormat
+	report.MinCoverage = coverageMin
+
+	if report.Files == report.FilesWithoutData {
+		logWarning("No scanned file has coverage data, check that the report belongs to these sources")
+	}
+
+	if err := writeCoverage(report); err != nil {
+		logError(fmt.Sprintf("Failed to write coverage: %v", err))
+		return err
+	}
+
+	logSuccess(fmt.Sprintf("Line coverage %.1f%%, %d untested complex functions", report.Coverage, len(report.Untested)))
+
+	if coverageMin > 0 && report.Coverage < coverageMin {
+		// Low coverage is a finding, not a usage mistake
+		cmd.SilenceUsage = true
+		return fmt.Errorf("line coverage %.1f%% is below the minimum of %.1f%%", report.Coverage, coverageMin)
+	}
+	return nil
+}
+
+// readCoverage parses an lcov tracefile or an llvm-cov JSON export, told
+// apart by their first character.
+func readCoverage(path string) (map[string]LineCoverage, string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '{' {
+		data, err := parseLLVMCoverage(trimmed)
+		return data, "llvm-cov", err
+	}
+	data, err := parseLcov(bytes.NewReader(content))
+	return data, "lcov", err
+}
+
+// parseLcov reads the DA records of an lcov tracefile. Records of the same
+// source file, one per test, are summed.
+func parseLcov(r io.Reader) (map[string]LineCoverage, error) {
+	data := make(map[string]LineCoverage)
+	var current LineCoverage
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "SF:"):
+			file := filepath.Clean(strings.TrimPrefix(line, "SF:"))
+			if data[file] == nil {
+				data[file] = make(LineCoverage)
+			}
+			current = data[file]
+		case strings.HasPrefix(line, "DA:"):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: DA record outside of a source file", lineNumber)
+			}
+			fields := strings.Split(strings.TrimPrefix(line, "DA:"), ",")
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("line %d: malformed DA record %q", lineNumber, line)
+			}
+			number, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: malformed DA record %q", lineNumber, line)
+			}
+			// gcov reports counts that overflowed as negative, and large ones as floats
+			count, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: malformed DA record %q", lineNumber, line)
+			}
+			current[number] += max(int(count), 0)
+		case line == "end_of_record":
+			current = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no SF record found, not an lcov tracefile")
+	}
+	return data, nil
+}
+
+type llvmExport struct {
+	Type string `json:"type"`
+	Data []struct {
+		Files []struct {
+			Filename string  `json:"filename"`
+			Segments [][]any `json:"segments"`
+		} `json:"files"`
+	} `json:"data"`
+}
+
+// llvmSegment is one [line, col, count, hasCount, isRegionEntry, isGapRegion]
+// entry of an llvm-cov export.
+type llvmSegment struct {
+	line      int
+	count     int
+	hasCount  bool
+	entry     bool
+	gapRegion bool
+}
+
+// parseLLVMCoverage derives line counts from the segments of an llvm-cov
+// export the way llvm-cov does: a line takes the count of the region it
+// is in at its start, or of the regions starting on it when larger.
+func parseLLVMCoverage(content []byte) (map[string]LineCoverage, error) {
+	var export llvmExport
+	if err := json.Unmarshal(content, &export); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(export.Type, "llvm.coverage.json.export") {
+		return nil, fmt.Errorf("not an llvm-cov export (type %q)", export.Type)
+	}
+
+	data := make(map[string]LineCoverage)
+	for _, export := range export.Data {
+		for _, file := range export.Files {
+			segments := make([]llvmSegment, 0, len(file.Segments))
+			for _, raw := range file.Segments {
+				if len(raw) < 5 {
+					return nil, fmt.Errorf("%s: malformed segment %v", file.Filename, raw)
+				}
+				segment := llvmSegment{line: jsonInt(raw[0]), count: jsonInt(raw[2]), hasCount: raw[3] == true, entry: raw[4] == true}
+				if len(raw) > 5 {
+					segment.gapRegion = raw[5] == true
+				}
+				segments = append(segments, segment)
+			}
+
+			name := filepath.Clean(file.Filename)
+			if data[name] == nil {
+				data[name] = make(LineCoverage)
+			}
+			for line, count := range segmentLines(segments) {
+				data[name][line] += count
+			}
+		}
+	}
+	return data, nil
+}
+
+func segmentLines(segments []llvmSegment) LineCoverage {
+	lines := make(LineCoverage)
+	if len(segments) == 0 {
+		return lines
+	}
+
+	var wrapped *llvmSegment
+	next := 0
+	for line := segments[0].line; line <= segments[len(segments)-1].line; line++ {
+		mapped := wrapped != nil && wrapped.hasCount && !wrapped.gapRegion
+		count := 0
+		if mapped {
+			count = wrapped.count
+		}
+		for ; next < len(segments) && segments[next].line == line; next++ {
+			segment := segments[next]
+			if segment.hasCount && segment.entry && !segment.gapRegion {
+				mapped = true
+				count = max(count, segment.count)
+			}
+			wrapped = &segments[next]
+		}
+		if mapped {
+			lines[line] = count
+		}
+	}
+	return lines
+}
+
+func jsonInt(value any) int {
+	if number, ok := value.(float64); ok {
+		return int(number)
+	}
+	return 0
+}
+
+// matchCoverage finds the coverage of each scanned file: the entry with the
+// same absolute path, otherwise the only entry ending with the scanned path.
+func matchCoverage(files []string, data map[string]LineCoverage) []LineCoverage {
+	byName := make(map[string][]string)
+	for path := range data {
+		byName[filepath.Base(path)] = append(byName[filepath.Base(path)], path)
+	}
+
+	matched := make([]LineCoverage, len(files))
+	for i, file := range files {
+		if abs, err := filepath.Abs(file); err == nil && data[abs] != nil {
+			matched[i] = data[abs]
+			continue
+		}
+		clean := filepath.ToSlash(filepath.Clean(file))
+		var candidates []string
+		for _, candidate := range byName[filepath.Base(file)] {
+			slashed := filepath.ToSlash(candidate)
+			if slashed == clean || strings.HasSuffix(slashed, "/"+strings.TrimPrefix(clean, "../")) {
+				candidates = append(candidates, candidate)
+			}
+		}
+		if len(candidates) == 1 {
+			matched[i] = data[candidates[0]]
+		}
+	}
+	return matched
+}
+
+// joinCoverage computes the coverage of every function from the lines it
+// spans, and totals the instrumented lines per directory. Untested functions
+// below the complexity threshold are left out, and at most top are listed.
+func joinCoverage(files []string, functions [][]registry.Function, coverage []LineCoverage, threshold, top int) *CoverageReport {
+	report := &CoverageReport{Files: len(files), Complexity: threshold}
+	modules := make(map[string]*ModuleCoverage)
+
+	for i, file := range files {
+		shown := utils.DisplayPath(file)
+		dir := filepath.Dir(shown)
+		module, ok := modules[dir]
+		if !ok {
+			module = &ModuleCoverage{Module: dir}
+			modules[dir] = module
+		}
+		module.Files++
+
+		lines := coverage[i]
+		if lines == nil {
+			report.FilesWithoutData++
+		}
+		for _, count := range lines {
+			module.Lines++
+			if count > 0 {
+				module.CoveredLines++
+			}
+		}
+
+		for _, fn := range functions[i] {
+			if fn.Metadata["declaration"] == "true" || fn.IsTest {
+				continue
+			}
+			result := FunctionCoverage{Name: fn.Name, File: shown, Line: fn.Line, Complexity: fn.Complexity, Instrumented: lines != nil}
+			for line := fn.Line; line < fn.Line+max(fn.Size, 1); line++ {
+				if count, ok := lines[line]; ok {
+					result.Lines++
+					if count > 0 {
+						result.CoveredLines++
+					}
+				}
+			}
+			result.Coverage = percentage(result.CoveredLines, result.Lines)
+
+			report.Functions++
+			module.Functions++
+			if result.CoveredLines > 0 {
+				report.TestedFunctions++
+				continue
+			}
+			module.UntestedFunctions++
+			if fn.Complexity >= threshold {
+				report.Untested = append(report.Untested, result)
+			}
+		}
+	}
+
+	for _, module := range modules {
+		module.Coverage = percentage(module.CoveredLines, module.Lines)
+		report.Lines += module.Lines
+		report.CoveredLines += module.CoveredLines
+		report.Modules = append(report.Modules, *module)
+	}
+	report.Coverage = percentage(report.CoveredLines, report.Lines)
+
+	// Least covered directories first, those without data last
+	sort.Slice(report.Modules, func(i, j int) bool {
+		mi, mj := report.Modules[i], report.Modules[j]
+		if (mi.Lines == 0) != (mj.Lines == 0) {
+			return mj.Lines == 0
+		}
+		if mi.Coverage != mj.Coverage {
+			return mi.Coverage < mj.Coverage
+		}
+		return mi.Module < mj.Module
+	})
+	sort.SliceStable(report.Untested, func(i, j int) bool {
+		if report.Untested[i].Complexity != report.Untested[j].Complexity {
+			return report.Untested[i].Complexity > report.Untested[j].Complexity
+		}
+		if report.Untested[i].File != report.Untested[j].File {
+			return report.Untested[i].File < report.Untested[j].File
+		}
+		return report.Untested[i].Line < report.Untested[j].Line
+	})
+	if top > 0 && len(report.Untested) > top {
+		report.Untested = report.Untested[:top]
+	}
+
+	return report
+}
+
+func writeCoverage(report *CoverageReport) error {
+	format := coverageFormat
+	if format == "" {
+		format = "markdown"
+		switch filepath.Ext(coverageOutputFile) {
+		case ".json":
+			format = "json"
+		case ".html", ".htm":
+			format = "html"
+		}
+	}
+
+	var output []byte
+	var err error
+
+	switch format {
+	case "markdown", "md":
+		output = []byte(formatCoverage(report))
+	case "html":
+		var buf bytes.Buffer
+		err = coverageTemplate.Execute(&buf, report)
+		output = buf.Bytes()
+	case "json":
+		output, err = json.MarshalIndent(report, "", "  ")
+		output = append(output, '\n')
+	default:
+		return fmt.Errorf("unsupported format: %s (expected markdown, html or json)", format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if coverageOutputFile != "" {
+		runManifest.AddResult(coverageOutputFile, output)
+		return os.WriteFile(coverageOutputFile, output, 0644)
+	}
+
+	runManifest.AddResult("stdout", output)
+	fmt.Print(string(output))
+	return nil
+}
+
+func formatCoverage(report *CoverageReport) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Coverage\n\n")
+	sb.WriteString(fmt.Sprintf("%s coverage from %s joined with %s scanned files.\n\n", report.InputFormat, report.Input, utils.FormatCount(report.Files)))
+	sb.WriteString(fmt.Sprintf("- **Line Coverage**: %.1f%% (%s of %s lines)\n", report.Coverage, utils.FormatCount(report.CoveredLines), utils.FormatCount(report.Lines)))
+	if report.MinCoverage > 0 {
+		status := "passed"
+		if report.Coverage < report.MinCoverage {
+			status = "failed"
+		}
+		sb.WriteString(fmt.Sprintf("- **Minimum**: %.1f%% (%s)\n", report.MinCoverage, status))
+	}
+	sb.WriteString(fmt.Sprintf("- **Tested Functions**: %s of %s (%.1f%%)\n", utils.FormatCount(report.TestedFunctions), utils.FormatCount(report.Functions),
+		percentage(report.TestedFunctions, report.Functions)))
+	sb.WriteString(fmt.Sprintf("- **Files Without Coverage Data**: %s\n", utils.FormatCount(report.FilesWithoutData)))
+
+	sb.WriteString(fmt.Sprintf("\n## Untested Complex Functions (complexity %d or more)\n\n", report.Complexity))
+	if len(report.Untested) == 0 {
+		sb.WriteString("None.\n")
+	} else {
+		sb.WriteString("| # | Function | Location | Complexity | Instrumented Lines |\n")
+		sb.WriteString("|---|----------|----------|------------|--------------------|\n")
+		for i, fn := range report.Untested {
+			lines := strconv.Itoa(fn.Lines)
+			if !fn.Instrumented {
+				lines = "no data"
+			}
+			sb.WriteString(fmt.Sprintf("| %d | `%s` | %s:%d | %d | %s |\n", i+1, fn.Name, fn.File, fn.Line, fn.Complexity, lines))
+		}
+	}
+
+	sb.WriteString("\n## Coverage by Directory\n\n")
+	sb.WriteString("| Directory | Files | Functions | Untested | Lines | Covered | Coverage |\n")
+	sb.WriteString("|-----------|-------|-----------|----------|-------|---------|----------|\n")
+	for _, module := range report.Modules {
+		coverage := fmt.Sprintf("%.1f%%", module.Coverage)
+		if module.Lines == 0 {
+			coverage = "no data"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %s | %s | %s |\n", module.Module, module.Files, module.Functions,
+			module.UntestedFunctions, utils.FormatCount(module.Lines), utils.FormatCount(module.CoveredLines), coverage))
+	}
+
+	return sb.String()
+}
+
+var coverageTemplate = template.Must(template.New("coverage").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Coverage</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: left; }
+th { background: #f0f0f0; }
+td.number { text-align: right; }
+.failed { color: #b00020; font-weight: bold; }
+.passed { color: #1b7f3b; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>Coverage</h1>
+<p>{{.InputFormat}} coverage from <code>{{.Input}}</code> joined with {{.Files}} scanned files.</p>
+<ul>
+<li><strong>Line Coverage</strong>: {{printf "%.1f" .Coverage}}% ({{.CoveredLines}} of {{.Lines}} lines)</li>
+{{- if gt .MinCoverage 0.0}}
+<li><strong>Minimum</strong>: {{printf "%.1f" .MinCoverage}}% {{if lt .Coverage .MinCoverage}}<span class="failed">failed</span>{{else}}<span class="passed">passed</span>{{end}}</li>
+{{- end}}
+<li><strong>Tested Functions</strong>: {{.TestedFunctions}} of {{.Functions}}</li>
+<li><strong>Files Without Coverage Data</strong>: {{.FilesWithoutData}}</li>
+</ul>
+<h2>Untested Complex Functions (complexity {{.Complexity}} or more)</h2>
+{{- if .Untested}}
+<table>
+<tr><th>#</th><th>Function</th><th>Location</th><th>Complexity</th><th>Instrumented Lines</th></tr>
+{{- range $i, $fn := .Untested}}
+<tr><td class="number">{{inc $i}}</td><td><code>{{$fn.Name}}</code></td><td>{{$fn.File}}:{{$fn.Line}}</td><td class="number">{{$fn.Complexity}}</td><td class="number">{{if $fn.Instrumented}}{{$fn.Lines}}{{else}}no data{{end}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>None.</p>
+{{- end}}
+<h2>Coverage by Directory</h2>
+<table>
+<tr><th>Directory</th><th>Files</th><th>Functions</th><th>Untested</th><th>Lines</th><th>Covered</th><th>Coverage</th></tr>
+{{- range .Modules}}
+<tr><td>{{.Module}}</td><td class="number">{{.Files}}</td><td class="number">{{.Functions}}</td><td class="number">{{.UntestedFunctions}}</td><td class="number">{{.Lines}}</td><td class="number">{{.CoveredLines}}</td><td class="number">{{if .Lines}}{{printf "%.1f" .Coverage}}%{{else}}no data{{end}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
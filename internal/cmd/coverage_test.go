@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vitruves/gop/internal/registry"
+)
+
+func TestParseCoverage(t *testing.T) {
+	lcov := `TN:unit
+SF:/build/project/src/parse.c
+FN:3,parse
+FNDA:2,parse
+DA:3,2
+DA:4,2
+DA:6,0
+end_of_record
+TN:integration
+SF:/build/project/src/parse.c
+DA:6,1
+DA:7,0
+end_of_record
+`
+	data, err := parseLcov(strings.NewReader(lcov))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := LineCoverage{3: 2, 4: 2, 6: 1, 7: 0}
+	if got := data[filepath.Clean("/build/project/src/parse.c")]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected lcov lines %v, got %v", want, got)
+	}
+
+	// A function on lines 3-8 with a branch on line 5 taken once out of 4
+	export := `{"type": "llvm.coverage.json.export", "version": "2.0.1", "data": [{"files": [{
+		"filename": "/build/project/src/parse.c",
+		"segments": [[3, 20, 4, true, true, false], [5, 12, 0, true, true, false], [5, 30, 4, true, false, false], [6, 5, 1, true, true, false], [7, 6, 4, true, false, false], [8, 2, 0, false, false, false]]
+	}]}]}`
+	data, err = parseLLVMCoverage([]byte(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = LineCoverage{3: 4, 4: 4, 5: 4, 6: 4, 7: 1, 8: 4}
+	if got := data[filepath.Clean("/build/project/src/parse.c")]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected llvm-cov lines %v, got %v", want, got)
+	}
+
+	if _, err := parseLLVMCoverage([]byte(`{"type": "other"}`)); err == nil {
+		t.Error("Expected an error for a JSON file that is not an llvm-cov export")
+	}
+}
+
+func TestJoinCoverage(t *testing.T) {
+	files := []string{filepath.Join("src", "parse.c"), filepath.Join("src", "util", "str.c"), filepath.Join("src", "gen.c")}
+	data := map[string]LineCoverage{
+		"/build/project/src/parse.c":    {2: 3, 3: 3, 4: 0, 10: 0, 11: 0, 12: 0},
+		"/build/project/src/util/str.c": {1: 1},
+		"/build/other/parse.c":          {1: 0},
+	}
+	functions := [][]registry.Function{
+		{
+			{Name: "parse", Line: 1, Size: 5, Complexity: 12},
+			{Name: "parse_slow", Line: 10, Size: 4, Complexity: 15},
+			{Name: "parse_small", Line: 20, Size: 2, Complexity: 1},
+		},
+		{{Name: "str_len", Line: 1, Size: 3, Complexity: 2}},
+		{{Name: "generate", Line: 1, Size: 50, Complexity: 30}},
+	}
+
+	coverage := matchCoverage(files, data)
+	if coverage[2] != nil {
+		t.Errorf("Expected no coverage for %s, got %v", files[2], coverage[2])
+	}
+
+	report := joinCoverage(files, functions, coverage, 10, 0)
+
+	if report.Lines != 7 || report.CoveredLines != 3 || report.FilesWithoutData != 1 {
+		t.Errorf("Expected 3 of 7 lines covered and 1 file without data, got %d of %d and %d", report.CoveredLines, report.Lines, report.FilesWithoutData)
+	}
+	if report.Functions != 5 || report.TestedFunctions != 2 {
+		t.Errorf("Expected 2 of 5 functions tested, got %d of %d", report.TestedFunctions, report.Functions)
+	}
+
+	var untested []string
+	for _, fn := range report.Untested {
+		untested = append(untested, fn.Name)
+	}
+	if want := []string{"generate", "parse_slow"}; !reflect.DeepEqual(untested, want) {
+		t.Errorf("Expected untested complex functions %v, got %v", want, untested)
+	}
+	if report.Untested[0].Instrumented || !report.Untested[1].Instrumented {
+		t.Error("Expected only the function of the file without data to be uninstrumented")
+	}
+
+	var modules []string
+	for _, module := range report.Modules {
+		modules = append(modules, module.Module)
+	}
+	if want := []string{"src", filepath.Join("src", "util")}; !reflect.DeepEqual(modules, want) {
+		t.Errorf("Expected modules %v, got %v", want, modules)
+	}
+}
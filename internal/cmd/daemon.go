@@ -0,0 +1,373 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// DaemonConfig is the configuration file of gop daemon. It lists projects
+// like the projects file of gop multi, the current directory when none are
+// listed; flags given on the command line take precedence.
+type DaemonConfig struct {
+	Analyzers []string       `yaml:"analyzers"`
+	Projects  []ProjectEntry `yaml:"projects"`
+	Interval  string         `yaml:"interval"`
+	Listen    string         `yaml:"listen"`
+	History   string         `yaml:"history"`
+	// Keep is the number of runs kept in the history, 0 for all
+	Keep int `yaml:"keep"`
+}
+
+// DaemonRun is one scheduled analysis, as kept in the history and served on
+// /report/latest.
+type DaemonRun struct {
+	Date     string  `json:"date"`
+	Duration float64 `json:"duration_seconds"`
+	MultiReport
+}
+
+var (
+	daemonConfigFile string
+	daemonInterval   string
+	daemonListen     string
+	daemonHistory    string
+	daemonKeep       int
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run analyses on a schedule and serve their results over HTTP",
+	Long: `Run the analyzers of gop multi over the configured projects every --interval, append each
+run to a JSON history file and serve the results: /metrics in the Prometheus text format and
+/report/latest as JSON. The latest run of the history is served until the first run ends.`,
+	Args: cobra.NoArgs,
+	RunE: runDaemon,
+}
+
+func init() {
+	daemonCmd.Flags().StringVar(&daemonConfigFile, "config", ".gop.yaml", "YAML file with the projects, analyzers and settings, optional unless given")
+	daemonCmd.Flags().StringVar(&daemonInterval, "interval", "1h", "Time between analyses (e.g. 30m, 1h, 1d)")
+	daemonCmd.Flags().StringVar(&daemonListen, "listen", ":9090", "Address the HTTP endpoint listens on")
+	daemonCmd.Flags().StringVar(&daemonHistory, "history", ".gop-history.json", "JSON file the runs are appended to, empty to keep no history")
+	daemonCmd.Flags().IntVar(&daemonKeep, "keep", 0, "Number of runs kept in the history, 0 for all")
+}
+
+func runDaemon(cmd *cobra.Command, args []string) error {
+	config, err := loadDaemonConfig(daemonConfigFile, cmd.Flags().Changed("config"))
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read %s: %v", daemonConfigFile, err))
+		return err
+	}
+	overrides := []struct {
+		flag   string
+		value  *string
+		config *string
+	}{
+		{"interval", &daemonInterval, &config.Interval},
+		{"listen", &daemonListen, &config.Listen},
+		{"history", &daemonHistory, &config.History},
+	}
+	for _, o := range overrides {
+		if cmd.Flags().Changed(o.flag) || *o.config == "" {
+			*o.config = *o.value
+		}
+	}
+	if cmd.Flags().Changed("keep") || config.Keep == 0 {
+		config.Keep = daemonKeep
+	}
+
+	interval, err := utils.ParseDuration(config.Interval)
+	if err != nil || interval <= 0 {
+		return fmt.Errorf("invalid interval: %q (e.g. 30m, 1h, 1d)", config.Interval)
+	}
+	if len(config.Analyzers) == 0 {
+		config.Analyzers = []string{"stats", "functions", "placeholders"}
+	}
+	enabled, err := enabledAnalyzers(config.Analyzers)
+	if err != nil {
+		return err
+	}
+
+	d := &daemon{config: config, enabled: enabled}
+	if config.History != "" {
+		// A history kept inside a project is not one of its source files
+		historyPath, _ := filepath.Abs(config.History)
+		for _, project := range config.Projects {
+			root, _ := filepath.Abs(project.Path)
+			if rel, err := filepath.Rel(root, historyPath); err == nil && !strings.HasPrefix(rel, "..") {
+				exclude = append(exclude, rel, rel+".tmp")
+			}
+		}
+
+		history, err := readDaemonHistory(config.History)
+		if err != nil {
+			return err
+		}
+		if len(history) > 0 {
+			d.latest = &history[len(history)-1]
+		}
+	}
+
+	listener, err := net.Listen("tcp", config.Listen)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: d.handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logError(fmt.Sprintf("HTTP server stopped: %v", err))
+		}
+	}()
+	logSuccess(fmt.Sprintf("Serving /metrics and /report/latest on %s, analyzing %d projects every %s",
+		listener.Addr(), len(config.Projects), utils.HumanDuration(interval)))
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			d.analyze()
+			<-ticker.C
+		}
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+
+	logInfo("Shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(ctx)
+}
+
+// loadDaemonConfig reads the configuration file. A missing file is only an
+// error when it was asked for.
+func loadDaemonConfig(path string, required bool) (*DaemonConfig, error) {
+	config := &DaemonConfig{}
+	data, err := os.ReadFile(path)
+	if err != nil && (required || !errors.Is(err, os.ErrNotExist)) {
+		return nil, err
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(config.Projects) == 0 {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		config.Projects = []ProjectEntry{{Name: filepath.Base(wd), Path: wd}}
+	}
+	if err := resolveProjects(config.Projects, filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+type daemon struct {
+	config  *DaemonConfig
+	enabled map[string]bool
+
+	mu     sync.RWMutex
+	latest *DaemonRun
+	runs   int
+}
+
+// analyze runs the analyzers over every project, one at a time, and records
+// the run.
+func (d *daemon) analyze() {
+	start := time.Now()
+	logInfo(fmt.Sprintf("Analyzing %d projects", len(d.config.Projects)))
+	// Each run reports the files it skipped itself
+	failures.Reset()
+
+	run := &DaemonRun{
+		Date: start.Format(time.RFC3339),
+		MultiReport: MultiReport{
+			Analyzers: d.config.Analyzers,
+			Projects:  make([]ProjectSummary, len(d.config.Projects)),
+		},
+	}
+	for i, project := range d.config.Projects {
+		run.Projects[i] = summarizeProject(project, d.enabled, true)
+	}
+	run.Total = totalSummary(run.Projects)
+	run.Duration = time.Since(start).Seconds()
+
+	d.mu.Lock()
+	d.latest = run
+	d.runs++
+	d.mu.Unlock()
+
+	if d.config.History != "" {
+		if err := appendDaemonHistory(d.config.History, *run, d.config.Keep); err != nil {
+			logError(fmt.Sprintf("Failed to update history: %v", err))
+		}
+	}
+
+	failed := 0
+	for _, project := range run.Projects {
+		if project.Error != "" {
+			failed++
+			logWarning(fmt.Sprintf("%s: %s", project.Name, project.Error))
+		}
+	}
+	if skipped, total := failures.Counts(); skipped > 0 {
+		logWarning(fmt.Sprintf("%s of %s files skipped due to errors", utils.FormatCount(skipped), utils.FormatCount(total)))
+	}
+	logSuccess(fmt.Sprintf("Analyzed %d projects in %s", len(run.Projects)-failed, utils.HumanDuration(time.Since(start))))
+}
+
+func (d *daemon) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		d.mu.RLock()
+		defer d.mu.RUnlock()
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		fmt.Fprint(w, formatMetrics(d.latest, d.enabled, d.runs))
+	})
+	mux.HandleFunc("/report/latest", func(w http.ResponseWriter, r *http.Request) {
+		d.mu.RLock()
+		defer d.mu.RUnlock()
+		if d.latest == nil {
+			http.Error(w, "no analysis has finished yet", http.StatusServiceUnavailable)
+			return
+		}
+		output, err := json.MarshalIndent(d.latest, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(append(output, '\n'))
+	})
+	return mux
+}
+
+// formatMetrics writes the latest run in the Prometheus text format, one
+// gauge per summary value labeled by project. runs counts the analyses of
+// this process.
+func formatMetrics(run *DaemonRun, enabled map[string]bool, runs int) string {
+	var sb strings.Builder
+	write := func(name, kind, help string, samples func(func(labels string, value float64))) {
+		sb.WriteString(fmt.Sprintf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind))
+		samples(func(labels string, value float64) {
+			sb.WriteString(fmt.Sprintf("%s%s %v\n", name, labels, value))
+		})
+	}
+
+	write("gop_runs_total", "counter", "Analyses finished since the daemon started.", func(sample func(string, float64)) {
+		sample("", float64(runs))
+	})
+	if run == nil {
+		return sb.String()
+	}
+
+	if date, err := time.Parse(time.RFC3339, run.Date); err == nil {
+		write("gop_last_run_timestamp_seconds", "gauge", "Start of the latest analysis.", func(sample func(string, float64)) {
+			sample("", float64(date.Unix()))
+		})
+	}
+	write("gop_last_run_duration_seconds", "gauge", "Duration of the latest analysis.", func(sample func(string, float64)) {
+		sample("", run.Duration)
+	})
+
+	gauges := []struct {
+		analyzer string
+		name     string
+		help     string
+		value    func(ProjectSummary) float64
+	}{
+		{"", "gop_project_up", "Whether the project could be analyzed.", func(p ProjectSummary) float64 {
+			if p.Error != "" {
+				return 0
+			}
+			return 1
+		}},
+		{"stats", "gop_files", "Source files.", func(p ProjectSummary) float64 { return float64(p.Files) }},
+		{"stats", "gop_lines", "Lines of the source files.", func(p ProjectSummary) float64 { return float64(p.Lines) }},
+		{"stats", "gop_code_lines", "Lines of code, without blanks and comments.", func(p ProjectSummary) float64 { return float64(p.CodeLines) }},
+		{"functions", "gop_functions", "Functions.", func(p ProjectSummary) float64 { return float64(p.Functions) }},
+		{"functions", "gop_complexity_total", "Sum of the cyclomatic complexity of the functions.", func(p ProjectSummary) float64 { return float64(p.TotalComplexity) }},
+		{"functions", "gop_complexity_average", "Average cyclomatic complexity of the functions.", func(p ProjectSummary) float64 { return p.AvgComplexity }},
+		{"placeholders", "gop_placeholders", "Placeholders such as TODO comments.", func(p ProjectSummary) float64 { return float64(p.Placeholders) }},
+	}
+	for _, gauge := range gauges {
+		if gauge.analyzer != "" && !enabled[gauge.analyzer] {
+			continue
+		}
+		write(gauge.name, "gauge", gauge.help, func(sample func(string, float64)) {
+			for _, project := range run.Projects {
+				if gauge.analyzer != "" && project.Error != "" {
+					continue
+				}
+				sample(fmt.Sprintf(`{project="%s"}`, escapeLabel(project.Name)), gauge.value(project))
+			}
+		})
+	}
+	return sb.String()
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func readDaemonHistory(path string) ([]DaemonRun, error) {
+	var history []DaemonRun
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(content) > 0 {
+		if err := json.Unmarshal(content, &history); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return history, nil
+}
+
+// appendDaemonHistory adds run to the history file, dropping the oldest runs
+// beyond keep. The file is replaced in one step so a reader never sees it
+// half written.
+func appendDaemonHistory(path string, run DaemonRun, keep int) error {
+	history, err := readDaemonHistory(path)
+	if err != nil {
+		return err
+	}
+	history = append(history, run)
+	if keep > 0 && len(history) > keep {
+		history = history[len(history)-keep:]
+	}
+
+	output, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, append(output, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(temp, path)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormatMetrics(t *testing.T) {
+	run := &DaemonRun{
+		Date:     "2024-05-01T10:00:00Z",
+		Duration: 2.5,
+		MultiReport: MultiReport{Projects: []ProjectSummary{
+			{Name: `core "lib"`, Files: 12, CodeLines: 800, Placeholders: 3},
+			{Name: "broken", Error: "broken is not a directory"},
+		}},
+	}
+
+	metrics := formatMetrics(run, map[string]bool{"stats": true, "placeholders": true}, 4)
+
+	for _, want := range []string{
+		"gop_runs_total 4\n",
+		"gop_last_run_timestamp_seconds 1.7145576e+09\n",
+		"# TYPE gop_code_lines gauge\n",
+		`gop_code_lines{project="core \"lib\""} 800` + "\n",
+		`gop_placeholders{project="core \"lib\""} 3` + "\n",
+		`gop_project_up{project="broken"} 0` + "\n",
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("Expected %q in metrics:\n%s", want, metrics)
+		}
+	}
+	if strings.Contains(metrics, "gop_functions") {
+		t.Errorf("Metrics of analyzers that did not run should be left out:\n%s", metrics)
+	}
+	if strings.Contains(metrics, `gop_files{project="broken"}`) {
+		t.Errorf("Projects that failed should only report gop_project_up:\n%s", metrics)
+	}
+}
+
+func TestAppendDaemonHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+
+	for _, date := range []string{"2024-05-01", "2024-05-02", "2024-05-03"} {
+		if err := appendDaemonHistory(path, DaemonRun{Date: date}, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err := readDaemonHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Date != "2024-05-02" || history[1].Date != "2024-05-03" {
+		t.Errorf("Expected the two latest runs, got %+v", history)
+	}
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/docs"
+)
+
+var (
+	docsOutputDir string
+	docsFormats   []string
+)
+
+var docsCLICmd = &cobra.Command{
+	Use:   "docs-cli",
+	Short: "Generate man pages and a markdown reference for all commands",
+	Long: `Generate a man page and a markdown page for every command and its flags, for packaging
+with releases. Set SOURCE_DATE_EPOCH for reproducible output.`,
+	Args: cobra.NoArgs,
+	RunE: runDocsCLI,
+}
+
+func init() {
+	docsCLICmd.Flags().StringVarP(&docsOutputDir, "output", "o", "docs/cli", "Output directory, pages go to man/man1 and markdown below it")
+	docsCLICmd.Flags().StringSliceVarP(&docsFormats, "format", "f", []string{"man", "markdown"}, "Formats to generate (man, markdown)")
+}
+
+func runDocsCLI(cmd *cobra.Command, args []string) error {
+	config := docs.Config{
+		Root:      rootCmd,
+		OutputDir: docsOutputDir,
+		Formats:   docsFormats,
+		Version:   buildInfo().Version,
+		Verbose:   verbose,
+	}
+
+	return docs.Run(config)
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/doctor"
+)
+
+var doctorJSON bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor [directory]",
+	Short: "Check the environment and project for problems that affect gop",
+	Long: `Check the tools gop relies on (Graphviz, git, terminal capabilities) and walk the project
+for directories skipped by accident, unreadable files, and files in unknown or mixed encodings.
+Every problem comes with a remediation step. Exits with an error when a check fails.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "Print the checks as JSON")
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	config := doctor.Config{
+		Language:      language,
+		Exclude:       exclude,
+		SkipVendor:    skipVendor,
+		IncludeHidden: includeHidden,
+		MaxFileSize:   maxFileSizeBytes,
+		JSON:          doctorJSON,
+	}
+	if len(args) > 0 {
+		config.Root = args[0]
+	}
+
+	return doctor.Run(config)
+}
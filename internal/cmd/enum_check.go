@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/enumcheck"
+)
+
+var (
+	enumCheckOutputFile string
+	enumCheckFormat     string
+	enumCheckTemplate   string
+
+	enumCheckFindings findingFlags
+)
+
+var enumCheckCmd = &cobra.Command{
+	Use:   "enum-check",
+	Short: "Find switch statements that miss enumerators of an enum",
+	Long: `Collect the enumerators of every C/C++ enum and report switch statements over an enum
+that neither handle all of its enumerators nor have a default case.`,
+	RunE: runEnumCheck,
+}
+
+func init() {
+	enumCheckCmd.Flags().StringVarP(&enumCheckOutputFile, "output", "o", "", "Output file")
+	enumCheckCmd.Flags().StringVarP(&enumCheckFormat, "format", "f", "text", "Output format (text, checkstyle, codeclimate, json, sarif)")
+	enumCheckCmd.Flags().StringVar(&enumCheckTemplate, "template", "", "Go text/template file rendering the findings, replaces --format")
+	addFindingFilterFlags(enumCheckCmd, &enumCheckFindings, false)
+}
+
+func runEnumCheck(cmd *cobra.Command, args []string) error {
+	config := enumcheck.Config{
+		Language:       language,
+		Include:        include,
+		Exclude:        exclude,
+		Recursive:      recursive,
+		Depth:          depth,
+		Jobs:           jobs,
+		Verbose:        verbose,
+		OutputFile:     enumCheckOutputFile,
+		Format:         enumCheckFormat,
+		Template:       enumCheckTemplate,
+		SkipGenerated:  skipGenerated,
+		SkipVendor:     skipVendor,
+		MaxFileSize:    maxFileSizeBytes,
+		FollowSymlinks: followSymlinks,
+		IncludeHidden:  includeHidden,
+		NoProgress:     noProgress,
+		Manifest:       runManifest,
+	}
+
+	return enumcheck.Run(config)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/errorcheck"
+)
+
+var (
+	errorCheckFunctions     []string
+	errorCheckFunctionsFile string
+	errorCheckOutputFile    string
+	errorCheckFormat        string
+
+	errorCheckFindings findingFlags
+)
+
+var errorCheckCmd = &cobra.Command{
+	Use:   "error-check",
+	Short: "Find ignored return values and swallowed exceptions in C/C++",
+	Long: `Report calls whose result is dropped for functions that must be checked (malloc, fopen,
+pthread_* and any project-specific functions), project functions returning an error code
+that none of their callers check, and empty catch blocks in C++.`,
+	RunE: runErrorCheck,
+}
+
+func init() {
+	errorCheckCmd.Flags().StringSliceVar(&errorCheckFunctions, "functions", nil, "Additional functions whose result must be checked (glob patterns allowed)")
+	errorCheckCmd.Flags().StringVar(&errorCheckFunctionsFile, "functions-file", "", "File listing additional functions, one per line")
+	errorCheckCmd.Flags().StringVarP(&errorCheckOutputFile, "output", "o", "", "Output file")
+	errorCheckCmd.Flags().StringVarP(&errorCheckFormat, "format", "f", "text", "Output format (text, checkstyle, codeclimate, json, sarif)")
+	addFindingFilterFlags(errorCheckCmd, &errorCheckFindings, true)
+}
+
+func runErrorCheck(cmd *cobra.Command, args []string) error {
+	config := errorcheck.Config{
+		Language:       language,
+		Include:        include,
+		Exclude:        exclude,
+		Recursive:      recursive,
+		Depth:          depth,
+		Jobs:           jobs,
+		Verbose:        verbose,
+		Functions:      errorCheckFunctions,
+		FunctionsFile:  errorCheckFunctionsFile,
+		OutputFile:     errorCheckOutputFile,
+		Format:         errorCheckFormat,
+		SkipGenerated:  skipGenerated,
+		SkipVendor:     skipVendor,
+		MaxFileSize:    maxFileSizeBytes,
+		FollowSymlinks: followSymlinks,
+		IncludeHidden:  includeHidden,
+		NoProgress:     noProgress,
+		Manifest:       runManifest,
+	}
+
+	return errorcheck.Run(config)
+}
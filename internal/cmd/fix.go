@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/fix"
+)
+
+var (
+	fixRules       []string
+	fixList        bool
+	fixDryRun      bool
+	fixBackup      bool
+	fixIncludeDirs []string
+)
+
+var fixCmd = &cobra.Command{
+	Use:   "fix",
+	Short: "Apply mechanical fixes to C/C++ code",
+	Long: `Find mechanical problems in C/C++ code and apply the edits that fix them: bounded copies
+instead of strcpy, missing include guards, repeated and unused includes. Run with --list
+for the available rules and --dry-run to review the changes as a unified diff first.`,
+	RunE: runFix,
+}
+
+func init() {
+	fixCmd.Flags().StringSliceVar(&fixRules, "rule", nil, "Rules to apply, \"all\" for every rule (see --list)")
+	fixCmd.Flags().BoolVar(&fixList, "list", false, "List the available rules")
+	fixCmd.Flags().BoolVar(&fixDryRun, "dry-run", false, "Show the changes as a unified diff without writing anything")
+	fixCmd.Flags().BoolVar(&fixBackup, "backup", false, "Keep a .bak copy of every modified file")
+	fixCmd.Flags().StringSliceVarP(&fixIncludeDirs, "include-dir", "I", nil, "Include search directories used to resolve includes (default: current directory)")
+}
+
+func runFix(cmd *cobra.Command, args []string) error {
+	config := fix.Config{
+		Rules:          fixRules,
+		List:           fixList,
+		DryRun:         fixDryRun,
+		Backup:         fixBackup,
+		IncludeDirs:    fixIncludeDirs,
+		Include:        include,
+		Exclude:        exclude,
+		Recursive:      recursive,
+		Depth:          depth,
+		Verbose:        verbose,
+		SkipGenerated:  skipGenerated,
+		SkipVendor:     skipVendor,
+		MaxFileSize:    maxFileSizeBytes,
+		FollowSymlinks: followSymlinks,
+		IncludeHidden:  includeHidden,
+		Manifest:       runManifest,
+	}
+
+	return fix.Run(config)
+}
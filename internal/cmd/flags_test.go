@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/vitruves/gop/internal/config"
+	"github.com/vitruves/gop/internal/worker"
+)
+
+// parseArgs resolves the subcommand and parses its flags the way Execute
+// does, restoring every flag to its default when the test ends.
+func parseArgs(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+
+	command, rest, err := rootCmd.Find(args)
+	if err != nil {
+		t.Fatalf("Failed to find command for %v: %v", args, err)
+	}
+	t.Cleanup(func() { resetFlags(command) })
+
+	if err := command.ParseFlags(rest); err != nil {
+		t.Fatalf("Failed to parse %v: %v", args, err)
+	}
+	return command
+}
+
+// runCommand runs a subcommand with its flags the way Execute does, and
+// returns the error Execute would turn into the exit status.
+func runCommand(t *testing.T, args ...string) error {
+	t.Helper()
+
+	command := parseArgs(t, args...)
+	if err := rootCmd.PersistentPreRunE(command, command.Flags().Args()); err != nil {
+		return err
+	}
+	return command.RunE(command, command.Flags().Args())
+}
+
+// captureStdout returns what fn prints to stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+
+	defer func() { os.Stdout = saved }()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func resetFlags(command *cobra.Command) {
+	command.Flags().VisitAll(func(flag *pflag.Flag) {
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			// Slice defaults print as [a,b]
+			var values []string
+			if defaults := strings.Trim(flag.DefValue, "[]"); defaults != "" {
+				values = strings.Split(defaults, ",")
+			}
+			slice.Replace(values)
+		} else {
+			flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	})
+}
+
+func TestParseGlobalFlags(t *testing.T) {
+	command := parseArgs(t, "stats", "-R", "-i", "src", "-i", "lib", "-e", "*_gen.go", "-j", "2", "-d", "3",
+		"--max-file-size", "1MB", "--no-progress", "--follow-symlinks")
+
+	if command != statsCmd {
+		t.Fatalf("Expected the stats command, got %s", command.Name())
+	}
+	if !recursive || depth != 3 || jobs != 2 || !noProgress || !followSymlinks {
+		t.Errorf("Unexpected scan flags: recursive=%v depth=%d jobs=%d noProgress=%v followSymlinks=%v",
+			recursive, depth, jobs, noProgress, followSymlinks)
+	}
+	if !reflect.DeepEqual(include, []string{"src", "lib"}) || !reflect.DeepEqual(exclude, []string{"*_gen.go"}) {
+		t.Errorf("Unexpected include/exclude: %v %v", include, exclude)
+	}
+
+	if err := rootCmd.PersistentPreRunE(command, nil); err != nil {
+		t.Fatalf("PersistentPreRunE failed: %v", err)
+	}
+	if maxFileSizeBytes != 1<<20 {
+		t.Errorf("Expected --max-file-size 1MB to be %d bytes, got %d", 1<<20, maxFileSizeBytes)
+	}
+
+	options := scanOptions()
+	if !options.Recursive || options.Depth != 3 || options.MaxFileSize != 1<<20 || !options.SkipVendor {
+		t.Errorf("Unexpected scan options: %+v", options)
+	}
+}
+
+func TestParseInvalidMaxFileSize(t *testing.T) {
+	command := parseArgs(t, "stats", "--max-file-size", "lots")
+
+	if err := rootCmd.PersistentPreRunE(command, nil); err == nil {
+		t.Error("Expected an error for an invalid --max-file-size")
+	}
+}
+
+func TestParseTimeouts(t *testing.T) {
+	t.Cleanup(func() { worker.SetTimeouts(0, 0) })
+
+	// Timeouts take the day, week and year units of --older-than too
+	command := parseArgs(t, "stats", "--file-timeout", "1d")
+	if err := rootCmd.PersistentPreRunE(command, nil); err != nil {
+		t.Fatalf("PersistentPreRunE failed: %v", err)
+	}
+	if runTimeout != 0 || fileTimeout != 24*time.Hour {
+		t.Errorf("Expected no run timeout and a file timeout of a day, got %s and %s", runTimeout, fileTimeout)
+	}
+
+	for _, args := range [][]string{{"--timeout", "-5m"}, {"--timeout", "soon"}, {"--file-timeout", "1x"}} {
+		command := parseArgs(t, append([]string{"stats"}, args...)...)
+		if err := rootCmd.PersistentPreRunE(command, nil); err == nil {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
+
+func TestParseFraction(t *testing.T) {
+	valid := map[string]float64{"0.1": 0.1, "10%": 0.1, "0": 0, "100%": 1, "1": 1}
+	for value, want := range valid {
+		if got, err := parseFraction(value); err != nil || got != want {
+			t.Errorf("parseFraction(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "half", "150%", "-0.1"} {
+		if _, err := parseFraction(value); err == nil {
+			t.Errorf("Expected parseFraction(%q) to fail", value)
+		}
+	}
+}
+
+func TestParseCommandFlags(t *testing.T) {
+	parseArgs(t, "function-registry", "-o", "registry.json", "-f", "jsonl", "--by-script", "--hierarchy")
+	if registryOutputFile != "registry.json" || registryFormat != "jsonl" || !registryByScript || !registryHierarchy {
+		t.Errorf("Unexpected function-registry flags: output=%q format=%q byScript=%v hierarchy=%v",
+			registryOutputFile, registryFormat, registryByScript, registryHierarchy)
+	}
+
+	parseArgs(t, "placeholders", "--top", "20", "--older-than", "90d")
+	if placeholdersTop != 20 || placeholdersOlderThan != "90d" || placeholdersFormat != "text" {
+		t.Errorf("Unexpected placeholders flags: top=%d olderThan=%q format=%q",
+			placeholdersTop, placeholdersOlderThan, placeholdersFormat)
+	}
+
+	// Flags of one command must not leak into another
+	if registryFormat != "jsonl" || statsFormat != "" {
+		t.Errorf("Unexpected formats after parsing placeholders: registry=%q stats=%q", registryFormat, statsFormat)
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	file := &config.File{Profiles: map[string]map[string]any{
+		"legacy": {
+			"max-file-size":   "1MB",
+			"top":             50,
+			"exclude-finding": []any{"debug_print", "exit_call"},
+			"placeholders":    map[string]any{"older-than": "30d", "top": 10},
+		},
+		"typo":    {"older-then": "30d"},
+		"section": {"placeholder": map[string]any{"top": 10}},
+	}}
+
+	command := parseArgs(t, "placeholders", "--older-than", "90d")
+	if err := applyProfile(command, file, "legacy"); err != nil {
+		t.Fatal(err)
+	}
+	// The command line wins over the profile, the command section over the
+	// values for every command
+	if placeholdersOlderThan != "90d" || placeholdersTop != 10 || maxFileSize != "1MB" {
+		t.Errorf("Unexpected flags: olderThan=%q top=%d maxFileSize=%q", placeholdersOlderThan, placeholdersTop, maxFileSize)
+	}
+	if !reflect.DeepEqual(placeholdersFindings.excludes, []string{"debug_print", "exit_call"}) || !command.Flags().Changed("exclude-finding") {
+		t.Errorf("Unexpected --exclude-finding: %v", placeholdersFindings.excludes)
+	}
+
+	for _, name := range []string{"typo", "section"} {
+		if err := applyProfile(command, file, name); err == nil {
+			t.Errorf("Expected profile %s to be rejected", name)
+		}
+	}
+}
+
+func TestParseUnknownFlag(t *testing.T) {
+	command, rest, err := rootCmd.Find([]string{"stats", "--top", "5"})
+	if err != nil {
+		t.Fatalf("Failed to find command: %v", err)
+	}
+	t.Cleanup(func() { resetFlags(command) })
+
+	if err := command.ParseFlags(rest); err == nil {
+		t.Error("Expected stats to reject the placeholders-only --top flag")
+	}
+}
+
+// Merging the persistent flags panics when a command reuses a global
+// shorthand, so every command is parsed once.
+func TestFlagShorthandsDoNotConflict(t *testing.T) {
+	var visit func(command *cobra.Command)
+	visit = func(command *cobra.Command) {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("%s: %v", command.CommandPath(), r)
+				}
+			}()
+			command.Flags().VisitAll(func(*pflag.Flag) {})
+			command.InheritedFlags()
+		}()
+		for _, child := range command.Commands() {
+			visit(child)
+		}
+	}
+	visit(rootCmd)
+}
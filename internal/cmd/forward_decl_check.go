@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/fwddecl"
+)
+
+var (
+	forwardDeclIncludeDirs []string
+	forwardDeclFix         bool
+	forwardDeclDryRun      bool
+	forwardDeclBackup      bool
+	forwardDeclOutputFile  string
+	forwardDeclFormat      string
+
+	forwardDeclFindings findingFlags
+)
+
+var forwardDeclCheckCmd = &cobra.Command{
+	Use:   "forward-decl-check",
+	Short: "Find header includes that a forward declaration could replace",
+	Long: `Follow the include graph of C/C++ headers and report includes a header only needs for
+classes, structs and unions it uses through pointers or references, never by value, by
+member access or through their scope. Such includes can be replaced by forward
+declarations, cutting rebuilds when the included header changes.
+
+With --fix the includes are replaced and the files that relied on the header through
+the removed include get it included directly. Use --dry-run to preview the edits.`,
+	RunE: runForwardDeclCheck,
+}
+
+func init() {
+	forwardDeclCheckCmd.Flags().StringSliceVarP(&forwardDeclIncludeDirs, "include-dir", "I", nil, "Include search directories (default: current directory)")
+	forwardDeclCheckCmd.Flags().BoolVar(&forwardDeclFix, "fix", false, "Replace the includes by forward declarations")
+	forwardDeclCheckCmd.Flags().BoolVar(&forwardDeclDryRun, "dry-run", false, "Show the --fix edits without writing anything")
+	forwardDeclCheckCmd.Flags().BoolVar(&forwardDeclBackup, "backup", false, "Keep a .bak copy of each file --fix edits")
+	forwardDeclCheckCmd.Flags().StringVarP(&forwardDeclOutputFile, "output", "o", "", "Output file")
+	forwardDeclCheckCmd.Flags().StringVarP(&forwardDeclFormat, "format", "f", "text", "Output format (text, checkstyle, codeclimate, json, sarif)")
+	addFindingFilterFlags(forwardDeclCheckCmd, &forwardDeclFindings, false)
+}
+
+func runForwardDeclCheck(cmd *cobra.Command, args []string) error {
+	config := fwddecl.Config{
+		Include:        include,
+		Exclude:        exclude,
+		Recursive:      recursive,
+		Depth:          depth,
+		Jobs:           jobs,
+		Verbose:        verbose,
+		IncludeDirs:    forwardDeclIncludeDirs,
+		Fix:            forwardDeclFix,
+		DryRun:         forwardDeclDryRun,
+		Backup:         forwardDeclBackup,
+		OutputFile:     forwardDeclOutputFile,
+		Format:         forwardDeclFormat,
+		SkipGenerated:  skipGenerated,
+		SkipVendor:     skipVendor,
+		MaxFileSize:    maxFileSizeBytes,
+		FollowSymlinks: followSymlinks,
+		IncludeHidden:  includeHidden,
+		NoProgress:     noProgress,
+		Manifest:       runManifest,
+	}
+
+	return fwddecl.Run(config)
+}
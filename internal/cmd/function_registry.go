@@ -7,10 +7,21 @@ import (
 
 var (
 	registryOutputFile      string
+	registryFormat          string
 	registryByScript        bool
 	registryOnlyHeaderFiles bool
 	registryAddRelations    bool
 	registryOnlyDeadCode    bool
+	registryGroupOverloads  bool
+	registryHierarchy       bool
+	registryMacroMap        string
+	registryTemplate        string
+	registryModules         bool
+	registryTypes           []string
+	registryPerFile         bool
+	registryQualify         string
+	registryPublicOnly      bool
+	registryPublicRoots     []string
 )
 
 var functionRegistryCmd = &cobra.Command{
@@ -22,11 +33,22 @@ including usage, availability (private/public), call relationships, and more.`,
 }
 
 func init() {
-	functionRegistryCmd.Flags().StringVarP(&registryOutputFile, "output", "o", "", "Output file (.md, .txt, .yaml, .json, or .csv)")
+	functionRegistryCmd.Flags().StringVarP(&registryOutputFile, "output", "o", "", "Output file (.md, .txt, .yaml, .json, .jsonl, or .csv)")
+	functionRegistryCmd.Flags().StringVarP(&registryFormat, "format", "f", "", "Output format (text, yaml, json, jsonl, csv, names), defaults to the output file extension")
 	functionRegistryCmd.Flags().BoolVar(&registryByScript, "by-script", false, "Group functions by script/file")
 	functionRegistryCmd.Flags().BoolVar(&registryOnlyHeaderFiles, "only-header-files", false, "For C/C++: only analyze header files")
 	functionRegistryCmd.Flags().BoolVar(&registryAddRelations, "add-relations", false, "Analyze function call relationships")
 	functionRegistryCmd.Flags().BoolVar(&registryOnlyDeadCode, "only-dead-code", false, "Show only unused/dead functions")
+	functionRegistryCmd.Flags().BoolVar(&registryHierarchy, "hierarchy", false, "Nest methods, fields and nested types under their owning class/struct/namespace")
+	functionRegistryCmd.Flags().BoolVar(&registryGroupOverloads, "group-overloads", false, "Group overloads and template specializations under one entry")
+	functionRegistryCmd.Flags().StringVar(&registryTemplate, "template", "", "Go text/template file rendering the registry, replaces --format")
+	functionRegistryCmd.Flags().BoolVar(&registryModules, "modules", false, "Group functions by module, a directory holding a README or CMakeLists.txt, led by its README excerpt")
+	functionRegistryCmd.Flags().StringSliceVar(&registryTypes, "types", nil, "Only list these kinds: function, method, and with --hierarchy class, struct, union, enum, interface, namespace, field, enumerator, or the groups types and members")
+	functionRegistryCmd.Flags().StringVar(&registryMacroMap, "macro-map", "", "YAML file mapping declaration macros to the function signatures they expand to")
+	functionRegistryCmd.Flags().BoolVar(&registryPerFile, "per-file", false, "List one summary per file: lines, function, method and type counts, exported symbols and includes")
+	functionRegistryCmd.Flags().StringVar(&registryQualify, "qualify", "full", "Function names to write: full (ns::Class::method, Type.Method) or none (the bare name)")
+	functionRegistryCmd.Flags().BoolVar(&registryPublicOnly, "public-only", false, "For C/C++: only list the public functions of the headers under --public-root and report the API surface")
+	functionRegistryCmd.Flags().StringArrayVar(&registryPublicRoots, "public-root", registry.DefaultPublicRoots, "Directory holding public headers for --public-only (repeatable)")
 }
 
 func runFunctionRegistry(cmd *cobra.Command, args []string) error {
@@ -39,10 +61,28 @@ func runFunctionRegistry(cmd *cobra.Command, args []string) error {
 		Jobs:            jobs,
 		Verbose:         verbose,
 		OutputFile:      registryOutputFile,
+		Format:          registryFormat,
 		ByScript:        registryByScript,
 		OnlyHeaderFiles: registryOnlyHeaderFiles,
 		AddRelations:    registryAddRelations,
 		OnlyDeadCode:    registryOnlyDeadCode,
+		SkipGenerated:   skipGenerated,
+		SkipVendor:      skipVendor,
+		MaxFileSize:     maxFileSizeBytes,
+		FollowSymlinks:  followSymlinks,
+		IncludeHidden:   includeHidden,
+		NoProgress:      noProgress,
+		Manifest:        runManifest,
+		GroupOverloads:  registryGroupOverloads,
+		Hierarchy:       registryHierarchy,
+		MacroMap:        registryMacroMap,
+		Template:        registryTemplate,
+		Modules:         registryModules,
+		Types:           registryTypes,
+		PerFile:         registryPerFile,
+		Qualify:         registryQualify,
+		PublicOnly:      registryPublicOnly,
+		PublicRoots:     registryPublicRoots,
 	}
 
 	return registry.Run(config)
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gitCommit is one commit of the history with the files it touched, as
+// absolute paths.
+type gitCommit struct {
+	Author string
+	Files  []string
+}
+
+// gitRoot returns the top directory of the git work tree around the current
+// directory.
+func gitRoot() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", fmt.Errorf("not a git repository")
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// gitCommits reads the commits since the given time, or the whole history for
+// a zero time. Authors are mailmap-resolved names.
+func gitCommits(since time.Time) ([]gitCommit, error) {
+	root, err := gitRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	// A marker line starts every commit, its file list follows
+	args := []string{"log", "--format=@@%aN", "--name-only", "--no-renames"}
+	if !since.IsZero() {
+		args = append(args, "--since="+since.Format(time.RFC3339))
+	}
+	log := exec.Command("git", args...)
+	log.Dir = root
+	out, err := log.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []gitCommit
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			commits = append(commits, gitCommit{Author: strings.TrimPrefix(line, "@@")})
+		case line != "" && len(commits) > 0:
+			commit := &commits[len(commits)-1]
+			commit.Files = append(commit.Files, filepath.Join(root, filepath.FromSlash(line)))
+		}
+	}
+	return commits, nil
+}
+
+// gitChurn counts the commits touching each file since the given time, keyed
+// by absolute path.
+func gitChurn(since time.Time) (map[string]int, error) {
+	commits, err := gitCommits(since)
+	if err != nil {
+		return nil, err
+	}
+
+	churn := make(map[string]int)
+	for _, commit := range commits {
+		for _, file := range commit.Files {
+			churn[file]++
+		}
+	}
+	return churn, nil
+}
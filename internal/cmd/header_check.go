@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/headercheck"
+)
+
+var (
+	headerCheckIncludeDirs []string
+	headerCheckCompile     bool
+	headerCheckBuildDir    string
+	headerCheckOutputFile  string
+	headerCheckFormat      string
+
+	headerCheckFindings findingFlags
+)
+
+var headerCheckCmd = &cobra.Command{
+	Use:   "header-check",
+	Short: "Find C/C++ headers that only compile after other headers",
+	Long: `Check that every header is self-contained: that the types it names are declared by the
+header itself or by the headers it includes, so it does not rely on being included after
+others. By default the include graph is followed and the types declared in the scanned
+headers are matched, which needs no compiler.
+
+With --compile each header is compiled on its own with the flags of the closest entry
+of compile_commands.json, and the compiler errors are reported.`,
+	RunE: runHeaderCheck,
+}
+
+func init() {
+	headerCheckCmd.Flags().StringSliceVarP(&headerCheckIncludeDirs, "include-dir", "I", nil, "Include search directories (default: current directory)")
+	headerCheckCmd.Flags().BoolVar(&headerCheckCompile, "compile", false, "Compile every header on its own with the flags of the compilation database")
+	headerCheckCmd.Flags().StringVarP(&headerCheckBuildDir, "build-dir", "p", "", "Directory holding compile_commands.json for --compile (default: build if it has one, otherwise the current directory)")
+	headerCheckCmd.Flags().StringVarP(&headerCheckOutputFile, "output", "o", "", "Output file")
+	headerCheckCmd.Flags().StringVarP(&headerCheckFormat, "format", "f", "text", "Output format (text, checkstyle, codeclimate, json, sarif)")
+	addFindingFilterFlags(headerCheckCmd, &headerCheckFindings, false)
+}
+
+func runHeaderCheck(cmd *cobra.Command, args []string) error {
+	config := headercheck.Config{
+		Include:        include,
+		Exclude:        exclude,
+		Recursive:      recursive,
+		Depth:          depth,
+		Jobs:           jobs,
+		Verbose:        verbose,
+		IncludeDirs:    headerCheckIncludeDirs,
+		Compile:        headerCheckCompile,
+		BuildDir:       headerCheckBuildDir,
+		OutputFile:     headerCheckOutputFile,
+		Format:         headerCheckFormat,
+		SkipGenerated:  skipGenerated,
+		SkipVendor:     skipVendor,
+		MaxFileSize:    maxFileSizeBytes,
+		FollowSymlinks: followSymlinks,
+		IncludeHidden:  includeHidden,
+		NoProgress:     noProgress,
+		Manifest:       runManifest,
+	}
+
+	return headercheck.Run(config)
+}
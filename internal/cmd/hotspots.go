@@ -0,0 +1,559 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/codeowners"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
+)
+
+// Hotspot is one file with the factors that make it risky to change.
+// Factors lists the ones in the top tenth of the project and above its mean.
+type Hotspot struct {
+	File       string   `json:"file"`
+	Score      float64  `json:"score"`
+	CodeLines  int      `json:"code_lines"`
+	Complexity int      `json:"complexity"`
+	Functions  int      `json:"functions"`
+	IncludedBy int      `json:"included_by"`
+	Churn      int      `json:"churn"`
+	Factors    []string `json:"factors,omitempty"`
+	GodFile    bool     `json:"god_file"`
+	Owners     []string `json:"owners,omitempty"`
+}
+
+type HotspotReport struct {
+	// Since is the start of the churn window, empty for the whole history
+	Since    string    `json:"since,omitempty"`
+	Files    int       `json:"files"`
+	GodFiles int       `json:"god_files"`
+	Hotspots []Hotspot `json:"hotspots,omitempty"`
+	// Quadrants replace the ranking with --churn
+	MedianChurn      int        `json:"median_churn,omitempty"`
+	MedianComplexity int        `json:"median_complexity,omitempty"`
+	Quadrants        []Quadrant `json:"quadrants,omitempty"`
+}
+
+// Quadrant groups the files above or below the project medians of churn and
+// complexity, the most changed and most complex ones first.
+type Quadrant struct {
+	Name   string    `json:"name"`
+	Advice string    `json:"advice"`
+	Count  int       `json:"count"`
+	Files  []Hotspot `json:"files"`
+}
+
+// Quadrants in priority order, indexed by high churn (2) plus high complexity (1).
+var quadrants = []struct {
+	index  int
+	name   string
+	advice string
+}{
+	{3, "Refactor first", "Complex code that keeps changing, where refactoring pays off most"},
+	{1, "Complex but stable", "Refactor when work reaches it, changes are rare"},
+	{2, "Changing but simple", "Keep an eye on its complexity as it grows"},
+	{0, "Healthy", "No action needed"},
+}
+
+var hotspotFactors = []struct {
+	name  string
+	value func(Hotspot) int
+}{
+	{"size", func(h Hotspot) int { return h.CodeLines }},
+	{"complexity", func(h Hotspot) int { return h.Complexity }},
+	{"functions", func(h Hotspot) int { return h.Functions }},
+	{"includes", func(h Hotspot) int { return h.IncludedBy }},
+	{"churn", func(h Hotspot) int { return h.Churn }},
+}
+
+// A file with this many factors in the top tenth is a God file.
+const godFileFactors = 3
+
+var hotspotIncludeRegex = regexp.MustCompile(`^\s*#\s*include\s*[<"]([^>"]+)[>"]`)
+
+var (
+	hotspotsOutputFile string
+	hotspotsFormat     string
+	hotspotsTop        int
+	hotspotsSince      string
+	hotspotsChurn      bool
+	hotspotsCodeOwners string
+)
+
+var hotspotsCmd = &cobra.Command{
+	Use:   "hotspots",
+	Short: "Rank files by size, complexity, includes and churn to find God files",
+	Long: `Rank source files by a combined score of code lines, cyclomatic complexity, number of
+functions, how many scanned files include them (C/C++) and how often git history changed them.
+Files in the top tenth of the project for several factors are flagged as God files.
+With --churn, files are instead split into churn vs complexity quadrants to pick
+refactoring priorities.`,
+	RunE: runHotspots,
+}
+
+func init() {
+	hotspotsCmd.Flags().StringVarP(&hotspotsOutputFile, "output", "o", "", "Output file (.md, .json or .csv)")
+	hotspotsCmd.Flags().StringVarP(&hotspotsFormat, "format", "f", "", "Output format (markdown, json, csv), defaults to the output file extension")
+	hotspotsCmd.Flags().IntVar(&hotspotsTop, "top", 20, "Number of files to list, 0 for all")
+	hotspotsCmd.Flags().BoolVar(&hotspotsChurn, "churn", false, "Tabulate churn vs complexity quadrants instead of the ranking")
+	hotspotsCmd.Flags().StringVar(&hotspotsCodeOwners, "codeowners", "", "CODEOWNERS file naming the owners of each file, found in the repository root, .github/ or docs/ by default")
+	hotspotsCmd.Flags().StringVar(&hotspotsSince, "since", "1y", "Count commits in this window for churn (e.g. 90d, 1y), 0 for the whole history")
+}
+
+func runHotspots(cmd *cobra.Command, args []string) error {
+	window, err := utils.ParseDuration(hotspotsSince)
+	if err != nil {
+		return fmt.Errorf("--since: %w", err)
+	}
+	owners, err := openCodeOwners(hotspotsCodeOwners)
+	if err != nil {
+		return err
+	}
+
+	files, err := utils.GetFilesToProcess(scanOptions(), func(path string) bool {
+		return statsParser(detectLanguage(path)) != nil
+	})
+	if err != nil {
+		logError(fmt.Sprintf("Failed to collect files: %v", err))
+		return err
+	}
+
+	if len(files) == 0 {
+		logWarning("No source files found")
+		return nil
+	}
+
+	if verbose {
+		logInfo(fmt.Sprintf("Analyzing %d files", len(files)))
+	}
+	runManifest.AddFiles(files)
+
+	var since time.Time
+	if window > 0 {
+		since = time.Now().Add(-window)
+	}
+	hotspots, err := collectHotspots(files, owners, since, hotspotsChurn)
+	if err != nil {
+		return err
+	}
+
+	report := &HotspotReport{Files: len(files), Hotspots: hotspots}
+	if !since.IsZero() {
+		report.Since = since.Format("2006-01-02")
+	}
+	for _, hotspot := range report.Hotspots {
+		if hotspot.GodFile {
+			report.GodFiles++
+		}
+	}
+	if hotspotsChurn {
+		report.MedianChurn, report.MedianComplexity, report.Quadrants = churnQuadrants(report.Hotspots, hotspotsTop)
+		report.Hotspots = nil
+	} else if hotspotsTop > 0 && len(report.Hotspots) > hotspotsTop {
+		report.Hotspots = report.Hotspots[:hotspotsTop]
+	}
+
+	if err := writeHotspots(report); err != nil {
+		logError(fmt.Sprintf("Failed to write hotspots: %v", err))
+		return err
+	}
+
+	logSuccess(fmt.Sprintf("Ranked %d files, %d God files", len(files), report.GodFiles))
+	return nil
+}
+
+// collectHotspots analyzes files and scores them, counting churn since the
+// given time. Without git history churn is left out, or is an error when
+// needChurn is set.
+func collectHotspots(files []string, owners *codeowners.Resolver, since time.Time, needChurn bool) ([]Hotspot, error) {
+	type analyzedFile struct {
+		hotspot  Hotspot
+		includes []string
+	}
+
+	analyzed := worker.Run(files, jobs, progress.New("Analyzing files", len(files), noProgress), func(ctx context.Context, idx int, filePath string) (result analyzedFile) {
+		fileStats, err := analyzeFile(filePath)
+		if err != nil {
+			failures.Record(filePath, err)
+			return result
+		}
+
+		hotspot := Hotspot{File: filePath, CodeLines: fileStats.CodeLines, Owners: owners.Owners(filePath)}
+		functions, err := statsParser(fileStats.Language).ParseFile(filePath)
+		if err != nil {
+			failures.Record(filePath, err)
+		}
+		hotspot.Functions = len(functions)
+		for _, fn := range functions {
+			hotspot.Complexity += fn.Complexity
+		}
+
+		if fileStats.Language == "C" || fileStats.Language == "C++" {
+			result.includes = fileIncludes(filePath)
+		}
+
+		result.hotspot = hotspot
+		return result
+	})
+
+	hotspots := make([]Hotspot, len(files))
+	includes := make([][]string, len(files))
+	for i, result := range analyzed {
+		hotspots[i] = result.hotspot
+		includes[i] = result.includes
+	}
+
+	includedBy := countIncluders(files, includes)
+	for i := range hotspots {
+		hotspots[i].IncludedBy = includedBy[files[i]]
+	}
+
+	churn, err := gitChurn(since)
+	if err != nil && needChurn {
+		return nil, fmt.Errorf("--churn needs git history: %w", err)
+	}
+	if err != nil {
+		logWarning(fmt.Sprintf("Churn is not counted, git history is unavailable: %v", err))
+	}
+	for i := range hotspots {
+		if abs, err := filepath.Abs(hotspots[i].File); err == nil {
+			hotspots[i].Churn = churn[abs]
+		}
+		hotspots[i].File = utils.DisplayPath(hotspots[i].File)
+	}
+	return scoreHotspots(hotspots), nil
+}
+
+func fileIncludes(filePath string) []string {
+	content, err := utils.ReadSourceFile(filePath)
+	if err != nil {
+		failures.Record(filePath, err)
+		return nil
+	}
+
+	var includes []string
+	for _, line := range utils.SplitLines(content) {
+		if match := hotspotIncludeRegex.FindStringSubmatch(line); match != nil {
+			includes = append(includes, match[1])
+		}
+	}
+	return includes
+}
+
+// countIncluders counts the distinct scanned files including each file.
+// An include is resolved next to the including file first, otherwise it
+// matches the one scanned file whose path ends with it, which covers -I
+// search paths without knowing them.
+func countIncluders(files []string, includes [][]string) map[string]int {
+	known := make(map[string]bool)
+	byName := make(map[string][]string)
+	for _, file := range files {
+		known[filepath.Clean(file)] = true
+		byName[filepath.Base(file)] = append(byName[filepath.Base(file)], file)
+	}
+
+	counts := make(map[string]int)
+	for i, file := range files {
+		resolved := make(map[string]bool)
+		for _, include := range includes[i] {
+			target := filepath.Join(filepath.Dir(file), include)
+			if !known[target] {
+				target = ""
+				var candidates []string
+				for _, candidate := range byName[filepath.Base(include)] {
+					if filepath.ToSlash(candidate) == include || strings.HasSuffix(filepath.ToSlash(candidate), "/"+include) {
+						candidates = append(candidates, candidate)
+					}
+				}
+				if len(candidates) == 1 {
+					target = filepath.Clean(candidates[0])
+				}
+			}
+			if target != "" && target != filepath.Clean(file) && !resolved[target] {
+				resolved[target] = true
+				counts[target]++
+			}
+		}
+	}
+
+	// Report under the paths the files were scanned with
+	byPath := make(map[string]int)
+	for _, file := range files {
+		byPath[file] = counts[filepath.Clean(file)]
+	}
+	return byPath
+}
+
+// scoreHotspots scales every factor to the largest value in the project and
+// averages them into a 0-100 score. Factors nobody has, such as churn outside
+// a git repository, are left out of the average.
+func scoreHotspots(hotspots []Hotspot) []Hotspot {
+	var ranked []Hotspot
+	for _, hotspot := range hotspots {
+		if hotspot.File != "" {
+			ranked = append(ranked, hotspot)
+		}
+	}
+
+	for _, factor := range hotspotFactors {
+		values := make([]int, len(ranked))
+		total := 0
+		for i, hotspot := range ranked {
+			values[i] = factor.value(hotspot)
+			total += values[i]
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(values)))
+		if len(values) == 0 || values[0] == 0 {
+			continue
+		}
+		highest, topTenth := values[0], values[len(values)/10]
+		mean := float64(total) / float64(len(values))
+
+		for i := range ranked {
+			value := factor.value(ranked[i])
+			ranked[i].Score += float64(value) / float64(highest)
+			// Ties with the tenth value do not count when most files share it
+			if value >= topTenth && float64(value) > mean {
+				ranked[i].Factors = append(ranked[i].Factors, factor.name)
+			}
+		}
+	}
+
+	used := 0
+	for _, factor := range hotspotFactors {
+		for _, hotspot := range ranked {
+			if factor.value(hotspot) > 0 {
+				used++
+				break
+			}
+		}
+	}
+
+	for i := range ranked {
+		if used > 0 {
+			ranked[i].Score = 100 * ranked[i].Score / float64(used)
+		}
+		ranked[i].GodFile = len(ranked[i].Factors) >= godFileFactors
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].File < ranked[j].File
+	})
+	return ranked
+}
+
+// churnQuadrants splits files at the median churn and complexity. A file is
+// high on a factor above the median, so untouched files never count as
+// churning. Each quadrant lists at most top files, 0 for all.
+func churnQuadrants(hotspots []Hotspot, top int) (int, int, []Quadrant) {
+	medianChurn := median(hotspots, func(h Hotspot) int { return h.Churn })
+	medianComplexity := median(hotspots, func(h Hotspot) int { return h.Complexity })
+
+	grouped := make([][]Hotspot, len(quadrants))
+	for _, hotspot := range hotspots {
+		index := 0
+		if hotspot.Churn > medianChurn {
+			index += 2
+		}
+		if hotspot.Complexity > medianComplexity {
+			index++
+		}
+		grouped[index] = append(grouped[index], hotspot)
+	}
+
+	var result []Quadrant
+	for _, quadrant := range quadrants {
+		files := grouped[quadrant.index]
+		sort.SliceStable(files, func(i, j int) bool {
+			if pi, pj := files[i].Churn*files[i].Complexity, files[j].Churn*files[j].Complexity; pi != pj {
+				return pi > pj
+			}
+			if files[i].Complexity != files[j].Complexity {
+				return files[i].Complexity > files[j].Complexity
+			}
+			return files[i].Churn > files[j].Churn
+		})
+		count := len(files)
+		if top > 0 && len(files) > top {
+			files = files[:top]
+		}
+		result = append(result, Quadrant{Name: quadrant.name, Advice: quadrant.advice, Count: count, Files: files})
+	}
+
+	return medianChurn, medianComplexity, result
+}
+
+func median(hotspots []Hotspot, value func(Hotspot) int) int {
+	if len(hotspots) == 0 {
+		return 0
+	}
+	values := make([]int, len(hotspots))
+	for i, hotspot := range hotspots {
+		values[i] = value(hotspot)
+	}
+	sort.Ints(values)
+	return values[len(values)/2]
+}
+
+func writeHotspots(report *HotspotReport) error {
+	format := hotspotsFormat
+	if format == "" {
+		format = "markdown"
+		switch filepath.Ext(hotspotsOutputFile) {
+		case ".json":
+			format = "json"
+		case ".csv":
+			format = "csv"
+		}
+	}
+
+	var output []byte
+	var err error
+
+	switch format {
+	case "markdown", "md":
+		if report.Quadrants != nil {
+			output = []byte(formatQuadrants(report))
+		} else {
+			output = []byte(formatHotspots(report))
+		}
+	case "json":
+		output, err = json.MarshalIndent(report, "", "  ")
+		output = append(output, '\n')
+	case "csv":
+		if report.Quadrants != nil {
+			output, err = formatQuadrantsCSV(report)
+		} else {
+			output, err = formatHotspotsCSV(report)
+		}
+	default:
+		return fmt.Errorf("unsupported format: %s (expected markdown, json or csv)", format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if hotspotsOutputFile != "" {
+		runManifest.AddResult(hotspotsOutputFile, output)
+		return os.WriteFile(hotspotsOutputFile, output, 0644)
+	}
+
+	runManifest.AddResult("stdout", output)
+	fmt.Print(string(output))
+	return nil
+}
+
+func formatHotspots(report *HotspotReport) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Hotspots\n\n")
+	churn := "commits in the whole history"
+	if report.Since != "" {
+		churn = "commits since " + report.Since
+	}
+	sb.WriteString(fmt.Sprintf("%s files ranked by code lines, complexity, functions, incoming includes and %s. ",
+		utils.FormatCount(report.Files), churn))
+	sb.WriteString(fmt.Sprintf("Factors in the top tenth of the project are listed; files with %d or more are God files.\n\n", godFileFactors))
+	sb.WriteString(fmt.Sprintf("- **God Files**: %d\n\n", report.GodFiles))
+
+	sb.WriteString("| # | File | Score | Code Lines | Complexity | Functions | Included By | Commits | Factors |\n")
+	sb.WriteString("|---|------|-------|------------|------------|-----------|-------------|---------|---------|\n")
+	for i, hotspot := range report.Hotspots {
+		file := hotspot.File
+		if hotspot.GodFile {
+			file += " **(God file)**"
+		}
+		if len(hotspot.Owners) > 0 {
+			file += " — " + strings.Join(hotspot.Owners, " ")
+		}
+		sb.WriteString(fmt.Sprintf("| %d | %s | %.1f | %s | %d | %d | %d | %d | %s |\n",
+			i+1, file, hotspot.Score, utils.FormatCount(hotspot.CodeLines), hotspot.Complexity, hotspot.Functions,
+			hotspot.IncludedBy, hotspot.Churn, strings.Join(hotspot.Factors, ", ")))
+	}
+
+	return sb.String()
+}
+
+func formatQuadrants(report *HotspotReport) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Churn vs Complexity\n\n")
+	churn := "commits in the whole history"
+	if report.Since != "" {
+		churn = "commits since " + report.Since
+	}
+	sb.WriteString(fmt.Sprintf("%s files split at the median churn (%d %s) and the median complexity (%d).\n\n",
+		utils.FormatCount(report.Files), report.MedianChurn, churn, report.MedianComplexity))
+
+	sb.WriteString("| Quadrant | Files | Advice |\n")
+	sb.WriteString("|----------|-------|--------|\n")
+	for _, quadrant := range report.Quadrants {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", quadrant.Name, utils.FormatCount(quadrant.Count), quadrant.Advice))
+	}
+
+	for _, quadrant := range report.Quadrants {
+		if len(quadrant.Files) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", quadrant.Name))
+		sb.WriteString("| # | File | Commits | Complexity | Code Lines |\n")
+		sb.WriteString("|---|------|---------|------------|------------|\n")
+		for i, hotspot := range quadrant.Files {
+			sb.WriteString(fmt.Sprintf("| %d | %s | %d | %d | %s |\n",
+				i+1, hotspot.File, hotspot.Churn, hotspot.Complexity, utils.FormatCount(hotspot.CodeLines)))
+		}
+	}
+
+	return sb.String()
+}
+
+func formatQuadrantsCSV(report *HotspotReport) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	writer.Write([]string{"quadrant", "rank", "file", "churn", "complexity", "code_lines"})
+	for _, quadrant := range report.Quadrants {
+		for i, hotspot := range quadrant.Files {
+			writer.Write([]string{quadrant.Name, strconv.Itoa(i + 1), hotspot.File,
+				strconv.Itoa(hotspot.Churn), strconv.Itoa(hotspot.Complexity), strconv.Itoa(hotspot.CodeLines)})
+		}
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+func formatHotspotsCSV(report *HotspotReport) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	writer.Write([]string{"rank", "file", "score", "code_lines", "complexity", "functions", "included_by", "churn", "factors", "god_file", "owners"})
+	for i, hotspot := range report.Hotspots {
+		writer.Write([]string{strconv.Itoa(i + 1), hotspot.File, strconv.FormatFloat(hotspot.Score, 'f', 1, 64),
+			strconv.Itoa(hotspot.CodeLines), strconv.Itoa(hotspot.Complexity), strconv.Itoa(hotspot.Functions),
+			strconv.Itoa(hotspot.IncludedBy), strconv.Itoa(hotspot.Churn), strings.Join(hotspot.Factors, " "),
+			strconv.FormatBool(hotspot.GodFile), strings.Join(hotspot.Owners, " ")})
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
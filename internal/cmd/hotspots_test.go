@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCountIncluders(t *testing.T) {
+	files := []string{
+		filepath.Join("src", "core.h"),
+		filepath.Join("src", "core.cpp"),
+		filepath.Join("src", "app", "main.cpp"),
+		filepath.Join("include", "util", "log.h"),
+		filepath.Join("src", "other", "log.h"),
+	}
+	includes := [][]string{
+		nil,
+		{"core.h", "core.h", "vector"},
+		{"../core.h", "util/log.h", "log.h"},
+		nil,
+		nil,
+	}
+
+	counts := countIncluders(files, includes)
+
+	// log.h alone is ambiguous between the two headers and is not counted
+	want := map[string]int{files[0]: 2, files[1]: 0, files[2]: 0, files[3]: 1, files[4]: 0}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("Expected %v, got %v", want, counts)
+	}
+}
+
+func TestScoreHotspots(t *testing.T) {
+	var hotspots []Hotspot
+	for i := 1; i <= 10; i++ {
+		hotspots = append(hotspots, Hotspot{File: string(rune('a'-1+i)) + ".c", CodeLines: i * 10, Complexity: i, Functions: 1})
+	}
+	hotspots[3] = Hotspot{File: "god.c", CodeLines: 500, Complexity: 80, Functions: 40, IncludedBy: 3}
+	hotspots = append(hotspots, Hotspot{})
+
+	ranked := scoreHotspots(hotspots)
+
+	if len(ranked) != 10 {
+		t.Fatalf("Expected files that failed to analyze to be dropped, got %d", len(ranked))
+	}
+	if ranked[0].File != "god.c" || !ranked[0].GodFile || ranked[0].Score != 100 {
+		t.Errorf("Expected god.c first with a score of 100, got %+v", ranked[0])
+	}
+	if !reflect.DeepEqual(ranked[0].Factors, []string{"size", "complexity", "functions", "includes"}) {
+		t.Errorf("Unexpected factors: %v", ranked[0].Factors)
+	}
+	for _, hotspot := range ranked[1:] {
+		if hotspot.GodFile {
+			t.Errorf("Unexpected God file: %+v", hotspot)
+		}
+	}
+}
+
+func TestChurnQuadrants(t *testing.T) {
+	hotspots := []Hotspot{
+		{File: "hot.c", Churn: 12, Complexity: 40},
+		{File: "warm.c", Churn: 6, Complexity: 30},
+		{File: "legacy.c", Churn: 0, Complexity: 90},
+		{File: "config.c", Churn: 20, Complexity: 2},
+		{File: "leaf.c", Churn: 0, Complexity: 3},
+		{File: "util.c", Churn: 1, Complexity: 5},
+		{File: "old.c", Churn: 1, Complexity: 20},
+	}
+
+	medianChurn, medianComplexity, result := churnQuadrants(hotspots, 1)
+
+	if medianChurn != 1 || medianComplexity != 20 {
+		t.Errorf("Expected medians 1 and 20, got %d and %d", medianChurn, medianComplexity)
+	}
+
+	want := map[string]struct {
+		count int
+		first string
+	}{
+		"Refactor first":      {2, "hot.c"},
+		"Complex but stable":  {1, "legacy.c"},
+		"Changing but simple": {1, "config.c"},
+		"Healthy":             {3, "old.c"},
+	}
+	if len(result) != len(want) || result[0].Name != "Refactor first" {
+		t.Fatalf("Unexpected quadrants: %+v", result)
+	}
+	for _, quadrant := range result {
+		expected := want[quadrant.Name]
+		if quadrant.Count != expected.count || len(quadrant.Files) != 1 || quadrant.Files[0].File != expected.first {
+			t.Errorf("%s: expected %d files led by %s, got %d: %+v", quadrant.Name, expected.count, expected.first, quadrant.Count, quadrant.Files)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/lsp"
+	"github.com/vitruves/gop/internal/registry"
+)
+
+var lspMaxComplexity int
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run a Language Server Protocol server over stdio",
+	Long: `Run a minimal language server on stdin/stdout so editors such as VS Code and Neovim show
+gop findings inline. Placeholders and functions above the complexity threshold are reported
+as diagnostics when a file is opened or saved, and the function registry provides document symbols.`,
+	Args: cobra.NoArgs,
+	RunE: runLSP,
+}
+
+func init() {
+	lspCmd.Flags().IntVar(&lspMaxComplexity, "max-complexity", 15, "Report functions with a higher cyclomatic complexity, 0 disables the check")
+}
+
+func runLSP(cmd *cobra.Command, args []string) error {
+	config := lsp.Config{
+		Diagnose: fileDiagnostics,
+		Parser: func(path string) registry.LanguageParser {
+			return statsParser(detectLanguage(path))
+		},
+		Version: buildInfo().Version,
+		Log:     os.Stderr,
+	}
+
+	// The protocol owns stdout, log lines printed by the analyzers go to stderr
+	out := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = out }()
+
+	return lsp.NewServer(os.Stdin, out, config).Serve()
+}
+
+// fileDiagnostics runs the analyzers that are fast enough to rerun on every save.
+func fileDiagnostics(path string) []diagnostics.Diagnostic {
+	var result []diagnostics.Diagnostic
+
+	if placeholders, err := scanFileForPlaceholders(path); err == nil {
+		result = append(result, placeholderDiagnostics(placeholders)...)
+	}
+
+	parser := statsParser(detectLanguage(path))
+	if parser == nil || lspMaxComplexity <= 0 {
+		return result
+	}
+
+	functions, err := parser.ParseFile(path)
+	if err != nil {
+		return result
+	}
+	for _, fn := range functions {
+		if fn.Complexity <= lspMaxComplexity {
+			continue
+		}
+		result = append(result, diagnostics.Diagnostic{
+			File:     path,
+			Line:     fn.Line,
+			Column:   1,
+			Severity: diagnostics.SeverityWarning,
+			Rule:     "complexity",
+			Message:  fmt.Sprintf("%s has cyclomatic complexity %d (threshold %d)", fn.Name, fn.Complexity, lspMaxComplexity),
+			Function: fn.Name,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectsFile lists the project roots analyzed by gop multi. Paths are
+// relative to the file.
+type ProjectsFile struct {
+	Analyzers []string       `yaml:"analyzers"`
+	Projects  []ProjectEntry `yaml:"projects"`
+}
+
+type ProjectEntry struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path"`
+}
+
+type ProjectSummary struct {
+	Name            string  `json:"name"`
+	Path            string  `json:"path"`
+	Files           int     `json:"files"`
+	Lines           int     `json:"lines"`
+	CodeLines       int     `json:"code_lines"`
+	Functions       int     `json:"functions"`
+	TotalComplexity int     `json:"total_complexity"`
+	AvgComplexity   float64 `json:"avg_complexity"`
+	Placeholders    int     `json:"placeholders"`
+	Error           string  `json:"error,omitempty"`
+}
+
+type MultiReport struct {
+	Analyzers []string         `json:"analyzers"`
+	Projects  []ProjectSummary `json:"projects"`
+	Total     ProjectSummary   `json:"total"`
+}
+
+var (
+	multiProjectsFile string
+	multiAnalyzers    []string
+	multiParallel     int
+	multiOutputFile   string
+	multiFormat       string
+)
+
+var multiCmd = &cobra.Command{
+	Use:   "multi",
+	Short: "Analyze several projects and compare them in one report",
+	Long: `Run the analyzers over every project root listed in a projects file, for example all
+services checked out under a workspace, and aggregate the per-project summaries into one
+comparative report. Projects are analyzed concurrently with --parallel.`,
+	Args: cobra.NoArgs,
+	RunE: runMulti,
+}
+
+func init() {
+	multiCmd.Flags().StringVar(&multiProjectsFile, "projects", "projects.yaml", "YAML file listing the projects")
+	multiCmd.Flags().StringSliceVar(&multiAnalyzers, "analyzers", nil, "Analyzers to run (stats, functions, placeholders), overrides the projects file (default all)")
+	multiCmd.Flags().IntVar(&multiParallel, "parallel", 1, "Number of projects analyzed at the same time")
+	multiCmd.Flags().StringVarP(&multiOutputFile, "output", "o", "", "Output file (.md or .json)")
+	multiCmd.Flags().StringVarP(&multiFormat, "format", "f", "", "Output format (markdown, json), defaults to the output file extension")
+}
+
+func runMulti(cmd *cobra.Command, args []string) error {
+	projects, err := loadProjectsFile(multiProjectsFile)
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read %s: %v", multiProjectsFile, err))
+		return err
+	}
+
+	analyzers := multiAnalyzers
+	if len(analyzers) == 0 {
+		analyzers = projects.Analyzers
+	}
+	if len(analyzers) == 0 {
+		analyzers = []string{"stats", "functions", "placeholders"}
+	}
+	enabled, err := enabledAnalyzers(analyzers)
+	if err != nil {
+		return err
+	}
+
+	logInfo(fmt.Sprintf("Analyzing %d projects", len(projects.Projects)))
+
+	report := &MultiReport{
+		Analyzers: analyzers,
+		Projects:  make([]ProjectSummary, len(projects.Projects)),
+	}
+
+	// Progress bars of concurrent projects would overwrite each other
+	quiet := multiParallel > 1
+
+	slots := make(chan struct{}, max(multiParallel, 1))
+	var wg sync.WaitGroup
+	for i, project := range projects.Projects {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, project ProjectEntry) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			report.Projects[i] = summarizeProject(project, enabled, quiet)
+			if quiet {
+				logInfo(fmt.Sprintf("Finished %s", project.Name))
+			}
+		}(i, project)
+	}
+	wg.Wait()
+
+	report.Total = totalSummary(report.Projects)
+
+	if err := writeMultiReport(report, enabled); err != nil {
+		logError(fmt.Sprintf("Failed to write output: %v", err))
+		return err
+	}
+
+	failed := 0
+	for _, project := range report.Projects {
+		if project.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		logWarning(fmt.Sprintf("%d of %d projects could not be analyzed", failed, len(report.Projects)))
+	}
+
+	logSuccess(fmt.Sprintf("Analyzed %d projects", len(report.Projects)-failed))
+	return nil
+}
+
+func loadProjectsFile(path string) (*ProjectsFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var projects ProjectsFile
+	if err := yaml.Unmarshal(data, &projects); err != nil {
+		return nil, err
+	}
+	if len(projects.Projects) == 0 {
+		return nil, fmt.Errorf("no projects listed")
+	}
+
+	if err := resolveProjects(projects.Projects, filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	return &projects, nil
+}
+
+// resolveProjects makes project paths relative to baseDir and names the
+// projects without a name after their directory.
+func resolveProjects(projects []ProjectEntry, baseDir string) error {
+	for i := range projects {
+		project := &projects[i]
+		if project.Path == "" {
+			return fmt.Errorf("project %d has no path", i+1)
+		}
+		if !filepath.IsAbs(project.Path) {
+			project.Path = filepath.Join(baseDir, project.Path)
+		}
+		if project.Name == "" {
+			project.Name = filepath.Base(project.Path)
+		}
+	}
+	return nil
+}
+
+func summarizeProject(project ProjectEntry, enabled map[string]bool, quiet bool) ProjectSummary {
+	summary := ProjectSummary{Name: project.Name, Path: utils.DisplayPath(project.Path)}
+
+	if info, err := os.Stat(project.Path); err != nil || !info.IsDir() {
+		summary.Error = fmt.Sprintf("%s is not a directory", project.Path)
+		return summary
+	}
+
+	snapshot, err := snapshotTree(project.Path, "Analyzing "+project.Name, enabled, quiet)
+	if err != nil {
+		summary.Error = err.Error()
+		return summary
+	}
+
+	summary.Files = snapshot.Files
+	summary.Lines = snapshot.Lines
+	summary.CodeLines = snapshot.CodeLines
+	summary.Functions = len(snapshot.Functions)
+	summary.TotalComplexity = totalComplexity(snapshot.Functions)
+	if summary.Functions > 0 {
+		summary.AvgComplexity = float64(summary.TotalComplexity) / float64(summary.Functions)
+	}
+	summary.Placeholders = len(snapshot.Placeholders)
+
+	return summary
+}
+
+func totalSummary(projects []ProjectSummary) ProjectSummary {
+	total := ProjectSummary{Name: "Total"}
+	for _, project := range projects {
+		total.Files += project.Files
+		total.Lines += project.Lines
+		total.CodeLines += project.CodeLines
+		total.Functions += project.Functions
+		total.TotalComplexity += project.TotalComplexity
+		total.Placeholders += project.Placeholders
+	}
+	if total.Functions > 0 {
+		total.AvgComplexity = float64(total.TotalComplexity) / float64(total.Functions)
+	}
+	return total
+}
+
+func writeMultiReport(report *MultiReport, enabled map[string]bool) error {
+	format := multiFormat
+	if format == "" {
+		format = "markdown"
+		if filepath.Ext(multiOutputFile) == ".json" {
+			format = "json"
+		}
+	}
+
+	var output []byte
+	var err error
+
+	switch format {
+	case "markdown", "md":
+		output = []byte(formatMultiReport(report, enabled))
+	case "json":
+		output, err = json.MarshalIndent(report, "", "  ")
+	default:
+		return fmt.Errorf("unsupported format: %s (expected markdown or json)", format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if multiOutputFile != "" {
+		runManifest.AddResult(multiOutputFile, output)
+		return os.WriteFile(multiOutputFile, output, 0644)
+	}
+
+	runManifest.AddResult("stdout", output)
+	fmt.Print(string(output))
+	return nil
+}
+
+func formatMultiReport(report *MultiReport, enabled map[string]bool) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Multi-Project Report\n\n")
+
+	headers := []string{"Project"}
+	if enabled["stats"] {
+		headers = append(headers, "Files", "Lines", "Code Lines", "Share")
+	}
+	if enabled["functions"] {
+		headers = append(headers, "Functions", "Total Complexity", "Avg Complexity")
+	}
+	if enabled["placeholders"] {
+		headers = append(headers, "Placeholders")
+	}
+
+	sb.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+	sb.WriteString("|" + strings.Repeat("--------|", len(headers)) + "\n")
+
+	row := func(summary ProjectSummary) string {
+		cells := []string{summary.Name}
+		if enabled["stats"] {
+			cells = append(cells,
+				utils.FormatCount(summary.Files),
+				utils.FormatCount(summary.Lines),
+				utils.FormatCount(summary.CodeLines),
+				fmt.Sprintf("%.1f%%", percentage(summary.CodeLines, report.Total.CodeLines)))
+		}
+		if enabled["functions"] {
+			cells = append(cells,
+				utils.FormatCount(summary.Functions),
+				utils.FormatCount(summary.TotalComplexity),
+				fmt.Sprintf("%.1f", summary.AvgComplexity))
+		}
+		if enabled["placeholders"] {
+			cells = append(cells, utils.FormatCount(summary.Placeholders))
+		}
+		return "| " + strings.Join(cells, " | ") + " |\n"
+	}
+
+	for _, project := range report.Projects {
+		if project.Error == "" {
+			sb.WriteString(row(project))
+		}
+	}
+	total := report.Total
+	total.Name = "**Total**"
+	sb.WriteString(row(total))
+
+	var failed []string
+	for _, project := range report.Projects {
+		if project.Error != "" {
+			failed = append(failed, fmt.Sprintf("- %s: %s", project.Name, project.Error))
+		}
+	}
+	if len(failed) > 0 {
+		sb.WriteString("\n## Failed Projects\n")
+		sb.WriteString(strings.Join(failed, "\n") + "\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMultiProjects(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles := func(project string, files map[string]string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(dir, project), 0755); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, project, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	writeFiles("alpha", map[string]string{"lib.c": `int add(int a, int b) {
+    return a + b;
+}
+
+int clamp(int x) {
+    if (x < 0) {
+        return 0;
+    }
+    return x;
+}
+`})
+	writeFiles("beta", map[string]string{
+		"main.c": `int main(void) {
+    return 0;
+}
+`,
+		"util.c": `int twice(int x) {
+    // TODO: handle overflow
+    return 2 * x;
+}
+`,
+	})
+
+	projectsFile := filepath.Join(dir, "projects.yaml")
+	projects := `projects:
+  - path: alpha
+  - name: Beta
+    path: beta
+  - path: missing
+`
+	if err := os.WriteFile(projectsFile, []byte(projects), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(t.TempDir(), "multi.json")
+	if err := runCommand(t, "multi", "--projects", projectsFile, "--parallel", "2", "-o", output, "--no-progress"); err != nil {
+		t.Fatalf("multi failed: %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report MultiReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Projects) != 3 {
+		t.Fatalf("Expected 3 projects, got %d", len(report.Projects))
+	}
+	alpha, beta, missing := report.Projects[0], report.Projects[1], report.Projects[2]
+
+	// Projects are reported in the order of the projects file, named after
+	// their directory unless the file names them
+	if alpha.Name != "alpha" || beta.Name != "Beta" || missing.Name != "missing" {
+		t.Errorf("Expected projects alpha, Beta and missing, got %s, %s and %s", alpha.Name, beta.Name, missing.Name)
+	}
+	if alpha.Files != 1 || alpha.Functions != 2 || alpha.TotalComplexity != 3 || alpha.Error != "" {
+		t.Errorf("Unexpected summary of alpha: %+v", alpha)
+	}
+	if beta.Files != 2 || beta.Functions != 2 || beta.Placeholders != 1 || beta.Error != "" {
+		t.Errorf("Unexpected summary of beta: %+v", beta)
+	}
+	if missing.Error == "" || missing.Files != 0 {
+		t.Errorf("Expected the missing project to fail, got %+v", missing)
+	}
+
+	// The total adds up the projects
+	total := report.Total
+	if total.Files != alpha.Files+beta.Files || total.Lines != alpha.Lines+beta.Lines ||
+		total.Functions != alpha.Functions+beta.Functions || total.Placeholders != beta.Placeholders {
+		t.Errorf("Expected the total to add up the projects, got %+v", total)
+	}
+	if total.AvgComplexity != float64(total.TotalComplexity)/float64(total.Functions) {
+		t.Errorf("Expected the average complexity over all functions, got %.2f", total.AvgComplexity)
+	}
+}
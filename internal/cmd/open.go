@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/editor"
+)
+
+var openEditor string
+
+var openCmd = &cobra.Command{
+	Use:   "open REPORT [N]",
+	Short: "Open the findings of a report in an editor",
+	Long: `Open the Nth finding of a checkstyle, codeclimate, json or sarif report at its line in $VISUAL,
+$EDITOR or VS Code. Without N the findings are listed, and on a terminal a number picks the
+one to open, "/text" narrows the list and q quits.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runOpen,
+}
+
+func init() {
+	openCmd.Flags().StringVar(&openEditor, "editor", "", "Editor command line (default: $VISUAL, $EDITOR, code when installed, vi)")
+}
+
+func runOpen(cmd *cobra.Command, args []string) error {
+	config := editor.Config{
+		Report:  args[0],
+		Editor:  openEditor,
+		Verbose: verbose,
+	}
+	if len(args) == 2 {
+		index, err := strconv.Atoi(args[1])
+		if err != nil || index < 1 {
+			return fmt.Errorf("invalid finding number: %s", args[1])
+		}
+		config.Index = index
+	}
+
+	return editor.Run(config)
+}
@@ -0,0 +1,426 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/codeowners"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
+)
+
+type Contributor struct {
+	Author  string  `json:"author"`
+	Commits int     `json:"commits"`
+	Share   float64 `json:"share"`
+}
+
+// DirectoryOwnership describes who changed the source files of a directory.
+// BusFactor is the fewest contributors behind more than half of its commits.
+type DirectoryOwnership struct {
+	Directory    string        `json:"directory"`
+	Files        int           `json:"files"`
+	Complexity   int           `json:"complexity"`
+	Commits      int           `json:"commits"`
+	Contributors []Contributor `json:"contributors,omitempty"`
+	BusFactor    int           `json:"bus_factor"`
+	CodeOwners   []string      `json:"codeowners,omitempty"`
+	Unowned      bool          `json:"unowned,omitempty"`
+	HighRisk     bool          `json:"high_risk"`
+}
+
+type OwnershipReport struct {
+	Since      string               `json:"since,omitempty"`
+	CodeOwners string               `json:"codeowners_file,omitempty"`
+	Unowned    int                  `json:"unowned_high_risk"`
+	HighRisk   int                  `json:"high_risk"`
+	Dirs       []DirectoryOwnership `json:"directories"`
+}
+
+// Contributors listed per directory in the markdown table.
+const listedContributors = 3
+
+var (
+	ownersOutputFile string
+	ownersFormat     string
+	ownersTop        int
+	ownersSince      string
+	ownersDirLevel   int
+	ownersCodeOwners string
+)
+
+var ownersCmd = &cobra.Command{
+	Use:   "owners",
+	Short: "Report the main contributors and bus factor of each directory",
+	Long: `Read git history to find the main contributors of every directory holding source files,
+how concentrated ownership is and its bus factor: the fewest people behind more than half
+of its commits. Directories with a bus factor of 1 and above-median complexity are high risk;
+with a CODEOWNERS file, high-risk directories nobody owns are flagged.`,
+	RunE: runOwners,
+}
+
+func init() {
+	ownersCmd.Flags().StringVarP(&ownersOutputFile, "output", "o", "", "Output file (.md, .json or .csv)")
+	ownersCmd.Flags().StringVarP(&ownersFormat, "format", "f", "", "Output format (markdown, json, csv), defaults to the output file extension")
+	ownersCmd.Flags().IntVar(&ownersTop, "top", 20, "Number of directories to list, 0 for all")
+	ownersCmd.Flags().StringVar(&ownersSince, "since", "0", "Only count commits in this window (e.g. 90d, 1y), 0 for the whole history")
+	ownersCmd.Flags().IntVar(&ownersDirLevel, "dir-level", 0, "Group by the first N path components (e.g. 2 for src/module), 0 for each file's directory")
+	ownersCmd.Flags().StringVar(&ownersCodeOwners, "codeowners", "", "CODEOWNERS file, found in the repository root, .github/ or docs/ by default")
+}
+
+func runOwners(cmd *cobra.Command, args []string) error {
+	window, err := utils.ParseDuration(ownersSince)
+	if err != nil {
+		return fmt.Errorf("--since: %w", err)
+	}
+	var since time.Time
+	if window > 0 {
+		since = time.Now().Add(-window)
+	}
+
+	root, err := gitRoot()
+	if err != nil {
+		return fmt.Errorf("owners needs git history: %w", err)
+	}
+
+	files, err := utils.GetFilesToProcess(scanOptions(), func(path string) bool {
+		return statsParser(detectLanguage(path)) != nil
+	})
+	if err != nil {
+		logError(fmt.Sprintf("Failed to collect files: %v", err))
+		return err
+	}
+
+	if len(files) == 0 {
+		logWarning("No source files found")
+		return nil
+	}
+	runManifest.AddFiles(files)
+
+	complexity := worker.Run(files, jobs, progress.New("Analyzing files", len(files), noProgress), func(ctx context.Context, idx int, filePath string) int {
+		functions, err := statsParser(detectLanguage(filePath)).ParseFile(filePath)
+		if err != nil {
+			failures.Record(filePath, err)
+			return 0
+		}
+		total := 0
+		for _, fn := range functions {
+			total += fn.Complexity
+		}
+		return total
+	})
+
+	commits, err := gitCommits(since)
+	if err != nil {
+		return fmt.Errorf("failed to read git history: %w", err)
+	}
+
+	var rules []codeowners.Rule
+	codeOwnersFile := ownersCodeOwners
+	if codeOwnersFile == "" {
+		codeOwnersFile = codeowners.Find(root)
+	}
+	if codeOwnersFile != "" {
+		if rules, err = codeowners.Load(codeOwnersFile); err != nil {
+			return fmt.Errorf("failed to read %s: %w", codeOwnersFile, err)
+		}
+	}
+
+	report := &OwnershipReport{Dirs: directoryOwnership(files, complexity, commits, root, ownersDirLevel, rules, codeOwnersFile != "")}
+	if !since.IsZero() {
+		report.Since = since.Format("2006-01-02")
+	}
+	if codeOwnersFile != "" {
+		report.CodeOwners = utils.DisplayPath(codeOwnersFile)
+	}
+	// Directories are grouped on the scanned paths, and only written in the
+	// selected path style
+	for i := range report.Dirs {
+		report.Dirs[i].Directory = utils.DisplayPath(report.Dirs[i].Directory)
+	}
+	for _, dir := range report.Dirs {
+		if dir.HighRisk {
+			report.HighRisk++
+			if dir.Unowned {
+				report.Unowned++
+			}
+		}
+	}
+	if ownersTop > 0 && len(report.Dirs) > ownersTop {
+		report.Dirs = report.Dirs[:ownersTop]
+	}
+
+	if err := writeOwners(report); err != nil {
+		logError(fmt.Sprintf("Failed to write ownership report: %v", err))
+		return err
+	}
+
+	logSuccess(fmt.Sprintf("Ownership analyzed for %d files, %d high-risk directories", len(files), report.HighRisk))
+	return nil
+}
+
+// directoryOwnership groups files by directory and counts every commit once
+// per directory it touched. Directories are ordered riskiest first: high-risk,
+// then unowned, then by complexity.
+func directoryOwnership(files []string, complexity []int, commits []gitCommit, root string, level int, rules []codeowners.Rule, withCodeOwners bool) []DirectoryOwnership {
+	dirs := make(map[string]*DirectoryOwnership)
+	dirOf := make(map[string]string)
+	owners := make(map[string]map[string]bool)
+
+	for i, file := range files {
+		name := ownersDirectory(file, level)
+		dir, exists := dirs[name]
+		if !exists {
+			dir = &DirectoryOwnership{Directory: name}
+			dirs[name] = dir
+			owners[name] = make(map[string]bool)
+		}
+		dir.Files++
+		dir.Complexity += complexity[i]
+
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			continue
+		}
+		dirOf[abs] = name
+
+		if rel, err := filepath.Rel(root, abs); err == nil {
+			for _, owner := range codeowners.Match(rules, filepath.ToSlash(rel)) {
+				owners[name][owner] = true
+			}
+		}
+	}
+
+	authors := make(map[string]map[string]int)
+	for _, commit := range commits {
+		touched := make(map[string]bool)
+		for _, file := range commit.Files {
+			if name, ok := dirOf[file]; ok && !touched[name] {
+				touched[name] = true
+				if authors[name] == nil {
+					authors[name] = make(map[string]int)
+				}
+				authors[name][commit.Author]++
+				dirs[name].Commits++
+			}
+		}
+	}
+
+	var result []DirectoryOwnership
+	var complexities []int
+	for name, dir := range dirs {
+		dir.Contributors = rankContributors(authors[name], dir.Commits)
+		dir.BusFactor = busFactor(dir.Contributors)
+		for owner := range owners[name] {
+			dir.CodeOwners = append(dir.CodeOwners, owner)
+		}
+		sort.Strings(dir.CodeOwners)
+		result = append(result, *dir)
+		complexities = append(complexities, dir.Complexity)
+	}
+
+	medianComplexity := medianOf(complexities)
+	for i := range result {
+		dir := &result[i]
+		dir.HighRisk = dir.BusFactor == 1 && float64(dir.Complexity) > medianComplexity
+		// Only high-risk directories are flagged for lacking an owner
+		dir.Unowned = withCodeOwners && dir.HighRisk && len(dir.CodeOwners) == 0
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].HighRisk != result[j].HighRisk {
+			return result[i].HighRisk
+		}
+		if result[i].Unowned != result[j].Unowned {
+			return result[i].Unowned
+		}
+		if result[i].Complexity != result[j].Complexity {
+			return result[i].Complexity > result[j].Complexity
+		}
+		return result[i].Directory < result[j].Directory
+	})
+	return result
+}
+
+// medianOf returns the median of the values, the mean of the two middle
+// ones for an even count.
+func medianOf(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return float64(sorted[middle-1]+sorted[middle]) / 2
+	}
+	return float64(sorted[middle])
+}
+
+func ownersDirectory(file string, level int) string {
+	dir := filepath.ToSlash(filepath.Dir(filepath.Clean(file)))
+	if level <= 0 || dir == "." {
+		return dir
+	}
+	parts := strings.Split(dir, "/")
+	if len(parts) > level {
+		parts = parts[:level]
+	}
+	return strings.Join(parts, "/")
+}
+
+func rankContributors(commits map[string]int, total int) []Contributor {
+	var contributors []Contributor
+	for author, count := range commits {
+		contributors = append(contributors, Contributor{
+			Author:  author,
+			Commits: count,
+			Share:   percentage(count, total),
+		})
+	}
+	sort.Slice(contributors, func(i, j int) bool {
+		if contributors[i].Commits != contributors[j].Commits {
+			return contributors[i].Commits > contributors[j].Commits
+		}
+		return contributors[i].Author < contributors[j].Author
+	})
+	return contributors
+}
+
+// busFactor is the number of top contributors whose commits add up to more
+// than half of the total, 0 for directories without history.
+func busFactor(contributors []Contributor) int {
+	share := 0.0
+	for i, contributor := range contributors {
+		share += contributor.Share
+		if share > 50 {
+			return i + 1
+		}
+	}
+	return len(contributors)
+}
+
+func writeOwners(report *OwnershipReport) error {
+	format := ownersFormat
+	if format == "" {
+		format = "markdown"
+		switch filepath.Ext(ownersOutputFile) {
+		case ".json":
+			format = "json"
+		case ".csv":
+			format = "csv"
+		}
+	}
+
+	var output []byte
+	var err error
+
+	switch format {
+	case "markdown", "md":
+		output = []byte(formatOwners(report))
+	case "json":
+		output, err = json.MarshalIndent(report, "", "  ")
+		output = append(output, '\n')
+	case "csv":
+		output, err = formatOwnersCSV(report)
+	default:
+		return fmt.Errorf("unsupported format: %s (expected markdown, json or csv)", format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if ownersOutputFile != "" {
+		runManifest.AddResult(ownersOutputFile, output)
+		return os.WriteFile(ownersOutputFile, output, 0644)
+	}
+
+	runManifest.AddResult("stdout", output)
+	fmt.Print(string(output))
+	return nil
+}
+
+func formatOwners(report *OwnershipReport) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Ownership\n\n")
+	history := "the whole history"
+	if report.Since != "" {
+		history = "commits since " + report.Since
+	}
+	sb.WriteString(fmt.Sprintf("Contributors by commits touching each directory, over %s. ", history))
+	sb.WriteString("The bus factor is the fewest contributors behind more than half of the commits.\n\n")
+	sb.WriteString(fmt.Sprintf("- **High-Risk Directories** (bus factor 1, above-median complexity): %d\n", report.HighRisk))
+	if report.CodeOwners != "" {
+		sb.WriteString(fmt.Sprintf("- **Unowned High-Risk Directories** (per %s): %d\n", report.CodeOwners, report.Unowned))
+	}
+	sb.WriteString("\n")
+
+	header := "| Directory | Files | Complexity | Commits | Main Contributors | Bus Factor |"
+	separator := "|-----------|-------|------------|---------|-------------------|------------|"
+	if report.CodeOwners != "" {
+		header += " Code Owners |"
+		separator += "-------------|"
+	}
+	sb.WriteString(header + "\n" + separator + "\n")
+
+	for _, dir := range report.Dirs {
+		var contributors []string
+		for i, contributor := range dir.Contributors {
+			if i == listedContributors {
+				break
+			}
+			contributors = append(contributors, fmt.Sprintf("%s (%.0f%%)", contributor.Author, contributor.Share))
+		}
+
+		name := dir.Directory
+		if dir.HighRisk {
+			name += " **(high risk)**"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %s | %d |", name, dir.Files, dir.Complexity, dir.Commits,
+			strings.Join(contributors, ", "), dir.BusFactor))
+		if report.CodeOwners != "" {
+			owners := strings.Join(dir.CodeOwners, " ")
+			if dir.Unowned {
+				owners = "**none**"
+			}
+			sb.WriteString(fmt.Sprintf(" %s |", owners))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func formatOwnersCSV(report *OwnershipReport) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	writer.Write([]string{"directory", "files", "complexity", "commits", "main_contributor", "main_share", "bus_factor", "codeowners", "unowned", "high_risk"})
+	for _, dir := range report.Dirs {
+		main, share := "", ""
+		if len(dir.Contributors) > 0 {
+			main = dir.Contributors[0].Author
+			share = strconv.FormatFloat(dir.Contributors[0].Share, 'f', 1, 64)
+		}
+		writer.Write([]string{dir.Directory, strconv.Itoa(dir.Files), strconv.Itoa(dir.Complexity), strconv.Itoa(dir.Commits),
+			main, share, strconv.Itoa(dir.BusFactor), strings.Join(dir.CodeOwners, " "),
+			strconv.FormatBool(dir.Unowned), strconv.FormatBool(dir.HighRisk)})
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/vitruves/gop/internal/codeowners"
+)
+
+func TestDirectoryOwnership(t *testing.T) {
+	root, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []string{"core/a.c", "core/b.c", "ui/view.c", "ui/menu.c", "tools/gen.c"}
+	complexity := []int{40, 30, 5, 5, 1}
+	abs := func(file string) string { return filepath.Join(root, file) }
+
+	commits := []gitCommit{
+		{Author: "ana", Files: []string{abs("core/a.c"), abs("core/b.c")}},
+		{Author: "ana", Files: []string{abs("core/a.c")}},
+		{Author: "ana", Files: []string{abs("core/b.c"), abs("ui/view.c")}},
+		{Author: "ben", Files: []string{abs("ui/menu.c")}},
+		{Author: "cho", Files: []string{abs("ui/view.c"), abs("README.md")}},
+		{Author: "ben", Files: []string{abs("core/a.c"), abs("tools/gen.c")}},
+	}
+	rules := []codeowners.Rule{{Patterns: codeowners.Patterns("/ui/"), Owners: []string{"@ui-team"}}}
+
+	dirs := directoryOwnership(files, complexity, commits, root, 0, rules, true)
+	byName := make(map[string]DirectoryOwnership)
+	for _, dir := range dirs {
+		byName[dir.Directory] = dir
+	}
+
+	core := byName["core"]
+	if dirs[0].Directory != "core" || core.Commits != 4 || core.BusFactor != 1 || !core.HighRisk || !core.Unowned {
+		t.Errorf("Expected core to be the first, unowned high-risk directory, got %+v", core)
+	}
+	if core.Contributors[0].Author != "ana" || core.Contributors[0].Share != 75 {
+		t.Errorf("Expected ana to own 75%% of core, got %+v", core.Contributors)
+	}
+
+	ui := byName["ui"]
+	if ui.Commits != 3 || ui.BusFactor != 2 || ui.HighRisk || ui.Unowned || !reflect.DeepEqual(ui.CodeOwners, []string{"@ui-team"}) {
+		t.Errorf("Unexpected ownership of ui: %+v", ui)
+	}
+
+	if tools := byName["tools"]; tools.BusFactor != 1 || tools.HighRisk {
+		t.Errorf("Expected tools to be low risk despite its bus factor, got %+v", tools)
+	}
+}
+
+func TestOwnershipMedian(t *testing.T) {
+	root, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		complexity []int
+		highRisk   []string
+	}{
+		// The median of 10, 20 and 30 is 20, a directory at it is not above it
+		{[]int{10, 20, 30}, []string{"d2"}},
+		// The median of an even count is the mean of the two middle values, 25
+		{[]int{10, 20, 30, 40}, []string{"d2", "d3"}},
+	}
+	for _, test := range tests {
+		var files []string
+		var commits []gitCommit
+		for i := range test.complexity {
+			file := filepath.Join(fmt.Sprintf("d%d", i), "a.c")
+			files = append(files, file)
+			commits = append(commits, gitCommit{Author: "ana", Files: []string{filepath.Join(root, file)}})
+		}
+
+		dirs := directoryOwnership(files, test.complexity, commits, root, 0, nil, true)
+		var highRisk []string
+		for _, dir := range dirs {
+			if dir.HighRisk {
+				highRisk = append(highRisk, dir.Directory)
+			}
+			if dir.Unowned != dir.HighRisk {
+				t.Errorf("complexity %v: expected only high-risk directories to be unowned, got %+v", test.complexity, dir)
+			}
+		}
+		sort.Strings(highRisk)
+		if !reflect.DeepEqual(highRisk, test.highRisk) {
+			t.Errorf("complexity %v: expected high-risk %v, got %v", test.complexity, test.highRisk, highRisk)
+		}
+	}
+}
+
+func TestOwnersDirectory(t *testing.T) {
+	if got := ownersDirectory("src/net/tls/handshake.c", 2); got != "src/net" {
+		t.Errorf("Expected src/net, got %s", got)
+	}
+	if got := ownersDirectory("main.c", 2); got != "." {
+		t.Errorf("Expected ., got %s", got)
+	}
+}
@@ -2,28 +2,60 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
-	"sync"
+	"unicode/utf8"
 
-	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
-	"golang.org/x/sync/semaphore"
+	"github.com/vitruves/gop/internal/ahocorasick"
+	"github.com/vitruves/gop/internal/codeowners"
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/report"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
 )
 
 type Placeholder struct {
-	File    string
-	Line    int
-	Column  int
-	Content string
-	Type    string
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Content string `json:"content"`
+	Type    string `json:"type"`
+	// Set when ranking, see scorePlaceholders
+	Marker   string  `json:"marker,omitempty"`
+	Priority string  `json:"priority,omitempty"`
+	AgeDays  int     `json:"age_days,omitempty"`
+	Score    float64 `json:"score,omitempty"`
+	// Owners are the CODEOWNERS entries of the file
+	Owners []string `json:"owners,omitempty"`
+	// path is the file as scanned, kept for the fingerprint when File is
+	// displayed
+	path string
 }
 
+var (
+	placeholdersTop        int
+	placeholdersFormat     string
+	placeholdersOutputFile string
+	placeholdersTemplate   string
+	placeholdersOlderThan  string
+	placeholdersCodeOwners string
+	placeholdersGroupBy    string
+	placeholdersEngine     string
+
+	placeholdersFindings findingFlags
+)
+
 var placeholdersCmd = &cobra.Command{
 	Use:   "placeholders",
 	Short: "Search and highlight placeholders in code",
@@ -31,7 +63,47 @@ var placeholdersCmd = &cobra.Command{
 	RunE:  runPlaceholders,
 }
 
+func init() {
+	placeholdersCmd.Flags().IntVar(&placeholdersTop, "top", 0, "Rank placeholders by priority, marker, age and complexity and show the N highest")
+	placeholdersCmd.Flags().StringVarP(&placeholdersFormat, "format", "f", "text", "Output format (text, csv, checkstyle, codeclimate, json, sarif), csv is a ranked backlog for spreadsheets")
+	placeholdersCmd.Flags().StringVarP(&placeholdersOutputFile, "output", "o", "", "Output file for csv, checkstyle, codeclimate, json, sarif and template output")
+	placeholdersCmd.Flags().StringVar(&placeholdersOlderThan, "older-than", "", "Only report placeholders whose line was last changed longer ago than this, per git blame (e.g. 90d, 2w, 1y)")
+	placeholdersCmd.Flags().StringVar(&placeholdersTemplate, "template", "", "Go text/template file rendering the placeholders, replaces --format")
+	placeholdersCmd.Flags().StringVar(&placeholdersCodeOwners, "codeowners", "", "CODEOWNERS file routing placeholders to their owners, found in the repository root, .github/ or docs/ by default")
+	placeholdersCmd.Flags().StringVar(&placeholdersGroupBy, "group-by", "type", "Group the text listing by type or owner, owner also prints per-owner counts")
+	placeholdersCmd.Flags().StringVar(&placeholdersEngine, "engine", engineAhoCorasick, "Scanning engine: aho-corasick runs a pattern only on lines holding one of its keywords, regex runs every pattern on every line")
+	addFindingFilterFlags(placeholdersCmd, &placeholdersFindings, false)
+}
+
 func runPlaceholders(cmd *cobra.Command, args []string) error {
+	if placeholdersFormat != "text" && placeholdersFormat != "csv" && !diagnostics.IsFormat(placeholdersFormat) {
+		return fmt.Errorf("unsupported format: %s (expected text, csv, checkstyle, codeclimate, json or sarif)", placeholdersFormat)
+	}
+	if placeholdersGroupBy != "type" && placeholdersGroupBy != "owner" {
+		return fmt.Errorf("unsupported --group-by: %s (expected type or owner)", placeholdersGroupBy)
+	}
+	if placeholdersEngine != engineAhoCorasick && placeholdersEngine != engineRegex {
+		return fmt.Errorf("unsupported --engine: %s (expected aho-corasick or regex)", placeholdersEngine)
+	}
+	ranked := placeholdersTop > 0 || placeholdersFormat == "csv"
+
+	owners, err := openCodeOwners(placeholdersCodeOwners)
+	if err != nil {
+		return err
+	}
+	if placeholdersGroupBy == "owner" && owners == nil {
+		return fmt.Errorf("--group-by owner needs a CODEOWNERS file, none found (use --codeowners)")
+	}
+
+	var minAgeDays int
+	if placeholdersOlderThan != "" {
+		age, err := utils.ParseDuration(placeholdersOlderThan)
+		if err != nil {
+			return err
+		}
+		minAgeDays = int(age.Hours() / 24)
+	}
+
 	if verbose {
 		logInfo("Starting placeholder search")
 	}
@@ -50,99 +122,114 @@ func runPlaceholders(cmd *cobra.Command, args []string) error {
 	if verbose {
 		logInfo(fmt.Sprintf("Scanning %d files for placeholders", len(files)))
 	}
+	runManifest.AddFiles(files)
+
+	found := worker.Run(files, jobs, progress.New("Scanning for placeholders", len(files), noProgress), func(ctx context.Context, idx int, filePath string) []Placeholder {
+		placeholders, err := scanFileForPlaceholders(filePath)
+		if err != nil {
+			failures.Record(filePath, err)
+			return nil
+		}
+		if ranked && len(placeholders) > 0 {
+			scorePlaceholders(filePath, placeholders)
+		}
+		if placeholdersOlderThan != "" && len(placeholders) > 0 {
+			placeholders = olderPlaceholders(filePath, placeholders, minAgeDays, ranked)
+		}
+
+		fileOwners := owners.Owners(filePath)
+		for i := range placeholders {
+			placeholders[i].Owners = fileOwners
+		}
+		return placeholders
+	})
 
 	var allPlaceholders []Placeholder
-	var mu sync.Mutex
-
-	bar := progressbar.NewOptions(len(files),
-		progressbar.OptionSetDescription("Scanning for placeholders"),
-		progressbar.OptionShowCount(),
-		progressbar.OptionSetRenderBlankState(true),
-		progressbar.OptionClearOnFinish(),
-	)
-
-	sem := semaphore.NewWeighted(int64(jobs))
-	var wg sync.WaitGroup
-
-	for _, file := range files {
-		wg.Add(1)
-		go func(filePath string) {
-			defer wg.Done()
-			sem.Acquire(context.Background(), 1)
-			defer sem.Release(1)
-
-			placeholders, err := scanFileForPlaceholders(filePath)
-			if err != nil {
-				logError(fmt.Sprintf("Error scanning %s: %v", filePath, err))
-				return
-			}
+	for _, placeholders := range found {
+		allPlaceholders = append(allPlaceholders, placeholders...)
+	}
 
-			mu.Lock()
-			allPlaceholders = append(allPlaceholders, placeholders...)
-			bar.Add(1)
-			mu.Unlock()
-		}(file)
+	kept := allPlaceholders[:0]
+	for _, p := range allPlaceholders {
+		if diagnostics.Keep(placeholderDiagnostic(p)) {
+			kept = append(kept, p)
+		}
 	}
+	allPlaceholders = kept
 
-	wg.Wait()
-	bar.Finish()
+	// Files are only read above, from here on paths are output
+	for i := range allPlaceholders {
+		allPlaceholders[i].path = allPlaceholders[i].File
+		allPlaceholders[i].File = utils.DisplayPath(allPlaceholders[i].File)
+	}
 
-	if len(allPlaceholders) == 0 {
-		logSuccess("No placeholders found")
-		return nil
+	sort.Slice(allPlaceholders, func(i, j int) bool {
+		if allPlaceholders[i].File != allPlaceholders[j].File {
+			return allPlaceholders[i].File < allPlaceholders[j].File
+		}
+		return allPlaceholders[i].Line < allPlaceholders[j].Line
+	})
+
+	if runManifest != nil {
+		if digest, err := json.Marshal(allPlaceholders); err == nil {
+			runManifest.AddResult("placeholders", digest)
+		}
 	}
 
-	displayPlaceholders(allPlaceholders)
-	logSuccess(fmt.Sprintf("Found %d placeholders", len(allPlaceholders)))
+	if placeholdersTemplate != "" {
+		return writePlaceholdersTemplate(allPlaceholders, ranked)
+	}
 
-	return nil
-}
+	if diagnostics.IsFormat(placeholdersFormat) {
+		return writePlaceholderDiagnostics(allPlaceholders)
+	}
 
-func collectSourceFiles() ([]string, error) {
-	var files []string
-	extensions := []string{".py", ".rs", ".go", ".c", ".cpp", ".cxx", ".cc", ".h", ".hpp", ".hxx", ".hh", ".js", ".ts", ".java", ".kt", ".swift", ".rb", ".php"}
+	if len(allPlaceholders) == 0 {
+		logSuccess("No placeholders found")
+		return nil
+	}
 
-	startDir := "."
-	if len(include) > 0 {
-		for _, path := range include {
-			matches, err := filepath.Glob(path)
-			if err != nil {
-				return nil, err
-			}
-			files = append(files, matches...)
+	if !ranked {
+		if placeholdersGroupBy == "owner" {
+			displayPlaceholdersByOwner(allPlaceholders)
+		} else {
+			displayPlaceholders(allPlaceholders)
 		}
-		return files, nil
+		logSuccess(fmt.Sprintf("Found %d placeholders", len(allPlaceholders)))
+		return nil
 	}
 
-	err := filepath.WalkDir(startDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
+	rankPlaceholders(allPlaceholders)
+	backlog := allPlaceholders
+	if placeholdersTop > 0 && len(backlog) > placeholdersTop {
+		backlog = backlog[:placeholdersTop]
+	}
+
+	if placeholdersFormat == "csv" {
+		if err := writePlaceholdersCSV(backlog); err != nil {
+			logError(fmt.Sprintf("Failed to write backlog: %v", err))
 			return err
 		}
-
-		if d.IsDir() {
-			if shouldExcludeDirPlaceholders(path, exclude) {
-				return filepath.SkipDir
-			}
-			if !recursive && path != startDir {
-				return filepath.SkipDir
-			}
-			if depth > 0 {
-				relPath, _ := filepath.Rel(startDir, path)
-				if strings.Count(relPath, string(filepath.Separator)) >= depth {
-					return filepath.SkipDir
-				}
-			}
+		if placeholdersOutputFile == "" {
 			return nil
 		}
+	} else if placeholdersGroupBy == "owner" {
+		displayPlaceholdersByOwner(backlog)
+	} else {
+		displayRankedPlaceholders(backlog)
+	}
 
-		if isValidSourceFile(path, extensions) && !shouldExcludeFile(path, exclude) {
-			files = append(files, path)
-		}
+	logSuccess(fmt.Sprintf("Found %d placeholders", len(allPlaceholders)))
 
-		return nil
-	})
+	return nil
+}
+
+func collectSourceFiles() ([]string, error) {
+	extensions := []string{".py", ".rs", ".go", ".c", ".cpp", ".cxx", ".cc", ".h", ".hpp", ".hxx", ".hh", ".js", ".ts", ".java", ".kt", ".swift", ".rb", ".php"}
 
-	return files, err
+	return utils.GetFilesToProcess(scanOptions(), func(path string) bool {
+		return len(include) > 0 || isValidSourceFile(path, extensions)
+	})
 }
 
 func isValidSourceFile(path string, extensions []string) bool {
@@ -155,67 +242,77 @@ func isValidSourceFile(path string, extensions []string) bool {
 	return false
 }
 
-func shouldExcludeFile(path string, exclude []string) bool {
-	for _, excludePattern := range exclude {
-		if matched, _ := filepath.Match(excludePattern, path); matched {
-			return true
-		}
-	}
-	return false
+// placeholderPatterns find placeholders of each type. A pattern can only
+// match a line holding one of its literals, whatever their case: the
+// aho-corasick engine runs a pattern on the lines holding its literals only.
+var placeholderPatterns = []struct {
+	regex    *regexp.Regexp
+	ptype    string
+	literals []string
+}{
+	{regexp.MustCompile(`(?i)#?\s*(TODO|FIXME|HACK|XXX|BUG|NOTE)(\([^)]*\))?\s*:?\s*(.+)`), "comment",
+		[]string{"todo", "fixme", "hack", "xxx", "bug", "note"}},
+	{regexp.MustCompile(`(?i)placeholder|temp|temporary|dummy|mock|stub|simple|simplification|basic|minimal|naive|hardcode|hardcoded`), "temporary",
+		[]string{"placeholder", "temp", "dummy", "mock", "stub", "simple", "simplification", "basic", "minimal", "naive", "hardcode"}},
+	{regexp.MustCompile(`\b(localhost|127\.0\.0\.1|0\.0\.0\.0)\b`), "hardcoded_host",
+		[]string{"localhost", "127.0.0.1", "0.0.0.0"}},
+	{regexp.MustCompile(`\b\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}\b`), "ip_address",
+		[]string{"0.", "1.", "2.", "3.", "4.", "5.", "6.", "7.", "8.", "9."}},
+	{regexp.MustCompile(`\b(password|passwd|secret|key|token)\s*[=:]\s*["']([^"']+)["']`), "hardcoded_secret",
+		[]string{"password", "passwd", "secret", "key", "token"}},
+	{regexp.MustCompile(`\btest\w*\s*=\s*true\b`), "test_flag", []string{"test"}},
+	{regexp.MustCompile(`\bdebug\s*=\s*true\b`), "debug_flag", []string{"debug"}},
+	{regexp.MustCompile(`\b(print|console\.log|fmt\.Print|println!|cout\s*<<)\s*\(`), "debug_print",
+		[]string{"print", "console.log", "cout"}},
+	{regexp.MustCompile(`\b(exit|quit|abort)\s*\(`), "exit_call", []string{"exit", "quit", "abort"}},
+	{regexp.MustCompile(`\bthrow\s+new\s+Exception\(|panic!\(|unreachable!\(`), "exception",
+		[]string{"throw", "panic!(", "unreachable!("}},
+	{regexp.MustCompile(`(?i)\b(implement|implementation|implement this|not implemented|unimplemented|not done|incomplete)\b`), "unimplemented",
+		[]string{"implement", "not done", "incomplete"}},
+	{regexp.MustCompile(`(?i)\b(example|sample|demo|test data|fake data)\b`), "example_data",
+		[]string{"example", "sample", "demo", "test data", "fake data"}},
+	{regexp.MustCompile(`(?i)\b(quick|dirty|quick and dirty|workaround|kludge|band-aid|bandaid)\b`), "quick_fix",
+		[]string{"quick", "dirty", "workaround", "kludge", "band-aid", "bandaid"}},
 }
 
-func shouldExcludeDirPlaceholders(path string, exclude []string) bool {
-	excludeDirs := []string{".git", "node_modules", "__pycache__", ".pytest_cache", "target", "build", "dist", "vendor"}
-	
-	for _, excludePattern := range exclude {
-		if matched, _ := filepath.Match(excludePattern, path); matched {
-			return true
-		}
-	}
-	
-	for _, excludeDir := range excludeDirs {
-		if strings.Contains(path, excludeDir) {
-			return true
-		}
+var placeholderMatcher = func() *ahocorasick.Matcher {
+	groups := make([][]string, len(placeholderPatterns))
+	for i, pattern := range placeholderPatterns {
+		groups[i] = pattern.literals
 	}
-	
-	return false
-}
+	return ahocorasick.New(groups)
+}()
+
+// Placeholder scanning engines: every pattern on every line, or only the
+// patterns whose literals the line holds.
+const (
+	engineRegex       = "regex"
+	engineAhoCorasick = "aho-corasick"
+)
 
 func scanFileForPlaceholders(filePath string) ([]Placeholder, error) {
-	file, err := os.Open(filePath)
+	content, err := utils.ReadSourceFile(filePath)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
 	var placeholders []Placeholder
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(strings.NewReader(content))
 	lineNum := 1
 
-	patterns := []struct {
-		regex *regexp.Regexp
-		ptype string
-	}{
-		{regexp.MustCompile(`(?i)#?\s*(TODO|FIXME|HACK|XXX|BUG|NOTE)(\([^)]*\))?\s*:?\s*(.+)`), "comment"},
-		{regexp.MustCompile(`(?i)placeholder|temp|temporary|dummy|mock|stub|simple|simplification|basic|minimal|naive|hardcode|hardcoded`), "temporary"},
-		{regexp.MustCompile(`\b(localhost|127\.0\.0\.1|0\.0\.0\.0)\b`), "hardcoded_host"},
-		{regexp.MustCompile(`\b\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}\b`), "ip_address"},
-		{regexp.MustCompile(`\b(password|passwd|secret|key|token)\s*[=:]\s*["']([^"']+)["']`), "hardcoded_secret"},
-		{regexp.MustCompile(`\btest\w*\s*=\s*true\b`), "test_flag"},
-		{regexp.MustCompile(`\bdebug\s*=\s*true\b`), "debug_flag"},
-		{regexp.MustCompile(`\b(print|console\.log|fmt\.Print|println!|cout\s*<<)\s*\(`), "debug_print"},
-		{regexp.MustCompile(`\b(exit|quit|abort)\s*\(`), "exit_call"},
-		{regexp.MustCompile(`\bthrow\s+new\s+Exception\(|panic!\(|unreachable!\(`), "exception"},
-		{regexp.MustCompile(`(?i)\b(implement|implementation|implement this|not implemented|unimplemented|not done|incomplete)\b`), "unimplemented"},
-		{regexp.MustCompile(`(?i)\b(example|sample|demo|test data|fake data)\b`), "example_data"},
-		{regexp.MustCompile(`(?i)\b(quick|dirty|quick and dirty|workaround|kludge|band-aid|bandaid)\b`), "quick_fix"},
-	}
-
 	for scanner.Scan() {
 		line := scanner.Text()
 		
-		for _, pattern := range patterns {
+		candidates := ^uint64(0)
+		// Non-ASCII text can match case-insensitive patterns through
+		// Unicode folding, which the literals do not follow
+		if placeholdersEngine != engineRegex && isASCII(line) {
+			candidates = placeholderMatcher.Match(line)
+		}
+		for i, pattern := range placeholderPatterns {
+			if candidates&(1<<i) == 0 {
+				continue
+			}
 			matches := pattern.regex.FindAllStringIndex(line, -1)
 			for _, match := range matches {
 				placeholder := Placeholder{
@@ -235,6 +332,15 @@ func scanFileForPlaceholders(filePath string) ([]Placeholder, error) {
 	return placeholders, scanner.Err()
 }
 
+func isASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
 func displayPlaceholders(placeholders []Placeholder) {
 	typeGroups := make(map[string][]Placeholder)
 	
@@ -243,11 +349,143 @@ func displayPlaceholders(placeholders []Placeholder) {
 	}
 
 	for ptype, items := range typeGroups {
-		fmt.Printf("\n\033[1;36m=== %s ===\033[0m\n", strings.ToUpper(ptype))
+		fmt.Printf("\n%s\n", color.Wrap(color.BoldCyan, "=== "+strings.ToUpper(ptype)+" ==="))
 		
 		for _, item := range items {
-			fmt.Printf("\033[33m%s:%d:%d\033[0m - %s\n", 
-				item.File, item.Line, item.Column, item.Content)
+			fmt.Printf("%s - %s\n", 
+				color.Wrap(color.Yellow, fmt.Sprintf("%s:%d:%d", item.File, item.Line, item.Column)), item.Content)
 		}
 	}
-}
\ No newline at end of file
+}
+// displayPlaceholdersByOwner lists placeholders under each owner, in the
+// order given, and ends with the count of each owner.
+func displayPlaceholdersByOwner(placeholders []Placeholder) {
+	findings := placeholderDiagnostics(placeholders)
+	order, groups := codeowners.Group(findings)
+
+	for _, owner := range order {
+		fmt.Printf("\n%s\n", color.Wrap(color.BoldCyan, fmt.Sprintf("=== %s (%d) ===", owner, len(groups[owner]))))
+		for _, finding := range groups[owner] {
+			fmt.Printf("%s [%s] - %s\n",
+				color.Wrap(color.Yellow, fmt.Sprintf("%s:%d:%d", finding.File, finding.Line, finding.Column)), strings.TrimPrefix(finding.Rule, "placeholders/"), finding.Message)
+		}
+	}
+	displayOwnerCounts(findings)
+}
+
+// Placeholder types that should fail a review rather than merely annotate it.
+var placeholderSeverities = map[string]diagnostics.Severity{
+	"hardcoded_secret": diagnostics.SeverityError,
+	"unimplemented":    diagnostics.SeverityWarning,
+	"exception":        diagnostics.SeverityWarning,
+	"debug_flag":       diagnostics.SeverityWarning,
+	"test_flag":        diagnostics.SeverityWarning,
+}
+
+func placeholderDiagnostics(placeholders []Placeholder) []diagnostics.Diagnostic {
+	var result []diagnostics.Diagnostic
+	for _, p := range placeholders {
+		result = append(result, placeholderDiagnostic(p))
+	}
+	return result
+}
+
+func placeholderDiagnostic(p Placeholder) diagnostics.Diagnostic {
+	severity, ok := placeholderSeverities[p.Type]
+	if !ok {
+		severity = diagnostics.SeverityInfo
+	}
+	if marker := markerRegex.FindString(p.Content); marker == "FIXME" || marker == "BUG" {
+		severity = diagnostics.SeverityWarning
+	}
+
+	return diagnostics.Diagnostic{
+		File:        p.File,
+		Line:        p.Line,
+		Column:      p.Column,
+		Severity:    severity,
+		Rule:        "placeholders/" + p.Type,
+		Message:     p.Content,
+		Excerpt:     p.Content,
+		Owners:      p.Owners,
+		ScannedFile: p.path,
+	}
+}
+
+func writePlaceholderDiagnostics(placeholders []Placeholder) error {
+	diags := placeholderDiagnostics(placeholders)
+	if placeholdersOutputFile == "" {
+		return diagnostics.WriteReport(os.Stdout, placeholdersFormat, diags)
+	}
+
+	var buf bytes.Buffer
+	if err := diagnostics.WriteReport(&buf, placeholdersFormat, diags); err != nil {
+		return err
+	}
+	output := buf.Bytes()
+	runManifest.AddResult(placeholdersOutputFile, output)
+	if err := os.WriteFile(placeholdersOutputFile, output, 0644); err != nil {
+		logError(fmt.Sprintf("Failed to write report: %v", err))
+		return err
+	}
+	logSuccess(fmt.Sprintf("Wrote %d placeholders to %s", len(placeholders), placeholdersOutputFile))
+	return nil
+}
+
+// PlaceholderReport is the data given to --template.
+type PlaceholderReport struct {
+	Placeholders []Placeholder
+	Diagnostics  []diagnostics.Diagnostic
+	Counts       map[string]int
+	Total        int
+	// Owners counts placeholders per CODEOWNERS owner, empty without one
+	Owners []codeowners.Count
+}
+
+func placeholdersHaveOwners(placeholders []Placeholder) bool {
+	for _, p := range placeholders {
+		if len(p.Owners) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func writePlaceholdersTemplate(placeholders []Placeholder, ranked bool) error {
+	data := PlaceholderReport{Counts: make(map[string]int), Total: len(placeholders)}
+	for _, p := range placeholders {
+		data.Counts[p.Type]++
+	}
+	if placeholdersHaveOwners(placeholders) {
+		data.Owners = codeowners.Counts(placeholderDiagnostics(placeholders))
+	}
+
+	if ranked {
+		rankPlaceholders(placeholders)
+		if placeholdersTop > 0 && len(placeholders) > placeholdersTop {
+			placeholders = placeholders[:placeholdersTop]
+		}
+	}
+	data.Placeholders = placeholders
+	data.Diagnostics = placeholderDiagnostics(placeholders)
+
+	output, err := report.Render(placeholdersTemplate, data)
+	if err != nil {
+		logError(fmt.Sprintf("Failed to render template: %v", err))
+		return err
+	}
+
+	if placeholdersOutputFile != "" {
+		runManifest.AddResult(placeholdersOutputFile, output)
+		if err := os.WriteFile(placeholdersOutputFile, output, 0644); err != nil {
+			logError(fmt.Sprintf("Failed to write report: %v", err))
+			return err
+		}
+		logSuccess(fmt.Sprintf("Wrote %d placeholders to %s", len(placeholders), placeholdersOutputFile))
+		return nil
+	}
+
+	runManifest.AddResult("stdout", output)
+	fmt.Print(string(output))
+	return nil
+}
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/utils"
+)
+
+// Base weight of each marker, FIXME outranks TODO which outranks NOTE.
+var markerWeights = map[string]float64{
+	"FIXME": 5,
+	"BUG":   5,
+	"HACK":  4,
+	"XXX":   4,
+	"TODO":  3,
+	"NOTE":  1,
+}
+
+// Weights of placeholder types found without a marker comment.
+var placeholderTypeWeights = map[string]float64{
+	"hardcoded_secret": 5,
+	"unimplemented":    4,
+	"exception":        3,
+	"quick_fix":        3,
+	"debug_flag":       2,
+	"test_flag":        2,
+	"hardcoded_host":   2,
+	"ip_address":       2,
+}
+
+// Explicit priorities such as TODO(P1), [urgent], @high or priority: low.
+var priorityWeights = map[string]float64{
+	"p0":       6,
+	"critical": 6,
+	"p1":       4,
+	"urgent":   4,
+	"asap":     4,
+	"high":     4,
+	"p2":       2,
+	"medium":   2,
+	"p3":       0,
+	"low":      -1,
+}
+
+var (
+	markerRegex   = regexp.MustCompile(`\b(TODO|FIXME|HACK|XXX|BUG|NOTE)\b`)
+	priorityRegex = regexp.MustCompile(`(?i)(?:\(|\[|@|priority\s*[:=]?\s*)(p[0-3]|critical|urgent|asap|high|medium|low)\b`)
+)
+
+const (
+	// Every half year a placeholder has been left in the code adds a point.
+	ageDaysPerPoint = 180
+	maxAgeScore     = 6
+	// Every five points of cyclomatic complexity of the file's most complex
+	// function add a point.
+	complexityPerPoint = 5
+	maxComplexityScore = 4
+)
+
+// scorePlaceholders ranks the placeholders of one file. Ages come from git
+// blame and are left at zero outside a repository or for uncommitted lines.
+func scorePlaceholders(filePath string, placeholders []Placeholder) {
+	ages := blameAges(filePath)
+	complexity := fileComplexity(filePath)
+
+	for i := range placeholders {
+		p := &placeholders[i]
+
+		weight, ok := placeholderTypeWeights[p.Type]
+		if !ok {
+			weight = 1
+		}
+		if marker := markerRegex.FindString(p.Content); marker != "" {
+			p.Marker = marker
+			weight = markerWeights[marker]
+		}
+
+		if match := priorityRegex.FindStringSubmatch(p.Content); match != nil {
+			p.Priority = strings.ToLower(match[1])
+			weight += priorityWeights[p.Priority]
+		}
+
+		p.AgeDays = ages[p.Line]
+		weight += math.Min(float64(p.AgeDays)/ageDaysPerPoint, maxAgeScore)
+		weight += math.Min(float64(complexity)/complexityPerPoint, maxComplexityScore)
+
+		p.Score = math.Round(weight*10) / 10
+	}
+}
+
+// olderPlaceholders keeps the placeholders on lines older than minAgeDays.
+// Lines outside a repository or not committed yet have no age and are
+// dropped. Scored placeholders already carry their age.
+func olderPlaceholders(filePath string, placeholders []Placeholder, minAgeDays int, scored bool) []Placeholder {
+	var ages map[int]int
+	if !scored {
+		ages = blameAges(filePath)
+	}
+
+	var older []Placeholder
+	for _, p := range placeholders {
+		if !scored {
+			p.AgeDays = ages[p.Line]
+		}
+		if p.AgeDays > 0 && p.AgeDays >= minAgeDays {
+			older = append(older, p)
+		}
+	}
+	return older
+}
+
+// blameAges maps line numbers to the age in days of their last change.
+func blameAges(filePath string) map[int]int {
+	ages := make(map[int]int)
+
+	blame := exec.Command("git", "blame", "--line-porcelain", "--", filepath.Base(filePath))
+	blame.Dir = filepath.Dir(filePath)
+	output, err := blame.Output()
+	if err != nil {
+		return ages
+	}
+
+	now := time.Now()
+	line := 0
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+
+		// Each entry starts with "<sha> <original line> <final line>"
+		fields := strings.Fields(text)
+		if len(fields) >= 3 && len(fields[0]) == 40 {
+			line, _ = strconv.Atoi(fields[2])
+			continue
+		}
+
+		if timestamp, ok := strings.CutPrefix(text, "author-time "); ok {
+			seconds, err := strconv.ParseInt(timestamp, 10, 64)
+			if err == nil {
+				ages[line] = int(now.Sub(time.Unix(seconds, 0)).Hours() / 24)
+			}
+		}
+	}
+
+	return ages
+}
+
+// fileComplexity is the cyclomatic complexity of the most complex function in
+// the file, zero for languages without a parser.
+func fileComplexity(filePath string) int {
+	parser := statsParser(detectLanguage(filePath))
+	if parser == nil {
+		return 0
+	}
+
+	functions, err := parser.ParseFile(filePath)
+	if err != nil {
+		failures.Record(filePath, err)
+		return 0
+	}
+
+	complexity := 0
+	for _, fn := range functions {
+		complexity = max(complexity, fn.Complexity)
+	}
+	return complexity
+}
+
+func rankPlaceholders(placeholders []Placeholder) {
+	sort.SliceStable(placeholders, func(i, j int) bool {
+		return placeholders[i].Score > placeholders[j].Score
+	})
+}
+
+func displayRankedPlaceholders(placeholders []Placeholder) {
+	fmt.Printf("\n%s\n", color.Wrap(color.BoldCyan, "=== BACKLOG ==="))
+
+	for _, p := range placeholders {
+		var details []string
+		if p.Marker != "" {
+			details = append(details, p.Marker)
+		} else {
+			details = append(details, p.Type)
+		}
+		if p.Priority != "" {
+			details = append(details, p.Priority)
+		}
+		if p.AgeDays > 0 {
+			details = append(details, utils.HumanDuration(time.Duration(p.AgeDays)*24*time.Hour)+" old")
+		}
+
+		location := fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+		fmt.Printf("%6.1f  %s [%s] - %s\n",
+			p.Score, color.Wrap(color.Yellow, location), strings.Join(details, ", "), p.Content)
+	}
+}
+
+func formatPlaceholdersCSV(placeholders []Placeholder) (string, error) {
+	var sb strings.Builder
+	writer := csv.NewWriter(&sb)
+
+	writer.Write([]string{"score", "file", "line", "column", "type", "marker", "priority", "age_days", "content", "owners"})
+	for _, p := range placeholders {
+		writer.Write([]string{
+			strconv.FormatFloat(p.Score, 'f', 1, 64),
+			p.File,
+			strconv.Itoa(p.Line),
+			strconv.Itoa(p.Column),
+			p.Type,
+			p.Marker,
+			p.Priority,
+			strconv.Itoa(p.AgeDays),
+			p.Content,
+			strings.Join(p.Owners, " "),
+		})
+	}
+
+	writer.Flush()
+	return sb.String(), writer.Error()
+}
+
+func writePlaceholdersCSV(placeholders []Placeholder) error {
+	output, err := formatPlaceholdersCSV(placeholders)
+	if err != nil {
+		return err
+	}
+
+	if placeholdersOutputFile != "" {
+		runManifest.AddResult(placeholdersOutputFile, []byte(output))
+		return os.WriteFile(placeholdersOutputFile, []byte(output), 0644)
+	}
+
+	fmt.Print(output)
+	return nil
+}
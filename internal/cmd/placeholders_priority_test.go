@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScorePlaceholders(t *testing.T) {
+	// The most complex function has a complexity of 5, adding a point to
+	// every placeholder of the file
+	content := `int clamp(int x, int low, int high) {
+    if (x < low) {
+        return low;
+    }
+    if (x > high) {
+        return high;
+    }
+    for (int i = 0; i < 2; i++) {
+        while (x > 0) {
+            x--;
+        }
+    }
+    return x;
+}
+`
+	path := filepath.Join(t.TempDir(), "clamp.c")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if complexity := fileComplexity(path); complexity != 5 {
+		t.Fatalf("Expected a complexity of 5, got %d", complexity)
+	}
+
+	placeholders := []Placeholder{
+		{Line: 1, Content: "// TODO(P1): retry", Type: "todo"},
+		{Line: 2, Content: "# FIXME priority: low", Type: "todo"},
+		{Line: 3, Content: `const char *api_key = "abc123";`, Type: "hardcoded_secret"},
+		{Line: 4, Content: "// NOTE [urgent] see above", Type: "todo"},
+		{Line: 5, Content: "Example data", Type: "example_data"},
+		{Line: 6, Content: "// todo @HIGH", Type: "todo"},
+		{Line: 7, Content: "// TODO: highlight the low bits", Type: "todo"},
+	}
+	scorePlaceholders(path, placeholders)
+
+	expected := []struct {
+		marker, priority string
+		score            float64
+	}{
+		{"TODO", "p1", 3 + 4 + 1},
+		{"FIXME", "low", 5 - 1 + 1},
+		{"", "", 5 + 1},
+		{"NOTE", "urgent", 1 + 4 + 1},
+		// Unknown types without a marker weigh a point
+		{"", "", 1 + 1},
+		// Markers are upper case, priorities are not
+		{"", "high", 1 + 4 + 1},
+		// Priority words count only as TODO(P1), [urgent], @high or
+		// priority: low
+		{"TODO", "", 3 + 1},
+	}
+	for i, p := range placeholders {
+		want := expected[i]
+		if p.Marker != want.marker || p.Priority != want.priority || p.Score != want.score || p.AgeDays != 0 {
+			t.Errorf("%q: expected marker %q, priority %q and score %.1f, got %q, %q and %.1f (%d days old)",
+				p.Content, want.marker, want.priority, want.score, p.Marker, p.Priority, p.Score, p.AgeDays)
+		}
+	}
+
+	// Ranking puts the highest scores first and keeps the order of ties
+	rankPlaceholders(placeholders)
+	var lines []int
+	for _, p := range placeholders {
+		lines = append(lines, p.Line)
+	}
+	if want := []int{1, 3, 4, 6, 2, 7, 5}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected lines ranked %v, got %v", want, lines)
+	}
+}
+
+func TestPlaceholderAges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	git := func(date string, args ...string) {
+		t.Helper()
+		command := exec.Command("git", args...)
+		command.Dir = dir
+		command.Env = append(os.Environ(), "GIT_AUTHOR_NAME=gop", "GIT_AUTHOR_EMAIL=gop@example.com",
+			"GIT_COMMITTER_NAME=gop", "GIT_COMMITTER_EMAIL=gop@example.com", "GIT_AUTHOR_DATE="+date)
+		if output, err := command.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+	commit := func(content string, age time.Duration) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git("", "add", "-A")
+		git(time.Now().Add(-age).Format(time.RFC3339), "commit", "--quiet", "-m", "notes")
+	}
+
+	git("", "init", "--quiet")
+	commit("TODO: old\n", 400*24*time.Hour)
+	commit("TODO: old\nTODO: recent\n", 10*24*time.Hour)
+	if err := os.WriteFile(path, []byte("TODO: old\nTODO: recent\nTODO: uncommitted\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	placeholders := []Placeholder{
+		{Line: 1, Content: "TODO: old", Type: "todo"},
+		{Line: 2, Content: "TODO: recent", Type: "todo"},
+		{Line: 3, Content: "TODO: uncommitted", Type: "todo"},
+	}
+	scored := append([]Placeholder(nil), placeholders...)
+	scorePlaceholders(path, scored)
+
+	// Every half year adds a point, files without a parser add nothing for
+	// complexity
+	if old := scored[0]; old.AgeDays != 400 || old.Score != 3+2.2 {
+		t.Errorf("Expected the first line 400 days old and scored 5.2, got %+v", old)
+	}
+	if recent := scored[1]; recent.AgeDays != 10 || recent.Score != 3+0.1 {
+		t.Errorf("Expected the second line 10 days old and scored 3.1, got %+v", recent)
+	}
+	if uncommitted := scored[2]; uncommitted.AgeDays != 0 || uncommitted.Score != 3 {
+		t.Errorf("Expected uncommitted lines without an age, got %+v", uncommitted)
+	}
+
+	// Uncommitted lines are never old enough, whether or not they were
+	// scored first
+	for _, test := range []struct {
+		placeholders []Placeholder
+		scored       bool
+	}{{placeholders, false}, {scored, true}} {
+		older := olderPlaceholders(path, test.placeholders, 10, test.scored)
+		if len(older) != 2 || older[0].Line != 1 || older[1].Line != 2 {
+			t.Errorf("Expected the committed lines at least 10 days old, got %+v", older)
+		}
+		older = olderPlaceholders(path, test.placeholders, 90, test.scored)
+		if len(older) != 1 || older[0].AgeDays != 400 {
+			t.Errorf("Expected only the line older than 90 days, got %+v", older)
+		}
+	}
+}
+
+func TestPlaceholdersCSV(t *testing.T) {
+	output, err := formatPlaceholdersCSV([]Placeholder{
+		{File: "src/net.c", Line: 12, Column: 5, Content: `// FIXME(P0): "retry", then fail`, Type: "todo",
+			Marker: "FIXME", Priority: "p0", AgeDays: 30, Score: 11.2, Owners: []string{"@net", "@core"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "score,file,line,column,type,marker,priority,age_days,content,owners\n" +
+		`11.2,src/net.c,12,5,todo,FIXME,p0,30,"// FIXME(P0): ""retry"", then fail",@net @core` + "\n"
+	if output != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
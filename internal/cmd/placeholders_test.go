@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlaceholderEngines(t *testing.T) {
+	content := strings.Join([]string{
+		"// TODO(alice): handle retries",
+		"# FixMe later",
+		"const char *api_key = \"abc123\";",
+		"password = 'hunter2'",
+		"connect(\"LOCALHOST\", 8080); // localhost",
+		"addr = \"10.0.12.7\"",
+		"version = 1.2",
+		"if (Debug = true) { printf(\"x\"); }",
+		"testMode = true",
+		"throw new Exception(\"x\")",
+		"unreachable!()",
+		"NOT IMPLEMENTED yet",
+		"this is a Quick and dirty Band-Aid",
+		"Example data with a sample",
+		"exit(1); abort();",
+		"std::cout << value;",
+		"A simplification of the naive approach",
+		"ſimple unicode and ToDo: Ünïcode",
+		"nothing to see here",
+		"",
+	}, "\n")
+	path := filepath.Join(t.TempDir(), "sample.c")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(engine string) { placeholdersEngine = engine }(placeholdersEngine)
+	found := make(map[string][]Placeholder)
+	for _, engine := range []string{engineRegex, engineAhoCorasick} {
+		placeholdersEngine = engine
+		placeholders, err := scanFileForPlaceholders(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found[engine] = placeholders
+	}
+
+	if len(found[engineRegex]) < 20 {
+		t.Fatalf("Expected the sample to hold placeholders of every type, got %d", len(found[engineRegex]))
+	}
+	if !reflect.DeepEqual(found[engineRegex], found[engineAhoCorasick]) {
+		t.Errorf("Engines disagree:\nregex:        %+v\naho-corasick: %+v", found[engineRegex], found[engineAhoCorasick])
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/config"
+	"github.com/vitruves/gop/internal/plugins"
+)
+
+var (
+	pluginsConfigFile string
+	pluginsOutputFile string
+	pluginsFormat     string
+	pluginsTemplate   string
+	pluginsSelect     []string
+
+	pluginsFindings findingFlags
+)
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "Run external analyzers and merge their findings",
+	Long: `Run the external analyzers listed in the plugins section of .gop.yaml and merge their
+findings into one report. Each plugin reads a JSON request with the files to analyze on its
+standard input and writes its findings to its standard output in any report format gop
+reads (json, sarif, checkstyle, codeclimate):
+
+  plugins:
+    - name: banned-api
+      command: [python3, tools/banned_api.py]
+      languages: [c, cpp]
+      functions: true
+      timeout: 2m`,
+	Args: cobra.NoArgs,
+	RunE: runPlugins,
+}
+
+func init() {
+	pluginsCmd.Flags().StringVar(&pluginsConfigFile, "config", config.FileName, "YAML file whose plugins section lists the plugins")
+	pluginsCmd.Flags().StringVarP(&pluginsOutputFile, "output", "o", "", "Output file")
+	pluginsCmd.Flags().StringVarP(&pluginsFormat, "format", "f", "text", "Output format (text, checkstyle, codeclimate, json, sarif)")
+	pluginsCmd.Flags().StringVar(&pluginsTemplate, "template", "", "Go text/template file rendering the findings, replaces --format")
+	pluginsCmd.Flags().StringArrayVar(&pluginsSelect, "plugin", nil, "Only run this plugin (repeatable)")
+	addFindingFilterFlags(pluginsCmd, &pluginsFindings, true)
+}
+
+func runPlugins(cmd *cobra.Command, args []string) error {
+	file, err := config.Load(pluginsConfigFile)
+	if err != nil {
+		return err
+	}
+
+	pluginsConfig := plugins.Config{
+		Language:       language,
+		Include:        include,
+		Exclude:        exclude,
+		Recursive:      recursive,
+		Depth:          depth,
+		Jobs:           jobs,
+		Verbose:        verbose,
+		OutputFile:     pluginsOutputFile,
+		Format:         pluginsFormat,
+		Template:       pluginsTemplate,
+		Plugins:        file.Plugins,
+		Select:         pluginsSelect,
+		SkipGenerated:  skipGenerated,
+		SkipVendor:     skipVendor,
+		MaxFileSize:    maxFileSizeBytes,
+		FollowSymlinks: followSymlinks,
+		IncludeHidden:  includeHidden,
+		NoProgress:     noProgress,
+		Manifest:       runManifest,
+	}
+
+	return plugins.Run(pluginsConfig)
+}
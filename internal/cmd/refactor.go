@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/refactor"
+)
+
+var (
+	moveHeaderFrom        string
+	moveHeaderTo          string
+	moveHeaderIncludeDirs []string
+	moveHeaderDryRun      bool
+	moveHeaderBackup      bool
+)
+
+var refactorCmd = &cobra.Command{
+	Use:   "refactor",
+	Short: "Apply project-wide refactorings",
+}
+
+var moveHeaderCmd = &cobra.Command{
+	Use:   "move-header",
+	Short: "Move a header and update every #include of it",
+	Long: `Move a C/C++ header to a new path, rewrite every #include that refers to it, with quotes
+or angle brackets and relative to the including file or an include directory, and rename
+its include guard when the guard was derived from the old path.`,
+	RunE: runMoveHeader,
+}
+
+func init() {
+	moveHeaderCmd.Flags().StringVar(&moveHeaderFrom, "from", "", "Current path of the header")
+	moveHeaderCmd.Flags().StringVar(&moveHeaderTo, "to", "", "New path of the header")
+	moveHeaderCmd.Flags().StringSliceVarP(&moveHeaderIncludeDirs, "include-dir", "I", nil, "Include search directories used to resolve includes (default: current directory)")
+	moveHeaderCmd.Flags().BoolVar(&moveHeaderDryRun, "dry-run", false, "Show the changes without writing anything")
+	moveHeaderCmd.Flags().BoolVar(&moveHeaderBackup, "backup", false, "Keep a .bak copy of every modified file")
+	moveHeaderCmd.MarkFlagRequired("from")
+	moveHeaderCmd.MarkFlagRequired("to")
+
+	refactorCmd.AddCommand(moveHeaderCmd)
+}
+
+func runMoveHeader(cmd *cobra.Command, args []string) error {
+	config := refactor.MoveHeaderConfig{
+		From:           moveHeaderFrom,
+		To:             moveHeaderTo,
+		IncludeDirs:    moveHeaderIncludeDirs,
+		DryRun:         moveHeaderDryRun,
+		Backup:         moveHeaderBackup,
+		Include:        include,
+		Exclude:        exclude,
+		Depth:          depth,
+		SkipGenerated:  skipGenerated,
+		SkipVendor:     skipVendor,
+		MaxFileSize:    maxFileSizeBytes,
+		FollowSymlinks: followSymlinks,
+		IncludeHidden:  includeHidden,
+		Verbose:        verbose,
+		Manifest:       runManifest,
+	}
+
+	return refactor.MoveHeader(config)
+}
@@ -1,13 +1,29 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"runtime"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/config"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
 )
 
+// version is set at build time with -ldflags "-X github.com/vitruves/gop/internal/cmd.version=..."
+var version = "dev"
+
 var (
 	language  string
 	include   []string
@@ -16,17 +32,188 @@ var (
 	depth     int
 	jobs      int
 	verbose   bool
+
+	skipGenerated bool
+	skipVendor    bool
+	noProgress    bool
+	noColor       bool
+	maxFileSize   string
+
+	followSymlinks bool
+	includeHidden  bool
+
+	maxFileSizeBytes int64
+
+	pathStyle string
+
+	annotationsFile string
+
+	manifestFile string
+	runManifest  *manifest.Manifest
+
+	profile string
+
+	maxSkipped         string
+	maxSkippedFraction = 0.5
+
+	timeoutValue     string
+	fileTimeoutValue string
+	runTimeout       time.Duration
+	fileTimeout      time.Duration
 )
 
+// timeoutGrace is how long a run may go on after --timeout, for the analysis
+// outside the worker pools and the reports, before gop stops it.
+const timeoutGrace = 30 * time.Second
+
+// ExitSkipped is the exit status of a run that skipped more than
+// --max-skipped of its files due to errors, or reached --timeout.
+const ExitSkipped = 3
+
 var rootCmd = &cobra.Command{
 	Use:   "gop",
 	Short: "A tool to provide utilities to help code with AI",
 	Long: `gop is a CLI tool that provides various utilities to help with AI-assisted coding.
 It can concatenate code files, create function registries, find placeholders, and generate statistics.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		file, err := config.Load(config.FileName)
+		if err != nil {
+			return err
+		}
+		if profile != "" {
+			if err := applyProfile(cmd, file, profile); err != nil {
+				return fmt.Errorf("--profile: %w", err)
+			}
+		}
+
+		if noColor {
+			color.Disable()
+		}
+
+		size, err := utils.ParseSize(maxFileSize)
+		if err != nil {
+			return fmt.Errorf("--max-file-size: %w", err)
+		}
+		maxFileSizeBytes = size
+
+		fraction, err := parseFraction(maxSkipped)
+		if err != nil {
+			return fmt.Errorf("--max-skipped: %w", err)
+		}
+		maxSkippedFraction = fraction
+
+		if runTimeout, err = utils.ParseDuration(timeoutValue); err != nil {
+			return fmt.Errorf("--timeout: %w", err)
+		}
+		if fileTimeout, err = utils.ParseDuration(fileTimeoutValue); err != nil {
+			return fmt.Errorf("--file-timeout: %w", err)
+		}
+		worker.SetTimeouts(runTimeout, fileTimeout)
+		if runTimeout > 0 {
+			time.AfterFunc(runTimeout+timeoutGrace, func() {
+				fmt.Fprintf(os.Stderr, "Error: still running %s after --timeout %s, stopping\n", timeoutGrace, runTimeout)
+				os.Exit(ExitSkipped)
+			})
+		}
+
+		// from-root paths and fingerprints are relative to the repository,
+		// or to the working directory outside one
+		root := ""
+		if dir, err := gitRoot(); err == nil {
+			root = dir
+		}
+		if err := utils.SetPathStyle(pathStyle, root); err != nil {
+			return fmt.Errorf("--path-style: %w", err)
+		}
+
+		filter, err := findingFilterOf(cmd)
+		if err != nil {
+			return err
+		}
+		overrides, err := config.NewOverrides(".", file)
+		if err != nil {
+			return err
+		}
+		diagnostics.SetFilter(filter.WithOverrides(overrides.Keep))
+
+		annotations, err := config.LoadAnnotations(annotationsFile)
+		if err != nil {
+			return err
+		}
+		diagnostics.SetAnnotations(annotations)
+
+		if manifestFile != "" {
+			runManifest = manifest.New(buildInfo().Version, cmd.CommandPath(), args, flagValues(cmd))
+		}
+		return nil
+	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		for _, failure := range failures.Failures() {
+			runManifest.AddSkipped(utils.DisplayPath(failure.File), failure.Error)
+		}
+		if runManifest == nil {
+			return nil
+		}
+		if err := runManifest.Write(manifestFile); err != nil {
+			return fmt.Errorf("failed to write manifest: %w", err)
+		}
+		logInfo(fmt.Sprintf("Manifest written to %s", manifestFile))
+		return nil
+	},
 }
 
+// Execute runs the command line and reports the files skipped due to
+// errors, failing with a failures.TooManyError past --max-skipped.
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+
+	skipped := failures.Failures()
+	if len(skipped) == 0 {
+		return err
+	}
+	_, total := failures.Counts()
+	message := fmt.Sprintf("%s of %s files skipped due to errors", utils.FormatCount(len(skipped)), utils.FormatCount(total))
+	if !verbose {
+		message += ", --verbose lists them"
+	}
+	logWarning(message)
+	for _, failure := range skipped {
+		logInfo(fmt.Sprintf("  %s: %s", utils.DisplayPath(failure.File), failure.Error))
+	}
+
+	if err == nil && worker.TimedOut() {
+		err = fmt.Errorf("%w after --timeout %s, the results are partial", worker.ErrTimeout, runTimeout)
+	}
+	if err == nil {
+		err = failures.Check(maxSkippedFraction)
+	}
+	return err
+}
+
+// ExitCode is the exit status for the error Execute returned.
+func ExitCode(err error) int {
+	var tooMany *failures.TooManyError
+	if errors.As(err, &tooMany) || errors.Is(err, worker.ErrTimeout) {
+		return ExitSkipped
+	}
+	return 1
+}
+
+// parseFraction reads a fraction given as a number between 0 and 1 or as a
+// percentage.
+func parseFraction(value string) (float64, error) {
+	percent := strings.HasSuffix(value, "%")
+	fraction, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid fraction %q, expected e.g. 0.1 or 10%%", value)
+	}
+	if percent {
+		fraction /= 100
+	}
+	if fraction < 0 || fraction > 1 {
+		return 0, fmt.Errorf("%q is not between 0 and 1 (0%% and 100%%)", value)
+	}
+	return fraction, nil
 }
 
 func init() {
@@ -37,29 +224,214 @@ func init() {
 	rootCmd.PersistentFlags().IntVarP(&depth, "depth", "d", -1, "Maximum depth for recursive processing")
 	rootCmd.PersistentFlags().IntVarP(&jobs, "jobs", "j", runtime.NumCPU(), "Number of CPU cores to use")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().BoolVar(&skipGenerated, "skip-generated", true, "Skip generated files (\"DO NOT EDIT\", \"generated by\" markers, *.pb.go, ...)")
+	rootCmd.PersistentFlags().BoolVar(&skipVendor, "skip-vendor", true, "Skip vendored code (vendor/, third_party/, 3rdparty/, external/)")
+	rootCmd.PersistentFlags().StringVar(&maxFileSize, "max-file-size", "5MB", "Skip files larger than this (e.g. 500KB, 10MB, 0 for no limit); binary and minified files are always skipped")
+	rootCmd.PersistentFlags().BoolVar(&followSymlinks, "follow-symlinks", false, "Follow symbolic links to directories, symlinked files are always scanned (cycles are detected)")
+	rootCmd.PersistentFlags().BoolVar(&includeHidden, "include-hidden", false, "Include hidden files and directories (names starting with a dot)")
+	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false, "Disable progress reporting")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also disabled by NO_COLOR and when stdout is not a terminal)")
+	rootCmd.PersistentFlags().StringVar(&pathStyle, "path-style", utils.PathStyleRelative, "How file paths are written in reports: relative (to the working directory), absolute, or from-root (relative to the repository root)")
+	rootCmd.PersistentFlags().StringVar(&manifestFile, "manifest", "", "Write a JSON manifest of the run (version, options, input hashes, timing, result digests)")
+	rootCmd.PersistentFlags().StringVar(&maxSkipped, "max-skipped", "50%", "Exit with status 3 when more than this fraction of the files could not be read or parsed (e.g. 0.1 or 10%)")
+	rootCmd.PersistentFlags().StringVar(&timeoutValue, "timeout", "0", "Stop analyzing after this long (e.g. 10m or 1d) and report the files left as skipped, 0 for no limit")
+	rootCmd.PersistentFlags().StringVar(&fileTimeoutValue, "file-timeout", "0", "Skip a file whose analysis takes longer than this (e.g. 30s), 0 for no limit")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Take the flags not given on the command line from this profile of .gop.yaml")
+	rootCmd.PersistentFlags().StringVar(&annotationsFile, "annotations", config.AnnotationsFile, "YAML file annotating findings by fingerprint with comments, owners and ignore-until dates")
 
+	rootCmd.AddCommand(apiDiffCmd)
 	rootCmd.AddCommand(concatenateCmd)
+	rootCmd.AddCommand(ciCmd)
+	rootCmd.AddCommand(classGraphCmd)
+	rootCmd.AddCommand(compareCmd)
+	rootCmd.AddCommand(configMatrixCmd)
+	rootCmd.AddCommand(coverageCmd)
+	rootCmd.AddCommand(daemonCmd)
+	rootCmd.AddCommand(docsCLICmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(enumCheckCmd)
+	rootCmd.AddCommand(errorCheckCmd)
+	rootCmd.AddCommand(fixCmd)
+	rootCmd.AddCommand(forwardDeclCheckCmd)
 	rootCmd.AddCommand(functionRegistryCmd)
+	rootCmd.AddCommand(headerCheckCmd)
+	rootCmd.AddCommand(hotspotsCmd)
+	rootCmd.AddCommand(licenseCheckCmd)
+	rootCmd.AddCommand(lspCmd)
+	rootCmd.AddCommand(multiCmd)
+	rootCmd.AddCommand(openCmd)
+	rootCmd.AddCommand(ownersCmd)
 	rootCmd.AddCommand(placeholdersCmd)
+	rootCmd.AddCommand(pluginsCmd)
+	rootCmd.AddCommand(refactorCmd)
+	rootCmd.AddCommand(sanitizeTriageCmd)
+	rootCmd.AddCommand(selfTestCmd)
+	rootCmd.AddCommand(splitCmd)
+	rootCmd.AddCommand(stackUsageCmd)
 	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(tidyCmd)
+	rootCmd.AddCommand(tuiCmd)
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(warningsCmd)
+
+	rootCmd.Version = buildInfo().Version
+}
+
+func scanOptions() utils.ScanOptions {
+	return utils.ScanOptions{
+		Include:        include,
+		Exclude:        exclude,
+		Recursive:      recursive,
+		Depth:          depth,
+		SkipGenerated:  skipGenerated,
+		SkipVendor:     skipVendor,
+		MaxFileSize:    maxFileSizeBytes,
+		FollowSymlinks: followSymlinks,
+		IncludeHidden:  includeHidden,
+		Verbose:        verbose,
+	}
+}
+
+// findingFlags are the finding filters of a command reporting findings.
+type findingFlags struct {
+	excludes  []string
+	functions []string
+	where     []string
+}
+
+// findingFlagsOf maps the commands reporting findings to their filters.
+var findingFlagsOf = make(map[*cobra.Command]*findingFlags)
+
+// addFindingFilterFlags registers the finding filters on a command reporting
+// findings, --include-function only where findings name their function.
+func addFindingFilterFlags(cmd *cobra.Command, flags *findingFlags, functions bool) {
+	findingFlagsOf[cmd] = flags
+	cmd.Flags().StringArrayVar(&flags.excludes, "exclude-finding", nil, "Drop findings whose rule or message matches this regular expression (repeatable)")
+	cmd.Flags().StringArrayVar(&flags.where, "where", nil, "Only report findings matching this expression, e.g. \"severity>=high && file~'src/net/'\" (repeatable, all must match)")
+	if functions {
+		cmd.Flags().StringArrayVar(&flags.functions, "include-function", nil, "Only report findings in functions matching this regular expression (repeatable)")
+	}
+}
+
+// findingFilterOf builds the filter from the finding flags of the command,
+// an empty one for commands without them.
+func findingFilterOf(cmd *cobra.Command) (*diagnostics.Filter, error) {
+	flags, ok := findingFlagsOf[cmd]
+	if !ok {
+		flags = &findingFlags{}
+	}
+
+	filter, err := diagnostics.NewFilter(flags.excludes, flags.functions)
+	if err != nil {
+		return nil, err
+	}
+	for _, expression := range flags.where {
+		where, err := diagnostics.ParseWhere(expression)
+		if err != nil {
+			return nil, err
+		}
+		filter.WithWhere(where)
+	}
+	return filter, nil
+}
+
+// applyProfile sets the flags a profile gives values to, unless they are
+// given on the command line. Values for flags the command does not have are
+// skipped, so a profile can serve every command.
+func applyProfile(cmd *cobra.Command, file *config.File, name string) error {
+	root := cmd.Root()
+	command := strings.TrimPrefix(cmd.CommandPath(), root.Name()+" ")
+	values, err := file.Profile(name, command)
+	if err != nil {
+		return err
+	}
+
+	flags, commands := make(map[string]bool), make(map[string]bool)
+	var collect func(c *cobra.Command)
+	collect = func(c *cobra.Command) {
+		commands[strings.TrimPrefix(c.CommandPath(), root.Name()+" ")] = true
+		for _, set := range []*pflag.FlagSet{c.Flags(), c.PersistentFlags()} {
+			set.VisitAll(func(flag *pflag.Flag) { flags[flag.Name] = true })
+		}
+		for _, sub := range c.Commands() {
+			collect(sub)
+		}
+	}
+	collect(root)
+	for key, value := range file.Profiles[name] {
+		if _, section := value.(map[string]any); section && !commands[key] {
+			return fmt.Errorf("profile %q: unknown command %q", name, key)
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if !flags[key] || key == "profile" {
+			return fmt.Errorf("profile %q: unknown flag %q", name, key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		flag := cmd.Flags().Lookup(key)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		var list []string
+		if items, ok := values[key].([]any); ok {
+			for _, item := range items {
+				list = append(list, fmt.Sprint(item))
+			}
+		} else {
+			list = []string{fmt.Sprint(values[key])}
+		}
+
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			if err := slice.Replace(list); err != nil {
+				return fmt.Errorf("profile %q: %s: %w", name, key, err)
+			}
+			flag.Changed = true
+			continue
+		}
+		if len(list) != 1 {
+			return fmt.Errorf("profile %q: %s takes a single value", name, key)
+		}
+		if err := cmd.Flags().Set(key, list[0]); err != nil {
+			return fmt.Errorf("profile %q: %s: %w", name, key, err)
+		}
+	}
+	return nil
+}
+
+// flagValues captures every flag of the running command, defaults included,
+// so the manifest describes the full configuration.
+func flagValues(cmd *cobra.Command) map[string]string {
+	values := make(map[string]string)
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Name != "help" {
+			values[flag.Name] = flag.Value.String()
+		}
+	})
+	return values
 }
 
 func logInfo(msg string) {
 	if verbose {
-		fmt.Printf("\033[34m%s - INFO: %s\033[0m\n", getCurrentTime(), msg)
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
 	}
 }
 
 func logSuccess(msg string) {
-	fmt.Printf("\033[32m%s - SUCCESS: %s\033[0m\n", getCurrentTime(), msg)
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
 }
 
 func logWarning(msg string) {
-	fmt.Printf("\033[33m%s - WARNING: %s\033[0m\n", getCurrentTime(), msg)
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
 }
 
 func logError(msg string) {
-	fmt.Printf("\033[31m%s - ERROR: %s\033[0m\n", getCurrentTime(), msg)
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
 }
 
 func getCurrentTime() string {
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/vitruves/gop/internal/color"
+)
+
+func TestCommandsRegisteredOnce(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, command := range rootCmd.Commands() {
+		if seen[command.Name()] {
+			t.Errorf("Command %s is registered more than once", command.Name())
+		}
+		seen[command.Name()] = true
+	}
+}
+
+// Two commands binding a flag to the same variable would leak the value set
+// on one command into the other.
+func TestFlagsDoNotShareVariables(t *testing.T) {
+	for _, shared := range sharedFlags(rootCmd) {
+		t.Error(shared)
+	}
+
+	// Slice and array flags wrap their variable, sharing one must be found too
+	var values []string
+	root := &cobra.Command{Use: "root"}
+	for _, name := range []string{"a", "b"} {
+		command := &cobra.Command{Use: name}
+		command.Flags().StringArrayVar(&values, "value", nil, "")
+		root.AddCommand(command)
+	}
+	if shared := sharedFlags(root); len(shared) != 1 {
+		t.Errorf("Expected the shared --value to be found, got %v", shared)
+	}
+}
+
+// sharedFlags lists the flags of the command tree writing to the variable
+// of another flag.
+func sharedFlags(root *cobra.Command) []string {
+	var shared []string
+	owners := make(map[uintptr]string)
+
+	var visit func(command *cobra.Command)
+	visit = func(command *cobra.Command) {
+		command.LocalNonPersistentFlags().VisitAll(func(flag *pflag.Flag) {
+			destination := flagDestination(flag.Value)
+			if destination == 0 {
+				return
+			}
+			name := command.CommandPath() + " --" + flag.Name
+			if owner, ok := owners[destination]; ok {
+				shared = append(shared, fmt.Sprintf("%s shares its variable with %s", name, owner))
+			}
+			owners[destination] = name
+		})
+		for _, child := range command.Commands() {
+			visit(child)
+		}
+	}
+	visit(root)
+	return shared
+}
+
+// flagDestination returns the address of the variable a flag writes to.
+// Scalar flag values are that variable, while slice, array and map values
+// are structs holding a pointer to it.
+func flagDestination(value pflag.Value) uintptr {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Ptr {
+		return 0
+	}
+	if elem := v.Elem(); elem.Kind() == reflect.Struct {
+		if field := elem.FieldByName("value"); field.IsValid() && field.Kind() == reflect.Ptr {
+			return field.Pointer()
+		}
+	}
+	return v.Pointer()
+}
+
+// Output piped to a file or another program carries no escape codes, and
+// --no-color turns them off on a terminal too.
+func TestNoColor(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "lib.c"), []byte("// TODO: handle overflow\nint x;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	for _, args := range [][]string{
+		{"placeholders", "--no-progress"},
+		{"placeholders", "--no-progress", "--no-color"},
+	} {
+		var err error
+		output := captureStdout(t, func() {
+			err = runCommand(t, args...)
+		})
+		if err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+		if !strings.Contains(output, "lib.c:1:") || !strings.Contains(output, "SUCCESS") {
+			t.Errorf("Expected the placeholder and a log line from %v, got %q", args, output)
+		}
+		if strings.Contains(output, "\x1b[") {
+			t.Errorf("Expected no escape codes from %v, got %q", args, output)
+		}
+	}
+	if color.Enabled() {
+		t.Error("Expected --no-color to disable colors")
+	}
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/sanitize"
+)
+
+var (
+	sanitizeRoot       string
+	sanitizeSkipFrames []string
+	sanitizeCodeOwners string
+	sanitizeGroupBy    string
+	sanitizeOutputFile string
+	sanitizeFormat     string
+
+	sanitizeFindings findingFlags
+)
+
+var sanitizeTriageCmd = &cobra.Command{
+	Use:   "sanitize-triage [logs...]",
+	Short: "Deduplicate and group AddressSanitizer, UBSan and TSan reports from run logs",
+	Long: `Read AddressSanitizer, LeakSanitizer, UndefinedBehaviorSanitizer and ThreadSanitizer
+reports from test or run logs (standard input when no log is given). File paths are made
+relative to --root, also when the logs come from another machine or build directory.
+Reports sharing their root-cause frame, the first frame in the project, are grouped so
+each bug is listed once with how often it was hit, in the same diagnostics formats as
+the static checks.`,
+	RunE: runSanitizeTriage,
+}
+
+func init() {
+	sanitizeTriageCmd.Flags().StringVar(&sanitizeRoot, "root", ".", "Source tree that frames are resolved against")
+	sanitizeTriageCmd.Flags().StringSliceVar(&sanitizeSkipFrames, "skip-frame", nil, "Functions or files never taken as the root cause, e.g. allocation wrappers (glob patterns)")
+	sanitizeTriageCmd.Flags().StringVar(&sanitizeCodeOwners, "codeowners", "", "CODEOWNERS file routing reports to the owners of their root-cause frame, found under --root by default")
+	sanitizeTriageCmd.Flags().StringVar(&sanitizeGroupBy, "group-by", "", "Group the text report by owner, with per-owner counts")
+	sanitizeTriageCmd.Flags().StringVarP(&sanitizeOutputFile, "output", "o", "", "Output file")
+	sanitizeTriageCmd.Flags().StringVarP(&sanitizeFormat, "format", "f", "text", "Output format (text, checkstyle, codeclimate, json, sarif)")
+	addFindingFilterFlags(sanitizeTriageCmd, &sanitizeFindings, true)
+}
+
+func runSanitizeTriage(cmd *cobra.Command, args []string) error {
+	config := sanitize.Config{
+		Logs:       args,
+		Root:       sanitizeRoot,
+		SkipFrames: sanitizeSkipFrames,
+		CodeOwners: sanitizeCodeOwners,
+		GroupBy:    sanitizeGroupBy,
+		OutputFile: sanitizeOutputFile,
+		Format:     sanitizeFormat,
+		Verbose:    verbose,
+		Manifest:   runManifest,
+	}
+
+	return sanitize.Run(config)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/selftest"
+)
+
+var (
+	selfTestAnalyzers []string
+	selfTestUpdate    string
+	selfTestList      bool
+)
+
+var selfTestCmd = &cobra.Command{
+	Use:   "self-test",
+	Short: "Check the analyzers against the built-in test corpus",
+	Long: `Run every analyzer on a built-in corpus of small C/C++ files annotated with the findings
+expected on each line ("// EXPECT: <rule>"), and compare the full output with golden files.
+Packagers can run it to check a build; it exits non-zero when any analyzer differs.`,
+	Args: cobra.NoArgs,
+	RunE: runSelfTest,
+}
+
+func init() {
+	selfTestCmd.Flags().StringSliceVar(&selfTestAnalyzers, "analyzer", nil, "Analyzers to check (default: all, see --list)")
+	selfTestCmd.Flags().StringVar(&selfTestUpdate, "update", "", "Write the golden files to this directory instead of comparing against them")
+	selfTestCmd.Flags().BoolVar(&selfTestList, "list", false, "List the analyzers and the rules they report")
+}
+
+func runSelfTest(cmd *cobra.Command, args []string) error {
+	config := selftest.Config{
+		Analyzers: selfTestAnalyzers,
+		Update:    selfTestUpdate,
+		List:      selfTestList,
+		Verbose:   verbose,
+	}
+
+	return selftest.Run(config)
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/concatenate"
+)
+
+var (
+	splitOutputDir string
+	splitDryRun    bool
+	splitForce     bool
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split ARTIFACT SPLIT_MANIFEST",
+	Short: "Write the files of a concatenated output back to the tree",
+	Long: `Reconstruct the original files from the output of concatenate and the manifest written
+with its --split-manifest flag. Output edited since, by a reviewer for instance, is split on
+the file headers of --add-headers. Files whose comments, tests or secrets were removed when
+concatenating are only written with --force.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runSplit,
+}
+
+func init() {
+	splitCmd.Flags().StringVarP(&splitOutputDir, "output-dir", "o", ".", "Directory the files are written below")
+	splitCmd.Flags().BoolVar(&splitDryRun, "dry-run", false, "List the files that would be written without writing them")
+	splitCmd.Flags().BoolVar(&splitForce, "force", false, "Also write files that were altered when concatenating (--remove-comments, --remove-tests, --redact)")
+}
+
+func runSplit(cmd *cobra.Command, args []string) error {
+	config := concatenate.SplitConfig{
+		Artifact:      args[0],
+		SplitManifest: args[1],
+		OutputDir:     splitOutputDir,
+		DryRun:        splitDryRun,
+		Force:         splitForce,
+		Verbose:       verbose,
+		Manifest:      runManifest,
+	}
+
+	return concatenate.Split(config)
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/stackusage"
+	"github.com/vitruves/gop/internal/utils"
+)
+
+var (
+	stackUsageEntries      []string
+	stackUsageBudget       string
+	stackUsagePointerSize  int64
+	stackUsageCallOverhead int64
+	stackUsageOutputFile   string
+	stackUsageFormat       string
+)
+
+var stackUsageCmd = &cobra.Command{
+	Use:   "stack-usage",
+	Short: "Estimate the worst-case stack depth of each entry point",
+	Long: `Estimate the stack frame of every C/C++ function from the size of its local variables, then
+follow the call graph from each entry point to find its deepest path. Recursion and entry
+points exceeding --budget are flagged. Functions that nobody calls are the entry points
+unless --entry is given, so interrupt handlers and tasks are covered as well as main.
+
+Sizes are estimates: locals of every block are added up, registers and compiler
+temporaries are ignored, and calls through function pointers are not followed.`,
+	RunE: runStackUsage,
+}
+
+func init() {
+	stackUsageCmd.Flags().StringSliceVar(&stackUsageEntries, "entry", nil, "Entry point functions (default: functions nobody calls)")
+	stackUsageCmd.Flags().StringVar(&stackUsageBudget, "budget", "", "Stack budget per entry point (e.g. 2KB), larger entry points fail the run")
+	stackUsageCmd.Flags().Int64Var(&stackUsagePointerSize, "pointer-size", 4, "Size of pointers, long and size_t on the target in bytes")
+	stackUsageCmd.Flags().Int64Var(&stackUsageCallOverhead, "call-overhead", 8, "Bytes added to every frame for the return address and saved registers")
+	stackUsageCmd.Flags().StringVarP(&stackUsageOutputFile, "output", "o", "", "Output file (.md, .json or .csv)")
+	stackUsageCmd.Flags().StringVarP(&stackUsageFormat, "format", "f", "", "Output format (markdown, json, csv), defaults to the output file extension")
+}
+
+func runStackUsage(cmd *cobra.Command, args []string) error {
+	var budget int64
+	if stackUsageBudget != "" {
+		var err error
+		if budget, err = utils.ParseSize(stackUsageBudget); err != nil {
+			return err
+		}
+	}
+
+	config := stackusage.Config{
+		Language:       language,
+		Include:        include,
+		Exclude:        exclude,
+		Recursive:      recursive,
+		Depth:          depth,
+		Jobs:           jobs,
+		Verbose:        verbose,
+		Entries:        stackUsageEntries,
+		Budget:         budget,
+		PointerSize:    stackUsagePointerSize,
+		CallOverhead:   stackUsageCallOverhead,
+		OutputFile:     stackUsageOutputFile,
+		Format:         stackUsageFormat,
+		SkipGenerated:  skipGenerated,
+		SkipVendor:     skipVendor,
+		MaxFileSize:    maxFileSizeBytes,
+		FollowSymlinks: followSymlinks,
+		IncludeHidden:  includeHidden,
+		NoProgress:     noProgress,
+		Manifest:       runManifest,
+	}
+
+	// An entry point over budget is a finding, not a usage mistake
+	cmd.SilenceUsage = true
+	return stackusage.Run(config)
+}
@@ -2,19 +2,27 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
-	"sync"
 
-	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
-	"golang.org/x/sync/semaphore"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/errorcheck"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/registry"
+	"github.com/vitruves/gop/internal/report"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
 )
 
 type FileStats struct {
@@ -29,6 +37,7 @@ type FileStats struct {
 	Imports      int
 	Size         int64
 	Complexity   int
+	IsTest       bool
 }
 
 type CodebaseStats struct {
@@ -42,31 +51,88 @@ type CodebaseStats struct {
 	TotalImports      int
 	TotalSize         int64
 	LanguageStats     map[string]LanguageStats
-	FileStats         []FileStats
+	ExtensionStats    map[string]LanguageStats
+	SizeHistogram     []SizeBucket
+	// CodeGroups splits the totals into production and test code with --split-tests
+	CodeGroups       map[string]LanguageStats
+	FileStats        []FileStats
+	ComplexFunctions []registry.Function
+	// TestComplexFunctions is only filled with --split-tests, ComplexFunctions
+	// then covers production code alone
+	TestComplexFunctions []registry.Function
+	PlaceholderCounts    map[string]int
+	// Reliability is only filled with --reliability
+	Reliability *ReliabilityStats
+	// Quality is only filled with --quality
+	Quality *QualityStats
 }
 
 type LanguageStats struct {
-	Files        int
-	Lines        int
-	CodeLines    int
-	CommentLines int
-	Functions    int
-	Classes      int
+	Files        int   `json:"files"`
+	Lines        int   `json:"lines"`
+	CodeLines    int   `json:"code_lines"`
+	CommentLines int   `json:"comment_lines"`
+	BlankLines   int   `json:"blank_lines"`
+	Functions    int   `json:"functions"`
+	Classes      int   `json:"classes"`
+	Complexity   int   `json:"complexity"`
+	Size         int64 `json:"size_bytes"`
+}
+
+// SizeBucket counts the files whose size is below MaxBytes and at least the
+// previous bucket's limit. The last bucket has no limit.
+type SizeBucket struct {
+	Label    string `json:"label"`
+	MaxBytes int64  `json:"max_bytes,omitempty"`
+	Files    int    `json:"files"`
+	Lines    int    `json:"lines"`
 }
 
+// fileAnalysis is everything stats learns from a single file.
+type fileAnalysis struct {
+	stats        FileStats
+	functions    []registry.Function
+	placeholders []Placeholder
+	reliability  []FunctionReliability
+	errorScan    *errorcheck.FileResult
+}
+
+const topComplexFunctions = 10
+
 var (
 	statsOutputFile string
+	statsFormat     string
+	statsSplitTests bool
+	statsTestGlobs  []string
+	statsTemplate   string
+
+	statsReliability    bool
+	statsAssertPatterns []string
+	statsLogPatterns    []string
+
+	statsQuality  bool
+	statsFindings []string
 )
 
 var statsCmd = &cobra.Command{
 	Use:   "stats",
 	Short: "Generate comprehensive codebase statistics",
-	Long:  `Generate detailed statistics about your codebase including file counts, line counts, function counts, and complexity metrics.`,
-	RunE:  runStats,
+	Long: `Generate a one-page overview of your codebase: line counts by language, file counts,
+the most complex functions and placeholder counts by type.`,
+	RunE: runStats,
 }
 
 func init() {
-	statsCmd.Flags().StringVarP(&statsOutputFile, "output", "o", "", "Output file (.txt)")
+	statsCmd.Flags().StringVarP(&statsOutputFile, "output", "o", "", "Output file (.md, .json or .csv)")
+	statsCmd.Flags().StringVarP(&statsFormat, "format", "f", "", "Output format (markdown, json, csv), defaults to the output file extension")
+	statsCmd.Flags().StringVar(&statsTemplate, "template", "", "Go text/template file rendering the statistics, replaces --format")
+	statsCmd.Flags().BoolVar(&statsSplitTests, "split-tests", false, "Report production and test code separately")
+	statsCmd.Flags().StringSliceVar(&statsTestGlobs, "test-pattern", utils.DefaultTestPatterns, "Globs recognizing test files for --split-tests (file names, or paths with ** for directories)")
+	statsCmd.Flags().BoolVar(&statsReliability, "reliability", false, "Count assertions and log statements per function and module, and list exported functions without validation or logging")
+	statsCmd.Flags().StringArrayVar(&statsAssertPatterns, "assert-pattern", nil, "Regular expression recognizing an assertion line, replaces the defaults (repeatable)")
+	statsCmd.Flags().StringArrayVar(&statsLogPatterns, "log-pattern", nil, "Regular expression recognizing a log statement, replaces the defaults (repeatable)")
+	statsCmd.Flags().BoolVar(&statsQuality, "quality", false, "Score the project from its placeholder and error-check findings weighted by severity level per KLOC")
+	statsCmd.Flags().StringArrayVar(&statsFindings, "findings", nil, "Report of another analyzer to include in the quality score, in any diagnostics format (repeatable, implies --quality)")
 }
 
 func runStats(cmd *cobra.Command, args []string) error {
@@ -74,6 +140,36 @@ func runStats(cmd *cobra.Command, args []string) error {
 		logInfo("Starting codebase analysis")
 	}
 
+	var assertRegexes, logRegexes []*regexp.Regexp
+	if statsReliability {
+		assertPatterns, logPatterns := defaultAssertPatterns, defaultLogPatterns
+		if len(statsAssertPatterns) > 0 {
+			assertPatterns = statsAssertPatterns
+		}
+		if len(statsLogPatterns) > 0 {
+			logPatterns = statsLogPatterns
+		}
+
+		var err error
+		if assertRegexes, err = compilePatterns(assertPatterns); err != nil {
+			return err
+		}
+		if logRegexes, err = compilePatterns(logPatterns); err != nil {
+			return err
+		}
+	}
+
+	if len(statsFindings) > 0 {
+		statsQuality = true
+	}
+	var reported []diagnostics.Diagnostic
+	if statsQuality {
+		var err error
+		if reported, err = readFindingReports(statsFindings); err != nil {
+			return err
+		}
+	}
+
 	files, err := collectAllFiles()
 	if err != nil {
 		logError(fmt.Sprintf("Failed to collect files: %v", err))
@@ -88,56 +184,101 @@ func runStats(cmd *cobra.Command, args []string) error {
 	if verbose {
 		logInfo(fmt.Sprintf("Analyzing %d files", len(files)))
 	}
+	runManifest.AddFiles(files)
 
 	stats := &CodebaseStats{
-		LanguageStats: make(map[string]LanguageStats),
-		FileStats:     make([]FileStats, 0, len(files)),
+		LanguageStats:     make(map[string]LanguageStats),
+		ExtensionStats:    make(map[string]LanguageStats),
+		SizeHistogram:     newSizeHistogram(),
+		CodeGroups:        make(map[string]LanguageStats),
+		FileStats:         make([]FileStats, 0, len(files)),
+		PlaceholderCounts: make(map[string]int),
 	}
 
-	bar := progressbar.NewOptions(len(files),
-		progressbar.OptionSetDescription("Analyzing files"),
-		progressbar.OptionShowCount(),
-		progressbar.OptionSetRenderBlankState(true),
-		progressbar.OptionClearOnFinish(),
-	)
-
-	sem := semaphore.NewWeighted(int64(jobs))
-	var mu sync.Mutex
-	var wg sync.WaitGroup
+	results := worker.Run(files, jobs, progress.New("Analyzing files", len(files), noProgress), func(ctx context.Context, idx int, filePath string) fileAnalysis {
+		fileStats, err := analyzeFile(filePath)
+		if err != nil {
+			failures.Record(filePath, err)
+			return fileAnalysis{}
+		}
 
-	results := make([]FileStats, len(files))
+		fileStats.IsTest = statsSplitTests && utils.IsTestFile(filePath, statsTestGlobs)
+		analysis := fileAnalysis{stats: fileStats}
 
-	for i, file := range files {
-		wg.Add(1)
-		go func(idx int, filePath string) {
-			defer wg.Done()
-			sem.Acquire(context.Background(), 1)
-			defer sem.Release(1)
+		if parser := statsParser(fileStats.Language); parser != nil {
+			analysis.functions, err = parser.ParseFile(filePath)
+			if err != nil {
+				failures.Record(filePath, err)
+			}
+			for _, fn := range analysis.functions {
+				analysis.stats.Complexity += fn.Complexity
+			}
 
-			fileStats, err := analyzeFile(filePath)
+			analysis.placeholders, err = scanFileForPlaceholders(filePath)
 			if err != nil {
-				logError(fmt.Sprintf("Error analyzing %s: %v", filePath, err))
-				return
+				failures.Record(filePath, err)
 			}
 
-			mu.Lock()
-			results[idx] = fileStats
-			bar.Add(1)
-			mu.Unlock()
-		}(i, file)
-	}
+			if statsReliability || statsQuality {
+				if content, err := utils.ReadSourceFile(filePath); err == nil {
+					if statsReliability {
+						analysis.reliability = functionReliability(content, fileStats.Language, analysis.functions, assertRegexes, logRegexes)
+					}
+					if statsQuality && (fileStats.Language == "C" || fileStats.Language == "C++") {
+						scan := errorcheck.Scan(filePath, content)
+						analysis.errorScan = &scan
+					}
+				}
+			}
+		}
+
+		return analysis
+	})
+
+	var functions, testFunctions []registry.Function
+	var reliability []FunctionReliability
+	var placeholders []Placeholder
+	var errorScans []errorcheck.FileResult
+	for _, analysis := range results {
+		if analysis.stats.File == "" {
+			continue
+		}
 
-	wg.Wait()
-	bar.Finish()
+		stats.FileStats = append(stats.FileStats, analysis.stats)
+		updateStats(stats, analysis.stats)
 
-	for _, fileStats := range results {
-		if fileStats.File != "" {
-			stats.FileStats = append(stats.FileStats, fileStats)
-			updateStats(stats, fileStats)
+		if analysis.stats.IsTest {
+			testFunctions = append(testFunctions, analysis.functions...)
+		} else {
+			functions = append(functions, analysis.functions...)
+		}
+		for _, placeholder := range analysis.placeholders {
+			stats.PlaceholderCounts[placeholder.Type]++
+		}
+		reliability = append(reliability, analysis.reliability...)
+		placeholders = append(placeholders, analysis.placeholders...)
+		if analysis.errorScan != nil {
+			errorScans = append(errorScans, *analysis.errorScan)
 		}
 	}
 
+	stats.ComplexFunctions = mostComplex(functions, topComplexFunctions)
+	if statsSplitTests {
+		stats.TestComplexFunctions = mostComplex(testFunctions, topComplexFunctions)
+	}
+
+	if statsReliability {
+		stats.Reliability = summarizeReliability(reliability, statsTestGlobs)
+	}
+
+	if statsQuality {
+		findings := append(placeholderDiagnostics(placeholders), errorcheck.Check(errorScans, errorcheck.DefaultFunctions)...)
+		findings = append(diagnostics.Filtered(findings), reported...)
+		stats.Quality = summarizeQuality(findings, stats.TotalCodeLines)
+	}
+
 	stats.TotalFiles = len(stats.FileStats)
+	displayStatsPaths(stats)
 
 	err = displayStats(stats)
 	if err != nil {
@@ -150,72 +291,38 @@ func runStats(cmd *cobra.Command, args []string) error {
 }
 
 func collectAllFiles() ([]string, error) {
-	var files []string
-
-	startDir := "."
-	if len(include) > 0 {
-		for _, path := range include {
-			matches, err := filepath.Glob(path)
-			if err != nil {
-				return nil, err
-			}
-			files = append(files, matches...)
-		}
-		return files, nil
-	}
-
-	err := filepath.WalkDir(startDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if d.IsDir() {
-			if shouldExcludeDirStats(path, exclude) {
-				return filepath.SkipDir
-			}
-			if !recursive && path != startDir {
-				return filepath.SkipDir
-			}
-			if depth > 0 {
-				relPath, _ := filepath.Rel(startDir, path)
-				if strings.Count(relPath, string(filepath.Separator)) >= depth {
-					return filepath.SkipDir
-				}
-			}
-			return nil
-		}
-
-		if !shouldExcludeFileStats(path, exclude) {
-			files = append(files, path)
-		}
-
-		return nil
+	return utils.GetFilesToProcess(scanOptions(), func(path string) bool {
+		return true
 	})
-
-	return files, err
 }
 
-func analyzeFile(filePath string) (FileStats, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return FileStats{}, err
+// displayStatsPaths writes the file and directory paths of the stats in the
+// selected path style, once everything grouped by path is counted.
+func displayStatsPaths(stats *CodebaseStats) {
+	for i := range stats.FileStats {
+		stats.FileStats[i].File = utils.DisplayPath(stats.FileStats[i].File)
 	}
-	defer file.Close()
-
-	fileInfo, err := file.Stat()
-	if err != nil {
-		return FileStats{}, err
+	for _, functions := range [][]registry.Function{stats.ComplexFunctions, stats.TestComplexFunctions} {
+		for i := range functions {
+			functions[i].File = utils.DisplayPath(functions[i].File)
+		}
 	}
-
-	stats := FileStats{
-		File:     filePath,
-		Language: detectLanguage(filePath),
-		Size:     fileInfo.Size(),
+	if stats.Reliability == nil {
+		return
 	}
+	for _, functions := range [][]FunctionReliability{stats.Reliability.Functions, stats.Reliability.Unguarded} {
+		for i := range functions {
+			functions[i].File = utils.DisplayPath(functions[i].File)
+		}
+	}
+	for i := range stats.Reliability.Modules {
+		stats.Reliability.Modules[i].Module = utils.DisplayPath(stats.Reliability.Modules[i].Module)
+	}
+}
 
-	scanner := bufio.NewScanner(file)
-
-	functionRegexes := []*regexp.Regexp{
+// Line patterns counting functions, classes and imports in any language.
+var (
+	statsFunctionRegexes = []*regexp.Regexp{
 		regexp.MustCompile(`^\s*(def|async def)\s+\w+`),                                    // Python
 		regexp.MustCompile(`^\s*(pub\s+)?fn\s+\w+`),                                        // Rust
 		regexp.MustCompile(`^\s*func\s+\w+`),                                               // Go
@@ -223,19 +330,39 @@ func analyzeFile(filePath string) (FileStats, error) {
 		regexp.MustCompile(`^\s*(public|private|protected)?\s*(static\s+)?\w+\s+\w+\s*\(`), // Java/C#
 	}
 
-	classRegexes := []*regexp.Regexp{
+	statsClassRegexes = []*regexp.Regexp{
 		regexp.MustCompile(`^\s*class\s+\w+`),           // Python, C++, Java, C#
 		regexp.MustCompile(`^\s*(pub\s+)?struct\s+\w+`), // Rust
 		regexp.MustCompile(`^\s*type\s+\w+\s+struct`),   // Go
 	}
 
-	importRegexes := []*regexp.Regexp{
+	statsImportRegexes = []*regexp.Regexp{
 		regexp.MustCompile(`^\s*(import|from\s+\w+\s+import)`), // Python
 		regexp.MustCompile(`^\s*use\s+`),                       // Rust
 		regexp.MustCompile(`^\s*import\s+`),                    // Go, Java
 		regexp.MustCompile(`^\s*#include\s+`),                  // C/C++
 		regexp.MustCompile(`^\s*using\s+`),                     // C#
 	}
+)
+
+func analyzeFile(filePath string) (FileStats, error) {
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return FileStats{}, err
+	}
+
+	content, err := utils.ReadSourceFile(filePath)
+	if err != nil {
+		return FileStats{}, err
+	}
+
+	stats := FileStats{
+		File:     filePath,
+		Language: detectLanguage(filePath),
+		Size:     fileInfo.Size(),
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -251,21 +378,21 @@ func analyzeFile(filePath string) (FileStats, error) {
 			stats.CodeLines++
 		}
 
-		for _, regex := range functionRegexes {
+		for _, regex := range statsFunctionRegexes {
 			if regex.MatchString(line) {
 				stats.Functions++
 				break
 			}
 		}
 
-		for _, regex := range classRegexes {
+		for _, regex := range statsClassRegexes {
 			if regex.MatchString(line) {
 				stats.Classes++
 				break
 			}
 		}
 
-		for _, regex := range importRegexes {
+		for _, regex := range statsImportRegexes {
 			if regex.MatchString(line) {
 				stats.Imports++
 				break
@@ -335,6 +462,39 @@ func isCommentLine(line, language string) bool {
 	}
 }
 
+// statsParser returns the registry parser for a detected language, or nil
+// when functions of that language are not analyzed.
+func statsParser(language string) registry.LanguageParser {
+	switch language {
+	case "Python":
+		return registry.GetParser("python")
+	case "Rust":
+		return registry.GetParser("rust")
+	case "Go":
+		return registry.GetParser("go")
+	case "C":
+		return registry.GetParser("c")
+	case "C++":
+		return registry.GetParser("cpp")
+	default:
+		return nil
+	}
+}
+
+func mostComplex(functions []registry.Function, limit int) []registry.Function {
+	sort.SliceStable(functions, func(i, j int) bool {
+		if functions[i].Complexity != functions[j].Complexity {
+			return functions[i].Complexity > functions[j].Complexity
+		}
+		return functions[i].Size > functions[j].Size
+	})
+
+	if len(functions) > limit {
+		functions = functions[:limit]
+	}
+	return functions
+}
+
 func updateStats(stats *CodebaseStats, fileStats FileStats) {
 	stats.TotalLines += fileStats.Lines
 	stats.TotalCodeLines += fileStats.CodeLines
@@ -345,70 +505,254 @@ func updateStats(stats *CodebaseStats, fileStats FileStats) {
 	stats.TotalImports += fileStats.Imports
 	stats.TotalSize += fileStats.Size
 
-	langStats := stats.LanguageStats[fileStats.Language]
-	langStats.Files++
-	langStats.Lines += fileStats.Lines
-	langStats.CodeLines += fileStats.CodeLines
-	langStats.CommentLines += fileStats.CommentLines
-	langStats.Functions += fileStats.Functions
-	langStats.Classes += fileStats.Classes
-	stats.LanguageStats[fileStats.Language] = langStats
+	stats.LanguageStats[fileStats.Language] = addFileStats(stats.LanguageStats[fileStats.Language], fileStats)
+
+	if statsSplitTests {
+		group := "production"
+		if fileStats.IsTest {
+			group = "test"
+		}
+		stats.CodeGroups[group] = addFileStats(stats.CodeGroups[group], fileStats)
+	}
+
+	ext := fileExtension(fileStats.File)
+	stats.ExtensionStats[ext] = addFileStats(stats.ExtensionStats[ext], fileStats)
+
+	for i := range stats.SizeHistogram {
+		bucket := &stats.SizeHistogram[i]
+		if bucket.MaxBytes == 0 || fileStats.Size < bucket.MaxBytes {
+			bucket.Files++
+			bucket.Lines += fileStats.Lines
+			break
+		}
+	}
+}
+
+func addFileStats(group LanguageStats, fileStats FileStats) LanguageStats {
+	group.Files++
+	group.Lines += fileStats.Lines
+	group.CodeLines += fileStats.CodeLines
+	group.CommentLines += fileStats.CommentLines
+	group.BlankLines += fileStats.BlankLines
+	group.Functions += fileStats.Functions
+	group.Classes += fileStats.Classes
+	group.Complexity += fileStats.Complexity
+	group.Size += fileStats.Size
+	return group
+}
+
+func fileExtension(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		return "(none)"
+	}
+	return ext
+}
+
+func newSizeHistogram() []SizeBucket {
+	return []SizeBucket{
+		{Label: "0-1 KB", MaxBytes: 1 << 10},
+		{Label: "1-10 KB", MaxBytes: 10 << 10},
+		{Label: "10-100 KB", MaxBytes: 100 << 10},
+		{Label: "100 KB-1 MB", MaxBytes: 1 << 20},
+		{Label: "1 MB+"},
+	}
 }
 
 func displayStats(stats *CodebaseStats) error {
-	output := formatStats(stats)
+	format := statsFormat
+	if statsTemplate != "" {
+		format = "template"
+	}
+	if format == "" {
+		format = "markdown"
+		switch filepath.Ext(statsOutputFile) {
+		case ".json":
+			format = "json"
+		case ".csv":
+			format = "csv"
+		}
+	}
+
+	var output []byte
+	var err error
+
+	switch format {
+	case "template":
+		output, err = report.Render(statsTemplate, stats)
+	case "markdown", "md":
+		output = []byte(formatStats(stats))
+	case "json":
+		output, err = formatStatsJSON(stats)
+	case "csv":
+		output, err = formatStatsCSV(stats)
+	default:
+		return fmt.Errorf("unsupported format: %s (expected markdown, json or csv)", format)
+	}
+
+	if err != nil {
+		return err
+	}
 
 	if statsOutputFile != "" {
-		return os.WriteFile(statsOutputFile, []byte(output), 0644)
+		runManifest.AddResult(statsOutputFile, output)
+		return os.WriteFile(statsOutputFile, output, 0644)
 	} else {
-		fmt.Print(output)
+		runManifest.AddResult("stdout", output)
+		fmt.Print(string(output))
 		return nil
 	}
 }
 
+// sortedGroups orders language or extension groups by line count, largest first.
+func sortedGroups(groups map[string]LanguageStats) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if groups[names[i]].Lines != groups[names[j]].Lines {
+			return groups[names[i]].Lines > groups[names[j]].Lines
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func formatStatsJSON(stats *CodebaseStats) ([]byte, error) {
+	report := struct {
+		Files         int                      `json:"files"`
+		Lines         int                      `json:"lines"`
+		CodeLines     int                      `json:"code_lines"`
+		CommentLines  int                      `json:"comment_lines"`
+		BlankLines    int                      `json:"blank_lines"`
+		Functions     int                      `json:"functions"`
+		Classes       int                      `json:"classes"`
+		Imports       int                      `json:"imports"`
+		Size          int64                    `json:"size_bytes"`
+		Languages     map[string]LanguageStats `json:"languages"`
+		Extensions    map[string]LanguageStats `json:"extensions"`
+		SizeHistogram []SizeBucket             `json:"size_histogram"`
+		Code          map[string]LanguageStats `json:"code,omitempty"`
+		Placeholders  map[string]int           `json:"placeholders"`
+		Reliability   *ReliabilityStats        `json:"reliability,omitempty"`
+		Quality       *QualityStats            `json:"quality,omitempty"`
+	}{
+		Files:         stats.TotalFiles,
+		Lines:         stats.TotalLines,
+		CodeLines:     stats.TotalCodeLines,
+		CommentLines:  stats.TotalCommentLines,
+		BlankLines:    stats.TotalBlankLines,
+		Functions:     stats.TotalFunctions,
+		Classes:       stats.TotalClasses,
+		Imports:       stats.TotalImports,
+		Size:          stats.TotalSize,
+		Languages:     stats.LanguageStats,
+		Extensions:    stats.ExtensionStats,
+		SizeHistogram: stats.SizeHistogram,
+		Code:          stats.CodeGroups,
+		Placeholders:  stats.PlaceholderCounts,
+		Reliability:   stats.Reliability,
+		Quality:       stats.Quality,
+	}
+
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(output, '\n'), nil
+}
+
+// formatStatsCSV writes one row per language, extension, size bucket and,
+// with --split-tests, production and test code, so the breakdowns can be
+// loaded into a single table.
+func formatStatsCSV(stats *CodebaseStats) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	writer.Write([]string{"group", "name", "files", "lines", "code_lines", "comment_lines", "blank_lines", "complexity", "size_bytes"})
+
+	writeGroups := func(group string, groups map[string]LanguageStats) {
+		for _, name := range sortedGroups(groups) {
+			g := groups[name]
+			writer.Write([]string{group, name,
+				strconv.Itoa(g.Files), strconv.Itoa(g.Lines), strconv.Itoa(g.CodeLines),
+				strconv.Itoa(g.CommentLines), strconv.Itoa(g.BlankLines), strconv.Itoa(g.Complexity), strconv.FormatInt(g.Size, 10)})
+		}
+	}
+	writeGroups("language", stats.LanguageStats)
+	writeGroups("extension", stats.ExtensionStats)
+	writeGroups("code", stats.CodeGroups)
+
+	for _, bucket := range stats.SizeHistogram {
+		writer.Write([]string{"size", bucket.Label, strconv.Itoa(bucket.Files), strconv.Itoa(bucket.Lines), "", "", "", "", ""})
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
 func formatStats(stats *CodebaseStats) string {
 	var sb strings.Builder
 
 	sb.WriteString("# Codebase Statistics\n\n")
 
 	sb.WriteString("## Overall Summary\n")
-	sb.WriteString(fmt.Sprintf("- **Total Files**: %d\n", stats.TotalFiles))
-	sb.WriteString(fmt.Sprintf("- **Total Lines**: %d\n", stats.TotalLines))
-	sb.WriteString(fmt.Sprintf("- **Code Lines**: %d (%.1f%%)\n", stats.TotalCodeLines, percentage(stats.TotalCodeLines, stats.TotalLines)))
-	sb.WriteString(fmt.Sprintf("- **Comment Lines**: %d (%.1f%%)\n", stats.TotalCommentLines, percentage(stats.TotalCommentLines, stats.TotalLines)))
-	sb.WriteString(fmt.Sprintf("- **Blank Lines**: %d (%.1f%%)\n", stats.TotalBlankLines, percentage(stats.TotalBlankLines, stats.TotalLines)))
-	sb.WriteString(fmt.Sprintf("- **Total Functions**: %d\n", stats.TotalFunctions))
-	sb.WriteString(fmt.Sprintf("- **Total Classes**: %d\n", stats.TotalClasses))
-	sb.WriteString(fmt.Sprintf("- **Total Imports**: %d\n", stats.TotalImports))
-	sb.WriteString(fmt.Sprintf("- **Total Size**: %.2f MB\n", float64(stats.TotalSize)/(1024*1024)))
+	sb.WriteString(fmt.Sprintf("- **Total Files**: %s\n", utils.FormatCount(stats.TotalFiles)))
+	sb.WriteString(fmt.Sprintf("- **Total Lines**: %s\n", utils.FormatCount(stats.TotalLines)))
+	sb.WriteString(fmt.Sprintf("- **Code Lines**: %s (%.1f%%)\n", utils.FormatCount(stats.TotalCodeLines), percentage(stats.TotalCodeLines, stats.TotalLines)))
+	sb.WriteString(fmt.Sprintf("- **Comment Lines**: %s (%.1f%%)\n", utils.FormatCount(stats.TotalCommentLines), percentage(stats.TotalCommentLines, stats.TotalLines)))
+	sb.WriteString(fmt.Sprintf("- **Blank Lines**: %s (%.1f%%)\n", utils.FormatCount(stats.TotalBlankLines), percentage(stats.TotalBlankLines, stats.TotalLines)))
+	sb.WriteString(fmt.Sprintf("- **Total Functions**: %s\n", utils.FormatCount(stats.TotalFunctions)))
+	sb.WriteString(fmt.Sprintf("- **Total Classes**: %s\n", utils.FormatCount(stats.TotalClasses)))
+	sb.WriteString(fmt.Sprintf("- **Total Imports**: %s\n", utils.FormatCount(stats.TotalImports)))
+	sb.WriteString(fmt.Sprintf("- **Total Size**: %s\n", utils.HumanSize(stats.TotalSize)))
 	sb.WriteString("\n")
 
+	if len(stats.CodeGroups) > 0 {
+		sb.WriteString("## Production vs Test\n")
+		sb.WriteString("| Code | Files | Lines | Share | Code Lines | Functions | Total Complexity |\n")
+		sb.WriteString("|------|-------|-------|-------|------------|-----------|------------------|\n")
+		for _, group := range []string{"production", "test"} {
+			gs := stats.CodeGroups[group]
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %.1f%% | %s | %s | %s |\n",
+				group, utils.FormatCount(gs.Files), utils.FormatCount(gs.Lines), percentage(gs.Lines, stats.TotalLines),
+				utils.FormatCount(gs.CodeLines), utils.FormatCount(gs.Functions), utils.FormatCount(gs.Complexity)))
+		}
+		sb.WriteString("\n")
+	}
+
 	sb.WriteString("## Language Breakdown\n")
 
-	type langStat struct {
-		lang  string
-		stats LanguageStats
+	for _, lang := range sortedGroups(stats.LanguageStats) {
+		ls := stats.LanguageStats[lang]
+		sb.WriteString(fmt.Sprintf("### %s\n", lang))
+		sb.WriteString(fmt.Sprintf("- Files: %s\n", utils.FormatCount(ls.Files)))
+		sb.WriteString(fmt.Sprintf("- Lines: %s (%.1f%%)\n", utils.FormatCount(ls.Lines), percentage(ls.Lines, stats.TotalLines)))
+		sb.WriteString(fmt.Sprintf("- Code Lines: %s\n", utils.FormatCount(ls.CodeLines)))
+		sb.WriteString(fmt.Sprintf("- Comment Lines: %s\n", utils.FormatCount(ls.CommentLines)))
+		sb.WriteString(fmt.Sprintf("- Blank Lines: %s\n", utils.FormatCount(ls.BlankLines)))
+		sb.WriteString(fmt.Sprintf("- Functions: %s\n", utils.FormatCount(ls.Functions)))
+		sb.WriteString(fmt.Sprintf("- Classes: %s\n", utils.FormatCount(ls.Classes)))
+		sb.WriteString("\n")
 	}
 
-	var langStats []langStat
-	for lang, stat := range stats.LanguageStats {
-		langStats = append(langStats, langStat{lang, stat})
+	sb.WriteString("## Extension Breakdown\n")
+	sb.WriteString("| Extension | Files | Lines | Share | Code | Comments | Blank |\n")
+	sb.WriteString("|-----------|-------|-------|-------|------|----------|-------|\n")
+	for _, ext := range sortedGroups(stats.ExtensionStats) {
+		es := stats.ExtensionStats[ext]
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %.1f%% | %s | %s | %s |\n",
+			ext, utils.FormatCount(es.Files), utils.FormatCount(es.Lines), percentage(es.Lines, stats.TotalLines),
+			utils.FormatCount(es.CodeLines), utils.FormatCount(es.CommentLines), utils.FormatCount(es.BlankLines)))
 	}
+	sb.WriteString("\n")
 
-	sort.Slice(langStats, func(i, j int) bool {
-		return langStats[i].stats.Lines > langStats[j].stats.Lines
-	})
-
-	for _, ls := range langStats {
-		sb.WriteString(fmt.Sprintf("### %s\n", ls.lang))
-		sb.WriteString(fmt.Sprintf("- Files: %d\n", ls.stats.Files))
-		sb.WriteString(fmt.Sprintf("- Lines: %d (%.1f%%)\n", ls.stats.Lines, percentage(ls.stats.Lines, stats.TotalLines)))
-		sb.WriteString(fmt.Sprintf("- Code Lines: %d\n", ls.stats.CodeLines))
-		sb.WriteString(fmt.Sprintf("- Comment Lines: %d\n", ls.stats.CommentLines))
-		sb.WriteString(fmt.Sprintf("- Functions: %d\n", ls.stats.Functions))
-		sb.WriteString(fmt.Sprintf("- Classes: %d\n", ls.stats.Classes))
-		sb.WriteString("\n")
+	sb.WriteString("## Files by Size\n")
+	for _, bucket := range stats.SizeHistogram {
+		sb.WriteString(fmt.Sprintf("- %s: %s files, %s lines\n", bucket.Label, utils.FormatCount(bucket.Files), utils.FormatCount(bucket.Lines)))
 	}
+	sb.WriteString("\n")
 
 	sb.WriteString("## Top Files by Size\n")
 
@@ -423,43 +767,58 @@ func formatStats(stats *CodebaseStats) string {
 
 	for i := 0; i < maxFiles; i++ {
 		fs := stats.FileStats[i]
-		sb.WriteString(fmt.Sprintf("1. **%s** (%s) - %d lines, %d functions\n",
-			fs.File, fs.Language, fs.Lines, fs.Functions))
+		sb.WriteString(fmt.Sprintf("1. **%s** (%s) - %s lines, %d functions, %s\n",
+			fs.File, fs.Language, utils.FormatCount(fs.Lines), fs.Functions, utils.HumanSize(fs.Size)))
 	}
 
-	return sb.String()
-}
+	writeComplexFunctions(&sb, "Most Complex Functions", stats.ComplexFunctions)
+	writeComplexFunctions(&sb, "Most Complex Test Functions", stats.TestComplexFunctions)
 
-func percentage(part, total int) float64 {
-	if total == 0 {
-		return 0
+	if len(stats.PlaceholderCounts) > 0 {
+		sb.WriteString("\n## Placeholders by Type\n")
+
+		var types []string
+		for ptype := range stats.PlaceholderCounts {
+			types = append(types, ptype)
+		}
+		sort.Slice(types, func(i, j int) bool {
+			if stats.PlaceholderCounts[types[i]] != stats.PlaceholderCounts[types[j]] {
+				return stats.PlaceholderCounts[types[i]] > stats.PlaceholderCounts[types[j]]
+			}
+			return types[i] < types[j]
+		})
+
+		for _, ptype := range types {
+			sb.WriteString(fmt.Sprintf("- %s: %d\n", ptype, stats.PlaceholderCounts[ptype]))
+		}
 	}
-	return float64(part) / float64(total) * 100
+
+	if stats.Reliability != nil {
+		writeReliability(&sb, stats.Reliability)
+	}
+
+	if stats.Quality != nil {
+		writeQuality(&sb, stats.Quality)
+	}
+
+	return sb.String()
 }
 
-func shouldExcludeDirStats(path string, exclude []string) bool {
-	excludeDirs := []string{".git", "node_modules", "__pycache__", ".pytest_cache", "target", "build", "dist", "vendor"}
-	
-	for _, excludePattern := range exclude {
-		if matched, _ := filepath.Match(excludePattern, path); matched {
-			return true
-		}
+func writeComplexFunctions(sb *strings.Builder, title string, functions []registry.Function) {
+	if len(functions) == 0 {
+		return
 	}
-	
-	for _, excludeDir := range excludeDirs {
-		if strings.Contains(path, excludeDir) {
-			return true
-		}
+
+	sb.WriteString(fmt.Sprintf("\n## %s\n", title))
+	for _, fn := range functions {
+		sb.WriteString(fmt.Sprintf("1. `%s` (%s:%d) - complexity %d, %d lines\n",
+			fn.Name, fn.File, fn.Line, fn.Complexity, fn.Size))
 	}
-	
-	return false
 }
 
-func shouldExcludeFileStats(path string, exclude []string) bool {
-	for _, excludePattern := range exclude {
-		if matched, _ := filepath.Match(excludePattern, path); matched {
-			return true
-		}
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
 	}
-	return false
+	return float64(part) / float64(total) * 100
 }
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/utils"
+)
+
+// qualityHalfDensity is the weighted findings per thousand code lines that
+// halve the quality score.
+const qualityHalfDensity = 10.0
+
+const maxQualityRulesListed = 10
+
+// RuleQuality is the share of one rule in the weighted findings.
+type RuleQuality struct {
+	Rule     string            `json:"rule"`
+	Level    diagnostics.Level `json:"level"`
+	Findings int               `json:"findings"`
+	Weighted float64           `json:"weighted"`
+}
+
+// QualityStats weighs the findings of the analyzers by their level on the
+// shared scale, see diagnostics.LevelOf. Score goes from 100 without
+// findings down towards 0 as the weighted findings per KLOC grow.
+type QualityStats struct {
+	Findings int                       `json:"findings"`
+	Levels   map[diagnostics.Level]int `json:"levels"`
+	Weighted float64                   `json:"weighted_findings"`
+	KLOC     float64                   `json:"kloc"`
+	Density  float64                   `json:"weighted_per_kloc"`
+	Score    float64                   `json:"score"`
+	Rules    []RuleQuality             `json:"rules"`
+}
+
+// readFindingReports reads the findings of reports written by other gop
+// commands or tools, in any format diagnostics.Parse reads.
+func readFindingReports(paths []string) ([]diagnostics.Diagnostic, error) {
+	var findings []diagnostics.Diagnostic
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := diagnostics.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		findings = append(findings, parsed...)
+	}
+	return findings, nil
+}
+
+func summarizeQuality(findings []diagnostics.Diagnostic, codeLines int) *QualityStats {
+	stats := &QualityStats{Levels: make(map[diagnostics.Level]int), Rules: []RuleQuality{}}
+	for _, level := range diagnostics.Levels {
+		stats.Levels[level] = 0
+	}
+
+	rules := make(map[string]*RuleQuality)
+	for _, finding := range findings {
+		level := diagnostics.LevelOf(finding)
+		stats.Findings++
+		stats.Levels[level]++
+		stats.Weighted += level.Score()
+
+		rule, ok := rules[finding.Rule]
+		if !ok {
+			rule = &RuleQuality{Rule: finding.Rule, Level: level}
+			rules[finding.Rule] = rule
+		}
+		rule.Findings++
+		rule.Weighted += level.Score()
+		// A rule whose findings differ in severity shows its worst level
+		if level.Score() > rule.Level.Score() {
+			rule.Level = level
+		}
+	}
+
+	for _, rule := range rules {
+		stats.Rules = append(stats.Rules, *rule)
+	}
+	sort.Slice(stats.Rules, func(i, j int) bool {
+		if stats.Rules[i].Weighted != stats.Rules[j].Weighted {
+			return stats.Rules[i].Weighted > stats.Rules[j].Weighted
+		}
+		return stats.Rules[i].Rule < stats.Rules[j].Rule
+	})
+
+	stats.KLOC = float64(codeLines) / 1000
+	if stats.KLOC > 0 {
+		stats.Density = stats.Weighted / stats.KLOC
+	} else if stats.Weighted > 0 {
+		stats.Density = math.Inf(1)
+	}
+	stats.Score = 100 / (1 + stats.Density/qualityHalfDensity)
+	if math.IsInf(stats.Density, 1) {
+		stats.Density, stats.Score = 0, 0
+	}
+	stats.Score = math.Round(stats.Score*10) / 10
+	return stats
+}
+
+func writeQuality(sb *strings.Builder, stats *QualityStats) {
+	sb.WriteString("\n## Quality Score\n")
+	sb.WriteString(fmt.Sprintf("- **Score**: %.1f / 100\n", stats.Score))
+	sb.WriteString(fmt.Sprintf("- **Findings**: %s (%.1f weighted)\n", utils.FormatCount(stats.Findings), stats.Weighted))
+	sb.WriteString(fmt.Sprintf("- **Weighted per KLOC**: %.2f\n", stats.Density))
+	sb.WriteString("\n")
+
+	sb.WriteString("| Level | Weight | Findings |\n")
+	sb.WriteString("|-------|--------|----------|\n")
+	for i := len(diagnostics.Levels) - 1; i >= 0; i-- {
+		level := diagnostics.Levels[i]
+		sb.WriteString(fmt.Sprintf("| %s | %g | %s |\n", level, level.Score(), utils.FormatCount(stats.Levels[level])))
+	}
+
+	if len(stats.Rules) == 0 {
+		return
+	}
+	sb.WriteString("\n### Rules by Weight\n")
+	sb.WriteString("| Rule | Level | Findings | Weighted |\n")
+	sb.WriteString("|------|-------|----------|----------|\n")
+	for i, rule := range stats.Rules {
+		if i == maxQualityRulesListed {
+			break
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %.1f |\n", rule.Rule, rule.Level, utils.FormatCount(rule.Findings), rule.Weighted))
+	}
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/vitruves/gop/internal/diagnostics"
+)
+
+func TestSummarizeQuality(t *testing.T) {
+	findings := []diagnostics.Diagnostic{
+		{Severity: diagnostics.SeverityError, Rule: "placeholders/hardcoded_secret"},
+		{Severity: diagnostics.SeverityWarning, Rule: "errors/unchecked_call"},
+		{Severity: diagnostics.SeverityWarning, Rule: "errors/unchecked_call"},
+		{Severity: diagnostics.SeverityInfo, Rule: "placeholders/comment"},
+		{Severity: diagnostics.SeverityInfo, Rule: "includes/forward_declare"},
+	}
+
+	stats := summarizeQuality(findings, 3000)
+	if stats.Findings != 5 || stats.Weighted != 30 || stats.Density != 10 || stats.Score != 50 {
+		t.Errorf("Expected 5 findings weighing 30 over 3 KLOC for a score of 50, got %+v", stats)
+	}
+	if stats.Levels[diagnostics.LevelHigh] != 2 || stats.Levels[diagnostics.LevelInfo] != 1 || stats.Levels[diagnostics.LevelMedium] != 0 {
+		t.Errorf("Unexpected level counts %v", stats.Levels)
+	}
+	if len(stats.Rules) != 4 || stats.Rules[0].Rule != "placeholders/hardcoded_secret" || stats.Rules[1].Findings != 2 {
+		t.Errorf("Expected the rules ordered by weight, got %+v", stats.Rules)
+	}
+
+	if empty := summarizeQuality(nil, 0); empty.Score != 100 {
+		t.Errorf("Expected a perfect score without findings, got %v", empty.Score)
+	}
+	if unmeasured := summarizeQuality(findings, 0); unmeasured.Score != 0 {
+		t.Errorf("Expected a zero score for findings without code lines, got %v", unmeasured.Score)
+	}
+}
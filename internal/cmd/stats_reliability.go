@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vitruves/gop/internal/registry"
+	"github.com/vitruves/gop/internal/utils"
+)
+
+// FunctionReliability counts the assertions and log statements of a
+// function. Validates is set when it asserts or guards one of its parameters
+// with an early return, raise or panic.
+type FunctionReliability struct {
+	Name      string `json:"name"`
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Exported  bool   `json:"exported"`
+	Asserts   int    `json:"asserts"`
+	Logs      int    `json:"logs"`
+	Validates bool   `json:"validates_input"`
+}
+
+type ModuleReliability struct {
+	Module      string `json:"module"`
+	Functions   int    `json:"functions"`
+	Asserts     int    `json:"asserts"`
+	Logs        int    `json:"logs"`
+	WithAsserts int    `json:"functions_with_asserts"`
+	WithLogs    int    `json:"functions_with_logs"`
+}
+
+type ReliabilityStats struct {
+	Functions []FunctionReliability `json:"functions"`
+	Modules   []ModuleReliability   `json:"modules"`
+	// Unguarded lists exported functions taking parameters that neither
+	// validate them nor log anything
+	Unguarded []FunctionReliability `json:"unguarded"`
+}
+
+// Patterns recognizing assertions and log statements when --assert-pattern
+// and --log-pattern are not given.
+var (
+	defaultAssertPatterns = []string{
+		`^\s*assert\b`,
+		`\b(?:static_|debug_)?assert\w*!?\s*\(`,
+		`\b(?:D?CHECK|VERIFY|ENSURE|REQUIRE)(?:_[A-Z]+)?\s*\(`,
+	}
+	defaultLogPatterns = []string{
+		`\b(?:log|logger|logging|_log|slog|klog|glog|zap|zerolog|logrus|tracing|spdlog|console)\s*(?:\.|::)\s*\w+\s*\(`,
+		`\b(?:trace|debug|info|warn|error)!\s*\(`,
+		`\bLOG(?:_[A-Z]+)?\s*\(`,
+		`\bsyslog\s*\(`,
+		`\bfprintf\s*\(\s*stderr`,
+	}
+)
+
+var (
+	guardRegex = regexp.MustCompile(`\b(?:if|unless|guard)\b`)
+	exitRegex  = regexp.MustCompile(`\b(?:return|raise|throw|panic|abort|exit|bail|Err)\b|\b(?:panic|bail)!`)
+)
+
+// Lines after a guard condition searched for its early exit.
+const guardReach = 2
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	regexes := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+		regexes = append(regexes, regex)
+	}
+	return regexes, nil
+}
+
+func matchesAnyRegex(line string, regexes []*regexp.Regexp) bool {
+	for _, regex := range regexes {
+		if regex.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// functionReliability counts the assertion and log lines in the body of
+// each function, leaving comments out.
+func functionReliability(content, language string, functions []registry.Function, asserts, logs []*regexp.Regexp) []FunctionReliability {
+	lines := utils.SplitLines(content)
+	var result []FunctionReliability
+
+	for _, fn := range functions {
+		if fn.Metadata["declaration"] == "true" || fn.Line < 1 || fn.Line > len(lines) {
+			continue
+		}
+		end := min(fn.Line-1+max(fn.Size, 1), len(lines))
+		body := lines[fn.Line-1 : end]
+
+		var params []*regexp.Regexp
+		for _, param := range fn.Parameters {
+			if param != "" && param != "self" && param != "cls" && param != "this" {
+				params = append(params, regexp.MustCompile(`\b`+regexp.QuoteMeta(param)+`\b`))
+			}
+		}
+
+		metrics := FunctionReliability{
+			Name:     fn.Name,
+			File:     fn.File,
+			Line:     fn.Line,
+			Exported: fn.Visibility == "public" && !fn.IsTest && !fn.IsMain && len(params) > 0,
+		}
+		for i, line := range body {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || isCommentLine(trimmed, language) {
+				continue
+			}
+			if matchesAnyRegex(line, asserts) {
+				metrics.Asserts++
+			}
+			if matchesAnyRegex(line, logs) {
+				metrics.Logs++
+			}
+			if i > 0 && !metrics.Validates && guardRegex.MatchString(line) && matchesAnyRegex(line, params) {
+				guard := strings.Join(body[i:min(i+guardReach+1, len(body))], "\n")
+				metrics.Validates = exitRegex.MatchString(guard)
+			}
+		}
+		metrics.Validates = metrics.Validates || metrics.Asserts > 0
+
+		result = append(result, metrics)
+	}
+
+	return result
+}
+
+// summarizeReliability groups function metrics by directory and lists the
+// exported functions without any validation or logging, test files aside.
+func summarizeReliability(functions []FunctionReliability, testGlobs []string) *ReliabilityStats {
+	stats := &ReliabilityStats{Functions: functions}
+	modules := make(map[string]*ModuleReliability)
+
+	for _, fn := range functions {
+		dir := filepath.Dir(fn.File)
+		module, ok := modules[dir]
+		if !ok {
+			module = &ModuleReliability{Module: dir}
+			modules[dir] = module
+		}
+		module.Functions++
+		module.Asserts += fn.Asserts
+		module.Logs += fn.Logs
+		if fn.Asserts > 0 {
+			module.WithAsserts++
+		}
+		if fn.Logs > 0 {
+			module.WithLogs++
+		}
+
+		if fn.Exported && !fn.Validates && fn.Logs == 0 && !utils.IsTestFile(fn.File, testGlobs) {
+			stats.Unguarded = append(stats.Unguarded, fn)
+		}
+	}
+
+	for _, module := range modules {
+		stats.Modules = append(stats.Modules, *module)
+	}
+	sort.Slice(stats.Modules, func(i, j int) bool {
+		if stats.Modules[i].Functions != stats.Modules[j].Functions {
+			return stats.Modules[i].Functions > stats.Modules[j].Functions
+		}
+		return stats.Modules[i].Module < stats.Modules[j].Module
+	})
+	sort.SliceStable(stats.Unguarded, func(i, j int) bool {
+		if stats.Unguarded[i].File != stats.Unguarded[j].File {
+			return stats.Unguarded[i].File < stats.Unguarded[j].File
+		}
+		return stats.Unguarded[i].Line < stats.Unguarded[j].Line
+	})
+
+	return stats
+}
+
+// Unguarded functions listed in markdown, JSON has all of them.
+const maxUnguardedListed = 20
+
+func writeReliability(sb *strings.Builder, stats *ReliabilityStats) {
+	withAsserts, withLogs := 0, 0
+	for _, fn := range stats.Functions {
+		if fn.Asserts > 0 {
+			withAsserts++
+		}
+		if fn.Logs > 0 {
+			withLogs++
+		}
+	}
+
+	sb.WriteString("\n## Assertions and Logging\n")
+	sb.WriteString(fmt.Sprintf("- Functions: %s\n", utils.FormatCount(len(stats.Functions))))
+	sb.WriteString(fmt.Sprintf("- With Assertions: %s (%.1f%%)\n", utils.FormatCount(withAsserts), percentage(withAsserts, len(stats.Functions))))
+	sb.WriteString(fmt.Sprintf("- With Logging: %s (%.1f%%)\n", utils.FormatCount(withLogs), percentage(withLogs, len(stats.Functions))))
+	sb.WriteString("\n")
+
+	sb.WriteString("| Module | Functions | Asserts | Logs | With Asserts | With Logging |\n")
+	sb.WriteString("|--------|-----------|---------|------|--------------|--------------|\n")
+	for _, module := range stats.Modules {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %.1f%% | %.1f%% |\n",
+			module.Module, utils.FormatCount(module.Functions), utils.FormatCount(module.Asserts), utils.FormatCount(module.Logs),
+			percentage(module.WithAsserts, module.Functions), percentage(module.WithLogs, module.Functions)))
+	}
+
+	if len(stats.Unguarded) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("\n### Exported Functions Without Validation or Logging (%d)\n", len(stats.Unguarded)))
+	for i, fn := range stats.Unguarded {
+		if i == maxUnguardedListed {
+			sb.WriteString(fmt.Sprintf("- ... and %d more\n", len(stats.Unguarded)-maxUnguardedListed))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("- `%s` (%s:%d)\n", fn.Name, fn.File, fn.Line))
+	}
+}
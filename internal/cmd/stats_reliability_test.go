@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/vitruves/gop/internal/registry"
+)
+
+func TestFunctionReliability(t *testing.T) {
+	content := `package store
+
+func Open(path string) error {
+	if path == "" {
+		return errEmpty
+	}
+	return nil
+}
+
+func Save(key string, value []byte) {
+	// log.Printf("saving %s", key)
+	write(key, value)
+}
+
+func Flush(force bool) {
+	logger.Info("flushing")
+}
+
+func Close(s *Store) {
+	assert(s != nil)
+}
+`
+	functions := []registry.Function{
+		{Name: "Open", File: "store/store.go", Line: 3, Size: 6, Visibility: "public", Parameters: []string{"path"}},
+		{Name: "Save", File: "store/store.go", Line: 10, Size: 4, Visibility: "public", Parameters: []string{"key", "value"}},
+		{Name: "Flush", File: "store/store.go", Line: 15, Size: 3, Visibility: "public", Parameters: []string{"force"}},
+		{Name: "Close", File: "store/store_test.go", Line: 19, Size: 3, Visibility: "public", Parameters: []string{"s"}},
+	}
+
+	asserts, _ := compilePatterns(defaultAssertPatterns)
+	logs, _ := compilePatterns(defaultLogPatterns)
+	metrics := functionReliability(content, "go", functions, asserts, logs)
+
+	expected := []FunctionReliability{
+		{Name: "Open", Validates: true},
+		{Name: "Save"},
+		{Name: "Flush", Logs: 1},
+		{Name: "Close", Asserts: 1, Validates: true},
+	}
+	for i, want := range expected {
+		got := metrics[i]
+		if got.Asserts != want.Asserts || got.Logs != want.Logs || got.Validates != want.Validates {
+			t.Errorf("%s: got %+v, want asserts %d, logs %d, validates %v", want.Name, got, want.Asserts, want.Logs, want.Validates)
+		}
+	}
+
+	stats := summarizeReliability(metrics, []string{"*_test.go"})
+	if len(stats.Unguarded) != 1 || stats.Unguarded[0].Name != "Save" {
+		t.Errorf("unexpected unguarded functions: %+v", stats.Unguarded)
+	}
+	if len(stats.Modules) != 1 || stats.Modules[0].Functions != 4 || stats.Modules[0].WithLogs != 1 {
+		t.Errorf("unexpected modules: %+v", stats.Modules)
+	}
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/registry"
+)
+
+// statsProject writes files into a temporary directory and runs the test
+// from it, as stats scans the working directory.
+func statsProject(t *testing.T, files map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestStatsManifest(t *testing.T) {
+	statsProject(t, map[string]string{
+		"lib.c": `int add(int a, int b) {
+    return a + b;
+}
+`,
+		"lib.h": "int add(int a, int b);\n",
+	})
+
+	out := t.TempDir()
+	output := filepath.Join(out, "stats.json")
+	manifestPath := filepath.Join(out, "manifest.json")
+	t.Cleanup(func() { runManifest = nil })
+
+	command := parseArgs(t, "stats", "-o", output, "--manifest", manifestPath, "--no-progress")
+	if err := rootCmd.PersistentPreRunE(command, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := command.RunE(command, nil); err != nil {
+		t.Fatalf("stats failed: %v", err)
+	}
+	if err := rootCmd.PersistentPostRunE(command, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Files      int                      `json:"files"`
+		Extensions map[string]LanguageStats `json:"extensions"`
+		Histogram  []SizeBucket             `json:"size_histogram"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Files != 2 || report.Extensions[".c"].Files != 1 || report.Extensions[".h"].Files != 1 {
+		t.Errorf("Expected one .c and one .h file, got %+v", report)
+	}
+	if len(report.Histogram) == 0 || report.Histogram[0].Files != 2 {
+		t.Errorf("Expected both files in the smallest size bucket, got %+v", report.Histogram)
+	}
+
+	// The manifest lists the inputs and the digest of the report written
+	manifestData, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written manifest.Manifest
+	if err := json.Unmarshal(manifestData, &written); err != nil {
+		t.Fatal(err)
+	}
+	if written.FileCount != 2 {
+		t.Errorf("Expected 2 input files in the manifest, got %d", written.FileCount)
+	}
+	sum := sha256.Sum256(data)
+	if len(written.Results) != 1 || written.Results[0].Target != output || written.Results[0].SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected the digest of %s, got %+v", output, written.Results)
+	}
+}
+
+func TestStatsCSV(t *testing.T) {
+	statsProject(t, map[string]string{
+		"lib.c": `int add(int a, int b) {
+    return a + b;
+}
+`,
+		"lib.h": "int add(int a, int b);\n",
+		"test_add.c": `// test
+int test_add(void) {
+    return add(1, 2) == 3;
+}
+`,
+	})
+
+	// The format follows the output file extension
+	output := filepath.Join(t.TempDir(), "stats.csv")
+	if err := runCommand(t, "stats", "-o", output, "--split-tests", "--no-progress"); err != nil {
+		t.Fatalf("stats failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `group,name,files,lines,code_lines,comment_lines,blank_lines,complexity,size_bytes
+language,C,3,8,7,1,0,3,125
+extension,.c,2,7,6,1,0,2,102
+extension,.h,1,1,1,0,0,1,23
+code,production,2,4,4,0,0,2,67
+code,test,1,4,3,1,0,1,58
+size,0-1 KB,3,8,,,,,
+size,1-10 KB,0,0,,,,,
+size,10-100 KB,0,0,,,,,
+size,100 KB-1 MB,0,0,,,,,
+size,1 MB+,0,0,,,,,
+`
+	if string(data) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, data)
+	}
+}
+
+func TestStatsComplexFunctions(t *testing.T) {
+	statsProject(t, map[string]string{
+		"lib.c": `static const char *password = "hunter2";
+static const char *server = "localhost";
+static const char *backup = "localhost";
+
+int add(int a, int b) {
+    // TODO: handle overflow
+    return a + b;
+}
+
+int clamp(int x) {
+    if (x < 0) {
+        return 0;
+    }
+    return x;
+}
+
+int twice(int x) {
+    return 2 * x;
+}
+`,
+		"test_lib.c": `int test_clamp(void) {
+    // TODO: cover large values
+    if (clamp(-1) != 0) {
+        return 1;
+    }
+    if (clamp(1) != 1) {
+        return 1;
+    }
+    return 0;
+}
+`,
+	})
+
+	report := func(args ...string) string {
+		t.Helper()
+		output := filepath.Join(t.TempDir(), "stats.md")
+		if err := runCommand(t, append([]string{"stats", "-o", output, "--no-progress"}, args...)...); err != nil {
+			t.Fatalf("stats failed: %v", err)
+		}
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, sections, _ := strings.Cut(string(data), "\n## Most Complex Functions\n")
+		return sections
+	}
+
+	// Functions are ranked by complexity, then size, and placeholder types
+	// by count, then name
+	expected := "1. `test_clamp` (test_lib.c:1) - complexity 3, 10 lines\n" +
+		"1. `clamp` (lib.c:10) - complexity 2, 6 lines\n" +
+		"1. `add` (lib.c:5) - complexity 1, 4 lines\n" +
+		"1. `twice` (lib.c:17) - complexity 1, 3 lines\n" +
+		"\n## Placeholders by Type\n" +
+		"- comment: 2\n" +
+		"- hardcoded_host: 2\n" +
+		"- hardcoded_secret: 1\n"
+	if got := report(); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	// With --split-tests, test functions are ranked on their own
+	expected = "1. `clamp` (lib.c:10) - complexity 2, 6 lines\n" +
+		"1. `add` (lib.c:5) - complexity 1, 4 lines\n" +
+		"1. `twice` (lib.c:17) - complexity 1, 3 lines\n" +
+		"\n## Most Complex Test Functions\n" +
+		"1. `test_clamp` (test_lib.c:1) - complexity 3, 10 lines\n"
+	if got := report("--split-tests"); !strings.HasPrefix(got, expected) {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestMostComplex(t *testing.T) {
+	var functions []registry.Function
+	for i := 0; i < 2*topComplexFunctions; i++ {
+		functions = append(functions, registry.Function{Name: fmt.Sprintf("f%d", i), Complexity: i / 2, Size: i % 2})
+	}
+
+	// Ties on complexity go to the longer function
+	ranked := mostComplex(functions, topComplexFunctions)
+	if len(ranked) != topComplexFunctions {
+		t.Fatalf("Expected the %d most complex functions, got %d", topComplexFunctions, len(ranked))
+	}
+	last := 2*topComplexFunctions - 1
+	for i, fn := range ranked {
+		if want := fmt.Sprintf("f%d", last-i); fn.Name != want {
+			t.Errorf("Expected %s at rank %d, got %s", want, i+1, fn.Name)
+		}
+	}
+}
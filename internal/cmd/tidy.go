@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/tidy"
+)
+
+var (
+	tidyBuildDir   string
+	tidyClangTidy  string
+	tidyChecks     string
+	tidyGopChecks  bool
+	tidyOutputFile string
+	tidyFormat     string
+
+	tidyFindings findingFlags
+)
+
+var tidyCmd = &cobra.Command{
+	Use:   "tidy",
+	Short: "Run clang-tidy over the compilation database and merge its findings with gop's checks",
+	Long: `Run clang-tidy on every file of compile_commands.json in parallel, collect its findings
+through --export-fixes and report them together with gop's error-check findings on the same
+files, in one list and one format. Header findings repeated for every file including the
+header are listed once, and findings outside the current directory are dropped.
+
+When clang-tidy is not installed, gop's checks still run and a warning is printed.`,
+	Args: cobra.NoArgs,
+	RunE: runTidy,
+}
+
+func init() {
+	tidyCmd.Flags().StringVarP(&tidyBuildDir, "build-dir", "p", "", "Directory holding compile_commands.json (default: build if it has one, otherwise the current directory)")
+	tidyCmd.Flags().StringVar(&tidyClangTidy, "clang-tidy", "clang-tidy", "clang-tidy executable")
+	tidyCmd.Flags().StringVar(&tidyChecks, "checks", "", "Checks passed to clang-tidy --checks (default: the .clang-tidy files)")
+	tidyCmd.Flags().BoolVar(&tidyGopChecks, "gop-checks", true, "Also run gop's error-check on the same files")
+	tidyCmd.Flags().StringVarP(&tidyOutputFile, "output", "o", "", "Output file")
+	tidyCmd.Flags().StringVarP(&tidyFormat, "format", "f", "text", "Output format (text, checkstyle, codeclimate, json, sarif)")
+	addFindingFilterFlags(tidyCmd, &tidyFindings, false)
+}
+
+func runTidy(cmd *cobra.Command, args []string) error {
+	config := tidy.Config{
+		BuildDir:   tidyBuildDir,
+		ClangTidy:  tidyClangTidy,
+		Checks:     tidyChecks,
+		GopChecks:  tidyGopChecks,
+		Include:    include,
+		Exclude:    exclude,
+		Jobs:       jobs,
+		Verbose:    verbose,
+		OutputFile: tidyOutputFile,
+		Format:     tidyFormat,
+		NoProgress: noProgress,
+		Manifest:   runManifest,
+	}
+
+	return tidy.Run(config)
+}
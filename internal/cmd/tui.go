@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/errorcheck"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/registry"
+	"github.com/vitruves/gop/internal/tui"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
+)
+
+var (
+	tuiEditor  string
+	tuiReports []string
+	tuiSince   string
+
+	tuiFindings findingFlags
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Browse placeholders, diagnostics and hotspots in a terminal UI",
+	Long: `Scan the files once and browse the results in panes: placeholders as found by the
+placeholders command, error-check diagnostics of C/C++ files together with those of any
+--report, and hotspots ranked as by the hotspots command. Items can be filtered, sorted
+and opened at their line in the editor. Without a terminal the panes are listed instead.`,
+	RunE: runTUI,
+}
+
+func init() {
+	tuiCmd.Flags().StringVar(&tuiEditor, "editor", "", "Editor command line (default: $VISUAL, $EDITOR, code when installed, vi)")
+	tuiCmd.Flags().StringArrayVar(&tuiReports, "report", nil, "Checkstyle, codeclimate, json or sarif report of any command to add to the diagnostics pane (repeatable)")
+	tuiCmd.Flags().StringVar(&tuiSince, "since", "1y", "Count commits in this window for hotspot churn (e.g. 90d, 1y), 0 for the whole history")
+	addFindingFilterFlags(tuiCmd, &tuiFindings, true)
+}
+
+func runTUI(cmd *cobra.Command, args []string) error {
+	window, err := utils.ParseDuration(tuiSince)
+	if err != nil {
+		return fmt.Errorf("--since: %w", err)
+	}
+
+	var reported []diagnostics.Diagnostic
+	for _, report := range tuiReports {
+		data, err := os.ReadFile(report)
+		if err != nil {
+			return err
+		}
+		findings, err := diagnostics.Parse(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", report, err)
+		}
+		reported = append(reported, findings...)
+	}
+	runManifest.AddFiles(tuiReports)
+
+	placeholders, err := tuiPlaceholders()
+	if err != nil {
+		return err
+	}
+	findings, err := tuiDiagnostics()
+	if err != nil {
+		return err
+	}
+	hotspots, err := tuiHotspots(window)
+	if err != nil {
+		return err
+	}
+
+	return tui.Run(tui.Config{
+		Panes: []tui.Pane{
+			{Name: "Placeholders", Items: placeholders},
+			{Name: "Diagnostics", Items: diagnosticItems(diagnostics.Filtered(append(findings, reported...)))},
+			{Name: "Hotspots", Items: hotspots},
+		},
+		Editor: tuiEditor,
+	})
+}
+
+func tuiPlaceholders() ([]tui.Item, error) {
+	files, err := collectSourceFiles()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to collect files: %v", err))
+		return nil, err
+	}
+	runManifest.AddFiles(files)
+
+	found := worker.Run(files, jobs, progress.New("Scanning for placeholders", len(files), noProgress), func(ctx context.Context, idx int, filePath string) []Placeholder {
+		placeholders, err := scanFileForPlaceholders(filePath)
+		if err != nil {
+			failures.Record(filePath, err)
+			return nil
+		}
+		return placeholders
+	})
+
+	var items []tui.Item
+	for _, placeholders := range found {
+		for _, p := range placeholders {
+			if !diagnostics.Keep(placeholderDiagnostic(p)) {
+				continue
+			}
+			items = append(items, tui.Item{
+				File:   utils.DisplayPath(p.File),
+				Line:   p.Line,
+				Column: p.Column,
+				Kind:   p.Type,
+				Title:  p.Content,
+				Detail: p.Type,
+			})
+		}
+	}
+	return items, nil
+}
+
+// tuiDiagnostics runs the error check on the C and C++ files with its
+// default functions.
+func tuiDiagnostics() ([]diagnostics.Diagnostic, error) {
+	extensions := append(registry.GetParser("c").GetExtensions(), registry.GetParser("cpp").GetExtensions()...)
+	files, err := utils.GetFilesToProcess(scanOptions(), func(path string) bool {
+		return isValidSourceFile(path, extensions)
+	})
+	if err != nil {
+		logError(fmt.Sprintf("Failed to collect files: %v", err))
+		return nil, err
+	}
+
+	results := worker.Run(files, jobs, progress.New("Checking error handling", len(files), noProgress), func(ctx context.Context, idx int, filePath string) errorcheck.FileResult {
+		content, err := utils.ReadSourceFile(filePath)
+		if err != nil {
+			failures.Record(filePath, err)
+			return errorcheck.FileResult{}
+		}
+		return errorcheck.Scan(filePath, content)
+	})
+	return errorcheck.Check(results, errorcheck.DefaultFunctions), nil
+}
+
+func diagnosticItems(findings []diagnostics.Diagnostic) []tui.Item {
+	items := make([]tui.Item, 0, len(findings))
+	for _, finding := range findings {
+		detail := finding.Rule
+		if finding.Function != "" {
+			detail += " in " + finding.Function
+		}
+		items = append(items, tui.Item{
+			File:   filepath.FromSlash(finding.File),
+			Line:   finding.Line,
+			Column: finding.Column,
+			Kind:   string(finding.Severity),
+			Title:  fmt.Sprintf("%s: %s", finding.Severity, finding.Message),
+			Detail: detail,
+		})
+	}
+	return items
+}
+
+func tuiHotspots(window time.Duration) ([]tui.Item, error) {
+	owners, err := openCodeOwners("")
+	if err != nil {
+		return nil, err
+	}
+	files, err := utils.GetFilesToProcess(scanOptions(), func(path string) bool {
+		return statsParser(detectLanguage(path)) != nil
+	})
+	if err != nil {
+		logError(fmt.Sprintf("Failed to collect files: %v", err))
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	var since time.Time
+	if window > 0 {
+		since = time.Now().Add(-window)
+	}
+	hotspots, err := collectHotspots(files, owners, since, false)
+	if err != nil {
+		return nil, err
+	}
+	return hotspotItems(hotspots), nil
+}
+
+func hotspotItems(hotspots []Hotspot) []tui.Item {
+	items := make([]tui.Item, 0, len(hotspots))
+	for _, hotspot := range hotspots {
+		kind := "file"
+		if hotspot.GodFile {
+			kind = "god file"
+		}
+		factors := strings.Join(hotspot.Factors, ", ")
+		if factors == "" {
+			factors = "none in the top tenth"
+		}
+		items = append(items, tui.Item{
+			File:   hotspot.File,
+			Kind:   kind,
+			Title:  fmt.Sprintf("score %.1f, %d code lines, complexity %d, %d commits", hotspot.Score, hotspot.CodeLines, hotspot.Complexity, hotspot.Churn),
+			Detail: fmt.Sprintf("%s, factors: %s", kind, factors),
+		})
+	}
+	return items
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Set at build time with -ldflags "-X github.com/vitruves/gop/internal/cmd.commit=...",
+// otherwise taken from the VCS stamp Go embeds in the binary.
+var (
+	commit    = ""
+	buildDate = ""
+)
+
+// latestReleaseURL is replaced by tests
+var latestReleaseURL = "https://api.github.com/repos/vitruves/gop/releases/latest"
+
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+type UpdateCheck struct {
+	Latest          string `json:"latest"`
+	URL             string `json:"url,omitempty"`
+	UpdateAvailable bool   `json:"update_available"`
+}
+
+var (
+	versionJSON  bool
+	versionCheck bool
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show version and build information",
+	Long: `Show the gop version with the commit, build date and Go version it was built with.
+With --check, also ask GitHub whether a newer release is available.`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
+}
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "Print build information as JSON")
+	versionCmd.Flags().BoolVar(&versionCheck, "check", false, "Check GitHub releases for a newer version")
+}
+
+// buildInfo combines the ldflags values with the module version and VCS
+// stamp of the binary, so go install and go build both report something useful.
+func buildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	if info.Version == "dev" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.BuildDate == "" {
+				info.BuildDate = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+
+	return info
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	info := buildInfo()
+
+	var update *UpdateCheck
+	if versionCheck {
+		var err error
+		update, err = checkLatestRelease(info.Version)
+		if err != nil {
+			logError(fmt.Sprintf("Failed to check for updates: %v", err))
+			return err
+		}
+	}
+
+	if versionJSON {
+		output := struct {
+			BuildInfo
+			Update *UpdateCheck `json:"update,omitempty"`
+		}{info, update}
+
+		data, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("gop version %s\n", info.Version)
+	if info.Commit != "" {
+		commit := info.Commit
+		if info.Modified {
+			commit += " (modified)"
+		}
+		fmt.Printf("  commit:     %s\n", commit)
+	}
+	if info.BuildDate != "" {
+		fmt.Printf("  built:      %s\n", info.BuildDate)
+	}
+	fmt.Printf("  go version: %s\n", info.GoVersion)
+	fmt.Printf("  platform:   %s\n", info.Platform)
+
+	if update != nil {
+		if update.UpdateAvailable {
+			logWarning(fmt.Sprintf("A newer version is available: %s (%s)", update.Latest, update.URL))
+		} else {
+			logSuccess(fmt.Sprintf("gop is up to date (latest release %s)", update.Latest))
+		}
+	}
+
+	return nil
+}
+
+func checkLatestRelease(current string) (*UpdateCheck, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	request, err := http.NewRequest(http.MethodGet, latestReleaseURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/vnd.github+json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub returned %s", response.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&release); err != nil {
+		return nil, err
+	}
+
+	return &UpdateCheck{
+		Latest:          release.TagName,
+		URL:             release.HTMLURL,
+		UpdateAvailable: compareVersions(release.TagName, current) > 0,
+	}, nil
+}
+
+// compareVersions orders dotted versions such as v1.2.10 numerically. A
+// development build is older than any release.
+func compareVersions(a, b string) int {
+	if b == "dev" {
+		return 1
+	}
+
+	partsA := versionParts(a)
+	partsB := versionParts(b)
+	for i := 0; i < max(len(partsA), len(partsB)); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x = partsA[i]
+		}
+		if i < len(partsB) {
+			y = partsB[i]
+		}
+		if x != y {
+			if x > y {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
+
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(version, "v")
+	// Pre-release and build suffixes are ignored
+	if idx := strings.IndexAny(version, "-+"); idx != -1 {
+		version = version[:idx]
+	}
+
+	var parts []int
+	for _, part := range strings.Split(version, ".") {
+		n, _ := strconv.Atoi(part)
+		parts = append(parts, n)
+	}
+	return parts
+}
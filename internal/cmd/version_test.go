@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestVersionCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"tag_name": "v1.10.0", "html_url": "https://github.com/vitruves/gop/releases/tag/v1.10.0"}`))
+	}))
+	defer server.Close()
+	defer func(url string) { latestReleaseURL = url }(latestReleaseURL)
+	latestReleaseURL = server.URL
+
+	defer func(v string) { version = v }(version)
+	for current, newer := range map[string]bool{"v1.9.3": true, "v1.10.0": false, "v1.10.1-rc1": false, "dev": true} {
+		version = current
+
+		var err error
+		output := captureStdout(t, func() {
+			err = runCommand(t, "version", "--json", "--check")
+		})
+		if err != nil {
+			t.Fatalf("version %s failed: %v", current, err)
+		}
+
+		var report struct {
+			BuildInfo
+			Update *UpdateCheck `json:"update"`
+		}
+		if err := json.Unmarshal([]byte(output), &report); err != nil {
+			t.Fatalf("Expected JSON, got %q: %v", output, err)
+		}
+		if report.Version != current || report.GoVersion != runtime.Version() {
+			t.Errorf("Expected version %s built with %s, got %+v", current, runtime.Version(), report.BuildInfo)
+		}
+		if report.Update == nil || report.Update.Latest != "v1.10.0" || report.Update.UpdateAvailable != newer {
+			t.Errorf("Expected an update to be available from %s: %v, got %+v", current, newer, report.Update)
+		}
+	}
+
+	// A failed check fails the command
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+	latestReleaseURL = missing.URL
+	if err := runCommand(t, "version", "--check"); err == nil {
+		t.Error("Expected an unreachable release to fail the check")
+	}
+}
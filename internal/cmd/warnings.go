@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vitruves/gop/internal/warnings"
+)
+
+var (
+	warningsInputs     []string
+	warningsCompiler   string
+	warningsRoot       string
+	warningsExternal   bool
+	warningsHistory    string
+	warningsOutputFile string
+	warningsFormat     string
+
+	warningsFindings findingFlags
+)
+
+var warningsCmd = &cobra.Command{
+	Use:   "warnings",
+	Short: "Collect compiler warnings and errors from a build log as diagnostics",
+	Long: `Parse the warnings and errors of gcc, clang or MSVC from build logs (standard input when
+no --input is given) into gop's diagnostics, with paths relative to --root. Warnings repeated
+for every file including a header are listed once, and those in system or third-party
+headers outside the root are dropped. Each warning is named after its flag or code, e.g.
+compiler/unused-variable or compiler/C4996.
+
+With --history, the counts of the run are appended to a JSON file so the number of warnings
+can be followed over time.`,
+	Args: cobra.NoArgs,
+	RunE: runWarnings,
+}
+
+func init() {
+	warningsCmd.Flags().StringSliceVar(&warningsInputs, "input", nil, "Build logs to read (default: standard input)")
+	warningsCmd.Flags().StringVar(&warningsCompiler, "compiler", "", "Compiler output format (gcc, clang, msvc), both formats are recognized when empty")
+	warningsCmd.Flags().StringVar(&warningsRoot, "root", ".", "Source tree that paths are resolved against")
+	warningsCmd.Flags().BoolVar(&warningsExternal, "external", false, "Keep diagnostics in files outside the source tree")
+	warningsCmd.Flags().StringVar(&warningsHistory, "history", "", "JSON file the counts of this run are appended to")
+	warningsCmd.Flags().StringVarP(&warningsOutputFile, "output", "o", "", "Output file")
+	warningsCmd.Flags().StringVarP(&warningsFormat, "format", "f", "text", "Output format (text, checkstyle, codeclimate, json, sarif)")
+	addFindingFilterFlags(warningsCmd, &warningsFindings, false)
+}
+
+func runWarnings(cmd *cobra.Command, args []string) error {
+	config := warnings.Config{
+		Inputs:      warningsInputs,
+		Compiler:    warningsCompiler,
+		Root:        warningsRoot,
+		External:    warningsExternal,
+		HistoryFile: warningsHistory,
+		OutputFile:  warningsOutputFile,
+		Format:      warningsFormat,
+		Verbose:     verbose,
+		Manifest:    runManifest,
+	}
+
+	return warnings.Run(config)
+}
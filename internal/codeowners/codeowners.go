@@ -0,0 +1,205 @@
+package codeowners
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/utils"
+)
+
+// Rule is one CODEOWNERS line, later rules take precedence.
+type Rule struct {
+	Patterns []string
+	Owners   []string
+}
+
+// Locations are where GitHub and GitLab look for the file, relative to the
+// repository root.
+var Locations = []string{"CODEOWNERS", ".github/CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// Unowned stands for the owner of findings no rule matches.
+const Unowned = "(unowned)"
+
+// Find returns the CODEOWNERS file of the repository at root, or "".
+func Find(root string) string {
+	for _, location := range Locations {
+		path := filepath.Join(root, location)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+func Load(path string) ([]Rule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rules []Rule
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") {
+			continue
+		}
+		if idx := strings.Index(line, " #"); idx != -1 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		rules = append(rules, Rule{Patterns: Patterns(fields[0]), Owners: fields[1:]})
+	}
+	return rules, scanner.Err()
+}
+
+// Patterns translates a gitignore-style CODEOWNERS pattern to
+// utils.MatchPath patterns: a slash other than a trailing one anchors it at
+// the repository root, and a pattern matches the files below a directory too.
+func Patterns(pattern string) []string {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	if strings.Contains(pattern, "/") && !strings.HasPrefix(pattern, "**/") {
+		pattern = "/" + strings.TrimPrefix(pattern, "/")
+	}
+
+	if dirOnly {
+		return []string{pattern + "/**"}
+	}
+	return []string{pattern, pattern + "/**"}
+}
+
+// Match returns the owners of the last rule matching the path, relative to
+// the repository root.
+func Match(rules []Rule, path string) []string {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if utils.MatchPath(path, rules[i].Patterns) {
+			return rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// Resolver finds the owners of files given relative to the current
+// directory. A nil Resolver owns nothing, so callers need not check whether
+// the repository has a CODEOWNERS file.
+type Resolver struct {
+	root  string
+	rules []Rule
+}
+
+// Open loads the CODEOWNERS file at path, or the one of the repository at
+// root when path is empty. It returns nil when there is none.
+func Open(path, root string) (*Resolver, error) {
+	if path == "" {
+		path = Find(root)
+		if path == "" {
+			return nil, nil
+		}
+	}
+	rules, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	return &Resolver{root: absRoot, rules: rules}, nil
+}
+
+func (r *Resolver) Owners(file string) []string {
+	if r == nil {
+		return nil
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil
+	}
+	rel, err := filepath.Rel(r.root, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+	return Match(r.rules, filepath.ToSlash(rel))
+}
+
+// Annotate sets the owners of each finding.
+func (r *Resolver) Annotate(findings []diagnostics.Diagnostic) {
+	if r == nil {
+		return
+	}
+	for i := range findings {
+		findings[i].Owners = r.Owners(findings[i].File)
+	}
+}
+
+// Count is the number of findings routed to one owner.
+type Count struct {
+	Owner    string `json:"owner"`
+	Findings int    `json:"findings"`
+	Errors   int    `json:"errors"`
+	Warnings int    `json:"warnings"`
+}
+
+// Counts tallies findings per owner, the busiest first. A finding with
+// several owners counts for each of them.
+func Counts(findings []diagnostics.Diagnostic) []Count {
+	byOwner := make(map[string]*Count)
+	for _, finding := range findings {
+		owners := finding.Owners
+		if len(owners) == 0 {
+			owners = []string{Unowned}
+		}
+		for _, owner := range owners {
+			count, ok := byOwner[owner]
+			if !ok {
+				count = &Count{Owner: owner}
+				byOwner[owner] = count
+			}
+			count.Findings++
+			switch finding.Severity {
+			case diagnostics.SeverityError:
+				count.Errors++
+			case diagnostics.SeverityWarning:
+				count.Warnings++
+			}
+		}
+	}
+
+	counts := make([]Count, 0, len(byOwner))
+	for _, count := range byOwner {
+		counts = append(counts, *count)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Findings != counts[j].Findings {
+			return counts[i].Findings > counts[j].Findings
+		}
+		return counts[i].Owner < counts[j].Owner
+	})
+	return counts
+}
+
+// Group splits findings by owner, in the order of Counts.
+func Group(findings []diagnostics.Diagnostic) ([]string, map[string][]diagnostics.Diagnostic) {
+	groups := make(map[string][]diagnostics.Diagnostic)
+	for _, finding := range findings {
+		owners := finding.Owners
+		if len(owners) == 0 {
+			owners = []string{Unowned}
+		}
+		for _, owner := range owners {
+			groups[owner] = append(groups[owner], finding)
+		}
+	}
+
+	var order []string
+	for _, count := range Counts(findings) {
+		order = append(order, count.Owner)
+	}
+	return order, groups
+}
@@ -0,0 +1,93 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/vitruves/gop/internal/diagnostics"
+)
+
+func TestMatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CODEOWNERS")
+	content := `# Default owners
+*                 @org/everyone
+*.md              @org/docs
+/src/net/         @net-team   # networking
+src/ui            @ui-team
+third_party/
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create CODEOWNERS: %v", err)
+	}
+
+	rules, err := Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load CODEOWNERS: %v", err)
+	}
+
+	cases := map[string][]string{
+		"main.c":                     {"@org/everyone"},
+		"docs/guide.md":              {"@org/docs"},
+		"src/net/socket.c":           {"@net-team"},
+		"src/net/tls/handshake.c":    {"@net-team"},
+		"lib/src/net/socket.c":       {"@org/everyone"},
+		"src/ui/window.cpp":          {"@ui-team"},
+		"src/ui.c":                   {"@org/everyone"},
+		"lib/third_party/zlib/inf.c": {},
+	}
+	for file, want := range cases {
+		got := Match(rules, file)
+		if len(got) != len(want) || (len(want) > 0 && !reflect.DeepEqual(got, want)) {
+			t.Errorf("Match(%q) = %v, expected %v", file, got, want)
+		}
+	}
+}
+
+func TestAnnotateAndCounts(t *testing.T) {
+	root := t.TempDir()
+	content := "*.c @core\n/net/ @net-team @sre\n"
+	if err := os.MkdirAll(filepath.Join(root, ".github"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".github", "CODEOWNERS"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create CODEOWNERS: %v", err)
+	}
+
+	resolver, err := Open("", root)
+	if err != nil || resolver == nil {
+		t.Fatalf("Expected the CODEOWNERS file to be found, got %v", err)
+	}
+
+	findings := []diagnostics.Diagnostic{
+		{File: filepath.Join(root, "net", "sock.c"), Severity: diagnostics.SeverityError},
+		{File: filepath.Join(root, "net", "tls.c"), Severity: diagnostics.SeverityWarning},
+		{File: filepath.Join(root, "ui", "win.c"), Severity: diagnostics.SeverityWarning},
+		{File: filepath.Join(root, "docs", "guide.md"), Severity: diagnostics.SeverityInfo},
+	}
+	resolver.Annotate(findings)
+	if !reflect.DeepEqual(findings[0].Owners, []string{"@net-team", "@sre"}) || !reflect.DeepEqual(findings[2].Owners, []string{"@core"}) || findings[3].Owners != nil {
+		t.Errorf("Unexpected owners: %+v", findings)
+	}
+
+	want := []Count{
+		{Owner: "@net-team", Findings: 2, Errors: 1, Warnings: 1},
+		{Owner: "@sre", Findings: 2, Errors: 1, Warnings: 1},
+		{Owner: Unowned, Findings: 1},
+		{Owner: "@core", Findings: 1, Warnings: 1},
+	}
+	if got := Counts(findings); !reflect.DeepEqual(got, want) {
+		t.Errorf("Counts() = %+v, expected %+v", got, want)
+	}
+
+	order, groups := Group(findings)
+	if len(order) != 4 || order[0] != "@net-team" || len(groups["@sre"]) != 2 || len(groups[Unowned]) != 1 {
+		t.Errorf("Unexpected groups: %v %+v", order, groups)
+	}
+
+	var missing *Resolver
+	if missing.Owners("main.c") != nil {
+		t.Error("A nil Resolver should own nothing")
+	}
+}
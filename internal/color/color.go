@@ -0,0 +1,44 @@
+// Package color decides whether gop writes ANSI color codes. Colors are off
+// when NO_COLOR is set, when TERM is dumb, when stdout is not a terminal, or
+// after Disable (the --no-color flag).
+package color
+
+import "os"
+
+const (
+	Red      = "31"
+	Green    = "32"
+	Yellow   = "33"
+	Blue     = "34"
+	BoldCyan = "1;36"
+)
+
+var enabled = detect()
+
+func detect() bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func Enabled() bool {
+	return enabled
+}
+
+func Disable() {
+	enabled = false
+}
+
+// Wrap surrounds text with the given SGR code, or returns it untouched when
+// colors are off.
+func Wrap(code, text string) string {
+	if !enabled {
+		return text
+	}
+	return "\033[" + code + "m" + text + "\033[0m"
+}
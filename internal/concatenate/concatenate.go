@@ -4,15 +4,17 @@ import (
 	"bufio"
 	"context"
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
-	"sync"
 	"time"
 
-	"github.com/schollz/progressbar/v3"
-	"golang.org/x/sync/semaphore"
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
 )
 
 type Config struct {
@@ -27,7 +29,18 @@ type Config struct {
 	RemoveComments bool
 	AddLineNumbers bool
 	AddHeaders     bool
+	Redact         bool
 	OutputFile     string
+	// SplitManifest names the file the boundaries of each source file in
+	// the output are written to, for gop split
+	SplitManifest  string
+	SkipGenerated  bool
+	SkipVendor     bool
+	MaxFileSize    int64
+	FollowSymlinks bool
+	IncludeHidden  bool
+	NoProgress     bool
+	Manifest       *manifest.Manifest
 }
 
 type FileProcessor interface {
@@ -42,6 +55,10 @@ type FileProcessor interface {
 func Run(config Config) error {
 	logInfo(config.Verbose, "Starting code concatenation")
 
+	if config.SplitManifest != "" && config.OutputFile == "" {
+		return fmt.Errorf("--split-manifest needs --output, the console output is mixed with log lines")
+	}
+
 	processor := getProcessor(config.Language)
 	if processor == nil {
 		return fmt.Errorf("unsupported language: %s", config.Language)
@@ -59,61 +76,59 @@ func Run(config Config) error {
 	}
 
 	logInfo(config.Verbose, fmt.Sprintf("Found %d files to process", len(files)))
+	config.Manifest.AddFiles(files)
 
 	var output strings.Builder
 	
-	bar := progressbar.NewOptions(len(files),
-		progressbar.OptionSetDescription("Processing files"),
-		progressbar.OptionShowCount(),
-		progressbar.OptionSetRenderBlankState(true),
-		progressbar.OptionClearOnFinish(),
-	)
-
-	sem := semaphore.NewWeighted(int64(config.Jobs))
-	var mu sync.Mutex
-	var wg sync.WaitGroup
-
-	results := make([]string, len(files))
-	
-	for i, file := range files {
-		wg.Add(1)
-		go func(idx int, filePath string) {
-			defer wg.Done()
-			sem.Acquire(context.Background(), 1)
-			defer sem.Release(1)
-
-			content, err := processFile(filePath, config, processor)
-			if err != nil {
-				logError(fmt.Sprintf("Error processing %s: %v", filePath, err))
-				return
-			}
+	reporter := progress.New("Processing files", len(files), config.NoProgress)
+	results := worker.Run(files, config.Jobs, reporter, func(ctx context.Context, idx int, filePath string) segment {
+		seg, err := processFile(filePath, config, processor)
+		if err != nil {
+			failures.Record(filePath, err)
+			return segment{}
+		}
+		return seg
+	})
 
-			mu.Lock()
-			results[idx] = content
-			bar.Add(1)
-			mu.Unlock()
-		}(i, file)
+	if config.Redact {
+		total := 0
+		for i, seg := range results {
+			if seg.redacted > 0 {
+				logWarning(fmt.Sprintf("Redacted %d secret(s) in %s", seg.redacted, files[i]))
+				total += seg.redacted
+			}
+		}
+		logInfo(config.Verbose, fmt.Sprintf("Redacted %d secret(s) in total", total))
 	}
 
-	wg.Wait()
-	bar.Finish()
-
-	for _, content := range results {
-		if content != "" {
-			output.WriteString(content)
+	offsets := make([]int, len(results))
+	for i, seg := range results {
+		offsets[i] = output.Len()
+		if seg.text != "" {
+			output.WriteString(seg.text)
 		}
 	}
 
 	finalOutput := output.String()
 	
 	if config.OutputFile != "" {
+		config.Manifest.AddResult(config.OutputFile, []byte(finalOutput))
 		err := os.WriteFile(config.OutputFile, []byte(finalOutput), 0644)
 		if err != nil {
 			logError(fmt.Sprintf("Failed to write output file: %v", err))
 			return err
 		}
 		logSuccess(fmt.Sprintf("Output written to %s", config.OutputFile))
+
+		if config.SplitManifest != "" {
+			if err := writeSplitManifest(config, files, results, offsets, finalOutput); err != nil {
+				logError(fmt.Sprintf("Failed to write split manifest: %v", err))
+				return err
+			}
+			logSuccess(fmt.Sprintf("Split manifest written to %s", config.SplitManifest))
+		}
 	} else {
+		config.Manifest.AddResult("stdout", []byte(finalOutput))
 		fmt.Print(finalOutput)
 	}
 
@@ -139,55 +154,28 @@ func getProcessor(language string) FileProcessor {
 }
 
 func collectFiles(config Config, processor FileProcessor) ([]string, error) {
-	var files []string
 	extensions := processor.GetExtensions()
 	specialFiles := processor.SupportsSpecialFiles()
 
-	startDir := "."
-	if len(config.Include) > 0 {
-		for _, path := range config.Include {
-			matches, err := filepath.Glob(path)
-			if err != nil {
-				return nil, err
-			}
-			for _, match := range matches {
-				if isValidFile(match, extensions) || isSpecialFile(match, specialFiles) {
-					files = append(files, match)
-				}
-			}
-		}
-		return files, nil
+	opts := utils.ScanOptions{
+		Include:        config.Include,
+		Exclude:        config.Exclude,
+		Recursive:      config.Recursive,
+		Depth:          config.Depth,
+		SkipGenerated:  config.SkipGenerated,
+		SkipVendor:     config.SkipVendor,
+		MaxFileSize:    config.MaxFileSize,
+		FollowSymlinks: config.FollowSymlinks,
+		IncludeHidden:  config.IncludeHidden,
+		Verbose:        config.Verbose,
 	}
 
-	err := filepath.WalkDir(startDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if d.IsDir() {
-			if shouldExcludeDir(path, config.Exclude) {
-				return filepath.SkipDir
-			}
-			if !config.Recursive && path != startDir {
-				return filepath.SkipDir
-			}
-			if config.Depth > 0 {
-				relPath, _ := filepath.Rel(startDir, path)
-				if strings.Count(relPath, string(filepath.Separator)) >= config.Depth {
-					return filepath.SkipDir
-				}
-			}
-			return nil
-		}
-
-		if (isValidFile(path, extensions) || isSpecialFile(path, specialFiles)) && !shouldExcludeFile(path, config, processor) {
-			files = append(files, path)
+	return utils.GetFilesToProcess(opts, func(path string) bool {
+		if !isValidFile(path, extensions) && !isSpecialFile(path, specialFiles) {
+			return false
 		}
-
-		return nil
+		return len(config.Include) > 0 || !shouldExcludeFile(path, config, processor)
 	})
-
-	return files, err
 }
 
 func isValidFile(path string, extensions []string) bool {
@@ -205,47 +193,34 @@ func isSpecialFile(path string, specialFiles map[string]bool) bool {
 	return specialFiles[filename]
 }
 
-func shouldExcludeDir(path string, exclude []string) bool {
-	excludeDirs := []string{".git", "node_modules", "__pycache__", ".pytest_cache", "target", "build", "dist", "vendor"}
-	
-	for _, excludePattern := range exclude {
-		if matched, _ := filepath.Match(excludePattern, path); matched {
-			return true
-		}
-	}
-	
-	for _, excludeDir := range excludeDirs {
-		if strings.Contains(path, excludeDir) {
-			return true
-		}
-	}
-	
-	return false
-}
-
 func shouldExcludeFile(path string, config Config, processor FileProcessor) bool {
 	if config.RemoveTests && processor.IsTestFile(path) {
 		return true
 	}
 	
-	for _, excludePattern := range config.Exclude {
-		if matched, _ := filepath.Match(excludePattern, path); matched {
-			return true
-		}
-	}
-	
-	return false
+	return utils.MatchesAny(path, config.Exclude)
+}
+
+// segment is a file as written to the output. Its content, without the
+// header, lies between start and end of text.
+type segment struct {
+	text       string
+	header     string
+	start, end int
+	redacted   int
+	processed  bool
 }
 
-func processFile(filePath string, config Config, processor FileProcessor) (string, error) {
+// processFile returns the formatted content of a file and the number of
+// secrets redacted from it.
+func processFile(filePath string, config Config, processor FileProcessor) (segment, error) {
 	logDebug(config.Verbose, fmt.Sprintf("Processing file: %s", filePath))
 	
-	content, err := os.ReadFile(filePath)
+	contentStr, err := utils.ReadSourceFile(filePath)
 	if err != nil {
-		return "", err
+		return segment{}, err
 	}
 
-	contentStr := string(content)
 	
 	if config.RemoveComments {
 		contentStr = processor.RemoveComments(contentStr)
@@ -255,12 +230,20 @@ func processFile(filePath string, config Config, processor FileProcessor) (strin
 		contentStr = processor.RemoveTestCode(contentStr)
 	}
 
+	redacted := 0
+	if config.Redact {
+		contentStr, redacted = redactSecrets(contentStr, filePath)
+	}
+
 	var result strings.Builder
+	seg := segment{redacted: redacted}
 	
 	if config.AddHeaders {
-		result.WriteString(fmt.Sprintf("// === %s ===\n", filePath))
-		result.WriteString(fmt.Sprintf("// Path: %s\n\n", filePath))
+		shown := utils.DisplayPath(filePath)
+		seg.header = fmt.Sprintf("// === %s ===\n// Path: %s\n\n", shown, shown)
+		result.WriteString(seg.header)
 	}
+	seg.start = result.Len()
 
 	if config.AddLineNumbers {
 		scanner := bufio.NewScanner(strings.NewReader(contentStr))
@@ -272,35 +255,38 @@ func processFile(filePath string, config Config, processor FileProcessor) (strin
 	} else {
 		result.WriteString(contentStr)
 	}
+	seg.end = result.Len()
 	
 	if config.AddHeaders {
 		result.WriteString("\n\n")
 	}
 
-	return result.String(), nil
+	seg.text = result.String()
+	seg.processed = true
+	return seg, nil
 }
 
 func logInfo(verbose bool, msg string) {
 	if verbose {
-		fmt.Printf("\033[34m%s - INFO: %s\033[0m\n", getCurrentTime(), msg)
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
 	}
 }
 
 func logSuccess(msg string) {
-	fmt.Printf("\033[32m%s - SUCCESS: %s\033[0m\n", getCurrentTime(), msg)
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
 }
 
 func logWarning(msg string) {
-	fmt.Printf("\033[33m%s - WARNING: %s\033[0m\n", getCurrentTime(), msg)
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
 }
 
 func logError(msg string) {
-	fmt.Printf("\033[31m%s - ERROR: %s\033[0m\n", getCurrentTime(), msg)
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
 }
 
 func logDebug(verbose bool, msg string) {
 	if os.Getenv("DEBUG") != "" || verbose {
-		fmt.Printf("\033[33m%s - DEBUG: %s\033[0m\n", getCurrentTime(), msg)
+		fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - DEBUG: %s", getCurrentTime(), msg)))
 	}
 }
 
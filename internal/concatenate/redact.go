@@ -0,0 +1,91 @@
+package concatenate
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type secretPattern struct {
+	kind  string
+	regex *regexp.Regexp
+	// group is the submatch holding the secret, 0 replaces the whole match
+	group int
+}
+
+// Well-known token formats, replaced wherever they appear.
+var secretPatterns = []secretPattern{
+	{"private_key", regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----[\s\S]*?-----END [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----`), 0},
+	{"aws_access_key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), 0},
+	{"github_token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`), 0},
+	{"slack_token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`), 0},
+	{"google_api_key", regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`), 0},
+	{"stripe_key", regexp.MustCompile(`\b[sr]k_(?:live|test)_[0-9A-Za-z]{16,}\b`), 0},
+	{"api_key", regexp.MustCompile(`\bsk-[A-Za-z0-9_\-]{20,}`), 0},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`), 0},
+	{"bearer_token", regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/=-]{16,})`), 1},
+	{"url_password", regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@]+:([^\s@/]+)@`), 1},
+}
+
+const secretNames = `[\w.-]*(?i:password|passwd|pwd|secret|api[_-]?key|apikey|access[_-]?key|private[_-]?key|token|credentials?)[\w.-]*`
+
+// Quoted values assigned to secret-looking names, in any language.
+var quotedSecretRegex = regexp.MustCompile(`\b` + secretNames + `\s*[:=]+\s*["']([^"'\s]{4,})["']`)
+
+// Unquoted values are only treated as secrets in configuration files, in code
+// they are usually expressions such as token = getToken().
+var unquotedSecretRegex = regexp.MustCompile(`(?m)^\s*(?:export\s+)?` + secretNames + `\s*[:=]\s*([^\s"'#;]{4,})\s*$`)
+
+var configExtensions = map[string]bool{
+	".env": true, ".ini": true, ".cfg": true, ".conf": true, ".properties": true,
+	".yaml": true, ".yml": true, ".toml": true,
+}
+
+// redactSecrets replaces anything that looks like a credential with a
+// placeholder naming its kind and returns how many were replaced.
+func redactSecrets(content, filePath string) (string, int) {
+	count := 0
+
+	for _, pattern := range secretPatterns {
+		content = replaceGroup(content, pattern.regex, pattern.group, pattern.kind, &count)
+	}
+
+	content = replaceGroup(content, quotedSecretRegex, 1, "secret", &count)
+
+	if isConfigFile(filePath) {
+		content = replaceGroup(content, unquotedSecretRegex, 1, "secret", &count)
+	}
+
+	return content, count
+}
+
+func replaceGroup(content string, regex *regexp.Regexp, group int, kind string, count *int) string {
+	matches := regex.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return content
+	}
+
+	placeholder := fmt.Sprintf("[REDACTED:%s]", kind)
+
+	var sb strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[2*group], match[2*group+1]
+		if start < 0 || strings.HasPrefix(content[start:end], "[REDACTED") {
+			continue
+		}
+		sb.WriteString(content[last:start])
+		sb.WriteString(placeholder)
+		last = end
+		*count++
+	}
+	sb.WriteString(content[last:])
+
+	return sb.String()
+}
+
+func isConfigFile(path string) bool {
+	name := filepath.Base(path)
+	return configExtensions[filepath.Ext(name)] || strings.HasPrefix(name, ".env")
+}
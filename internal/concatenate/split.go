@@ -0,0 +1,273 @@
+package concatenate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/vitruves/gop/internal/manifest"
+)
+
+// SplitManifest records where each file lies in a concatenated output, so
+// gop split can write the files back.
+type SplitManifest struct {
+	Artifact    string      `json:"artifact"`
+	SHA256      string      `json:"sha256"`
+	Headers     bool        `json:"headers"`
+	LineNumbers bool        `json:"line_numbers"`
+	Files       []SplitFile `json:"files"`
+}
+
+// SplitFile is one file of the output. Offset and Size delimit its content,
+// header excluded, and SHA256 is the hash of that content. NoFinalNewline
+// records a newline the line numbers added. Lossy is set when the content
+// does not give the file on disk back, because comments, tests or secrets
+// were removed or its encoding or line endings were converted.
+type SplitFile struct {
+	Path           string `json:"path"`
+	Header         string `json:"header,omitempty"`
+	Offset         int    `json:"offset"`
+	Size           int    `json:"size"`
+	SHA256         string `json:"sha256"`
+	SourceSHA256   string `json:"source_sha256"`
+	NoFinalNewline bool   `json:"no_final_newline,omitempty"`
+	Lossy          bool   `json:"lossy,omitempty"`
+}
+
+type SplitConfig struct {
+	Artifact string
+	// SplitManifest is the file written by concatenate --split-manifest
+	SplitManifest string
+	OutputDir     string
+	DryRun        bool
+	// Force writes the files of a lossy concatenation back anyway
+	Force    bool
+	Verbose  bool
+	Manifest *manifest.Manifest
+}
+
+var lineNumberRegex = regexp.MustCompile(`^ *\d+: `)
+
+func writeSplitManifest(config Config, files []string, results []segment, offsets []int, output string) error {
+	cwd, _ := os.Getwd()
+	split := SplitManifest{
+		Artifact:    filepath.ToSlash(config.OutputFile),
+		SHA256:      hashString(output),
+		Headers:     config.AddHeaders,
+		LineNumbers: config.AddLineNumbers,
+		Files:       []SplitFile{},
+	}
+
+	for i, seg := range results {
+		if !seg.processed {
+			continue
+		}
+		source, err := os.ReadFile(files[i])
+		if err != nil {
+			return err
+		}
+
+		path := files[i]
+		if abs, err := filepath.Abs(path); err == nil {
+			if rel, err := filepath.Rel(cwd, abs); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+		content := seg.text[seg.start:seg.end]
+		file := SplitFile{
+			Path:         filepath.ToSlash(path),
+			Header:       seg.header,
+			Offset:       offsets[i] + seg.start,
+			Size:         len(content),
+			SHA256:       hashString(content),
+			SourceSHA256: hashString(string(source)),
+		}
+		body := content
+		if config.AddLineNumbers {
+			body = stripLineNumbers(body)
+			file.NoFinalNewline = len(source) > 0 && !strings.HasSuffix(string(source), "\n")
+			if file.NoFinalNewline {
+				body = strings.TrimSuffix(body, "\n")
+			}
+		}
+		file.Lossy = body != string(source)
+		split.Files = append(split.Files, file)
+	}
+
+	data, err := json.MarshalIndent(split, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	config.Manifest.AddResult(config.SplitManifest, data)
+	return os.WriteFile(config.SplitManifest, data, 0644)
+}
+
+// Split writes the files of a concatenated output back, using the boundaries
+// recorded by concatenate --split-manifest. An output edited since then is
+// split on its file headers instead of the recorded offsets.
+func Split(config SplitConfig) error {
+	if config.OutputDir == "" {
+		config.OutputDir = "."
+	}
+
+	data, err := os.ReadFile(config.SplitManifest)
+	if err != nil {
+		return err
+	}
+	var split SplitManifest
+	if err := json.Unmarshal(data, &split); err != nil {
+		return fmt.Errorf("%s: %w", config.SplitManifest, err)
+	}
+
+	artifact, err := os.ReadFile(config.Artifact)
+	if err != nil {
+		return err
+	}
+	config.Manifest.AddFiles([]string{config.Artifact, config.SplitManifest})
+
+	contents, err := locateFiles(split, string(artifact))
+	if err != nil {
+		return err
+	}
+	if hashString(string(artifact)) != split.SHA256 {
+		logInfo(config.Verbose, fmt.Sprintf("%s changed since it was written, splitting it on its file headers", config.Artifact))
+	}
+
+	written, unchanged, skipped := 0, 0, 0
+	for i, file := range split.Files {
+		content := contents[i]
+		if content == "" && file.Size > 0 {
+			logWarning(fmt.Sprintf("%s is empty in %s, skipping it", file.Path, config.Artifact))
+			skipped++
+			continue
+		}
+		edited := hashString(content) != file.SHA256
+		if split.LineNumbers {
+			content = stripLineNumbers(content)
+			if file.NoFinalNewline {
+				content = strings.TrimSuffix(content, "\n")
+			}
+		}
+
+		target, err := targetPath(config.OutputDir, file.Path)
+		if err != nil {
+			logError(err.Error())
+			skipped++
+			continue
+		}
+		if existing, err := os.ReadFile(target); err == nil && string(existing) == content {
+			unchanged++
+			continue
+		}
+		if file.Lossy && !config.Force {
+			logWarning(fmt.Sprintf("Skipping %s: it was altered when concatenating (comments, tests or secrets removed, encoding or line endings converted), use --force to write it anyway", file.Path))
+			skipped++
+			continue
+		}
+
+		state := "unchanged since concatenation"
+		if edited {
+			state = "edited"
+		}
+		if config.DryRun {
+			fmt.Printf("Would write %s (%s)\n", target, state)
+			written++
+			continue
+		}
+
+		mode := os.FileMode(0644)
+		if info, err := os.Stat(target); err == nil {
+			mode = info.Mode().Perm()
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, []byte(content), mode); err != nil {
+			return err
+		}
+		config.Manifest.AddResult(target, []byte(content))
+		logInfo(config.Verbose, fmt.Sprintf("Wrote %s (%s)", target, state))
+		written++
+	}
+
+	verb := "Wrote"
+	if config.DryRun {
+		verb = "Would write"
+	}
+	logSuccess(fmt.Sprintf("%s %d files, %d unchanged, %d skipped", verb, written, unchanged, skipped))
+	return nil
+}
+
+// locateFiles returns the content of each file of the manifest in artifact.
+func locateFiles(split SplitManifest, artifact string) ([]string, error) {
+	contents := make([]string, len(split.Files))
+
+	if hashString(artifact) == split.SHA256 {
+		for i, file := range split.Files {
+			if file.Offset < 0 || file.Offset+file.Size > len(artifact) {
+				return nil, fmt.Errorf("%s lies outside the artifact, the manifest does not belong to it", file.Path)
+			}
+			contents[i] = artifact[file.Offset : file.Offset+file.Size]
+		}
+		return contents, nil
+	}
+
+	if !split.Headers {
+		return nil, fmt.Errorf("the artifact changed since it was concatenated and has no file headers to split it on, concatenate with --add-headers to split edited output")
+	}
+
+	// Each file runs from the end of its header to the blank lines before
+	// the next header
+	starts := make([]int, len(split.Files))
+	ends := make([]int, len(split.Files))
+	pos := 0
+	for i, file := range split.Files {
+		idx := strings.Index(artifact[pos:], file.Header)
+		if idx < 0 {
+			return nil, fmt.Errorf("the header of %s is missing from the artifact", file.Path)
+		}
+		if i > 0 {
+			ends[i-1] = pos + idx
+		}
+		starts[i] = pos + idx + len(file.Header)
+		pos = starts[i]
+	}
+	for i := range split.Files {
+		end := len(artifact)
+		if i+1 < len(split.Files) {
+			end = ends[i]
+		}
+		contents[i] = strings.TrimSuffix(artifact[starts[i]:end], "\n\n")
+	}
+	return contents, nil
+}
+
+// targetPath joins path to dir, refusing paths that lead outside of it.
+func targetPath(dir, path string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(path))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the output directory, skipping it", path)
+	}
+	return filepath.Join(dir, clean), nil
+}
+
+// stripLineNumbers removes the prefixes of --add-line-numbers, lines added
+// without one are kept as they are.
+func stripLineNumbers(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = lineNumberRegex.ReplaceAllString(line, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func hashString(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
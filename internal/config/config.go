@@ -0,0 +1,305 @@
+// Package config reads the profiles, per-directory overrides and plugins of
+// .gop.yaml. Profiles are named sets of flag defaults selected with
+// --profile, overrides disable rules or raise the minimum level of the
+// findings reported in part of the tree. Like editorconfig, a .gop.yaml in
+// a subdirectory adds overrides for the files below it.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the configuration file read in the working directory and,
+// for overrides, in the directories below it.
+const FileName = ".gop.yaml"
+
+// AnnotationsFile holds the notes and ignore dates kept on findings, tracked
+// with the sources.
+const AnnotationsFile = ".gop/annotations.yaml"
+
+// File holds the sections of .gop.yaml this package reads, the other
+// sections belong to their commands.
+type File struct {
+	// Profiles maps a profile name to flag values by flag name. A nested
+	// map keyed by a command, such as "lsp" or "ci gate", holds values for
+	// that command only and takes precedence.
+	Profiles  map[string]map[string]any `yaml:"profiles"`
+	Overrides []Override                `yaml:"overrides"`
+	Plugins   []Plugin                  `yaml:"plugins"`
+}
+
+// Override applies to the files matching one of Paths, relative to the
+// directory of its .gop.yaml. Sections apply in order: later sections and
+// those of deeper files win.
+type Override struct {
+	Paths []string `yaml:"paths"`
+	// Disable and Enable are regular expressions on rules, Enable brings
+	// back rules an earlier section disabled
+	Disable []string `yaml:"disable"`
+	Enable  []string `yaml:"enable"`
+	// MinLevel drops findings below this level of the shared scale
+	MinLevel diagnostics.Level `yaml:"min_level"`
+}
+
+// Plugin is an external analyzer gop plugins runs, see the plugins package
+// for the protocol.
+type Plugin struct {
+	Name string `yaml:"name"`
+	// Command is the executable and its arguments
+	Command []string `yaml:"command"`
+	// Languages restricts the files sent to the plugin, all when empty
+	Languages []string `yaml:"languages"`
+	// Functions adds the functions gop parses in each file to the request
+	Functions bool   `yaml:"functions"`
+	Timeout   string `yaml:"timeout"`
+}
+
+// Load reads a configuration file, a missing file is an empty one.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &File{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &file, nil
+}
+
+// LoadAnnotations reads an annotations file, a missing file holds none:
+//
+//	annotations:
+//	  - fingerprint: 3f2a9c...
+//	    comment: Rewritten with the new parser
+//	    owners: ["@net"]
+//	    ignore-until: 2025-06-01
+func LoadAnnotations(path string) ([]diagnostics.Annotation, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Annotations []diagnostics.Annotation `yaml:"annotations"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	seen := make(map[string]bool)
+	for i, annotation := range file.Annotations {
+		if annotation.Fingerprint == "" {
+			return nil, fmt.Errorf("%s: annotation %d has no fingerprint", path, i+1)
+		}
+		if seen[annotation.Fingerprint] {
+			return nil, fmt.Errorf("%s: fingerprint %s is annotated twice", path, annotation.Fingerprint)
+		}
+		seen[annotation.Fingerprint] = true
+		if annotation.IgnoreUntil != "" {
+			if _, err := time.Parse(diagnostics.AnnotationDate, annotation.IgnoreUntil); err != nil {
+				return nil, fmt.Errorf("%s: fingerprint %s: ignore-until %q is not a YYYY-MM-DD date", path, annotation.Fingerprint, annotation.IgnoreUntil)
+			}
+		}
+	}
+	return file.Annotations, nil
+}
+
+// Profile returns the flag values of a profile for a command, given by its
+// path below the root command ("placeholders", "ci gate"). Values are
+// strings, or lists of strings for repeatable flags.
+func (f *File) Profile(name, command string) (map[string]any, error) {
+	profile, ok := f.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(f.Profiles))
+		for name := range f.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown profile %q (defined: %v)", name, names)
+	}
+
+	values := make(map[string]any)
+	for key, value := range profile {
+		if _, ok := value.(map[string]any); !ok {
+			values[key] = value
+		}
+	}
+	if section, ok := profile[command].(map[string]any); ok {
+		for key, value := range section {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+type compiledOverride struct {
+	paths    []string
+	disable  []*regexp.Regexp
+	enable   []*regexp.Regexp
+	minLevel diagnostics.Level
+}
+
+// Overrides decides which findings the override sections keep, for files
+// below the directory of the root configuration.
+type Overrides struct {
+	root string
+
+	mu sync.Mutex
+	// dirs caches the compiled sections of each directory's .gop.yaml,
+	// nil when it has none
+	dirs map[string][]compiledOverride
+}
+
+// NewOverrides reads the overrides of the configuration in root and of the
+// .gop.yaml files below it, as findings need them.
+func NewOverrides(root string, file *File) (*Overrides, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	sections, err := compile(filepath.Join(root, FileName), file.Overrides)
+	if err != nil {
+		return nil, err
+	}
+	return &Overrides{root: root, dirs: map[string][]compiledOverride{root: sections}}, nil
+}
+
+func compile(path string, overrides []Override) ([]compiledOverride, error) {
+	var sections []compiledOverride
+	for i, override := range overrides {
+		if len(override.Paths) == 0 {
+			return nil, fmt.Errorf("%s: override %d has no paths", path, i+1)
+		}
+		if override.MinLevel != "" && !validLevel(override.MinLevel) {
+			return nil, fmt.Errorf("%s: override %d: unknown min_level %q (valid: %v)", path, i+1, override.MinLevel, diagnostics.Levels)
+		}
+		section := compiledOverride{paths: override.Paths, minLevel: override.MinLevel}
+		for _, pattern := range override.Disable {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("%s: override %d: disable %q: %w", path, i+1, pattern, err)
+			}
+			section.disable = append(section.disable, re)
+		}
+		for _, pattern := range override.Enable {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("%s: override %d: enable %q: %w", path, i+1, pattern, err)
+			}
+			section.enable = append(section.enable, re)
+		}
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
+
+func validLevel(level diagnostics.Level) bool {
+	for _, l := range diagnostics.Levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// sections returns the sections of the .gop.yaml in dir. Files that fail
+// to load are reported once and then ignored.
+func (o *Overrides) sections(dir string) []compiledOverride {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if sections, ok := o.dirs[dir]; ok {
+		return sections
+	}
+
+	path := filepath.Join(dir, FileName)
+	file, err := Load(path)
+	var sections []compiledOverride
+	if err == nil {
+		sections, err = compile(path, file.Overrides)
+	}
+	if err != nil {
+		logWarning(fmt.Sprintf("Ignoring %s: %v", path, err))
+	}
+	o.dirs[dir] = sections
+	return sections
+}
+
+// Keep reports whether the overrides matching the file of a finding keep
+// it. Findings outside the root are always kept.
+func (o *Overrides) Keep(d diagnostics.Diagnostic) bool {
+	path, err := filepath.Abs(d.File)
+	if err != nil {
+		return true
+	}
+	rel, err := filepath.Rel(o.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return true
+	}
+
+	// The directories from the root down to the file, outer files first
+	dirs := []string{o.root}
+	dir := o.root
+	if parent := filepath.Dir(rel); parent != "." {
+		for _, part := range strings.Split(parent, string(filepath.Separator)) {
+			dir = filepath.Join(dir, part)
+			dirs = append(dirs, dir)
+		}
+	}
+
+	disabled := false
+	minLevel := diagnostics.Level("")
+	for _, dir := range dirs {
+		fileRel, _ := filepath.Rel(dir, path)
+		for _, section := range o.sections(dir) {
+			if !utils.MatchPath(fileRel, section.paths) {
+				continue
+			}
+			for _, re := range section.disable {
+				if re.MatchString(d.Rule) {
+					disabled = true
+				}
+			}
+			for _, re := range section.enable {
+				if re.MatchString(d.Rule) {
+					disabled = false
+				}
+			}
+			if section.minLevel != "" {
+				minLevel = section.minLevel
+			}
+		}
+	}
+
+	if disabled {
+		return false
+	}
+	return minLevel == "" || diagnostics.LevelOf(d).Score() >= minLevel.Score()
+}
+
+func logWarning(msg string) {
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
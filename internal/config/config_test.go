@@ -0,0 +1,152 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vitruves/gop/internal/diagnostics"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	writeConfig(t, path, `
+profiles:
+  strict:
+    max-file-size: 1MB
+    exclude-finding: [a, b]
+    lsp:
+      max-complexity: 10
+      max-file-size: 2MB
+`)
+	file, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := file.Profile("strict", "lsp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"max-file-size": "2MB", "exclude-finding": []any{"a", "b"}, "max-complexity": 10}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("Profile(strict, lsp) = %v, want %v", values, want)
+	}
+	if values, _ := file.Profile("strict", "stats"); values["max-file-size"] != "1MB" || values["max-complexity"] != nil {
+		t.Errorf("Expected the lsp section to apply to lsp only, got %v", values)
+	}
+
+	if _, err := file.Profile("legacy", "lsp"); err == nil || !strings.Contains(err.Error(), "strict") {
+		t.Errorf("Expected an unknown profile to list the defined ones, got %v", err)
+	}
+
+	if file, err := Load(filepath.Join(t.TempDir(), FileName)); err != nil || file.Profiles != nil {
+		t.Errorf("Expected a missing file to load empty, got %+v %v", file, err)
+	}
+}
+
+func TestLoadAnnotations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), AnnotationsFile)
+	writeConfig(t, path, `
+annotations:
+  - fingerprint: abc
+    comment: Rewritten with the new parser
+    owners: ["@net"]
+    ignore-until: 2025-06-01
+  - fingerprint: def
+`)
+	annotations, err := LoadAnnotations(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []diagnostics.Annotation{
+		{Fingerprint: "abc", Comment: "Rewritten with the new parser", Owners: []string{"@net"}, IgnoreUntil: "2025-06-01"},
+		{Fingerprint: "def"},
+	}
+	if !reflect.DeepEqual(annotations, want) {
+		t.Errorf("LoadAnnotations = %+v, want %+v", annotations, want)
+	}
+
+	invalid := []string{
+		"annotations:\n  - comment: no fingerprint\n",
+		"annotations:\n  - fingerprint: abc\n  - fingerprint: abc\n",
+		"annotations:\n  - fingerprint: abc\n    ignore-until: next month\n",
+	}
+	for _, content := range invalid {
+		writeConfig(t, path, content)
+		if _, err := LoadAnnotations(path); err == nil {
+			t.Errorf("Expected %q to be rejected", content)
+		}
+	}
+
+	if annotations, err := LoadAnnotations(filepath.Join(t.TempDir(), AnnotationsFile)); err != nil || annotations != nil {
+		t.Errorf("Expected a missing file to hold no annotations, got %+v %v", annotations, err)
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	root := t.TempDir()
+	writeConfig(t, filepath.Join(root, FileName), `
+overrides:
+  - paths: ["third_party/**"]
+    disable: [".*"]
+  - paths: ["tests/**"]
+    disable: ["^placeholders/"]
+    min_level: medium
+`)
+	writeConfig(t, filepath.Join(root, "tests", "fixtures", FileName), `
+overrides:
+  - paths: ["*.c"]
+    enable: ["^placeholders/todo$"]
+`)
+	writeConfig(t, filepath.Join(root, "src", FileName), "overrides: [{paths: [x], min_level: severe}]")
+
+	file, err := Load(filepath.Join(root, FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	overrides, err := NewOverrides(root, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file  string
+		rule  string
+		level diagnostics.Severity
+		want  bool
+	}{
+		{"src/a.c", "placeholders/todo", diagnostics.SeverityInfo, true},
+		{"third_party/zlib/inflate.c", "errors/unchecked_call", diagnostics.SeverityError, false},
+		{"tests/a.c", "placeholders/todo", diagnostics.SeverityWarning, false},
+		{"tests/a.c", "errors/unchecked_call", diagnostics.SeverityError, true},
+		{"tests/a.c", "errors/other", diagnostics.SeverityInfo, false},
+		// Re-enabled below, but still under the minimum level of tests/
+		{"tests/fixtures/a.c", "placeholders/todo", diagnostics.SeverityInfo, false},
+		{"tests/fixtures/a.c", "placeholders/todo", diagnostics.SeverityWarning, true},
+		{"tests/fixtures/a.h", "placeholders/todo", diagnostics.SeverityWarning, false},
+		{"../outside.c", "placeholders/todo", diagnostics.SeverityInfo, true},
+	}
+	for _, test := range tests {
+		d := diagnostics.Diagnostic{File: filepath.Join(root, test.file), Rule: test.rule, Severity: test.level}
+		if got := overrides.Keep(d); got != test.want {
+			t.Errorf("Keep(%s, %s, %s) = %v, want %v", test.file, test.rule, test.level, got, test.want)
+		}
+	}
+
+	if _, err := NewOverrides(root, &File{Overrides: []Override{{Paths: []string{"x"}, Disable: []string{"("}}}}); err == nil {
+		t.Error("Expected an invalid disable pattern to be rejected")
+	}
+}
@@ -0,0 +1,510 @@
+package configmatrix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/registry"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	Include   []string
+	Exclude   []string
+	Recursive bool
+	Depth     int
+	Jobs      int
+	Verbose   bool
+	// ConfigFile is the YAML file listing the named configurations
+	ConfigFile string
+	// Compare parses every file once per configuration and compares the
+	// functions each defines
+	Compare bool
+	// Configurations restricts the comparison to these names, all when empty
+	Configurations []string
+	OutputFile     string
+	Format         string
+	SkipGenerated  bool
+	SkipVendor     bool
+	MaxFileSize    int64
+	FollowSymlinks bool
+	IncludeHidden  bool
+	NoProgress     bool
+	Manifest       *manifest.Manifest
+}
+
+// Configuration is a named set of macros, listed under configurations in the
+// configuration file. Defines are NAME or NAME=VALUE, NAME alone meaning 1.
+type Configuration struct {
+	Name    string   `yaml:"name" json:"name"`
+	Defines []string `yaml:"defines" json:"defines"`
+}
+
+type Macro struct {
+	Name         string   `json:"name"`
+	Files        []string `json:"files"`
+	Conditionals int      `json:"conditionals"`
+}
+
+type FileMacros struct {
+	File   string   `json:"file"`
+	Macros []string `json:"macros"`
+}
+
+type ConfigurationResult struct {
+	Configuration
+	Functions int `json:"functions"`
+}
+
+// Difference is a function that some configurations define and others leave
+// out, or define with another signature.
+type Difference struct {
+	Name      string   `json:"name"`
+	Signature string   `json:"signature"`
+	File      string   `json:"file"`
+	Line      int      `json:"line"`
+	In        []string `json:"in"`
+}
+
+type Report struct {
+	Files          int                   `json:"files"`
+	Conditionals   int                   `json:"conditionals"`
+	Macros         []Macro               `json:"macros"`
+	FileMacros     []FileMacros          `json:"file_macros"`
+	Configurations []ConfigurationResult `json:"configurations,omitempty"`
+	Differences    []Difference          `json:"differences,omitempty"`
+}
+
+func Run(config Config) error {
+	logInfo(config.Verbose, "Starting configuration matrix analysis")
+
+	format := outputFormat(config)
+	if format != "markdown" && format != "json" {
+		return fmt.Errorf("unsupported format: %s (expected markdown or json)", format)
+	}
+
+	var configurations []Configuration
+	if config.Compare {
+		var err error
+		if configurations, err = LoadConfigurations(config.ConfigFile, config.Configurations); err != nil {
+			return err
+		}
+	}
+
+	cExtensions := make(map[string]bool)
+	for _, ext := range registry.GetParser("c").GetExtensions() {
+		cExtensions[ext] = true
+	}
+	cppExtensions := make(map[string]bool)
+	for _, ext := range registry.GetParser("cpp").GetExtensions() {
+		cppExtensions[ext] = true
+	}
+
+	opts := utils.ScanOptions{
+		Include:        config.Include,
+		Exclude:        config.Exclude,
+		Recursive:      config.Recursive,
+		Depth:          config.Depth,
+		SkipGenerated:  config.SkipGenerated,
+		SkipVendor:     config.SkipVendor,
+		MaxFileSize:    config.MaxFileSize,
+		FollowSymlinks: config.FollowSymlinks,
+		IncludeHidden:  config.IncludeHidden,
+		Verbose:        config.Verbose,
+	}
+
+	files, err := utils.GetFilesToProcess(opts, func(path string) bool {
+		ext := filepath.Ext(path)
+		return cExtensions[ext] || cppExtensions[ext]
+	})
+	if err != nil {
+		logError(fmt.Sprintf("Failed to collect files: %v", err))
+		return err
+	}
+	if len(files) == 0 {
+		logWarning("No C/C++ files found matching criteria")
+		return nil
+	}
+
+	logInfo(config.Verbose, fmt.Sprintf("Found %d files to analyze", len(files)))
+	config.Manifest.AddFiles(files)
+
+	var tempDir string
+	if len(configurations) > 0 {
+		if tempDir, err = os.MkdirTemp("", "gop-config-matrix-"); err != nil {
+			return err
+		}
+		defer os.RemoveAll(tempDir)
+	}
+
+	type analysis struct {
+		conditionals []Conditional
+		functions    [][]registry.Function
+		err          error
+	}
+
+	results := worker.Run(files, config.Jobs, progress.New("Analyzing conditionals", len(files), config.NoProgress), func(ctx context.Context, idx int, path string) (result analysis) {
+		content, err := utils.ReadSourceFile(path)
+		if err != nil {
+			failures.Record(path, err)
+			return result
+		}
+		result.conditionals = Conditionals(content)
+		if len(configurations) == 0 {
+			return result
+		}
+
+		language := "cpp"
+		if cExtensions[filepath.Ext(path)] {
+			language = "c"
+		}
+		parser := registry.GetParser(language)
+		result.functions = make([][]registry.Function, len(configurations))
+		for i, configuration := range configurations {
+			// A timed-out file stops before writing to the temporary
+			// directory, which is removed once the run returns
+			if ctx.Err() != nil {
+				return result
+			}
+			temp := filepath.Join(tempDir, fmt.Sprintf("%d-%d%s", idx, i, filepath.Ext(path)))
+			if err := os.WriteFile(temp, []byte(Preprocess(content, defineMap(configuration.Defines))), 0644); err != nil {
+				result.err = err
+				return result
+			}
+			parsed, err := parser.ParseFile(temp)
+			os.Remove(temp)
+			if err != nil {
+				failures.Record(path, fmt.Errorf("%s: %w", configuration.Name, err))
+				continue
+			}
+			for j := range parsed {
+				parsed[j].File = path
+			}
+			result.functions[i] = parsed
+		}
+		return result
+	})
+
+	conditionals := make([][]Conditional, len(files))
+	functions := make([][][]registry.Function, len(files))
+	for idx, result := range results {
+		if result.err != nil {
+			return result.err
+		}
+		conditionals[idx] = result.conditionals
+		functions[idx] = result.functions
+	}
+
+	report := buildReport(files, conditionals, configurations, functions)
+	logInfo(config.Verbose, fmt.Sprintf("Found %d conditionals on %d macros", report.Conditionals, len(report.Macros)))
+	return writeOutput(report, format, config)
+}
+
+// LoadConfigurations reads the configurations of path, keeping only the
+// given names when there are any.
+func LoadConfigurations(path string, names []string) ([]Configuration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configurations: %w", err)
+	}
+	var file struct {
+		Configurations []Configuration `yaml:"configurations"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool)
+	for _, configuration := range file.Configurations {
+		if configuration.Name == "" {
+			return nil, fmt.Errorf("%s: every configuration needs a name", path)
+		}
+		if seen[configuration.Name] {
+			return nil, fmt.Errorf("%s: configuration %s is listed twice", path, configuration.Name)
+		}
+		seen[configuration.Name] = true
+	}
+	if len(file.Configurations) == 0 {
+		return nil, fmt.Errorf("%s lists no configurations", path)
+	}
+	if len(names) == 0 {
+		return file.Configurations, nil
+	}
+
+	var selected []Configuration
+	for _, name := range names {
+		if !seen[name] {
+			return nil, fmt.Errorf("unknown configuration: %s", name)
+		}
+		for _, configuration := range file.Configurations {
+			if configuration.Name == name {
+				selected = append(selected, configuration)
+			}
+		}
+	}
+	return selected, nil
+}
+
+func defineMap(defines []string) map[string]string {
+	result := make(map[string]string, len(defines))
+	for _, define := range defines {
+		name, value, ok := strings.Cut(define, "=")
+		if !ok {
+			value = "1"
+		}
+		result[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return result
+}
+
+func buildReport(files []string, conditionals [][]Conditional, configurations []Configuration, functions [][][]registry.Function) *Report {
+	report := &Report{}
+	macros := make(map[string]*Macro)
+	for i, path := range files {
+		if len(conditionals[i]) == 0 {
+			continue
+		}
+		report.Files++
+		var names []string
+		for _, conditional := range conditionals[i] {
+			report.Conditionals++
+			for _, name := range conditional.Macros {
+				macro, ok := macros[name]
+				if !ok {
+					macro = &Macro{Name: name}
+					macros[name] = macro
+				}
+				macro.Conditionals++
+				if len(macro.Files) == 0 || macro.Files[len(macro.Files)-1] != path {
+					macro.Files = append(macro.Files, path)
+					names = append(names, name)
+				}
+			}
+		}
+		sort.Strings(names)
+		report.FileMacros = append(report.FileMacros, FileMacros{File: path, Macros: names})
+	}
+	for _, macro := range macros {
+		report.Macros = append(report.Macros, *macro)
+	}
+	sort.Slice(report.Macros, func(i, j int) bool {
+		a, b := report.Macros[i], report.Macros[j]
+		if len(a.Files) != len(b.Files) {
+			return len(a.Files) > len(b.Files)
+		}
+		return a.Name < b.Name
+	})
+
+	if len(configurations) == 0 {
+		return report
+	}
+
+	type key struct{ file, name, signature string }
+	presence := make(map[key]*Difference)
+	var order []key
+	for i, configuration := range configurations {
+		result := ConfigurationResult{Configuration: configuration}
+		for f := range files {
+			if functions[f] == nil {
+				continue
+			}
+			for _, function := range functions[f][i] {
+				result.Functions++
+				name := function.Name
+				if function.Scope != "" {
+					name = function.Scope + "::" + function.Name
+				}
+				k := key{function.File, name, function.Signature}
+				difference, ok := presence[k]
+				if !ok {
+					difference = &Difference{Name: name, Signature: function.Signature, File: function.File, Line: function.Line}
+					presence[k] = difference
+					order = append(order, k)
+				}
+				if len(difference.In) == 0 || difference.In[len(difference.In)-1] != configuration.Name {
+					difference.In = append(difference.In, configuration.Name)
+				}
+			}
+		}
+		report.Configurations = append(report.Configurations, result)
+	}
+	for _, k := range order {
+		if difference := presence[k]; len(difference.In) < len(configurations) {
+			report.Differences = append(report.Differences, *difference)
+		}
+	}
+	sort.SliceStable(report.Differences, func(i, j int) bool {
+		a, b := report.Differences[i], report.Differences[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return report
+}
+
+func outputFormat(config Config) string {
+	if config.Format != "" {
+		return config.Format
+	}
+	if filepath.Ext(config.OutputFile) == ".json" {
+		return "json"
+	}
+	return "markdown"
+}
+
+func writeOutput(report *Report, format string, config Config) error {
+	report = displayPaths(report)
+	var output []byte
+	var err error
+
+	switch format {
+	case "json":
+		output, err = json.MarshalIndent(report, "", "  ")
+		output = append(output, '\n')
+	default:
+		output = []byte(formatMarkdown(report))
+	}
+	if err != nil {
+		return err
+	}
+
+	if config.OutputFile != "" {
+		config.Manifest.AddResult(config.OutputFile, output)
+		if err := os.WriteFile(config.OutputFile, output, 0644); err != nil {
+			logError(fmt.Sprintf("Failed to write report: %v", err))
+			return err
+		}
+		logSuccess(fmt.Sprintf("Configuration matrix written to %s", config.OutputFile))
+	} else {
+		config.Manifest.AddResult("stdout", output)
+		fmt.Print(string(output))
+	}
+	return nil
+}
+
+// displayPaths returns a copy of the report with its files in the selected
+// path style.
+func displayPaths(report *Report) *Report {
+	displayed := *report
+	displayed.Macros = make([]Macro, len(report.Macros))
+	for i, macro := range report.Macros {
+		files := make([]string, len(macro.Files))
+		for j, file := range macro.Files {
+			files[j] = utils.DisplayPath(file)
+		}
+		macro.Files = files
+		displayed.Macros[i] = macro
+	}
+	displayed.FileMacros = make([]FileMacros, len(report.FileMacros))
+	for i, file := range report.FileMacros {
+		file.File = utils.DisplayPath(file.File)
+		displayed.FileMacros[i] = file
+	}
+	displayed.Differences = make([]Difference, len(report.Differences))
+	for i, difference := range report.Differences {
+		difference.File = utils.DisplayPath(difference.File)
+		displayed.Differences[i] = difference
+	}
+	return &displayed
+}
+
+func formatMarkdown(report *Report) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Configuration Matrix\n\n")
+	sb.WriteString(fmt.Sprintf("%s conditionals on %s macros in %s files. Include guards are left out.\n",
+		utils.FormatCount(report.Conditionals), utils.FormatCount(len(report.Macros)), utils.FormatCount(report.Files)))
+
+	if len(report.Macros) > 0 {
+		sb.WriteString("\n## Macros\n\n")
+		sb.WriteString("| Macro | Files | Conditionals |\n")
+		sb.WriteString("|-------|-------|--------------|\n")
+		for _, macro := range report.Macros {
+			sb.WriteString(fmt.Sprintf("| `%s` | %d | %d |\n", macro.Name, len(macro.Files), macro.Conditionals))
+		}
+
+		sb.WriteString("\n## Files\n\n")
+		sb.WriteString("| File | Macros |\n")
+		sb.WriteString("|------|--------|\n")
+		for _, file := range report.FileMacros {
+			sb.WriteString(fmt.Sprintf("| %s | %s |\n", file.File, strings.Join(file.Macros, ", ")))
+		}
+	}
+
+	if len(report.Configurations) == 0 {
+		return sb.String()
+	}
+
+	sb.WriteString("\n## Configurations\n\n")
+	sb.WriteString("| Configuration | Defines | Functions |\n")
+	sb.WriteString("|---------------|---------|-----------|\n")
+	for _, configuration := range report.Configurations {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", configuration.Name, strings.Join(configuration.Defines, ", "), utils.FormatCount(configuration.Functions)))
+	}
+
+	sb.WriteString("\n## Differences\n\n")
+	if len(report.Differences) == 0 {
+		sb.WriteString("Every configuration defines the same functions.\n")
+		return sb.String()
+	}
+	sb.WriteString("Functions not defined, or defined with another signature, in every configuration.\n\n")
+	sb.WriteString("| Function | Location |")
+	separator := "|----------|----------|"
+	for _, configuration := range report.Configurations {
+		sb.WriteString(fmt.Sprintf(" %s |", configuration.Name))
+		separator += strings.Repeat("-", len(configuration.Name)+2) + "|"
+	}
+	sb.WriteString("\n" + separator + "\n")
+	for _, difference := range report.Differences {
+		in := make(map[string]bool, len(difference.In))
+		for _, name := range difference.In {
+			in[name] = true
+		}
+		sb.WriteString(fmt.Sprintf("| `%s` | %s:%d |", difference.Name, difference.File, difference.Line))
+		for _, configuration := range report.Configurations {
+			mark := ""
+			if in[configuration.Name] {
+				mark = "✓"
+			}
+			sb.WriteString(fmt.Sprintf(" %s |", mark))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func logInfo(verbose bool, msg string) {
+	if verbose {
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
+	}
+}
+
+func logSuccess(msg string) {
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
+}
+
+func logWarning(msg string) {
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
+}
+
+func logError(msg string) {
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
@@ -0,0 +1,97 @@
+package configmatrix
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	macros := map[string]string{"VERSION": "3", "ALIAS": "VERSION * 2", "EMPTY": ""}
+	tests := map[string]int64{
+		"1":                                  1,
+		"defined(VERSION) && !defined(NONE)": 1,
+		"defined EMPTY":                      1,
+		"VERSION >= 2 && VERSION < 4":        1,
+		"ALIAS == 6":                         1,
+		"UNKNOWN":                            0,
+		"0x10 + 1L":                          17,
+		"(1 + 2) * 3 - 4 / 2":                7,
+		"VERSION > 2 ? 10 : 20":              10,
+		"__has_include(<foo.h>) + (1 << 3)":  8,
+		"1 / 0":                              0,
+	}
+	for condition, want := range tests {
+		if got := evaluate(condition, macros); got != want {
+			t.Errorf("evaluate(%q) = %d, want %d", condition, got, want)
+		}
+	}
+}
+
+func TestConditionals(t *testing.T) {
+	content := strings.Join([]string{
+		"#ifndef NET_H",
+		"#define NET_H",
+		"#ifdef USE_SSL /* TLS */",
+		"#if defined(__linux__) && \\",
+		"    __has_include(<sys/epoll.h>)",
+		"#elif defined _WIN32",
+		"#endif",
+		"#endif",
+		"#endif",
+	}, "\n")
+
+	got := Conditionals(content)
+	want := []Conditional{
+		{Line: 3, Macros: []string{"USE_SSL"}},
+		{Line: 4, Macros: []string{"__linux__", "__has_include"}},
+		{Line: 6, Macros: []string{"_WIN32"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Conditionals() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPreprocess(t *testing.T) {
+	content := strings.Join([]string{
+		"#define LOCAL 1",
+		"#ifdef USE_SSL",
+		"void ssl_init(void);",
+		"#elif LOCAL",
+		"void plain_init(void);",
+		"#else",
+		"void never(void);",
+		"#endif",
+		"#if VERSION > 1",
+		"#undef LOCAL",
+		"#endif",
+		"#if LOCAL",
+		"void local(void);",
+		"#endif",
+	}, "\n")
+
+	tests := []struct {
+		defines map[string]string
+		kept    []string
+	}{
+		{nil, []string{"plain_init", "local"}},
+		{defineMap([]string{"USE_SSL"}), []string{"ssl_init", "local"}},
+		{defineMap([]string{"VERSION=2"}), []string{"plain_init"}},
+	}
+	all := []string{"ssl_init", "plain_init", "never", "local"}
+	for _, tt := range tests {
+		got := Preprocess(content, tt.defines)
+		if lines := strings.Count(got, "\n"); lines != strings.Count(content, "\n") {
+			t.Errorf("Preprocess(%v) has %d lines, want %d", tt.defines, lines, strings.Count(content, "\n"))
+		}
+		for _, name := range all {
+			kept := false
+			for _, want := range tt.kept {
+				kept = kept || want == name
+			}
+			if strings.Contains(got, name+"(") != kept {
+				t.Errorf("Preprocess(%v) keeps %s = %v, want %v", tt.defines, name, !kept, kept)
+			}
+		}
+	}
+}
@@ -0,0 +1,480 @@
+package configmatrix
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// directive is a preprocessor line, with its continuation lines, spanning
+// lines start to end of the file.
+type directive struct {
+	start int
+	end   int
+	name  string
+	args  string
+}
+
+var (
+	directiveRegex = regexp.MustCompile(`^\s*#\s*(\w+)(.*)$`)
+	commentRegex   = regexp.MustCompile(`/\*.*?\*/|//.*$`)
+)
+
+// directives lists the preprocessor directives of the lines, comments
+// removed from their arguments.
+func directives(lines []string) []directive {
+	var result []directive
+	for i := 0; i < len(lines); i++ {
+		match := directiveRegex.FindStringSubmatch(strings.TrimRight(lines[i], "\r"))
+		if match == nil {
+			continue
+		}
+		d := directive{start: i, end: i, name: match[1], args: match[2]}
+		for strings.HasSuffix(strings.TrimRight(d.args, " \t\r"), "\\") && d.end+1 < len(lines) {
+			d.args = strings.TrimSuffix(strings.TrimRight(d.args, " \t\r"), "\\") + " " + strings.TrimRight(lines[d.end+1], "\r")
+			d.end++
+		}
+		d.args = strings.TrimSpace(commentRegex.ReplaceAllString(d.args, " "))
+		result = append(result, d)
+		i = d.end
+	}
+	return result
+}
+
+// Conditional is a #if, #ifdef, #ifndef or #elif and the macros it tests.
+type Conditional struct {
+	Line   int
+	Macros []string
+}
+
+// Conditionals lists the conditionals of a file. The include guard, a first
+// #ifndef whose macro the next directive defines, is left out.
+func Conditionals(content string) []Conditional {
+	found := directives(strings.Split(content, "\n"))
+
+	var result []Conditional
+	for i, d := range found {
+		var macros []string
+		switch d.name {
+		case "ifdef", "ifndef":
+			name := firstWord(d.args)
+			if name == "" {
+				continue
+			}
+			guard := d.name == "ifndef" && len(result) == 0 && i+1 < len(found) &&
+				found[i+1].name == "define" && firstWord(found[i+1].args) == name
+			if guard {
+				continue
+			}
+			macros = []string{name}
+		case "if", "elif":
+			macros = conditionMacros(d.args)
+		default:
+			continue
+		}
+		result = append(result, Conditional{Line: d.start + 1, Macros: macros})
+	}
+	return result
+}
+
+func firstWord(text string) string {
+	fields := strings.FieldsFunc(text, func(r rune) bool { return !isIdentRune(r) })
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// conditionMacros lists the macros a condition tests, once each. The
+// arguments of function-like macros such as __has_include are not macros.
+func conditionMacros(condition string) []string {
+	tokens := tokenize(condition)
+	seen := make(map[string]bool)
+	var macros []string
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			macros = append(macros, name)
+		}
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if t.kind != identToken {
+			continue
+		}
+		switch {
+		case t.text == "defined":
+			for j := i + 1; j < len(tokens) && j <= i+2; j++ {
+				if tokens[j].kind == identToken {
+					add(tokens[j].text)
+					i = j
+					break
+				}
+			}
+		case i+1 < len(tokens) && tokens[i+1].text == "(":
+			add(t.text)
+			i = skipParens(tokens, i+1) - 1
+		default:
+			add(t.text)
+		}
+	}
+	return macros
+}
+
+// Preprocess blanks the lines that the conditionals of content leave out when
+// the given macros are defined. Lines keep their numbers and directives are
+// kept. #define and #undef of the file itself are followed, the files it
+// includes are not read.
+func Preprocess(content string, defines map[string]string) string {
+	macros := make(map[string]string, len(defines))
+	for name, value := range defines {
+		macros[name] = value
+	}
+
+	lines := strings.Split(content, "\n")
+	type frame struct {
+		parent bool
+		taken  bool
+		active bool
+	}
+	var stack []frame
+	active := true
+	inactive := make([]bool, len(lines))
+
+	next := 0
+	for _, d := range directives(lines) {
+		for ; next < d.start; next++ {
+			inactive[next] = !active
+		}
+		next = d.end + 1
+
+		switch d.name {
+		case "if", "ifdef", "ifndef":
+			condition := false
+			if active {
+				switch d.name {
+				case "ifdef":
+					_, condition = macros[firstWord(d.args)]
+				case "ifndef":
+					_, condition = macros[firstWord(d.args)]
+					condition = !condition
+				default:
+					condition = evaluate(d.args, macros) != 0
+				}
+			}
+			stack = append(stack, frame{parent: active, taken: condition, active: active && condition})
+		case "elif", "else":
+			if len(stack) == 0 {
+				continue
+			}
+			top := &stack[len(stack)-1]
+			condition := !top.taken
+			if condition && d.name == "elif" && top.parent {
+				condition = evaluate(d.args, macros) != 0
+			}
+			top.active = top.parent && condition
+			top.taken = top.taken || top.active
+		case "endif":
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case "define":
+			if active {
+				name := firstWord(d.args)
+				value := strings.TrimSpace(strings.TrimPrefix(d.args, name))
+				if !strings.HasPrefix(value, "(") {
+					macros[name] = value
+				} else {
+					macros[name] = ""
+				}
+			}
+		case "undef":
+			if active {
+				delete(macros, firstWord(d.args))
+			}
+		}
+		active = len(stack) == 0 || stack[len(stack)-1].active
+	}
+	for ; next < len(lines); next++ {
+		inactive[next] = !active
+	}
+
+	for i, line := range lines {
+		if inactive[i] && !directiveRegex.MatchString(line) {
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+type tokenKind int
+
+const (
+	identToken tokenKind = iota
+	numberToken
+	operatorToken
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<<", ">>"}
+
+func tokenize(expr string) []token {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case isIdentRune(c) && !(c >= '0' && c <= '9'):
+			j := i
+			for j < len(expr) && isIdentRune(rune(expr[j])) {
+				j++
+			}
+			tokens = append(tokens, token{identToken, expr[i:j]})
+			i = j
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(expr) && (isIdentRune(rune(expr[j])) || expr[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{numberToken, expr[i:j]})
+			i = j
+		case c == '\'':
+			// Character literals count as 0
+			j := i + 1
+			for j < len(expr) && expr[j] != '\'' {
+				if expr[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			tokens = append(tokens, token{numberToken, "0"})
+			i = j + 1
+		default:
+			text := string(c)
+			for _, op := range operators {
+				if strings.HasPrefix(expr[i:], op) {
+					text = op
+					break
+				}
+			}
+			tokens = append(tokens, token{operatorToken, text})
+			i += len(text)
+		}
+	}
+	return tokens
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+// skipParens returns the index after the parenthesis closing the one at open.
+func skipParens(tokens []token, open int) int {
+	depth := 0
+	for i := open; i < len(tokens); i++ {
+		switch tokens[i].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(tokens)
+}
+
+// evaluate computes a #if condition as the preprocessor does: undefined
+// macros are 0, defined ones are replaced by their value. Function-like
+// macros and malformed conditions count as 0.
+func evaluate(condition string, macros map[string]string) int64 {
+	e := &evaluator{tokens: tokenize(condition), macros: macros}
+	return e.ternary()
+}
+
+type evaluator struct {
+	tokens []token
+	pos    int
+	macros map[string]string
+	depth  int
+}
+
+var precedence = map[string]int{
+	"||": 1, "&&": 2, "|": 3, "^": 4, "&": 5,
+	"==": 6, "!=": 6, "<": 7, ">": 7, "<=": 7, ">=": 7,
+	"<<": 8, ">>": 8, "+": 9, "-": 9, "*": 10, "/": 10, "%": 10,
+}
+
+func (e *evaluator) peek() string {
+	if e.pos < len(e.tokens) && e.tokens[e.pos].kind == operatorToken {
+		return e.tokens[e.pos].text
+	}
+	return ""
+}
+
+func (e *evaluator) ternary() int64 {
+	condition := e.binary(1)
+	if e.peek() != "?" {
+		return condition
+	}
+	e.pos++
+	yes := e.ternary()
+	if e.peek() == ":" {
+		e.pos++
+	}
+	no := e.ternary()
+	if condition != 0 {
+		return yes
+	}
+	return no
+}
+
+func (e *evaluator) binary(minPrecedence int) int64 {
+	left := e.unary()
+	for {
+		op := e.peek()
+		prec, ok := precedence[op]
+		if !ok || prec < minPrecedence {
+			return left
+		}
+		e.pos++
+		right := e.binary(prec + 1)
+		left = apply(op, left, right)
+	}
+}
+
+func apply(op string, left, right int64) int64 {
+	boolean := func(b bool) int64 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+	switch op {
+	case "||":
+		return boolean(left != 0 || right != 0)
+	case "&&":
+		return boolean(left != 0 && right != 0)
+	case "|":
+		return left | right
+	case "^":
+		return left ^ right
+	case "&":
+		return left & right
+	case "==":
+		return boolean(left == right)
+	case "!=":
+		return boolean(left != right)
+	case "<":
+		return boolean(left < right)
+	case ">":
+		return boolean(left > right)
+	case "<=":
+		return boolean(left <= right)
+	case ">=":
+		return boolean(left >= right)
+	case "<<":
+		return left << (uint64(right) & 63)
+	case ">>":
+		return left >> (uint64(right) & 63)
+	case "+":
+		return left + right
+	case "-":
+		return left - right
+	case "*":
+		return left * right
+	case "/":
+		if right == 0 {
+			return 0
+		}
+		return left / right
+	case "%":
+		if right == 0 {
+			return 0
+		}
+		return left % right
+	}
+	return 0
+}
+
+func (e *evaluator) unary() int64 {
+	switch e.peek() {
+	case "!":
+		e.pos++
+		if e.unary() == 0 {
+			return 1
+		}
+		return 0
+	case "~":
+		e.pos++
+		return ^e.unary()
+	case "-":
+		e.pos++
+		return -e.unary()
+	case "+":
+		e.pos++
+		return e.unary()
+	}
+	return e.primary()
+}
+
+func (e *evaluator) primary() int64 {
+	if e.pos >= len(e.tokens) {
+		return 0
+	}
+	t := e.tokens[e.pos]
+	e.pos++
+
+	switch {
+	case t.text == "(":
+		value := e.ternary()
+		if e.peek() == ")" {
+			e.pos++
+		}
+		return value
+	case t.kind == numberToken:
+		text := strings.TrimRight(strings.ToLower(t.text), "ul")
+		value, err := strconv.ParseInt(text, 0, 64)
+		if err != nil {
+			unsigned, _ := strconv.ParseUint(text, 0, 64)
+			value = int64(unsigned)
+		}
+		return value
+	case t.text == "defined":
+		parens := e.peek() == "("
+		if parens {
+			e.pos++
+		}
+		defined := false
+		if e.pos < len(e.tokens) && e.tokens[e.pos].kind == identToken {
+			_, defined = e.macros[e.tokens[e.pos].text]
+			e.pos++
+		}
+		if parens && e.peek() == ")" {
+			e.pos++
+		}
+		if defined {
+			return 1
+		}
+		return 0
+	case t.kind == identToken:
+		if e.peek() == "(" {
+			e.pos = skipParens(e.tokens, e.pos)
+			return 0
+		}
+		value, ok := e.macros[t.text]
+		if !ok || strings.TrimSpace(value) == "" || e.depth > 16 {
+			return 0
+		}
+		nested := &evaluator{tokens: tokenize(value), macros: e.macros, depth: e.depth + 1}
+		return nested.ternary()
+	}
+	return 0
+}
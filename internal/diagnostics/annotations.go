@@ -0,0 +1,65 @@
+package diagnostics
+
+import "time"
+
+// AnnotationDate is the layout of IgnoreUntil.
+const AnnotationDate = "2006-01-02"
+
+// Annotation is a note a team keeps on a finding, found by its fingerprint.
+// Reports carry the comment and owners, and a finding is left out of them
+// before its IgnoreUntil date and reported again from that day on.
+type Annotation struct {
+	Fingerprint string   `yaml:"fingerprint" json:"-"`
+	Comment     string   `yaml:"comment" json:"comment,omitempty"`
+	Owners      []string `yaml:"owners" json:"owners,omitempty"`
+	IgnoreUntil string   `yaml:"ignore-until" json:"ignore_until,omitempty"`
+	// Expired is set in reports on findings whose IgnoreUntil has passed
+	Expired bool `yaml:"-" json:"expired,omitempty"`
+}
+
+var (
+	activeAnnotations map[string]Annotation
+	// today is replaced by tests
+	today = func() string { return time.Now().Format(AnnotationDate) }
+)
+
+// SetAnnotations selects the annotations Filtered, Keep and the report
+// formats apply, nil drops them.
+func SetAnnotations(annotations []Annotation) {
+	activeAnnotations = nil
+	if len(annotations) == 0 {
+		return
+	}
+	activeAnnotations = make(map[string]Annotation, len(annotations))
+	for _, annotation := range annotations {
+		activeAnnotations[annotation.Fingerprint] = annotation
+	}
+}
+
+// annotationOf returns the annotation of the finding with the fingerprint,
+// marked expired once its date has passed.
+func annotationOf(fingerprint string) (Annotation, bool) {
+	annotation, ok := activeAnnotations[fingerprint]
+	if ok && annotation.IgnoreUntil != "" && annotation.IgnoreUntil <= today() {
+		annotation.Expired = true
+	}
+	return annotation, ok
+}
+
+// ignored reports whether an annotation hides the finding for now.
+func ignored(fingerprint string) bool {
+	annotation, ok := annotationOf(fingerprint)
+	return ok && annotation.IgnoreUntil != "" && !annotation.Expired
+}
+
+// annotationsOf returns the annotation of each diagnostic, nil for those
+// without one.
+func annotationsOf(fingerprints []string) []*Annotation {
+	annotations := make([]*Annotation, len(fingerprints))
+	for i, fingerprint := range fingerprints {
+		if annotation, ok := annotationOf(fingerprint); ok {
+			annotations[i] = &annotation
+		}
+	}
+	return annotations
+}
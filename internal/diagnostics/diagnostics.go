@@ -0,0 +1,397 @@
+package diagnostics
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vitruves/gop/internal/utils"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Diagnostic is a finding at a source location, in the shape CI annotation
+// tools expect.
+type Diagnostic struct {
+	File     string
+	Line     int
+	Column   int
+	Severity Severity
+	// Rule identifies the check, e.g. "placeholders/hardcoded_secret"
+	Rule    string
+	Message string
+	// Function and Excerpt are optional and only feed the fingerprint: the
+	// enclosing function and the source text the finding is about
+	Function string
+	Excerpt  string
+	// Owners are the CODEOWNERS entries of the file, when known
+	Owners []string
+	// ScannedFile is the file as it was scanned, kept when File is rewritten
+	// for display. The fingerprint is taken from it, so that it does not
+	// change with the path style
+	ScannedFile string
+}
+
+// DisplayPaths returns the diagnostics with their files written in the
+// selected path style, see utils.DisplayPath.
+func DisplayPaths(diagnostics []Diagnostic) []Diagnostic {
+	displayed := make([]Diagnostic, len(diagnostics))
+	for i, d := range diagnostics {
+		if d.ScannedFile == "" {
+			d.ScannedFile = d.File
+		}
+		d.File = utils.DisplayPath(d.File)
+		displayed[i] = d
+	}
+	return displayed
+}
+
+// Formats lists the output formats Format accepts.
+var Formats = []string{"checkstyle", "codeclimate", "json", "sarif"}
+
+func IsFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+func Format(diagnostics []Diagnostic, format string) ([]byte, error) {
+	switch format {
+	case "checkstyle":
+		return formatCheckstyle(diagnostics)
+	case "codeclimate":
+		return formatCodeClimate(diagnostics)
+	case "json":
+		return formatJSON(diagnostics)
+	case "sarif":
+		return formatSARIF(diagnostics)
+	default:
+		return nil, fmt.Errorf("unsupported diagnostics format: %s", format)
+	}
+}
+
+// WriteReport writes the diagnostics to w in one of Formats. Analyzers write
+// their report with it even when they found nothing, so a CI run that fixed
+// the last finding replaces the report and clears its stale annotations.
+func WriteReport(w io.Writer, format string, diagnostics []Diagnostic) error {
+	output, err := Format(diagnostics, format)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(output)
+	return err
+}
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// formatCheckstyle groups diagnostics by file as in Checkstyle's XML report,
+// which reviewdog and most CI servers read.
+func formatCheckstyle(diagnostics []Diagnostic) ([]byte, error) {
+	report := checkstyleReport{Version: "4.3"}
+
+	byFile := make(map[string][]checkstyleError)
+	for _, d := range diagnostics {
+		byFile[d.File] = append(byFile[d.File], checkstyleError{
+			Line:     d.Line,
+			Column:   d.Column,
+			Severity: string(d.Severity),
+			Message:  d.Message,
+			Source:   "gop." + d.Rule,
+		})
+	}
+
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		report.Files = append(report.Files, checkstyleFile{Name: file, Errors: byFile[file]})
+	}
+
+	output, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(output, '\n')...), nil
+}
+
+type codeClimateIssue struct {
+	Type        string              `json:"type"`
+	CheckName   string              `json:"check_name"`
+	Description string              `json:"description"`
+	Categories  []string            `json:"categories"`
+	Severity    string              `json:"severity"`
+	Fingerprint string              `json:"fingerprint"`
+	Location    codeClimateLocation `json:"location"`
+}
+
+type codeClimateLocation struct {
+	Path  string           `json:"path"`
+	Lines codeClimateLines `json:"lines"`
+}
+
+type codeClimateLines struct {
+	Begin int `json:"begin"`
+}
+
+var codeClimateSeverities = map[Severity]string{
+	SeverityError:   "critical",
+	SeverityWarning: "major",
+	SeverityInfo:    "minor",
+}
+
+// Fingerprints returns a stable identity for each diagnostic, so tools
+// comparing runs can tell new findings from persistent and fixed ones. It
+// hashes the rule, the path relative to the project root (see
+// utils.ProjectPath), the function and the whitespace normalized excerpt, falling back to the message when neither function nor
+// excerpt is known. Line numbers are left out so a finding keeps its identity
+// when code above it moves.
+func Fingerprints(diagnostics []Diagnostic) []string {
+	fingerprints := make([]string, len(diagnostics))
+	seen := make(map[string]int)
+
+	for i, d := range diagnostics {
+		subject := d.Function + "|" + strings.Join(strings.Fields(d.Excerpt), " ")
+		if d.Function == "" && d.Excerpt == "" {
+			subject = d.Message
+		}
+		path := d.ScannedFile
+		if path == "" {
+			path = d.File
+		}
+		sum := md5.Sum([]byte(d.Rule + "|" + utils.ProjectPath(path) + "|" + subject))
+		fingerprint := hex.EncodeToString(sum[:])
+
+		// Identical findings in one file still need distinct fingerprints
+		seen[fingerprint]++
+		if n := seen[fingerprint]; n > 1 {
+			sum = md5.Sum([]byte(fmt.Sprintf("%s|%d", fingerprint, n)))
+			fingerprint = hex.EncodeToString(sum[:])
+		}
+		fingerprints[i] = fingerprint
+	}
+
+	return fingerprints
+}
+
+func normalizePath(path string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "./")
+}
+
+// formatCodeClimate writes the issue array GitLab's code quality widget reads.
+func formatCodeClimate(diagnostics []Diagnostic) ([]byte, error) {
+	issues := make([]codeClimateIssue, 0, len(diagnostics))
+	fingerprints := Fingerprints(diagnostics)
+
+	for i, d := range diagnostics {
+		fingerprint := fingerprints[i]
+		issues = append(issues, codeClimateIssue{
+			Type:        "issue",
+			CheckName:   d.Rule,
+			Description: d.Message,
+			Categories:  []string{"Bug Risk"},
+			Severity:    codeClimateSeverities[d.Severity],
+			Fingerprint: fingerprint,
+			Location: codeClimateLocation{
+				Path:  filepath.ToSlash(d.File),
+				Lines: codeClimateLines{Begin: d.Line},
+			},
+		})
+	}
+
+	output, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(output, '\n'), nil
+}
+
+type jsonDiagnostic struct {
+	File        string   `json:"file"`
+	Line        int      `json:"line"`
+	Column      int      `json:"column,omitempty"`
+	Severity    string   `json:"severity"`
+	Level       string   `json:"level"`
+	Rule        string   `json:"rule"`
+	Message     string   `json:"message"`
+	Function    string   `json:"function,omitempty"`
+	Owners      []string `json:"owners,omitempty"`
+	Fingerprint string   `json:"fingerprint"`
+	// Annotation is the note .gop/annotations.yaml keeps on the finding
+	Annotation *Annotation `json:"annotation,omitempty"`
+}
+
+func formatJSON(diagnostics []Diagnostic) ([]byte, error) {
+	fingerprints := Fingerprints(diagnostics)
+	annotations := annotationsOf(fingerprints)
+	findings := make([]jsonDiagnostic, 0, len(diagnostics))
+	for i, d := range diagnostics {
+		findings = append(findings, jsonDiagnostic{
+			File:        filepath.ToSlash(d.File),
+			Line:        d.Line,
+			Column:      d.Column,
+			Severity:    string(d.Severity),
+			Level:       string(LevelOf(d)),
+			Rule:        d.Rule,
+			Message:     d.Message,
+			Function:    d.Function,
+			Owners:      d.Owners,
+			Fingerprint: fingerprints[i],
+			Annotation:  annotations[i],
+		})
+	}
+
+	output, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(output, '\n'), nil
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Properties          *sarifProperties  `json:"properties,omitempty"`
+}
+
+type sarifProperties struct {
+	Annotation *Annotation `json:"annotation"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+var sarifLevels = map[Severity]string{
+	SeverityError:   "error",
+	SeverityWarning: "warning",
+	SeverityInfo:    "note",
+}
+
+// formatSARIF writes a SARIF 2.1.0 log, the format GitHub code scanning
+// uploads read.
+func formatSARIF(diagnostics []Diagnostic) ([]byte, error) {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "gop", InformationURI: "https://github.com/vitruves/gop", Rules: []sarifRule{}}},
+		Results: make([]sarifResult, 0, len(diagnostics)),
+	}
+	fingerprints := Fingerprints(diagnostics)
+	annotations := annotationsOf(fingerprints)
+
+	rules := make(map[string]bool)
+	for i, d := range diagnostics {
+		if !rules[d.Rule] {
+			rules[d.Rule] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: d.Rule})
+		}
+		location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: normalizePath(d.File)},
+		}}
+		// SARIF lines start at 1, findings about a whole file have none
+		if d.Line > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: d.Line, StartColumn: d.Column}
+		}
+		result := sarifResult{
+			RuleID:              d.Rule,
+			Level:               sarifLevels[d.Severity],
+			Message:             sarifMessage{Text: d.Message},
+			Locations:           []sarifLocation{location},
+			PartialFingerprints: map[string]string{"gop/v1": fingerprints[i]},
+		}
+		if annotations[i] != nil {
+			result.Properties = &sarifProperties{Annotation: annotations[i]}
+		}
+		run.Results = append(run.Results, result)
+	}
+	sort.Slice(run.Tool.Driver.Rules, func(i, j int) bool { return run.Tool.Driver.Rules[i].ID < run.Tool.Driver.Rules[j].ID })
+
+	output, err := json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(output, '\n'), nil
+}
@@ -0,0 +1,381 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vitruves/gop/internal/utils"
+)
+
+var sample = []Diagnostic{
+	{File: "b.go", Line: 3, Column: 5, Severity: SeverityWarning, Rule: "placeholders/comment", Message: "FIXME: \"quoted\" <tag>"},
+	{File: "a.go", Line: 1, Severity: SeverityError, Rule: "placeholders/hardcoded_secret", Message: "secret"},
+	{File: "a.go", Line: 9, Severity: SeverityError, Rule: "placeholders/hardcoded_secret", Message: "secret"},
+}
+
+func TestFormatCheckstyle(t *testing.T) {
+	output, err := Format(sample, "checkstyle")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := string(output)
+	if !strings.HasPrefix(text, `<?xml version="1.0" encoding="UTF-8"?>`) {
+		t.Errorf("missing XML header:\n%s", text)
+	}
+	if strings.Index(text, `name="a.go"`) > strings.Index(text, `name="b.go"`) {
+		t.Errorf("files are not sorted:\n%s", text)
+	}
+	if !strings.Contains(text, `<error line="3" column="5" severity="warning" message="FIXME: &#34;quoted&#34; &lt;tag&gt;" source="gop.placeholders/comment"></error>`) {
+		t.Errorf("unexpected error element:\n%s", text)
+	}
+}
+
+func TestFormatCodeClimate(t *testing.T) {
+	output, err := Format(sample, "codeclimate")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var issues []codeClimateIssue
+	if err := json.Unmarshal(output, &issues); err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("got %d issues, want 3", len(issues))
+	}
+	if issues[1].Severity != "critical" || issues[1].Location.Path != "a.go" || issues[1].Location.Lines.Begin != 1 {
+		t.Errorf("unexpected issue: %+v", issues[1])
+	}
+	if issues[1].Fingerprint == issues[2].Fingerprint {
+		t.Errorf("identical findings share a fingerprint")
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	// Every format writes a report without findings, replacing the last one
+	for _, format := range Formats {
+		var sb strings.Builder
+		if err := WriteReport(&sb, format, nil); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		parsed, err := Parse([]byte(sb.String()))
+		if sb.Len() == 0 || err != nil || len(parsed) != 0 {
+			t.Errorf("%s: expected an empty report, got %q (%v)", format, sb.String(), err)
+		}
+	}
+
+	var sb strings.Builder
+	if err := WriteReport(&sb, "text", sample); err == nil || sb.Len() != 0 {
+		t.Errorf("Expected text to be left to the analyzers, got %q", sb.String())
+	}
+}
+
+func TestFingerprints(t *testing.T) {
+	before := []Diagnostic{
+		{File: "./src/a.go", Line: 10, Rule: "complexity", Message: "run has cyclomatic complexity 12", Function: "run"},
+		{File: "src/b.go", Line: 3, Rule: "placeholders/comment", Message: "TODO: x", Excerpt: "// TODO:   x"},
+	}
+	after := []Diagnostic{
+		{File: "src/a.go", Line: 25, Rule: "complexity", Message: "run has cyclomatic complexity 14", Function: "run"},
+		{File: "src/b.go", Line: 7, Rule: "placeholders/comment", Message: "TODO: x", Excerpt: "  // TODO: x"},
+		{File: "src/b.go", Line: 9, Rule: "placeholders/comment", Message: "TODO: y", Excerpt: "// TODO: y"},
+	}
+
+	old, current := Fingerprints(before), Fingerprints(after)
+	if old[0] != current[0] || old[1] != current[1] {
+		t.Errorf("moved findings changed fingerprints: %v -> %v", old, current)
+	}
+	if current[2] == current[1] {
+		t.Errorf("different excerpts share a fingerprint")
+	}
+}
+
+func TestFormatJSON(t *testing.T) {
+	output, err := Format(sample, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var findings []jsonDiagnostic
+	if err := json.Unmarshal(output, &findings); err != nil {
+		t.Fatal(err)
+	}
+	fingerprints := Fingerprints(sample)
+	for i, finding := range findings {
+		if finding.Fingerprint != fingerprints[i] || finding.Rule != sample[i].Rule {
+			t.Errorf("finding %d = %+v", i, finding)
+		}
+	}
+}
+
+func TestFormatSARIF(t *testing.T) {
+	output, err := Format(sample, "sarif")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(output, &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log: %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[0].ID != "placeholders/comment" {
+		t.Errorf("rules are not listed once each, sorted: %+v", run.Tool.Driver.Rules)
+	}
+	result := run.Results[0]
+	if result.Level != "warning" || result.Locations[0].PhysicalLocation.Region.StartColumn != 5 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result.PartialFingerprints["gop/v1"] != Fingerprints(sample)[0] {
+		t.Errorf("fingerprint not carried: %+v", result.PartialFingerprints)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	findings := []Diagnostic{
+		{File: "a.c", Line: 1, Rule: "errors/unchecked_call", Message: "return value of malloc is ignored", Function: "leak"},
+		{File: "a.c", Line: 2, Rule: "errors/unchecked_call", Message: "return value of fclose is ignored", Function: "store_close"},
+		{File: "a.c", Line: 3, Rule: "errors/empty_catch", Message: "empty catch block"},
+	}
+
+	filter, err := NewFilter([]string{"fclose"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetFilter(filter)
+	defer SetFilter(nil)
+	if kept := Filtered(findings); len(kept) != 2 || kept[0].Line != 1 || kept[1].Line != 3 {
+		t.Errorf("Expected the fclose finding to be dropped, got %+v", kept)
+	}
+
+	filter, _ = NewFilter([]string{"^errors/empty_catch$"}, []string{"^(leak|store_)"})
+	SetFilter(filter)
+	// The finding outside any function is dropped once functions are selected
+	if kept := Filtered(findings); len(kept) != 2 || kept[0].Function != "leak" || kept[1].Function != "store_close" {
+		t.Errorf("Expected the two findings in matching functions, got %+v", kept)
+	}
+
+	if _, err := NewFilter(nil, []string{"("}); err == nil || !strings.Contains(err.Error(), "--include-function") {
+		t.Errorf("Expected an invalid pattern to name its flag, got %v", err)
+	}
+}
+
+func TestWhere(t *testing.T) {
+	findings := []Diagnostic{
+		{File: "src/net/socket.c", Line: 12, Rule: "errors/unchecked_call", Severity: SeverityError, Owners: []string{"@net"}},
+		{File: "src/net/socket.c", Line: 40, Rule: "placeholders/debug_print", Severity: SeverityWarning},
+		{File: "src/ui/view.c", Line: 3, Rule: "placeholders/hardcoded_secret", Severity: SeverityWarning, Message: "API key"},
+	}
+
+	tests := []struct {
+		expression string
+		lines      []int
+	}{
+		{"", []int{12, 40, 3}},
+		{"severity>=high", []int{12, 3}},
+		{"severity>=high && file~'src/net/'", []int{12}},
+		{`rule!="errors/unchecked_call" && !(line < 10)`, []int{40}},
+		{"severity==critical || owner=@net", []int{12, 3}},
+		{"owner !~ net && message ~ '(?i)key'", []int{3}},
+	}
+	for _, test := range tests {
+		where, err := ParseWhere(test.expression)
+		if err != nil {
+			t.Fatalf("%q: %v", test.expression, err)
+		}
+		var lines []int
+		for _, d := range findings {
+			if where.Match(d) {
+				lines = append(lines, d.Line)
+			}
+		}
+		if fmt.Sprint(lines) != fmt.Sprint(test.lines) {
+			t.Errorf("%q: expected lines %v, got %v", test.expression, test.lines, lines)
+		}
+	}
+
+	invalid := []string{"severity>=urgent", "rule>a", "line==ten", "colour==red", "(rule==a", "rule==", "rule~'('", "rule=='a", "rule==a b"}
+	for _, expression := range invalid {
+		if _, err := ParseWhere(expression); err == nil || !strings.Contains(err.Error(), "--where") {
+			t.Errorf("Expected %q to be rejected, got %v", expression, err)
+		}
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	findings := []Diagnostic{
+		{File: "a.c", Line: 1, Rule: "errors/unchecked_call", Message: "fclose"},
+		{File: "a.c", Line: 2, Rule: "errors/unchecked_call", Message: "fwrite"},
+		{File: "b.c", Line: 3, Rule: "errors/unchecked_call", Message: "fread"},
+	}
+	fingerprints := Fingerprints(findings)
+
+	defer func(saved func() string) { today = saved }(today)
+	today = func() string { return "2025-05-31" }
+	SetAnnotations([]Annotation{
+		{Fingerprint: fingerprints[0], IgnoreUntil: "2025-06-01"},
+		{Fingerprint: fingerprints[1], Comment: "Checked by the caller", Owners: []string{"@io"}},
+	})
+	defer SetAnnotations(nil)
+
+	kept := Filtered(findings)
+	if len(kept) != 2 || kept[0].Line != 2 || kept[1].Line != 3 || Keep(findings[0]) || !Keep(findings[1]) {
+		t.Fatalf("Expected the first finding to be ignored until June, got %+v", kept)
+	}
+
+	output, err := formatJSON(kept)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reported []jsonDiagnostic
+	if err := json.Unmarshal(output, &reported); err != nil {
+		t.Fatal(err)
+	}
+	if reported[0].Annotation == nil || reported[0].Annotation.Comment != "Checked by the caller" || reported[1].Annotation != nil {
+		t.Errorf("Expected the comment on the fwrite finding only, got %s", output)
+	}
+
+	// From the date on the finding is reported again, marked expired
+	today = func() string { return "2025-06-01" }
+	if kept := Filtered(findings); len(kept) != 3 {
+		t.Fatalf("Expected the finding to be reported again, got %+v", kept)
+	}
+	output, err = formatSARIF(findings[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(output), `"expired": true`) {
+		t.Errorf("Expected the expired annotation in the SARIF result, got %s", output)
+	}
+}
+
+// A finding keeps its fingerprint whatever the path style and the directory
+// gop runs from, so annotations keep applying to it.
+func TestFingerprintsIgnorePathStyle(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		os.Chdir(wd)
+		utils.SetPathStyle(utils.PathStyleRelative, "")
+	}()
+
+	reported := func(dir, path, style string) string {
+		t.Helper()
+		if err := os.Chdir(dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := utils.SetPathStyle(style, root); err != nil {
+			t.Fatal(err)
+		}
+		finding := Diagnostic{File: path, Line: 3, Rule: "errors/unchecked_call", Message: "fclose", Function: "save"}
+		output, err := formatJSON(DisplayPaths([]Diagnostic{finding}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var findings []jsonDiagnostic
+		if err := json.Unmarshal(output, &findings); err != nil {
+			t.Fatal(err)
+		}
+		return findings[0].Fingerprint
+	}
+
+	want := reported(root, "src/a.c", utils.PathStyleRelative)
+	runs := []struct{ dir, path string }{
+		{root, "src/a.c"},
+		{root, filepath.Join(root, "src", "a.c")},
+		{filepath.Join(root, "src"), "a.c"},
+	}
+	for _, run := range runs {
+		for _, style := range utils.PathStyles {
+			if got := reported(run.dir, run.path, style); got != want {
+				t.Errorf("%s from %s with --path-style %s: fingerprint %s, want %s", run.path, run.dir, style, got, want)
+			}
+		}
+	}
+
+	// An annotation taken from a relative report still ignores the finding
+	// in an absolute one
+	defer func(saved func() string) { today = saved }(today)
+	today = func() string { return "2025-05-31" }
+	SetAnnotations([]Annotation{{Fingerprint: want, IgnoreUntil: "2025-06-01"}})
+	defer SetAnnotations(nil)
+	if err := utils.SetPathStyle(utils.PathStyleAbsolute, root); err != nil {
+		t.Fatal(err)
+	}
+	finding := Diagnostic{File: "a.c", Line: 3, Rule: "errors/unchecked_call", Message: "fclose", Function: "save"}
+	if kept := Filtered([]Diagnostic{finding}); len(kept) != 0 {
+		t.Errorf("Expected the annotation to ignore the finding under --path-style absolute, got %+v", kept)
+	}
+}
+
+func TestParse(t *testing.T) {
+	findings := []Diagnostic{
+		{File: "src/a.c", Line: 3, Column: 5, Severity: SeverityError, Rule: "errors/unchecked_call", Message: "return value of malloc is ignored", Function: "leak"},
+		{File: "src/b.c", Line: 9, Severity: SeverityWarning, Rule: "errors/empty_catch", Message: "empty catch block"},
+	}
+
+	for _, format := range Formats {
+		output, err := Format(findings, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := Parse(output)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if len(parsed) != len(findings) {
+			t.Fatalf("%s: parsed %d findings, want %d", format, len(parsed), len(findings))
+		}
+		for i, want := range findings {
+			got := parsed[i]
+			if got.File != want.File || got.Line != want.Line || got.Rule != want.Rule || got.Message != want.Message || got.Severity != want.Severity {
+				t.Errorf("%s: parsed %+v, want %+v", format, got, want)
+			}
+		}
+	}
+
+	if _, err := Parse([]byte("src/a.c:3:5: error")); err == nil {
+		t.Error("Expected text output to be rejected")
+	}
+}
+
+func TestLevelOf(t *testing.T) {
+	tests := []struct {
+		finding Diagnostic
+		want    Level
+	}{
+		{Diagnostic{Severity: SeverityError, Rule: "placeholders/hardcoded_secret"}, LevelCritical},
+		{Diagnostic{Severity: SeverityWarning, Rule: "placeholders/comment"}, LevelMedium},
+		{Diagnostic{Severity: SeverityInfo, Rule: "placeholders/comment"}, LevelLow},
+		{Diagnostic{Severity: SeverityInfo, Rule: "includes/forward_declare"}, LevelInfo},
+		{Diagnostic{Severity: SeverityError, Rule: "license/missing_header"}, LevelLow},
+		{Diagnostic{Severity: SeverityError, Rule: "sanitizer/heap-use-after-free"}, LevelCritical},
+		{Diagnostic{Severity: SeverityWarning, Rule: "sanitizer/memory-leak"}, LevelHigh},
+		{Diagnostic{Severity: SeverityWarning, Rule: "clang-tidy/clang-analyzer-core.NullDereference"}, LevelHigh},
+		{Diagnostic{Severity: SeverityWarning, Rule: "clang-tidy/readability-braces"}, LevelMedium},
+		{Diagnostic{Severity: SeverityError, Rule: "compiler/unused-variable"}, LevelHigh},
+		{Diagnostic{Severity: "note", Rule: "other"}, LevelMedium},
+	}
+	for _, tt := range tests {
+		if got := LevelOf(tt.finding); got != tt.want {
+			t.Errorf("LevelOf(%s, %s) = %s, want %s", tt.finding.Rule, tt.finding.Severity, got, tt.want)
+		}
+	}
+}
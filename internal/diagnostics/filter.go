@@ -0,0 +1,118 @@
+package diagnostics
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Filter drops findings after analysis: those whose rule or message matches
+// an excluded pattern, those a --where expression rejects, those the
+// per-directory overrides drop and, when function patterns are set, those
+// outside the matching functions.
+type Filter struct {
+	excludes  []*regexp.Regexp
+	functions []*regexp.Regexp
+	where     []*Where
+	overrides func(Diagnostic) bool
+}
+
+// activeFilter is applied by Filtered, it keeps everything until SetFilter.
+var activeFilter *Filter
+
+func NewFilter(excludes, functions []string) (*Filter, error) {
+	filter := &Filter{}
+	for _, pattern := range excludes {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("--exclude-finding %q: %w", pattern, err)
+		}
+		filter.excludes = append(filter.excludes, re)
+	}
+	for _, pattern := range functions {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("--include-function %q: %w", pattern, err)
+		}
+		filter.functions = append(filter.functions, re)
+	}
+	return filter, nil
+}
+
+// WithWhere makes the filter also drop the findings the expression rejects.
+func (f *Filter) WithWhere(where *Where) *Filter {
+	f.where = append(f.where, where)
+	return f
+}
+
+// WithOverrides makes the filter drop the findings keep rejects, the
+// overrides .gop.yaml sets for the directory of their file.
+func (f *Filter) WithOverrides(keep func(Diagnostic) bool) *Filter {
+	f.overrides = keep
+	return f
+}
+
+// SetFilter selects the filter Filtered and Keep apply, nil keeps every
+// finding.
+func SetFilter(filter *Filter) {
+	activeFilter = filter
+}
+
+// Keep reports whether the active filter keeps a finding and no annotation
+// ignores it. Findings that are not attributed to a function are dropped
+// when function patterns are set.
+func Keep(d Diagnostic) bool {
+	if activeAnnotations != nil && ignored(Fingerprints([]Diagnostic{d})[0]) {
+		return false
+	}
+	return activeFilter.Keep(d)
+}
+
+func (f *Filter) Keep(d Diagnostic) bool {
+	if f == nil {
+		return true
+	}
+	for _, re := range f.excludes {
+		if re.MatchString(d.Rule) || re.MatchString(d.Message) {
+			return false
+		}
+	}
+	for _, where := range f.where {
+		if !where.Match(d) {
+			return false
+		}
+	}
+	if f.overrides != nil && !f.overrides(d) {
+		return false
+	}
+	if len(f.functions) == 0 {
+		return true
+	}
+	for _, re := range f.functions {
+		if d.Function != "" && re.MatchString(d.Function) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filtered returns the diagnostics the active filter keeps, without those
+// an annotation ignores for now.
+func Filtered(diagnostics []Diagnostic) []Diagnostic {
+	if activeFilter == nil && activeAnnotations == nil {
+		return diagnostics
+	}
+	var fingerprints []string
+	if activeAnnotations != nil {
+		fingerprints = Fingerprints(diagnostics)
+	}
+	kept := []Diagnostic{}
+	for i, d := range diagnostics {
+		if fingerprints != nil && ignored(fingerprints[i]) {
+			continue
+		}
+		if activeFilter.Keep(d) {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,105 @@
+package diagnostics
+
+import "strings"
+
+// Level is the severity of a finding on the scale shared by every analyzer,
+// finer than the error/warning/info that report formats carry.
+type Level string
+
+const (
+	LevelInfo     Level = "info"
+	LevelLow      Level = "low"
+	LevelMedium   Level = "medium"
+	LevelHigh     Level = "high"
+	LevelCritical Level = "critical"
+)
+
+// Levels lists the scale from the least to the most severe.
+var Levels = []Level{LevelInfo, LevelLow, LevelMedium, LevelHigh, LevelCritical}
+
+var levelScores = map[Level]float64{
+	LevelInfo:     0,
+	LevelLow:      1,
+	LevelMedium:   3,
+	LevelHigh:     7,
+	LevelCritical: 15,
+}
+
+// Score is the weight of a finding of the level in quality scores.
+func (l Level) Score() float64 {
+	return levelScores[l]
+}
+
+// ruleLevels maps the rules of the analyzers onto the scale. A key ending
+// in "/" or "-" covers every rule it prefixes, the longest key wins. Rules
+// left out take the level of their severity, see severityLevels.
+var ruleLevels = map[string]Level{
+	"placeholders/hardcoded_secret": LevelCritical,
+	"placeholders/unimplemented":    LevelMedium,
+	"placeholders/exception":        LevelMedium,
+	"placeholders/debug_flag":       LevelMedium,
+	"placeholders/test_flag":        LevelMedium,
+	"placeholders/hardcoded_host":   LevelLow,
+	"placeholders/ip_address":       LevelLow,
+	"placeholders/debug_print":      LevelLow,
+	"placeholders/exit_call":        LevelLow,
+
+	"errors/unchecked_call":       LevelHigh,
+	"errors/unchecked_error_code": LevelMedium,
+	"errors/empty_catch":          LevelMedium,
+
+	"correctness/incomplete_switch": LevelMedium,
+
+	"includes/forward_declare": LevelInfo,
+
+	"license/missing_header":    LevelLow,
+	"license/mismatched_header": LevelLow,
+
+	"sanitizer/":                   LevelCritical,
+	"sanitizer/memory-leak":        LevelHigh,
+	"sanitizer/undefined-behavior": LevelHigh,
+
+	"clang-tidy/clang-analyzer-": LevelHigh,
+	"clang-tidy/cert-":           LevelHigh,
+
+	"compiler/error": LevelHigh,
+
+	"complexity": LevelLow,
+
+	"fix/strcpy":            LevelHigh,
+	"fix/include-guard":     LevelLow,
+	"fix/duplicate-include": LevelLow,
+	"fix/unused-include":    LevelLow,
+}
+
+// severityLevels places the findings of the rules ruleLevels leaves out,
+// whose severity already tells how serious they are: placeholders turned
+// into warnings by a FIXME marker, headers failing to compile, compiler
+// warnings promoted to errors. Only rules known to be harmless are info.
+var severityLevels = map[Severity]Level{
+	SeverityError:   LevelHigh,
+	SeverityWarning: LevelMedium,
+	SeverityInfo:    LevelLow,
+}
+
+// LevelOf places a finding on the shared scale.
+func LevelOf(d Diagnostic) Level {
+	if level, ok := ruleLevels[d.Rule]; ok {
+		return level
+	}
+
+	level, longest := Level(""), 0
+	for prefix, l := range ruleLevels {
+		if len(prefix) > longest && (strings.HasSuffix(prefix, "/") || strings.HasSuffix(prefix, "-")) && strings.HasPrefix(d.Rule, prefix) {
+			level, longest = l, len(prefix)
+		}
+	}
+	if level != "" {
+		return level
+	}
+
+	if level, ok := severityLevels[d.Severity]; ok {
+		return level
+	}
+	return LevelMedium
+}
@@ -0,0 +1,140 @@
+package diagnostics
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Parse reads a report written by Format, in any of its formats, back into
+// diagnostics. Fields a format does not carry are left empty.
+func Parse(data []byte) ([]Diagnostic, error) {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(data, []byte("<")):
+		return parseCheckstyle(data)
+	case bytes.HasPrefix(data, []byte("[")):
+		return parseJSON(data)
+	case bytes.HasPrefix(data, []byte("{")):
+		return parseSARIF(data)
+	default:
+		return nil, fmt.Errorf("not a checkstyle, codeclimate, json or sarif report")
+	}
+}
+
+func parseCheckstyle(data []byte) ([]Diagnostic, error) {
+	var report checkstyleReport
+	if err := xml.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+
+	var diagnostics []Diagnostic
+	for _, file := range report.Files {
+		for _, e := range file.Errors {
+			diagnostics = append(diagnostics, Diagnostic{
+				File:     file.Name,
+				Line:     e.Line,
+				Column:   e.Column,
+				Severity: Severity(e.Severity),
+				Rule:     strings.TrimPrefix(e.Source, "gop."),
+				Message:  e.Message,
+			})
+		}
+	}
+	return diagnostics, nil
+}
+
+// parseJSON reads the json format and the codeclimate one, told apart by
+// the location of their entries.
+func parseJSON(data []byte) ([]Diagnostic, error) {
+	var entries []struct {
+		File        string              `json:"file"`
+		Line        int                 `json:"line"`
+		Column      int                 `json:"column"`
+		Severity    string              `json:"severity"`
+		Rule        string              `json:"rule"`
+		Message     string              `json:"message"`
+		Function    string              `json:"function"`
+		Owners      []string            `json:"owners"`
+		CheckName   string              `json:"check_name"`
+		Description string              `json:"description"`
+		Location    codeClimateLocation `json:"location"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	diagnostics := make([]Diagnostic, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Location.Path != "" {
+			diagnostics = append(diagnostics, Diagnostic{
+				File:     entry.Location.Path,
+				Line:     entry.Location.Lines.Begin,
+				Severity: codeClimateSeverity(entry.Severity),
+				Rule:     entry.CheckName,
+				Message:  entry.Description,
+			})
+			continue
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			File:     entry.File,
+			Line:     entry.Line,
+			Column:   entry.Column,
+			Severity: Severity(entry.Severity),
+			Rule:     entry.Rule,
+			Message:  entry.Message,
+			Function: entry.Function,
+			Owners:   entry.Owners,
+		})
+	}
+	return diagnostics, nil
+}
+
+// parseSARIF reads the results of every run of a SARIF log, from gop or
+// from another tool.
+func parseSARIF(data []byte) ([]Diagnostic, error) {
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, err
+	}
+	if log.Version == "" {
+		return nil, fmt.Errorf("not a sarif report")
+	}
+
+	var diagnostics []Diagnostic
+	for _, run := range log.Runs {
+		for _, result := range run.Results {
+			d := Diagnostic{
+				Severity: SeverityWarning,
+				Rule:     result.RuleID,
+				Message:  result.Message.Text,
+			}
+			for severity, level := range sarifLevels {
+				if level == result.Level {
+					d.Severity = severity
+				}
+			}
+			if len(result.Locations) > 0 {
+				location := result.Locations[0].PhysicalLocation
+				d.File = location.ArtifactLocation.URI
+				if location.Region != nil {
+					d.Line = location.Region.StartLine
+					d.Column = location.Region.StartColumn
+				}
+			}
+			diagnostics = append(diagnostics, d)
+		}
+	}
+	return diagnostics, nil
+}
+
+func codeClimateSeverity(severity string) Severity {
+	for s, name := range codeClimateSeverities {
+		if name == severity {
+			return s
+		}
+	}
+	return SeverityInfo
+}
@@ -0,0 +1,319 @@
+package diagnostics
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Where is a parsed --where expression, a condition on findings such as
+//
+//	severity>=high && file~'src/net/' && rule!='placeholders/debug_print'
+//
+// Comparisons are joined with && and ||, negated with ! and grouped with
+// parentheses. The fields are severity (the level on the shared scale),
+// rule, message, file, function, owner, line and column. Strings compare
+// with == and !=, match a regular expression with ~ and !~, while severity,
+// line and column also order with <, <=, > and >=. Values are quoted with '
+// or ", or written bare when they hold no spaces or operators.
+type Where struct {
+	source string
+	match  func(Diagnostic) bool
+}
+
+// ParseWhere parses a --where expression, an empty one matches everything.
+func ParseWhere(expression string) (*Where, error) {
+	if strings.TrimSpace(expression) == "" {
+		return &Where{match: func(Diagnostic) bool { return true }}, nil
+	}
+
+	tokens, err := tokenizeWhere(expression)
+	if err != nil {
+		return nil, fmt.Errorf("--where %q: %w", expression, err)
+	}
+	p := &whereParser{tokens: tokens}
+	match, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("--where %q: %w", expression, err)
+	}
+	return &Where{source: expression, match: match}, nil
+}
+
+// Match reports whether the finding satisfies the expression.
+func (w *Where) Match(d Diagnostic) bool {
+	return w == nil || w.match(d)
+}
+
+func (w *Where) String() string {
+	return w.source
+}
+
+type whereToken struct {
+	text   string
+	quoted bool
+}
+
+var whereOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "!~", "=", "<", ">", "~", "!", "(", ")"}
+
+func tokenizeWhere(s string) ([]whereToken, error) {
+	var tokens []whereToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c == ' ' || c == '\t' || c == '\n' {
+			i++
+			continue
+		}
+
+		if c == '\'' || c == '"' {
+			end := strings.IndexByte(s[i+1:], c)
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			tokens = append(tokens, whereToken{text: s[i+1 : i+1+end], quoted: true})
+			i += end + 2
+			continue
+		}
+
+		operator := ""
+		for _, op := range whereOperators {
+			if strings.HasPrefix(s[i:], op) {
+				operator = op
+				break
+			}
+		}
+		if operator != "" {
+			tokens = append(tokens, whereToken{text: operator})
+			i += len(operator)
+			continue
+		}
+
+		start := i
+		for i < len(s) && !strings.ContainsRune(" \t\n'\"&|=!<>~()", rune(s[i])) {
+			i++
+		}
+		if start == i {
+			return nil, fmt.Errorf("unexpected %q at offset %d", s[i:i+1], i)
+		}
+		tokens = append(tokens, whereToken{text: s[start:i]})
+	}
+	return tokens, nil
+}
+
+type whereParser struct {
+	tokens []whereToken
+	pos    int
+}
+
+// accept consumes the next token when it is the operator op.
+func (p *whereParser) accept(op string) bool {
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && p.tokens[p.pos].text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *whereParser) next(what string) (whereToken, error) {
+	if p.pos >= len(p.tokens) {
+		return whereToken{}, fmt.Errorf("expected %s at the end", what)
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *whereParser) or() (func(Diagnostic) bool, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(d Diagnostic) bool { return l(d) || right(d) }
+	}
+	return left, nil
+}
+
+func (p *whereParser) and() (func(Diagnostic) bool, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(d Diagnostic) bool { return l(d) && right(d) }
+	}
+	return left, nil
+}
+
+func (p *whereParser) unary() (func(Diagnostic) bool, error) {
+	if p.accept("!") {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(d Diagnostic) bool { return !operand(d) }, nil
+	}
+	if p.accept("(") {
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing )")
+		}
+		return inner, nil
+	}
+	return p.comparison()
+}
+
+// whereStrings returns the values of a string field of a finding, several
+// for owner.
+var whereStrings = map[string]func(Diagnostic) []string{
+	"rule":     func(d Diagnostic) []string { return []string{d.Rule} },
+	"message":  func(d Diagnostic) []string { return []string{d.Message} },
+	"file":     func(d Diagnostic) []string { return []string{wherePath(d.File)} },
+	"function": func(d Diagnostic) []string { return []string{d.Function} },
+	"owner":    func(d Diagnostic) []string { return d.Owners },
+}
+
+var whereNumbers = map[string]func(Diagnostic) int{
+	"line":   func(d Diagnostic) int { return d.Line },
+	"column": func(d Diagnostic) int { return d.Column },
+	"severity": func(d Diagnostic) int {
+		return levelRank(LevelOf(d))
+	},
+}
+
+func (p *whereParser) comparison() (func(Diagnostic) bool, error) {
+	field, err := p.next("a field")
+	if err != nil {
+		return nil, err
+	}
+	operator, err := p.next("an operator after " + field.text)
+	if err != nil {
+		return nil, err
+	}
+	value, err := p.next("a value after " + field.text + operator.text)
+	if err != nil {
+		return nil, err
+	}
+	if operator.quoted {
+		return nil, fmt.Errorf("expected an operator after %s, got %q", field.text, operator.text)
+	}
+	op := operator.text
+	if op == "=" {
+		op = "=="
+	}
+
+	if values, ok := whereStrings[field.text]; ok {
+		return stringComparison(field.text, op, value.text, values)
+	}
+	if number, ok := whereNumbers[field.text]; ok {
+		return numberComparison(field.text, op, value.text, number)
+	}
+	return nil, fmt.Errorf("unknown field %q (expected severity, rule, message, file, function, owner, line or column)", field.text)
+}
+
+func stringComparison(field, op, value string, values func(Diagnostic) []string) (func(Diagnostic) bool, error) {
+	var matches func(string) bool
+	switch op {
+	case "==", "!=":
+		matches = func(s string) bool { return s == value }
+	case "~", "!~":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s%s: %w", field, op, err)
+		}
+		matches = re.MatchString
+	default:
+		return nil, fmt.Errorf("%s compares with ==, !=, ~ and !~, not %s", field, op)
+	}
+
+	negate := op == "!=" || op == "!~"
+	return func(d Diagnostic) bool {
+		for _, s := range values(d) {
+			if matches(s) {
+				return !negate
+			}
+		}
+		return negate
+	}, nil
+}
+
+func numberComparison(field, op, value string, number func(Diagnostic) int) (func(Diagnostic) bool, error) {
+	var operand int
+	if field == "severity" {
+		operand = levelRank(Level(strings.ToLower(value)))
+		if operand == -1 {
+			return nil, fmt.Errorf("unknown severity %q (expected %s)", value, levelNames())
+		}
+	} else {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s expects a number, got %q", field, value)
+		}
+		operand = n
+	}
+
+	compare := map[string]func(a, b int) bool{
+		"==": func(a, b int) bool { return a == b },
+		"!=": func(a, b int) bool { return a != b },
+		"<":  func(a, b int) bool { return a < b },
+		"<=": func(a, b int) bool { return a <= b },
+		">":  func(a, b int) bool { return a > b },
+		">=": func(a, b int) bool { return a >= b },
+	}[op]
+	if compare == nil {
+		return nil, fmt.Errorf("%s compares with ==, !=, <, <=, > and >=, not %s", field, op)
+	}
+	return func(d Diagnostic) bool { return compare(number(d), operand) }, nil
+}
+
+// levelRank is the position of the level in Levels, -1 for unknown levels.
+func levelRank(level Level) int {
+	for i, l := range Levels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+func levelNames() string {
+	names := make([]string, len(Levels))
+	for i, level := range Levels {
+		names[i] = string(level)
+	}
+	return strings.Join(names, ", ")
+}
+
+// wherePath writes a finding's file relative to the working directory with
+// forward slashes, so expressions read the same whatever path it was found
+// by.
+func wherePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	wd, err := filepath.Abs(".")
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	if rel, err := filepath.Rel(wd, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
@@ -0,0 +1,86 @@
+package docs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	"github.com/vitruves/gop/internal/color"
+)
+
+type Config struct {
+	Root      *cobra.Command
+	OutputDir string
+	Formats   []string
+	Version   string
+	Verbose   bool
+}
+
+func Run(config Config) error {
+	// The footer cobra adds is dated with the current time, which would keep
+	// SOURCE_DATE_EPOCH from making pages reproducible
+	config.Root.DisableAutoGenTag = true
+
+	for _, format := range config.Formats {
+		var dir string
+		var generate func(dir string) error
+
+		switch format {
+		case "markdown", "md":
+			dir = filepath.Join(config.OutputDir, "markdown")
+			generate = func(dir string) error {
+				return doc.GenMarkdownTree(config.Root, dir)
+			}
+		case "man":
+			dir = filepath.Join(config.OutputDir, "man", "man1")
+			header := &doc.GenManHeader{Source: "gop " + config.Version, Manual: "gop Manual"}
+			generate = func(dir string) error {
+				return doc.GenManTree(config.Root, header, dir)
+			}
+		default:
+			return fmt.Errorf("unsupported format: %s (expected markdown or man)", format)
+		}
+
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+
+		logInfo(config.Verbose, fmt.Sprintf("Writing %s pages to %s", format, dir))
+		if err := generate(dir); err != nil {
+			return err
+		}
+
+		logSuccess(fmt.Sprintf("Generated %d %s pages in %s", countPages(config.Root), format, dir))
+	}
+
+	return nil
+}
+
+// countPages counts cmd and the subcommands below it that cobra documents.
+func countPages(cmd *cobra.Command) int {
+	count := 1
+	for _, child := range cmd.Commands() {
+		if child.IsAvailableCommand() && !child.IsAdditionalHelpTopicCommand() {
+			count += countPages(child)
+		}
+	}
+	return count
+}
+
+func logInfo(verbose bool, msg string) {
+	if verbose {
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
+	}
+}
+
+func logSuccess(msg string) {
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
@@ -0,0 +1,61 @@
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestRun(t *testing.T) {
+	root := &cobra.Command{Use: "gop", Short: "Root command"}
+	root.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose output")
+
+	parent := &cobra.Command{Use: "refactor", Short: "Apply refactorings"}
+	child := &cobra.Command{
+		Use:   "move-header",
+		Short: "Move a header",
+		Long:  ".starts with a dot\nand has a back\\slash",
+		RunE:  func(cmd *cobra.Command, args []string) error { return nil },
+	}
+	child.Flags().String("from", "", "Current path")
+	parent.AddCommand(child)
+	root.AddCommand(parent)
+
+	// Pages are dated from SOURCE_DATE_EPOCH, without cobra's footer dated
+	// from the current time
+	t.Setenv("SOURCE_DATE_EPOCH", "86400")
+	dir := t.TempDir()
+	if err := Run(Config{Root: root, OutputDir: dir, Formats: []string{"man", "markdown"}, Version: "v1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	man, err := os.ReadFile(filepath.Join(dir, "man", "man1", "gop-refactor-move-header.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`.TH "GOP-REFACTOR-MOVE-HEADER" "1" "Jan 1970" "gop v1.0.0" "gop Manual"`,
+		`\&.starts with a dot`,
+		`\fB--from\fP`,
+		`\fB-v\fP, \fB--verbose\fP`,
+		`\fBgop-refactor(1)\fP`,
+	} {
+		if !strings.Contains(string(man), want) {
+			t.Errorf("man page missing %q:\n%s", want, man)
+		}
+	}
+
+	markdown, err := os.ReadFile(filepath.Join(dir, "markdown", "gop_refactor.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(markdown), "* [gop refactor move-header](gop_refactor_move-header.md)") {
+		t.Errorf("markdown page does not link its subcommand:\n%s", markdown)
+	}
+	if strings.Contains(string(markdown), "Auto generated") {
+		t.Errorf("Expected no generation date in the markdown page:\n%s", markdown)
+	}
+}
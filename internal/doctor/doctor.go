@@ -0,0 +1,378 @@
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/registry"
+	"github.com/vitruves/gop/internal/utils"
+)
+
+type Config struct {
+	Root          string
+	Language      string
+	Exclude       []string
+	SkipVendor    bool
+	IncludeHidden bool
+	MaxFileSize   int64
+	JSON          bool
+}
+
+const (
+	StatusOK      = "ok"
+	StatusInfo    = "info"
+	StatusWarning = "warning"
+	StatusError   = "error"
+)
+
+type Check struct {
+	Category string `json:"category"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Message  string `json:"message"`
+	Fix      string `json:"fix,omitempty"`
+}
+
+type Report struct {
+	Checks   []Check `json:"checks"`
+	Warnings int     `json:"warnings"`
+	Errors   int     `json:"errors"`
+}
+
+// Files listed per finding before the rest is summarized as a count.
+const maxExamples = 5
+
+func Run(config Config) error {
+	if config.Root == "" {
+		config.Root = "."
+	}
+	if info, err := os.Stat(config.Root); err != nil || !info.IsDir() {
+		return fmt.Errorf("not a directory: %s", config.Root)
+	}
+
+	report := &Report{}
+	report.Checks = append(report.Checks, environmentChecks(config.Root)...)
+	report.Checks = append(report.Checks, projectChecks(config)...)
+	for _, check := range report.Checks {
+		switch check.Status {
+		case StatusWarning:
+			report.Warnings++
+		case StatusError:
+			report.Errors++
+		}
+	}
+
+	if config.JSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		printReport(report)
+	}
+
+	if report.Errors > 0 {
+		return fmt.Errorf("doctor found %d error(s)", report.Errors)
+	}
+	return nil
+}
+
+func environmentChecks(root string) []Check {
+	var checks []Check
+
+	if dot, err := exec.LookPath("dot"); err == nil {
+		checks = append(checks, Check{Category: "environment", Name: "graphviz", Status: StatusOK,
+			Message: "dot found at " + dot})
+	} else {
+		checks = append(checks, Check{Category: "environment", Name: "graphviz", Status: StatusWarning,
+			Message: "dot not found in PATH, class-graph --render writes DOT files instead of images",
+			Fix:     "Install Graphviz (https://graphviz.org/download/) and make sure dot is in PATH"})
+	}
+
+	if git, err := exec.LookPath("git"); err != nil {
+		checks = append(checks, Check{Category: "environment", Name: "git", Status: StatusWarning,
+			Message: "git not found in PATH, placeholder ages are unknown and compare only accepts directories and archives",
+			Fix:     "Install git and make sure it is in PATH"})
+	} else {
+		inside := exec.Command(git, "rev-parse", "--is-inside-work-tree")
+		inside.Dir = root
+		if out, err := inside.Output(); err != nil || strings.TrimSpace(string(out)) != "true" {
+			checks = append(checks, Check{Category: "environment", Name: "git", Status: StatusInfo,
+				Message: root + " is not inside a git work tree, placeholder ages and git refs for compare are unavailable"})
+		} else {
+			checks = append(checks, Check{Category: "environment", Name: "git", Status: StatusOK,
+				Message: "git found at " + git + ", " + root + " is a git work tree"})
+		}
+	}
+
+	return append(checks, terminalCheck())
+}
+
+func terminalCheck() Check {
+	check := Check{Category: "environment", Name: "terminal", Status: StatusInfo}
+
+	switch {
+	case os.Getenv("NO_COLOR") != "":
+		check.Message = "colors are off because NO_COLOR is set"
+	case os.Getenv("TERM") == "dumb":
+		check.Message = "colors are off because TERM=dumb"
+	case !isTerminal(os.Stdout):
+		check.Message = "colors are off because stdout is not a terminal"
+	case !color.Enabled():
+		check.Message = "colors are off because of --no-color"
+	default:
+		check.Status = StatusOK
+		check.Message = "stdout is a terminal with colors"
+	}
+
+	if isTerminal(os.Stderr) {
+		check.Message += "; progress is shown as a bar"
+	} else {
+		check.Message += "; stderr is not a terminal, progress is printed as plain lines"
+	}
+	return check
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// projectScan collects what a walk of the whole tree reveals about the
+// files the analyzers would read or silently skip.
+type projectScan struct {
+	config     Config
+	extensions map[string]bool
+
+	suspicious  []excludedDir
+	hidden      []string
+	unreadable  []string
+	binary      []string
+	tooLarge    []string
+	encodings   map[string][]string
+	sourceFiles int
+}
+
+type excludedDir struct {
+	path     string
+	sources  int
+	excluded string
+}
+
+func projectChecks(config Config) []Check {
+	scan := &projectScan{
+		config:     config,
+		extensions: make(map[string]bool),
+		encodings:  make(map[string][]string),
+	}
+	for _, ext := range registry.GetParser(config.Language).GetExtensions() {
+		scan.extensions[ext] = true
+	}
+
+	filepath.WalkDir(config.Root, scan.visit)
+
+	checks := []Check{{Category: "project", Name: "source files", Status: StatusOK,
+		Message: fmt.Sprintf("%s source files under %s", utils.FormatCount(scan.sourceFiles), config.Root)}}
+
+	for _, dir := range scan.suspicious {
+		checks = append(checks, Check{Category: "project", Name: "excluded directory", Status: StatusWarning,
+			Message: fmt.Sprintf("%s (%d source file(s)) is skipped because its path contains %q", dir.path, dir.sources, dir.excluded),
+			Fix:     fmt.Sprintf("Analyze it explicitly with -i '%s/*', or rename it", filepath.ToSlash(dir.path))})
+	}
+	if len(scan.hidden) > 0 {
+		checks = append(checks, Check{Category: "project", Name: "hidden directories", Status: StatusInfo,
+			Message: "skipped hidden directories contain source files: " + examples(scan.hidden),
+			Fix:     "Pass --include-hidden to analyze them"})
+	}
+
+	if len(scan.unreadable) > 0 {
+		checks = append(checks, Check{Category: "project", Name: "unreadable files", Status: StatusError,
+			Message: fmt.Sprintf("%d unreadable: %s", len(scan.unreadable), examples(scan.unreadable)),
+			Fix:     "Fix their permissions, or exclude them with -e"})
+	}
+	if len(scan.binary) > 0 {
+		checks = append(checks, Check{Category: "project", Name: "skipped files", Status: StatusInfo,
+			Message: fmt.Sprintf("%d binary or minified source files are always skipped: %s", len(scan.binary), examples(scan.binary))})
+	}
+	if len(scan.tooLarge) > 0 {
+		checks = append(checks, Check{Category: "project", Name: "large files", Status: StatusWarning,
+			Message: fmt.Sprintf("%d source files are larger than --max-file-size %s and skipped: %s",
+				len(scan.tooLarge), utils.HumanSize(config.MaxFileSize), examples(scan.tooLarge)),
+			Fix: "Raise --max-file-size, or pass --max-file-size 0 to analyze files of any size"})
+	}
+
+	return append(checks, scan.encodingChecks()...)
+}
+
+func (s *projectScan) visit(path string, entry fs.DirEntry, err error) error {
+	if err != nil {
+		s.unreadable = append(s.unreadable, path)
+		if entry != nil && entry.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	}
+
+	relPath, _ := filepath.Rel(s.config.Root, path)
+	if relPath == "." {
+		return nil
+	}
+
+	if entry.IsDir() {
+		return s.visitDir(path, relPath, entry.Name())
+	}
+
+	if !entry.Type().IsRegular() || !s.extensions[filepath.Ext(path)] || utils.MatchesAny(relPath, s.config.Exclude) {
+		return nil
+	}
+	s.sourceFiles++
+
+	if info, err := entry.Info(); err == nil && s.config.MaxFileSize > 0 && info.Size() > s.config.MaxFileSize {
+		s.tooLarge = append(s.tooLarge, path)
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		s.unreadable = append(s.unreadable, path)
+		return nil
+	}
+	if reason := utils.SniffContent(path); reason != "" {
+		s.binary = append(s.binary, path)
+		return nil
+	}
+
+	_, encoding := utils.DecodeText(data)
+	s.encodings[encoding] = append(s.encodings[encoding], path)
+	return nil
+}
+
+// visitDir skips the directories the analyzers skip, remembering the ones
+// that look skipped by accident.
+func (s *projectScan) visitDir(path, relPath, name string) error {
+	if name == ".git" {
+		return filepath.SkipDir
+	}
+
+	if !s.config.IncludeHidden && strings.HasPrefix(name, ".") {
+		if s.countSources(path) > 0 {
+			s.hidden = append(s.hidden, path)
+		}
+		return filepath.SkipDir
+	}
+
+	if utils.MatchesAny(relPath, s.config.Exclude) || (s.config.SkipVendor && utils.IsVendorDir(relPath)) {
+		return filepath.SkipDir
+	}
+
+	// node_modules, build, ... are meant to be skipped, but the default
+	// rule also catches names that merely contain them
+	if excluded := utils.DefaultExcludedDir(relPath); excluded != "" {
+		if name != excluded {
+			if count := s.countSources(path); count > 0 {
+				s.suspicious = append(s.suspicious, excludedDir{path: path, sources: count, excluded: excluded})
+			}
+		}
+		return filepath.SkipDir
+	}
+
+	return nil
+}
+
+func (s *projectScan) countSources(dir string) int {
+	count := 0
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() && s.extensions[filepath.Ext(path)] {
+			count++
+		}
+		return nil
+	})
+	return count
+}
+
+func (s *projectScan) encodingChecks() []Check {
+	var checks []Check
+
+	if unknown := s.encodings[utils.EncodingUnknown]; len(unknown) > 0 {
+		checks = append(checks, Check{Category: "project", Name: "unknown encoding", Status: StatusWarning,
+			Message: fmt.Sprintf("%d files are decoded as Latin-1 because their encoding cannot be determined: %s",
+				len(unknown), examples(unknown)),
+			Fix: "Convert them to UTF-8, e.g. iconv -f <encoding> -t utf-8"})
+	}
+
+	var names []string
+	for encoding := range s.encodings {
+		if encoding != utils.EncodingUnknown {
+			names = append(names, encoding)
+		}
+	}
+	if len(names) < 2 {
+		return checks
+	}
+
+	// On a tie UTF-8 is the encoding to convert to
+	sort.Slice(names, func(i, j int) bool {
+		if len(s.encodings[names[i]]) != len(s.encodings[names[j]]) {
+			return len(s.encodings[names[i]]) > len(s.encodings[names[j]])
+		}
+		if names[i] == utils.EncodingUTF8 || names[j] == utils.EncodingUTF8 {
+			return names[i] == utils.EncodingUTF8
+		}
+		return names[i] < names[j]
+	})
+	var parts []string
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%d %s", len(s.encodings[name]), name))
+	}
+	var others []string
+	for _, name := range names[1:] {
+		others = append(others, s.encodings[name]...)
+	}
+
+	return append(checks, Check{Category: "project", Name: "mixed encodings", Status: StatusWarning,
+		Message: fmt.Sprintf("source files use several encodings (%s); not %s: %s",
+			strings.Join(parts, ", "), names[0], examples(others)),
+		Fix: fmt.Sprintf("Convert them to %s so offsets and output are consistent, e.g. with iconv", names[0])})
+}
+
+func examples(paths []string) string {
+	if len(paths) <= maxExamples {
+		return strings.Join(paths, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(paths[:maxExamples], ", "), len(paths)-maxExamples)
+}
+
+var statusColors = map[string]string{
+	StatusOK:      color.Green,
+	StatusInfo:    color.Blue,
+	StatusWarning: color.Yellow,
+	StatusError:   color.Red,
+}
+
+func printReport(report *Report) {
+	category := ""
+	for _, check := range report.Checks {
+		if check.Category != category {
+			category = check.Category
+			fmt.Printf("\n%s\n", color.Wrap(color.BoldCyan, "=== "+strings.ToUpper(category)+" ==="))
+		}
+		status := fmt.Sprintf("%-9s", "["+check.Status+"]")
+		fmt.Printf("%s %s: %s\n", color.Wrap(statusColors[check.Status], status), check.Name, check.Message)
+		if check.Fix != "" {
+			fmt.Printf("%-9s -> %s\n", "", check.Fix)
+		}
+	}
+
+	fmt.Printf("\n%d warning(s), %d error(s)\n", report.Warnings, report.Errors)
+}
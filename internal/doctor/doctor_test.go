@@ -0,0 +1,64 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProjectChecks(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"src/a.c":           "int a;\n",
+		"src/b.c":           "int b; /* caf\xe9 */\n",
+		"distributed/c.c":   "int c;\n",
+		"build/d.c":         "int d;\n",
+		".tools/e.c":        "int e;\n",
+		"vendor/lib/f.c":    "int f;\n",
+		"src/notes.txt":     "not source\n",
+		"src/big/large.cpp": strings.Repeat("int x;\n", 200),
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	checks := projectChecks(Config{Root: tempDir, SkipVendor: true, MaxFileSize: 1024})
+	byName := make(map[string]Check)
+	for _, check := range checks {
+		byName[check.Name] = check
+	}
+
+	if check := byName["source files"]; !strings.HasPrefix(check.Message, "3 source files") {
+		t.Errorf("Unexpected source file count: %+v", check)
+	}
+
+	// build/ is skipped on purpose, distributed/ only because it contains "dist"
+	if check, ok := byName["excluded directory"]; !ok || !strings.Contains(check.Message, "distributed") || check.Status != StatusWarning {
+		t.Errorf("Expected a warning for distributed/, got %+v", checks)
+	}
+	for _, check := range checks {
+		if strings.Contains(check.Message, "build") || strings.Contains(check.Message, "vendor") {
+			t.Errorf("Deliberately skipped directory reported: %+v", check)
+		}
+	}
+
+	if check := byName["hidden directories"]; !strings.Contains(check.Message, ".tools") {
+		t.Errorf("Expected .tools to be reported, got %+v", check)
+	}
+	if check := byName["large files"]; !strings.Contains(check.Message, "large.cpp") {
+		t.Errorf("Expected large.cpp to be reported, got %+v", check)
+	}
+	if check := byName["mixed encodings"]; !strings.Contains(check.Message, "1 utf-8") || !strings.Contains(check.Message, "b.c") {
+		t.Errorf("Expected b.c to be reported as a mixed encoding, got %+v", check)
+	}
+	if _, ok := byName["unreadable files"]; ok {
+		t.Errorf("Unexpected unreadable files: %+v", byName["unreadable files"])
+	}
+}
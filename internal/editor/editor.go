@@ -0,0 +1,242 @@
+package editor
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/diagnostics"
+)
+
+type Config struct {
+	// Report is a checkstyle, codeclimate, json or sarif report of any command
+	Report string
+	// Index is the 1-based finding to open, 0 lists the findings and, on a
+	// terminal, asks which to open
+	Index int
+	// Editor is the command line of the editor, $VISUAL or $EDITOR when empty
+	Editor  string
+	Verbose bool
+}
+
+func Run(config Config) error {
+	data, err := os.ReadFile(config.Report)
+	if err != nil {
+		return err
+	}
+	findings, err := diagnostics.Parse(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.Report, err)
+	}
+	if len(findings) == 0 {
+		logSuccess(fmt.Sprintf("No findings in %s", config.Report))
+		return nil
+	}
+
+	editor := Editor(config.Editor)
+	reportDir := filepath.Dir(config.Report)
+
+	if config.Index != 0 {
+		if config.Index < 1 || config.Index > len(findings) {
+			return fmt.Errorf("finding %d does not exist, %s has %d", config.Index, config.Report, len(findings))
+		}
+		return open(editor, findings[config.Index-1], reportDir, config.Verbose)
+	}
+
+	if !isTerminal(os.Stdin) {
+		list(os.Stdout, findings, allIndexes(len(findings)))
+		return nil
+	}
+	return browse(os.Stdin, os.Stdout, findings, func(finding diagnostics.Diagnostic) error {
+		return open(editor, finding, reportDir, config.Verbose)
+	})
+}
+
+// Editor returns the editor command line: the given one, $VISUAL, $EDITOR,
+// VS Code when installed, or vi.
+func Editor(editor string) string {
+	for _, candidate := range []string{editor, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if strings.TrimSpace(candidate) != "" {
+			return candidate
+		}
+	}
+	if _, err := exec.LookPath("code"); err == nil {
+		return "code"
+	}
+	return "vi"
+}
+
+// Command returns the program and arguments opening file at line and column
+// in editor, using the jump syntax of the editors it knows and "+line file"
+// for the others.
+func Command(editor, file string, line, column int) (string, []string) {
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		fields = []string{"vi"}
+	}
+	program, args := fields[0], fields[1:]
+	line = max(line, 1)
+	column = max(column, 1)
+	position := fmt.Sprintf("%s:%d:%d", file, line, column)
+
+	name := strings.ToLower(strings.TrimSuffix(filepath.Base(program), ".exe"))
+	switch name {
+	case "code", "code-insiders", "codium", "vscodium", "cursor":
+		args = append(args, "-g", position)
+	case "subl", "sublime_text", "zed", "hx", "helix":
+		args = append(args, position)
+	case "idea", "idea64", "goland", "clion", "pycharm", "webstorm", "rider":
+		args = append(args, "--line", strconv.Itoa(line), "--column", strconv.Itoa(column), file)
+	case "emacs", "emacsclient":
+		args = append(args, fmt.Sprintf("+%d:%d", line, column), file)
+	default:
+		args = append(args, fmt.Sprintf("+%d", line), file)
+	}
+	return program, args
+}
+
+func open(editor string, finding diagnostics.Diagnostic, reportDir string, verbose bool) error {
+	file := resolve(finding.File, reportDir)
+	program, args := Command(editor, file, finding.Line, finding.Column)
+	logInfo(verbose, fmt.Sprintf("Running %s %s", program, strings.Join(args, " ")))
+
+	command := exec.Command(program, args...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	if err := command.Run(); err != nil {
+		return fmt.Errorf("%s: %w", program, err)
+	}
+	return nil
+}
+
+// resolve finds the file of a finding from the working directory, or from
+// the directory of the report when the report was written elsewhere.
+func resolve(file, reportDir string) string {
+	path := filepath.FromSlash(file)
+	if _, err := os.Stat(path); err == nil || filepath.IsAbs(path) {
+		return path
+	}
+	if candidate := filepath.Join(reportDir, path); fileExists(candidate) {
+		return candidate
+	}
+	logWarning(fmt.Sprintf("%s not found, run gop open from the directory the report was written in", file))
+	return path
+}
+
+// browse lists the findings and opens the ones picked by number until the
+// input ends or q is entered. "/text" narrows the list to the findings
+// mentioning text, "/" alone lists all of them again.
+func browse(in io.Reader, out io.Writer, findings []diagnostics.Diagnostic, openFinding func(diagnostics.Diagnostic) error) error {
+	shown := allIndexes(len(findings))
+	list(out, findings, shown)
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprintf(out, "Finding to open (number, /filter, q to quit): ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+		input := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case input == "":
+			continue
+		case input == "q" || input == "quit":
+			return nil
+		case strings.HasPrefix(input, "/"):
+			shown = matching(findings, strings.TrimPrefix(input, "/"))
+			list(out, findings, shown)
+		default:
+			index, err := strconv.Atoi(input)
+			if err != nil || index < 1 || index > len(findings) {
+				fmt.Fprintf(out, "Enter a number from 1 to %d\n", len(findings))
+				continue
+			}
+			if err := openFinding(findings[index-1]); err != nil {
+				logError(err.Error())
+			}
+		}
+	}
+}
+
+// list writes the findings at indexes, numbered from 1 as gop open N takes
+// them.
+func list(out io.Writer, findings []diagnostics.Diagnostic, indexes []int) {
+	width := len(strconv.Itoa(len(findings)))
+	for _, i := range indexes {
+		finding := findings[i]
+		location := fmt.Sprintf("%s:%d", finding.File, finding.Line)
+		if finding.Column > 0 {
+			location += fmt.Sprintf(":%d", finding.Column)
+		}
+		fmt.Fprintf(out, "%*d  %s: %s %s [%s]\n", width, i+1, location, finding.Severity, finding.Message, finding.Rule)
+	}
+	if len(indexes) == 0 {
+		fmt.Fprintln(out, "No findings match")
+	}
+}
+
+func matching(findings []diagnostics.Diagnostic, text string) []int {
+	text = strings.ToLower(text)
+	var indexes []int
+	for i, finding := range findings {
+		haystack := strings.ToLower(finding.File + " " + finding.Rule + " " + finding.Message + " " + finding.Function)
+		if strings.Contains(haystack, text) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+func allIndexes(n int) []int {
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func logInfo(verbose bool, msg string) {
+	if verbose {
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
+	}
+}
+
+func logSuccess(msg string) {
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
+}
+
+func logWarning(msg string) {
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
+}
+
+func logError(msg string) {
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
@@ -0,0 +1,51 @@
+package editor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/vitruves/gop/internal/diagnostics"
+)
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		editor string
+		want   string
+	}{
+		{"code --wait", "code --wait -g src/a.c:12:5"},
+		{"/usr/bin/nvim", "/usr/bin/nvim +12 src/a.c"},
+		{"emacsclient -t", "emacsclient -t +12:5 src/a.c"},
+		{"subl", "subl src/a.c:12:5"},
+		{"goland", "goland --line 12 --column 5 src/a.c"},
+	}
+	for _, tt := range tests {
+		program, args := Command(tt.editor, "src/a.c", 12, 5)
+		if got := strings.Join(append([]string{program}, args...), " "); got != tt.want {
+			t.Errorf("Command(%q) = %s, want %s", tt.editor, got, tt.want)
+		}
+	}
+}
+
+func TestBrowse(t *testing.T) {
+	findings := []diagnostics.Diagnostic{
+		{File: "a.c", Line: 1, Rule: "errors/unchecked_call", Message: "return value of malloc is ignored"},
+		{File: "b.c", Line: 7, Rule: "errors/empty_catch", Message: "empty catch block"},
+	}
+
+	var opened []string
+	var out bytes.Buffer
+	err := browse(strings.NewReader("/catch\n9\n2\nq\n1\n"), &out, findings, func(finding diagnostics.Diagnostic) error {
+		opened = append(opened, finding.File)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(opened, ",") != "b.c" {
+		t.Errorf("Expected only b.c to be opened before quitting, got %v", opened)
+	}
+	if !strings.Contains(out.String(), "2  b.c:7") || !strings.Contains(out.String(), "Enter a number from 1 to 2") {
+		t.Errorf("Unexpected listing:\n%s", out.String())
+	}
+}
@@ -0,0 +1,434 @@
+package enumcheck
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/registry"
+	"github.com/vitruves/gop/internal/report"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
+)
+
+type Config struct {
+	Language       string
+	Include        []string
+	Exclude        []string
+	Recursive      bool
+	Depth          int
+	Jobs           int
+	Verbose        bool
+	OutputFile     string
+	Format         string
+	Template       string
+	SkipGenerated  bool
+	SkipVendor     bool
+	MaxFileSize    int64
+	FollowSymlinks bool
+	IncludeHidden  bool
+	NoProgress     bool
+	Manifest       *manifest.Manifest
+}
+
+// Enum is an enum type with its enumerators in declaration order. Name is
+// qualified with the enclosing namespaces and classes.
+type Enum struct {
+	Name        string
+	File        string
+	Line        int
+	Enumerators []string
+}
+
+const Rule = "correctness/incomplete_switch"
+
+func Run(config Config) error {
+	logInfo(config.Verbose, "Starting enum switch check")
+
+	if config.Language == "" {
+		config.Language = "cpp"
+	}
+	if config.Language != "c" && config.Language != "cpp" {
+		return fmt.Errorf("enum check is not supported for language: %s (expected c or cpp)", config.Language)
+	}
+	if config.Format == "" {
+		config.Format = "text"
+	}
+	if config.Format != "text" && config.Template == "" && !diagnostics.IsFormat(config.Format) {
+		return fmt.Errorf("unsupported format: %s (expected text, %s)", config.Format, strings.Join(diagnostics.Formats, ", "))
+	}
+
+	parser := registry.GetParser(config.Language)
+	memberParser := parser.(registry.MemberParser)
+
+	extensions := parser.GetExtensions()
+	opts := utils.ScanOptions{
+		Include:        config.Include,
+		Exclude:        config.Exclude,
+		Recursive:      config.Recursive,
+		Depth:          config.Depth,
+		SkipGenerated:  config.SkipGenerated,
+		SkipVendor:     config.SkipVendor,
+		MaxFileSize:    config.MaxFileSize,
+		FollowSymlinks: config.FollowSymlinks,
+		IncludeHidden:  config.IncludeHidden,
+		Verbose:        config.Verbose,
+	}
+
+	files, err := utils.GetFilesToProcess(opts, func(path string) bool {
+		ext := filepath.Ext(path)
+		for _, validExt := range extensions {
+			if ext == validExt {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		logError(fmt.Sprintf("Failed to collect files: %v", err))
+		return err
+	}
+
+	if len(files) == 0 {
+		logWarning("No files found matching criteria")
+		return nil
+	}
+
+	logInfo(config.Verbose, fmt.Sprintf("Found %d files to analyze", len(files)))
+	config.Manifest.AddFiles(files)
+
+	// Enums are usually declared in headers and switched over elsewhere, so
+	// every file is parsed before any switch is checked
+	parsed := worker.Run(files, config.Jobs, progress.New("Collecting enums", len(files), config.NoProgress), func(ctx context.Context, idx int, filePath string) []registry.Member {
+		members, err := memberParser.ParseMembers(filePath)
+		if err != nil {
+			failures.Record(filePath, err)
+			return nil
+		}
+		return members
+	})
+
+	var members []registry.Member
+	for _, fileMembers := range parsed {
+		members = append(members, fileMembers...)
+	}
+
+	enums := CollectEnums(members)
+	logInfo(config.Verbose, fmt.Sprintf("Found %d enums", len(enums)))
+
+	var results [][]diagnostics.Diagnostic
+	if len(enums) > 0 {
+		results = worker.Run(files, config.Jobs, progress.New("Checking switches", len(files), config.NoProgress), func(ctx context.Context, idx int, filePath string) []diagnostics.Diagnostic {
+			content, err := utils.ReadSourceFile(filePath)
+			if err != nil {
+				failures.Record(filePath, err)
+				return nil
+			}
+			return CheckSwitches(filePath, content, enums)
+		})
+	}
+
+	var findings []diagnostics.Diagnostic
+	for _, fileFindings := range results {
+		findings = append(findings, fileFindings...)
+	}
+
+	return writeOutput(findings, config)
+}
+
+// CollectEnums groups enumerator members by the enum that declares them.
+// Enums without enumerators, such as forward declarations, are left out.
+func CollectEnums(members []registry.Member) []Enum {
+	byName := make(map[string]*Enum)
+	var names []string
+
+	for _, member := range members {
+		if member.Kind != "enumerator" {
+			continue
+		}
+		enum, ok := byName[member.Scope]
+		if !ok {
+			enum = &Enum{Name: member.Scope, File: member.File, Line: member.Line}
+			byName[member.Scope] = enum
+			names = append(names, member.Scope)
+		}
+		enum.Enumerators = append(enum.Enumerators, member.Name)
+	}
+
+	sort.Strings(names)
+	enums := make([]Enum, 0, len(names))
+	for _, name := range names {
+		enums = append(enums, *byName[name])
+	}
+	return enums
+}
+
+var (
+	switchRegex  = regexp.MustCompile(`\bswitch\s*\(`)
+	defaultRegex = regexp.MustCompile(`^default\s*:`)
+	caseRegex    = regexp.MustCompile(`^case\b`)
+	labelRegex   = regexp.MustCompile(`^(?:((?:[A-Za-z_]\w*::)*[A-Za-z_]\w*)::)?([A-Za-z_]\w*)$`)
+)
+
+// CheckSwitches reports switch statements whose case labels name
+// enumerators of a single enum, leave some of its enumerators out and have no
+// default case.
+func CheckSwitches(filePath, content string, enums []Enum) []diagnostics.Diagnostic {
+	code := utils.BlankCommentsAndLiterals(content)
+	lines := utils.NewLineIndex(code)
+	sourceLines := utils.SplitLines(content)
+
+	var findings []diagnostics.Diagnostic
+	for _, loc := range switchRegex.FindAllStringIndex(code, -1) {
+		body, ok := switchBody(code, loc[1]-1)
+		if !ok {
+			continue
+		}
+
+		labels, hasDefault := switchLabels(body)
+		if hasDefault || len(labels) == 0 {
+			continue
+		}
+
+		enum, ok := matchEnum(labels, enums)
+		if !ok {
+			continue
+		}
+
+		handled := make(map[string]bool)
+		for _, label := range labels {
+			handled[label.name] = true
+		}
+		var missing []string
+		for _, enumerator := range enum.Enumerators {
+			if !handled[enumerator] {
+				missing = append(missing, enumerator)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+
+		line, column := lines.Position(loc[0])
+		findings = append(findings, diagnostics.Diagnostic{
+			File:     filePath,
+			Line:     line,
+			Column:   column,
+			Severity: diagnostics.SeverityWarning,
+			Rule:     Rule,
+			Message:  fmt.Sprintf("switch over %s does not handle %s and has no default case", enum.Name, strings.Join(missing, ", ")),
+			Excerpt:  sourceLines[line-1],
+		})
+	}
+
+	return findings
+}
+
+type caseLabel struct {
+	qualifier string
+	name      string
+}
+
+// switchBody returns the braced body of the switch whose condition opens at
+// paren.
+func switchBody(code string, paren int) (string, bool) {
+	end := utils.MatchingBracket(code, paren, '(', ')')
+	if end == -1 {
+		return "", false
+	}
+
+	open := end + 1
+	for open < len(code) && strings.ContainsRune(" \t\r\n", rune(code[open])) {
+		open++
+	}
+	if open >= len(code) || code[open] != '{' {
+		return "", false
+	}
+
+	close := utils.MatchingBracket(code, open, '{', '}')
+	if close == -1 {
+		return "", false
+	}
+	return code[open+1 : close], true
+}
+
+// switchLabels collects the case labels directly in a switch body. Labels of
+// nested switches sit in deeper braces and are skipped.
+func switchLabels(body string) ([]caseLabel, bool) {
+	var labels []caseLabel
+	hasDefault := false
+	depth := 0
+
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '{', '(':
+			depth++
+			continue
+		case '}', ')':
+			depth--
+			continue
+		}
+		if depth != 0 || (i > 0 && isIdentChar(body[i-1])) {
+			continue
+		}
+
+		rest := body[i:]
+		if defaultRegex.MatchString(rest) {
+			hasDefault = true
+			continue
+		}
+		if !caseRegex.MatchString(rest) {
+			continue
+		}
+
+		expr, ok := labelExpression(rest[len("case"):])
+		if !ok {
+			continue
+		}
+		match := labelRegex.FindStringSubmatch(expr)
+		if match == nil {
+			// Integer or character labels, the switch is not over an enum
+			return nil, hasDefault
+		}
+		labels = append(labels, caseLabel{qualifier: match[1], name: match[2]})
+	}
+
+	return labels, hasDefault
+}
+
+// labelExpression returns the text of a case label up to its colon, skipping
+// the scope operator.
+func labelExpression(text string) (string, bool) {
+	for i := 0; i < len(text); i++ {
+		if text[i] != ':' {
+			continue
+		}
+		if i+1 < len(text) && text[i+1] == ':' {
+			i++
+			continue
+		}
+		return strings.Join(strings.Fields(text[:i]), ""), true
+	}
+	return "", false
+}
+
+// matchEnum finds the only enum declaring every label. A qualified label
+// must also name the enum, or a scope the enum is declared in.
+func matchEnum(labels []caseLabel, enums []Enum) (Enum, bool) {
+	var candidates []Enum
+
+	for _, enum := range enums {
+		declared := make(map[string]bool, len(enum.Enumerators))
+		for _, enumerator := range enum.Enumerators {
+			declared[enumerator] = true
+		}
+
+		matches := true
+		for _, label := range labels {
+			if !declared[label.name] || !qualifies(label.qualifier, enum.Name) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			candidates = append(candidates, enum)
+		}
+	}
+
+	if len(candidates) != 1 {
+		return Enum{}, false
+	}
+	return candidates[0], true
+}
+
+func qualifies(qualifier, enum string) bool {
+	if qualifier == "" {
+		return true
+	}
+	scope := "::" + enum
+	return strings.HasSuffix(scope, "::"+qualifier) || strings.Contains(scope+"::", "::"+qualifier+"::")
+}
+
+func isIdentChar(char byte) bool {
+	return char == '_' || char >= '0' && char <= '9' || char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z'
+}
+
+func writeOutput(findings []diagnostics.Diagnostic, config Config) error {
+	findings = diagnostics.DisplayPaths(diagnostics.Filtered(findings))
+	var output []byte
+
+	if config.Template != "" {
+		var err error
+		output, err = report.Render(config.Template, struct{ Diagnostics []diagnostics.Diagnostic }{findings})
+		if err != nil {
+			return err
+		}
+	} else if config.Format == "text" {
+		if len(findings) == 0 {
+			logSuccess("No incomplete switches found")
+			return nil
+		}
+
+		var sb strings.Builder
+		for _, finding := range findings {
+			sb.WriteString(fmt.Sprintf("%s:%d:%d: %s\n", finding.File, finding.Line, finding.Column, finding.Message))
+		}
+		output = []byte(sb.String())
+	} else {
+		var buf bytes.Buffer
+		if err := diagnostics.WriteReport(&buf, config.Format, findings); err != nil {
+			return err
+		}
+		output = buf.Bytes()
+	}
+
+	if config.OutputFile != "" {
+		config.Manifest.AddResult(config.OutputFile, output)
+		if err := os.WriteFile(config.OutputFile, output, 0644); err != nil {
+			logError(fmt.Sprintf("Failed to write report: %v", err))
+			return err
+		}
+	} else {
+		config.Manifest.AddResult("stdout", output)
+		fmt.Print(string(output))
+	}
+
+	if config.Format == "text" && config.Template == "" {
+		logWarning(fmt.Sprintf("Found %d incomplete switches", len(findings)))
+	}
+	return nil
+}
+
+func logInfo(verbose bool, msg string) {
+	if verbose {
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
+	}
+}
+
+func logSuccess(msg string) {
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
+}
+
+func logWarning(msg string) {
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
+}
+
+func logError(msg string) {
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
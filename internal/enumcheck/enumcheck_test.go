@@ -0,0 +1,87 @@
+package enumcheck
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vitruves/gop/internal/registry"
+)
+
+func TestCheckSwitches(t *testing.T) {
+	tempDir := t.TempDir()
+	header := filepath.Join(tempDir, "color.hpp")
+	headerContent := `
+namespace ui {
+enum class Color { RED, GREEN, BLUE };
+}
+
+enum State {
+    IDLE = 0, // waiting for input
+    RUNNING,
+    DONE
+};
+`
+	if err := os.WriteFile(header, []byte(headerContent), 0644); err != nil {
+		t.Fatalf("Failed to create header: %v", err)
+	}
+
+	members, err := (&registry.CppParser{}).ParseMembers(header)
+	if err != nil {
+		t.Fatalf("Failed to parse members: %v", err)
+	}
+	enums := CollectEnums(members)
+	if len(enums) != 2 {
+		t.Fatalf("Expected 2 enums, got %+v", enums)
+	}
+	if enums[1].Name != "ui::Color" || strings.Join(enums[1].Enumerators, ",") != "RED,GREEN,BLUE" {
+		t.Errorf("Unexpected enum: %+v", enums[1])
+	}
+
+	source := `
+const char *name(ui::Color color) {
+    switch (color) {
+    case ui::Color::RED: return "red";
+    case ui::Color::GREEN: return "green";
+    }
+    return "?";
+}
+
+void step(State state) {
+    switch (state) {
+    case IDLE:
+        break;
+    default:
+        break;
+    }
+    switch (state) {
+    case IDLE: {
+        switch (code) { case 1: break; }
+        break;
+    }
+    // case DONE: handled by the caller
+    case RUNNING: break;
+    }
+}
+
+int parse(char c) {
+    switch (c) {
+    case 'a': return 1;
+    }
+    return 0;
+}
+`
+
+	findings := CheckSwitches("main.cpp", source, enums)
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %d: %+v", len(findings), findings)
+	}
+
+	if findings[0].Line != 3 || findings[0].Rule != Rule || !strings.Contains(findings[0].Message, "ui::Color does not handle BLUE") {
+		t.Errorf("Unexpected finding: %+v", findings[0])
+	}
+	if findings[1].Line != 17 || !strings.Contains(findings[1].Message, "State does not handle DONE") {
+		t.Errorf("Unexpected finding: %+v", findings[1])
+	}
+}
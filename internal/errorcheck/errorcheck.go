@@ -0,0 +1,616 @@
+package errorcheck
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/registry"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
+)
+
+type Config struct {
+	Language       string
+	Include        []string
+	Exclude        []string
+	Recursive      bool
+	Depth          int
+	Jobs           int
+	Verbose        bool
+	Functions      []string
+	FunctionsFile  string
+	OutputFile     string
+	Format         string
+	SkipGenerated  bool
+	SkipVendor     bool
+	MaxFileSize    int64
+	FollowSymlinks bool
+	IncludeHidden  bool
+	NoProgress     bool
+	Manifest       *manifest.Manifest
+}
+
+const (
+	RuleUncheckedCall      = "errors/unchecked_call"
+	RuleUncheckedErrorCode = "errors/unchecked_error_code"
+	RuleEmptyCatch         = "errors/empty_catch"
+)
+
+// DefaultFunctions are the library calls whose result must be checked. Names
+// may be glob patterns.
+var DefaultFunctions = []string{
+	"malloc", "calloc", "realloc", "aligned_alloc", "strdup", "strndup",
+	"fopen", "fdopen", "freopen", "tmpfile", "open", "socket", "mmap",
+	"pthread_*",
+}
+
+// pthread functions that return nothing or cannot fail.
+var neverFail = map[string]bool{
+	"pthread_exit":         true,
+	"pthread_self":         true,
+	"pthread_equal":        true,
+	"pthread_testcancel":   true,
+	"pthread_cleanup_push": true,
+	"pthread_cleanup_pop":  true,
+}
+
+// Definition is a function defined in the scanned code.
+type Definition struct {
+	Name       string
+	File       string
+	Line       int
+	ReturnType string
+	// ReturnsErrorCode is set when the function reports failure through its
+	// return value, e.g. int returning -1 or a status type
+	ReturnsErrorCode bool
+	// Body is the code between the braces, with comments, literals and
+	// preprocessor directives blanked
+	Body string
+}
+
+// Call is a call site. Ignored is set when the call is a statement of its
+// own, so its result is dropped; (void) casts count as checked.
+type Call struct {
+	Name     string
+	File     string
+	Line     int
+	Column   int
+	Function string
+	Ignored  bool
+	Excerpt  string
+}
+
+// FileResult holds what Scan finds in one file.
+type FileResult struct {
+	Definitions []Definition
+	Calls       []Call
+	EmptyCatch  []diagnostics.Diagnostic
+}
+
+var (
+	callRegex        = regexp.MustCompile(`\b([A-Za-z_]\w*(?:::[A-Za-z_~]\w*)*)\s*\(`)
+	preprocessorRe   = regexp.MustCompile(`(?m)^[ \t]*#(?:.*\\\r?\n)*.*$`)
+	accessRegex      = regexp.MustCompile(`^.*\b(?:public|private|protected)\s*:([^:]|$)`)
+	returnTypeRegex  = regexp.MustCompile(`^[\w\s\*&:<>,~\[\]]*$`)
+	trailingRegex    = regexp.MustCompile(`^\s*(?:(?:const|noexcept|override|final|volatile)\b|&&|&)`)
+	errorTypeRegex   = regexp.MustCompile(`(?i)(?:^|_)(?:err|error|status|result|rc)(?:_t)?$|^(?:errno_t|error_t|HRESULT|NTSTATUS)$`)
+	intTypeRegex     = regexp.MustCompile(`^(?:int|long|ssize_t|int32_t|int64_t)$`)
+	errorReturnRegex = regexp.MustCompile(`\breturn\s*\(?\s*(?:-\s*\w+|\w*(?:ERR|FAIL)\w*)`)
+	typeQualifiers   = regexp.MustCompile(`\b(?:static|inline|extern|const|constexpr|virtual|explicit|unsigned|signed)\b|\[\[.*?\]\]`)
+	catchRegex       = regexp.MustCompile(`\bcatch\s*\(`)
+)
+
+// Words followed by a parenthesis that are not function calls.
+var keywords = map[string]bool{
+	"if": true, "while": true, "for": true, "switch": true, "return": true, "sizeof": true,
+	"alignof": true, "decltype": true, "catch": true, "defined": true, "typeof": true,
+	"static_assert": true, "_Static_assert": true, "new": true, "delete": true, "throw": true,
+	"case": true, "do": true, "else": true, "__attribute__": true, "_Generic": true,
+	"offsetof": true, "noexcept": true, "alignas": true, "operator": true,
+}
+
+func Run(config Config) error {
+	logInfo(config.Verbose, "Starting error handling check")
+
+	if config.Language == "" {
+		config.Language = "cpp"
+	}
+	if config.Language != "c" && config.Language != "cpp" {
+		return fmt.Errorf("error check is not supported for language: %s (expected c or cpp)", config.Language)
+	}
+	if config.Format == "" {
+		config.Format = "text"
+	}
+	if config.Format != "text" && !diagnostics.IsFormat(config.Format) {
+		return fmt.Errorf("unsupported format: %s (expected text, %s)", config.Format, strings.Join(diagnostics.Formats, ", "))
+	}
+
+	functions := append(append([]string{}, DefaultFunctions...), config.Functions...)
+	if config.FunctionsFile != "" {
+		names, err := LoadFunctions(config.FunctionsFile)
+		if err != nil {
+			return err
+		}
+		functions = append(functions, names...)
+	}
+
+	extensions := registry.GetParser(config.Language).GetExtensions()
+	opts := utils.ScanOptions{
+		Include:        config.Include,
+		Exclude:        config.Exclude,
+		Recursive:      config.Recursive,
+		Depth:          config.Depth,
+		SkipGenerated:  config.SkipGenerated,
+		SkipVendor:     config.SkipVendor,
+		MaxFileSize:    config.MaxFileSize,
+		FollowSymlinks: config.FollowSymlinks,
+		IncludeHidden:  config.IncludeHidden,
+		Verbose:        config.Verbose,
+	}
+
+	files, err := utils.GetFilesToProcess(opts, func(path string) bool {
+		ext := filepath.Ext(path)
+		for _, validExt := range extensions {
+			if ext == validExt {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		logError(fmt.Sprintf("Failed to collect files: %v", err))
+		return err
+	}
+
+	if len(files) == 0 {
+		logWarning("No files found matching criteria")
+		return nil
+	}
+
+	logInfo(config.Verbose, fmt.Sprintf("Found %d files to analyze", len(files)))
+	config.Manifest.AddFiles(files)
+
+	results := worker.Run(files, config.Jobs, progress.New("Checking error handling", len(files), config.NoProgress), func(ctx context.Context, idx int, filePath string) FileResult {
+		content, err := utils.ReadSourceFile(filePath)
+		if err != nil {
+			failures.Record(filePath, err)
+			return FileResult{}
+		}
+		return Scan(filePath, content)
+	})
+
+	return writeOutput(Check(results, functions), config)
+}
+
+// LoadFunctions reads a list of function names or glob patterns, one per
+// line, with # comments.
+func LoadFunctions(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.SplitN(scanner.Text(), "#", 2)[0])
+		if line != "" {
+			names = append(names, line)
+		}
+	}
+	return names, scanner.Err()
+}
+
+// Scan collects the function definitions and call sites of a C/C++ file, and
+// the catch blocks that are empty. A catch block holding only a comment is
+// taken as a deliberate choice and not reported.
+func Scan(filePath, content string) FileResult {
+	// Directives are blanked too, so macro bodies are not taken for calls
+	blanked := []byte(utils.BlankCommentsAndLiterals(content))
+	for _, loc := range preprocessorRe.FindAllIndex(blanked, -1) {
+		for i := loc[0]; i < loc[1]; i++ {
+			if blanked[i] != '\n' {
+				blanked[i] = ' '
+			}
+		}
+	}
+	code := string(blanked)
+	lines := utils.NewLineIndex(code)
+	sourceLines := utils.SplitLines(content)
+
+	var result FileResult
+	type extent struct {
+		name       string
+		start, end int
+	}
+	var bodies []extent
+	enclosing := func(offset int) string {
+		for i := len(bodies) - 1; i >= 0; i-- {
+			if offset > bodies[i].start && offset < bodies[i].end {
+				return bodies[i].name
+			}
+		}
+		return ""
+	}
+
+	for _, loc := range callRegex.FindAllStringSubmatchIndex(code, -1) {
+		name := code[loc[2]:loc[3]]
+		if keywords[name] {
+			continue
+		}
+		if prev := previousChar(code, loc[2]); prev == '.' || prev == '>' && loc[2] > 1 && code[lastNonSpace(code, loc[2])-1] == '-' {
+			continue
+		}
+
+		closeParen := utils.MatchingBracket(code, loc[3]-1, '(', ')')
+		if closeParen < 0 {
+			continue
+		}
+
+		if open := bodyAfter(code, closeParen+1); open >= 0 {
+			returnType, ok := declarationPrefix(code, loc[2])
+			if !ok {
+				continue
+			}
+			end := utils.MatchingBracket(code, open, '{', '}')
+			if end < 0 {
+				continue
+			}
+			bodies = append(bodies, extent{name: name, start: open, end: end})
+			line, _ := lines.Position(loc[2])
+			result.Definitions = append(result.Definitions, Definition{
+				Name:             name,
+				File:             filePath,
+				Line:             line,
+				ReturnType:       returnType,
+				ReturnsErrorCode: returnsErrorCode(returnType, code[open:end]),
+				Body:             code[open+1 : end],
+			})
+			continue
+		}
+
+		line, column := lines.Position(loc[2])
+		result.Calls = append(result.Calls, Call{
+			Name:     unqualified(name),
+			File:     filePath,
+			Line:     line,
+			Column:   column,
+			Function: enclosing(loc[2]),
+			Ignored:  statementStart(code, loc[2]) && nextChar(code, closeParen+1) == ';',
+			Excerpt:  strings.TrimSpace(sourceLines[line-1]),
+		})
+	}
+
+	for _, loc := range catchRegex.FindAllStringIndex(code, -1) {
+		closeParen := utils.MatchingBracket(code, loc[1]-1, '(', ')')
+		if closeParen < 0 {
+			continue
+		}
+		open := closeParen + 1
+		for open < len(code) && isSpace(code[open]) {
+			open++
+		}
+		if open >= len(code) || code[open] != '{' {
+			continue
+		}
+		end := utils.MatchingBracket(code, open, '{', '}')
+		if end < 0 || strings.TrimSpace(content[open+1:end]) != "" {
+			continue
+		}
+
+		line, column := lines.Position(loc[0])
+		result.EmptyCatch = append(result.EmptyCatch, diagnostics.Diagnostic{
+			File:     filePath,
+			Line:     line,
+			Column:   column,
+			Severity: diagnostics.SeverityWarning,
+			Rule:     RuleEmptyCatch,
+			Message:  "empty catch block swallows the exception",
+			Function: enclosing(loc[0]),
+			Excerpt:  strings.TrimSpace(sourceLines[line-1]),
+		})
+	}
+
+	return result
+}
+
+// Check reports ignored results of the listed functions at each call site,
+// and project functions returning an error code that no call site checks.
+func Check(results []FileResult, functions []string) []diagnostics.Diagnostic {
+	var findings []diagnostics.Diagnostic
+	callsByName := make(map[string][]Call)
+
+	for _, result := range results {
+		for _, call := range result.Calls {
+			callsByName[call.Name] = append(callsByName[call.Name], call)
+			if call.Ignored && listed(call.Name, functions) {
+				findings = append(findings, diagnostics.Diagnostic{
+					File:     call.File,
+					Line:     call.Line,
+					Column:   call.Column,
+					Severity: diagnostics.SeverityWarning,
+					Rule:     RuleUncheckedCall,
+					Message:  fmt.Sprintf("return value of %s is ignored", call.Name),
+					Function: call.Function,
+					Excerpt:  call.Excerpt,
+				})
+			}
+		}
+		findings = append(findings, result.EmptyCatch...)
+	}
+
+	reported := make(map[string]bool)
+	for _, result := range results {
+		for _, def := range result.Definitions {
+			name := unqualified(def.Name)
+			calls := callsByName[name]
+			if !def.ReturnsErrorCode || reported[name] || len(calls) == 0 || listed(name, functions) {
+				continue
+			}
+
+			checked := false
+			for _, call := range calls {
+				if !call.Ignored {
+					checked = true
+					break
+				}
+			}
+			if checked {
+				continue
+			}
+
+			reported[name] = true
+			findings = append(findings, diagnostics.Diagnostic{
+				File:     def.File,
+				Line:     def.Line,
+				Column:   1,
+				Severity: diagnostics.SeverityWarning,
+				Rule:     RuleUncheckedErrorCode,
+				Message:  fmt.Sprintf("%s returns an error code (%s) that none of its %d call sites check", def.Name, def.ReturnType, len(calls)),
+				Function: def.Name,
+			})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+func listed(name string, functions []string) bool {
+	if neverFail[name] {
+		return false
+	}
+	for _, pattern := range functions {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// returnsErrorCode tells whether a function signals failure through its
+// result: a status type, or an int-like type with a negative or *ERR*/*FAIL*
+// return in its body.
+func returnsErrorCode(returnType, body string) bool {
+	fields := strings.Fields(typeQualifiers.ReplaceAllString(returnType, " "))
+	if len(fields) == 0 || strings.ContainsAny(fields[len(fields)-1], "*&") {
+		return false
+	}
+	base := fields[len(fields)-1]
+	if errorTypeRegex.MatchString(base) {
+		return true
+	}
+	return intTypeRegex.MatchString(base) && errorReturnRegex.MatchString(body)
+}
+
+// declarationPrefix returns the return type in front of a function name
+// that starts a definition, and false when the name is preceded by anything
+// but a type, e.g. a macro invocation followed by a block.
+func declarationPrefix(code string, nameStart int) (string, bool) {
+	start := strings.LastIndexAny(code[:nameStart], ";{}") + 1
+	prefix := strings.TrimSpace(code[start:nameStart])
+	prefix = strings.TrimSpace(accessRegex.ReplaceAllString(prefix, "$1"))
+	if !returnTypeRegex.MatchString(prefix) {
+		return "", false
+	}
+	if prefix == "" && !strings.Contains(code[nameStart:strings.IndexByte(code[nameStart:], '(')+nameStart], "::") {
+		return "", false
+	}
+	return prefix, true
+}
+
+// bodyAfter returns the offset of the brace opening a function body after a
+// parameter list, skipping qualifiers and trailing return types, or -1.
+func bodyAfter(code string, offset int) int {
+	for {
+		rest := code[offset:]
+		loc := trailingRegex.FindStringIndex(rest)
+		if loc == nil || loc[1] == 0 {
+			break
+		}
+		offset += loc[1]
+	}
+	for offset < len(code) && isSpace(code[offset]) {
+		offset++
+	}
+	if offset+1 < len(code) && code[offset] == '-' && code[offset+1] == '>' {
+		brace := strings.IndexAny(code[offset:], "{;")
+		if brace < 0 || code[offset+brace] != '{' {
+			return -1
+		}
+		return offset + brace
+	}
+	if offset < len(code) && code[offset] == '{' {
+		return offset
+	}
+	return -1
+}
+
+// statementStart tells whether a call is the start of a statement: after a
+// semicolon, a brace, a label, else/do, or the condition of if/while/for.
+func statementStart(code string, nameStart int) bool {
+	i := lastNonSpace(code, nameStart)
+	if i < 0 {
+		return true
+	}
+
+	switch code[i] {
+	case ';', '{', '}':
+		return true
+	case ':':
+		return i == 0 || code[i-1] != ':'
+	case ')':
+		open := matchingOpen(code, i)
+		if open < 0 {
+			return false
+		}
+		if strings.TrimSpace(code[open+1:i]) == "void" {
+			return false
+		}
+		word := previousWord(code, open)
+		return word == "if" || word == "while" || word == "for"
+	}
+
+	word := previousWord(code, nameStart)
+	return word == "else" || word == "do"
+}
+
+func matchingOpen(code string, close int) int {
+	depth := 0
+	for i := close; i >= 0; i-- {
+		switch code[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func previousWord(code string, offset int) string {
+	end := lastNonSpace(code, offset) + 1
+	start := end
+	for start > 0 && isIdentChar(code[start-1]) {
+		start--
+	}
+	return code[start:end]
+}
+
+func lastNonSpace(code string, offset int) int {
+	i := offset - 1
+	for i >= 0 && isSpace(code[i]) {
+		i--
+	}
+	return i
+}
+
+func previousChar(code string, offset int) byte {
+	if i := lastNonSpace(code, offset); i >= 0 {
+		return code[i]
+	}
+	return 0
+}
+
+func nextChar(code string, offset int) byte {
+	for ; offset < len(code); offset++ {
+		if !isSpace(code[offset]) {
+			return code[offset]
+		}
+	}
+	return 0
+}
+
+func unqualified(name string) string {
+	if i := strings.LastIndex(name, "::"); i >= 0 {
+		return name[i+2:]
+	}
+	return name
+}
+
+func isSpace(char byte) bool {
+	return char == ' ' || char == '\t' || char == '\n' || char == '\r'
+}
+
+func isIdentChar(char byte) bool {
+	return char == '_' || char >= '0' && char <= '9' || char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z'
+}
+
+func writeOutput(findings []diagnostics.Diagnostic, config Config) error {
+	findings = diagnostics.DisplayPaths(diagnostics.Filtered(findings))
+	var sb strings.Builder
+
+	if config.Format == "text" {
+		if len(findings) == 0 {
+			logSuccess("No error handling issues found")
+			return nil
+		}
+
+		for _, finding := range findings {
+			sb.WriteString(fmt.Sprintf("%s:%d:%d: %s\n", finding.File, finding.Line, finding.Column, finding.Message))
+		}
+	} else if err := diagnostics.WriteReport(&sb, config.Format, findings); err != nil {
+		return err
+	}
+	output := []byte(sb.String())
+
+	if config.OutputFile != "" {
+		config.Manifest.AddResult(config.OutputFile, output)
+		if err := os.WriteFile(config.OutputFile, output, 0644); err != nil {
+			logError(fmt.Sprintf("Failed to write report: %v", err))
+			return err
+		}
+	} else {
+		config.Manifest.AddResult("stdout", output)
+		fmt.Print(string(output))
+	}
+
+	if config.Format == "text" {
+		logWarning(fmt.Sprintf("Found %d error handling issues", len(findings)))
+	}
+	return nil
+}
+
+func logInfo(verbose bool, msg string) {
+	if verbose {
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
+	}
+}
+
+func logSuccess(msg string) {
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
+}
+
+func logWarning(msg string) {
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
+}
+
+func logError(msg string) {
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
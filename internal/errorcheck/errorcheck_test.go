@@ -0,0 +1,82 @@
+package errorcheck
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	source := `#include <stdlib.h>
+#define ALLOC(n) malloc(n)
+
+static int load_config(const char *path)
+{
+    FILE *f = fopen(path, "r");
+    if (!f)
+        return -1;
+    fclose(f);
+    return 0;
+}
+
+int parse(const char *s) {
+    return s[0] == 'x';
+}
+
+status_t flush(void) {
+    return STATUS_OK;
+}
+
+void run(void) {
+    char *buf;
+    load_config("a");
+    if (ready) load_config("b");
+    (void)flush();
+    malloc(16);
+    buf = malloc(16);
+    pthread_mutex_lock(&lock);
+    pthread_exit(NULL);
+    parse("x");
+    my_alloc(4);
+    // malloc(1);
+}
+`
+	result := Scan("main.c", source)
+	findings := Check([]FileResult{result}, append(DefaultFunctions, "my_*"))
+
+	var got []string
+	for _, finding := range findings {
+		got = append(got, finding.Rule+"@"+strings.TrimPrefix(finding.Message, "return value of "))
+	}
+	expected := []string{
+		"errors/unchecked_error_code@load_config returns an error code (static int) that none of its 2 call sites check",
+		"errors/unchecked_call@malloc is ignored",
+		"errors/unchecked_call@pthread_mutex_lock is ignored",
+		"errors/unchecked_call@my_alloc is ignored",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Check() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+	if findings[1].Line != 26 || findings[1].Function != "run" {
+		t.Errorf("unexpected malloc finding: %+v", findings[1])
+	}
+}
+
+func TestEmptyCatch(t *testing.T) {
+	source := `
+void Server::start() {
+    try {
+        listen();
+    } catch (const std::exception &e) {
+    }
+    try {
+        listen();
+    } catch (...) {
+        // shutting down, nothing to report
+    }
+}
+`
+	result := Scan("server.cpp", source)
+	if len(result.EmptyCatch) != 1 || result.EmptyCatch[0].Line != 5 || result.EmptyCatch[0].Function != "Server::start" {
+		t.Errorf("unexpected empty catch findings: %+v", result.EmptyCatch)
+	}
+}
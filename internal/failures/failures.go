@@ -0,0 +1,93 @@
+// Package failures collects the files a run could not read or parse. An
+// analyzer skipping a file records it here instead of printing an error, and
+// the run ends with a summary and fails when too many files were skipped.
+package failures
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Failure is a skipped file with the first error it caused.
+type Failure struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+var (
+	mu        sync.Mutex
+	attempted = make(map[string]bool)
+	failed    = make(map[string]string)
+)
+
+// Attempt notes files a run is about to process, the total skipped files
+// are compared with. Files are counted once however often they are given.
+func Attempt(files []string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, file := range files {
+		attempted[file] = true
+	}
+}
+
+// Record notes that file was skipped because of err. Only the first error
+// of a file is kept, later stages usually fail for the same reason.
+func Record(file string, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	attempted[file] = true
+	if _, ok := failed[file]; !ok {
+		failed[file] = err.Error()
+	}
+}
+
+// Failures returns the skipped files by path.
+func Failures() []Failure {
+	mu.Lock()
+	defer mu.Unlock()
+	result := make([]Failure, 0, len(failed))
+	for file, err := range failed {
+		result = append(result, Failure{File: file, Error: err})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].File < result[j].File })
+	return result
+}
+
+// Counts returns the number of skipped files and of files attempted.
+func Counts() (skipped, total int) {
+	mu.Lock()
+	defer mu.Unlock()
+	return len(failed), len(attempted)
+}
+
+// Reset forgets the files of the previous run, for processes running
+// several.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	attempted = make(map[string]bool)
+	failed = make(map[string]string)
+}
+
+// TooManyError fails a run that skipped more than the tolerated fraction of
+// its files.
+type TooManyError struct {
+	Skipped int
+	Total   int
+	Limit   float64
+}
+
+func (e *TooManyError) Error() string {
+	return fmt.Sprintf("%d of %d files skipped due to errors, more than the %g%% tolerated", e.Skipped, e.Total, e.Limit*100)
+}
+
+// Check returns a TooManyError when more than limit, a fraction between 0
+// and 1, of the attempted files were skipped.
+func Check(limit float64) error {
+	skipped, total := Counts()
+	if total > 0 && float64(skipped) > limit*float64(total) {
+		return &TooManyError{Skipped: skipped, Total: total, Limit: limit}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package failures
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	Attempt([]string{"a.c", "b.c", "c.c", "d.c"})
+	Attempt([]string{"a.c"})
+	Record("b.c", errors.New("permission denied"))
+	Record("b.c", errors.New("parse error"))
+	if skipped, total := Counts(); skipped != 1 || total != 4 {
+		t.Errorf("Counts() = %d, %d, want 1, 4", skipped, total)
+	}
+	if got := Failures(); len(got) != 1 || got[0] != (Failure{File: "b.c", Error: "permission denied"}) {
+		t.Errorf("Expected the first error of b.c, got %+v", got)
+	}
+
+	if err := Check(0.25); err != nil {
+		t.Errorf("Expected 1 of 4 files to be tolerated at 25%%, got %v", err)
+	}
+	Record("e.c", errors.New("is a directory"))
+	var tooMany *TooManyError
+	if err := Check(0.25); !errors.As(err, &tooMany) || tooMany.Skipped != 2 || tooMany.Total != 5 {
+		t.Errorf("Expected 2 of 5 files to be too many at 25%%, got %v", err)
+	}
+}
@@ -0,0 +1,248 @@
+package fix
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/refactor"
+	"github.com/vitruves/gop/internal/utils"
+)
+
+type Config struct {
+	// Rules are the IDs of the rules to apply, "all" for every rule
+	Rules  []string
+	List   bool
+	DryRun bool
+	Backup bool
+	// IncludeDirs are the -I search paths used to resolve includes
+	IncludeDirs    []string
+	Include        []string
+	Exclude        []string
+	Recursive      bool
+	Depth          int
+	Verbose        bool
+	SkipGenerated  bool
+	SkipVendor     bool
+	MaxFileSize    int64
+	FollowSymlinks bool
+	IncludeHidden  bool
+	Manifest       *manifest.Manifest
+}
+
+// Fix is a finding with the edits that resolve it. The edits of a fix are
+// applied together or not at all.
+type Fix struct {
+	File    string
+	Line    int
+	Rule    string
+	Message string
+	Edits   []refactor.Edit
+}
+
+// Rule finds mechanical problems and the edits fixing them. Find gets every
+// scanned C/C++ file, so rules can look across files.
+type Rule struct {
+	ID          string
+	Description string
+	Find        func(files []string, config Config) []Fix
+}
+
+// Rules lists the available rules, new ones only need to be added here.
+var Rules = []Rule{
+	{ID: "strcpy", Description: "Replace strcpy into a char array by a bounded strncpy that terminates the string", Find: findStrcpy},
+	{ID: "include-guard", Description: "Add an include guard named after the path to headers without one or #pragma once", Find: findMissingGuards},
+	{ID: "duplicate-include", Description: "Remove an #include repeated in the same file", Find: findDuplicateIncludes},
+	{ID: "unused-include", Description: "Remove includes of project headers a source file uses nothing from", Find: findUnusedIncludes},
+}
+
+var cFamilyExtensions = map[string]bool{
+	".c": true, ".h": true, ".cpp": true, ".cxx": true, ".cc": true,
+	".hpp": true, ".hxx": true, ".hh": true, ".h++": true, ".c++": true,
+	".inl": true, ".ipp": true, ".tpp": true,
+}
+
+func Lookup(id string) (Rule, bool) {
+	for _, rule := range Rules {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+func Run(config Config) error {
+	if config.List {
+		listRules()
+		return nil
+	}
+	if len(config.Rules) == 0 {
+		return fmt.Errorf("no rule given, pick one with --rule (see --list)")
+	}
+
+	var rules []Rule
+	for _, id := range config.Rules {
+		if id == "all" {
+			rules = Rules
+			break
+		}
+		rule, ok := Lookup(id)
+		if !ok {
+			return fmt.Errorf("unknown rule: %s (see --list)", id)
+		}
+		rules = append(rules, rule)
+	}
+
+	opts := utils.ScanOptions{
+		Include:        config.Include,
+		Exclude:        config.Exclude,
+		Recursive:      config.Recursive,
+		Depth:          config.Depth,
+		SkipGenerated:  config.SkipGenerated,
+		SkipVendor:     config.SkipVendor,
+		MaxFileSize:    config.MaxFileSize,
+		FollowSymlinks: config.FollowSymlinks,
+		IncludeHidden:  config.IncludeHidden,
+		Verbose:        config.Verbose,
+	}
+
+	files, err := utils.GetFilesToProcess(opts, func(path string) bool {
+		return cFamilyExtensions[strings.ToLower(filepath.Ext(path))]
+	})
+	if err != nil {
+		logError(fmt.Sprintf("Failed to collect files: %v", err))
+		return err
+	}
+
+	if len(files) == 0 {
+		logWarning("No files found matching criteria")
+		return nil
+	}
+
+	logInfo(config.Verbose, fmt.Sprintf("Checking %d files", len(files)))
+	config.Manifest.AddFiles(files)
+
+	var fixes []Fix
+	for _, rule := range rules {
+		found := rule.Find(files, config)
+		logInfo(config.Verbose, fmt.Sprintf("%s: %d fixes", rule.ID, len(found)))
+		fixes = append(fixes, found...)
+	}
+
+	if len(fixes) == 0 {
+		logSuccess("Nothing to fix")
+		return nil
+	}
+
+	return apply(fixes, config)
+}
+
+// apply edits each file once with the fixes found in it. A fix overlapping
+// one before it is skipped, a later run picks it up.
+func apply(fixes []Fix, config Config) error {
+	byFile := make(map[string][]Fix)
+	for _, fix := range fixes {
+		byFile[fix.File] = append(byFile[fix.File], fix)
+	}
+	var files []string
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	applied, skipped, changed := 0, 0, 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		content := string(data)
+
+		fileFixes := byFile[file]
+		sort.SliceStable(fileFixes, func(i, j int) bool { return fileFixes[i].Line < fileFixes[j].Line })
+
+		shown := utils.DisplayPath(file)
+		fmt.Printf("\n%s\n", color.Wrap(color.BoldCyan, fmt.Sprintf("=== %s ===", shown)))
+		var edits []refactor.Edit
+		for _, fix := range fileFixes {
+			candidate := append(append([]refactor.Edit{}, edits...), fix.Edits...)
+			if _, err := refactor.ApplyEdits(content, candidate); err != nil {
+				fmt.Printf("%s - [%s] %s (skipped, overlaps another fix)\n", color.Wrap(color.Yellow, fmt.Sprintf("%s:%d", shown, fix.Line)), fix.Rule, fix.Message)
+				skipped++
+				continue
+			}
+			edits = candidate
+			applied++
+			fmt.Printf("%s - [%s] %s\n", color.Wrap(color.Yellow, fmt.Sprintf("%s:%d", shown, fix.Line)), fix.Rule, fix.Message)
+		}
+		if len(edits) == 0 {
+			continue
+		}
+		changed++
+
+		if config.DryRun {
+			diff, err := refactor.UnifiedDiff(filepath.ToSlash(shown), content, edits)
+			if err != nil {
+				return err
+			}
+			fmt.Print(diff)
+			continue
+		}
+
+		updated, err := refactor.ApplyEdits(content, edits)
+		if err != nil {
+			return err
+		}
+		if err := refactor.WriteFile(file, updated, config.Backup); err != nil {
+			return err
+		}
+	}
+
+	if skipped > 0 {
+		logWarning(fmt.Sprintf("Skipped %d overlapping fixes, run again to apply them", skipped))
+	}
+	if config.DryRun {
+		logSuccess(fmt.Sprintf("Dry run: %d fixes in %d files would be applied", applied, changed))
+		return nil
+	}
+	logSuccess(fmt.Sprintf("Applied %d fixes in %d files", applied, changed))
+	return nil
+}
+
+func listRules() {
+	width := 0
+	for _, rule := range Rules {
+		width = max(width, len(rule.ID))
+	}
+	for _, rule := range Rules {
+		fmt.Printf("%-*s  %s\n", width, rule.ID, rule.Description)
+	}
+}
+
+func logInfo(verbose bool, msg string) {
+	if verbose {
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
+	}
+}
+
+func logSuccess(msg string) {
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
+}
+
+func logWarning(msg string) {
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
+}
+
+func logError(msg string) {
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
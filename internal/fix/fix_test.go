@@ -0,0 +1,125 @@
+package fix
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/vitruves/gop/internal/refactor"
+)
+
+func TestRules(t *testing.T) {
+	dir := t.TempDir()
+	sources := map[string]string{
+		"util.h":    "// util\nint twice(int x);\n",
+		"guarded.h": "#ifndef GUARDED_H\n#define GUARDED_H\nstruct Unused { int x; };\n#endif\n",
+		"main.c": `#include "util.h"
+#include "guarded.h"
+#include <string.h>
+#ifdef DEBUG
+#include <stdio.h>
+#else
+#include <stdio.h>
+#endif
+#include <string.h>
+
+int main(int argc, char **argv) {
+    char name[16];
+    char *p = name;
+    strcpy(name, argv[0]);
+    strcpy(p, "x");
+    return twice(argc);
+}
+`,
+	}
+	var files []string
+	for name, content := range sources {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+
+	want := map[string]string{
+		"strcpy":            "main.c:14",
+		"include-guard":     "util.h:2",
+		"duplicate-include": "main.c:9",
+		"unused-include":    "main.c:2",
+	}
+	for _, rule := range Rules {
+		fixes := rule.Find(files, Config{IncludeDirs: []string{dir}})
+		var got []string
+		for _, fix := range fixes {
+			got = append(got, filepath.Base(fix.File)+":"+strconv.Itoa(fix.Line))
+		}
+		if len(got) != 1 || got[0] != want[rule.ID] {
+			t.Errorf("%s: got fixes %v, want %s", rule.ID, got, want[rule.ID])
+		}
+	}
+
+	content := sources["util.h"]
+	fixes := findMissingGuards([]string{filepath.Join(dir, "util.h")}, Config{IncludeDirs: []string{dir}})
+	fixed, err := refactor.ApplyEdits(content, fixes[0].Edits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "// util\n#ifndef UTIL_H\n#define UTIL_H\n\nint twice(int x);\n\n#endif /* UTIL_H */\n"; fixed != want {
+		t.Errorf("include guard:\n%s\nwant\n%s", fixed, want)
+	}
+
+	content = sources["main.c"]
+	fixes = findStrcpy([]string{filepath.Join(dir, "main.c")}, Config{})
+	fixed, err = refactor.ApplyEdits(content, fixes[0].Edits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantLines := "    strncpy(name, argv[0], sizeof(name) - 1);\n    name[sizeof(name) - 1] = '\\0';\n    strcpy(p, \"x\");\n"
+	if !strings.Contains(fixed, wantLines) {
+		t.Errorf("strcpy fix:\n%s", fixed)
+	}
+}
+
+// Only char arrays declared in the enclosing function give sizeof a bound,
+// a pointer parameter named like an array elsewhere must be left alone.
+func TestStrcpyEnclosingFunction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "copy.c")
+	content := `#include <string.h>
+
+void a(const char *s) {
+    char buf[64];
+    strcpy(buf, s);
+}
+
+void b(char *buf, const char *s) { strcpy(buf, s); }
+
+void c(char *buf, const char *s) {
+    if (s) {
+        char buf[8];
+        (void)buf;
+    }
+    strcpy(buf, s);
+}
+
+void d(const char *s) {
+    char tmp[4], buf[32];
+    while (*s) {
+        strcpy(buf, s);
+        s++;
+    }
+}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []int
+	for _, fix := range findStrcpy([]string{path}, Config{}) {
+		lines = append(lines, fix.Line)
+	}
+	if len(lines) != 2 || lines[0] != 5 || lines[1] != 21 {
+		t.Errorf("Expected fixes in a and d only, got lines %v", lines)
+	}
+}
@@ -0,0 +1,402 @@
+package fix
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/fwddecl"
+	"github.com/vitruves/gop/internal/refactor"
+	"github.com/vitruves/gop/internal/utils"
+)
+
+var headerExtensions = map[string]bool{
+	".h": true, ".hh": true, ".hpp": true, ".hxx": true, ".h++": true,
+}
+
+var (
+	strcpyRegex     = regexp.MustCompile(`\bstrcpy\s*\(`)
+	charDeclRegex   = regexp.MustCompile(`\bchar\b([^;(){}]*)`)
+	arrayNameRegex  = regexp.MustCompile(`([A-Za-z_]\w*)\s*\[[^\]]+\]`)
+	bodyOpenRegex   = regexp.MustCompile(`\)\s*(?:const\s*|noexcept\s*|override\s*|final\s*)*$`)
+	identifierRegex = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+	includeRegex    = regexp.MustCompile(`^\s*#\s*include\s*([<"])([^>"]+)[>"]`)
+	conditionRegex  = regexp.MustCompile(`^\s*#\s*(if|ifdef|ifndef|elif|else|endif)\b`)
+	guardedRegex    = regexp.MustCompile(`^\s*#\s*(?:ifndef\b|if\s*!\s*defined\b)`)
+	pragmaOnceRegex = regexp.MustCompile(`(?m)^\s*#\s*pragma\s+once\b`)
+)
+
+// readFile returns the file as it is on disk, edits are byte offsets into it.
+func readFile(path string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		failures.Record(path, err)
+		return "", false
+	}
+	return string(data), true
+}
+
+func lineEnding(content string) string {
+	if strings.Contains(content, "\r\n") {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// removeLine deletes a 1-based line with its line break.
+func removeLine(content string, index utils.LineIndex, line int) refactor.Edit {
+	end := len(content)
+	if line < len(index) {
+		end = index[line]
+	}
+	return refactor.Edit{Start: index[line-1], End: end}
+}
+
+// findStrcpy rewrites strcpy calls made as statements of their own into a
+// char array declared in the enclosing function, where sizeof gives the
+// bound:
+//
+//	strcpy(name, src);  ->  strncpy(name, src, sizeof(name) - 1);
+//	                        name[sizeof(name) - 1] = '\0';
+//
+// Calls whose destination is a pointer, a parameter included, or whose
+// result is used, are left for review.
+func findStrcpy(files []string, config Config) []Fix {
+	var fixes []Fix
+	for _, file := range files {
+		content, ok := readFile(file)
+		if !ok || !strings.Contains(content, "strcpy") {
+			continue
+		}
+		code := utils.BlankCommentsAndLiterals(content)
+		index := utils.NewLineIndex(content)
+
+		for _, loc := range strcpyRegex.FindAllStringIndex(code, -1) {
+			open := loc[1] - 1
+			close := utils.MatchingBracket(code, open, '(', ')')
+			if close == -1 {
+				continue
+			}
+			args := splitArguments(code, open+1, close)
+			if len(args) != 2 {
+				continue
+			}
+			destination := strings.TrimSpace(content[args[0][0]:args[0][1]])
+			source := strings.TrimSpace(content[args[1][0]:args[1][1]])
+			if !identifierRegex.MatchString(destination) {
+				continue
+			}
+
+			end := close + 1
+			for end < len(code) && (code[end] == ' ' || code[end] == '\t') {
+				end++
+			}
+			if end == len(code) || code[end] != ';' {
+				continue
+			}
+			before := strings.TrimRight(code[:loc[0]], " \t\r\n")
+			if before != "" && !strings.ContainsAny(before[len(before)-1:], ";{}") {
+				continue
+			}
+			if !declaresArray(visibleBody(code, loc[0]), destination) {
+				continue
+			}
+
+			line, _ := index.Position(loc[0])
+			rest := content[index[line-1]:]
+			indent := rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))]
+			text := fmt.Sprintf("strncpy(%s, %s, sizeof(%s) - 1);%s%s%s[sizeof(%s) - 1] = '\\0';",
+				destination, source, destination, lineEnding(content), indent, destination, destination)
+
+			fixes = append(fixes, Fix{
+				File:    file,
+				Line:    line,
+				Rule:    "strcpy",
+				Message: fmt.Sprintf("strcpy into %s is unbounded, copy at most sizeof(%s) - 1 bytes", destination, destination),
+				Edits:   []refactor.Edit{{Start: loc[0], End: end + 1, Text: text}},
+			})
+		}
+	}
+	return fixes
+}
+
+// visibleBody returns the text of the function body enclosing offset up to
+// it, without the blocks already closed there, so only the declarations in
+// scope at offset remain. Parameters are outside the body. It is empty
+// outside a function.
+func visibleBody(code string, offset int) string {
+	// The outermost brace opened after a parameter list is the body
+	var opens []int
+	for i := 0; i < offset; i++ {
+		switch code[i] {
+		case '{':
+			opens = append(opens, i)
+		case '}':
+			if len(opens) > 0 {
+				opens = opens[:len(opens)-1]
+			}
+		}
+	}
+	start := -1
+	for _, open := range opens {
+		if bodyOpenRegex.MatchString(code[:open]) {
+			start = open + 1
+			break
+		}
+	}
+	if start == -1 {
+		return ""
+	}
+
+	var visible []byte
+	var marks []int
+	for i := start; i < offset; i++ {
+		switch code[i] {
+		case '{':
+			marks = append(marks, len(visible))
+		case '}':
+			if n := len(marks); n > 0 {
+				visible = visible[:marks[n-1]]
+				marks = marks[:n-1]
+				continue
+			}
+		}
+		visible = append(visible, code[i])
+	}
+	return string(visible)
+}
+
+// declaresArray reports whether code declares name as a char array.
+func declaresArray(code, name string) bool {
+	for _, declaration := range charDeclRegex.FindAllStringSubmatch(code, -1) {
+		for _, array := range arrayNameRegex.FindAllStringSubmatch(declaration[1], -1) {
+			if array[1] == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitArguments returns the offsets of the comma-separated arguments in
+// code[start:end], ignoring commas nested in brackets.
+func splitArguments(code string, start, end int) [][2]int {
+	var args [][2]int
+	depth := 0
+	argStart := start
+	for i := start; i < end; i++ {
+		switch code[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, [2]int{argStart, i})
+				argStart = i + 1
+			}
+		}
+	}
+	return append(args, [2]int{argStart, end})
+}
+
+// findMissingGuards wraps headers that have neither an include guard nor
+// #pragma once in a guard named after their path, below any leading comment
+// such as a license header.
+func findMissingGuards(files []string, config Config) []Fix {
+	var fixes []Fix
+	for _, file := range files {
+		if !headerExtensions[strings.ToLower(filepath.Ext(file))] {
+			continue
+		}
+		content, ok := readFile(file)
+		if !ok || pragmaOnceRegex.MatchString(content) {
+			continue
+		}
+		code := utils.BlankCommentsAndLiterals(content)
+
+		// The first line holding code decides whether a guard is there
+		index := utils.NewLineIndex(content)
+		first := -1
+		for line := range index {
+			start := index[line]
+			end := len(code)
+			if line+1 < len(index) {
+				end = index[line+1]
+			}
+			if strings.TrimSpace(code[start:end]) != "" {
+				first = line
+				break
+			}
+		}
+		if first == -1 {
+			continue
+		}
+		start := index[first]
+		if guardedRegex.MatchString(code[start:]) {
+			continue
+		}
+
+		guard := refactor.GuardName(guardPath(file, config.IncludeDirs))
+		if guard == "" {
+			continue
+		}
+		if guard[0] >= '0' && guard[0] <= '9' {
+			guard = "H_" + guard
+		}
+
+		nl := lineEnding(content)
+		closing := nl + "#endif /* " + guard + " */" + nl
+		if !strings.HasSuffix(content, "\n") {
+			closing = nl + closing
+		}
+		fixes = append(fixes, Fix{
+			File:    file,
+			Line:    first + 1,
+			Rule:    "include-guard",
+			Message: fmt.Sprintf("header has no include guard, add %s", guard),
+			Edits: []refactor.Edit{
+				{Start: start, End: start, Text: "#ifndef " + guard + nl + "#define " + guard + nl + nl},
+				{Start: len(content), End: len(content), Text: closing},
+			},
+		})
+	}
+	return fixes
+}
+
+// guardPath is the path a guard is named after: the header's path below the
+// include directory it is found from, so include/mylib/util.h with -I include
+// gives MYLIB_UTIL_H, or else its path from the working directory.
+func guardPath(file string, includeDirs []string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return filepath.Clean(file)
+	}
+	best := ""
+	for _, dir := range append(append([]string{}, includeDirs...), ".") {
+		dirAbs, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(dirAbs, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if best == "" || len(rel) < len(best) {
+			best = rel
+		}
+	}
+	if best == "" {
+		return filepath.Clean(file)
+	}
+	return best
+}
+
+// findDuplicateIncludes removes includes of a path already included earlier
+// in the same conditional block, or in a block enclosing it.
+func findDuplicateIncludes(files []string, config Config) []Fix {
+	type key struct {
+		block   int
+		include string
+	}
+
+	var fixes []Fix
+	for _, file := range files {
+		content, ok := readFile(file)
+		if !ok {
+			continue
+		}
+		code := strings.Split(utils.BlankCommentsAndLiterals(content), "\n")
+		lines := strings.Split(content, "\n")
+		index := utils.NewLineIndex(content)
+
+		seen := make(map[key]int)
+		blocks := []int{0}
+		next := 1
+		for i, line := range lines {
+			// Directives inside comments are blanked in code
+			if !strings.HasPrefix(strings.TrimSpace(code[i]), "#") {
+				continue
+			}
+			if match := conditionRegex.FindStringSubmatch(line); match != nil {
+				switch match[1] {
+				case "if", "ifdef", "ifndef":
+					blocks = append(blocks, next)
+					next++
+				case "elif", "else":
+					if len(blocks) > 1 {
+						blocks[len(blocks)-1] = next
+						next++
+					}
+				case "endif":
+					if len(blocks) > 1 {
+						blocks = blocks[:len(blocks)-1]
+					}
+				}
+				continue
+			}
+
+			match := includeRegex.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			spelled := match[1] + match[2]
+			if match[1] == "<" {
+				spelled += ">"
+			} else {
+				spelled += `"`
+			}
+			previous := 0
+			for _, block := range blocks {
+				if first, ok := seen[key{block, spelled}]; ok {
+					previous = first
+				}
+			}
+			if previous == 0 {
+				seen[key{blocks[len(blocks)-1], spelled}] = i + 1
+				continue
+			}
+
+			fixes = append(fixes, Fix{
+				File:    file,
+				Line:    i + 1,
+				Rule:    "duplicate-include",
+				Message: fmt.Sprintf("%s is already included on line %d", spelled, previous),
+				Edits:   []refactor.Edit{removeLine(content, index, i+1)},
+			})
+		}
+	}
+	return fixes
+}
+
+// findUnusedIncludes removes includes of scanned headers that a source file
+// uses no declaration of, see fwddecl.UnusedIncludes.
+func findUnusedIncludes(files []string, config Config) []Fix {
+	var fixes []Fix
+	contents := make(map[string]string)
+	for _, unused := range fwddecl.UnusedIncludes(files, config.IncludeDirs) {
+		content, ok := contents[unused.File]
+		if !ok {
+			if content, ok = readFile(unused.File); !ok {
+				continue
+			}
+			contents[unused.File] = content
+		}
+
+		spelled := "<" + unused.Spelled + ">"
+		if unused.Quoted {
+			spelled = `"` + unused.Spelled + `"`
+		}
+		fixes = append(fixes, Fix{
+			File:    unused.File,
+			Line:    unused.Line,
+			Rule:    "unused-include",
+			Message: fmt.Sprintf("nothing declared by %s or the headers it includes is used", spelled),
+			Edits:   []refactor.Edit{removeLine(content, utils.NewLineIndex(content), unused.Line)},
+		})
+	}
+	return fixes
+}
@@ -0,0 +1,861 @@
+package fwddecl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/registry"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
+)
+
+type Config struct {
+	Include   []string
+	Exclude   []string
+	Recursive bool
+	Depth     int
+	Jobs      int
+	Verbose   bool
+	// IncludeDirs are the -I search paths, the current directory when empty
+	IncludeDirs    []string
+	Fix            bool
+	DryRun         bool
+	Backup         bool
+	OutputFile     string
+	Format         string
+	SkipGenerated  bool
+	SkipVendor     bool
+	MaxFileSize    int64
+	FollowSymlinks bool
+	IncludeHidden  bool
+	NoProgress     bool
+	Manifest       *manifest.Manifest
+}
+
+const Rule = "includes/forward_declare"
+
+// Type is a class, struct or union that can be declared ahead of its
+// definition. Namespace is qualified, empty at global scope.
+type Type struct {
+	Name      string
+	Kind      string
+	Namespace string
+}
+
+// Declaration spells the forward declaration, wrapped in its namespaces.
+func (t Type) Declaration() string {
+	declaration := fmt.Sprintf("%s %s;", t.Kind, t.Name)
+	if t.Namespace == "" {
+		return declaration
+	}
+	namespaces := strings.Split(t.Namespace, "::")
+	return "namespace " + strings.Join(namespaces, " { namespace ") + " { " + declaration + strings.Repeat(" }", len(namespaces))
+}
+
+// Opportunity is an include of a header that only needs the types it
+// names, used through pointers or references.
+type Opportunity struct {
+	Header  string
+	Line    int
+	Spelled string
+	Quoted  bool
+	Target  string
+	Types   []Type
+}
+
+// Edit is an include added to a file that relied on the replaced include.
+type Edit struct {
+	File    string
+	After   int
+	Spelled string
+	Quoted  bool
+}
+
+var cFamilyExtensions = map[string]bool{
+	".c": true, ".h": true, ".cpp": true, ".cxx": true, ".cc": true,
+	".hpp": true, ".hxx": true, ".hh": true, ".h++": true, ".c++": true,
+	".inl": true, ".ipp": true, ".tpp": true,
+}
+
+var headerExtensions = map[string]bool{
+	".h": true, ".hpp": true, ".hxx": true, ".hh": true, ".h++": true, ".inl": true, ".ipp": true, ".tpp": true,
+}
+
+var (
+	includeRegex  = regexp.MustCompile(`^\s*#\s*include\s*([<"])([^>"]+)[>"]`)
+	defineRegex   = regexp.MustCompile(`(?m)^[ \t]*#[ \t]*define[ \t]+(\w+)`)
+	typedefRegex  = regexp.MustCompile(`\btypedef\b[^;{}]*?(\w+)\s*(?:\[[^\]]*\]\s*)*;`)
+	funcPtrRegex  = regexp.MustCompile(`\(\s*\*\s*(\w+)\s*\)\s*\(`)
+	closingRegex  = regexp.MustCompile(`\}\s*(\w+)\s*[;,\[]`)
+	usingRegex    = regexp.MustCompile(`\busing\s+(\w+)\s*=`)
+	externRegex   = regexp.MustCompile(`\bextern\b[^;(]*?(\w+)\s*(?:\[[^\]]*\])?\s*;`)
+	identRegex    = regexp.MustCompile(`[A-Za-z_]\w*`)
+	pointerRegex  = regexp.MustCompile(`^\s*(?:const\b\s*)?[*&]+\s*(?:const\b\s*)?(\w*)`)
+	keywordBefore = regexp.MustCompile(`\b(?:struct|class|union|friend\s+(?:struct|class|union))\s*$`)
+)
+
+// file is what the analysis needs of one scanned file.
+type file struct {
+	path     string
+	includes []include
+	// records are the types declared here that can be forward declared,
+	// names everything else it declares
+	records map[string]Type
+	names   map[string]bool
+	// types are the records, enums, typedefs and aliases declared at
+	// namespace scope, templates included
+	types  map[string]bool
+	code   string
+	idents map[string]bool
+}
+
+type include struct {
+	line    int
+	spelled string
+	quoted  bool
+	target  string
+}
+
+func Run(config Config) error {
+	logInfo(config.Verbose, "Starting forward declaration check")
+
+	if config.Format == "" {
+		config.Format = "text"
+	}
+	if config.Format != "text" && !diagnostics.IsFormat(config.Format) {
+		return fmt.Errorf("unsupported format: %s (expected text, %s)", config.Format, strings.Join(diagnostics.Formats, ", "))
+	}
+	if config.DryRun {
+		config.Fix = true
+	}
+
+	opts := utils.ScanOptions{
+		Include:        config.Include,
+		Exclude:        config.Exclude,
+		Recursive:      config.Recursive,
+		Depth:          config.Depth,
+		SkipGenerated:  config.SkipGenerated,
+		SkipVendor:     config.SkipVendor,
+		MaxFileSize:    config.MaxFileSize,
+		FollowSymlinks: config.FollowSymlinks,
+		IncludeHidden:  config.IncludeHidden,
+		Verbose:        config.Verbose,
+	}
+
+	paths, err := utils.GetFilesToProcess(opts, func(path string) bool {
+		return cFamilyExtensions[strings.ToLower(filepath.Ext(path))]
+	})
+	if err != nil {
+		logError(fmt.Sprintf("Failed to collect files: %v", err))
+		return err
+	}
+
+	if len(paths) == 0 {
+		logWarning("No files found matching criteria")
+		return nil
+	}
+
+	logInfo(config.Verbose, fmt.Sprintf("Found %d files to analyze", len(paths)))
+	config.Manifest.AddFiles(paths)
+
+	files := worker.Run(paths, config.Jobs, progress.New("Parsing files", len(paths), config.NoProgress), func(ctx context.Context, idx int, path string) *file {
+		parsed, err := parseFile(path)
+		if err != nil {
+			failures.Record(path, err)
+			parsed = &file{path: path, records: map[string]Type{}, names: map[string]bool{}, idents: map[string]bool{}}
+		}
+		return parsed
+	})
+
+	includeDirs := config.IncludeDirs
+	if len(includeDirs) == 0 {
+		includeDirs = []string{"."}
+	}
+	graph := newGraph(files, includeDirs)
+	opportunities := graph.Opportunities()
+
+	if config.Fix {
+		return applyFixes(graph, opportunities, config)
+	}
+	return writeOutput(Diagnostics(opportunities), config)
+}
+
+// Find returns the forward declaration opportunities among paths, for
+// callers that run the check without Run's file scan and output.
+func Find(paths []string, includeDirs []string) []Opportunity {
+	files := make([]*file, 0, len(paths))
+	for _, path := range paths {
+		parsed, err := parseFile(path)
+		if err != nil {
+			parsed = &file{path: path, records: map[string]Type{}, names: map[string]bool{}, idents: map[string]bool{}}
+		}
+		files = append(files, parsed)
+	}
+	if len(includeDirs) == 0 {
+		includeDirs = []string{"."}
+	}
+	return newGraph(files, includeDirs).Opportunities()
+}
+
+// parseFile collects the includes, declared names and identifiers of a file.
+// Types nested in classes or declared as templates are not forward
+// declarable and count as plain names.
+func parseFile(path string) (*file, error) {
+	content, err := utils.ReadSourceFile(path)
+	if err != nil {
+		return nil, err
+	}
+	members, err := registry.GetParser("cpp").(registry.MemberParser).ParseMembers(path)
+	if err != nil {
+		return nil, err
+	}
+	functions, err := registry.GetParser("cpp").ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &file{path: path, records: make(map[string]Type), names: make(map[string]bool), types: make(map[string]bool), idents: make(map[string]bool)}
+	lines := utils.SplitLines(content)
+
+	code := []byte(utils.BlankCommentsAndLiterals(content))
+	codeLines := strings.Split(string(code), "\n")
+	offset := 0
+	for i, line := range lines {
+		if match := includeRegex.FindStringSubmatch(line); match != nil && i < len(codeLines) {
+			f.includes = append(f.includes, include{line: i + 1, spelled: match[2], quoted: match[1] == `"`})
+			// The include itself is not a use of what it names
+			for j := offset; j < offset+len(codeLines[i]); j++ {
+				code[j] = ' '
+			}
+		}
+		if i < len(codeLines) {
+			offset += len(codeLines[i]) + 1
+		}
+	}
+	f.code = string(code)
+	for _, ident := range identRegex.FindAllString(f.code, -1) {
+		f.idents[ident] = true
+	}
+
+	kinds := make(map[string]string)
+	for _, member := range members {
+		qualified := member.Name
+		if member.Scope != "" {
+			qualified = member.Scope + "::" + member.Name
+		}
+		kinds[qualified] = member.Kind
+	}
+	for _, member := range members {
+		switch member.Kind {
+		case "class", "struct", "union", "enum":
+			if member.Scope == "" || kinds[member.Scope] == "namespace" {
+				f.types[member.Name] = true
+			}
+		}
+		switch member.Kind {
+		case "class", "struct", "union":
+			template := member.Line >= 2 && strings.HasPrefix(strings.TrimSpace(lines[member.Line-2]), "template") ||
+				member.Line >= 1 && strings.Contains(lines[member.Line-1], "template")
+			if !template && (member.Scope == "" || kinds[member.Scope] == "namespace") {
+				f.records[member.Name] = Type{Name: member.Name, Kind: member.Kind, Namespace: member.Scope}
+			} else {
+				f.names[member.Name] = true
+			}
+		case "enum", "enumerator":
+			f.names[member.Name] = true
+		}
+	}
+	for _, fn := range functions {
+		name := fn.Name
+		if idx := strings.LastIndex(name, "::"); idx != -1 {
+			name = name[idx+2:]
+		}
+		f.names[name] = true
+	}
+	for _, regex := range []*regexp.Regexp{defineRegex, typedefRegex, funcPtrRegex, closingRegex, usingRegex, externRegex} {
+		source := f.code
+		if regex == defineRegex {
+			source = content
+		}
+		for _, match := range regex.FindAllStringSubmatch(source, -1) {
+			f.names[match[1]] = true
+			if regex == typedefRegex || regex == usingRegex || regex == closingRegex {
+				f.types[match[1]] = true
+			}
+		}
+	}
+	// A typedef sharing the tag name, as in typedef struct point point, is
+	// used without the struct keyword and needs the definition
+	for name := range f.records {
+		if f.names[name] {
+			delete(f.records, name)
+		}
+	}
+
+	return f, nil
+}
+
+// graph is the include graph of the scanned files.
+type graph struct {
+	files  map[string]*file
+	order  []string
+	byName map[string][]string
+	// includers maps a file to the files including it directly
+	includers map[string][]string
+	removed   map[[2]string]bool
+}
+
+func newGraph(files []*file, includeDirs []string) *graph {
+	g := &graph{files: make(map[string]*file), byName: make(map[string][]string), includers: make(map[string][]string), removed: make(map[[2]string]bool)}
+	for _, f := range files {
+		key := filepath.Clean(f.path)
+		f.path = key
+		g.files[key] = f
+		g.order = append(g.order, key)
+		g.byName[filepath.Base(key)] = append(g.byName[filepath.Base(key)], key)
+	}
+	sort.Strings(g.order)
+
+	for _, key := range g.order {
+		f := g.files[key]
+		for i := range f.includes {
+			f.includes[i].target = g.resolve(key, f.includes[i], includeDirs)
+			if target := f.includes[i].target; target != "" {
+				g.includers[target] = append(g.includers[target], key)
+			}
+		}
+	}
+	return g
+}
+
+// resolve finds the scanned file an include refers to: next to the including
+// file for quote includes, then in the include directories, then the only
+// scanned file whose path ends with it.
+func (g *graph) resolve(from string, inc include, includeDirs []string) string {
+	var candidates []string
+	if inc.quoted {
+		candidates = append(candidates, filepath.Join(filepath.Dir(from), filepath.FromSlash(inc.spelled)))
+	}
+	for _, dir := range includeDirs {
+		candidates = append(candidates, filepath.Join(dir, filepath.FromSlash(inc.spelled)))
+	}
+	for _, candidate := range candidates {
+		if _, ok := g.files[filepath.Clean(candidate)]; ok {
+			return filepath.Clean(candidate)
+		}
+	}
+
+	var matches []string
+	for _, candidate := range g.byName[filepath.Base(inc.spelled)] {
+		if filepath.ToSlash(candidate) == inc.spelled || strings.HasSuffix(filepath.ToSlash(candidate), "/"+inc.spelled) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0]
+	}
+	return ""
+}
+
+// reach lists the files reachable from start through includes, start
+// included, leaving out removed edges.
+func (g *graph) reach(start string) map[string]bool {
+	seen := map[string]bool{start: true}
+	stack := []string{start}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, inc := range g.files[current].includes {
+			if inc.target == "" || seen[inc.target] || g.removed[[2]string{current, inc.target}] {
+				continue
+			}
+			seen[inc.target] = true
+			stack = append(stack, inc.target)
+		}
+	}
+	return seen
+}
+
+// declared gathers the records and names of a set of files.
+func (g *graph) declared(files map[string]bool) (map[string]Type, map[string]bool) {
+	records := make(map[string]Type)
+	names := make(map[string]bool)
+	for path := range files {
+		for name, record := range g.files[path].records {
+			records[name] = record
+		}
+		for name := range g.files[path].names {
+			names[name] = true
+		}
+	}
+	return records, names
+}
+
+// Opportunities finds the includes of headers whose declarations are only
+// used as types behind pointers or references. Names reachable through the
+// header's other includes are not credited to the include, and one used
+// name of any other kind keeps it.
+func (g *graph) Opportunities() []Opportunity {
+	var result []Opportunity
+
+	for _, path := range g.order {
+		if !headerExtensions[strings.ToLower(filepath.Ext(path))] {
+			continue
+		}
+		header := g.files[path]
+
+		for i, inc := range header.includes {
+			if inc.target == "" || inc.target == path {
+				continue
+			}
+
+			others := make(map[string]bool)
+			for j, other := range header.includes {
+				if j != i && other.target != "" {
+					for reached := range g.reach(other.target) {
+						others[reached] = true
+					}
+				}
+			}
+			reached := g.reach(inc.target)
+			delete(reached, path)
+			records, names := g.declared(reached)
+			otherRecords, otherNames := g.declared(others)
+
+			needed := false
+			var types []Type
+			for ident := range header.idents {
+				if otherNames[ident] || header.names[ident] {
+					continue
+				}
+				if _, ok := otherRecords[ident]; ok {
+					continue
+				}
+				if _, own := header.records[ident]; own {
+					continue
+				}
+				if names[ident] {
+					needed = true
+					break
+				}
+				record, ok := records[ident]
+				if !ok {
+					continue
+				}
+				if !usedIndirectly(header.code, ident) {
+					needed = true
+					break
+				}
+				types = append(types, record)
+			}
+			if needed || len(types) == 0 {
+				continue
+			}
+
+			sort.Slice(types, func(a, b int) bool { return types[a].Name < types[b].Name })
+			result = append(result, Opportunity{Header: path, Line: inc.line, Spelled: inc.spelled, Quoted: inc.quoted, Target: inc.target, Types: types})
+		}
+	}
+
+	return result
+}
+
+// UnusedInclude is an include of a scanned header that a source file does
+// not need.
+type UnusedInclude struct {
+	File    string
+	Line    int
+	Spelled string
+	Quoted  bool
+}
+
+// UnusedIncludes finds the includes of source files whose header, and the
+// headers it includes, declare nothing the file uses. Headers are left alone
+// since their includers may rely on what they include, and an include is kept
+// when another header the file includes uses its declarations, as with
+// configuration headers that must come first.
+func UnusedIncludes(paths []string, includeDirs []string) []UnusedInclude {
+	var files []*file
+	for _, path := range paths {
+		parsed, err := parseFile(path)
+		if err != nil {
+			continue
+		}
+		files = append(files, parsed)
+	}
+	if len(includeDirs) == 0 {
+		includeDirs = []string{"."}
+	}
+	g := newGraph(files, includeDirs)
+
+	var result []UnusedInclude
+	for _, path := range g.order {
+		if headerExtensions[strings.ToLower(filepath.Ext(path))] {
+			continue
+		}
+		source := g.files[path]
+
+		for i, inc := range source.includes {
+			if inc.target == "" {
+				continue
+			}
+			records, names := g.declared(g.reach(inc.target))
+			used := func(idents map[string]bool) bool {
+				for ident := range idents {
+					if _, ok := records[ident]; ok || names[ident] {
+						return true
+					}
+				}
+				return false
+			}
+			if used(source.idents) {
+				continue
+			}
+
+			needed := false
+			for j, other := range source.includes {
+				if j == i || other.target == "" {
+					continue
+				}
+				for reached := range g.reach(other.target) {
+					// Headers including it themselves keep getting it
+					if !g.reach(reached)[inc.target] && used(g.files[reached].idents) {
+						needed = true
+					}
+				}
+			}
+			if !needed {
+				result = append(result, UnusedInclude{File: path, Line: inc.line, Spelled: inc.spelled, Quoted: inc.quoted})
+			}
+		}
+	}
+	return result
+}
+
+// usedIndirectly reports whether every use of a type in code goes through a
+// pointer or reference whose members are never accessed there, or is a
+// declaration of the type itself.
+func usedIndirectly(code, name string) bool {
+	regex := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+	var variables []string
+
+	for _, loc := range regex.FindAllStringIndex(code, -1) {
+		after := code[loc[1]:]
+		if strings.HasPrefix(strings.TrimLeft(after, " \t\r\n"), "::") {
+			return false
+		}
+		if strings.HasPrefix(strings.TrimLeft(after, " \t\r\n"), ";") && keywordBefore.MatchString(code[:loc[0]]) {
+			continue
+		}
+		match := pointerRegex.FindStringSubmatch(after)
+		if match == nil {
+			return false
+		}
+		if match[1] != "" {
+			variables = append(variables, match[1])
+		}
+	}
+
+	for _, variable := range variables {
+		if regexp.MustCompile(`\b` + regexp.QuoteMeta(variable) + `\s*(?:->|\.)`).MatchString(code) {
+			return false
+		}
+	}
+	return true
+}
+
+// Diagnostics reports each opportunity at its include.
+func Diagnostics(opportunities []Opportunity) []diagnostics.Diagnostic {
+	var result []diagnostics.Diagnostic
+	for _, opportunity := range opportunities {
+		var names, declarations []string
+		for _, t := range opportunity.Types {
+			names = append(names, t.Name)
+			declarations = append(declarations, t.Declaration())
+		}
+		result = append(result, diagnostics.Diagnostic{
+			File:     opportunity.Header,
+			Line:     opportunity.Line,
+			Column:   1,
+			Severity: diagnostics.SeverityInfo,
+			Rule:     Rule,
+			Message: fmt.Sprintf("%s is only included for %s, used by pointer or reference; forward declare instead: %s",
+				spell(opportunity.Spelled, opportunity.Quoted), strings.Join(names, ", "), strings.Join(declarations, " ")),
+			Excerpt: "#include " + spell(opportunity.Spelled, opportunity.Quoted),
+		})
+	}
+	return result
+}
+
+func spell(path string, quoted bool) string {
+	if quoted {
+		return `"` + path + `"`
+	}
+	return "<" + path + ">"
+}
+
+// Plan replaces each include by its forward declarations and lists the
+// includes to add to the files that used the removed header's declarations
+// through it, directly or through other headers.
+func (g *graph) Plan(opportunities []Opportunity) []Edit {
+	var edits []Edit
+	added := make(map[[2]string]bool)
+
+	for _, opportunity := range opportunities {
+		g.removed[[2]string{opportunity.Header, opportunity.Target}] = true
+		records, names := g.declared(map[string]bool{opportunity.Target: true})
+
+		for _, includer := range g.transitiveIncluders(opportunity.Header) {
+			f := g.files[includer]
+			if includer == opportunity.Target || added[[2]string{includer, opportunity.Target}] || g.reach(includer)[opportunity.Target] {
+				continue
+			}
+			uses := false
+			for ident := range f.idents {
+				if _, ok := records[ident]; ok || names[ident] {
+					uses = true
+					break
+				}
+			}
+			if !uses {
+				continue
+			}
+
+			after, spelled, quoted := g.includeSpelling(f, opportunity)
+			edits = append(edits, Edit{File: includer, After: after, Spelled: spelled, Quoted: quoted})
+			added[[2]string{includer, opportunity.Target}] = true
+		}
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].File != edits[j].File {
+			return edits[i].File < edits[j].File
+		}
+		return edits[i].After < edits[j].After
+	})
+	return edits
+}
+
+func (g *graph) transitiveIncluders(path string) []string {
+	seen := map[string]bool{path: true}
+	queue := []string{path}
+	var result []string
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, includer := range g.includers[current] {
+			if !seen[includer] {
+				seen[includer] = true
+				result = append(result, includer)
+				queue = append(queue, includer)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// includeSpelling places the new include after the file's first include and
+// spells it like the header did, relative to the file for quote includes
+// that were relative to the header.
+func (g *graph) includeSpelling(f *file, opportunity Opportunity) (int, string, bool) {
+	after := 0
+	if len(f.includes) > 0 {
+		after = f.includes[0].line
+	}
+	for _, inc := range f.includes {
+		if inc.target != "" && g.reach(inc.target)[opportunity.Header] || inc.target == opportunity.Header {
+			after = inc.line
+			break
+		}
+	}
+
+	spelled := opportunity.Spelled
+	relativeToHeader := filepath.Clean(filepath.Join(filepath.Dir(opportunity.Header), filepath.FromSlash(spelled))) == opportunity.Target
+	if opportunity.Quoted && relativeToHeader {
+		if rel, err := filepath.Rel(filepath.Dir(f.path), opportunity.Target); err == nil {
+			spelled = filepath.ToSlash(rel)
+		}
+	}
+	return after, spelled, opportunity.Quoted
+}
+
+func applyFixes(g *graph, opportunities []Opportunity, config Config) error {
+	if len(opportunities) == 0 {
+		logSuccess("No forward declaration opportunities found")
+		return nil
+	}
+	edits := g.Plan(opportunities)
+
+	replaced := make(map[string]map[int]Opportunity)
+	for _, opportunity := range opportunities {
+		if replaced[opportunity.Header] == nil {
+			replaced[opportunity.Header] = make(map[int]Opportunity)
+		}
+		replaced[opportunity.Header][opportunity.Line] = opportunity
+	}
+	added := make(map[string][]Edit)
+	for _, edit := range edits {
+		added[edit.File] = append(added[edit.File], edit)
+	}
+
+	var files []string
+	for path := range replaced {
+		files = append(files, path)
+	}
+	for path := range added {
+		if replaced[path] == nil {
+			files = append(files, path)
+		}
+	}
+	sort.Strings(files)
+
+	for _, path := range files {
+		shown := utils.DisplayPath(path)
+		fmt.Printf("\n%s\n", color.Wrap(color.BoldCyan, fmt.Sprintf("=== %s ===", shown)))
+		for _, opportunity := range opportunitiesByLine(replaced[path]) {
+			for _, t := range opportunity.Types {
+				fmt.Printf("%s - #include %s -> %s\n", color.Wrap(color.Yellow, fmt.Sprintf("%s:%d", shown, opportunity.Line)), spell(opportunity.Spelled, opportunity.Quoted), t.Declaration())
+			}
+		}
+		for _, edit := range added[path] {
+			fmt.Printf("%s + #include %s\n", color.Wrap(color.Yellow, fmt.Sprintf("%s:%d", shown, edit.After+1)), spell(edit.Spelled, edit.Quoted))
+		}
+	}
+
+	if config.DryRun {
+		logSuccess(fmt.Sprintf("Dry run: %d includes would be replaced by forward declarations, %d includes added", len(opportunities), len(edits)))
+		return nil
+	}
+
+	for _, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		lines := strings.Split(string(content), "\n")
+		ending := ""
+		if strings.Contains(string(content), "\r\n") {
+			ending = "\r"
+		}
+
+		var updated []string
+		for i, line := range lines {
+			if opportunity, ok := replaced[path][i+1]; ok {
+				for _, t := range opportunity.Types {
+					updated = append(updated, t.Declaration()+ending)
+				}
+			} else {
+				updated = append(updated, line)
+			}
+			for _, edit := range added[path] {
+				if edit.After == i+1 {
+					updated = append(updated, "#include "+spell(edit.Spelled, edit.Quoted)+ending)
+				}
+			}
+		}
+		if len(added[path]) > 0 && added[path][0].After == 0 {
+			var header []string
+			for _, edit := range added[path] {
+				if edit.After == 0 {
+					header = append(header, "#include "+spell(edit.Spelled, edit.Quoted)+ending)
+				}
+			}
+			updated = append(header, updated...)
+		}
+
+		if config.Backup {
+			if err := os.WriteFile(path+".bak", content, 0644); err != nil {
+				return err
+			}
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(strings.Join(updated, "\n")), info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+
+	logSuccess(fmt.Sprintf("Replaced %d includes by forward declarations, added %d includes", len(opportunities), len(edits)))
+	return nil
+}
+
+func opportunitiesByLine(byLine map[int]Opportunity) []Opportunity {
+	var result []Opportunity
+	for _, opportunity := range byLine {
+		result = append(result, opportunity)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Line < result[j].Line })
+	return result
+}
+
+func writeOutput(findings []diagnostics.Diagnostic, config Config) error {
+	findings = diagnostics.DisplayPaths(diagnostics.Filtered(findings))
+	var sb strings.Builder
+
+	if config.Format == "text" {
+		if len(findings) == 0 {
+			logSuccess("No forward declaration opportunities found")
+			return nil
+		}
+
+		for _, finding := range findings {
+			sb.WriteString(fmt.Sprintf("%s:%d:%d: %s\n", finding.File, finding.Line, finding.Column, finding.Message))
+		}
+	} else if err := diagnostics.WriteReport(&sb, config.Format, findings); err != nil {
+		return err
+	}
+	output := []byte(sb.String())
+
+	if config.OutputFile != "" {
+		config.Manifest.AddResult(config.OutputFile, output)
+		if err := os.WriteFile(config.OutputFile, output, 0644); err != nil {
+			logError(fmt.Sprintf("Failed to write report: %v", err))
+			return err
+		}
+	} else {
+		config.Manifest.AddResult("stdout", output)
+		fmt.Print(string(output))
+	}
+
+	if config.Format == "text" {
+		logWarning(fmt.Sprintf("Found %d includes that could be forward declarations", len(findings)))
+	}
+	return nil
+}
+
+func logInfo(verbose bool, msg string) {
+	if verbose {
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
+	}
+}
+
+func logSuccess(msg string) {
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
+}
+
+func logWarning(msg string) {
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
+}
+
+func logError(msg string) {
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
@@ -0,0 +1,146 @@
+package fwddecl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpportunitiesAndPlan(t *testing.T) {
+	tempDir := t.TempDir()
+	sources := map[string]string{
+		"canvas.h": `#pragma once
+namespace gfx {
+class Canvas {
+public:
+    void draw();
+};
+}
+`,
+		"point.h": `#pragma once
+struct Point { int x, y; };
+`,
+		"color.h": `#pragma once
+struct Color { int rgb; };
+enum Palette { DARK, LIGHT };
+`,
+		"widget.h": `#pragma once
+#include "canvas.h"
+#include "point.h"
+#include "color.h"
+class Widget {
+public:
+    void paint(gfx::Canvas *canvas, const Color &color);
+    Point origin;
+    Palette palette;
+};
+`,
+		"shape.h": `#pragma once
+#include "point.h"
+struct Shape {
+    const Point *points;
+    int first() const { return points->x; }
+};
+`,
+		"main.cpp": `#include "widget.h"
+void Widget::paint(gfx::Canvas *canvas, const Color &color) { canvas->draw(); }
+`,
+	}
+	var files []*file
+	for name, content := range sources {
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		parsed, err := parseFile(path)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", name, err)
+		}
+		files = append(files, parsed)
+	}
+
+	g := newGraph(files, nil)
+	opportunities := g.Opportunities()
+	// point.h is needed by value in widget.h and for member access in
+	// shape.h, color.h for Palette
+	if len(opportunities) != 1 {
+		t.Fatalf("Expected 1 opportunity, got %+v", opportunities)
+	}
+	opportunity := opportunities[0]
+	if filepath.Base(opportunity.Header) != "widget.h" || opportunity.Line != 2 || opportunity.Spelled != "canvas.h" {
+		t.Errorf("Unexpected opportunity: %+v", opportunity)
+	}
+	if len(opportunity.Types) != 1 || opportunity.Types[0].Declaration() != "namespace gfx { class Canvas; }" {
+		t.Errorf("Unexpected types: %+v", opportunity.Types)
+	}
+
+	edits := g.Plan(opportunities)
+	if len(edits) != 1 || filepath.Base(edits[0].File) != "main.cpp" || edits[0].After != 1 || edits[0].Spelled != "canvas.h" {
+		t.Errorf("Unexpected edits: %+v", edits)
+	}
+}
+
+func TestUsedIndirectly(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"struct Node; Node *next; const Node &first();", true},
+		{"Node *const head;", true},
+		{"Node value;", false},
+		{"Node *head; int size() { return head->size; }", false},
+		{"Node::Kind kind;", false},
+		{"std::vector<Node> nodes;", false},
+	}
+	for _, test := range tests {
+		if got := usedIndirectly(test.code, "Node"); got != test.want {
+			t.Errorf("usedIndirectly(%q) = %v, want %v", test.code, got, test.want)
+		}
+	}
+}
+
+func TestMissingIncludes(t *testing.T) {
+	tempDir := t.TempDir()
+	sources := map[string]string{
+		"point.h": `#pragma once
+struct Point { int x, y; };
+typedef struct { int r, g, b; } color_t;
+`,
+		"line.h": `#pragma once
+struct Line {
+    Point from, to;
+};
+`,
+		"path.h": `#pragma once
+#include "point.h"
+struct Path { Point *points; color_t color; };
+`,
+		"brush.h": `#pragma once
+#define FILL(p) fill((color_t *)(p))
+struct Point;
+struct Brush {
+    const Point *at;
+    struct Line *stroke;
+};
+`,
+	}
+	var paths []string
+	for name, content := range sources {
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		paths = append(paths, path)
+	}
+
+	// path.h has what it uses through point.h, brush.h only forward declares
+	// or names types in macros
+	missing := MissingIncludes(paths, nil)
+	if len(missing) != 1 {
+		t.Fatalf("Expected 1 missing type, got %+v", missing)
+	}
+	if filepath.Base(missing[0].Header) != "line.h" || missing[0].Line != 3 || missing[0].Name != "Point" ||
+		len(missing[0].DeclaredIn) != 1 || filepath.Base(missing[0].DeclaredIn[0]) != "point.h" {
+		t.Errorf("Unexpected missing type: %+v", missing[0])
+	}
+}
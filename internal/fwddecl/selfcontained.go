@@ -0,0 +1,124 @@
+package fwddecl
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vitruves/gop/internal/utils"
+)
+
+// Missing is a type a header uses that neither it nor the files it includes
+// declare, so the header only compiles after one of DeclaredIn.
+type Missing struct {
+	Header     string
+	Line       int
+	Column     int
+	Name       string
+	DeclaredIn []string
+}
+
+var (
+	directiveRegex = regexp.MustCompile(`(?m)^[ \t]*#(?:.*\\\r?\n)*.*$`)
+	forwardRegex   = regexp.MustCompile(`\b(?:class|struct|union|enum(?:\s+class)?)\s+(\w+)\s*;`)
+)
+
+// MissingIncludes finds, for every header among paths, the types it names
+// that are declared by other scanned headers but by nothing it reaches
+// through its own includes. Names in macro bodies, forward declared by the
+// header, or spelled with struct, class or union behind a pointer or
+// reference are not reported, since they need no declaration yet.
+func MissingIncludes(paths []string, includeDirs []string) []Missing {
+	var files []*file
+	for _, path := range paths {
+		parsed, err := parseFile(path)
+		if err != nil {
+			continue
+		}
+		files = append(files, parsed)
+	}
+	if len(includeDirs) == 0 {
+		includeDirs = []string{"."}
+	}
+	g := newGraph(files, includeDirs)
+
+	declaredBy := make(map[string][]string)
+	for _, path := range g.order {
+		if !headerExtensions[strings.ToLower(filepath.Ext(path))] {
+			continue
+		}
+		for name := range g.files[path].types {
+			declaredBy[name] = append(declaredBy[name], path)
+		}
+	}
+	names := make([]string, 0, len(declaredBy))
+	for name := range declaredBy {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result []Missing
+	for _, path := range g.order {
+		if !headerExtensions[strings.ToLower(filepath.Ext(path))] {
+			continue
+		}
+		header := g.files[path]
+
+		visible := make(map[string]bool)
+		for reached := range g.reach(path) {
+			for name := range g.files[reached].types {
+				visible[name] = true
+			}
+			for name := range g.files[reached].names {
+				visible[name] = true
+			}
+		}
+		code := directiveRegex.ReplaceAllStringFunc(header.code, func(directive string) string {
+			return strings.Map(func(r rune) rune {
+				if r == '\n' {
+					return r
+				}
+				return ' '
+			}, directive)
+		})
+		for _, match := range forwardRegex.FindAllStringSubmatch(code, -1) {
+			visible[match[1]] = true
+		}
+
+		var index utils.LineIndex
+		for _, name := range names {
+			if visible[name] || !header.idents[name] {
+				continue
+			}
+			offset := firstUse(code, name)
+			if offset < 0 {
+				continue
+			}
+			if index == nil {
+				index = utils.NewLineIndex(code)
+			}
+			line, column := index.Position(offset)
+			result = append(result, Missing{Header: path, Line: line, Column: column, Name: name, DeclaredIn: declaredBy[name]})
+		}
+	}
+	return result
+}
+
+// firstUse returns the offset of the first use of name in code that needs a
+// declaration, -1 when there is none.
+func firstUse(code, name string) int {
+	regex := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+	for _, loc := range regex.FindAllStringIndex(code, -1) {
+		before := strings.TrimRight(code[:loc[0]], " \t\r\n")
+		if strings.HasSuffix(before, ".") || strings.HasSuffix(before, "->") {
+			continue
+		}
+		after := code[loc[1]:]
+		if keywordBefore.MatchString(code[:loc[0]]) && (pointerRegex.MatchString(after) || strings.HasPrefix(strings.TrimLeft(after, " \t\r\n"), ";")) {
+			continue
+		}
+		return loc[0]
+	}
+	return -1
+}
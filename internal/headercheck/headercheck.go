@@ -0,0 +1,400 @@
+package headercheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/fwddecl"
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/tidy"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/warnings"
+	"github.com/vitruves/gop/internal/worker"
+)
+
+type Config struct {
+	Include   []string
+	Exclude   []string
+	Recursive bool
+	Depth     int
+	Jobs      int
+	Verbose   bool
+	// IncludeDirs are the -I search paths, the current directory when empty
+	IncludeDirs []string
+	// Compile compiles every header on its own with the flags of the
+	// compilation database in BuildDir, instead of following the includes
+	Compile        bool
+	BuildDir       string
+	OutputFile     string
+	Format         string
+	SkipGenerated  bool
+	SkipVendor     bool
+	MaxFileSize    int64
+	FollowSymlinks bool
+	IncludeHidden  bool
+	NoProgress     bool
+	Manifest       *manifest.Manifest
+}
+
+const Rule = "includes/self_contained"
+
+var cFamilyExtensions = map[string]bool{
+	".c": true, ".h": true, ".cpp": true, ".cxx": true, ".cc": true,
+	".hpp": true, ".hxx": true, ".hh": true, ".h++": true, ".c++": true,
+	".inl": true, ".ipp": true, ".tpp": true,
+}
+
+var headerExtensions = map[string]bool{
+	".h": true, ".hpp": true, ".hxx": true, ".hh": true, ".h++": true,
+}
+
+func Run(config Config) error {
+	logInfo(config.Verbose, "Starting header self-sufficiency check")
+
+	if config.Format == "" {
+		config.Format = "text"
+	}
+	if config.Format != "text" && !diagnostics.IsFormat(config.Format) {
+		return fmt.Errorf("unsupported format: %s (expected text, %s)", config.Format, strings.Join(diagnostics.Formats, ", "))
+	}
+
+	opts := utils.ScanOptions{
+		Include:        config.Include,
+		Exclude:        config.Exclude,
+		Recursive:      config.Recursive,
+		Depth:          config.Depth,
+		SkipGenerated:  config.SkipGenerated,
+		SkipVendor:     config.SkipVendor,
+		MaxFileSize:    config.MaxFileSize,
+		FollowSymlinks: config.FollowSymlinks,
+		IncludeHidden:  config.IncludeHidden,
+		Verbose:        config.Verbose,
+	}
+
+	paths, err := utils.GetFilesToProcess(opts, func(path string) bool {
+		return cFamilyExtensions[strings.ToLower(filepath.Ext(path))]
+	})
+	if err != nil {
+		logError(fmt.Sprintf("Failed to collect files: %v", err))
+		return err
+	}
+
+	var headers []string
+	for _, path := range paths {
+		if headerExtensions[strings.ToLower(filepath.Ext(path))] {
+			headers = append(headers, path)
+		}
+	}
+	if len(headers) == 0 {
+		logWarning("No headers found matching criteria")
+		return nil
+	}
+
+	logInfo(config.Verbose, fmt.Sprintf("Found %d headers among %d files", len(headers), len(paths)))
+	config.Manifest.AddFiles(paths)
+
+	if !config.Compile {
+		return writeOutput(Diagnostics(fwddecl.MissingIncludes(paths, config.IncludeDirs)), config)
+	}
+
+	if config.BuildDir == "" {
+		config.BuildDir = "."
+		if _, err := os.Stat(filepath.Join("build", "compile_commands.json")); err == nil {
+			config.BuildDir = "build"
+		}
+	}
+	commands, err := tidy.LoadDatabase(filepath.Join(config.BuildDir, "compile_commands.json"))
+	if err != nil {
+		return fmt.Errorf("%w (generate it with -DCMAKE_EXPORT_COMPILE_COMMANDS=ON or bear, and point --build-dir at its directory)", err)
+	}
+	if len(commands) == 0 {
+		return fmt.Errorf("the compilation database in %s is empty", config.BuildDir)
+	}
+
+	results := worker.Run(headers, config.Jobs, progress.New("Compiling headers", len(headers), config.NoProgress), func(ctx context.Context, idx int, header string) []diagnostics.Diagnostic {
+		command := closest(header, commands)
+		findings, err := Compile(ctx, header, command)
+		if err != nil {
+			logError(fmt.Sprintf("Failed to compile %s: %v", header, err))
+			return nil
+		}
+		return findings
+	})
+
+	var findings []diagnostics.Diagnostic
+	for _, headerFindings := range results {
+		findings = append(findings, headerFindings...)
+	}
+	return writeOutput(findings, config)
+}
+
+// Diagnostics reports each missing type at its first use.
+func Diagnostics(missing []fwddecl.Missing) []diagnostics.Diagnostic {
+	var result []diagnostics.Diagnostic
+	for _, m := range missing {
+		var declaredIn []string
+		for _, path := range m.DeclaredIn {
+			declaredIn = append(declaredIn, utils.DisplayPath(path))
+		}
+		result = append(result, diagnostics.Diagnostic{
+			File:     m.Header,
+			Line:     m.Line,
+			Column:   m.Column,
+			Severity: diagnostics.SeverityWarning,
+			Rule:     Rule,
+			Message: fmt.Sprintf("%s is declared neither here nor in an included header, the header only compiles after %s",
+				m.Name, strings.Join(declaredIn, " or ")),
+		})
+	}
+	return result
+}
+
+// Compile checks that header compiles on its own, as the only include of a
+// file compiled with the flags of command. The errors reported in the header
+// are returned, or the first error at its first line when it fails in a
+// header it includes.
+func Compile(ctx context.Context, header string, command tidy.CompileCommand) ([]diagnostics.Diagnostic, error) {
+	args := command.Arguments
+	if len(args) == 0 {
+		args = splitCommand(command.Command)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no command line for %s in the compilation database", command.File)
+	}
+	absHeader, err := filepath.Abs(header)
+	if err != nil {
+		return nil, err
+	}
+
+	tempDir, err := os.MkdirTemp("", "gop-header-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	// The header is compiled in the language of the file it borrows flags from
+	source := filepath.Join(tempDir, "header.cpp")
+	if strings.ToLower(filepath.Ext(command.File)) == ".c" {
+		source = filepath.Join(tempDir, "header.c")
+	}
+	if err := os.WriteFile(source, []byte(fmt.Sprintf("#include \"%s\"\n", filepath.ToSlash(absHeader))), 0644); err != nil {
+		return nil, err
+	}
+
+	run := exec.CommandContext(ctx, args[0], append(compileFlags(args[1:], command.File), "-fsyntax-only", source)...)
+	run.Dir = command.Directory
+	var output bytes.Buffer
+	run.Stdout = &output
+	run.Stderr = &output
+	runErr := run.Run()
+	if runErr == nil {
+		return nil, nil
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(runErr, &exitErr) {
+		return nil, runErr
+	}
+
+	parsed, err := warnings.Parse(bytes.NewReader(output.Bytes()), "")
+	if err != nil {
+		return nil, err
+	}
+	var findings []diagnostics.Diagnostic
+	first := ""
+	for _, finding := range parsed {
+		if finding.Severity != diagnostics.SeverityError {
+			continue
+		}
+		if first == "" {
+			first = finding.Message
+		}
+		file := finding.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(command.Directory, file)
+		}
+		if filepath.Clean(file) != absHeader {
+			continue
+		}
+		finding.File = header
+		finding.Rule = Rule
+		finding.Message = "does not compile on its own: " + finding.Message
+		findings = append(findings, finding)
+	}
+
+	if len(findings) == 0 {
+		if first == "" {
+			first, _, _ = strings.Cut(strings.TrimSpace(output.String()), "\n")
+		}
+		findings = append(findings, diagnostics.Diagnostic{
+			File:     header,
+			Line:     1,
+			Column:   1,
+			Severity: diagnostics.SeverityError,
+			Rule:     Rule,
+			Message:  "does not compile on its own: " + first,
+		})
+	}
+	return findings, nil
+}
+
+// closest picks the command of the database entry nearest to header, the
+// one sharing the most leading directories with it and then the fewest
+// others.
+func closest(header string, commands []tidy.CompileCommand) tidy.CompileCommand {
+	absHeader, _ := filepath.Abs(header)
+	headerDirs := strings.Split(filepath.ToSlash(filepath.Dir(absHeader)), "/")
+
+	best, bestShared, bestDepth := commands[0], -1, 0
+	for _, command := range commands {
+		file := command.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(command.Directory, file)
+		}
+		dirs := strings.Split(filepath.ToSlash(filepath.Dir(filepath.Clean(file))), "/")
+		shared := 0
+		for shared < len(dirs) && shared < len(headerDirs) && dirs[shared] == headerDirs[shared] {
+			shared++
+		}
+		if shared > bestShared || shared == bestShared && len(dirs) < bestDepth {
+			best, bestShared, bestDepth = command, shared, len(dirs)
+		}
+	}
+	return best
+}
+
+// compileFlags keeps the flags of a compile command that affect parsing,
+// dropping the source file, the output and dependency file options, and -c.
+func compileFlags(args []string, file string) []string {
+	var flags []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-c" || arg == "-MD" || arg == "-MMD" || arg == "-M" || arg == "-MM":
+		case arg == "-o" || arg == "-MF" || arg == "-MT" || arg == "-MQ":
+			i++
+		case strings.HasPrefix(arg, "-o"):
+		case !strings.HasPrefix(arg, "-") && (arg == file || filepath.Base(arg) == filepath.Base(file)):
+		default:
+			flags = append(flags, arg)
+		}
+	}
+	return flags
+}
+
+// splitCommand splits a command line into arguments, honoring quotes and
+// backslash escapes as a POSIX shell does.
+func splitCommand(command string) []string {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+func writeOutput(findings []diagnostics.Diagnostic, config Config) error {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	findings = diagnostics.DisplayPaths(diagnostics.Filtered(findings))
+	var sb strings.Builder
+
+	if config.Format == "text" {
+		if len(findings) == 0 {
+			logSuccess("All headers are self-contained")
+			return nil
+		}
+
+		for _, finding := range findings {
+			sb.WriteString(fmt.Sprintf("%s:%d:%d: %s: %s\n", finding.File, finding.Line, finding.Column, finding.Severity, finding.Message))
+		}
+	} else if err := diagnostics.WriteReport(&sb, config.Format, findings); err != nil {
+		return err
+	}
+	output := []byte(sb.String())
+
+	if config.OutputFile != "" {
+		config.Manifest.AddResult(config.OutputFile, output)
+		if err := os.WriteFile(config.OutputFile, output, 0644); err != nil {
+			logError(fmt.Sprintf("Failed to write report: %v", err))
+			return err
+		}
+	} else {
+		config.Manifest.AddResult("stdout", output)
+		fmt.Print(string(output))
+	}
+
+	if config.Format == "text" {
+		logWarning(fmt.Sprintf("Found %d findings in headers that are not self-contained", len(findings)))
+	}
+	return nil
+}
+
+func logInfo(verbose bool, msg string) {
+	if verbose {
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
+	}
+}
+
+func logSuccess(msg string) {
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
+}
+
+func logWarning(msg string) {
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
+}
+
+func logError(msg string) {
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
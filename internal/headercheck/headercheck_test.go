@@ -0,0 +1,67 @@
+package headercheck
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vitruves/gop/internal/tidy"
+)
+
+func TestCompileFlags(t *testing.T) {
+	args := splitCommand(`/usr/bin/c++ -DNAME="a b" -I'inc dir' -MD -MF main.o.d -c src/main.cpp -o main.o -std=c++17`)
+	want := []string{"-DNAME=a b", "-Iinc dir", "-std=c++17"}
+	if got := compileFlags(args[1:], "src/main.cpp"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestClosest(t *testing.T) {
+	commands := []tidy.CompileCommand{
+		{Directory: "/work", File: "app/main.cpp"},
+		{Directory: "/work", File: "/work/lib/net/socket.cpp"},
+		{Directory: "/work/lib", File: "core.cpp"},
+	}
+	if got := closest("/work/lib/net/socket.h", commands); got.File != "/work/lib/net/socket.cpp" {
+		t.Errorf("Expected the socket.cpp entry, got %+v", got)
+	}
+	if got := closest("/work/lib/util.h", commands); got.File != "core.cpp" {
+		t.Errorf("Expected the core.cpp entry, got %+v", got)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	compiler, err := exec.LookPath("c++")
+	if err != nil {
+		t.Skip("no C++ compiler in PATH")
+	}
+
+	tempDir := t.TempDir()
+	headers := map[string]string{
+		"point.h": "#pragma once\nstruct Point { int x, y; };\n",
+		"line.h":  "#pragma once\nstruct Line {\n    Point from, to;\n};\n",
+	}
+	for name, content := range headers {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	command := tidy.CompileCommand{Directory: tempDir, File: "main.cpp", Arguments: []string{compiler, "-I.", "-c", "main.cpp"}}
+
+	findings, err := Compile(context.Background(), filepath.Join(tempDir, "point.h"), command)
+	if err != nil || len(findings) != 0 {
+		t.Errorf("point.h should compile on its own, got %+v, %v", findings, err)
+	}
+
+	findings, err = Compile(context.Background(), filepath.Join(tempDir, "line.h"), command)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) == 0 || findings[0].Line != 3 || findings[0].Rule != Rule || !strings.Contains(findings[0].Message, "Point") {
+		t.Errorf("Expected the unknown Point in line.h, got %+v", findings)
+	}
+}
@@ -0,0 +1,486 @@
+package license
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/registry"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
+)
+
+type Config struct {
+	Language       string
+	Include        []string
+	Exclude        []string
+	Recursive      bool
+	Depth          int
+	Jobs           int
+	Verbose        bool
+	HeaderFile     string
+	Author         string
+	Year           int
+	Fix            bool
+	Backup         bool
+	OutputFile     string
+	Format         string
+	SkipGenerated  bool
+	SkipVendor     bool
+	MaxFileSize    int64
+	FollowSymlinks bool
+	IncludeHidden  bool
+	NoProgress     bool
+	Manifest       *manifest.Manifest
+}
+
+const (
+	RuleMissing    = "license/missing_header"
+	RuleMismatched = "license/mismatched_header"
+)
+
+// Header is an expected license header. {{year}} in the template matches a
+// year or a range such as 2019-2024, {{author}} matches any text.
+type Header struct {
+	Lines    []string
+	matchers []*regexp.Regexp
+}
+
+var (
+	placeholderRegex   = regexp.MustCompile(`\{\{\s*(year|author)\s*\}\}`)
+	licenseHintRegex   = regexp.MustCompile(`(?i)copyright|licen[cs]e|spdx`)
+	pythonCodingRegex  = regexp.MustCompile(`^#.*coding[:=]`)
+	hashCommentExts    = map[string]bool{".py": true, ".sh": true, ".rb": true, ".pl": true, ".yaml": true, ".yml": true}
+	blockCommentedExts = map[string]bool{".c": true, ".h": true}
+)
+
+func LoadHeader(path string) (*Header, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	header := &Header{Lines: uncomment(trimBlankEdges(strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")))}
+	if len(header.Lines) == 0 {
+		return nil, fmt.Errorf("license header template %s is empty", path)
+	}
+
+	for _, line := range header.Lines {
+		var pattern strings.Builder
+		pattern.WriteString(`^`)
+		last := 0
+		for _, loc := range placeholderRegex.FindAllStringSubmatchIndex(line, -1) {
+			pattern.WriteString(regexp.QuoteMeta(line[last:loc[0]]))
+			if line[loc[2]:loc[3]] == "year" {
+				pattern.WriteString(`\d{4}(?:\s*[-,]\s*\d{4})*`)
+			} else {
+				pattern.WriteString(`.+?`)
+			}
+			last = loc[1]
+		}
+		pattern.WriteString(regexp.QuoteMeta(line[last:]))
+		pattern.WriteString(`$`)
+		header.matchers = append(header.matchers, regexp.MustCompile(pattern.String()))
+	}
+
+	return header, nil
+}
+
+// HasAuthor reports whether the template needs an author to be rendered.
+func (h *Header) HasAuthor() bool {
+	for _, line := range h.Lines {
+		for _, match := range placeholderRegex.FindAllStringSubmatch(line, -1) {
+			if match[1] == "author" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (h *Header) Render(year int, author string) []string {
+	lines := make([]string, len(h.Lines))
+	for i, line := range h.Lines {
+		lines[i] = placeholderRegex.ReplaceAllStringFunc(line, func(placeholder string) string {
+			if strings.Contains(placeholder, "year") {
+				return strconv.Itoa(year)
+			}
+			return author
+		})
+	}
+	return lines
+}
+
+// Check compares the leading comment of a file with the header. It returns
+// nil when the header is present, otherwise a finding: a mismatch when the
+// comment looks like a license notice, a missing header when it does not.
+func (h *Header) Check(filePath, content string) *diagnostics.Diagnostic {
+	comment, line := leadingComment(filePath, content)
+
+	mismatch := -1
+	for i, matcher := range h.matchers {
+		if i >= len(comment) || !matcher.MatchString(comment[i]) {
+			mismatch = i
+			break
+		}
+	}
+	if mismatch == -1 {
+		return nil
+	}
+
+	if !licenseHintRegex.MatchString(strings.Join(comment, "\n")) {
+		return &diagnostics.Diagnostic{File: filePath, Line: 1, Column: 1, Severity: diagnostics.SeverityError,
+			Rule: RuleMissing, Message: "missing license header"}
+	}
+
+	found := "end of comment"
+	if mismatch < len(comment) {
+		found = strconv.Quote(comment[mismatch])
+	}
+	return &diagnostics.Diagnostic{File: filePath, Line: line, Column: 1, Severity: diagnostics.SeverityWarning,
+		Rule: RuleMismatched, Message: fmt.Sprintf("license header does not match line %d of the template: expected %q, found %s",
+			mismatch+1, h.Lines[mismatch], found)}
+}
+
+func Run(config Config) error {
+	logInfo(config.Verbose, "Starting license header check")
+
+	header, err := LoadHeader(config.HeaderFile)
+	if err != nil {
+		return err
+	}
+	if config.Fix && config.Author == "" && header.HasAuthor() {
+		return fmt.Errorf("--author is required to insert a header with {{author}}")
+	}
+	if config.Year == 0 {
+		config.Year = time.Now().Year()
+	}
+	if config.Format == "" {
+		config.Format = "text"
+	}
+	if config.Format != "text" && !diagnostics.IsFormat(config.Format) {
+		return fmt.Errorf("unsupported format: %s (expected text, %s)", config.Format, strings.Join(diagnostics.Formats, ", "))
+	}
+
+	extensions := registry.GetParser(config.Language).GetExtensions()
+	opts := utils.ScanOptions{
+		Include:        config.Include,
+		Exclude:        config.Exclude,
+		Recursive:      config.Recursive,
+		Depth:          config.Depth,
+		SkipGenerated:  config.SkipGenerated,
+		SkipVendor:     config.SkipVendor,
+		MaxFileSize:    config.MaxFileSize,
+		FollowSymlinks: config.FollowSymlinks,
+		IncludeHidden:  config.IncludeHidden,
+		Verbose:        config.Verbose,
+	}
+
+	files, err := utils.GetFilesToProcess(opts, func(path string) bool {
+		ext := filepath.Ext(path)
+		for _, validExt := range extensions {
+			if ext == validExt {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		logError(fmt.Sprintf("Failed to collect files: %v", err))
+		return err
+	}
+
+	if len(files) == 0 {
+		logWarning("No files found matching criteria")
+		return nil
+	}
+
+	logInfo(config.Verbose, fmt.Sprintf("Found %d files to check", len(files)))
+	config.Manifest.AddFiles(files)
+
+	results := worker.Run(files, config.Jobs, progress.New("Checking license headers", len(files), config.NoProgress), func(ctx context.Context, idx int, filePath string) *diagnostics.Diagnostic {
+		content, err := utils.ReadSourceFile(filePath)
+		if err != nil {
+			failures.Record(filePath, err)
+			return nil
+		}
+		return header.Check(filePath, content)
+	})
+
+	// Headers are inserted once the check is over, so that a file whose
+	// check timed out is never written
+	var findings []diagnostics.Diagnostic
+	inserted := 0
+	for idx, finding := range results {
+		if finding == nil {
+			continue
+		}
+		if finding.Rule == RuleMissing && config.Fix {
+			if err := InsertHeader(files[idx], header.Render(config.Year, config.Author), config.Backup); err != nil {
+				logError(fmt.Sprintf("Failed to insert header into %s: %v", files[idx], err))
+			} else {
+				inserted++
+				logInfo(config.Verbose, fmt.Sprintf("Inserted license header into %s", files[idx]))
+				continue
+			}
+		}
+		findings = append(findings, *finding)
+	}
+	if config.Fix {
+		logSuccess(fmt.Sprintf("Inserted license headers into %d files", inserted))
+	}
+
+	findings = diagnostics.Filtered(findings)
+	if err := writeOutput(findings, config); err != nil {
+		return err
+	}
+
+	if len(findings) > 0 {
+		return fmt.Errorf("%d of %d files lack the expected license header", len(findings), len(files))
+	}
+	logSuccess(fmt.Sprintf("All %d files carry the license header", len(files)))
+	return nil
+}
+
+// leadingComment returns the text of the first comment of a file, after a
+// shebang or Python coding line, with comment markers stripped, and the line
+// it starts on.
+func leadingComment(filePath, content string) ([]string, int) {
+	content = strings.TrimPrefix(content, "\ufeff")
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	start := prologueLines(lines)
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	if start == len(lines) {
+		return nil, 1
+	}
+
+	var comment []string
+	first := strings.TrimSpace(lines[start])
+
+	switch {
+	case hashCommentExts[filepath.Ext(filePath)]:
+		for _, line := range lines[start:] {
+			trimmed := strings.TrimSpace(line)
+			if !strings.HasPrefix(trimmed, "#") {
+				break
+			}
+			comment = append(comment, strings.TrimSpace(strings.TrimLeft(trimmed, "#")))
+		}
+	case strings.HasPrefix(first, "/*"):
+		for _, line := range lines[start:] {
+			trimmed := strings.TrimSpace(line)
+			end := strings.Contains(trimmed, "*/")
+			trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "/*"))
+			trimmed = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(strings.Split(trimmed, "*/")[0]), "*"))
+			trimmed = strings.TrimSpace(strings.TrimLeft(trimmed, "*"))
+			comment = append(comment, trimmed)
+			if end {
+				break
+			}
+		}
+	case strings.HasPrefix(first, "//"):
+		for _, line := range lines[start:] {
+			trimmed := strings.TrimSpace(line)
+			if !strings.HasPrefix(trimmed, "//") {
+				break
+			}
+			comment = append(comment, strings.TrimSpace(strings.TrimLeft(trimmed, "/!")))
+		}
+	}
+
+	return trimBlankEdges(comment), start + 1
+}
+
+// uncomment strips the markers of a template written as a comment, so that
+// it is wrapped and compared like a bare one.
+func uncomment(lines []string) []string {
+	if len(lines) == 0 {
+		return lines
+	}
+
+	text := strings.Join(lines, "\n")
+	switch {
+	case strings.HasPrefix(lines[0], "/*") && strings.HasSuffix(lines[len(lines)-1], "*/"):
+		comment, _ := leadingComment("", text)
+		return comment
+	case allPrefixed(lines, "//"):
+		comment, _ := leadingComment("", text)
+		return comment
+	case allPrefixed(lines, "#") && !strings.HasPrefix(lines[0], "#!"):
+		comment, _ := leadingComment(".sh", text)
+		return comment
+	}
+	return lines
+}
+
+func allPrefixed(lines []string, prefix string) bool {
+	for _, line := range lines {
+		if !strings.HasPrefix(line, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// prologueLines counts the lines that must stay above a license header.
+func prologueLines(lines []string) int {
+	n := 0
+	if n < len(lines) && strings.HasPrefix(lines[n], "#!") {
+		n++
+	}
+	if n < len(lines) && pythonCodingRegex.MatchString(lines[n]) {
+		n++
+	}
+	return n
+}
+
+// InsertHeader writes the header as a comment at the top of the file, below
+// a shebang or coding line, keeping a .bak copy when asked. Only UTF-8 files
+// are rewritten so their bytes are otherwise left untouched.
+func InsertHeader(filePath string, header []string, backup bool) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	content, encoding := utils.DecodeText(data)
+	if encoding != utils.EncodingUTF8 && encoding != utils.EncodingUTF8BOM {
+		return fmt.Errorf("%s files are not rewritten", encoding)
+	}
+	bom := len(data) - len(content)
+
+	newline := "\n"
+	if strings.Contains(content, "\r\n") {
+		newline = "\r\n"
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	offset := 0
+	for _, line := range lines[:prologueLines(strings.Split(content, "\n"))] {
+		offset += len(line)
+	}
+
+	comment := strings.Join(commentLines(filePath, header), newline) + newline
+	rest := content[offset:]
+	if strings.TrimSpace(strings.SplitN(rest, "\n", 2)[0]) != "" {
+		comment += newline
+	}
+
+	if backup {
+		if err := os.WriteFile(filePath+".bak", data, 0644); err != nil {
+			return err
+		}
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	updated := string(data[:bom]) + content[:offset] + comment + rest
+	return os.WriteFile(filePath, []byte(updated), info.Mode().Perm())
+}
+
+// commentLines formats the header in the comment style of the file: # for
+// scripts, a block comment for C and // for the other languages.
+func commentLines(filePath string, header []string) []string {
+	ext := filepath.Ext(filePath)
+	var lines []string
+
+	switch {
+	case hashCommentExts[ext]:
+		for _, line := range header {
+			lines = append(lines, strings.TrimRight("# "+line, " "))
+		}
+	case blockCommentedExts[ext]:
+		lines = append(lines, "/*")
+		for _, line := range header {
+			lines = append(lines, strings.TrimRight(" * "+line, " "))
+		}
+		lines = append(lines, " */")
+	default:
+		for _, line := range header {
+			lines = append(lines, strings.TrimRight("// "+line, " "))
+		}
+	}
+
+	return lines
+}
+
+func trimBlankEdges(lines []string) []string {
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func writeOutput(findings []diagnostics.Diagnostic, config Config) error {
+	findings = diagnostics.DisplayPaths(findings)
+	var sb strings.Builder
+
+	if config.Format == "text" {
+		if len(findings) == 0 {
+			return nil
+		}
+
+		for _, finding := range findings {
+			sb.WriteString(fmt.Sprintf("%s:%d:%d: %s\n", finding.File, finding.Line, finding.Column, finding.Message))
+		}
+	} else if err := diagnostics.WriteReport(&sb, config.Format, findings); err != nil {
+		return err
+	}
+	output := []byte(sb.String())
+
+	if config.OutputFile != "" {
+		config.Manifest.AddResult(config.OutputFile, output)
+		if err := os.WriteFile(config.OutputFile, output, 0644); err != nil {
+			logError(fmt.Sprintf("Failed to write report: %v", err))
+			return err
+		}
+	} else {
+		config.Manifest.AddResult("stdout", output)
+		fmt.Print(string(output))
+	}
+
+	return nil
+}
+
+func logInfo(verbose bool, msg string) {
+	if verbose {
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
+	}
+}
+
+func logSuccess(msg string) {
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
+}
+
+func logWarning(msg string) {
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
+}
+
+func logError(msg string) {
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
@@ -0,0 +1,128 @@
+package license
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func loadTestHeader(t *testing.T) *Header {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "HEADER")
+	if err := os.WriteFile(path, []byte("Copyright {{year}} {{author}}\nSPDX-License-Identifier: MIT\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	header, err := LoadHeader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return header
+}
+
+func TestCheck(t *testing.T) {
+	header := loadTestHeader(t)
+
+	tests := []struct {
+		file    string
+		content string
+		rule    string
+	}{
+		{"a.go", "// Copyright 2019-2024 Jane Doe\n// SPDX-License-Identifier: MIT\n\npackage a\n", ""},
+		{"a.c", "/*\n * Copyright 2024 Jane Doe\n * SPDX-License-Identifier: MIT\n */\nint x;\n", ""},
+		{"a.py", "#!/usr/bin/env python\n# -*- coding: utf-8 -*-\n# Copyright 2024 Jane Doe\n# SPDX-License-Identifier: MIT\n", ""},
+		{"a.go", "// Package a does things.\npackage a\n", RuleMissing},
+		{"a.go", "package a\n", RuleMissing},
+		{"a.go", "// Copyright 2024 Jane Doe\n// SPDX-License-Identifier: Apache-2.0\npackage a\n", RuleMismatched},
+		{"a.go", "// Copyright Jane Doe\npackage a\n", RuleMismatched},
+	}
+
+	for _, test := range tests {
+		finding := header.Check(test.file, test.content)
+		rule := ""
+		if finding != nil {
+			rule = finding.Rule
+		}
+		if rule != test.rule {
+			t.Errorf("Check(%s, %q) = %q, want %q", test.file, test.content, rule, test.rule)
+		}
+	}
+}
+
+func TestInsertHeader(t *testing.T) {
+	header := loadTestHeader(t)
+	dir := t.TempDir()
+
+	tests := []struct {
+		file     string
+		content  string
+		expected string
+	}{
+		{"a.go", "package a\n", "// Copyright 2024 Jane Doe\n// SPDX-License-Identifier: MIT\n\npackage a\n"},
+		{"a.c", "int x;\r\n", "/*\r\n * Copyright 2024 Jane Doe\r\n * SPDX-License-Identifier: MIT\r\n */\r\n\r\nint x;\r\n"},
+		{"a.py", "#!/usr/bin/env python\nprint(1)\n", "#!/usr/bin/env python\n# Copyright 2024 Jane Doe\n# SPDX-License-Identifier: MIT\n\nprint(1)\n"},
+		{"b.go", "\ufeffpackage b\n", "\ufeff// Copyright 2024 Jane Doe\n// SPDX-License-Identifier: MIT\n\npackage b\n"},
+	}
+
+	for _, test := range tests {
+		path := filepath.Join(dir, test.file)
+		if err := os.WriteFile(path, []byte(test.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := InsertHeader(path, header.Render(2024, "Jane Doe"), true); err != nil {
+			t.Fatalf("InsertHeader(%s): %v", test.file, err)
+		}
+
+		data, _ := os.ReadFile(path)
+		if string(data) != test.expected {
+			t.Errorf("InsertHeader(%s) wrote %q, want %q", test.file, data, test.expected)
+		}
+		if backup, _ := os.ReadFile(path + ".bak"); string(backup) != test.content {
+			t.Errorf("backup of %s is %q, want %q", test.file, backup, test.content)
+		}
+		if header.Check(path, string(data)) != nil {
+			t.Errorf("inserted header in %s is not recognized", test.file)
+		}
+	}
+}
+
+func TestCommentedTemplate(t *testing.T) {
+	dir := t.TempDir()
+
+	templates := map[string]string{
+		"block": "/*\n * Copyright {{year}} {{author}}\n * SPDX-License-Identifier: MIT\n */\n",
+		"line":  "// Copyright {{year}} {{author}}\n// SPDX-License-Identifier: MIT\n",
+		"hash":  "# Copyright {{year}} {{author}}\n# SPDX-License-Identifier: MIT\n",
+	}
+	for name, template := range templates {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(template), 0644); err != nil {
+			t.Fatal(err)
+		}
+		header, err := LoadHeader(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(header.Lines) != 2 || header.Lines[0] != "Copyright {{year}} {{author}}" {
+			t.Errorf("%s template: expected the comment markers stripped, got %q", name, header.Lines)
+		}
+
+		// A file carrying the template as written matches it
+		if finding := header.Check("a.c", "/*\n * Copyright 2024 Jane Doe\n * SPDX-License-Identifier: MIT\n */\nint x;\n"); finding != nil {
+			t.Errorf("%s template: expected the header to match, got %s", name, finding.Message)
+		}
+
+		// --fix wraps it once
+		file := filepath.Join(dir, name+".c")
+		if err := os.WriteFile(file, []byte("int x;\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := InsertHeader(file, header.Render(2024, "Jane Doe"), false); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := os.ReadFile(file)
+		expected := "/*\n * Copyright 2024 Jane Doe\n * SPDX-License-Identifier: MIT\n */\n\nint x;\n"
+		if string(data) != expected {
+			t.Errorf("%s template: InsertHeader wrote %q, want %q", name, data, expected)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/registry"
+)
+
+type Config struct {
+	// Diagnose returns the findings for one file, run when it is opened or saved
+	Diagnose func(path string) []diagnostics.Diagnostic
+	// Parser returns the registry parser for a file, nil when symbols are not supported
+	Parser  func(path string) registry.LanguageParser
+	Version string
+	// Log receives protocol errors, the client owns stdout
+	Log io.Writer
+}
+
+// Server speaks the Language Server Protocol over a pair of streams, usually
+// stdin and stdout of an editor-spawned process.
+type Server struct {
+	config   Config
+	in       *bufio.Reader
+	out      io.Writer
+	mu       sync.Mutex
+	shutdown bool
+}
+
+type request struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  any              `json:"result"`
+}
+
+// errorResponse has no result member, the protocol forbids both being set.
+type errorResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Error   responseError    `json:"error"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+const (
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInvalidRequest = -32600
+)
+
+func NewServer(in io.Reader, out io.Writer, config Config) *Server {
+	if config.Log == nil {
+		config.Log = io.Discard
+	}
+	return &Server{config: config, in: bufio.NewReader(in), out: out}
+}
+
+// Serve handles messages until the client sends exit or closes the stream.
+func (s *Server) Serve() error {
+	for {
+		body, err := s.readMessage()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			fmt.Fprintf(s.config.Log, "invalid message: %v\n", err)
+			continue
+		}
+
+		if req.Method == "exit" {
+			return nil
+		}
+		s.handle(req)
+	}
+}
+
+func (s *Server) handle(req request) {
+	if s.shutdown && req.ID != nil {
+		s.replyError(req.ID, codeInvalidRequest, "server is shutting down")
+		return
+	}
+
+	switch req.Method {
+	case "initialize":
+		s.reply(req.ID, map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync": map[string]any{
+					"openClose": true,
+					"save":      map[string]any{"includeText": false},
+				},
+				"documentSymbolProvider": true,
+			},
+			"serverInfo": map[string]any{"name": "gop", "version": s.config.Version},
+		})
+	case "shutdown":
+		s.shutdown = true
+		s.reply(req.ID, nil)
+	case "textDocument/didOpen", "textDocument/didSave":
+		if uri, ok := documentURI(req.Params); ok {
+			s.publishDiagnostics(uri)
+		}
+	case "textDocument/didClose":
+		if uri, ok := documentURI(req.Params); ok {
+			s.notify("textDocument/publishDiagnostics", map[string]any{"uri": uri, "diagnostics": []any{}})
+		}
+	case "textDocument/documentSymbol":
+		uri, ok := documentURI(req.Params)
+		if !ok {
+			s.replyError(req.ID, codeInvalidParams, "missing textDocument.uri")
+			return
+		}
+		s.reply(req.ID, s.documentSymbols(uri))
+	default:
+		// Notifications the server does not care about are ignored
+		if req.ID != nil {
+			s.replyError(req.ID, codeMethodNotFound, "method not supported: "+req.Method)
+		}
+	}
+}
+
+func (s *Server) publishDiagnostics(uri string) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		fmt.Fprintf(s.config.Log, "%v\n", err)
+		return
+	}
+
+	items := []map[string]any{}
+	if s.config.Diagnose != nil {
+		for _, d := range s.config.Diagnose(path) {
+			items = append(items, map[string]any{
+				"range":    lineRange(d.Line, d.Column),
+				"severity": severityLevel(d.Severity),
+				"code":     d.Rule,
+				"source":   "gop",
+				"message":  d.Message,
+			})
+		}
+	}
+
+	s.notify("textDocument/publishDiagnostics", map[string]any{"uri": uri, "diagnostics": items})
+}
+
+// LSP symbol kinds used for registry functions and members.
+var symbolKinds = map[string]int{
+	"namespace":  3,
+	"method":     6,
+	"field":      8,
+	"enum":       10,
+	"enumerator": 22,
+	"interface":  11,
+	"trait":      11,
+	"function":   12,
+	"struct":     23,
+	"union":      23,
+	"class":      5,
+	"type":       5,
+}
+
+func (s *Server) documentSymbols(uri string) []map[string]any {
+	symbols := []map[string]any{}
+
+	path, err := uriToPath(uri)
+	if err != nil || s.config.Parser == nil {
+		return symbols
+	}
+	parser := s.config.Parser(path)
+	if parser == nil {
+		return symbols
+	}
+
+	if memberParser, ok := parser.(registry.MemberParser); ok {
+		members, err := memberParser.ParseMembers(path)
+		if err == nil {
+			for _, member := range members {
+				kind, ok := symbolKinds[member.Kind]
+				if !ok {
+					kind = symbolKinds["type"]
+				}
+				symbols = append(symbols, symbol(member.Name, kind, member.Scope, uri, member.Line))
+			}
+		}
+	}
+
+	functions, err := parser.ParseFile(path)
+	if err != nil {
+		fmt.Fprintf(s.config.Log, "parsing %s: %v\n", path, err)
+		return symbols
+	}
+	for _, fn := range functions {
+		kind := symbolKinds["function"]
+		if fn.Scope != "" {
+			kind = symbolKinds["method"]
+		}
+		symbols = append(symbols, symbol(fn.Name, kind, fn.Scope, uri, fn.Line))
+	}
+
+	return symbols
+}
+
+func symbol(name string, kind int, container, uri string, line int) map[string]any {
+	// Parsers qualify methods with their scope, editors show the container separately
+	if container != "" {
+		for _, separator := range []string{"::", "."} {
+			name = strings.TrimPrefix(name, container+separator)
+		}
+	}
+
+	sym := map[string]any{
+		"name":     name,
+		"kind":     kind,
+		"location": map[string]any{"uri": uri, "range": lineRange(line, 1)},
+	}
+	if container != "" {
+		sym["containerName"] = container
+	}
+	return sym
+}
+
+// lineRange covers the rest of a 1-based line from a 1-based column, LSP
+// positions are 0-based and the range ends at the start of the next line.
+func lineRange(line, column int) map[string]any {
+	line = max(line-1, 0)
+	column = max(column-1, 0)
+	return map[string]any{
+		"start": map[string]int{"line": line, "character": column},
+		"end":   map[string]int{"line": line + 1, "character": 0},
+	}
+}
+
+func severityLevel(severity diagnostics.Severity) int {
+	switch severity {
+	case diagnostics.SeverityError:
+		return 1
+	case diagnostics.SeverityWarning:
+		return 2
+	default:
+		return 4 // Hint, placeholders are too frequent to be shown as information
+	}
+}
+
+func documentURI(params json.RawMessage) (string, bool) {
+	var p struct {
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil || p.TextDocument.URI == "" {
+		return "", false
+	}
+	return p.TextDocument.URI, true
+}
+
+func uriToPath(uri string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "file" {
+		return "", fmt.Errorf("unsupported document URI: %s", uri)
+	}
+	return filepath.FromSlash(parsed.Path), nil
+}
+
+// readMessage reads one base protocol message: headers, a blank line and a
+// body of Content-Length bytes.
+func (s *Server) readMessage() ([]byte, error) {
+	length := -1
+	for {
+		line, err := s.in.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %s", value)
+			}
+		}
+	}
+
+	if length < 0 {
+		return nil, fmt.Errorf("message without Content-Length")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (s *Server) write(message any) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		fmt.Fprintf(s.config.Log, "encoding message: %v\n", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (s *Server) reply(id *json.RawMessage, result any) {
+	s.write(response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *Server) replyError(id *json.RawMessage, code int, message string) {
+	s.write(errorResponse{JSONRPC: "2.0", ID: id, Error: responseError{Code: code, Message: message}})
+}
+
+func (s *Server) notify(method string, params any) {
+	s.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
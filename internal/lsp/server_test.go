@@ -0,0 +1,99 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/registry"
+)
+
+func frame(message string) string {
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(message), message)
+}
+
+func readMessages(t *testing.T, output []byte) []map[string]any {
+	t.Helper()
+
+	var messages []map[string]any
+	reader := &Server{in: bufio.NewReader(bytes.NewReader(output))}
+	for {
+		body, err := reader.readMessage()
+		if err != nil {
+			break
+		}
+		var message map[string]any
+		if err := json.Unmarshal(body, &message); err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+func TestServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.py")
+	if err := os.WriteFile(path, []byte("class Shape:\n    def area(self):\n        pass\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	uri := "file://" + filepath.ToSlash(path)
+
+	input := strings.Join([]string{
+		frame(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`),
+		frame(`{"jsonrpc":"2.0","method":"initialized","params":{}}`),
+		frame(`{"jsonrpc":"2.0","method":"textDocument/didSave","params":{"textDocument":{"uri":"` + uri + `"}}}`),
+		frame(`{"jsonrpc":"2.0","id":2,"method":"textDocument/documentSymbol","params":{"textDocument":{"uri":"` + uri + `"}}}`),
+		frame(`{"jsonrpc":"2.0","id":3,"method":"textDocument/hover","params":{}}`),
+		frame(`{"jsonrpc":"2.0","id":4,"method":"shutdown"}`),
+		frame(`{"jsonrpc":"2.0","method":"exit"}`),
+	}, "")
+
+	var output bytes.Buffer
+	server := NewServer(strings.NewReader(input), &output, Config{
+		Diagnose: func(file string) []diagnostics.Diagnostic {
+			return []diagnostics.Diagnostic{{File: file, Line: 3, Column: 9, Severity: diagnostics.SeverityWarning, Rule: "placeholders/unimplemented", Message: "pass"}}
+		},
+		Parser: func(string) registry.LanguageParser { return registry.GetParser("python") },
+	})
+	if err := server.Serve(); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := readMessages(t, output.Bytes())
+	if len(messages) != 5 {
+		t.Fatalf("got %d messages, want 5: %s", len(messages), output.String())
+	}
+
+	capabilities := messages[0]["result"].(map[string]any)["capabilities"].(map[string]any)
+	if capabilities["documentSymbolProvider"] != true {
+		t.Errorf("document symbols not advertised: %v", capabilities)
+	}
+
+	published := messages[1]["params"].(map[string]any)
+	diagnostic := published["diagnostics"].([]any)[0].(map[string]any)
+	start := diagnostic["range"].(map[string]any)["start"].(map[string]any)
+	if published["uri"] != uri || diagnostic["severity"] != float64(2) || start["line"] != float64(2) || start["character"] != float64(8) {
+		t.Errorf("unexpected diagnostics: %v", published)
+	}
+
+	var names []string
+	for _, sym := range messages[2]["result"].([]any) {
+		names = append(names, sym.(map[string]any)["name"].(string))
+	}
+	if strings.Join(names, ",") != "Shape,area" {
+		t.Errorf("got symbols %v, want Shape and area", names)
+	}
+
+	if _, ok := messages[3]["error"]; !ok {
+		t.Errorf("unsupported request was not rejected: %v", messages[3])
+	}
+	if result, ok := messages[4]["result"]; !ok || result != nil {
+		t.Errorf("unexpected shutdown reply: %v", messages[4])
+	}
+}
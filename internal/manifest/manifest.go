@@ -0,0 +1,155 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Manifest records what a run looked at and what it produced, so two runs
+// can be compared file by file when their results differ.
+type Manifest struct {
+	Tool      string            `json:"tool"`
+	Version   string            `json:"version"`
+	Command   string            `json:"command"`
+	Args      []string          `json:"args"`
+	Options   map[string]string `json:"options"`
+	GoVersion string            `json:"go_version"`
+	Platform  string            `json:"platform"`
+	StartedAt time.Time         `json:"started_at"`
+	Duration  string            `json:"duration"`
+	FileCount int               `json:"file_count"`
+	Files     []File            `json:"files"`
+	Results   []Result          `json:"results"`
+	// Skipped are the files that could not be read or parsed
+	Skipped []Skipped `json:"skipped,omitempty"`
+
+	mu sync.Mutex
+}
+
+type File struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+type Skipped struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+type Result struct {
+	Target string `json:"target"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+func New(version, command string, args []string, options map[string]string) *Manifest {
+	return &Manifest{
+		Tool:      "gop",
+		Version:   version,
+		Command:   command,
+		Args:      args,
+		Options:   options,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		StartedAt: time.Now(),
+		Files:     []File{},
+		Results:   []Result{},
+	}
+}
+
+// AddFiles hashes the input files of the run. It is a no-op on a nil manifest
+// so callers don't need to check whether one was requested.
+func (m *Manifest) AddFiles(files []string) {
+	if m == nil {
+		return
+	}
+
+	entries := make([]File, 0, len(files))
+	for _, path := range files {
+		entry, err := hashFile(path)
+		if err != nil {
+			entry = File{Path: path}
+		}
+		entries = append(entries, entry)
+	}
+
+	m.mu.Lock()
+	m.Files = append(m.Files, entries...)
+	m.mu.Unlock()
+}
+
+// AddResult records the digest of an output, target is the output file or
+// "stdout".
+func (m *Manifest) AddResult(target string, data []byte) {
+	if m == nil {
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	m.AddResultSum(target, len(data), sum[:])
+}
+
+// AddResultSum records an output that was streamed rather than held in
+// memory, sum is its SHA-256.
+func (m *Manifest) AddResultSum(target string, size int, sum []byte) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.Results = append(m.Results, Result{Target: target, Size: size, SHA256: hex.EncodeToString(sum)})
+	m.mu.Unlock()
+}
+
+// AddSkipped records a file the run could not read or parse.
+func (m *Manifest) AddSkipped(path, err string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.Skipped = append(m.Skipped, Skipped{Path: path, Error: err})
+	m.mu.Unlock()
+}
+
+func (m *Manifest) Write(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Duration = time.Since(m.StartedAt).Round(time.Millisecond).String()
+	m.FileCount = len(m.Files)
+	sort.Slice(m.Files, func(i, j int) bool {
+		return m.Files[i].Path < m.Files[j].Path
+	})
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func hashFile(path string) (File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return File{}, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return File{}, err
+	}
+
+	return File{Path: path, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
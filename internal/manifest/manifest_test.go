@@ -0,0 +1,76 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	b := filepath.Join(dir, "b.c")
+	a := filepath.Join(dir, "a.c")
+	if err := os.WriteFile(a, []byte("int a;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("int b;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New("1.2.3", "gop stats", []string{"src"}, map[string]string{"jobs": "4"})
+	m.AddFiles([]string{b, a, filepath.Join(dir, "missing.c")})
+	m.AddResult("stats.json", []byte(`{"files":2}`))
+	m.AddSkipped("broken.c", "permission denied")
+
+	path := filepath.Join(dir, "manifest.json")
+	if err := m.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written Manifest
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatal(err)
+	}
+
+	if written.Tool != "gop" || written.Version != "1.2.3" || written.Command != "gop stats" || written.Options["jobs"] != "4" {
+		t.Errorf("Unexpected run description %s %s %s %v", written.Tool, written.Version, written.Command, written.Options)
+	}
+	if written.Duration == "" || written.GoVersion == "" || written.Platform == "" {
+		t.Errorf("Expected the duration, Go version and platform, got %q, %q and %q", written.Duration, written.GoVersion, written.Platform)
+	}
+
+	// Files are sorted by path, and those that cannot be read keep only it
+	if written.FileCount != 3 || len(written.Files) != 3 {
+		t.Fatalf("Expected 3 files, got %d: %+v", written.FileCount, written.Files)
+	}
+	sum := sha256.Sum256([]byte("int a;\n"))
+	if first := written.Files[0]; first.Path != a || first.Size != 7 || first.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("Unexpected entry for a.c: %+v", first)
+	}
+	if missing := written.Files[2]; missing.Size != 0 || missing.SHA256 != "" {
+		t.Errorf("Expected no digest for a missing file, got %+v", missing)
+	}
+
+	sum = sha256.Sum256([]byte(`{"files":2}`))
+	if len(written.Results) != 1 || written.Results[0] != (Result{Target: "stats.json", Size: 11, SHA256: hex.EncodeToString(sum[:])}) {
+		t.Errorf("Unexpected results %+v", written.Results)
+	}
+	if len(written.Skipped) != 1 || written.Skipped[0] != (Skipped{Path: "broken.c", Error: "permission denied"}) {
+		t.Errorf("Unexpected skipped files %+v", written.Skipped)
+	}
+}
+
+func TestNilManifest(t *testing.T) {
+	// Callers record into the manifest whether or not one was requested
+	var m *Manifest
+	m.AddFiles([]string{"a.c"})
+	m.AddResult("stdout", []byte("output"))
+	m.AddResultSum("stdout", 6, nil)
+	m.AddSkipped("a.c", "unreadable")
+}
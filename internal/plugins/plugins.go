@@ -0,0 +1,429 @@
+// Package plugins runs external analyzers declared in .gop.yaml and merges
+// their findings into one report.
+//
+// A plugin is any executable. gop writes a Request as JSON to its standard
+// input and reads its findings from its standard output, in any report
+// format diagnostics.Parse reads: gop's json format, SARIF, checkstyle or
+// codeclimate. Empty output means no findings. A plugin exiting with an
+// error status fails unless it wrote findings, since linters commonly exit
+// with 1 when they find something. What it writes to standard error is
+// passed through.
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/config"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/registry"
+	"github.com/vitruves/gop/internal/report"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
+)
+
+// Protocol is the version of the request, raised when a change would break
+// existing plugins.
+const Protocol = 1
+
+const defaultTimeout = 5 * time.Minute
+
+// languages are the languages gop parses, in the order files are attributed
+// to them.
+var languages = []string{"python", "rust", "go", "c", "cpp"}
+
+type Config struct {
+	Language       string
+	Include        []string
+	Exclude        []string
+	Recursive      bool
+	Depth          int
+	Jobs           int
+	Verbose        bool
+	OutputFile     string
+	Format         string
+	Template       string
+	Plugins        []config.Plugin
+	Select         []string
+	SkipGenerated  bool
+	SkipVendor     bool
+	MaxFileSize    int64
+	FollowSymlinks bool
+	IncludeHidden  bool
+	NoProgress     bool
+	Manifest       *manifest.Manifest
+}
+
+// Request is what a plugin reads on its standard input.
+type Request struct {
+	Protocol int    `json:"protocol"`
+	Plugin   string `json:"plugin"`
+	// Root is the working directory of gop and of the plugin, paths are
+	// relative to it unless given as absolute paths
+	Root  string `json:"root"`
+	Files []File `json:"files"`
+}
+
+type File struct {
+	Path     string `json:"path"`
+	Language string `json:"language"`
+	// Functions is only set for plugins asking for them
+	Functions []registry.Function `json:"functions,omitempty"`
+}
+
+func Run(config Config) error {
+	logInfo(config.Verbose, "Starting plugins")
+
+	if config.Format == "" {
+		config.Format = "text"
+	}
+	if config.Format != "text" && config.Template == "" && !diagnostics.IsFormat(config.Format) {
+		return fmt.Errorf("unsupported format: %s (expected text, %s)", config.Format, strings.Join(diagnostics.Formats, ", "))
+	}
+
+	plugins, err := selectPlugins(config.Plugins, config.Select)
+	if err != nil {
+		return err
+	}
+
+	opts := utils.ScanOptions{
+		Include:        config.Include,
+		Exclude:        config.Exclude,
+		Recursive:      config.Recursive,
+		Depth:          config.Depth,
+		SkipGenerated:  config.SkipGenerated,
+		SkipVendor:     config.SkipVendor,
+		MaxFileSize:    config.MaxFileSize,
+		FollowSymlinks: config.FollowSymlinks,
+		IncludeHidden:  config.IncludeHidden,
+		Verbose:        config.Verbose,
+	}
+	paths, err := utils.GetFilesToProcess(opts, func(path string) bool {
+		language := languageOf(path)
+		return language != "" && (config.Language == "" || language == config.Language)
+	})
+	if err != nil {
+		logError(fmt.Sprintf("Failed to collect files: %v", err))
+		return err
+	}
+
+	if len(paths) == 0 {
+		logWarning("No files found matching criteria")
+		return nil
+	}
+
+	logInfo(config.Verbose, fmt.Sprintf("Found %d files for %d plugins", len(paths), len(plugins)))
+	config.Manifest.AddFiles(paths)
+
+	files := make([]File, len(paths))
+	for i, path := range paths {
+		files[i] = File{Path: path, Language: languageOf(path)}
+	}
+
+	withFunctions := false
+	for _, plugin := range plugins {
+		withFunctions = withFunctions || plugin.Functions
+	}
+	if withFunctions {
+		parsed := worker.Run(paths, config.Jobs, progress.New("Parsing files", len(paths), config.NoProgress), func(ctx context.Context, idx int, filePath string) []registry.Function {
+			functions, err := registry.GetParser(files[idx].Language).ParseFile(filePath)
+			if err != nil {
+				failures.Record(filePath, err)
+				return nil
+			}
+			return functions
+		})
+		for idx, functions := range parsed {
+			files[idx].Functions = functions
+		}
+	}
+
+	root, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	// Plugins run outside the worker pool: their own timeout bounds them, and
+	// unlike analyses of a file they are killed when it passes
+	results := make([][]diagnostics.Diagnostic, len(plugins))
+	reporter := progress.New("Running plugins", len(plugins), config.NoProgress)
+	var mu sync.Mutex
+	var failed []string
+	var wg sync.WaitGroup
+	for idx, plugin := range plugins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer reporter.Add(plugin.Name)
+			request := Request{Protocol: Protocol, Plugin: plugin.Name, Root: root, Files: filesFor(plugin, files)}
+
+			start := time.Now()
+			findings, err := Execute(plugin, request)
+			if err != nil {
+				logError(fmt.Sprintf("Plugin %s: %v", plugin.Name, err))
+				mu.Lock()
+				failed = append(failed, plugin.Name)
+				mu.Unlock()
+				return
+			}
+			logInfo(config.Verbose, fmt.Sprintf("Plugin %s reported %d findings on %d files in %s", plugin.Name, len(findings), len(request.Files), utils.HumanDuration(time.Since(start))))
+			results[idx] = findings
+		}()
+	}
+	wg.Wait()
+	reporter.Finish()
+
+	var findings []diagnostics.Diagnostic
+	for _, pluginFindings := range results {
+		findings = append(findings, pluginFindings...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+
+	if err := writeOutput(findings, config); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("plugins failed: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// selectPlugins validates the configured plugins and returns those named in
+// selected, all of them when it is empty.
+func selectPlugins(plugins []config.Plugin, selected []string) ([]config.Plugin, error) {
+	if len(plugins) == 0 {
+		return nil, fmt.Errorf("no plugins configured in the plugins section of %s", config.FileName)
+	}
+
+	byName := make(map[string]config.Plugin)
+	var names []string
+	for i, plugin := range plugins {
+		if plugin.Name == "" {
+			return nil, fmt.Errorf("plugin %d has no name", i+1)
+		}
+		if _, ok := byName[plugin.Name]; ok {
+			return nil, fmt.Errorf("plugin %s is configured twice", plugin.Name)
+		}
+		if len(plugin.Command) == 0 {
+			return nil, fmt.Errorf("plugin %s has no command", plugin.Name)
+		}
+		if plugin.Timeout != "" {
+			if _, err := time.ParseDuration(plugin.Timeout); err != nil {
+				return nil, fmt.Errorf("plugin %s: invalid timeout: %w", plugin.Name, err)
+			}
+		}
+		for _, language := range plugin.Languages {
+			if !knownLanguage(language) {
+				return nil, fmt.Errorf("plugin %s: unknown language %s (expected %s)", plugin.Name, language, strings.Join(languages, ", "))
+			}
+		}
+		byName[plugin.Name] = plugin
+		names = append(names, plugin.Name)
+	}
+
+	if len(selected) == 0 {
+		return plugins, nil
+	}
+	var result []config.Plugin
+	for _, name := range selected {
+		plugin, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown plugin: %s (configured: %s)", name, strings.Join(names, ", "))
+		}
+		result = append(result, plugin)
+	}
+	return result, nil
+}
+
+func knownLanguage(language string) bool {
+	for _, known := range languages {
+		if language == known {
+			return true
+		}
+	}
+	return false
+}
+
+func languageOf(path string) string {
+	ext := filepath.Ext(path)
+	for _, language := range languages {
+		for _, validExt := range registry.GetParser(language).GetExtensions() {
+			if ext == validExt {
+				return language
+			}
+		}
+	}
+	return ""
+}
+
+// filesFor returns the files in the languages of a plugin, without the
+// functions unless it asked for them.
+func filesFor(plugin config.Plugin, files []File) []File {
+	result := []File{}
+	for _, file := range files {
+		if len(plugin.Languages) > 0 && !contains(plugin.Languages, file.Language) {
+			continue
+		}
+		if !plugin.Functions {
+			file.Functions = nil
+		}
+		result = append(result, file)
+	}
+	return result
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Execute runs a plugin on a request and returns its findings. Rules are
+// prefixed with the plugin name unless they already are, and findings
+// without a severity are warnings.
+func Execute(plugin config.Plugin, request Request) ([]diagnostics.Diagnostic, error) {
+	timeout := defaultTimeout
+	if plugin.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(plugin.Timeout); err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, plugin.Command[0], plugin.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = os.Stderr
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	// Children the plugin started may hold its output open after it is
+	// killed
+	cmd.WaitDelay = time.Second
+
+	runErr := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("timed out after %s", timeout)
+	}
+	output := bytes.TrimSpace(stdout.Bytes())
+	if len(output) == 0 {
+		// No findings, or no output because it failed
+		return nil, runErr
+	}
+
+	findings, err := diagnostics.Parse(output)
+	if err != nil {
+		if runErr != nil {
+			return nil, runErr
+		}
+		return nil, fmt.Errorf("unreadable output: %w", err)
+	}
+	for i := range findings {
+		if findings[i].Rule == "" {
+			findings[i].Rule = plugin.Name
+		} else if !strings.HasPrefix(findings[i].Rule, plugin.Name+"/") {
+			findings[i].Rule = plugin.Name + "/" + findings[i].Rule
+		}
+		if findings[i].Severity == "" {
+			findings[i].Severity = diagnostics.SeverityWarning
+		}
+	}
+	return findings, nil
+}
+
+func writeOutput(findings []diagnostics.Diagnostic, config Config) error {
+	findings = diagnostics.DisplayPaths(diagnostics.Filtered(findings))
+	var output []byte
+
+	if config.Template != "" {
+		var err error
+		output, err = report.Render(config.Template, struct{ Diagnostics []diagnostics.Diagnostic }{findings})
+		if err != nil {
+			return err
+		}
+	} else if config.Format == "text" {
+		if len(findings) == 0 {
+			logSuccess("No findings reported by plugins")
+			return nil
+		}
+
+		var sb strings.Builder
+		for _, finding := range findings {
+			sb.WriteString(fmt.Sprintf("%s:%d:%d: %s: %s [%s]\n", finding.File, finding.Line, finding.Column, finding.Severity, finding.Message, finding.Rule))
+		}
+		output = []byte(sb.String())
+	} else {
+		var buf bytes.Buffer
+		if err := diagnostics.WriteReport(&buf, config.Format, findings); err != nil {
+			return err
+		}
+		output = buf.Bytes()
+	}
+
+	if config.OutputFile != "" {
+		config.Manifest.AddResult(config.OutputFile, output)
+		if err := os.WriteFile(config.OutputFile, output, 0644); err != nil {
+			logError(fmt.Sprintf("Failed to write report: %v", err))
+			return err
+		}
+	} else {
+		config.Manifest.AddResult("stdout", output)
+		fmt.Print(string(output))
+	}
+
+	if config.Format == "text" && config.Template == "" {
+		logWarning(fmt.Sprintf("Plugins reported %d findings", len(findings)))
+	}
+	return nil
+}
+
+func logInfo(verbose bool, msg string) {
+	if verbose {
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
+	}
+}
+
+func logSuccess(msg string) {
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
+}
+
+func logWarning(msg string) {
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
+}
+
+func logError(msg string) {
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
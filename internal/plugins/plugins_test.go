@@ -0,0 +1,100 @@
+package plugins
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vitruves/gop/internal/config"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/registry"
+)
+
+func TestExecute(t *testing.T) {
+	dir := t.TempDir()
+	requestFile := filepath.Join(dir, "request.json")
+	plugin := config.Plugin{
+		Name: "banned-api",
+		Command: []string{"sh", "-c", `cat > "$0"; echo '[
+			{"file": "src/a.c", "line": 3, "rule": "strcpy", "message": "strcpy is banned"},
+			{"file": "src/a.c", "line": 9, "severity": "error", "rule": "banned-api/gets", "message": "gets is banned"}
+		]'; exit 1`, requestFile},
+	}
+	request := Request{Protocol: Protocol, Plugin: plugin.Name, Root: dir, Files: []File{
+		{Path: "src/a.c", Language: "c", Functions: []registry.Function{{Name: "main", Line: 1}}},
+	}}
+
+	// Findings are kept although the plugin exits with an error status
+	findings, err := Execute(plugin, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 2 || findings[0].Rule != "banned-api/strcpy" || findings[0].Severity != diagnostics.SeverityWarning ||
+		findings[1].Rule != "banned-api/gets" || findings[1].Severity != diagnostics.SeverityError {
+		t.Errorf("Unexpected findings: %+v", findings)
+	}
+
+	data, err := os.ReadFile(requestFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received Request
+	if err := json.Unmarshal(data, &received); err != nil {
+		t.Fatal(err)
+	}
+	if received.Protocol != Protocol || received.Plugin != "banned-api" || len(received.Files) != 1 || received.Files[0].Functions[0].Name != "main" {
+		t.Errorf("Unexpected request: %s", data)
+	}
+
+	tests := []struct {
+		command []string
+		timeout string
+		err     string
+	}{
+		{[]string{"sh", "-c", "exit 0"}, "", ""},
+		{[]string{"sh", "-c", "exit 2"}, "", "exit status 2"},
+		{[]string{"sh", "-c", "echo findings"}, "", "unreadable output"},
+		{[]string{"sleep", "5"}, "50ms", "timed out"},
+	}
+	for _, test := range tests {
+		_, err := Execute(config.Plugin{Name: "p", Command: test.command, Timeout: test.timeout}, request)
+		if test.err == "" && err != nil || test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+			t.Errorf("%v: expected error %q, got %v", test.command, test.err, err)
+		}
+	}
+}
+
+func TestSelectPlugins(t *testing.T) {
+	configured := []config.Plugin{
+		{Name: "a", Command: []string{"a"}},
+		{Name: "b", Command: []string{"b"}, Languages: []string{"c", "cpp"}},
+	}
+
+	if selected, err := selectPlugins(configured, []string{"b"}); err != nil || len(selected) != 1 || selected[0].Name != "b" {
+		t.Errorf("Expected plugin b, got %+v %v", selected, err)
+	}
+	if _, err := selectPlugins(configured, []string{"c"}); err == nil || !strings.Contains(err.Error(), "configured: a, b") {
+		t.Errorf("Expected an unknown plugin to list the configured ones, got %v", err)
+	}
+
+	invalid := [][]config.Plugin{
+		nil,
+		{{Command: []string{"a"}}},
+		{{Name: "a"}},
+		{{Name: "a", Command: []string{"a"}}, {Name: "a", Command: []string{"a"}}},
+		{{Name: "a", Command: []string{"a"}, Languages: []string{"java"}}},
+		{{Name: "a", Command: []string{"a"}, Timeout: "soon"}},
+	}
+	for _, plugins := range invalid {
+		if _, err := selectPlugins(plugins, nil); err == nil {
+			t.Errorf("Expected %+v to be rejected", plugins)
+		}
+	}
+
+	files := []File{{Path: "a.py", Language: "python"}, {Path: "b.c", Language: "c", Functions: []registry.Function{{Name: "f"}}}}
+	if got := filesFor(configured[1], files); len(got) != 1 || got[0].Path != "b.c" || got[0].Functions != nil {
+		t.Errorf("Expected only b.c without functions, got %+v", got)
+	}
+}
@@ -0,0 +1,152 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+	"github.com/vitruves/gop/internal/utils"
+)
+
+// Plain mode prints a line at most this often, plus one per tenth of the work.
+const plainInterval = 5 * time.Second
+
+const maxFileWidth = 40
+
+// Reporter shows how far a run over a list of files has got. On a terminal it
+// draws a bar with percentage, rate, ETA and the file last finished; otherwise
+// it prints periodic plain-text lines suitable for CI logs.
+type Reporter struct {
+	description string
+	total       int
+	done        int
+	start       time.Time
+	lastLine    time.Time
+	lastDecile  int
+
+	mu  sync.Mutex
+	out io.Writer
+	bar *progressbar.ProgressBar
+}
+
+// New returns a reporter for total files. A disabled reporter does nothing.
+func New(description string, total int, disabled bool) *Reporter {
+	now := time.Now()
+	r := &Reporter{
+		description: description,
+		total:       total,
+		start:       now,
+		lastLine:    now,
+	}
+
+	if disabled {
+		return r
+	}
+
+	r.out = os.Stderr
+	if isTerminal(os.Stderr) {
+		r.bar = progressbar.NewOptions(total,
+			progressbar.OptionSetWriter(os.Stderr),
+			progressbar.OptionSetDescription(description),
+			progressbar.OptionShowCount(),
+			progressbar.OptionShowIts(),
+			progressbar.OptionSetItsString("files"),
+			progressbar.OptionSetPredictTime(true),
+			progressbar.OptionSetRenderBlankState(true),
+			progressbar.OptionClearOnFinish(),
+		)
+	}
+
+	return r
+}
+
+// Add marks file as finished.
+func (r *Reporter) Add(file string) {
+	if r == nil || r.out == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.done++
+
+	if r.bar != nil {
+		r.bar.Describe(fmt.Sprintf("%s %s", r.description, shortenPath(file)))
+		r.bar.Add(1)
+		return
+	}
+
+	decile := 0
+	if r.total > 0 {
+		decile = r.done * 10 / r.total
+	}
+	if decile > r.lastDecile || time.Since(r.lastLine) >= plainInterval {
+		r.lastDecile = decile
+		r.lastLine = time.Now()
+		fmt.Fprintln(r.out, r.status())
+	}
+}
+
+// Finish completes the bar, or prints the closing line in plain mode.
+func (r *Reporter) Finish() {
+	if r == nil || r.out == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.bar != nil {
+		r.bar.Finish()
+		return
+	}
+
+	if r.done > 0 && r.lastDecile < 10 {
+		fmt.Fprintln(r.out, r.status())
+	}
+}
+
+func (r *Reporter) status() string {
+	elapsed := time.Since(r.start)
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(r.done) / elapsed.Seconds()
+	}
+
+	percent := 100
+	if r.total > 0 {
+		percent = r.done * 100 / r.total
+	}
+
+	line := fmt.Sprintf("%s: %d/%d (%d%%) %.1f files/s", r.description, r.done, r.total, percent, rate)
+	if r.done < r.total && rate > 0 {
+		eta := time.Duration(float64(r.total-r.done) / rate * float64(time.Second))
+		line += fmt.Sprintf(", ETA %s", utils.HumanDuration(eta.Round(time.Second)))
+	}
+	return line
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// shortenPath keeps the tail of long paths so the bar fits on one line.
+func shortenPath(path string) string {
+	if len(path) <= maxFileWidth {
+		return path
+	}
+	base := filepath.Base(path)
+	if len(base) >= maxFileWidth-4 {
+		return "..." + base[len(base)-(maxFileWidth-3):]
+	}
+	return "..." + path[len(path)-(maxFileWidth-3):]
+}
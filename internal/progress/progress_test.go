@@ -0,0 +1,108 @@
+package progress
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// capture replaces stdout and stderr with pipes while fn runs, and returns
+// what was written to each.
+func capture(t *testing.T, fn func()) (stdout, stderr string) {
+	t.Helper()
+
+	read := func(target **os.File) func() string {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved := *target
+		*target = w
+		done := make(chan string)
+		go func() {
+			data, _ := io.ReadAll(r)
+			done <- string(data)
+		}()
+		return func() string {
+			*target = saved
+			w.Close()
+			return <-done
+		}
+	}
+
+	restoreStdout := read(&os.Stdout)
+	restoreStderr := read(&os.Stderr)
+	fn()
+	return restoreStdout(), restoreStderr()
+}
+
+func TestPlainOutput(t *testing.T) {
+	stdout, stderr := capture(t, func() {
+		reporter := New("Scanning", 20, false)
+		for i := 0; i < 20; i++ {
+			reporter.Add("file.c")
+		}
+		reporter.Finish()
+	})
+
+	if stdout != "" {
+		t.Errorf("Expected nothing on stdout, got %q", stdout)
+	}
+
+	// Off a terminal, one line per tenth of the work
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	if len(lines) != 10 {
+		t.Fatalf("Expected 10 lines on stderr, got %d:\n%s", len(lines), stderr)
+	}
+	if !strings.HasPrefix(lines[0], "Scanning: 2/20 (10%)") {
+		t.Errorf("Unexpected first line %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[9], "Scanning: 20/20 (100%)") || strings.Contains(lines[9], "ETA") {
+		t.Errorf("Unexpected last line %q", lines[9])
+	}
+	for _, line := range lines {
+		if strings.Contains(line, "\r") || strings.Contains(line, "\x1b") {
+			t.Errorf("Expected plain lines without terminal control, got %q", line)
+		}
+	}
+}
+
+func TestPlainFinish(t *testing.T) {
+	// Finish prints the closing line when the last tenth was not reached
+	_, stderr := capture(t, func() {
+		reporter := New("Scanning", 20, false)
+		reporter.Add("file.c")
+		reporter.Finish()
+	})
+	if !strings.HasPrefix(stderr, "Scanning: 1/20 (5%)") || strings.Count(stderr, "\n") != 1 {
+		t.Errorf("Expected one closing line, got %q", stderr)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	stdout, stderr := capture(t, func() {
+		reporter := New("Scanning", 2, true)
+		reporter.Add("a.c")
+		reporter.Add("b.c")
+		reporter.Finish()
+
+		// A nil reporter does nothing either
+		var none *Reporter
+		none.Add("a.c")
+		none.Finish()
+	})
+	if stdout != "" || stderr != "" {
+		t.Errorf("Expected no output, got stdout %q and stderr %q", stdout, stderr)
+	}
+}
+
+func TestShortenPath(t *testing.T) {
+	if got := shortenPath("src/lib.c"); got != "src/lib.c" {
+		t.Errorf("Expected short paths unchanged, got %q", got)
+	}
+	long := "very/long/directory/structure/of/the/project/src/lib.c"
+	if got := shortenPath(long); len(got) > maxFileWidth || !strings.HasSuffix(got, "lib.c") {
+		t.Errorf("Expected the tail of %s within %d characters, got %q", long, maxFileWidth, got)
+	}
+}
@@ -0,0 +1,168 @@
+package refactor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vitruves/gop/internal/utils"
+)
+
+// Edit replaces the bytes [Start, End) of a file with Text. Start == End
+// inserts Text.
+type Edit struct {
+	Start int
+	End   int
+	Text  string
+}
+
+// Context lines shown around each hunk of a diff.
+const diffContext = 3
+
+// ApplyEdits returns content with the edits applied. Edits may come in any
+// order but must not overlap.
+func ApplyEdits(content string, edits []Edit) (string, error) {
+	sorted, err := sortEdits(content, edits)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, edit := range sorted {
+		sb.WriteString(content[last:edit.Start])
+		sb.WriteString(edit.Text)
+		last = edit.End
+	}
+	sb.WriteString(content[last:])
+	return sb.String(), nil
+}
+
+func sortEdits(content string, edits []Edit) ([]Edit, error) {
+	sorted := append([]Edit{}, edits...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	for i, edit := range sorted {
+		if edit.Start < 0 || edit.End < edit.Start || edit.End > len(content) {
+			return nil, fmt.Errorf("edit [%d, %d) is outside the file", edit.Start, edit.End)
+		}
+		if i > 0 && edit.Start < sorted[i-1].End {
+			return nil, fmt.Errorf("edits [%d, %d) and [%d, %d) overlap", sorted[i-1].Start, sorted[i-1].End, edit.Start, edit.End)
+		}
+	}
+	return sorted, nil
+}
+
+// UnifiedDiff renders the edits of a file as a unified diff. Hunks are built
+// from the edited lines directly, so large files with a few edits stay cheap.
+func UnifiedDiff(path, content string, edits []Edit) (string, error) {
+	sorted, err := sortEdits(content, edits)
+	if err != nil || len(sorted) == 0 {
+		return "", err
+	}
+
+	index := utils.NewLineIndex(content)
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	offset := func(line int) int {
+		if line < len(index) {
+			return index[line]
+		}
+		return len(content)
+	}
+
+	// Each change covers the whole lines its edits touch; an insertion at the
+	// start of a line covers none
+	type change struct {
+		first, end int
+		added      []string
+	}
+	var changes []change
+	var grouped [][]Edit
+	for _, edit := range sorted {
+		first, _ := index.Position(edit.Start)
+		first--
+		end := first + 1
+		if edit.End > edit.Start {
+			last, _ := index.Position(edit.End - 1)
+			end = last
+		} else if edit.Start == offset(first) {
+			end = first
+		}
+
+		if n := len(changes); n > 0 && first <= changes[n-1].end {
+			changes[n-1].end = max(changes[n-1].end, end)
+			grouped[n-1] = append(grouped[n-1], edit)
+			continue
+		}
+		changes = append(changes, change{first: first, end: end})
+		grouped = append(grouped, []Edit{edit})
+	}
+	for i := range changes {
+		start, end := offset(changes[i].first), offset(changes[i].end)
+		var shifted []Edit
+		for _, edit := range grouped[i] {
+			shifted = append(shifted, Edit{Start: edit.Start - start, End: edit.End - start, Text: edit.Text})
+		}
+		replaced, err := ApplyEdits(content[start:end], shifted)
+		if err != nil {
+			return "", err
+		}
+		added := strings.SplitAfter(replaced, "\n")
+		if added[len(added)-1] == "" {
+			added = added[:len(added)-1]
+		}
+		changes[i].added = added
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- a/%s\n+++ b/%s\n", path, path))
+	delta := 0
+	for i := 0; i < len(changes); {
+		// Changes whose context would touch share a hunk
+		j := i + 1
+		for j < len(changes) && changes[j].first-changes[j-1].end <= 2*diffContext {
+			j++
+		}
+		hunk := changes[i:j]
+		i = j
+
+		before := max(0, hunk[0].first-diffContext)
+		after := min(len(lines), hunk[len(hunk)-1].end+diffContext)
+		oldCount := after - before
+		newCount := oldCount
+		for _, c := range hunk {
+			newCount += len(c.added) - (c.end - c.first)
+		}
+		sb.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(before, oldCount), hunkRange(before+delta, newCount)))
+		delta += newCount - oldCount
+
+		line := before
+		for _, c := range hunk {
+			writeLines(&sb, " ", lines[line:c.first])
+			writeLines(&sb, "-", lines[c.first:min(c.end, len(lines))])
+			writeLines(&sb, "+", c.added)
+			line = min(c.end, len(lines))
+		}
+		writeLines(&sb, " ", lines[line:after])
+	}
+	return sb.String(), nil
+}
+
+// hunkRange formats the 1-based start and length of a hunk side.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func writeLines(sb *strings.Builder, prefix string, lines []string) {
+	for _, line := range lines {
+		sb.WriteString(prefix + line)
+		if !strings.HasSuffix(line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
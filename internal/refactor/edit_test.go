@@ -0,0 +1,51 @@
+package refactor
+
+import "testing"
+
+func TestApplyEditsAndDiff(t *testing.T) {
+	content := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+	edits := []Edit{
+		{Start: 22, End: 24, Text: "L\n"},
+		{Start: 0, End: 0, Text: "top\n"},
+		{Start: 2, End: 4},
+	}
+
+	got, err := ApplyEdits(content, edits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "top\na\nc\nd\ne\nf\ng\nh\ni\nj\nk\nL\n"; got != want {
+		t.Errorf("ApplyEdits = %q, want %q", got, want)
+	}
+
+	if _, err := ApplyEdits(content, []Edit{{Start: 0, End: 3}, {Start: 2, End: 4}}); err == nil {
+		t.Error("expected an error for overlapping edits")
+	}
+	if _, err := ApplyEdits(content, []Edit{{Start: 20, End: 40}}); err == nil {
+		t.Error("expected an error for an edit past the end")
+	}
+
+	diff, err := UnifiedDiff("x.c", content, edits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `--- a/x.c
++++ b/x.c
+@@ -1,5 +1,5 @@
++top
+ a
+-b
+ c
+ d
+ e
+@@ -9,4 +9,4 @@
+ i
+ j
+ k
+-l
++L
+`
+	if diff != want {
+		t.Errorf("UnifiedDiff =\n%s\nwant\n%s", diff, want)
+	}
+}
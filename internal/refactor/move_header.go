@@ -0,0 +1,401 @@
+package refactor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/utils"
+)
+
+type MoveHeaderConfig struct {
+	From string
+	To   string
+	// IncludeDirs are the -I search paths angle and unresolved quote includes
+	// are looked up in, the scanned root when empty
+	IncludeDirs    []string
+	DryRun         bool
+	Backup         bool
+	Include        []string
+	Exclude        []string
+	Depth          int
+	SkipGenerated  bool
+	SkipVendor     bool
+	MaxFileSize    int64
+	FollowSymlinks bool
+	IncludeHidden  bool
+	Verbose        bool
+	Manifest       *manifest.Manifest
+}
+
+// IncludeChange is one rewritten #include directive.
+type IncludeChange struct {
+	File    string
+	Line    int
+	OldPath string
+	NewPath string
+}
+
+var cFamilyExtensions = map[string]bool{
+	".c": true, ".h": true, ".cpp": true, ".cxx": true, ".cc": true,
+	".hpp": true, ".hxx": true, ".hh": true, ".h++": true, ".c++": true,
+	".inl": true, ".ipp": true, ".tpp": true,
+}
+
+var (
+	includeRegex = regexp.MustCompile(`^(\s*#\s*include\s*)([<"])([^>"]+)([>"])`)
+	guardRegex   = regexp.MustCompile(`^\s*#\s*ifndef\s+(\w+)\s*$`)
+	defineRegex  = regexp.MustCompile(`^\s*#\s*define\s+(\w+)\s*$`)
+	guardToken   = regexp.MustCompile(`[A-Za-z0-9]+`)
+)
+
+// MoveHeader moves a header, rewrites every #include that resolves to it and
+// renames its include guard when the guard was derived from the old path.
+func MoveHeader(config MoveHeaderConfig) error {
+	from, err := filepath.Abs(config.From)
+	if err != nil {
+		return err
+	}
+	to, err := filepath.Abs(config.To)
+	if err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(from); err != nil || info.IsDir() {
+		return fmt.Errorf("%s is not a file", config.From)
+	}
+	if _, err := os.Stat(to); err == nil {
+		return fmt.Errorf("%s already exists", config.To)
+	}
+
+	includeDirs := config.IncludeDirs
+	if len(includeDirs) == 0 {
+		includeDirs = []string{"."}
+	}
+	for i, dir := range includeDirs {
+		if includeDirs[i], err = filepath.Abs(dir); err != nil {
+			return err
+		}
+	}
+
+	// A moved header has to be found wherever it is included, so the whole
+	// tree is always searched
+	opts := utils.ScanOptions{
+		Include:        config.Include,
+		Exclude:        config.Exclude,
+		Recursive:      true,
+		Depth:          config.Depth,
+		SkipGenerated:  config.SkipGenerated,
+		SkipVendor:     config.SkipVendor,
+		MaxFileSize:    config.MaxFileSize,
+		FollowSymlinks: config.FollowSymlinks,
+		IncludeHidden:  config.IncludeHidden,
+		Verbose:        config.Verbose,
+	}
+
+	files, err := utils.GetFilesToProcess(opts, func(path string) bool {
+		return cFamilyExtensions[strings.ToLower(filepath.Ext(path))]
+	})
+	if err != nil {
+		return err
+	}
+	config.Manifest.AddFiles(files)
+
+	logInfo(config.Verbose, fmt.Sprintf("Searching %d files for includes of %s", len(files), config.From))
+
+	rewrites := make(map[string]string)
+	var changes []IncludeChange
+
+	for _, file := range files {
+		path, err := filepath.Abs(file)
+		if err != nil || path == from {
+			continue
+		}
+
+		// Files are rewritten byte for byte apart from the include paths
+		content, err := os.ReadFile(file)
+		if err != nil {
+			failures.Record(file, err)
+			continue
+		}
+
+		updated, fileChanges := rewriteIncludes(string(content), file, func(include string, quoted bool) string {
+			return retargetInclude(include, quoted, filepath.Dir(path), from, to, includeDirs)
+		})
+		if len(fileChanges) > 0 {
+			rewrites[file] = updated
+			changes = append(changes, fileChanges...)
+		}
+	}
+
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+
+	// Quote includes relative to the header's own directory have to follow it
+	header, headerChanges := rewriteIncludes(string(data), config.To, func(path string, quoted bool) string {
+		return relocateInclude(path, quoted, filepath.Dir(from), filepath.Dir(to))
+	})
+	changes = append(changes, headerChanges...)
+
+	// Guards are named after the path within the project
+	cwd, _ := os.Getwd()
+	relFrom, _ := filepath.Rel(cwd, from)
+	relTo, _ := filepath.Rel(cwd, to)
+	oldGuard, newGuard := renameGuard(header, relFrom, relTo)
+	if newGuard != "" {
+		header = regexp.MustCompile(`\b`+regexp.QuoteMeta(oldGuard)+`\b`).ReplaceAllString(header, newGuard)
+	} else if oldGuard != "" {
+		logWarning(fmt.Sprintf("Include guard %s does not follow the header path, left unchanged", oldGuard))
+	}
+
+	reportMove(config.From, config.To, changes, oldGuard, newGuard)
+
+	if config.DryRun {
+		logSuccess(fmt.Sprintf("Dry run: %d includes in %d files would be updated", len(changes), len(rewrites)))
+		return nil
+	}
+
+	for file, content := range rewrites {
+		if err := WriteFile(file, content, config.Backup); err != nil {
+			return err
+		}
+	}
+
+	if config.Backup {
+		if err := copyFile(from, from+".bak"); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(to, []byte(header), 0644); err != nil {
+		return err
+	}
+	if err := os.Remove(from); err != nil {
+		return err
+	}
+
+	logSuccess(fmt.Sprintf("Moved %s to %s, updated %d includes in %d files", config.From, config.To, len(changes), len(rewrites)))
+	return nil
+}
+
+// rewriteIncludes applies retarget to the path of every #include directive
+// and returns the new content with the directives it changed.
+func rewriteIncludes(content, file string, retarget func(path string, quoted bool) string) (string, []IncludeChange) {
+	var changes []IncludeChange
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		match := includeRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		newPath := retarget(match[3], match[2] == `"`)
+		if newPath == "" || newPath == match[3] {
+			continue
+		}
+
+		lines[i] = match[1] + match[2] + newPath + match[4] + line[len(match[0]):]
+		changes = append(changes, IncludeChange{File: file, Line: i + 1, OldPath: match[3], NewPath: newPath})
+	}
+
+	return strings.Join(lines, "\n"), changes
+}
+
+// retargetInclude returns the spelling of an include of the moved header, or
+// an empty string when path refers to another file. Quote includes are first
+// resolved next to the including file, like the preprocessor does.
+func retargetInclude(path string, quoted bool, fileDir, from, to string, includeDirs []string) string {
+	if quoted && resolvesTo(fileDir, path, from) {
+		return relativeInclude(fileDir, to)
+	}
+
+	for _, dir := range includeDirs {
+		if !resolvesTo(dir, path, from) {
+			continue
+		}
+		if rel := relativeInclude(dir, to); !strings.HasPrefix(rel, "../") {
+			return rel
+		}
+		// The new location is outside this search path
+		if quoted {
+			return relativeInclude(fileDir, to)
+		}
+		return relativeInclude(dir, to)
+	}
+
+	return ""
+}
+
+// relocateInclude keeps a quote include of the moved header pointing at the
+// same file once the header lives in newDir.
+func relocateInclude(path string, quoted bool, oldDir, newDir string) string {
+	if !quoted {
+		return ""
+	}
+
+	target := filepath.Join(oldDir, filepath.FromSlash(path))
+	if _, err := os.Stat(target); err != nil {
+		return ""
+	}
+	return relativeInclude(newDir, target)
+}
+
+func resolvesTo(dir, path, target string) bool {
+	return filepath.Clean(filepath.Join(dir, filepath.FromSlash(path))) == target
+}
+
+func relativeInclude(dir, target string) string {
+	rel, err := filepath.Rel(dir, target)
+	if err != nil {
+		return filepath.ToSlash(target)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// renameGuard finds the header's include guard and, when its name ends with
+// the tokens of the old path (FOO_H, SRC_FOO_H_, MYLIB_SRC_FOO_H), returns
+// it with those tokens replaced by the new path.
+func renameGuard(header, from, to string) (string, string) {
+	lines := strings.Split(header, "\n")
+
+	guard := ""
+	for i := 0; i+1 < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "/*") || strings.HasPrefix(trimmed, "*") {
+			continue
+		}
+		ifndef := guardRegex.FindStringSubmatch(lines[i])
+		define := defineRegex.FindStringSubmatch(lines[i+1])
+		if ifndef != nil && define != nil && ifndef[1] == define[1] {
+			guard = ifndef[1]
+		}
+		break
+	}
+	if guard == "" {
+		return "", ""
+	}
+
+	oldTokens := pathTokens(from)
+	newTokens := pathTokens(to)
+	guardTokens := strings.Split(guard, "_")
+
+	// Leading and trailing underscores are kept as they are
+	start, end := 0, len(guardTokens)
+	for start < end && guardTokens[start] == "" {
+		start++
+	}
+	for end > start && guardTokens[end-1] == "" {
+		end--
+	}
+	body := guardTokens[start:end]
+
+	matched := 0
+	for matched < len(oldTokens) && matched < len(body) &&
+		strings.EqualFold(body[len(body)-1-matched], oldTokens[len(oldTokens)-1-matched]) {
+		matched++
+	}
+
+	// The extension alone is not enough to tell the guard came from the path
+	if matched < 2 {
+		return guard, ""
+	}
+
+	replacement := newTokens
+	if matched < len(oldTokens) && matched < len(newTokens) {
+		replacement = newTokens[len(newTokens)-matched:]
+	}
+
+	renamed := append([]string{}, guardTokens[:start]...)
+	renamed = append(renamed, body[:len(body)-matched]...)
+	renamed = append(renamed, replacement...)
+	renamed = append(renamed, guardTokens[end:]...)
+
+	newGuard := strings.Join(renamed, "_")
+	if newGuard == guard {
+		return guard, ""
+	}
+	return guard, newGuard
+}
+
+func pathTokens(path string) []string {
+	tokens := guardToken.FindAllString(filepath.ToSlash(filepath.Clean(path)), -1)
+	for i := range tokens {
+		tokens[i] = strings.ToUpper(tokens[i])
+	}
+	return tokens
+}
+
+// GuardName derives an include guard from a header path, e.g. SRC_UTIL_H
+// for src/util.h.
+func GuardName(path string) string {
+	return strings.Join(pathTokens(path), "_")
+}
+
+func reportMove(from, to string, changes []IncludeChange, oldGuard, newGuard string) {
+	fmt.Printf("\n%s\n", color.Wrap(color.BoldCyan, fmt.Sprintf("=== MOVE %s -> %s ===", from, to)))
+
+	for _, change := range changes {
+		fmt.Printf("%s - %s -> %s\n", color.Wrap(color.Yellow, fmt.Sprintf("%s:%d", utils.DisplayPath(change.File), change.Line)), change.OldPath, change.NewPath)
+	}
+	if newGuard != "" {
+		fmt.Printf("%s - include guard %s -> %s\n", color.Wrap(color.Yellow, to), oldGuard, newGuard)
+	}
+}
+
+// WriteFile replaces the content of a file, keeping its permissions, and
+// first copies it to path.bak when backup is set.
+func WriteFile(path, content string, backup bool) error {
+	if backup {
+		if err := copyFile(path, path+".bak"); err != nil {
+			return err
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), info.Mode().Perm())
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0644)
+}
+
+func logInfo(verbose bool, msg string) {
+	if verbose {
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
+	}
+}
+
+func logSuccess(msg string) {
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
+}
+
+func logWarning(msg string) {
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
+}
+
+func logError(msg string) {
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
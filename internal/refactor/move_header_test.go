@@ -0,0 +1,55 @@
+package refactor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMoveHeader(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"old/path.h":   "#ifndef MYLIB_OLD_PATH_H_\n#define MYLIB_OLD_PATH_H_\n#include \"util.h\"\n#endif // MYLIB_OLD_PATH_H_\n",
+		"old/util.h":   "#pragma once\n",
+		"old/path.c":   "#include \"path.h\"\n",
+		"src/main.cpp": "#include <old/path.h>\n#include \"old/path.h\" // keep comment\n#include <vector>\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MoveHeader(MoveHeaderConfig{From: "old/path.h", To: "include/mylib/path.h"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"include/mylib/path.h": "#ifndef MYLIB_INCLUDE_MYLIB_PATH_H_\n#define MYLIB_INCLUDE_MYLIB_PATH_H_\n#include \"../../old/util.h\"\n#endif // MYLIB_INCLUDE_MYLIB_PATH_H_\n",
+		"old/path.c":           "#include \"../include/mylib/path.h\"\n",
+		"src/main.cpp":         "#include <include/mylib/path.h>\n#include \"include/mylib/path.h\" // keep comment\n#include <vector>\n",
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != content {
+			t.Errorf("%s:\ngot  %q\nwant %q", name, got, content)
+		}
+	}
+
+	if _, err := os.Stat("old/path.h"); !os.IsNotExist(err) {
+		t.Errorf("old/path.h was not removed")
+	}
+}
@@ -0,0 +1,253 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
+)
+
+// DefaultPublicRoots are the directories --public-only takes the public
+// headers from when --public-root is not given.
+var DefaultPublicRoots = []string{"include"}
+
+// APISurface is the exported API --public-only reports: its size and
+// composition, and the public functions leaking internal types.
+type APISurface struct {
+	Roots     []string       `json:"roots" yaml:"roots"`
+	Headers   int            `json:"headers" yaml:"headers"`
+	Functions int            `json:"functions" yaml:"functions"`
+	Methods   int            `json:"methods" yaml:"methods"`
+	Types     map[string]int `json:"types" yaml:"types"`
+	Total     int            `json:"total" yaml:"total"`
+	Warnings  []APIWarning   `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// APIWarning is a public function returning a type users of the API cannot
+// name: one declared outside the public headers, or nested in a private
+// section.
+type APIWarning struct {
+	Function string `json:"function" yaml:"function"`
+	File     string `json:"file" yaml:"file"`
+	Line     int    `json:"line" yaml:"line"`
+	Type     string `json:"type" yaml:"type"`
+	Reason   string `json:"reason" yaml:"reason"`
+}
+
+// splitPublic separates the headers under the public roots from the other
+// files, which only tell which types are internal.
+func splitPublic(files, roots []string, parser LanguageParser) (public, internal []string) {
+	var absRoots []string
+	for _, root := range roots {
+		if abs, err := filepath.Abs(root); err == nil {
+			absRoots = append(absRoots, abs)
+		}
+	}
+
+	for _, file := range files {
+		if parser.IsHeaderFile(file) && underAny(file, absRoots) {
+			public = append(public, file)
+		} else {
+			internal = append(internal, file)
+		}
+	}
+	return public, internal
+}
+
+func underAny(file string, roots []string) bool {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return false
+	}
+	for _, root := range roots {
+		if rel, err := filepath.Rel(root, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// publicFunctions keeps the functions the headers export: free functions
+// that are not static and public methods.
+func publicFunctions(functions []Function, scopeKinds map[string]string) []Function {
+	var public []Function
+	for _, fn := range functions {
+		if fn.Visibility != "public" {
+			continue
+		}
+		if fn.Metadata["static"] == "true" && functionKind(fn, scopeKinds) == "function" {
+			continue
+		}
+		public = append(public, fn)
+	}
+	return public
+}
+
+func isTypeKind(kind string) bool {
+	for _, k := range kindAliases["types"] {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func isPublicMember(member Member) bool {
+	return member.Visibility == "" || member.Visibility == "public"
+}
+
+// buildAPISurface sizes the API of the public headers and warns about the
+// public functions whose return type is internal. The other files are
+// parsed for the types they declare.
+func buildAPISurface(functions []Function, members []Member, headers int, internalFiles []string, parser LanguageParser, config Config) *APISurface {
+	surface := &APISurface{Roots: config.PublicRoots, Headers: headers, Types: make(map[string]int)}
+
+	scopeKinds := memberScopeKinds(members)
+	for _, fn := range functions {
+		if functionKind(fn, scopeKinds) == "method" {
+			surface.Methods++
+		} else {
+			surface.Functions++
+		}
+	}
+
+	// Types users can name, and where the others are declared
+	public := make(map[string]bool)
+	internal := make(map[string]string)
+	for _, member := range members {
+		if !isTypeKind(member.Kind) {
+			continue
+		}
+		if isPublicMember(member) {
+			public[member.Name] = true
+			surface.Types[member.Kind]++
+		} else if _, ok := internal[member.Name]; !ok {
+			internal[member.Name] = fmt.Sprintf("%s type of %s", member.Visibility, member.Scope)
+		}
+	}
+	for _, member := range internalTypes(internalFiles, parser, config) {
+		if _, ok := internal[member.Name]; !ok {
+			internal[member.Name] = "declared in " + utils.DisplayPath(member.File) + ", outside the public headers"
+		}
+	}
+
+	surface.Total = surface.Functions + surface.Methods
+	for _, count := range surface.Types {
+		surface.Total += count
+	}
+
+	for _, fn := range functions {
+		for _, name := range typeNames(fn.ReturnType) {
+			reason, ok := internal[name]
+			if !ok || public[name] {
+				continue
+			}
+			surface.Warnings = append(surface.Warnings, APIWarning{
+				Function: fn.Name,
+				File:     fn.File,
+				Line:     fn.Line,
+				Type:     name,
+				Reason:   reason,
+			})
+			break
+		}
+	}
+	sort.Slice(surface.Warnings, func(i, j int) bool {
+		if surface.Warnings[i].File == surface.Warnings[j].File {
+			return surface.Warnings[i].Line < surface.Warnings[j].Line
+		}
+		return surface.Warnings[i].File < surface.Warnings[j].File
+	})
+
+	return surface
+}
+
+// internalTypes returns the types declared in the files outside the public
+// headers.
+func internalTypes(files []string, parser LanguageParser, config Config) []Member {
+	memberParser, ok := parser.(MemberParser)
+	if !ok || len(files) == 0 {
+		return nil
+	}
+
+	reporter := progress.New("Collecting internal types", len(files), config.NoProgress)
+	found := worker.Run(files, config.Jobs, reporter, func(ctx context.Context, idx int, filePath string) []Member {
+		members, err := memberParser.ParseMembers(filePath)
+		if err != nil {
+			failures.Record(filePath, err)
+			return nil
+		}
+		var types []Member
+		for _, member := range members {
+			if isTypeKind(member.Kind) {
+				types = append(types, member)
+			}
+		}
+		return types
+	})
+
+	var types []Member
+	for _, members := range found {
+		types = append(types, members...)
+	}
+	return types
+}
+
+var typeNameRegex = regexp.MustCompile(`\w+(?:\s*::\s*\w+)*`)
+
+// typeNames returns the names a return type mentions, without their scope,
+// so that detail::State and State both name the State member.
+func typeNames(returnType string) []string {
+	var names []string
+	for _, match := range typeNameRegex.FindAllString(returnType, -1) {
+		names = append(names, lastComponent(strings.ReplaceAll(match, " ", "")))
+	}
+	return names
+}
+
+func displayAPISurface(surface *APISurface) *APISurface {
+	displayed := *surface
+	displayed.Warnings = make([]APIWarning, len(surface.Warnings))
+	for i, warning := range surface.Warnings {
+		warning.File = utils.DisplayPath(warning.File)
+		displayed.Warnings[i] = warning
+	}
+	return &displayed
+}
+
+func formatAPISurface(surface *APISurface) string {
+	var sb strings.Builder
+
+	sb.WriteString("## API Surface\n")
+	sb.WriteString(fmt.Sprintf("- Public Roots: %s\n", strings.Join(surface.Roots, ", ")))
+	sb.WriteString(fmt.Sprintf("- Public Headers: %d\n", surface.Headers))
+	sb.WriteString(fmt.Sprintf("- Functions: %d\n", surface.Functions))
+	sb.WriteString(fmt.Sprintf("- Methods: %d\n", surface.Methods))
+	kinds := make([]string, 0, len(surface.Types))
+	for kind := range surface.Types {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		sb.WriteString(fmt.Sprintf("- Types (%s): %d\n", kind, surface.Types[kind]))
+	}
+	sb.WriteString(fmt.Sprintf("- Total Exported: %d\n", surface.Total))
+	sb.WriteString("\n")
+
+	if len(surface.Warnings) > 0 {
+		sb.WriteString("### Internal Types in the Public API\n\n")
+		for _, warning := range surface.Warnings {
+			sb.WriteString(fmt.Sprintf("- `%s` returns `%s`, %s — %s:%d\n", warning.Function, warning.Type, warning.Reason, warning.File, warning.Line))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
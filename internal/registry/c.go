@@ -1,10 +1,11 @@
 package registry
 
 import (
-	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/vitruves/gop/internal/utils"
 )
 
 type CParser struct{}
@@ -17,38 +18,51 @@ func (c *CParser) IsHeaderFile(filePath string) bool {
 	return filepath.Ext(filePath) == ".h"
 }
 
+// More comprehensive C function regex
+var (
+	cFunctionRegex     = regexp.MustCompile(`^\s*(static\s+)?(extern\s+)?(inline\s+)?(\w+(?:\s*\*)*)\s+(\w+)\s*\((.*?)\)\s*[{;]`)
+	cStructRegex       = regexp.MustCompile(`^\s*struct\s+(\w+)`)
+	cPreprocessorRegex = regexp.MustCompile(`^\s*#(\w+)`)
+)
+
 func (c *CParser) ParseFile(filePath string) ([]Function, error) {
-	content, err := os.ReadFile(filePath)
+	content, err := utils.ReadSourceFile(filePath)
 	if err != nil {
 		return nil, err
 	}
 
 	var functions []Function
-	lines := strings.Split(string(content), "\n")
-	
-	// More comprehensive C function regex
-	fnRegex := regexp.MustCompile(`^\s*(static\s+)?(extern\s+)?(inline\s+)?(\w+(?:\s*\*)*)\s+(\w+)\s*\((.*?)\)\s*[{;]`)
-	structRegex := regexp.MustCompile(`^\s*struct\s+(\w+)`)
-	preprocessorRegex := regexp.MustCompile(`^\s*#(\w+)`)
+	lines := utils.SplitLines(content)
 	
 	var currentStruct string
+	// Braces open before the line, the extern "C" blocks of headers aside
+	depth := 0
 	
 	for i, line := range lines {
 		trimmed := strings.TrimSpace(line)
+		atFileScope := depth == 0
+		if !strings.HasPrefix(trimmed, `extern "C"`) {
+			depth = max(depth+strings.Count(line, "{")-strings.Count(line, "}"), 0)
+		}
 		
 		// Skip preprocessor directives
-		if preprocessorRegex.MatchString(line) {
+		if cPreprocessorRegex.MatchString(line) {
 			continue
 		}
 		
 		// Track struct context
-		if structMatch := structRegex.FindStringSubmatch(line); structMatch != nil {
+		if structMatch := cStructRegex.FindStringSubmatch(line); structMatch != nil {
 			currentStruct = structMatch[1]
 			continue
 		}
 		
-		// Parse function definitions and declarations
-		if fnMatch := fnRegex.FindStringSubmatch(line); fnMatch != nil {
+		// Parse function definitions and declarations, those split over
+		// several lines are matched as one
+		declaration := line
+		if atFileScope {
+			declaration = joinDeclaration(line, lines[i+1:])
+		}
+		if fnMatch := cFunctionRegex.FindStringSubmatch(declaration); fnMatch != nil {
 			staticMod := strings.TrimSpace(fnMatch[1])
 			externMod := strings.TrimSpace(fnMatch[2])
 			inlineMod := strings.TrimSpace(fnMatch[3])
@@ -57,7 +71,7 @@ func (c *CParser) ParseFile(filePath string) ([]Function, error) {
 			params := fnMatch[6]
 			
 			// Skip if this looks like a variable declaration
-			if strings.Contains(line, "=") && !strings.Contains(line, "{") {
+			if strings.Contains(declaration, "=") && !strings.Contains(declaration, "{") {
 				continue
 			}
 			
@@ -67,8 +81,8 @@ func (c *CParser) ParseFile(filePath string) ([]Function, error) {
 			}
 			
 			// Determine if it's a declaration or definition
-			isDeclaration := strings.HasSuffix(trimmed, ";")
-			isDefinition := strings.Contains(line, "{")
+			isDeclaration := strings.HasSuffix(strings.TrimSpace(declaration), ";")
+			isDefinition := strings.Contains(declaration, "{")
 			
 			paramList := parseCParameters(params)
 			comments := extractCComments(lines, i)
@@ -81,12 +95,13 @@ func (c *CParser) ParseFile(filePath string) ([]Function, error) {
 				ReturnType: returnType,
 				Parameters: paramList,
 				Language:   "c",
-				Signature:  strings.TrimSpace(line),
+				Signature:  strings.TrimSpace(declaration),
 				IsTest:     isCTestFunction(name),
 				IsMain:     name == "main",
 				Size:       calculateCFunctionSize(lines, i, isDefinition),
 				Comments:   comments,
 			}
+			fn.Complexity = calculateBranchComplexity(lines, i, fn.Size, cppBranchRegex, "//")
 			
 			// Set metadata
 			fn.Metadata = make(map[string]string)
@@ -118,9 +133,19 @@ func (c *CParser) ParseFile(filePath string) ([]Function, error) {
 	return functions, nil
 }
 
+// ParseMembers reports structs, unions, enums and their fields. C records
+// follow the same grammar as C++ ones, so the C++ scanner is reused.
+func (c *CParser) ParseMembers(filePath string) ([]Member, error) {
+	cpp := &CppParser{}
+	return cpp.ParseMembers(filePath)
+}
+
+// wordCallRegex finds calls by the identifier before a parenthesis, in C and
+// in the languages without a dedicated call pattern.
+var wordCallRegex = regexp.MustCompile(`(\w+)\s*\(`)
+
 func (c *CParser) FindFunctionCalls(content string) []string {
-	callRegex := regexp.MustCompile(`(\w+)\s*\(`)
-	matches := callRegex.FindAllStringSubmatch(content, -1)
+	matches := wordCallRegex.FindAllStringSubmatch(content, -1)
 	
 	var calls []string
 	seen := make(map[string]bool)
@@ -1,10 +1,11 @@
 package registry
 
 import (
-	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/vitruves/gop/internal/utils"
 )
 
 type CppParser struct{}
@@ -25,64 +26,106 @@ func (cpp *CppParser) IsHeaderFile(filePath string) bool {
 }
 
 func (cpp *CppParser) ParseFile(filePath string) ([]Function, error) {
-	content, err := os.ReadFile(filePath)
+	functions, _, err := cpp.parse(filePath)
+	return functions, err
+}
+
+func (cpp *CppParser) ParseMembers(filePath string) ([]Member, error) {
+	_, members, err := cpp.parse(filePath)
+	return members, err
+}
+
+// Comprehensive C++ function regex patterns
+var (
+	cppFunctionRegex = regexp.MustCompile(`^\s*(template\s*<[^>]*>\s*)?(public|private|protected)?\s*:\s*$|^\s*(virtual\s+)?(static\s+)?(inline\s+)?(explicit\s+)?(\w+(?:\s*::\s*\w+)*(?:\s*<[^>]*>)?(?:\s*\*)*)\s+(\w+(?:::\w+)*(?:<[^<>()]*>)?)\s*\((.*?)\)\s*(const)?\s*(override)?\s*(final)?\s*[{;]`)
+	cppAccessRegex   = regexp.MustCompile(`^\s*(public|private|protected)\s*:`)
+)
+
+func (cpp *CppParser) parse(filePath string) ([]Function, []Member, error) {
+	content, err := utils.ReadSourceFile(filePath)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var functions []Function
-	lines := strings.Split(string(content), "\n")
-	
-	// Comprehensive C++ function regex patterns
-	fnRegex := regexp.MustCompile(`^\s*(template\s*<[^>]*>\s*)?(public|private|protected)?\s*:\s*$|^\s*(virtual\s+)?(static\s+)?(inline\s+)?(explicit\s+)?(\w+(?:\s*::\s*\w+)*(?:\s*<[^>]*>)?(?:\s*\*)*)\s+(\w+(?:::\w+)*)\s*\((.*?)\)\s*(const)?\s*(override)?\s*(final)?\s*[{;]`)
-	classRegex := regexp.MustCompile(`^\s*(template\s*<[^>]*>\s*)?(class|struct)\s+(\w+)`)
-	namespaceRegex := regexp.MustCompile(`^\s*namespace\s+(\w+)`)
-	accessRegex := regexp.MustCompile(`^\s*(public|private|protected)\s*:`)
+	var members []Member
+	lines := utils.SplitLines(content)
 	
-	var currentClass string
-	var currentNamespace string
-	var currentAccess string = "private" // Default for class
+	scopes := &braceScopes{}
 	var templateContext string
 	
 	for i, line := range lines {
 		trimmed := strings.TrimSpace(line)
+		braces := line
 		
-		// Track template context
+		// Track template context, the declaration may follow on the same line
 		if strings.HasPrefix(trimmed, "template") && strings.Contains(trimmed, "<") {
-			templateContext = trimmed
-			continue
+			params, rest := splitTemplatePrefix(trimmed)
+			templateContext = params
+			if rest == "" {
+				scopes.advance(braces)
+				continue
+			}
+			line = rest
+			trimmed = rest
 		}
 		
-		// Track namespace
-		if nsMatch := namespaceRegex.FindStringSubmatch(line); nsMatch != nil {
-			currentNamespace = nsMatch[1]
-			templateContext = ""
+		// Base classes listed on the lines following the class name
+		if (strings.HasPrefix(trimmed, ":") || strings.HasPrefix(trimmed, ",") || scopes.expectsBases()) && scopes.addBases(trimmed) {
+			scopes.advance(braces)
 			continue
 		}
 		
-		// Track class/struct context
-		if classMatch := classRegex.FindStringSubmatch(line); classMatch != nil {
-			currentClass = classMatch[3]
-			currentAccess = "private"
-			if classMatch[2] == "struct" {
-				currentAccess = "public"
+		// Namespaces, types and linkage blocks open scopes once their brace is seen
+		if name, kind, bases, ok := matchCppScope(line); ok {
+			access := "public"
+			if kind == "class" {
+				access = "private"
 			}
+			scopes.declare(name, kind, access, bases, i+1)
+			scopes.continueBases(trimmed)
 			templateContext = ""
+			
+			// Enumerators listed on the line that opens the enum
+			if idx := strings.Index(trimmed, "{"); kind == "enum" && idx != -1 {
+				members = append(members, parseEnumerators(trimmed[idx+1:], scopes.qualify(name), filePath, i+1)...)
+			}
+			
+			scopes.advance(braces)
+			continue
+		}
+		
+		if scopes.inEnum() {
+			members = append(members, parseEnumerators(trimmed, scopes.path(), filePath, i+1)...)
+			scopes.advance(braces)
 			continue
 		}
 		
 		// Track access specifiers
-		if accessMatch := accessRegex.FindStringSubmatch(line); accessMatch != nil {
-			currentAccess = accessMatch[1]
+		if accessMatch := cppAccessRegex.FindStringSubmatch(line); accessMatch != nil {
+			scopes.setAccess(accessMatch[1])
 			templateContext = ""
+			scopes.advance(braces)
+			continue
+		}
+		
+		// Anything below a function body is not a declaration
+		if !scopes.atBodyLevel() {
+			scopes.advance(braces)
 			continue
 		}
 		
-		// Parse function definitions
-		if fnMatch := fnRegex.FindStringSubmatch(line); fnMatch != nil {
+		currentClass := scopes.currentType()
+		currentAccess := scopes.currentAccess()
+		
+		// Parse function definitions, declarations split over several lines
+		// are matched as one
+		declaration := joinDeclaration(line, lines[i+1:])
+		if fnMatch := cppFunctionRegex.FindStringSubmatch(declaration); fnMatch != nil {
 			// Skip access specifier lines
 			if fnMatch[2] != "" && fnMatch[7] == "" {
-				currentAccess = fnMatch[2]
+				scopes.setAccess(fnMatch[2])
+				scopes.advance(braces)
 				continue
 			}
 			
@@ -97,8 +140,16 @@ func (cpp *CppParser) ParseFile(filePath string) ([]Function, error) {
 			overrideMod := strings.TrimSpace(fnMatch[11])
 			finalMod := strings.TrimSpace(fnMatch[12])
 			
+			// A specifier in place of the return type declares a
+			// constructor, such as explicit Foo(double d)
+			specifier := ""
+			if isCppSpecifier(returnType) && isCppConstructor(name, currentClass) {
+				specifier, returnType = returnType, ""
+			}
+			
 			// Skip obvious non-functions
-			if returnType == "" || name == "" {
+			if returnType == "" && specifier == "" || name == "" || isCppKeyword(returnType) && !isCppTypeKeyword(returnType) {
+				scopes.advance(braces)
 				continue
 			}
 			
@@ -107,16 +158,12 @@ func (cpp *CppParser) ParseFile(filePath string) ([]Function, error) {
 				returnType = ""
 			}
 			
-			fullName := name
-			if currentClass != "" {
-				fullName = currentClass + "::" + name
-			}
-			if currentNamespace != "" {
-				if currentClass != "" {
-					fullName = currentNamespace + "::" + currentClass + "::" + name
-				} else {
-					fullName = currentNamespace + "::" + name
-				}
+			fullName := scopes.qualify(name)
+			scope := fullName
+			if idx := strings.LastIndex(baseCppName(fullName), "::"); idx != -1 {
+				scope = fullName[:idx]
+			} else {
+				scope = ""
 			}
 			
 			visibility := currentAccess
@@ -125,10 +172,11 @@ func (cpp *CppParser) ParseFile(filePath string) ([]Function, error) {
 			}
 			
 			// Determine if it's a declaration or definition
-			isDeclaration := strings.HasSuffix(trimmed, ";")
-			isDefinition := strings.Contains(line, "{")
+			isDeclaration := strings.HasSuffix(strings.TrimSpace(declaration), ";")
+			isDefinition := strings.Contains(declaration, "{")
 			
 			paramList := parseCppParameters(params)
+			paramTypes := ParameterTypes(params)
 			comments := extractCppComments(lines, i)
 			
 			fn := Function{
@@ -138,13 +186,16 @@ func (cpp *CppParser) ParseFile(filePath string) ([]Function, error) {
 				Visibility: visibility,
 				ReturnType: returnType,
 				Parameters: paramList,
+				ParamTypes: paramTypes,
 				Language:   "cpp",
-				Signature:  strings.TrimSpace(line),
+				Scope:      scope,
+				Signature:  strings.TrimSpace(lines[i] + declaration[len(line):]),
 				IsTest:     isCppTestFunction(name, fullName),
 				IsMain:     name == "main",
 				Size:       calculateCppFunctionSize(lines, i, isDefinition),
 				Comments:   comments,
 			}
+			fn.Complexity = calculateBranchComplexity(lines, i, fn.Size, cppBranchRegex, "//")
 			
 			// Set metadata
 			fn.Metadata = make(map[string]string)
@@ -169,8 +220,17 @@ func (cpp *CppParser) ParseFile(filePath string) ([]Function, error) {
 			if finalMod != "" {
 				fn.Metadata["final"] = "true"
 			}
+			if specifier != "" {
+				fn.Metadata[specifier] = "true"
+			}
 			if templateContext != "" {
 				fn.Metadata["template"] = "true"
+				if templateContext != "<>" {
+					fn.Metadata["template_params"] = templateContext
+				}
+			}
+			if strings.Contains(name, "<") {
+				fn.Metadata["specialization"] = "true"
 			}
 			if isDeclaration {
 				fn.Metadata["declaration"] = "true"
@@ -178,7 +238,7 @@ func (cpp *CppParser) ParseFile(filePath string) ([]Function, error) {
 			if isDefinition {
 				fn.Metadata["definition"] = "true"
 			}
-			if name == currentClass {
+			if isCppConstructor(name, currentClass) {
 				fn.Metadata["constructor"] = "true"
 			}
 			if name == "~"+currentClass {
@@ -187,6 +247,12 @@ func (cpp *CppParser) ParseFile(filePath string) ([]Function, error) {
 			
 			functions = append(functions, fn)
 			templateContext = ""
+		} else if field, ok := matchCppField(trimmed); ok && scopes.inRecord() {
+			field.Scope = scopes.path()
+			field.File = filePath
+			field.Line = i + 1
+			field.Visibility = currentAccess
+			members = append(members, field)
 		} else if trimmed != "" && !strings.HasPrefix(trimmed, "//") && !strings.HasPrefix(trimmed, "/*") {
 			// Reset template context on non-template lines
 			if !strings.Contains(trimmed, "template") {
@@ -194,41 +260,38 @@ func (cpp *CppParser) ParseFile(filePath string) ([]Function, error) {
 			}
 		}
 		
-		// Reset class context on closing brace
-		if strings.Contains(line, "}") && !strings.Contains(line, "{") {
-			// This is a simplified check - proper parsing would need brace counting
-			currentClass = ""
-			currentAccess = "private"
-		}
+		scopes.advance(braces)
 	}
 	
-	return functions, nil
+	members = append(members, scopes.members(filePath)...)
+	return functions, members, nil
 }
 
+var (
+	cppCallRegex   = regexp.MustCompile(`(\w+(?:::\w+)*)\s*\(`)
+	cppMethodRegex = regexp.MustCompile(`\.(\w+)\s*\(|->(\w+)\s*\(`)
+)
+
 func (cpp *CppParser) FindFunctionCalls(content string) []string {
-	callRegex := regexp.MustCompile(`(\w+(?:::\w+)*)\s*\(`)
-	methodRegex := regexp.MustCompile(`\.(\w+)\s*\(|->(\w+)\s*\(`)
 	
 	var calls []string
 	seen := make(map[string]bool)
 	
 	// Function calls
-	matches := callRegex.FindAllStringSubmatch(content, -1)
+	matches := cppCallRegex.FindAllStringSubmatch(content, -1)
 	for _, match := range matches {
+		// Qualifiers are kept so that ns::f credits ns::f only
 		call := match[1]
-		// Remove namespace qualifiers for simplicity
-		if idx := strings.LastIndex(call, "::"); idx != -1 {
-			call = call[idx+2:]
-		}
+		name := lastComponent(call)
 		
-		if !seen[call] && !isCppBuiltin(call) && !isCppKeyword(call) {
+		if !seen[call] && !isCppBuiltin(name) && !isCppKeyword(name) {
 			calls = append(calls, call)
 			seen[call] = true
 		}
 	}
 	
 	// Method calls
-	methodMatches := methodRegex.FindAllStringSubmatch(content, -1)
+	methodMatches := cppMethodRegex.FindAllStringSubmatch(content, -1)
 	for _, match := range methodMatches {
 		var call string
 		if match[1] != "" {
@@ -298,6 +361,191 @@ func parseCppParameters(params string) []string {
 	return result
 }
 
+// ParameterTypes returns the normalized type of each parameter of a C or C++
+// parameter list, with names and default values stripped, so overloads can
+// be told apart.
+func ParameterTypes(params string) []string {
+	if strings.TrimSpace(params) == "" || strings.TrimSpace(params) == "void" {
+		return []string{}
+	}
+
+	var result []string
+	for _, part := range splitCppParameters(params) {
+		if equalIndex := strings.Index(part, "="); equalIndex != -1 {
+			part = strings.TrimSpace(part[:equalIndex])
+		}
+		if part == "" || part == "void" {
+			continue
+		}
+
+		// Function pointers keep their full spelling minus the name
+		if strings.Contains(part, "(") {
+			result = append(result, normalizeCppType(cppIdentRegex.ReplaceAllString(part, "(*)")))
+			continue
+		}
+
+		isArray := false
+		if bracketIdx := strings.Index(part, "["); bracketIdx != -1 {
+			part = strings.TrimSpace(part[:bracketIdx])
+			isArray = true
+		}
+
+		words := strings.Fields(part)
+		if len(words) > 1 {
+			last := strings.TrimLeft(words[len(words)-1], "*&")
+			if isCppIdentifier(last) && !isCppTypeKeyword(last) {
+				part = strings.TrimSuffix(part, last)
+			}
+		}
+		if isArray {
+			part += "[]"
+		}
+
+		result = append(result, normalizeCppType(part))
+	}
+
+	return result
+}
+
+var cppIdentRegex = regexp.MustCompile(`\(\s*\*\s*\w*\s*\)`)
+
+// splitCppParameters splits on top-level commas only, so template arguments
+// such as std::map<int, int> stay in one piece.
+func splitCppParameters(params string) []string {
+	var parts []string
+	depth := 0
+	start := 0
+
+	for i, char := range params {
+		switch char {
+		case '<', '(', '[':
+			depth++
+		case '>', ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(params[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, strings.TrimSpace(params[start:]))
+
+	return parts
+}
+
+// maxDeclarationLines bounds how far a parameter list is followed when a
+// declaration is split over several lines.
+const maxDeclarationLines = 20
+
+// joinDeclaration returns the line joined with the following ones until the
+// parameter list it opens is closed, so that declarations split over several
+// lines match the function patterns. The line is returned as is when its
+// parentheses balance, or when the list does not close before a brace or a
+// semicolon.
+func joinDeclaration(line string, following []string) string {
+	depth := strings.Count(line, "(") - strings.Count(line, ")")
+	if depth <= 0 {
+		return line
+	}
+
+	joined := line
+	for j, next := range following {
+		if j == maxDeclarationLines {
+			break
+		}
+		if idx := strings.Index(next, "//"); idx != -1 {
+			next = next[:idx]
+		}
+		next = strings.TrimSpace(next)
+		joined += " " + next
+		depth += strings.Count(next, "(") - strings.Count(next, ")")
+		if depth <= 0 {
+			return joined
+		}
+		if strings.ContainsAny(next, "{};") {
+			break
+		}
+	}
+	return line
+}
+
+func normalizeCppType(typ string) string {
+	typ = strings.Join(strings.Fields(typ), " ")
+	for _, sym := range []string{"*", "&", "<", ">", ",", "(", ")"} {
+		typ = strings.ReplaceAll(typ, " "+sym, sym)
+		typ = strings.ReplaceAll(typ, sym+" ", sym)
+	}
+	return strings.ReplaceAll(typ, ",", ", ")
+}
+
+// splitTemplatePrefix separates "template <typename T>" from whatever follows
+// it on the same line.
+func splitTemplatePrefix(line string) (string, string) {
+	start := strings.Index(line, "<")
+	depth := 0
+	for i := start; i < len(line); i++ {
+		switch line[i] {
+		case '<':
+			depth++
+		case '>':
+			depth--
+			if depth == 0 {
+				return line[start : i+1], strings.TrimSpace(line[i+1:])
+			}
+		}
+	}
+	return line[start:], ""
+}
+
+func isCppIdentifier(word string) bool {
+	for i, char := range word {
+		if char == '_' || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') {
+			continue
+		}
+		if i > 0 && char >= '0' && char <= '9' {
+			continue
+		}
+		return false
+	}
+	return word != ""
+}
+
+// isCppSpecifier reports whether word is a declaration specifier the
+// function regex can take for the return type of a constructor.
+func isCppSpecifier(word string) bool {
+	switch word {
+	case "explicit", "inline", "constexpr", "virtual", "static":
+		return true
+	}
+	return false
+}
+
+// isCppConstructor reports whether name is a constructor of the current
+// class, or one defined outside it as Foo::Foo.
+func isCppConstructor(name, currentClass string) bool {
+	if currentClass != "" && name == currentClass {
+		return true
+	}
+	parts := strings.Split(baseCppName(name), "::")
+	return len(parts) >= 2 && parts[len(parts)-1] == parts[len(parts)-2]
+}
+
+func isCppTypeKeyword(word string) bool {
+	typeKeywords := []string{
+		"int", "char", "short", "long", "float", "double", "bool", "void",
+		"signed", "unsigned", "const", "volatile", "auto", "wchar_t", "char16_t", "char32_t",
+	}
+
+	for _, keyword := range typeKeywords {
+		if word == keyword {
+			return true
+		}
+	}
+
+	return false
+}
+
 func extractCppComments(lines []string, fnLine int) string {
 	var comments []string
 	
@@ -355,6 +603,23 @@ func extractCppComments(lines []string, fnLine int) string {
 	return strings.Join(comments, " ")
 }
 
+var cppBranchRegex = regexp.MustCompile(`\b(if|for|while|case|catch)\b|&&|\|\||\?`)
+
+// calculateBranchComplexity counts decision points over the lines of a
+// function body, starting from a base complexity of 1. Text after
+// commentPrefix is ignored.
+func calculateBranchComplexity(lines []string, startLine, size int, branchRegex *regexp.Regexp, commentPrefix string) int {
+	complexity := 1
+	for i := startLine; i < startLine+size && i < len(lines); i++ {
+		line := lines[i]
+		if idx := strings.Index(line, commentPrefix); idx != -1 {
+			line = line[:idx]
+		}
+		complexity += len(branchRegex.FindAllStringIndex(line, -1))
+	}
+	return complexity
+}
+
 func calculateCppFunctionSize(lines []string, startLine int, isDefinition bool) int {
 	if !isDefinition || startLine >= len(lines) {
 		return 1
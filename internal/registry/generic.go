@@ -1,10 +1,11 @@
 package registry
 
 import (
-	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/vitruves/gop/internal/utils"
 )
 
 type GenericParser struct{}
@@ -24,31 +25,31 @@ func (g *GenericParser) IsHeaderFile(filePath string) bool {
 	return false
 }
 
+// Generic patterns for different languages
+var genericPatterns = []struct {
+	regex    *regexp.Regexp
+	language string
+}{
+	{regexp.MustCompile(`^\s*(def|async def)\s+(\w+)\s*\(`), "python"},
+	{regexp.MustCompile(`^\s*(pub\s+)?fn\s+(\w+)\s*\(`), "rust"},
+	{regexp.MustCompile(`^\s*func\s+(\w+)\s*\(`), "go"},
+	{regexp.MustCompile(`^\s*(\w+)\s+(\w+)\s*\(.*\)\s*[{;]`), "c/cpp"},
+}
+
 func (g *GenericParser) ParseFile(filePath string) ([]Function, error) {
-	content, err := os.ReadFile(filePath)
+	content, err := utils.ReadSourceFile(filePath)
 	if err != nil {
 		return nil, err
 	}
 
 	var functions []Function
-	lines := strings.Split(string(content), "\n")
-	
-	// Generic patterns for different languages
-	patterns := []struct {
-		regex    *regexp.Regexp
-		language string
-	}{
-		{regexp.MustCompile(`^\s*(def|async def)\s+(\w+)\s*\(`), "python"},
-		{regexp.MustCompile(`^\s*(pub\s+)?fn\s+(\w+)\s*\(`), "rust"},
-		{regexp.MustCompile(`^\s*func\s+(\w+)\s*\(`), "go"},
-		{regexp.MustCompile(`^\s*(\w+)\s+(\w+)\s*\(.*\)\s*[{;]`), "c/cpp"},
-	}
+	lines := utils.SplitLines(content)
 	
 	ext := filepath.Ext(filePath)
 	detectedLang := detectLanguageFromExtension(ext)
 	
 	for i, line := range lines {
-		for _, pattern := range patterns {
+		for _, pattern := range genericPatterns {
 			if matches := pattern.regex.FindStringSubmatch(line); matches != nil {
 				var name string
 				
@@ -101,8 +102,7 @@ func (g *GenericParser) ParseFile(filePath string) ([]Function, error) {
 
 func (g *GenericParser) FindFunctionCalls(content string) []string {
 	// Generic function call patterns
-	callRegex := regexp.MustCompile(`(\w+)\s*\(`)
-	matches := callRegex.FindAllStringSubmatch(content, -1)
+	matches := wordCallRegex.FindAllStringSubmatch(content, -1)
 	
 	var calls []string
 	seen := make(map[string]bool)
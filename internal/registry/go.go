@@ -4,6 +4,7 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"strings"
 )
 
@@ -83,6 +84,7 @@ func (g *GoParser) ParseFile(filePath string) ([]Function, error) {
 					ReturnType: returnType,
 					Parameters: params,
 					Language:   "go",
+					Scope:      strings.TrimPrefix(receiverType, "*"),
 					Signature:  extractGoSignature(x, fset),
 					IsTest:     isTest,
 					IsMain:     isMain,
@@ -110,6 +112,84 @@ func (g *GoParser) ParseFile(filePath string) ([]Function, error) {
 	return functions, nil
 }
 
+func (g *GoParser) ParseMembers(filePath string) ([]Member, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, filePath, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []Member
+
+	for _, decl := range node.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+
+			kind := "type"
+			var fields []*ast.Field
+			fieldKind := "field"
+			switch t := typeSpec.Type.(type) {
+			case *ast.StructType:
+				kind = "struct"
+				fields = t.Fields.List
+			case *ast.InterfaceType:
+				kind = "interface"
+				fields = t.Methods.List
+				fieldKind = "method"
+			}
+
+			typeMember := Member{
+				Name:       typeSpec.Name.Name,
+				Kind:       kind,
+				File:       filePath,
+				Line:       fset.Position(typeSpec.Pos()).Line,
+				Visibility: goVisibility(typeSpec.Name),
+			}
+
+			var fieldMembers []Member
+			for _, field := range fields {
+				typeName := types.ExprString(field.Type)
+				names := field.Names
+				if len(names) == 0 {
+					// Embedded field or interface, the closest Go has to a base type
+					embedded := strings.TrimPrefix(typeName, "*")
+					typeMember.Bases = append(typeMember.Bases, embedded)
+					names = []*ast.Ident{ast.NewIdent(embedded)}
+				}
+
+				for _, name := range names {
+					fieldMembers = append(fieldMembers, Member{
+						Name:       name.Name,
+						Kind:       fieldKind,
+						Type:       typeName,
+						Scope:      typeSpec.Name.Name,
+						File:       filePath,
+						Line:       fset.Position(field.Pos()).Line,
+						Visibility: goVisibility(name),
+					})
+				}
+			}
+
+			members = append(members, typeMember)
+			members = append(members, fieldMembers...)
+		}
+	}
+
+	return members, nil
+}
+
+func goVisibility(name *ast.Ident) string {
+	if name.IsExported() {
+		return "public"
+	}
+	return "private"
+}
+
 func (g *GoParser) FindFunctionCalls(content string) []string {
 	fset := token.NewFileSet()
 	node, err := parser.ParseFile(fset, "", content, 0)
@@ -0,0 +1,183 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vitruves/gop/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// MacroMap teaches the registry what declaration macros expand to. Each
+// template is a function signature in the source language where $1, $2, ...
+// stand for the macro arguments:
+//
+//	macros:
+//	  DECLARE_HANDLER:
+//	    - "int handle_$1(struct request *req);"
+type MacroMap struct {
+	Macros map[string][]string `yaml:"macros"`
+}
+
+func LoadMacroMap(path string) (*MacroMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var macros MacroMap
+	if err := yaml.Unmarshal(data, &macros); err != nil {
+		return nil, err
+	}
+	if len(macros.Macros) == 0 {
+		return nil, fmt.Errorf("no macros defined in %s", path)
+	}
+
+	for name, templates := range macros.Macros {
+		if !macroNameRegex.MatchString(name) {
+			return nil, fmt.Errorf("invalid macro name: %q", name)
+		}
+		for _, template := range templates {
+			// Arguments often complete an identifier, as in handle_$1
+			if macroSignatureRegex.FindStringSubmatch(macroArgRegex.ReplaceAllString(template, "x")) == nil {
+				return nil, fmt.Errorf("macro %s: template is not a function signature: %q", name, template)
+			}
+		}
+	}
+
+	return &macros, nil
+}
+
+var (
+	macroNameRegex      = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+	macroArgRegex       = regexp.MustCompile(`\$(\d+)`)
+	macroSignatureRegex = regexp.MustCompile(`^\s*(.*?)\b([A-Za-z_]\w*(?:::[A-Za-z_]\w*)*)\s*\((.*)\)\s*(?:const\s*)?[;{]?\s*$`)
+)
+
+// macroParser adds the functions declared through known macros to the ones
+// the wrapped parser finds.
+type macroParser struct {
+	LanguageParser
+	macros     *MacroMap
+	invocation *regexp.Regexp
+	language   string
+}
+
+func withMacros(parser LanguageParser, macros *MacroMap, language string) LanguageParser {
+	names := make([]string, 0, len(macros.Macros))
+	for name := range macros.Macros {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Rust macro invocations carry a bang, C and C++ ones don't
+	invocation := regexp.MustCompile(`^\s*(` + strings.Join(names, "|") + `)!?\s*\((.*)\)\s*;?\s*$`)
+
+	if language == "" {
+		language = "generic"
+	}
+
+	return &macroParser{LanguageParser: parser, macros: macros, invocation: invocation, language: language}
+}
+
+func (m *macroParser) ParseFile(filePath string) ([]Function, error) {
+	functions, err := m.LanguageParser.ParseFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := utils.ReadSourceFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, line := range utils.SplitLines(content) {
+		match := m.invocation.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		args := splitCppParameters(match[2])
+		for _, template := range m.macros.Macros[match[1]] {
+			fn, ok := expandMacro(template, args)
+			if !ok {
+				continue
+			}
+			fn.File = filePath
+			fn.Line = i + 1
+			fn.Language = m.language
+			fn.Metadata["macro"] = match[1]
+			fn.Metadata["expanded_from"] = strings.TrimSpace(line)
+			functions = append(functions, fn)
+		}
+	}
+
+	return functions, nil
+}
+
+// ParseMembers keeps the hierarchy working when the wrapped parser reports
+// members.
+func (m *macroParser) ParseMembers(filePath string) ([]Member, error) {
+	if memberParser, ok := m.LanguageParser.(MemberParser); ok {
+		return memberParser.ParseMembers(filePath)
+	}
+	return nil, nil
+}
+
+// expandMacro substitutes the macro arguments into a template and reads the
+// resulting signature. Templates referring to a missing argument are skipped.
+func expandMacro(template string, args []string) (Function, bool) {
+	missing := false
+	signature := macroArgRegex.ReplaceAllStringFunc(template, func(ref string) string {
+		n, _ := strconv.Atoi(ref[1:])
+		if n < 1 || n > len(args) || args[n-1] == "" {
+			missing = true
+			return ref
+		}
+		return args[n-1]
+	})
+	if missing {
+		return Function{}, false
+	}
+
+	match := macroSignatureRegex.FindStringSubmatch(signature)
+	if match == nil {
+		return Function{}, false
+	}
+
+	returnType := strings.TrimSpace(match[1])
+	name := match[2]
+	params := match[3]
+
+	visibility := "public"
+	if strings.HasPrefix(returnType, "static ") {
+		visibility = "private"
+		returnType = strings.TrimSpace(strings.TrimPrefix(returnType, "static "))
+	}
+
+	fn := Function{
+		Name:       name,
+		Visibility: visibility,
+		ReturnType: returnType,
+		Parameters: parseCppParameters(params),
+		ParamTypes: ParameterTypes(params),
+		Signature:  strings.TrimSpace(signature),
+		IsMain:     name == "main",
+		Metadata:   map[string]string{},
+	}
+	if idx := strings.LastIndex(name, "::"); idx != -1 {
+		fn.Scope = name[:idx]
+	}
+
+	if strings.HasSuffix(strings.TrimSpace(signature), "{") {
+		fn.Metadata["definition"] = "true"
+	} else {
+		fn.Metadata["declaration"] = "true"
+	}
+
+	return fn, true
+}
@@ -0,0 +1,208 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Module is a directory holding a README or a CMakeLists.txt, with the
+// functions of the files below it that no nested module claims.
+type Module struct {
+	Path      string     `json:"path" yaml:"path"`
+	Readme    string     `json:"readme,omitempty" yaml:"readme,omitempty"`
+	Summary   string     `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Files     int        `json:"files" yaml:"files"`
+	Functions []Function `json:"functions" yaml:"functions"`
+}
+
+var (
+	readmeNames = []string{"README.md", "README.rst", "README.txt", "README", "readme.md"}
+	// project(name VERSION 1.0 DESCRIPTION "...")
+	cmakeDescriptionRegex = regexp.MustCompile(`(?is)\bproject\s*\([^)]*?\bDESCRIPTION\s+"([^"]*)"`)
+)
+
+const maxExcerpt = 600
+
+// buildModules groups functions by the nearest enclosing module directory.
+// Files outside every module belong to the "." module.
+func buildModules(functions []Function, files []string) []Module {
+	finder := moduleFinder{cache: make(map[string]string)}
+	modules := make(map[string]*Module)
+	module := func(path string) *Module {
+		if existing, ok := modules[path]; ok {
+			return existing
+		}
+		created := &Module{Path: path, Functions: []Function{}}
+		created.Readme, created.Summary = moduleDescription(path)
+		modules[path] = created
+		return created
+	}
+
+	for _, file := range files {
+		module(finder.find(file)).Files++
+	}
+	for _, fn := range functions {
+		m := module(finder.find(fn.File))
+		m.Functions = append(m.Functions, fn)
+	}
+
+	var result []Module
+	for _, m := range modules {
+		sort.SliceStable(m.Functions, func(i, j int) bool {
+			if m.Functions[i].File != m.Functions[j].File {
+				return m.Functions[i].File < m.Functions[j].File
+			}
+			return m.Functions[i].Line < m.Functions[j].Line
+		})
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	return result
+}
+
+type moduleFinder struct {
+	cache map[string]string
+}
+
+// find walks up from the directory of path to the first module directory.
+func (f *moduleFinder) find(path string) string {
+	dir := filepath.Dir(filepath.Clean(path))
+	var visited []string
+	module := "."
+	for {
+		if cached, ok := f.cache[dir]; ok {
+			module = cached
+			break
+		}
+		visited = append(visited, dir)
+		if isModuleDir(dir) {
+			module = dir
+			break
+		}
+		parent := filepath.Dir(dir)
+		if dir == "." || parent == dir {
+			break
+		}
+		dir = parent
+	}
+	for _, dir := range visited {
+		f.cache[dir] = module
+	}
+	return module
+}
+
+func isModuleDir(dir string) bool {
+	if readmePath(dir) != "" {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(dir, "CMakeLists.txt"))
+	return err == nil
+}
+
+func readmePath(dir string) string {
+	for _, name := range readmeNames {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// moduleDescription returns the README of a module and the excerpt shown
+// above its functions: the first paragraph of the README, or the
+// DESCRIPTION of the CMake project.
+func moduleDescription(dir string) (string, string) {
+	if path := readmePath(dir); path != "" {
+		if content, err := os.ReadFile(path); err == nil {
+			return filepath.ToSlash(path), readmeExcerpt(string(content))
+		}
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "CMakeLists.txt")); err == nil {
+		if match := cmakeDescriptionRegex.FindStringSubmatch(string(content)); match != nil {
+			return "", strings.TrimSpace(match[1])
+		}
+	}
+	return "", ""
+}
+
+// readmeExcerpt takes the first paragraph of prose, skipping titles, badges,
+// HTML and rst underlines.
+func readmeExcerpt(content string) string {
+	var paragraph []string
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		if len(paragraph) == 0 && isDecoration(trimmed) {
+			continue
+		}
+		if strings.Trim(trimmed, "=-~^*#") == "" {
+			// An rst underline makes the line above a title
+			paragraph = nil
+			continue
+		}
+		paragraph = append(paragraph, trimmed)
+	}
+
+	excerpt := strings.Join(paragraph, " ")
+	if len(excerpt) > maxExcerpt {
+		cut := strings.LastIndex(excerpt[:maxExcerpt], " ")
+		if cut <= 0 {
+			cut = maxExcerpt
+		}
+		excerpt = excerpt[:cut] + "…"
+	}
+	return excerpt
+}
+
+func isDecoration(line string) bool {
+	return strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[![") || strings.HasPrefix(line, "![") ||
+		strings.HasPrefix(line, "<") || strings.HasPrefix(line, "..") || strings.HasPrefix(line, "```")
+}
+
+func formatModules(modules []Module, config Config) string {
+	var sb strings.Builder
+
+	sb.WriteString("## Modules\n\n")
+	for _, m := range modules {
+		sb.WriteString(fmt.Sprintf("- [%s](#%s) — %d files, %d functions\n", m.Path, moduleAnchor(m.Path), m.Files, len(m.Functions)))
+	}
+	sb.WriteString("\n")
+
+	for _, m := range modules {
+		sb.WriteString(fmt.Sprintf("## Module %s\n\n", m.Path))
+		if m.Summary != "" {
+			sb.WriteString(fmt.Sprintf("> %s\n\n", m.Summary))
+		}
+		if m.Readme != "" {
+			sb.WriteString(fmt.Sprintf("See [%s](%s).\n\n", m.Readme, m.Readme))
+		}
+		writeFunctions(&sb, m.Functions, config)
+	}
+
+	return sb.String()
+}
+
+// moduleAnchor follows the heading anchors of GitHub-flavored markdown.
+func moduleAnchor(path string) string {
+	heading := strings.ToLower("module " + filepath.ToSlash(path))
+	var sb strings.Builder
+	for _, r := range heading {
+		switch {
+		case r == ' ':
+			sb.WriteRune('-')
+		case r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
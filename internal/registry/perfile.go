@@ -0,0 +1,222 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/utils"
+)
+
+// FileSummary is the compact view of one file that --per-file lists instead
+// of its functions.
+type FileSummary struct {
+	File      string   `json:"file" yaml:"file"`
+	Lines     int      `json:"lines" yaml:"lines"`
+	CodeLines int      `json:"code_lines" yaml:"code_lines"`
+	Functions int      `json:"functions" yaml:"functions"`
+	Methods   int      `json:"methods" yaml:"methods"`
+	Types     int      `json:"types" yaml:"types"`
+	Exported  []string `json:"exported" yaml:"exported"`
+	Includes  []string `json:"includes" yaml:"includes"`
+}
+
+var includeRegexes = map[string][]*regexp.Regexp{
+	"c":   {regexp.MustCompile(`^\s*#\s*include\s*[<"]([^>"]+)[>"]`)},
+	"cpp": {regexp.MustCompile(`^\s*#\s*include\s*[<"]([^>"]+)[>"]`)},
+	"python": {
+		regexp.MustCompile(`^import\s+([\w.]+(?:\s*,\s*[\w.]+)*)`),
+		regexp.MustCompile(`^from\s+([\w.]+)\s+import\b`),
+	},
+	"rust": {regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?use\s+([\w:]+)`)},
+}
+
+// summarizeFile counts the lines of a file and lists what it includes or
+// imports. Functions and types are counted later, once filtered.
+func summarizeFile(filePath, language string) FileSummary {
+	summary := FileSummary{File: filePath, Exported: []string{}, Includes: []string{}}
+	content, err := utils.ReadSourceFile(filePath)
+	if err != nil {
+		failures.Record(filePath, err)
+		return summary
+	}
+
+	code := content
+	if language != "python" {
+		code = utils.BlankCommentsAndLiterals(content)
+	}
+	lines := utils.SplitLines(strings.TrimSuffix(content, "\n"))
+	summary.Lines = len(lines)
+	for _, line := range utils.SplitLines(code) {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !(language == "python" && strings.HasPrefix(trimmed, "#")) {
+			summary.CodeLines++
+		}
+	}
+
+	if language == "go" {
+		if file, err := parser.ParseFile(token.NewFileSet(), filePath, content, parser.ImportsOnly); err == nil {
+			for _, spec := range file.Imports {
+				path, _ := strconv.Unquote(spec.Path.Value)
+				summary.Includes = append(summary.Includes, path)
+			}
+		}
+		return summary
+	}
+	for _, line := range lines {
+		for _, regex := range includeRegexes[language] {
+			match := regex.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			for _, name := range strings.Split(match[1], ",") {
+				summary.Includes = append(summary.Includes, strings.TrimSpace(name))
+			}
+		}
+	}
+	return summary
+}
+
+// buildFileSummaries adds to the summaries of summarizeFile the counts of the
+// functions and members found in each file, and the public ones by name.
+func buildFileSummaries(summaries []FileSummary, functions []Function, members []Member) []FileSummary {
+	byFile := make(map[string]*FileSummary, len(summaries))
+	for i := range summaries {
+		byFile[summaries[i].File] = &summaries[i]
+	}
+	scopeKinds := memberScopeKinds(members)
+
+	for _, fn := range functions {
+		summary, ok := byFile[fn.File]
+		if !ok {
+			continue
+		}
+		if functionKind(fn, scopeKinds) == "method" {
+			summary.Methods++
+		} else {
+			summary.Functions++
+		}
+		// Function names already carry their scope
+		if fn.Visibility == "public" {
+			summary.Exported = append(summary.Exported, fn.Name)
+		}
+	}
+
+	types := make(map[string]bool)
+	for _, kind := range kindAliases["types"] {
+		types[kind] = true
+	}
+	for _, member := range members {
+		summary, ok := byFile[member.File]
+		if !ok || !types[member.Kind] {
+			continue
+		}
+		summary.Types++
+		if member.Visibility != "private" && member.Visibility != "protected" {
+			name := member.Name
+			if member.Scope != "" {
+				name = member.Scope + "::" + member.Name
+			}
+			summary.Exported = append(summary.Exported, name)
+		}
+	}
+
+	for i := range summaries {
+		summaries[i].Exported = dedupeSorted(summaries[i].Exported)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].File < summaries[j].File })
+	return summaries
+}
+
+func dedupeSorted(names []string) []string {
+	sort.Strings(names)
+	result := names[:0]
+	for i, name := range names {
+		if i == 0 || name != names[i-1] {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func displayFileSummaries(summaries []FileSummary) []FileSummary {
+	if summaries == nil {
+		return nil
+	}
+	displayed := make([]FileSummary, len(summaries))
+	for i, summary := range summaries {
+		summary.File = utils.DisplayPath(summary.File)
+		displayed[i] = summary
+	}
+	return displayed
+}
+
+func formatFileSummariesText(summaries []FileSummary) string {
+	var sb strings.Builder
+
+	sb.WriteString("# File Summary\n\n")
+	sb.WriteString("| File | Lines | Code | Functions | Methods | Types | Exported | Includes |\n")
+	sb.WriteString("|------|-------|------|-----------|---------|-------|----------|----------|\n")
+	var lines, code, functions, methods, types int
+	for _, summary := range summaries {
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | %d | %s | %s |\n",
+			summary.File, summary.Lines, summary.CodeLines, summary.Functions, summary.Methods, summary.Types,
+			strings.Join(summary.Exported, ", "), strings.Join(summary.Includes, ", ")))
+		lines += summary.Lines
+		code += summary.CodeLines
+		functions += summary.Functions
+		methods += summary.Methods
+		types += summary.Types
+	}
+	sb.WriteString(fmt.Sprintf("| **Total** | %d | %d | %d | %d | %d | | |\n", lines, code, functions, methods, types))
+	return sb.String()
+}
+
+func formatFileSummariesCSV(summaries []FileSummary) ([]byte, error) {
+	var buf strings.Builder
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write([]string{"File", "Lines", "CodeLines", "Functions", "Methods", "Types", "Exported", "Includes"}); err != nil {
+		return nil, err
+	}
+	for _, summary := range summaries {
+		record := []string{
+			summary.File,
+			strconv.Itoa(summary.Lines),
+			strconv.Itoa(summary.CodeLines),
+			strconv.Itoa(summary.Functions),
+			strconv.Itoa(summary.Methods),
+			strconv.Itoa(summary.Types),
+			strings.Join(summary.Exported, ";"),
+			strings.Join(summary.Includes, ";"),
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return []byte(buf.String()), nil
+}
+
+func formatFileSummariesJSONL(summaries []FileSummary) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, summary := range summaries {
+		if err := encoder.Encode(summary); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
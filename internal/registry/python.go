@@ -1,9 +1,10 @@
 package registry
 
 import (
-	"os"
 	"regexp"
 	"strings"
+
+	"github.com/vitruves/gop/internal/utils"
 )
 
 type PythonParser struct{}
@@ -16,18 +17,20 @@ func (p *PythonParser) IsHeaderFile(filePath string) bool {
 	return false
 }
 
+var (
+	pythonDefRegex       = regexp.MustCompile(`^\s*(def|async def)\s+(\w+)\s*\((.*?)\)(?:\s*->\s*([^:]+))?\s*:`)
+	pythonClassRegex     = regexp.MustCompile(`^\s*class\s+(\w+)(?:\s*\([^)]*\))?\s*:`)
+	pythonDecoratorRegex = regexp.MustCompile(`^\s*@(\w+)`)
+)
+
 func (p *PythonParser) ParseFile(filePath string) ([]Function, error) {
-	content, err := os.ReadFile(filePath)
+	content, err := utils.ReadSourceFile(filePath)
 	if err != nil {
 		return nil, err
 	}
 
 	var functions []Function
-	lines := strings.Split(string(content), "\n")
-
-	defRegex := regexp.MustCompile(`^\s*(def|async def)\s+(\w+)\s*\((.*?)\)(?:\s*->\s*([^:]+))?\s*:`)
-	classRegex := regexp.MustCompile(`^\s*class\s+(\w+)(?:\s*\([^)]*\))?\s*:`)
-	decoratorRegex := regexp.MustCompile(`^\s*@(\w+)`)
+	lines := utils.SplitLines(content)
 
 	var currentClass string
 	var currentDecorators []string
@@ -36,13 +39,13 @@ func (p *PythonParser) ParseFile(filePath string) ([]Function, error) {
 		trimmed := strings.TrimSpace(line)
 
 		// Track decorators
-		if decoratorMatch := decoratorRegex.FindStringSubmatch(line); decoratorMatch != nil {
+		if decoratorMatch := pythonDecoratorRegex.FindStringSubmatch(line); decoratorMatch != nil {
 			currentDecorators = append(currentDecorators, decoratorMatch[1])
 			continue
 		}
 
 		// Track class context
-		if classMatch := classRegex.FindStringSubmatch(line); classMatch != nil {
+		if classMatch := pythonClassRegex.FindStringSubmatch(line); classMatch != nil {
 			currentClass = classMatch[1]
 			currentDecorators = nil
 			continue
@@ -59,7 +62,7 @@ func (p *PythonParser) ParseFile(filePath string) ([]Function, error) {
 		}
 
 		// Parse function definitions
-		if defMatch := defRegex.FindStringSubmatch(line); defMatch != nil {
+		if defMatch := pythonDefRegex.FindStringSubmatch(line); defMatch != nil {
 			fnType := defMatch[1]
 			name := defMatch[2]
 			params := defMatch[3]
@@ -94,12 +97,14 @@ func (p *PythonParser) ParseFile(filePath string) ([]Function, error) {
 				ReturnType: returnType,
 				Parameters: paramList,
 				Language:   "python",
+				Scope:      currentClass,
 				Signature:  strings.TrimSpace(line),
 				IsTest:     isTestFunction(name, currentDecorators),
 				IsMain:     name == "__main__" || (currentClass == "" && name == "main"),
 				Size:       calculatePythonFunctionSize(lines, i),
 				Comments:   comments,
 			}
+			fn.Complexity = calculateBranchComplexity(lines, i, fn.Size, pythonBranchRegex, "#")
 
 			if fnType == "async def" {
 				fn.Metadata = map[string]string{"async": "true"}
@@ -122,9 +127,76 @@ func (p *PythonParser) ParseFile(filePath string) ([]Function, error) {
 	return functions, nil
 }
 
+var pythonClassBasesRegex = regexp.MustCompile(`^(\s*)class\s+(\w+)\s*(?:\(([^)]*)\))?\s*:`)
+
+// ParseMembers reports classes with their base classes, nested classes are
+// scoped by indentation.
+func (p *PythonParser) ParseMembers(filePath string) ([]Member, error) {
+	content, err := utils.ReadSourceFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	type openClass struct {
+		name   string
+		indent int
+	}
+
+	var members []Member
+	var stack []openClass
+
+	for i, line := range utils.SplitLines(content) {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		indent := getIndentLevel(line)
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		classMatch := pythonClassBasesRegex.FindStringSubmatch(line)
+		if classMatch == nil {
+			continue
+		}
+
+		var scopeParts []string
+		for _, open := range stack {
+			scopeParts = append(scopeParts, open.name)
+		}
+
+		var bases []string
+		for _, base := range strings.Split(classMatch[3], ",") {
+			base = strings.TrimSpace(base)
+			if base == "" || base == "object" || strings.Contains(base, "=") {
+				continue
+			}
+			bases = append(bases, base)
+		}
+
+		visibility := "public"
+		if strings.HasPrefix(classMatch[2], "_") {
+			visibility = "private"
+		}
+
+		members = append(members, Member{
+			Name:       classMatch[2],
+			Kind:       "class",
+			Scope:      strings.Join(scopeParts, "::"),
+			File:       filePath,
+			Line:       i + 1,
+			Visibility: visibility,
+			Bases:      bases,
+		})
+
+		stack = append(stack, openClass{name: classMatch[2], indent: indent})
+	}
+
+	return members, nil
+}
+
 func (p *PythonParser) FindFunctionCalls(content string) []string {
-	callRegex := regexp.MustCompile(`(\w+)\s*\(`)
-	matches := callRegex.FindAllStringSubmatch(content, -1)
+	matches := wordCallRegex.FindAllStringSubmatch(content, -1)
 
 	seen := make(map[string]bool)
 	var calls []string
@@ -220,6 +292,8 @@ func extractPythonDocstring(lines []string, startLine int) string {
 	return ""
 }
 
+var pythonBranchRegex = regexp.MustCompile(`\b(if|elif|for|while|except|and|or)\b`)
+
 func calculatePythonFunctionSize(lines []string, startLine int) int {
 	if startLine >= len(lines) {
 		return 1
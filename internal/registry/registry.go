@@ -5,17 +5,20 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
-	"github.com/schollz/progressbar/v3"
-	"golang.org/x/sync/semaphore"
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/report"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
 	"gopkg.in/yaml.v3"
 )
 
@@ -28,10 +31,36 @@ type Config struct {
 	Jobs            int
 	Verbose         bool
 	OutputFile      string
+	Format          string
 	ByScript        bool
 	OnlyHeaderFiles bool
 	AddRelations    bool
 	OnlyDeadCode    bool
+	SkipGenerated   bool
+	SkipVendor      bool
+	MaxFileSize     int64
+	FollowSymlinks  bool
+	IncludeHidden   bool
+	NoProgress      bool
+	Manifest        *manifest.Manifest
+	GroupOverloads  bool
+	Hierarchy       bool
+	MacroMap        string
+	Template        string
+	// Modules groups functions by the directories holding a README or a
+	// CMakeLists.txt
+	Modules bool
+	// Types restricts the output to these kinds, see ParseKinds
+	Types []string
+	// PerFile lists one summary per file instead of the functions
+	PerFile bool
+	// Qualify is "full" to write ns::Class::method, "none" for the bare
+	// method name. Call relations use qualified names either way
+	Qualify string
+	// PublicOnly restricts the registry to the public functions of the
+	// headers under PublicRoots and reports the API they make up
+	PublicOnly  bool
+	PublicRoots []string
 }
 
 type Function struct {
@@ -41,7 +70,9 @@ type Function struct {
 	Visibility string            `json:"visibility" yaml:"visibility"`
 	ReturnType string            `json:"return_type" yaml:"return_type"`
 	Parameters []string          `json:"parameters" yaml:"parameters"`
+	ParamTypes []string          `json:"param_types,omitempty" yaml:"param_types,omitempty"`
 	Language   string            `json:"language" yaml:"language"`
+	Scope      string            `json:"scope,omitempty" yaml:"scope,omitempty"`
 	CallCount  int               `json:"call_count" yaml:"call_count"`
 	CalledBy   []string          `json:"called_by,omitempty" yaml:"called_by,omitempty"`
 	Calls      []string          `json:"calls,omitempty" yaml:"calls,omitempty"`
@@ -57,6 +88,10 @@ type Function struct {
 type Registry struct {
 	Functions []Function            `json:"functions" yaml:"functions"`
 	Scripts   map[string][]Function `json:"scripts,omitempty" yaml:"scripts,omitempty"`
+	Hierarchy *Scope                `json:"hierarchy,omitempty" yaml:"hierarchy,omitempty"`
+	Modules   []Module              `json:"modules,omitempty" yaml:"modules,omitempty"`
+	Files     []FileSummary         `json:"files,omitempty" yaml:"files,omitempty"`
+	API       *APISurface           `json:"api,omitempty" yaml:"api,omitempty"`
 	Summary   Summary               `json:"summary" yaml:"summary"`
 }
 
@@ -79,10 +114,47 @@ type LanguageParser interface {
 func Run(config Config) error {
 	logInfo(config.Verbose, "Starting function registry generation")
 
-	parser := getParser(config.Language)
+	parser := GetParser(config.Language)
 	if parser == nil {
 		return fmt.Errorf("unsupported language: %s", config.Language)
 	}
+	if config.Modules && config.Hierarchy {
+		return fmt.Errorf("--modules and --hierarchy cannot be combined")
+	}
+	if config.PerFile && (config.Modules || config.Hierarchy || config.ByScript) {
+		return fmt.Errorf("--per-file cannot be combined with --modules, --hierarchy or --by-script")
+	}
+	switch outputFormat(config) {
+	case "template", "text", "yaml", "json", "jsonl", "csv", "names":
+	default:
+		return fmt.Errorf("unsupported --format: %s (expected text, yaml, json, jsonl, csv or names)", config.Format)
+	}
+	if config.Qualify != "" && config.Qualify != "full" && config.Qualify != "none" {
+		return fmt.Errorf("unsupported --qualify: %s (expected full or none)", config.Qualify)
+	}
+	kinds, err := ParseKinds(config.Types)
+	if err != nil {
+		return fmt.Errorf("--types: %w", err)
+	}
+	if config.PublicOnly && config.Language != "c" && config.Language != "cpp" {
+		return fmt.Errorf("--public-only reads public headers, it needs -l c or -l cpp")
+	}
+	if config.PublicOnly && len(config.PublicRoots) == 0 {
+		config.PublicRoots = DefaultPublicRoots
+	}
+	if selectsMembers(kinds) && !config.Hierarchy {
+		return fmt.Errorf("--types: types, namespaces and fields are only listed with --hierarchy")
+	}
+
+	if config.MacroMap != "" {
+		macros, err := LoadMacroMap(config.MacroMap)
+		if err != nil {
+			logError(fmt.Sprintf("Failed to load macro map: %v", err))
+			return err
+		}
+		parser = withMacros(parser, macros, config.Language)
+		logInfo(config.Verbose, fmt.Sprintf("Expanding %d declaration macros", len(macros.Macros)))
+	}
 
 	files, err := collectFiles(config, parser)
 	if err != nil {
@@ -96,64 +168,83 @@ func Run(config Config) error {
 	}
 
 	logInfo(config.Verbose, fmt.Sprintf("Found %d files to analyze", len(files)))
+	config.Manifest.AddFiles(files)
+
+	// The other files are only read for the types they declare
+	var internalFiles []string
+	if config.PublicOnly {
+		files, internalFiles = splitPublic(files, config.PublicRoots, parser)
+		if len(files) == 0 {
+			logWarning(fmt.Sprintf("No headers found under the public roots %s", strings.Join(config.PublicRoots, ", ")))
+			return nil
+		}
+		logInfo(config.Verbose, fmt.Sprintf("Found %d public headers", len(files)))
+	}
+
+	// Relations, the hierarchy, modules and the types filter need every
+	// function before any can be written
+	if outputFormat(config) == "jsonl" && !config.AddRelations && !config.Hierarchy && !config.Modules && !config.PerFile && !config.PublicOnly && kinds == nil {
+		return runStreaming(config, parser, files)
+	}
 
 	registry := &Registry{
 		Functions: []Function{},
 		Scripts:   make(map[string][]Function),
 	}
 
-	bar := progressbar.NewOptions(len(files),
-		progressbar.OptionSetDescription("Analyzing functions"),
-		progressbar.OptionShowCount(),
-		progressbar.OptionSetRenderBlankState(true),
-		progressbar.OptionClearOnFinish(),
-	)
-
-	sem := semaphore.NewWeighted(int64(config.Jobs))
-	var mu sync.Mutex
-	var wg sync.WaitGroup
+	memberParser, parsesMembers := parser.(MemberParser)
 
-	allFunctions := make([][]Function, len(files))
-
-	for i, file := range files {
-		wg.Add(1)
-		go func(idx int, filePath string) {
-			defer wg.Done()
-			sem.Acquire(context.Background(), 1)
-			defer sem.Release(1)
+	reporter := progress.New("Analyzing functions", len(files), config.NoProgress)
+	parsed := worker.Run(files, config.Jobs, reporter, func(ctx context.Context, idx int, filePath string) (result parsedFile) {
+		functions, err := parser.ParseFile(filePath)
+		if err != nil {
+			failures.Record(filePath, err)
+			return result
+		}
 
-			functions, err := parser.ParseFile(filePath)
+		var members []Member
+		// Members tell methods from functions for the types filter and
+		// the per-file counts
+		if (config.Hierarchy || config.PerFile || config.PublicOnly || kinds != nil) && parsesMembers {
+			members, err = memberParser.ParseMembers(filePath)
 			if err != nil {
-				logError(fmt.Sprintf("Error parsing %s: %v", filePath, err))
-				return
+				failures.Record(filePath, err)
 			}
+		}
 
-			mu.Lock()
-			allFunctions[idx] = functions
-			bar.Add(1)
-			mu.Unlock()
-		}(i, file)
-	}
-
-	wg.Wait()
-	bar.Finish()
+		result.functions = functions
+		result.members = members
+		if config.PerFile {
+			result.summary = summarizeFile(filePath, config.Language)
+		}
+		return result
+	})
 
-	functionMap := make(map[string]*Function)
+	var members []Member
+	summaries := make([]FileSummary, len(files))
+	for i, file := range parsed {
+		members = append(members, file.members...)
+		summaries[i] = file.summary
+	}
+	scopeKinds := memberScopeKinds(members)
 
-	for i, functions := range allFunctions {
+	for i, file := range parsed {
+		functions := file.functions
 		if functions == nil {
 			continue
 		}
 
 		fileName := files[i]
+		if config.PublicOnly {
+			functions = publicFunctions(functions, scopeKinds)
+		}
 
-		for _, fn := range functions {
+		for _, fn := range filterFunctions(functions, kinds, scopeKinds) {
 			if config.OnlyDeadCode && fn.CallCount > 0 {
 				continue
 			}
 
 			registry.Functions = append(registry.Functions, fn)
-			functionMap[fn.Name] = &fn
 
 			if config.ByScript {
 				registry.Scripts[fileName] = append(registry.Scripts[fileName], fn)
@@ -167,6 +258,32 @@ func Run(config Config) error {
 
 	registry.Summary = generateSummary(registry.Functions, len(files))
 
+	if config.Hierarchy {
+		registry.Hierarchy = buildHierarchy(registry.Functions, filterMembers(members, kinds))
+	}
+
+	if config.Modules {
+		registry.Modules = buildModules(registry.Functions, files)
+	}
+
+	if config.PublicOnly {
+		registry.API = buildAPISurface(registry.Functions, members, len(files), internalFiles, parser, config)
+		for _, warning := range registry.API.Warnings {
+			logWarning(fmt.Sprintf("%s:%d: public function %s returns %s, %s", utils.DisplayPath(warning.File), warning.Line, warning.Function, warning.Type, warning.Reason))
+		}
+	}
+
+	if config.PerFile {
+		registry.Files = buildFileSummaries(summaries, registry.Functions, filterMembers(members, kinds))
+	}
+
+	if config.Qualify == "none" {
+		unqualifyFunctions(registry.Functions)
+		for _, functions := range registry.Scripts {
+			unqualifyFunctions(functions)
+		}
+	}
+
 	err = writeOutput(registry, config)
 	if err != nil {
 		logError(fmt.Sprintf("Failed to write output: %v", err))
@@ -177,7 +294,7 @@ func Run(config Config) error {
 	return nil
 }
 
-func getParser(language string) LanguageParser {
+func GetParser(language string) LanguageParser {
 	switch language {
 	case "python":
 		return &PythonParser{}
@@ -195,54 +312,24 @@ func getParser(language string) LanguageParser {
 }
 
 func collectFiles(config Config, parser LanguageParser) ([]string, error) {
-	var files []string
 	extensions := parser.GetExtensions()
 
-	startDir := "."
-	if len(config.Include) > 0 {
-		for _, path := range config.Include {
-			matches, err := filepath.Glob(path)
-			if err != nil {
-				return nil, err
-			}
-			for _, match := range matches {
-				if isValidFile(match, extensions, config, parser) {
-					files = append(files, match)
-				}
-			}
-		}
-		return files, nil
-	}
-
-	err := filepath.WalkDir(startDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if d.IsDir() {
-			if shouldExcludeDir(path, config.Exclude) {
-				return filepath.SkipDir
-			}
-			if !config.Recursive && path != startDir {
-				return filepath.SkipDir
-			}
-			if config.Depth > 0 {
-				relPath, _ := filepath.Rel(startDir, path)
-				if strings.Count(relPath, string(filepath.Separator)) >= config.Depth {
-					return filepath.SkipDir
-				}
-			}
-			return nil
-		}
-
-		if isValidFile(path, extensions, config, parser) {
-			files = append(files, path)
-		}
-
-		return nil
+	opts := utils.ScanOptions{
+		Include:        config.Include,
+		Exclude:        config.Exclude,
+		Recursive:      config.Recursive,
+		Depth:          config.Depth,
+		SkipGenerated:  config.SkipGenerated,
+		SkipVendor:     config.SkipVendor,
+		MaxFileSize:    config.MaxFileSize,
+		FollowSymlinks: config.FollowSymlinks,
+		IncludeHidden:  config.IncludeHidden,
+		Verbose:        config.Verbose,
+	}
+
+	return utils.GetFilesToProcess(opts, func(path string) bool {
+		return isValidFile(path, extensions, config, parser)
 	})
-
-	return files, err
 }
 
 func isValidFile(path string, extensions []string, config Config, parser LanguageParser) bool {
@@ -260,56 +347,93 @@ func isValidFile(path string, extensions []string, config Config, parser Languag
 	return false
 }
 
-func shouldExcludeDir(path string, exclude []string) bool {
-	excludeDirs := []string{".git", "node_modules", "__pycache__", ".pytest_cache", "target", "build", "dist", "vendor"}
-
-	for _, excludePattern := range exclude {
-		if matched, _ := filepath.Match(excludePattern, path); matched {
-			return true
-		}
-	}
-
-	for _, excludeDir := range excludeDirs {
-		if strings.Contains(path, excludeDir) {
-			return true
-		}
-	}
-
-	return false
-}
-
 func shouldExcludeFile(path string, exclude []string) bool {
-	for _, excludePattern := range exclude {
-		if matched, _ := filepath.Match(excludePattern, path); matched {
-			return true
-		}
-	}
-
-	return false
+	return utils.MatchesAny(path, exclude)
 }
 
 func addCallRelations(registry *Registry, files []string, parser LanguageParser, config Config) {
 	logInfo(config.Verbose, "Analyzing function call relationships")
 
-	functionMap := make(map[string]*Function)
+	// Calls only carry a name, so every overload sharing it is credited. A
+	// qualified call such as net::open credits the functions whose qualified
+	// name ends with it, a bare one every function of that name whatever its
+	// class or namespace, since the type of the receiver is not known
+	functionMap := make(map[string][]*Function)
 	for i := range registry.Functions {
-		functionMap[registry.Functions[i].Name] = &registry.Functions[i]
+		name := unqualifiedName(registry.Functions[i])
+		functionMap[name] = append(functionMap[name], &registry.Functions[i])
 	}
 
 	for _, file := range files {
-		content, err := os.ReadFile(file)
+		content, err := utils.ReadSourceFile(file)
 		if err != nil {
+			failures.Record(file, err)
 			continue
 		}
 
-		calls := parser.FindFunctionCalls(string(content))
+		calls := parser.FindFunctionCalls(content)
 
+		// A function is credited once per calling file, whether it is called
+		// by its bare or its qualified name
+		credited := make(map[*Function]bool)
 		for _, call := range calls {
-			if fn, exists := functionMap[call]; exists {
-				fn.CallCount++
+			name := lastComponent(call)
+			for _, fn := range functionMap[name] {
+				if (name == call || qualifiedBy(fn.Name, call)) && !credited[fn] {
+					credited[fn] = true
+					fn.CallCount++
+				}
+			}
+		}
+	}
+}
+
+// unqualifiedName returns the name of a function without its scope, the
+// class, namespace, module or receiver type the parsers prefix it with.
+func unqualifiedName(fn Function) string {
+	if fn.Scope != "" {
+		for _, separator := range []string{"::", "."} {
+			if prefix := fn.Scope + separator; strings.HasPrefix(fn.Name, prefix) {
+				return fn.Name[len(prefix):]
+			}
+		}
+	}
+	return lastComponent(fn.Name)
+}
+
+// lastComponent returns what follows the last :: or . of a name, template
+// arguments aside.
+func lastComponent(name string) string {
+	depth := 0
+	for i := len(name) - 1; i >= 0; i-- {
+		switch name[i] {
+		case '>':
+			depth++
+		case '<':
+			depth--
+		case ':':
+			if depth == 0 && i > 0 && name[i-1] == ':' {
+				return name[i+1:]
+			}
+		case '.':
+			if depth == 0 {
+				return name[i+1:]
 			}
 		}
 	}
+	return name
+}
+
+// qualifiedBy reports whether the qualified name of a function is, or ends
+// with, the qualified name of a call.
+func qualifiedBy(name, call string) bool {
+	return name == call || strings.HasSuffix(name, "::"+call) || strings.HasSuffix(name, "."+call)
+}
+
+func unqualifyFunctions(functions []Function) {
+	for i := range functions {
+		functions[i].Name = unqualifiedName(functions[i])
+	}
 }
 
 func generateSummary(functions []Function, totalFiles int) Summary {
@@ -338,20 +462,37 @@ func generateSummary(functions []Function, totalFiles int) Summary {
 }
 
 func writeOutput(registry *Registry, config Config) error {
+	registry = displayPaths(registry)
 	var output []byte
 	var err error
 
-	ext := filepath.Ext(config.OutputFile)
-
-	switch ext {
-	case ".yaml", ".yml":
-		output, err = yaml.Marshal(registry)
-	case ".json":
-		output, err = json.MarshalIndent(registry, "", "  ")
-	case ".csv":
-		output, err = formatCSV(registry)
+	switch outputFormat(config) {
+	case "template":
+		output, err = report.Render(config.Template, registry)
+	case "yaml":
+		output, err = yaml.Marshal(structuredView(registry))
+	case "json":
+		output, err = json.MarshalIndent(structuredView(registry), "", "  ")
+	case "jsonl":
+		if registry.Files != nil {
+			output, err = formatFileSummariesJSONL(registry.Files)
+		} else {
+			output, err = formatJSONL(registry.Functions)
+		}
+	case "csv":
+		if registry.Files != nil {
+			output, err = formatFileSummariesCSV(registry.Files)
+		} else {
+			output, err = formatCSV(registry)
+		}
+	case "names":
+		output = []byte(formatNames(registry))
 	default:
-		output = []byte(formatText(registry, config))
+		if registry.Files != nil {
+			output = []byte(formatFileSummariesText(registry.Files))
+		} else {
+			output = []byte(formatText(registry, config))
+		}
 	}
 
 	if err != nil {
@@ -359,13 +500,174 @@ func writeOutput(registry *Registry, config Config) error {
 	}
 
 	if config.OutputFile != "" {
+		config.Manifest.AddResult(config.OutputFile, output)
 		return os.WriteFile(config.OutputFile, output, 0644)
 	} else {
+		config.Manifest.AddResult("stdout", output)
 		fmt.Print(string(output))
 		return nil
 	}
 }
 
+// displayPaths returns a copy of the registry with every file path in the
+// selected path style.
+func displayPaths(registry *Registry) *Registry {
+	displayed := *registry
+	displayed.Functions = displayFunctions(registry.Functions)
+	if registry.Scripts != nil {
+		displayed.Scripts = make(map[string][]Function, len(registry.Scripts))
+		for file, functions := range registry.Scripts {
+			displayed.Scripts[utils.DisplayPath(file)] = displayFunctions(functions)
+		}
+	}
+	if registry.API != nil {
+		displayed.API = displayAPISurface(registry.API)
+	}
+	if registry.Hierarchy != nil {
+		displayed.Hierarchy = displayScope(registry.Hierarchy)
+	}
+	displayed.Files = displayFileSummaries(registry.Files)
+	if registry.Modules != nil {
+		displayed.Modules = make([]Module, len(registry.Modules))
+		for i, module := range registry.Modules {
+			module.Path = utils.DisplayPath(module.Path)
+			module.Readme = utils.DisplayPath(module.Readme)
+			module.Functions = displayFunctions(module.Functions)
+			displayed.Modules[i] = module
+		}
+	}
+	return &displayed
+}
+
+func displayFunctions(functions []Function) []Function {
+	if functions == nil {
+		return nil
+	}
+	displayed := make([]Function, len(functions))
+	for i, fn := range functions {
+		fn.File = utils.DisplayPath(fn.File)
+		displayed[i] = fn
+	}
+	return displayed
+}
+
+func displayScope(scope *Scope) *Scope {
+	displayed := *scope
+	displayed.File = utils.DisplayPath(scope.File)
+	displayed.Functions = displayFunctions(scope.Functions)
+	if scope.Fields != nil {
+		displayed.Fields = make([]Member, len(scope.Fields))
+		for i, field := range scope.Fields {
+			field.File = utils.DisplayPath(field.File)
+			displayed.Fields[i] = field
+		}
+	}
+	if scope.Children != nil {
+		displayed.Children = make([]*Scope, len(scope.Children))
+		for i, child := range scope.Children {
+			displayed.Children[i] = displayScope(child)
+		}
+	}
+	return &displayed
+}
+
+// outputFormat resolves --format, falling back to the output file extension.
+func outputFormat(config Config) string {
+	// A user template decides the layout, whatever the output extension
+	if config.Template != "" {
+		return "template"
+	}
+	if config.Format != "" {
+		return config.Format
+	}
+
+	switch filepath.Ext(config.OutputFile) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".json":
+		return "json"
+	case ".jsonl", ".ndjson":
+		return "jsonl"
+	case ".csv":
+		return "csv"
+	default:
+		return "text"
+	}
+}
+
+// formatNames lists one name per line, sorted and without duplicates: the
+// files with --per-file, the qualified types, fields and functions with
+// --hierarchy, and the functions otherwise.
+func formatNames(registry *Registry) string {
+	var names []string
+	switch {
+	case registry.Files != nil:
+		for _, summary := range registry.Files {
+			names = append(names, summary.File)
+		}
+	case registry.Hierarchy != nil:
+		names = scopeNames(registry.Hierarchy, "")
+	default:
+		for _, fn := range registry.Functions {
+			names = append(names, fn.Name)
+		}
+	}
+
+	sort.Strings(names)
+	var sb strings.Builder
+	for i, name := range names {
+		if i > 0 && name == names[i-1] {
+			continue
+		}
+		sb.WriteString(name + "\n")
+	}
+	return sb.String()
+}
+
+func scopeNames(scope *Scope, prefix string) []string {
+	var names []string
+	for _, field := range scope.Fields {
+		names = append(names, prefix+field.Name)
+	}
+	for _, fn := range scope.Functions {
+		names = append(names, fn.Name)
+	}
+	for _, child := range scope.Children {
+		// Namespaces only reopened to hold other scopes are not listed
+		if child.Kind != "" {
+			names = append(names, prefix+child.Name)
+		}
+		names = append(names, scopeNames(child, prefix+child.Name+"::")...)
+	}
+	return names
+}
+
+// structuredRegistry is a registry without the flat listings, which the
+// nested hierarchy, the modules or the file summaries replace.
+type structuredRegistry struct {
+	Hierarchy *Scope        `json:"hierarchy,omitempty" yaml:"hierarchy,omitempty"`
+	Modules   []Module      `json:"modules,omitempty" yaml:"modules,omitempty"`
+	Files     []FileSummary `json:"files,omitempty" yaml:"files,omitempty"`
+	API       *APISurface   `json:"api,omitempty" yaml:"api,omitempty"`
+	Summary   Summary       `json:"summary" yaml:"summary"`
+}
+
+// structuredView drops the flat listings when the nested hierarchy, the
+// modules or the file summaries replace them.
+func structuredView(registry *Registry) any {
+	if registry.Hierarchy == nil && registry.Modules == nil && registry.Files == nil {
+		return registry
+	}
+
+	return &structuredRegistry{
+		Hierarchy: registry.Hierarchy,
+		Modules:   registry.Modules,
+		Files:     registry.Files,
+		API:       registry.API,
+		Summary:   registry.Summary,
+	}
+}
+
 func formatText(registry *Registry, config Config) string {
 	var sb strings.Builder
 
@@ -380,7 +682,15 @@ func formatText(registry *Registry, config Config) string {
 	sb.WriteString(fmt.Sprintf("- Test Functions: %d\n", registry.Summary.TestFunctions))
 	sb.WriteString("\n")
 
-	if config.ByScript {
+	if registry.API != nil {
+		sb.WriteString(formatAPISurface(registry.API))
+	}
+
+	if registry.Hierarchy != nil {
+		sb.WriteString(formatHierarchy(registry.Hierarchy))
+	} else if registry.Modules != nil {
+		sb.WriteString(formatModules(registry.Modules, config))
+	} else if config.ByScript {
 		for file, functions := range registry.Scripts {
 			sb.WriteString(fmt.Sprintf("## %s\n\n", file))
 
@@ -388,9 +698,7 @@ func formatText(registry *Registry, config Config) string {
 				return functions[i].Line < functions[j].Line
 			})
 
-			for _, fn := range functions {
-				sb.WriteString(formatFunction(fn))
-			}
+			writeFunctions(&sb, functions, config)
 			sb.WriteString("\n")
 		}
 	} else {
@@ -402,12 +710,63 @@ func formatText(registry *Registry, config Config) string {
 		})
 
 		sb.WriteString("## Functions\n\n")
-		for _, fn := range registry.Functions {
+		writeFunctions(&sb, registry.Functions, config)
+	}
+
+	return sb.String()
+}
+
+func writeFunctions(sb *strings.Builder, functions []Function, config Config) {
+	if !config.GroupOverloads {
+		for _, fn := range functions {
 			sb.WriteString(formatFunction(fn))
 		}
+		return
 	}
 
-	return sb.String()
+	groups := make(map[string][]Function)
+	var order []string
+	for _, fn := range functions {
+		name := fn.Name
+		if idx := strings.Index(name, "<"); idx != -1 {
+			name = name[:idx]
+		}
+		if _, exists := groups[name]; !exists {
+			order = append(order, name)
+		}
+		groups[name] = append(groups[name], fn)
+	}
+
+	for _, name := range order {
+		overloads := groups[name]
+		if len(overloads) == 1 {
+			sb.WriteString(formatFunction(overloads[0]))
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("### %s (%d overloads)\n\n", name, len(overloads)))
+		for _, fn := range overloads {
+			entry := formatFunction(fn)
+			entry = strings.Replace(entry, "### "+fn.Name+"\n", "#### "+overloadKey(fn)+"\n", 1)
+			sb.WriteString(entry)
+		}
+	}
+}
+
+// overloadKey identifies a function by name, template parameters and
+// parameter types so overloads and specializations stay distinct.
+func overloadKey(fn Function) string {
+	key := fn.Name
+	if params := fn.Metadata["template_params"]; params != "" {
+		key = "template" + params + " " + key
+	}
+
+	types := fn.ParamTypes
+	if len(types) == 0 {
+		types = fn.Parameters
+	}
+
+	return key + "(" + strings.Join(types, ", ") + ")"
 }
 
 func formatFunction(fn Function) string {
@@ -504,22 +863,29 @@ func formatCSV(registry *Registry) ([]byte, error) {
 	return []byte(buf.String()), nil
 }
 
+// parsedFile is what the workers return for a file.
+type parsedFile struct {
+	functions []Function
+	members   []Member
+	summary   FileSummary
+}
+
 func logInfo(verbose bool, msg string) {
 	if verbose {
-		fmt.Printf("\033[34m%s - INFO: %s\033[0m\n", getCurrentTime(), msg)
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
 	}
 }
 
 func logSuccess(msg string) {
-	fmt.Printf("\033[32m%s - SUCCESS: %s\033[0m\n", getCurrentTime(), msg)
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
 }
 
 func logWarning(msg string) {
-	fmt.Printf("\033[33m%s - WARNING: %s\033[0m\n", getCurrentTime(), msg)
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
 }
 
 func logError(msg string) {
-	fmt.Printf("\033[31m%s - ERROR: %s\033[0m\n", getCurrentTime(), msg)
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
 }
 
 func getCurrentTime() string {
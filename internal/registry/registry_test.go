@@ -1,8 +1,13 @@
 package registry
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -193,4 +198,707 @@ func contains(slice []string, item string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+func TestCppOverloadsAndTemplates(t *testing.T) {
+	parser := &CppParser{}
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "overloads.cpp")
+	content := `int add(int a, int b) { return a + b; }
+double add(double a, double b) { return a + b; }
+template <typename T> T maxOf(T a, T b) { return a > b ? a : b; }
+template <>
+int maxOf<int>(int a, int b) { return a; }
+`
+
+	err := os.WriteFile(testFile, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	functions, err := parser.ParseFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	keys := make(map[string]bool)
+	for _, fn := range functions {
+		keys[overloadKey(fn)] = true
+	}
+
+	expected := []string{
+		"add(int, int)",
+		"add(double, double)",
+		"template<typename T> maxOf(T, T)",
+		"maxOf<int>(int, int)",
+	}
+	for _, key := range expected {
+		if !keys[key] {
+			t.Errorf("Expected overload key %q, got %v", key, keys)
+		}
+	}
+}
+
+func TestCppHierarchy(t *testing.T) {
+	parser := &CppParser{}
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "widget.hpp")
+	content := `namespace ui {
+class Widget
+{
+public:
+    int area() { return helper(width_); }
+    struct Inner {
+        int a;
+    };
+private:
+    int width_ = 0;
+};
+void free_function();
+}
+`
+
+	err := os.WriteFile(testFile, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	functions, err := parser.ParseFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	members, err := parser.ParseMembers(testFile)
+	if err != nil {
+		t.Fatalf("Failed to parse members: %v", err)
+	}
+
+	root := buildHierarchy(functions, members)
+	if len(root.Children) != 1 || root.Children[0].Kind != "namespace" {
+		t.Fatalf("Expected a single namespace at the top, got %+v", root.Children)
+	}
+
+	ui := root.Children[0]
+	if len(ui.Functions) != 1 || ui.Functions[0].Name != "ui::free_function" {
+		t.Errorf("free_function should belong to namespace ui after the class closes, got %+v", ui.Functions)
+	}
+
+	if len(ui.Children) != 1 || ui.Children[0].Name != "Widget" {
+		t.Fatalf("Expected class Widget in namespace ui, got %+v", ui.Children)
+	}
+
+	widget := ui.Children[0]
+	if len(widget.Functions) != 1 || widget.Functions[0].Visibility != "public" {
+		t.Errorf("Expected public method area in Widget, got %+v", widget.Functions)
+	}
+	if len(widget.Fields) != 1 || widget.Fields[0].Name != "width_" || widget.Fields[0].Visibility != "private" {
+		t.Errorf("Expected private field width_ in Widget, got %+v", widget.Fields)
+	}
+	if len(widget.Children) != 1 || widget.Children[0].Name != "Inner" || len(widget.Children[0].Fields) != 1 {
+		t.Errorf("Expected nested struct Inner with one field, got %+v", widget.Children)
+	}
+}
+
+func TestCppExplicitConstructor(t *testing.T) {
+	parser := &CppParser{}
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "meters.hpp")
+	content := `class Meters {
+public:
+    explicit Meters(double d) { value_ = d; }
+    constexpr Meters(int m) : value_(m) {}
+    double value() const { return value_; }
+private:
+    double value_;
+};
+inline Meters::Meters() {}
+`
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	functions, err := parser.ParseFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	var constructors []string
+	for _, fn := range functions {
+		if fn.Metadata["constructor"] == "true" {
+			if fn.ReturnType != "" {
+				t.Errorf("Constructor at line %d has return type %q", fn.Line, fn.ReturnType)
+			}
+			constructors = append(constructors, fmt.Sprintf("%s:%d", fn.Name, fn.Line))
+		}
+	}
+	expected := []string{"Meters::Meters:3", "Meters::Meters:4", "Meters::Meters:9"}
+	if !reflect.DeepEqual(constructors, expected) {
+		t.Errorf("Expected constructors %v, got %v", expected, constructors)
+	}
+	if len(functions) != 4 {
+		t.Errorf("Expected the three constructors and value, got %d functions", len(functions))
+	}
+	if len(functions) > 0 && functions[0].Metadata["explicit"] != "true" {
+		t.Errorf("Expected the explicit constructor to be marked explicit, got %v", functions[0].Metadata)
+	}
+}
+
+func TestMultiLineDeclarations(t *testing.T) {
+	content := `#ifdef __cplusplus
+extern "C" {
+#endif
+int resize(int width,
+           int height); // in pixels
+void draw(const char *name,
+          int x, int y) {
+    log_call(name,
+             x);
+}
+#ifdef __cplusplus
+}
+#endif
+`
+	tempDir := t.TempDir()
+	for _, parser := range []LanguageParser{&CParser{}, &CppParser{}} {
+		testFile := filepath.Join(tempDir, "shapes.h")
+		if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		functions, err := parser.ParseFile(testFile)
+		if err != nil {
+			t.Fatalf("Failed to parse file: %v", err)
+		}
+
+		// The call split over two lines in the body of draw is not a
+		// declaration
+		if len(functions) != 2 {
+			t.Fatalf("%T: expected resize and draw, got %+v", parser, functions)
+		}
+		resize, draw := functions[0], functions[1]
+		if resize.Name != "resize" || resize.Line != 4 || resize.Signature != "int resize(int width, int height);" ||
+			!reflect.DeepEqual(resize.Parameters, []string{"width", "height"}) || resize.Metadata["declaration"] != "true" {
+			t.Errorf("%T: unexpected declaration %+v", parser, resize)
+		}
+		if draw.Name != "draw" || draw.Line != 6 || draw.Metadata["definition"] != "true" || len(draw.Parameters) != 3 {
+			t.Errorf("%T: unexpected definition %+v", parser, draw)
+		}
+	}
+}
+
+func TestEmptyRegistryKeepsFunctions(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "empty.hpp")
+	if err := os.WriteFile(testFile, []byte("namespace ui {\nstruct Empty {};\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, hierarchy := range []bool{false, true} {
+		outputFile := filepath.Join(tempDir, "registry.json")
+		config := Config{Language: "cpp", Include: []string{testFile}, Jobs: 1, OutputFile: outputFile, Format: "json", Hierarchy: hierarchy, NoProgress: true}
+		if err := Run(config); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		output, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var registry map[string]any
+		if err := json.Unmarshal(output, &registry); err != nil {
+			t.Fatal(err)
+		}
+		// The hierarchy replaces the flat listing
+		if _, ok := registry["functions"]; ok == hierarchy {
+			t.Errorf("hierarchy=%v: unexpected presence of functions in %s", hierarchy, output)
+		}
+	}
+}
+
+func TestCppLineEndings(t *testing.T) {
+	parser := &CppParser{}
+	content := "namespace ui {\nclass Widget\n{\npublic:\n    int area() { return 1; }\nprivate:\n    int width_ = 0;\n};\n}\n"
+
+	parse := func(name, content string) ([]Function, []Member) {
+		testFile := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		functions, err := parser.ParseFile(testFile)
+		if err != nil {
+			t.Fatalf("Failed to parse file: %v", err)
+		}
+		members, err := parser.ParseMembers(testFile)
+		if err != nil {
+			t.Fatalf("Failed to parse members: %v", err)
+		}
+		return functions, members
+	}
+
+	lfFunctions, lfMembers := parse("lf.hpp", content)
+	crlfFunctions, crlfMembers := parse("crlf.hpp", strings.ReplaceAll(content, "\n", "\r\n"))
+	if len(lfMembers) == 0 {
+		t.Fatal("Expected members in the LF file")
+	}
+	if len(crlfMembers) != len(lfMembers) || len(crlfFunctions) != len(lfFunctions) {
+		t.Fatalf("CRLF file parsed to %d members and %d functions, want %d and %d", len(crlfMembers), len(crlfFunctions), len(lfMembers), len(lfFunctions))
+	}
+	for i := range lfMembers {
+		lf, crlf := lfMembers[i], crlfMembers[i]
+		if lf.Name != crlf.Name || lf.Scope != crlf.Scope || lf.Line != crlf.Line || lf.Visibility != crlf.Visibility {
+			t.Errorf("CRLF member %+v, want %+v", crlf, lf)
+		}
+	}
+	for i := range lfFunctions {
+		if lfFunctions[i].Name != crlfFunctions[i].Name || lfFunctions[i].Line != crlfFunctions[i].Line {
+			t.Errorf("CRLF function %s:%d, want %s:%d", crlfFunctions[i].Name, crlfFunctions[i].Line, lfFunctions[i].Name, lfFunctions[i].Line)
+		}
+	}
+}
+
+func TestJSONLStreaming(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "lib.py")
+	outputFile := filepath.Join(tempDir, "functions.jsonl")
+	content := `
+def first():
+    pass
+
+def second(a, b):
+    return a + b
+`
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config := Config{
+		Language:   "python",
+		Include:    []string{testFile},
+		Jobs:       1,
+		OutputFile: outputFile,
+		NoProgress: true,
+	}
+
+	if err := Run(config); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	output, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one line per function, got %d: %s", len(lines), output)
+	}
+
+	var fn Function
+	if err := json.Unmarshal([]byte(lines[1]), &fn); err != nil {
+		t.Fatalf("Line is not a JSON object: %v", err)
+	}
+	if fn.Name != "second" || len(fn.Parameters) != 2 {
+		t.Errorf("Expected second(a, b), got %+v", fn)
+	}
+}
+
+func TestMacroMap(t *testing.T) {
+	tempDir := t.TempDir()
+	macroFile := filepath.Join(tempDir, "macros.yaml")
+	testFile := filepath.Join(tempDir, "handlers.c")
+
+	macros := `macros:
+  DECLARE_HANDLER:
+    - "int handle_$1(struct request *req);"
+  DECLARE_ACCESSORS:
+    - "$2 get_$1(void);"
+    - "void set_$1($2 value);"
+`
+	content := `DECLARE_HANDLER(login);
+DECLARE_ACCESSORS(timeout, int);
+DECLARE_HANDLER();
+
+int main(void) {
+    return 0;
+}
+`
+
+	if err := os.WriteFile(macroFile, []byte(macros), 0644); err != nil {
+		t.Fatalf("Failed to create macro map: %v", err)
+	}
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	macroMap, err := LoadMacroMap(macroFile)
+	if err != nil {
+		t.Fatalf("Failed to load macro map: %v", err)
+	}
+
+	functions, err := withMacros(&CParser{}, macroMap, "c").ParseFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	byName := make(map[string]Function)
+	for _, fn := range functions {
+		byName[fn.Name] = fn
+	}
+
+	if len(functions) != 4 {
+		t.Errorf("Expected main and 3 expanded functions, got %d: %+v", len(functions), functions)
+	}
+
+	handler, ok := byName["handle_login"]
+	if !ok {
+		t.Fatal("Expected handle_login to be expanded from DECLARE_HANDLER")
+	}
+	if handler.Line != 1 || handler.ReturnType != "int" || handler.Metadata["macro"] != "DECLARE_HANDLER" {
+		t.Errorf("Unexpected expansion: %+v", handler)
+	}
+
+	setter := byName["set_timeout"]
+	if len(setter.ParamTypes) != 1 || setter.ParamTypes[0] != "int" {
+		t.Errorf("Expected set_timeout(int), got %+v", setter)
+	}
+	if byName["get_timeout"].ReturnType != "int" {
+		t.Errorf("Expected get_timeout to return int, got %+v", byName["get_timeout"])
+	}
+}
+
+func TestModules(t *testing.T) {
+	tempDir := t.TempDir()
+	readme := "# Codec\n\n[![CI](badge.svg)](ci)\n\nEncodes and decodes\nframes.\n\nMore details.\n"
+	sources := map[string]string{
+		"codec/README.md":     readme,
+		"codec/encode.c":      "int encode(int x) {\n    return x;\n}\n",
+		"codec/impl/decode.c": "int decode(int x) {\n    return x;\n}\n",
+		"net/CMakeLists.txt":  "project(net VERSION 1.0 DESCRIPTION \"Socket helpers\")\n",
+		"net/socket.c":        "int open_socket(void) {\n    return 0;\n}\n",
+		"tools/main.c":        "int main(void) {\n    return 0;\n}\n",
+	}
+	var files []string
+	var functions []Function
+	for name, content := range sources {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		if filepath.Ext(name) == ".c" {
+			files = append(files, path)
+			functions = append(functions, Function{Name: strings.TrimSuffix(filepath.Base(name), ".c"), File: path, Line: 1})
+		}
+	}
+
+	modules := buildModules(functions, files)
+	if len(modules) != 3 {
+		t.Fatalf("Expected 3 modules, got %+v", modules)
+	}
+	outside, codec, net := modules[0], modules[1], modules[2]
+	if outside.Path != "." || len(outside.Functions) != 1 {
+		t.Errorf("Expected tools/main.c outside every module, got %+v", outside)
+	}
+	if filepath.Base(codec.Path) != "codec" || codec.Files != 2 || len(codec.Functions) != 2 {
+		t.Errorf("Unexpected codec module: %+v", codec)
+	}
+	if codec.Summary != "Encodes and decodes frames." {
+		t.Errorf("Unexpected README excerpt: %q", codec.Summary)
+	}
+	if filepath.Base(net.Path) != "net" || net.Summary != "Socket helpers" || net.Readme != "" {
+		t.Errorf("Unexpected net module: %+v", net)
+	}
+
+	text := formatModules(modules, Config{})
+	if !strings.Contains(text, "## Module "+codec.Path+"\n\n> Encodes and decodes frames.\n") {
+		t.Errorf("Module section missing its excerpt:\n%s", text)
+	}
+}
+
+func TestParseKinds(t *testing.T) {
+	kinds, err := ParseKinds([]string{"functions", "Types"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kind := range []string{"function", "class", "struct", "union", "enum", "interface"} {
+		if !kinds[kind] {
+			t.Errorf("Expected %s to be selected, got %v", kind, kinds)
+		}
+	}
+	if kinds["method"] {
+		t.Error("method should not be selected by functions or types")
+	}
+
+	if _, err := ParseKinds([]string{"function", "constant"}); err == nil || !strings.Contains(err.Error(), "enumerator") {
+		t.Errorf("Expected an error listing the accepted kinds, got %v", err)
+	}
+	if kinds, err := ParseKinds(nil); kinds != nil || err != nil {
+		t.Errorf("No values should select everything, got %v, %v", kinds, err)
+	}
+}
+
+func TestFilterFunctionKinds(t *testing.T) {
+	members := []Member{{Name: "ui", Kind: "namespace"}, {Name: "Widget", Kind: "class", Scope: "ui"}}
+	functions := []Function{
+		{Name: "main"},
+		{Name: "ui::free_function", Scope: "ui"},
+		{Name: "ui::Widget::area", Scope: "ui::Widget"},
+	}
+	scopeKinds := memberScopeKinds(members)
+
+	methods := filterFunctions(functions, map[string]bool{"method": true}, scopeKinds)
+	if len(methods) != 1 || methods[0].Name != "ui::Widget::area" {
+		t.Errorf("Expected only the method, got %+v", methods)
+	}
+	free := filterFunctions(functions, map[string]bool{"function": true}, scopeKinds)
+	if len(free) != 2 {
+		t.Errorf("Expected the two namespace-level functions, got %+v", free)
+	}
+}
+
+func TestFileSummaries(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "widget.cpp")
+	content := "#include <vector>\n#include \"widget.h\"\n\n// Widget area\nnamespace ui {\nclass Widget {\npublic:\n    int area() { return 1; }\n};\n}\nstatic int helper() { return 2; }\nint run() { return helper(); }\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	parser := &CppParser{}
+	functions, err := parser.ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	members, err := parser.ParseMembers(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summaries := buildFileSummaries([]FileSummary{summarizeFile(path, "cpp")}, functions, members)
+
+	summary := summaries[0]
+	if summary.Lines != 12 || summary.CodeLines != 10 {
+		t.Errorf("Expected 12 lines, 10 of code, got %d and %d", summary.Lines, summary.CodeLines)
+	}
+	if summary.Functions != 2 || summary.Methods != 1 || summary.Types != 1 {
+		t.Errorf("Expected 2 functions, 1 method and 1 type, got %+v", summary)
+	}
+	if strings.Join(summary.Includes, ",") != "vector,widget.h" {
+		t.Errorf("Expected the two includes, got %v", summary.Includes)
+	}
+	exported := strings.Join(summary.Exported, ",")
+	if !strings.Contains(exported, "ui::Widget,") || !strings.Contains(exported, "run") {
+		t.Errorf("Expected the class and run to be exported, got %v", summary.Exported)
+	}
+}
+
+// benchmarkSource repeats a small function in the syntax of each language,
+// enough for the per-file cost to dominate.
+var benchmarkSource = map[string]string{
+	"c":      "/* TODO: check */\nstatic int add_%d(int a, int b) {\n    if (a > b && b > 0) {\n        return helper(a) + b;\n    }\n    return a;\n}\n\n",
+	"cpp":    "// TODO: check\nint Math::add_%d(int a, int b) const {\n    if (a > b && b > 0) {\n        return helper(a) + b;\n    }\n    return a;\n}\n\n",
+	"python": "def add_%d(a, b):\n    # TODO: check\n    if a > b and b > 0:\n        return helper(a) + b\n    return a\n\n",
+	"rust":   "pub fn add_%d(a: i32, b: i32) -> i32 {\n    // TODO: check\n    if a > b && b > 0 {\n        return helper(a) + b;\n    }\n    a\n}\n\n",
+}
+
+var benchmarkExtensions = map[string]string{"c": ".c", "cpp": ".cpp", "python": ".py", "rust": ".rs"}
+
+// BenchmarkParseFile parses one file per iteration from parallel workers, as
+// function-registry does, to measure the throughput of the shared matchers.
+func BenchmarkParseFile(b *testing.B) {
+	for _, language := range []string{"c", "cpp", "python", "rust"} {
+		b.Run(language, func(b *testing.B) {
+			var sb strings.Builder
+			for i := 0; i < 20; i++ {
+				sb.WriteString(fmt.Sprintf(benchmarkSource[language], i))
+			}
+			path := filepath.Join(b.TempDir(), "bench"+benchmarkExtensions[language])
+			if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+				b.Fatal(err)
+			}
+			parser := GetParser(language)
+
+			b.SetBytes(int64(sb.Len()))
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := parser.ParseFile(path); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestQualifiedCallRelations(t *testing.T) {
+	definitions := `namespace net {
+class Socket {
+public:
+    int open(int port) { return port; }
+};
+int open(int x) { return x; }
+}
+class File {
+public:
+    int open(int mode) { return mode; }
+    int close();
+};
+`
+	calls := `int main() {
+    net::open(3);
+    File f;
+    return f.close();
+}
+`
+	tempDir := t.TempDir()
+	definitionFile := filepath.Join(tempDir, "net.hpp")
+	callFile := filepath.Join(tempDir, "main.cpp")
+	if err := os.WriteFile(definitionFile, []byte(definitions), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(callFile, []byte(calls), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	parser := &CppParser{}
+	functions, err := parser.ParseFile(definitionFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := &Registry{Functions: functions}
+	// Only the calls of main.cpp are counted, definitions read as calls too
+	addCallRelations(registry, []string{callFile}, parser, Config{})
+
+	want := map[string]int{"net::Socket::open": 0, "net::open": 1, "File::open": 0, "File::close": 1}
+	for _, fn := range registry.Functions {
+		if count, ok := want[fn.Name]; ok && fn.CallCount != count {
+			t.Errorf("%s called %d times, want %d", fn.Name, fn.CallCount, count)
+		}
+		delete(want, fn.Name)
+	}
+	for name := range want {
+		t.Errorf("Expected a function named %s", name)
+	}
+
+	unqualifyFunctions(registry.Functions)
+	for _, fn := range registry.Functions {
+		if strings.Contains(fn.Name, "::") {
+			t.Errorf("Expected bare names with --qualify none, got %s", fn.Name)
+		}
+	}
+}
+
+func TestNamesFormat(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "widget.hpp")
+	content := "namespace ui {\nclass Widget {\npublic:\n    int area() { return 1; }\n    int width_ = 0;\n};\nint zoom() { return 2; }\nint zoom(int level) { return level; }\n}\n"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		hierarchy bool
+		types     []string
+		expected  string
+	}{
+		{false, nil, "ui::Widget::area\nui::zoom\n"},
+		{false, []string{"method"}, "ui::Widget::area\n"},
+		{true, []string{"class", "field"}, "ui::Widget\nui::Widget::width_\n"},
+	}
+	for _, test := range tests {
+		outputFile := filepath.Join(tempDir, "names.txt")
+		config := Config{
+			Language:   "cpp",
+			Include:    []string{testFile},
+			Jobs:       1,
+			OutputFile: outputFile,
+			Format:     "names",
+			Hierarchy:  test.hierarchy,
+			Types:      test.types,
+			NoProgress: true,
+		}
+		if err := Run(config); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		output, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(output) != test.expected {
+			t.Errorf("hierarchy=%v types=%v: expected %q, got %q", test.hierarchy, test.types, test.expected, output)
+		}
+	}
+
+	config := Config{Language: "cpp", Include: []string{testFile}, Format: "xml", NoProgress: true}
+	if err := Run(config); err == nil || !strings.Contains(err.Error(), "unsupported --format") {
+		t.Errorf("Expected an unknown format to be rejected, got %v", err)
+	}
+}
+
+func TestPublicOnly(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"include/lib/api.hpp": "namespace lib {\nclass Widget {\npublic:\n    int area();\n    Secret leak();\nprivate:\n    struct Secret { int a; };\n    int hidden();\n};\nstruct Point { int x; };\nPoint origin();\nState* current_state();\nstatic int helper();\n}\n",
+		"src/state.hpp":       "struct State { int level; };\nint internal_only();\n",
+		"src/widget.cpp":      "int lib::Widget::area() { return 1; }\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outputFile := filepath.Join(dir, "api.json")
+	config := Config{
+		Language:    "cpp",
+		Include:     []string{filepath.Join(dir, "include", "lib", "*"), filepath.Join(dir, "src", "*")},
+		Jobs:        1,
+		OutputFile:  outputFile,
+		NoProgress:  true,
+		PublicOnly:  true,
+		PublicRoots: []string{filepath.Join(dir, "include")},
+	}
+	if err := Run(config); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var registry Registry
+	if err := json.Unmarshal(data, &registry); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, fn := range registry.Functions {
+		names = append(names, fn.Name)
+	}
+	sort.Strings(names)
+	if strings.Join(names, " ") != "lib::Widget::area lib::Widget::leak lib::current_state lib::origin" {
+		t.Errorf("Expected the public functions of the public header only, got %v", names)
+	}
+
+	api := registry.API
+	if api == nil || api.Headers != 1 || api.Functions != 2 || api.Methods != 2 || api.Types["class"] != 1 || api.Types["struct"] != 1 || api.Total != 6 {
+		t.Fatalf("Unexpected API surface: %+v", api)
+	}
+	if len(api.Warnings) != 2 || api.Warnings[0].Type != "Secret" || !strings.Contains(api.Warnings[0].Reason, "private") ||
+		api.Warnings[1].Function != "lib::current_state" || !strings.Contains(api.Warnings[1].Reason, "state.hpp") {
+		t.Errorf("Expected warnings for Secret and State, got %+v", api.Warnings)
+	}
+
+	config.Language = "python"
+	if err := Run(config); err == nil || !strings.Contains(err.Error(), "--public-only") {
+		t.Errorf("Expected --public-only to need c or cpp, got %v", err)
+	}
+}
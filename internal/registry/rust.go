@@ -1,9 +1,10 @@
 package registry
 
 import (
-	"os"
 	"regexp"
 	"strings"
+
+	"github.com/vitruves/gop/internal/utils"
 )
 
 type RustParser struct{}
@@ -16,21 +17,25 @@ func (r *RustParser) IsHeaderFile(filePath string) bool {
 	return false
 }
 
+var (
+	rustFunctionRegex = regexp.MustCompile(`^\s*(pub\s+)?(unsafe\s+)?(extern\s+"[^"]+"\s+)?(async\s+)?fn\s+(\w+)\s*(<[^>]*>)?\s*\((.*?)\)(?:\s*->\s*([^{]+))?\s*\{`)
+	rustImplRegex     = regexp.MustCompile(`^\s*impl\s*(<[^>]*>)?\s*(\w+)(?:<[^>]*>)?(?:\s+for\s+(\w+))?`)
+	rustTraitRegex    = regexp.MustCompile(`^\s*(pub\s+)?trait\s+(\w+)`)
+	rustAttrRegex     = regexp.MustCompile(`^\s*#\[([^\]]+)\]`)
+)
+
 func (r *RustParser) ParseFile(filePath string) ([]Function, error) {
-	content, err := os.ReadFile(filePath)
+	content, err := utils.ReadSourceFile(filePath)
 	if err != nil {
 		return nil, err
 	}
 
 	var functions []Function
-	lines := strings.Split(string(content), "\n")
+	lines := utils.SplitLines(content)
 	
-	fnRegex := regexp.MustCompile(`^\s*(pub\s+)?(unsafe\s+)?(extern\s+"[^"]+"\s+)?(async\s+)?fn\s+(\w+)\s*(<[^>]*>)?\s*\((.*?)\)(?:\s*->\s*([^{]+))?\s*\{`)
-	implRegex := regexp.MustCompile(`^\s*impl\s*(<[^>]*>)?\s*(\w+)`)
-	traitRegex := regexp.MustCompile(`^\s*(pub\s+)?trait\s+(\w+)`)
-	attrRegex := regexp.MustCompile(`^\s*#\[([^\]]+)\]`)
 	
 	var currentImpl string
+	var currentImplTrait string
 	var currentTrait string
 	var currentAttributes []string
 	
@@ -38,29 +43,36 @@ func (r *RustParser) ParseFile(filePath string) ([]Function, error) {
 		trimmed := strings.TrimSpace(line)
 		
 		// Track attributes
-		if attrMatch := attrRegex.FindStringSubmatch(line); attrMatch != nil {
+		if attrMatch := rustAttrRegex.FindStringSubmatch(line); attrMatch != nil {
 			currentAttributes = append(currentAttributes, attrMatch[1])
 			continue
 		}
 		
 		// Track impl blocks
-		if implMatch := implRegex.FindStringSubmatch(line); implMatch != nil {
+		if implMatch := rustImplRegex.FindStringSubmatch(line); implMatch != nil {
+			// Methods of "impl Trait for Type" belong to Type
 			currentImpl = implMatch[2]
+			currentImplTrait = ""
+			if implMatch[3] != "" {
+				currentImpl = implMatch[3]
+				currentImplTrait = implMatch[2]
+			}
 			currentTrait = ""
 			currentAttributes = nil
 			continue
 		}
 		
 		// Track trait definitions
-		if traitMatch := traitRegex.FindStringSubmatch(line); traitMatch != nil {
+		if traitMatch := rustTraitRegex.FindStringSubmatch(line); traitMatch != nil {
 			currentTrait = traitMatch[2]
 			currentImpl = ""
+			currentImplTrait = ""
 			currentAttributes = nil
 			continue
 		}
 		
 		// Parse function definitions
-		if fnMatch := fnRegex.FindStringSubmatch(line); fnMatch != nil {
+		if fnMatch := rustFunctionRegex.FindStringSubmatch(line); fnMatch != nil {
 			pubMod := strings.TrimSpace(fnMatch[1])
 			unsafeMod := strings.TrimSpace(fnMatch[2])
 			externMod := strings.TrimSpace(fnMatch[3])
@@ -74,11 +86,14 @@ func (r *RustParser) ParseFile(filePath string) ([]Function, error) {
 				returnType = "()"
 			}
 			
+			scope := currentImpl
+			if scope == "" {
+				scope = currentTrait
+			}
+
 			fullName := name
-			if currentImpl != "" {
-				fullName = currentImpl + "::" + name
-			} else if currentTrait != "" {
-				fullName = currentTrait + "::" + name
+			if scope != "" {
+				fullName = scope + "::" + name
 			}
 			
 			visibility := "private"
@@ -97,6 +112,7 @@ func (r *RustParser) ParseFile(filePath string) ([]Function, error) {
 				ReturnType: returnType,
 				Parameters: paramList,
 				Language:   "rust",
+				Scope:      scope,
 				Signature:  strings.TrimSpace(line),
 				IsTest:     isRustTestFunction(currentAttributes),
 				IsMain:     name == "main",
@@ -119,6 +135,9 @@ func (r *RustParser) ParseFile(filePath string) ([]Function, error) {
 			if generics != "" {
 				fn.Metadata["generic"] = "true"
 			}
+			if currentImplTrait != "" {
+				fn.Metadata["trait"] = currentImplTrait
+			}
 			if len(currentAttributes) > 0 {
 				fn.Metadata["attributes"] = strings.Join(currentAttributes, ",")
 			}
@@ -135,15 +154,108 @@ func (r *RustParser) ParseFile(filePath string) ([]Function, error) {
 	return functions, nil
 }
 
+var (
+	rustTypeRegex      = regexp.MustCompile(`^\s*(pub(?:\([^)]*\))?\s+)?(struct|enum|trait|union)\s+(\w+)(?:<[^>]*>)?\s*(?::\s*([^{]+))?`)
+	rustTraitImplRegex = regexp.MustCompile(`^\s*impl\s*(?:<[^>]*>)?\s*([\w:]+)(?:<[^>]*>)?\s+for\s+(\w+)`)
+	rustFieldRegex     = regexp.MustCompile(`^\s*(pub(?:\([^)]*\))?\s+)?(\w+)\s*:\s*([^,]+?)\s*,?\s*$`)
+)
+
+// ParseMembers reports structs, enums and traits with their fields and
+// supertraits. "impl Trait for Type" blocks are reported as impl members
+// carrying the trait as a base of the type.
+func (r *RustParser) ParseMembers(filePath string) ([]Member, error) {
+	content, err := utils.ReadSourceFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+
+	var members []Member
+	var currentStruct string
+
+	for i, line := range utils.SplitLines(content) {
+		trimmed := strings.TrimSpace(line)
+
+		if currentStruct != "" {
+			if strings.HasPrefix(trimmed, "}") {
+				currentStruct = ""
+			} else if fieldMatch := rustFieldRegex.FindStringSubmatch(line); fieldMatch != nil && !strings.HasPrefix(trimmed, "//") {
+				visibility := "private"
+				if fieldMatch[1] != "" {
+					visibility = "public"
+				}
+				members = append(members, Member{
+					Name:       fieldMatch[2],
+					Kind:       "field",
+					Type:       strings.TrimSpace(fieldMatch[3]),
+					Scope:      currentStruct,
+					File:       filePath,
+					Line:       i + 1,
+					Visibility: visibility,
+				})
+			}
+			continue
+		}
+
+		if implMatch := rustTraitImplRegex.FindStringSubmatch(line); implMatch != nil {
+			members = append(members, Member{
+				Name:  implMatch[2],
+				Kind:  "impl",
+				File:  filePath,
+				Line:  i + 1,
+				Bases: []string{implMatch[1]},
+			})
+			continue
+		}
+
+		typeMatch := rustTypeRegex.FindStringSubmatch(line)
+		if typeMatch == nil {
+			continue
+		}
+
+		visibility := "private"
+		if typeMatch[1] != "" {
+			visibility = "public"
+		}
+
+		var bases []string
+		if typeMatch[2] == "trait" {
+			for _, base := range strings.Split(typeMatch[4], "+") {
+				if base = strings.TrimSpace(base); base != "" {
+					bases = append(bases, base)
+				}
+			}
+		}
+
+		members = append(members, Member{
+			Name:       typeMatch[3],
+			Kind:       typeMatch[2],
+			File:       filePath,
+			Line:       i + 1,
+			Visibility: visibility,
+			Bases:      bases,
+		})
+
+		if typeMatch[2] == "struct" && strings.HasSuffix(trimmed, "{") {
+			currentStruct = typeMatch[3]
+		}
+	}
+
+	return members, nil
+}
+
+var (
+	rustCallRegex   = regexp.MustCompile(`(\w+)!\s*\(|(\w+)\s*\(`)
+	rustMethodRegex = regexp.MustCompile(`\.(\w+)\s*\(`)
+)
+
 func (r *RustParser) FindFunctionCalls(content string) []string {
 	// Rust function calls and macro invocations
-	callRegex := regexp.MustCompile(`(\w+)!\s*\(|(\w+)\s*\(`)
-	methodRegex := regexp.MustCompile(`\.(\w+)\s*\(`)
 	
 	var calls []string
 	seen := make(map[string]bool)
 	
-	matches := callRegex.FindAllStringSubmatch(content, -1)
+	matches := rustCallRegex.FindAllStringSubmatch(content, -1)
 	for _, match := range matches {
 		var call string
 		if match[1] != "" { // Macro call
@@ -159,7 +271,7 @@ func (r *RustParser) FindFunctionCalls(content string) []string {
 	}
 	
 	// Method calls
-	methodMatches := methodRegex.FindAllStringSubmatch(content, -1)
+	methodMatches := rustMethodRegex.FindAllStringSubmatch(content, -1)
 	for _, match := range methodMatches {
 		call := match[1]
 		if !seen[call] && !isRustBuiltin(call) {
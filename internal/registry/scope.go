@@ -0,0 +1,533 @@
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type Member struct {
+	Name       string   `json:"name" yaml:"name"`
+	Kind       string   `json:"kind" yaml:"kind"`
+	Type       string   `json:"type,omitempty" yaml:"type,omitempty"`
+	Scope      string   `json:"scope,omitempty" yaml:"scope,omitempty"`
+	File       string   `json:"file" yaml:"file"`
+	Line       int      `json:"line" yaml:"line"`
+	Visibility string   `json:"visibility,omitempty" yaml:"visibility,omitempty"`
+	Bases      []string `json:"bases,omitempty" yaml:"bases,omitempty"`
+}
+
+type Scope struct {
+	Name      string     `json:"name,omitempty" yaml:"name,omitempty"`
+	Kind      string     `json:"kind,omitempty" yaml:"kind,omitempty"`
+	File      string     `json:"file,omitempty" yaml:"file,omitempty"`
+	Line      int        `json:"line,omitempty" yaml:"line,omitempty"`
+	Fields    []Member   `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Functions []Function `json:"functions,omitempty" yaml:"functions,omitempty"`
+	Children  []*Scope   `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// MemberParser is implemented by parsers that can also report the types,
+// namespaces and fields enclosing the functions they extract.
+type MemberParser interface {
+	ParseMembers(filePath string) ([]Member, error)
+}
+
+type braceScope struct {
+	name   string
+	kind   string
+	access string
+	// declared is the access of the section of the enclosing type the
+	// scope is declared in
+	declared  string
+	bases     []string
+	moreBases bool
+	line      int
+	depth     int
+	parent    string
+}
+
+// braceScopes follows brace depth through a C-family file so each line can
+// be attributed to the namespace or type whose body it sits in.
+type braceScopes struct {
+	stack     []*braceScope
+	opened    []*braceScope
+	pending   *braceScope
+	depth     int
+	inComment bool
+}
+
+func (b *braceScopes) declare(name, kind, access string, bases []string, line int) {
+	b.pending = &braceScope{name: name, kind: kind, access: access, bases: bases, line: line}
+}
+
+// advance consumes the braces of a line, ignoring comments and literals. A
+// pending declaration becomes the current scope at its opening brace and is
+// dropped at a semicolon (forward declarations, variables of that type).
+func (b *braceScopes) advance(line string) {
+	for i := 0; i < len(line); i++ {
+		char := line[i]
+
+		if b.inComment {
+			if char == '*' && i+1 < len(line) && line[i+1] == '/' {
+				b.inComment = false
+				i++
+			}
+			continue
+		}
+
+		switch char {
+		case '/':
+			if i+1 < len(line) && line[i+1] == '/' {
+				return
+			}
+			if i+1 < len(line) && line[i+1] == '*' {
+				b.inComment = true
+				i++
+			}
+		case '"', '\'':
+			i = skipLiteral(line, i)
+		case '{':
+			b.depth++
+			if b.pending != nil {
+				b.pending.declared = b.currentAccess()
+				b.pending.depth = b.depth
+				b.pending.parent = b.path()
+				b.stack = append(b.stack, b.pending)
+				b.opened = append(b.opened, b.pending)
+				b.pending = nil
+			}
+		case '}':
+			if n := len(b.stack); n > 0 && b.stack[n-1].depth == b.depth {
+				b.stack = b.stack[:n-1]
+			}
+			if b.depth > 0 {
+				b.depth--
+			}
+		case ';':
+			b.pending = nil
+		}
+	}
+}
+
+func skipLiteral(line string, start int) int {
+	quote := line[start]
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return len(line) - 1
+}
+
+// atBodyLevel reports whether the current line sits directly in a namespace
+// or type body (or at file level) rather than inside a function body.
+func (b *braceScopes) atBodyLevel() bool {
+	if n := len(b.stack); n > 0 {
+		return b.depth == b.stack[n-1].depth
+	}
+	return b.depth == 0
+}
+
+func (b *braceScopes) top() *braceScope {
+	if n := len(b.stack); n > 0 {
+		return b.stack[n-1]
+	}
+	return nil
+}
+
+func (b *braceScopes) inRecord() bool {
+	top := b.top()
+	return top != nil && isRecordKind(top.kind)
+}
+
+// inEnum reports whether the current line sits directly in an enum body.
+func (b *braceScopes) inEnum() bool {
+	top := b.top()
+	return top != nil && top.kind == "enum" && b.depth == top.depth
+}
+
+func (b *braceScopes) currentType() string {
+	if b.inRecord() {
+		return b.top().name
+	}
+	return ""
+}
+
+func (b *braceScopes) currentAccess() string {
+	if b.inRecord() {
+		return b.top().access
+	}
+	return "public"
+}
+
+func (b *braceScopes) setAccess(access string) {
+	if top := b.top(); top != nil {
+		top.access = access
+	}
+}
+
+func (b *braceScopes) path() string {
+	var parts []string
+	for _, scope := range b.stack {
+		if scope.name != "" {
+			parts = append(parts, scope.name)
+		}
+	}
+	return strings.Join(parts, "::")
+}
+
+func (b *braceScopes) qualify(name string) string {
+	if path := b.path(); path != "" {
+		return path + "::" + name
+	}
+	return name
+}
+
+func isRecordKind(kind string) bool {
+	return kind == "class" || kind == "struct" || kind == "union"
+}
+
+// members reports every named scope that was opened so far.
+func (b *braceScopes) members(filePath string) []Member {
+	var members []Member
+	for _, scope := range b.opened {
+		if scope.name == "" {
+			continue
+		}
+		visibility := "public"
+		if strings.HasPrefix(scope.name, "_") {
+			visibility = "private"
+		} else if scope.declared == "private" || scope.declared == "protected" {
+			visibility = scope.declared
+		}
+		members = append(members, Member{
+			Name:       scope.name,
+			Kind:       scope.kind,
+			Scope:      scope.parent,
+			File:       filePath,
+			Line:       scope.line,
+			Visibility: visibility,
+			Bases:      scope.bases,
+		})
+	}
+	return members
+}
+
+var (
+	cppNamespaceRegex = regexp.MustCompile(`^\s*(?:inline\s+)?namespace(?:\s+([\w:]+))?\s*(\{.*)?$`)
+	cppLinkageRegex   = regexp.MustCompile(`^\s*extern\s+"C(?:\+\+)?"\s*(\{.*)?$`)
+	cppTypeRegex      = regexp.MustCompile(`^\s*(?:typedef\s+)?(class|struct|union|enum(?:\s+class|\s+struct)?)\s+(?:\w+\s+)*?(\w+)\s*(?:final\s*)?(?::([^;{]*))?(\{.*)?$`)
+	cppFieldRegex     = regexp.MustCompile(`^(.*?[\s\*&>])(\w+)\s*(\[[^\]]*\])?$`)
+	cppBitFieldRegex  = regexp.MustCompile(`\w\s*:\s*\d+\s*;`)
+)
+
+// matchCppScope recognizes lines declaring a namespace, class, struct, union,
+// enum or extern "C" block, along with any base classes listed on the line.
+func matchCppScope(line string) (string, string, []string, bool) {
+	if strings.Contains(line, "(") {
+		return "", "", nil, false
+	}
+
+	if match := cppNamespaceRegex.FindStringSubmatch(line); match != nil {
+		return match[1], "namespace", nil, true
+	}
+
+	if cppLinkageRegex.MatchString(line) {
+		return "", "linkage", nil, true
+	}
+
+	if match := cppTypeRegex.FindStringSubmatch(line); match != nil {
+		kind := match[1]
+		if strings.HasPrefix(kind, "enum") {
+			return match[2], "enum", nil, true
+		}
+		return match[2], kind, parseCppBases(match[3]), true
+	}
+
+	return "", "", nil, false
+}
+
+// parseCppBases turns "public Base, private virtual Mixin<T>" into the list
+// of base class names.
+func parseCppBases(clause string) []string {
+	if idx := strings.Index(clause, "{"); idx != -1 {
+		clause = clause[:idx]
+	}
+
+	var bases []string
+	for _, part := range splitCppParameters(clause) {
+		var words []string
+		for _, word := range strings.Fields(part) {
+			switch word {
+			case "public", "protected", "private", "virtual":
+				continue
+			}
+			words = append(words, word)
+		}
+		if len(words) > 0 {
+			bases = append(bases, normalizeCppType(strings.Join(words, " ")))
+		}
+	}
+
+	return bases
+}
+
+// addBases attaches a base clause continued on the line after the class name.
+func (b *braceScopes) addBases(clause string) bool {
+	if b.pending == nil || !isRecordKind(b.pending.kind) {
+		return false
+	}
+	b.pending.bases = append(b.pending.bases, parseCppBases(strings.TrimLeft(clause, ":,"))...)
+	b.continueBases(clause)
+	return true
+}
+
+// continueBases remembers whether a base clause ends in a comma, in which case
+// the next line lists more bases.
+func (b *braceScopes) continueBases(clause string) {
+	if b.pending != nil {
+		b.pending.moreBases = strings.HasSuffix(strings.TrimSpace(clause), ",")
+	}
+}
+
+// expectsBases reports whether the next line continues a base clause.
+func (b *braceScopes) expectsBases() bool {
+	return b.pending != nil && b.pending.moreBases
+}
+
+// matchCppField recognizes a single data member declaration such as
+// "std::vector<int> items_;" or "char name[32] = {0};".
+func matchCppField(line string) (Member, bool) {
+	if idx := strings.Index(line, "//"); idx != -1 {
+		line = strings.TrimSpace(line[:idx])
+	}
+
+	if !strings.HasSuffix(line, ";") || strings.Contains(line, "(") || cppBitFieldRegex.MatchString(line) {
+		return Member{}, false
+	}
+
+	for _, prefix := range []string{"using ", "typedef ", "friend ", "return ", "template", "static_assert", "#"} {
+		if strings.HasPrefix(line, prefix) {
+			return Member{}, false
+		}
+	}
+
+	decl := strings.TrimSpace(strings.TrimSuffix(line, ";"))
+	if idx := strings.IndexAny(decl, "={"); idx != -1 {
+		decl = strings.TrimSpace(decl[:idx])
+	}
+
+	match := cppFieldRegex.FindStringSubmatch(decl)
+	if match == nil {
+		return Member{}, false
+	}
+
+	typ := strings.TrimSpace(match[1])
+	if typ == "" || isCppKeyword(match[2]) || strings.Contains(strings.ReplaceAll(typ, "::", ""), ":") {
+		return Member{}, false
+	}
+
+	// Only single declarators are recognized, "int a, b;" is skipped
+	depth := 0
+	for _, char := range typ {
+		switch char {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				return Member{}, false
+			}
+		}
+	}
+
+	if match[3] != "" {
+		typ += "[]"
+	}
+
+	return Member{Name: match[2], Kind: "field", Type: normalizeCppType(typ)}, true
+}
+
+var enumeratorRegex = regexp.MustCompile(`^([A-Za-z_]\w*)\s*(?:\[\[[^\]]*\]\]\s*)?(?:=.*)?$`)
+
+// parseEnumerators reads the enumerators of an enum body line such as
+// "RED = 1, GREEN, // primary" and reports them as members of the enum.
+func parseEnumerators(line, enum, filePath string, lineNumber int) []Member {
+	if idx := strings.Index(line, "//"); idx != -1 {
+		line = line[:idx]
+	}
+	if idx := strings.Index(line, "/*"); idx != -1 {
+		line = line[:idx]
+	}
+	if idx := strings.Index(line, "}"); idx != -1 {
+		line = line[:idx]
+	}
+	if strings.HasPrefix(strings.TrimSpace(line), "#") {
+		return nil
+	}
+
+	var members []Member
+	for _, part := range splitCppParameters(line) {
+		match := enumeratorRegex.FindStringSubmatch(strings.TrimSpace(part))
+		if match == nil {
+			continue
+		}
+		members = append(members, Member{
+			Name:       match[1],
+			Kind:       "enumerator",
+			Scope:      enum,
+			File:       filePath,
+			Line:       lineNumber,
+			Visibility: "public",
+		})
+	}
+	return members
+}
+
+// splitQualified splits ns::Class<A::B>::method into its components without
+// breaking template arguments apart.
+func splitQualified(name string) []string {
+	var parts []string
+	depth := 0
+	start := 0
+
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case ':':
+			if depth == 0 && i+1 < len(name) && name[i+1] == ':' {
+				parts = append(parts, name[start:i])
+				start = i + 2
+				i++
+			}
+		}
+	}
+
+	return append(parts, name[start:])
+}
+
+func baseCppName(name string) string {
+	if idx := strings.Index(name, "<"); idx != -1 {
+		return name[:idx]
+	}
+	return name
+}
+
+// buildHierarchy nests functions and members under the scope that owns them.
+// The returned root holds free functions and top-level scopes.
+func buildHierarchy(functions []Function, members []Member) *Scope {
+	root := &Scope{}
+	nodes := map[string]*Scope{"": root}
+
+	var node func(path string) *Scope
+	node = func(path string) *Scope {
+		if existing, ok := nodes[path]; ok {
+			return existing
+		}
+		parts := splitQualified(path)
+		parent := node(strings.Join(parts[:len(parts)-1], "::"))
+		created := &Scope{Name: parts[len(parts)-1]}
+		parent.Children = append(parent.Children, created)
+		nodes[path] = created
+		return created
+	}
+
+	for _, member := range members {
+		if member.Kind == "impl" {
+			continue
+		}
+
+		if member.Kind == "field" || member.Kind == "method" || member.Kind == "enumerator" {
+			owner := node(member.Scope)
+			owner.Fields = append(owner.Fields, member)
+			continue
+		}
+
+		path := member.Name
+		if member.Scope != "" {
+			path = member.Scope + "::" + member.Name
+		}
+		scope := node(path)
+		if scope.Kind == "" || scope.File == "" {
+			scope.Kind = member.Kind
+			scope.File = member.File
+			scope.Line = member.Line
+		}
+	}
+
+	for _, fn := range functions {
+		owner := node(fn.Scope)
+		owner.Functions = append(owner.Functions, fn)
+	}
+
+	sortScope(root)
+	return root
+}
+
+func sortScope(scope *Scope) {
+	sort.Slice(scope.Children, func(i, j int) bool {
+		return scope.Children[i].Name < scope.Children[j].Name
+	})
+	sort.Slice(scope.Functions, func(i, j int) bool {
+		if scope.Functions[i].File == scope.Functions[j].File {
+			return scope.Functions[i].Line < scope.Functions[j].Line
+		}
+		return scope.Functions[i].File < scope.Functions[j].File
+	})
+	for _, child := range scope.Children {
+		sortScope(child)
+	}
+}
+
+func formatHierarchy(root *Scope) string {
+	var sb strings.Builder
+
+	sb.WriteString("## Hierarchy\n\n")
+	writeScopeBody(&sb, root, 0)
+
+	return sb.String()
+}
+
+func writeScopeBody(sb *strings.Builder, scope *Scope, level int) {
+	indent := strings.Repeat("  ", level)
+
+	for _, field := range scope.Fields {
+		declaration := strings.TrimSpace(field.Type + " " + field.Name)
+		sb.WriteString(fmt.Sprintf("%s- %s `%s` — %s:%d (%s)\n", indent, field.Kind, declaration, field.File, field.Line, field.Visibility))
+	}
+
+	for _, fn := range scope.Functions {
+		sb.WriteString(fmt.Sprintf("%s- function `%s(%s)` — %s:%d (%s)\n", indent, shortName(fn.Name), strings.Join(fn.Parameters, ", "), fn.File, fn.Line, fn.Visibility))
+	}
+
+	for _, child := range scope.Children {
+		title := child.Name
+		if child.Kind != "" {
+			title = child.Kind + " " + child.Name
+		}
+		if child.File != "" {
+			sb.WriteString(fmt.Sprintf("%s- **%s** — %s:%d\n", indent, title, child.File, child.Line))
+		} else {
+			sb.WriteString(fmt.Sprintf("%s- **%s**\n", indent, title))
+		}
+		writeScopeBody(sb, child, level+1)
+	}
+}
+
+func shortName(name string) string {
+	parts := splitQualified(name)
+	name = parts[len(parts)-1]
+	if idx := strings.LastIndex(name, "."); idx != -1 {
+		name = name[idx+1:]
+	}
+	return name
+}
@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/worker"
+)
+
+// jsonlWriter writes one function per line as soon as its file is parsed, so
+// nothing but the current file's functions is held in memory.
+type jsonlWriter struct {
+	mu     sync.Mutex
+	file   *os.File
+	buf    *bufio.Writer
+	hash   hash.Hash
+	size   int
+	count  int
+	target string
+}
+
+func newJSONLWriter(outputFile string) (*jsonlWriter, error) {
+	w := &jsonlWriter{hash: sha256.New(), target: "stdout"}
+
+	var out io.Writer = os.Stdout
+	if outputFile != "" {
+		file, err := os.Create(outputFile)
+		if err != nil {
+			return nil, err
+		}
+		w.file = file
+		w.target = outputFile
+		out = file
+	}
+
+	w.buf = bufio.NewWriter(io.MultiWriter(out, w.hash))
+	return w, nil
+}
+
+func (w *jsonlWriter) write(functions []Function) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, fn := range displayFunctions(functions) {
+		line, err := json.Marshal(fn)
+		if err != nil {
+			return err
+		}
+		line = append(line, '\n')
+		if _, err := w.buf.Write(line); err != nil {
+			return err
+		}
+		w.size += len(line)
+		w.count++
+	}
+
+	// Flush per file so consumers see results while the scan is still running
+	return w.buf.Flush()
+}
+
+func (w *jsonlWriter) close(config Config) error {
+	err := w.buf.Flush()
+	if w.file != nil {
+		if closeErr := w.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	config.Manifest.AddResultSum(w.target, w.size, w.hash.Sum(nil))
+	return err
+}
+
+func runStreaming(config Config, parser LanguageParser, files []string) error {
+	writer, err := newJSONLWriter(config.OutputFile)
+	if err != nil {
+		logError(fmt.Sprintf("Failed to open output: %v", err))
+		return err
+	}
+
+	var writeErr error
+	var errOnce sync.Once
+
+	reporter := progress.New("Analyzing functions", len(files), config.NoProgress)
+	worker.Each(files, config.Jobs, reporter, func(ctx context.Context, idx int, filePath string) []Function {
+		functions, err := parser.ParseFile(filePath)
+		if err != nil {
+			failures.Record(filePath, err)
+			return nil
+		}
+		if config.Qualify == "none" {
+			unqualifyFunctions(functions)
+		}
+		return functions
+	}, func(idx int, functions []Function) {
+		if err := writer.write(functions); err != nil {
+			errOnce.Do(func() { writeErr = err })
+		}
+	})
+
+	if err := writer.close(config); err != nil && writeErr == nil {
+		writeErr = err
+	}
+	if writeErr != nil {
+		logError(fmt.Sprintf("Failed to write output: %v", writeErr))
+		return writeErr
+	}
+
+	logInfo(config.Verbose, fmt.Sprintf("Streamed %d functions", writer.count))
+	logSuccess("Function registry generated successfully")
+	return nil
+}
+
+func formatJSONL(functions []Function) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, fn := range functions {
+		if err := encoder.Encode(fn); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
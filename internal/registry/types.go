@@ -0,0 +1,123 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kinds are the element kinds --types selects. function and method apply to
+// the function list, the others to the members of --hierarchy.
+var Kinds = []string{"function", "method", "class", "struct", "union", "enum", "interface", "namespace", "field", "enumerator"}
+
+// kindAliases name groups of kinds.
+var kindAliases = map[string][]string{
+	"types":   {"class", "struct", "union", "enum", "interface"},
+	"members": {"field", "method", "enumerator"},
+}
+
+// ParseKinds validates --types values and expands aliases. Plurals are
+// accepted, "functions" selects function. An empty list selects everything
+// and returns nil.
+func ParseKinds(values []string) (map[string]bool, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	kinds := make(map[string]bool)
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+		if expanded, ok := kindAliases[value]; ok {
+			for _, kind := range expanded {
+				kinds[kind] = true
+			}
+			continue
+		}
+		kind, ok := knownKind(value)
+		if !ok {
+			aliases := make([]string, 0, len(kindAliases))
+			for alias := range kindAliases {
+				aliases = append(aliases, alias)
+			}
+			sort.Strings(aliases)
+			return nil, fmt.Errorf("unknown type %q, expected one of %s, or the groups %s", value, strings.Join(Kinds, ", "), strings.Join(aliases, ", "))
+		}
+		kinds[kind] = true
+	}
+	return kinds, nil
+}
+
+func knownKind(value string) (string, bool) {
+	for _, kind := range Kinds {
+		if value == kind || value == kind+"s" || value == kind+"es" {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// selectsMembers reports whether kinds names any kind other than function
+// and method, which only --hierarchy reports.
+func selectsMembers(kinds map[string]bool) bool {
+	for kind := range kinds {
+		if kind != "function" && kind != "method" {
+			return true
+		}
+	}
+	return false
+}
+
+// functionKind tells methods, functions scoped to a type, from functions.
+// scopeKinds maps qualified scope names to the kind of their member.
+func functionKind(fn Function, scopeKinds map[string]string) string {
+	if fn.Scope == "" || scopeKinds[fn.Scope] == "namespace" {
+		return "function"
+	}
+	return "method"
+}
+
+func filterFunctions(functions []Function, kinds map[string]bool, scopeKinds map[string]string) []Function {
+	if kinds == nil {
+		return functions
+	}
+	filtered := []Function{}
+	for _, fn := range functions {
+		if kinds[functionKind(fn, scopeKinds)] {
+			filtered = append(filtered, fn)
+		}
+	}
+	return filtered
+}
+
+// filterMembers keeps the members of the selected kinds. Scopes left out
+// still nest what they hold, without a kind.
+func filterMembers(members []Member, kinds map[string]bool) []Member {
+	if kinds == nil {
+		return members
+	}
+	var filtered []Member
+	for _, member := range members {
+		if kinds[member.Kind] {
+			filtered = append(filtered, member)
+		}
+	}
+	return filtered
+}
+
+// memberScopeKinds maps the qualified name of each member to its kind.
+func memberScopeKinds(members []Member) map[string]string {
+	scopeKinds := make(map[string]string)
+	for _, member := range members {
+		qualified := member.Name
+		if member.Scope != "" {
+			qualified = member.Scope + "::" + member.Name
+		}
+		if _, ok := scopeKinds[qualified]; !ok {
+			scopeKinds[qualified] = member.Kind
+		}
+	}
+	return scopeKinds
+}
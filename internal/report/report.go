@@ -0,0 +1,62 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Funcs are available to every report template on top of the text/template
+// builtins.
+var Funcs = template.FuncMap{
+	"join":    strings.Join,
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"repeat":  strings.Repeat,
+	"replace": strings.ReplaceAll,
+	"base":    filepath.Base,
+	"add":     func(a, b int) int { return a + b },
+	"percent": func(part, total int) string {
+		if total == 0 {
+			return "0.0%"
+		}
+		return fmt.Sprintf("%.1f%%", float64(part)/float64(total)*100)
+	},
+	"json": func(v any) (string, error) {
+		data, err := json.MarshalIndent(v, "", "  ")
+		return string(data), err
+	},
+}
+
+// Load parses a user-provided template file.
+func Load(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Funcs(Funcs).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Render executes the template at path with the structured results of a
+// command.
+func Render(path string, data any) ([]byte, error) {
+	tmpl, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("executing template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
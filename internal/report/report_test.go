@@ -0,0 +1,61 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tempDir := t.TempDir()
+	templateFile := filepath.Join(tempDir, "report.tmpl")
+	content := `{{range .Items}}{{upper .Name}} {{percent .Count $.Total}} {{join .Tags "+"}}
+{{end}}`
+	if err := os.WriteFile(templateFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+
+	type item struct {
+		Name  string
+		Count int
+		Tags  []string
+	}
+	data := struct {
+		Items []item
+		Total int
+	}{
+		Items: []item{{"parser", 3, []string{"c", "cpp"}}, {"lexer", 1, nil}},
+		Total: 4,
+	}
+
+	output, err := Render(templateFile, data)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	expected := "PARSER 75.0% c+cpp\nLEXER 25.0% \n"
+	if string(output) != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	if err := os.WriteFile(templateFile, []byte("{{.Missing}}"), 0644); err != nil {
+		t.Fatalf("Failed to update template: %v", err)
+	}
+	if _, err := Render(templateFile, data); err == nil || !strings.Contains(err.Error(), "executing template") {
+		t.Errorf("Expected an execution error for an unknown field, got %v", err)
+	}
+}
+
+func TestExampleTemplatesParse(t *testing.T) {
+	examples, err := filepath.Glob(filepath.Join("..", "..", "examples", "templates", "*.tmpl"))
+	if err != nil || len(examples) == 0 {
+		t.Fatalf("No example templates found: %v", err)
+	}
+
+	for _, example := range examples {
+		if _, err := Load(example); err != nil {
+			t.Errorf("%s: %v", example, err)
+		}
+	}
+}
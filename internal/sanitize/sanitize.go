@@ -0,0 +1,582 @@
+package sanitize
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vitruves/gop/internal/codeowners"
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/utils"
+)
+
+type Config struct {
+	// Logs are the sanitizer logs to read, standard input when empty
+	Logs []string
+	// Root is the source tree frames are made relative to
+	Root       string
+	SkipFrames []string
+	// CodeOwners is the CODEOWNERS file, looked up under the root when empty
+	CodeOwners string
+	// GroupBy lists the text report by "owner" instead of by frequency
+	GroupBy    string
+	OutputFile string
+	Format     string
+	Verbose    bool
+	Manifest   *manifest.Manifest
+}
+
+// Frame is one line of a sanitizer stack trace. Module is set for frames
+// without source information, e.g. "libc.so.6+0x29d90".
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+	Column   int
+	Module   string
+	// InProject is set once the file is resolved to a file under the root
+	InProject bool
+	// path is the file under the root, as scanned, once InProject
+	path string
+}
+
+// Report is one sanitizer finding as it appears in a log. Frames is the
+// first stack of the report, where the error happened; the allocation and
+// free stacks of AddressSanitizer and the other accesses of ThreadSanitizer
+// are left out.
+type Report struct {
+	Sanitizer string
+	Kind      string
+	// Detail is the line describing the access, with addresses masked
+	Detail string
+	Frames []Frame
+	Log    string
+	Line   int
+}
+
+// Group gathers the reports with the same rule and root-cause frame, the
+// first frame in the project that is not skipped.
+type Group struct {
+	Sanitizer string
+	Kind      string
+	Rule      string
+	Frame     Frame
+	// Count is the number of reports, Stacks the number of distinct stacks
+	// among them
+	Count   int
+	Stacks  int
+	Example Report
+	Logs    []string
+	Owners  []string
+}
+
+var (
+	headerRegex = regexp.MustCompile(`(?:==\d+==)?(?:ERROR|WARNING): (\w+Sanitizer): (.*)$`)
+	ubsanRegex  = regexp.MustCompile(`(\S+?):(\d+):(\d+): runtime error: (.*)$`)
+	leakRegex   = regexp.MustCompile(`^\s*((?:Direct|Indirect) leak) of \d+ byte\(s\) in \d+ object\(s\) allocated from:`)
+	frameRegex  = regexp.MustCompile(`^\s*#(\d+)\s+(?:0x[0-9a-fA-F]+\s*)?(?:in\s+)?(.*)$`)
+	moduleRegex = regexp.MustCompile(`\s*\(([^()\s]+\+0x[0-9a-fA-F]+)\)$`)
+	sourceRegex = regexp.MustCompile(`(?:^|\s)(\S+?):(\d+)(?::(\d+))?$`)
+	endRegex    = regexp.MustCompile(`^\s*(?:SUMMARY: |==\d+==ABORTING|={10,}$)`)
+	addressRe   = regexp.MustCompile(`0x[0-9a-fA-F]+`)
+	kindEndRe   = regexp.MustCompile(` on (?:unknown )?address| on 0x| at pc | \(|: `)
+)
+
+// Frames of a report printed in the text output.
+const maxFramesShown = 8
+
+func Run(config Config) error {
+	logInfo(config.Verbose, "Starting sanitizer log triage")
+
+	if config.Format == "" {
+		config.Format = "text"
+	}
+	if config.Format != "text" && !diagnostics.IsFormat(config.Format) {
+		return fmt.Errorf("unsupported format: %s (expected text, %s)", config.Format, strings.Join(diagnostics.Formats, ", "))
+	}
+	if config.Root == "" {
+		config.Root = "."
+	}
+	if config.GroupBy != "" && config.GroupBy != "owner" {
+		return fmt.Errorf("unsupported --group-by: %s (expected owner)", config.GroupBy)
+	}
+	owners, err := codeowners.Open(config.CodeOwners, config.Root)
+	if err != nil {
+		return fmt.Errorf("failed to read CODEOWNERS: %w", err)
+	}
+	if config.GroupBy == "owner" && owners == nil {
+		return fmt.Errorf("--group-by owner needs a CODEOWNERS file, none found (use --codeowners)")
+	}
+
+	var reports []Report
+	if len(config.Logs) == 0 {
+		parsed, err := Parse(os.Stdin, "stdin")
+		if err != nil {
+			return err
+		}
+		reports = parsed
+	}
+	for _, log := range config.Logs {
+		file, err := os.Open(log)
+		if err != nil {
+			return err
+		}
+		parsed, err := Parse(file, log)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", log, err)
+		}
+		logInfo(config.Verbose, fmt.Sprintf("Found %d reports in %s", len(parsed), log))
+		reports = append(reports, parsed...)
+	}
+	config.Manifest.AddFiles(config.Logs)
+
+	groups := Triage(reports, utils.NewPathResolver(config.Root), config.SkipFrames)
+	for i := range groups {
+		if groups[i].Frame.InProject {
+			groups[i].Owners = owners.Owners(filepath.Join(config.Root, groups[i].Frame.File))
+		}
+	}
+	groups = filterGroups(groups)
+	if err := writeOutput(groups, config); err != nil {
+		return err
+	}
+
+	if len(reports) > 0 {
+		logWarning(fmt.Sprintf("Triaged %d sanitizer reports into %d groups", len(reports), len(groups)))
+	}
+	return nil
+}
+
+// Parse reads the AddressSanitizer, LeakSanitizer, ThreadSanitizer and
+// UndefinedBehaviorSanitizer reports of a log. Lines may carry a prefix,
+// such as the test number of ctest, before the sanitizer output.
+func Parse(r io.Reader, name string) ([]Report, error) {
+	var reports []Report
+	var current *Report
+	// stackDone is set once the first stack of the current report ended
+	stackDone := false
+	leakSanitizer := ""
+
+	flush := func() {
+		if current != nil {
+			reports = append(reports, *current)
+			current = nil
+		}
+	}
+	start := func(report Report) {
+		flush()
+		current = &report
+		stackDone = false
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if match := headerRegex.FindStringSubmatch(line); match != nil {
+			flush()
+			if strings.HasPrefix(match[2], "detected memory leaks") {
+				// Every leak that follows is a report of its own
+				leakSanitizer = match[1]
+				continue
+			}
+			leakSanitizer = ""
+			start(Report{Sanitizer: match[1], Kind: reportKind(match[2]), Log: name, Line: lineNumber})
+			continue
+		}
+		if match := leakRegex.FindStringSubmatch(line); match != nil && leakSanitizer != "" {
+			start(Report{Sanitizer: leakSanitizer, Kind: "memory leak", Detail: strings.TrimSpace(strings.TrimSuffix(line, " allocated from:")), Log: name, Line: lineNumber})
+			continue
+		}
+		if match := ubsanRegex.FindStringSubmatch(line); match != nil {
+			report := Report{Sanitizer: "UndefinedBehaviorSanitizer", Kind: reportKind(match[4]), Detail: maskAddresses(match[4]), Log: name, Line: lineNumber}
+			lineNo, _ := strconv.Atoi(match[2])
+			column, _ := strconv.Atoi(match[3])
+			report.Frames = []Frame{{File: match[1], Line: lineNo, Column: column}}
+			// A stack trace, when printed, replaces the location of the message
+			start(report)
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		if endRegex.MatchString(line) {
+			flush()
+			leakSanitizer = ""
+			continue
+		}
+		if match := frameRegex.FindStringSubmatch(line); match != nil {
+			if stackDone {
+				continue
+			}
+			if match[1] == "0" && current.Sanitizer == "UndefinedBehaviorSanitizer" {
+				current.Frames = nil
+			}
+			current.Frames = append(current.Frames, parseFrame(match[2]))
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if len(current.Frames) > 0 {
+			stackDone = true
+		}
+		if current.Detail == "" && trimmed != "" {
+			current.Detail = maskAddresses(strings.TrimSuffix(trimmed, ":"))
+		}
+	}
+	flush()
+
+	return reports, scanner.Err()
+}
+
+// parseFrame splits "func file:line:col (module+0xoff)" and its variants.
+func parseFrame(text string) Frame {
+	var frame Frame
+	text = strings.TrimSpace(text)
+
+	if match := moduleRegex.FindStringSubmatch(text); match != nil {
+		frame.Module = match[1]
+		text = strings.TrimSpace(text[:len(text)-len(match[0])])
+	}
+	if match := sourceRegex.FindStringSubmatchIndex(text); match != nil {
+		frame.File = text[match[2]:match[3]]
+		frame.Line, _ = strconv.Atoi(text[match[4]:match[5]])
+		if match[6] >= 0 {
+			frame.Column, _ = strconv.Atoi(text[match[6]:match[7]])
+		}
+		text = strings.TrimSpace(text[:match[0]])
+	}
+	frame.Function = text
+	return frame
+}
+
+// reportKind keeps the name of the error out of a report header, without
+// the addresses, thread ids and values that change from run to run.
+func reportKind(message string) string {
+	if loc := kindEndRe.FindStringIndex(message); loc != nil {
+		message = message[:loc[0]]
+	}
+	return strings.TrimSpace(maskAddresses(message))
+}
+
+func maskAddresses(text string) string {
+	return addressRe.ReplaceAllString(text, "0x...")
+}
+
+// Triage resolves frames, drops duplicated reports and groups the rest by
+// root-cause frame. Frames whose function or file matches a skip pattern,
+// such as project allocation wrappers, are passed over for the root cause.
+// Groups are sorted by report count.
+func Triage(reports []Report, resolver *utils.PathResolver, skip []string) []Group {
+	groups := make(map[string]*Group)
+	stacks := make(map[string]map[string]bool)
+	var order []string
+
+	for _, report := range reports {
+		for i := range report.Frames {
+			frame := &report.Frames[i]
+			if resolved, ok := resolver.Resolve(frame.File); ok {
+				frame.File = resolved
+				frame.InProject = true
+				frame.path = resolver.Path(resolved)
+			}
+		}
+
+		root := rootFrame(report.Frames, skip)
+		rule := "sanitizer/" + slug(report.Kind)
+		if report.Sanitizer == "UndefinedBehaviorSanitizer" {
+			rule = "sanitizer/undefined-behavior"
+		}
+		key := fmt.Sprintf("%s\x00%s\x00%s\x00%d\x00%s", rule, report.Sanitizer, root.File, root.Line, root.Function)
+
+		group, ok := groups[key]
+		if !ok {
+			group = &Group{Sanitizer: report.Sanitizer, Kind: report.Kind, Rule: rule, Frame: root, Example: report}
+			groups[key] = group
+			stacks[key] = make(map[string]bool)
+			order = append(order, key)
+		}
+		group.Count++
+		if signature := stackSignature(report.Frames); !stacks[key][signature] {
+			stacks[key][signature] = true
+			group.Stacks++
+		}
+		group.Logs = appendUnique(group.Logs, report.Log)
+	}
+
+	result := make([]Group, 0, len(order))
+	for _, key := range order {
+		result = append(result, *groups[key])
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		if result[i].Frame.File != result[j].Frame.File {
+			return result[i].Frame.File < result[j].Frame.File
+		}
+		return result[i].Frame.Line < result[j].Frame.Line
+	})
+	return result
+}
+
+// rootFrame is the first project frame not skipped, otherwise the first
+// frame with a source file, otherwise the first frame.
+func rootFrame(frames []Frame, skip []string) Frame {
+	for _, frame := range frames {
+		if frame.InProject && !utils.MatchesAny(frame.Function, skip) && !utils.MatchPath(frame.File, skip) {
+			return frame
+		}
+	}
+	for _, frame := range frames {
+		if frame.File != "" {
+			return frame
+		}
+	}
+	if len(frames) > 0 {
+		return frames[0]
+	}
+	return Frame{}
+}
+
+// stackSignature identifies a stack by its functions and project lines,
+// so the same crash reached through another binary still matches.
+func stackSignature(frames []Frame) string {
+	var parts []string
+	for _, frame := range frames {
+		if frame.InProject {
+			parts = append(parts, fmt.Sprintf("%s@%s:%d", frame.Function, frame.File, frame.Line))
+		} else {
+			parts = append(parts, frame.Function)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func slug(kind string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(kind)), " ", "-")
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// Diagnostics turns groups into findings at their root-cause frame. The
+// fingerprint excerpt is the stack of function names, which survives code
+// moving around.
+func Diagnostics(groups []Group) []diagnostics.Diagnostic {
+	var result []diagnostics.Diagnostic
+	for _, group := range groups {
+		severity := diagnostics.SeverityError
+		if group.Kind == "memory leak" {
+			severity = diagnostics.SeverityWarning
+		}
+
+		var functions []string
+		for _, frame := range group.Example.Frames {
+			if frame.InProject {
+				functions = append(functions, frame.Function)
+			}
+		}
+
+		result = append(result, diagnostics.Diagnostic{
+			File:        group.Frame.File,
+			Line:        group.Frame.Line,
+			Column:      group.Frame.Column,
+			Severity:    severity,
+			Rule:        group.Rule,
+			Message:     message(group),
+			Function:    group.Frame.Function,
+			Excerpt:     group.Kind + "\n" + strings.Join(functions, "\n"),
+			Owners:      group.Owners,
+			ScannedFile: group.Frame.path,
+		})
+	}
+	return result
+}
+
+// filterGroups keeps the groups whose finding the --exclude-finding and
+// --include-function filter and the directory overrides keep.
+func filterGroups(groups []Group) []Group {
+	kept := []Group{}
+	for _, group := range groups {
+		if diagnostics.Keep(Diagnostics([]Group{group})[0]) {
+			kept = append(kept, group)
+		}
+	}
+	return kept
+}
+
+func message(group Group) string {
+	text := fmt.Sprintf("%s: %s", group.Sanitizer, group.Kind)
+	if group.Frame.Function != "" {
+		text += " in " + group.Frame.Function
+	}
+	if group.Example.Detail != "" && group.Example.Detail != group.Kind {
+		text += " (" + group.Example.Detail + ")"
+	}
+	if group.Count > 1 {
+		text += fmt.Sprintf(", %d reports with %d distinct stacks", group.Count, group.Stacks)
+	}
+	return text
+}
+
+func writeGroup(sb *strings.Builder, group Group) {
+	location := group.Frame.File
+	if location == "" {
+		location = group.Frame.Module
+	}
+	sb.WriteString(fmt.Sprintf("%s:%d:%d: %s\n", location, group.Frame.Line, group.Frame.Column, message(group)))
+	for i, frame := range group.Example.Frames {
+		if i == maxFramesShown {
+			sb.WriteString(fmt.Sprintf("    ... %d more frames\n", len(group.Example.Frames)-maxFramesShown))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("    #%d %s\n", i, formatFrame(frame)))
+	}
+}
+
+// writeByOwner lists the groups under each owner of their root-cause file,
+// the owner with the most groups first, and ends with the counts per owner.
+func writeByOwner(sb *strings.Builder, groups []Group) {
+	byOwner := make(map[string][]Group)
+	for _, group := range groups {
+		owners := group.Owners
+		if len(owners) == 0 {
+			owners = []string{codeowners.Unowned}
+		}
+		for _, owner := range owners {
+			byOwner[owner] = append(byOwner[owner], group)
+		}
+	}
+
+	counts := codeowners.Counts(Diagnostics(groups))
+	for _, count := range counts {
+		sb.WriteString(fmt.Sprintf("== %s ==\n", count.Owner))
+		for _, group := range byOwner[count.Owner] {
+			writeGroup(sb, group)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("Owners:\n")
+	for _, count := range counts {
+		sb.WriteString(fmt.Sprintf("%6d  %s (%d errors, %d warnings)\n", count.Findings, count.Owner, count.Errors, count.Warnings))
+	}
+}
+
+func writeOutput(groups []Group, config Config) error {
+	groups = displayPaths(groups, config.Root)
+	var sb strings.Builder
+
+	if config.Format == "text" {
+		if len(groups) == 0 {
+			logSuccess("No sanitizer reports found")
+			return nil
+		}
+
+		if config.GroupBy == "owner" {
+			writeByOwner(&sb, groups)
+		} else {
+			for _, group := range groups {
+				writeGroup(&sb, group)
+			}
+		}
+	} else if err := diagnostics.WriteReport(&sb, config.Format, Diagnostics(groups)); err != nil {
+		return err
+	}
+	output := []byte(sb.String())
+
+	if config.OutputFile != "" {
+		config.Manifest.AddResult(config.OutputFile, output)
+		if err := os.WriteFile(config.OutputFile, output, 0644); err != nil {
+			logError(fmt.Sprintf("Failed to write report: %v", err))
+			return err
+		}
+	} else {
+		config.Manifest.AddResult("stdout", output)
+		fmt.Print(string(output))
+	}
+	return nil
+}
+
+// displayPaths writes the files of project frames, relative to root after
+// Triage, in the selected path style. Frames outside the project are left as
+// the sanitizer wrote them.
+func displayPaths(groups []Group, root string) []Group {
+	display := func(frame Frame) Frame {
+		if frame.InProject {
+			frame.File = utils.DisplayPath(filepath.Join(root, frame.File))
+		}
+		return frame
+	}
+
+	displayed := make([]Group, len(groups))
+	for i, group := range groups {
+		group.Frame = display(group.Frame)
+		frames := make([]Frame, len(group.Example.Frames))
+		for j, frame := range group.Example.Frames {
+			frames[j] = display(frame)
+		}
+		group.Example.Frames = frames
+		displayed[i] = group
+	}
+	return displayed
+}
+
+func formatFrame(frame Frame) string {
+	parts := []string{}
+	if frame.Function != "" {
+		parts = append(parts, frame.Function)
+	}
+	switch {
+	case frame.File != "" && frame.Column > 0:
+		parts = append(parts, fmt.Sprintf("%s:%d:%d", frame.File, frame.Line, frame.Column))
+	case frame.File != "":
+		parts = append(parts, fmt.Sprintf("%s:%d", frame.File, frame.Line))
+	case frame.Module != "":
+		parts = append(parts, "("+frame.Module+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+func logInfo(verbose bool, msg string) {
+	if verbose {
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
+	}
+}
+
+func logSuccess(msg string) {
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
+}
+
+func logWarning(msg string) {
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
+}
+
+func logError(msg string) {
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
@@ -0,0 +1,149 @@
+package sanitize
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vitruves/gop/internal/utils"
+)
+
+const sampleLog = `[ RUN      ] Parser.Frames
+=================================================================
+==4242==ERROR: AddressSanitizer: heap-use-after-free on address 0x602000000010 at pc 0x4c3b7e bp 0x7ffc sp 0x7ff0
+READ of size 4 at 0x602000000010 thread T0
+    #0 0x4c3b7e in parse_frame /home/ci/build/src/parse.c:42:13
+    #1 0x4c3f10 in main /home/ci/build/src/main.c:10:5
+    #2 0x7f12 in __libc_start_main (/lib/x86_64-linux-gnu/libc.so.6+0x21b96)
+
+0x602000000010 is located 0 bytes inside of 4-byte region
+freed by thread T0 here:
+    #0 0x49a0 in free (/usr/lib/libasan.so.6+0xb0)
+    #1 0x4c3a00 in release /home/ci/build/src/parse.c:30:3
+
+SUMMARY: AddressSanitizer: heap-use-after-free /home/ci/build/src/parse.c:42:13 in parse_frame
+==4242==ABORTING
+==4243==ERROR: AddressSanitizer: heap-use-after-free on address 0x602000000090 at pc 0x4c3b7e bp 0x7ffc sp 0x7ff0
+READ of size 4 at 0x602000000090 thread T0
+    #0 0x4c3b7e in parse_frame /home/ci/build/src/parse.c:42:13
+    #1 0x4c3f99 in run_tests /home/ci/build/src/main.c:20:5
+SUMMARY: AddressSanitizer: heap-use-after-free /home/ci/build/src/parse.c:42:13 in parse_frame
+==4244==ERROR: LeakSanitizer: detected memory leaks
+
+Direct leak of 40 byte(s) in 1 object(s) allocated from:
+    #0 0x49b0 in malloc (/usr/lib/libasan.so.6+0xb1)
+    #1 0x4c1000 in xmalloc /home/ci/build/src/main.c:3:12
+    #2 0x4c1100 in make_buffer /home/ci/build/src/main.c:15:10
+
+Indirect leak of 8 byte(s) in 1 object(s) allocated from:
+    #0 0x49b0 in malloc (/usr/lib/libasan.so.6+0xb1)
+    #1 0x4c1000 in xmalloc /home/ci/build/src/main.c:3:12
+
+SUMMARY: AddressSanitizer: 48 byte(s) leaked in 2 allocation(s).
+==================
+WARNING: ThreadSanitizer: data race (pid=9000)
+  Write of size 4 at 0x7b0400000000 by thread T1:
+    #0 worker src/parse.c:8:10 (test+0x4b2)
+
+  Previous read of size 4 at 0x7b0400000000 by main thread:
+    #0 main src/main.c:12:3 (test+0x4c0)
+
+SUMMARY: ThreadSanitizer: data race src/parse.c:8:10 in worker
+==================
+3: /home/ci/build/src/parse.c:50:7: runtime error: signed integer overflow: 2147483647 + 1 cannot be represented in type 'int'
+`
+
+func TestParse(t *testing.T) {
+	reports, err := Parse(strings.NewReader(sampleLog), "test.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type summary struct {
+		sanitizer, kind string
+		frames          int
+	}
+	want := []summary{
+		{"AddressSanitizer", "heap-use-after-free", 3},
+		{"AddressSanitizer", "heap-use-after-free", 2},
+		{"LeakSanitizer", "memory leak", 3},
+		{"LeakSanitizer", "memory leak", 2},
+		{"ThreadSanitizer", "data race", 1},
+		{"UndefinedBehaviorSanitizer", "signed integer overflow", 1},
+	}
+	if len(reports) != len(want) {
+		t.Fatalf("Expected %d reports, got %d: %+v", len(want), len(reports), reports)
+	}
+	for i, report := range reports {
+		got := summary{report.Sanitizer, report.Kind, len(report.Frames)}
+		if got != want[i] {
+			t.Errorf("Report %d: expected %+v, got %+v", i, want[i], got)
+		}
+	}
+
+	first := reports[0]
+	if first.Detail != "READ of size 4 at 0x... thread T0" {
+		t.Errorf("Expected the access detail with addresses masked, got %q", first.Detail)
+	}
+	if frame := first.Frames[0]; frame.Function != "parse_frame" || frame.File != "/home/ci/build/src/parse.c" || frame.Line != 42 || frame.Column != 13 {
+		t.Errorf("Unexpected first frame %+v", frame)
+	}
+	if frame := first.Frames[2]; frame.Function != "__libc_start_main" || frame.File != "" || frame.Module != "/lib/x86_64-linux-gnu/libc.so.6+0x21b96" {
+		t.Errorf("Unexpected unsymbolized frame %+v", frame)
+	}
+	if frame := reports[5].Frames[0]; frame.File != "/home/ci/build/src/parse.c" || frame.Line != 50 {
+		t.Errorf("Expected the UBSan location as its frame, got %+v", frame)
+	}
+}
+
+func TestTriage(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"parse.c", "main.c"} {
+		if err := os.WriteFile(filepath.Join(root, "src", name), []byte("int x;\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reports, err := Parse(strings.NewReader(sampleLog), "test.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := Triage(reports, utils.NewPathResolver(root), []string{"xmalloc"})
+
+	// The two use-after-free reports share their root cause, the two leaks do not
+	if len(groups) != 5 {
+		t.Fatalf("Expected 5 groups, got %d: %+v", len(groups), groups)
+	}
+	top := groups[0]
+	if top.Count != 2 || top.Stacks != 2 || top.Rule != "sanitizer/heap-use-after-free" {
+		t.Errorf("Expected the use-after-free group first with 2 reports, got %+v", top)
+	}
+	if top.Frame.File != filepath.Join("src", "parse.c") || !top.Frame.InProject {
+		t.Errorf("Expected the root frame relative to the source root, got %+v", top.Frame)
+	}
+
+	var leakRoots []string
+	for _, group := range groups {
+		if group.Kind == "memory leak" {
+			leakRoots = append(leakRoots, group.Frame.Function)
+		}
+	}
+	// The indirect leak has no other project frame than the skipped xmalloc
+	if strings.Join(leakRoots, ",") != "xmalloc,make_buffer" {
+		t.Errorf("Expected leak roots xmalloc and make_buffer, got %v", leakRoots)
+	}
+
+	findings := Diagnostics(groups)
+	if findings[0].Rule != "sanitizer/heap-use-after-free" || findings[0].Line != 42 || findings[0].Function != "parse_frame" {
+		t.Errorf("Unexpected diagnostic %+v", findings[0])
+	}
+	for _, finding := range findings {
+		if finding.Rule == "sanitizer/undefined-behavior" && finding.File != filepath.Join("src", "parse.c") {
+			t.Errorf("Expected the UBSan finding in src/parse.c, got %s", finding.File)
+		}
+	}
+}
@@ -0,0 +1,461 @@
+package selftest
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/enumcheck"
+	"github.com/vitruves/gop/internal/errorcheck"
+	"github.com/vitruves/gop/internal/fix"
+	"github.com/vitruves/gop/internal/fwddecl"
+	"github.com/vitruves/gop/internal/registry"
+	"github.com/vitruves/gop/internal/utils"
+)
+
+// The corpus holds small C/C++ files whose lines carry the findings expected
+// on them as "// EXPECT: <rule>[, <rule>...]" comments. The golden files hold
+// the full output of each analyzer on the corpus.
+//
+//go:embed testdata/corpus testdata/golden
+var testdata embed.FS
+
+const (
+	corpusDir = "testdata/corpus"
+	goldenDir = "testdata/golden"
+)
+
+type Config struct {
+	// Analyzers restricts the run to these analyzers, all by default
+	Analyzers []string
+	// Update writes the golden files to this directory instead of comparing
+	// against them
+	Update  string
+	List    bool
+	Verbose bool
+}
+
+// Analyzer runs one gop check over the extracted corpus. Rules are the rule
+// IDs it reports, so EXPECT comments for other analyzers are left alone.
+type Analyzer struct {
+	Name  string
+	Rules []string
+	Check func(dir string, files []string) []diagnostics.Diagnostic
+}
+
+// Analyzers lists the checks covered by the corpus, new ones only need to be
+// added here with corpus files and a golden file.
+var Analyzers = []Analyzer{
+	{
+		Name:  "enum-check",
+		Rules: []string{enumcheck.Rule},
+		Check: checkEnums,
+	},
+	{
+		Name:  "error-check",
+		Rules: []string{errorcheck.RuleUncheckedCall, errorcheck.RuleUncheckedErrorCode, errorcheck.RuleEmptyCatch},
+		Check: checkErrors,
+	},
+	{
+		Name:  "forward-decl-check",
+		Rules: []string{fwddecl.Rule},
+		Check: checkForwardDecls,
+	},
+	{
+		Name:  "fix",
+		Rules: fixRules(),
+		Check: checkFixes,
+	},
+}
+
+// Result is the outcome of one analyzer on the corpus.
+type Result struct {
+	Analyzer string
+	// Output is the golden text of the findings
+	Output string
+	// Missing and Unexpected compare the findings with the EXPECT comments
+	Missing    []string
+	Unexpected []string
+	// Diff compares Output with the golden file, "-" lines are expected and
+	// "+" lines were found
+	Diff []string
+}
+
+func (r Result) Passed() bool {
+	return len(r.Missing) == 0 && len(r.Unexpected) == 0 && len(r.Diff) == 0
+}
+
+var expectRegex = regexp.MustCompile(`(?://|/\*)\s*EXPECT:\s*([^*]+?)\s*(?:\*/\s*)?$`)
+
+func Run(config Config) error {
+	if config.List {
+		width := 0
+		for _, analyzer := range Analyzers {
+			width = max(width, len(analyzer.Name))
+		}
+		for _, analyzer := range Analyzers {
+			fmt.Printf("%-*s  %s\n", width, analyzer.Name, strings.Join(analyzer.Rules, ", "))
+		}
+		return nil
+	}
+
+	analyzers, err := selectAnalyzers(config.Analyzers)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "gop-self-test-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	files, err := extractCorpus(dir)
+	if err != nil {
+		return fmt.Errorf("failed to extract the corpus: %w", err)
+	}
+	logInfo(config.Verbose, fmt.Sprintf("Extracted %d corpus files to %s", len(files), dir))
+
+	expectations, err := loadExpectations(dir, files)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, analyzer := range analyzers {
+		result := check(analyzer, dir, files, expectations)
+
+		if config.Update != "" {
+			if err := os.MkdirAll(config.Update, 0755); err != nil {
+				return err
+			}
+			path := filepath.Join(config.Update, analyzer.Name+".golden")
+			if err := os.WriteFile(path, []byte(result.Output), 0644); err != nil {
+				return err
+			}
+			logInfo(config.Verbose, fmt.Sprintf("Wrote %s", path))
+			result.Diff = nil
+		}
+
+		printResult(result, config.Verbose)
+		if !result.Passed() {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("self-test failed: %d of %d analyzers differ from the corpus", failed, len(analyzers))
+	}
+	logSuccess(fmt.Sprintf("All %d analyzers match the corpus", len(analyzers)))
+	return nil
+}
+
+func selectAnalyzers(names []string) ([]Analyzer, error) {
+	if len(names) == 0 {
+		return Analyzers, nil
+	}
+	var selected []Analyzer
+	for _, name := range names {
+		found := false
+		for _, analyzer := range Analyzers {
+			if analyzer.Name == name {
+				selected = append(selected, analyzer)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown analyzer: %s (see --list)", name)
+		}
+	}
+	return selected, nil
+}
+
+// extractCorpus writes the embedded corpus to dir, since the analyzers read
+// files from disk, and returns the paths written in order.
+func extractCorpus(dir string) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(testdata, corpusDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := testdata.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(path, corpusDir+"/")))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return err
+		}
+		files = append(files, target)
+		return nil
+	})
+	return files, err
+}
+
+// loadExpectations maps "file:line" to the rules expected there.
+func loadExpectations(dir string, files []string) (map[string][]string, error) {
+	expectations := make(map[string][]string)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for i, line := range utils.SplitLines(string(data)) {
+			match := expectRegex.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			key := fmt.Sprintf("%s:%d", relative(dir, file), i+1)
+			for _, rule := range strings.Split(match[1], ",") {
+				if rule = strings.TrimSpace(rule); rule != "" {
+					expectations[key] = append(expectations[key], rule)
+				}
+			}
+		}
+	}
+	return expectations, nil
+}
+
+func check(analyzer Analyzer, dir string, files []string, expectations map[string][]string) Result {
+	findings := analyzer.Check(dir, files)
+	for i := range findings {
+		findings[i].File = relative(dir, findings[i].File)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return a.Rule < b.Rule
+	})
+
+	result := Result{Analyzer: analyzer.Name}
+	var sb strings.Builder
+	for _, finding := range findings {
+		sb.WriteString(formatFinding(finding))
+	}
+	result.Output = sb.String()
+
+	// Each EXPECT entry is matched by one finding at most
+	owned := make(map[string]bool)
+	for _, rule := range analyzer.Rules {
+		owned[rule] = true
+	}
+	remaining := make(map[string][]string)
+	for key, rules := range expectations {
+		for _, rule := range rules {
+			if owned[rule] {
+				remaining[key] = append(remaining[key], rule)
+			}
+		}
+	}
+	for _, finding := range findings {
+		key := fmt.Sprintf("%s:%d", finding.File, finding.Line)
+		matched := false
+		for i, rule := range remaining[key] {
+			if rule == finding.Rule {
+				remaining[key] = append(remaining[key][:i], remaining[key][i+1:]...)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			result.Unexpected = append(result.Unexpected, strings.TrimSuffix(formatFinding(finding), "\n"))
+		}
+	}
+	for key, rules := range remaining {
+		for _, rule := range rules {
+			result.Missing = append(result.Missing, fmt.Sprintf("%s: %s", key, rule))
+		}
+	}
+	sort.Strings(result.Missing)
+
+	golden, err := testdata.ReadFile(goldenDir + "/" + analyzer.Name + ".golden")
+	if err != nil {
+		result.Diff = []string{fmt.Sprintf("no golden file for %s, create it with --update", analyzer.Name)}
+		return result
+	}
+	result.Diff = diffLines(strings.ReplaceAll(string(golden), "\r\n", "\n"), result.Output)
+	return result
+}
+
+func formatFinding(finding diagnostics.Diagnostic) string {
+	return fmt.Sprintf("%s:%d:%d: %s [%s] %s\n", finding.File, finding.Line, finding.Column, finding.Severity, finding.Rule, finding.Message)
+}
+
+// diffLines compares two outputs line by line. Both are sorted the same way,
+// so a merge over their lines finds what was dropped and what was added.
+func diffLines(want, got string) []string {
+	wantLines := splitLines(want)
+	gotLines := splitLines(got)
+	var diff []string
+	i, j := 0, 0
+	for i < len(wantLines) || j < len(gotLines) {
+		switch {
+		case i < len(wantLines) && j < len(gotLines) && wantLines[i] == gotLines[j]:
+			i++
+			j++
+		case j == len(gotLines) || (i < len(wantLines) && !contains(gotLines[j:], wantLines[i])):
+			diff = append(diff, "-"+wantLines[i])
+			i++
+		default:
+			diff = append(diff, "+"+gotLines[j])
+			j++
+		}
+	}
+	return diff
+}
+
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+func contains(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
+
+func relative(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+func printResult(result Result, verbose bool) {
+	if result.Passed() {
+		fmt.Printf("%s %s\n", color.Wrap(color.Green, "PASS"), result.Analyzer)
+		if verbose {
+			for _, line := range splitLines(result.Output) {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+		return
+	}
+
+	fmt.Printf("%s %s\n", color.Wrap(color.Red, "FAIL"), result.Analyzer)
+	for _, missing := range result.Missing {
+		fmt.Printf("    missing %s\n", missing)
+	}
+	for _, unexpected := range result.Unexpected {
+		fmt.Printf("    unexpected %s\n", unexpected)
+	}
+	if len(result.Diff) > 0 {
+		fmt.Printf("    golden output differs:\n")
+		for _, line := range result.Diff {
+			fmt.Printf("      %s\n", line)
+		}
+	}
+}
+
+func checkEnums(dir string, files []string) []diagnostics.Diagnostic {
+	parser := registry.GetParser("cpp").(registry.MemberParser)
+	var members []registry.Member
+	for _, file := range files {
+		fileMembers, err := parser.ParseMembers(file)
+		if err != nil {
+			logError(fmt.Sprintf("Error parsing %s: %v", file, err))
+			continue
+		}
+		members = append(members, fileMembers...)
+	}
+
+	enums := enumcheck.CollectEnums(members)
+	var findings []diagnostics.Diagnostic
+	for _, file := range files {
+		content, err := utils.ReadSourceFile(file)
+		if err != nil {
+			logError(fmt.Sprintf("Error reading %s: %v", file, err))
+			continue
+		}
+		findings = append(findings, enumcheck.CheckSwitches(file, content, enums)...)
+	}
+	return findings
+}
+
+func checkErrors(dir string, files []string) []diagnostics.Diagnostic {
+	var results []errorcheck.FileResult
+	for _, file := range files {
+		content, err := utils.ReadSourceFile(file)
+		if err != nil {
+			logError(fmt.Sprintf("Error reading %s: %v", file, err))
+			continue
+		}
+		results = append(results, errorcheck.Scan(file, content))
+	}
+	return errorcheck.Check(results, errorcheck.DefaultFunctions)
+}
+
+func checkForwardDecls(dir string, files []string) []diagnostics.Diagnostic {
+	return fwddecl.Diagnostics(fwddecl.Find(files, []string{dir}))
+}
+
+func checkFixes(dir string, files []string) []diagnostics.Diagnostic {
+	var findings []diagnostics.Diagnostic
+	for _, rule := range fix.Rules {
+		for _, f := range rule.Find(files, fix.Config{IncludeDirs: []string{dir}}) {
+			findings = append(findings, diagnostics.Diagnostic{
+				File:     f.File,
+				Line:     f.Line,
+				Column:   1,
+				Severity: diagnostics.SeverityWarning,
+				Rule:     "fix/" + f.Rule,
+				Message:  f.Message,
+			})
+		}
+	}
+	return findings
+}
+
+func fixRules() []string {
+	var rules []string
+	for _, rule := range fix.Rules {
+		rules = append(rules, "fix/"+rule.ID)
+	}
+	return rules
+}
+
+func logInfo(verbose bool, msg string) {
+	if verbose {
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
+	}
+}
+
+func logSuccess(msg string) {
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
+}
+
+func logError(msg string) {
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
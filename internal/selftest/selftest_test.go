@@ -0,0 +1,75 @@
+package selftest
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files from the current analyzers")
+
+func TestCorpus(t *testing.T) {
+	dir := t.TempDir()
+	files, err := extractCorpus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectations, err := loadExpectations(dir, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, analyzer := range Analyzers {
+		result := check(analyzer, dir, files, expectations)
+		if *update {
+			if err := os.WriteFile(goldenDir+"/"+analyzer.Name+".golden", []byte(result.Output), 0644); err != nil {
+				t.Fatal(err)
+			}
+			result.Diff = nil
+		}
+		if !result.Passed() {
+			t.Errorf("%s: missing %v, unexpected %v, golden diff:\n%s",
+				analyzer.Name, result.Missing, result.Unexpected, strings.Join(result.Diff, "\n"))
+		}
+	}
+}
+
+// TestCorpusCRLF checks a Windows checkout of the corpus gives the findings
+// of the golden files, on the same lines.
+func TestCorpusCRLF(t *testing.T) {
+	dir := t.TempDir()
+	files, err := extractCorpus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(strings.ReplaceAll(string(data), "\n", "\r\n")), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectations, err := loadExpectations(dir, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, analyzer := range Analyzers {
+		result := check(analyzer, dir, files, expectations)
+		if !result.Passed() {
+			t.Errorf("%s: missing %v, unexpected %v, golden diff:\n%s",
+				analyzer.Name, result.Missing, result.Unexpected, strings.Join(result.Diff, "\n"))
+		}
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	got := diffLines("a\nb\nc\n", "a\nc\nd\n")
+	want := []string{"-b", "+d"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("diffLines = %v, want %v", got, want)
+	}
+}
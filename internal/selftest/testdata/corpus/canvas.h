@@ -0,0 +1,6 @@
+#pragma once
+
+class Canvas {
+public:
+    void draw();
+};
@@ -0,0 +1,15 @@
+#include <stdexcept>
+
+void run();
+
+void guarded() {
+    try {
+        run();
+    } catch (const std::exception &) {} // EXPECT: errors/empty_catch
+
+    try {
+        run();
+    } catch (...) {
+        // Failures are reported by run itself
+    }
+}
@@ -0,0 +1,20 @@
+#include "colors.h"
+
+const char *name(Color color) {
+    switch (color) { // EXPECT: correctness/incomplete_switch
+    case Color::Red:
+        return "red";
+    case Color::Green:
+        return "green";
+    }
+    return "?";
+}
+
+int code(Color color) {
+    switch (color) {
+    case Color::Red:
+        return 1;
+    default:
+        return 0;
+    }
+}
@@ -0,0 +1,3 @@
+#pragma once
+
+enum class Color { Red, Green, Blue };
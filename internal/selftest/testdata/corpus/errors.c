@@ -0,0 +1,36 @@
+#include <stdio.h>
+#include <stdlib.h>
+
+int store_flush(int fd) { // EXPECT: errors/unchecked_error_code
+    if (fd < 0) {
+        return -1;
+    }
+    return 0;
+}
+
+int store_open(const char *path) {
+    if (path == NULL) {
+        return -1;
+    }
+    return 3;
+}
+
+void leak(void) {
+    malloc(16); // EXPECT: errors/unchecked_call
+    store_flush(3);
+}
+
+int checked(const char *path) {
+    char *buffer = malloc(64);
+    FILE *file;
+    if (buffer == NULL) {
+        return -1;
+    }
+    if ((file = fopen(path, "r")) == NULL) {
+        free(buffer);
+        return -1;
+    }
+    (void)fclose(file);
+    free(buffer);
+    return store_open(path) < 0 ? -1 : 0;
+}
@@ -0,0 +1,2 @@
+/* Declarations kept for old callers */
+int legacy(void); // EXPECT: fix/include-guard
@@ -0,0 +1,10 @@
+#include <string.h>
+#include <string.h> // EXPECT: fix/duplicate-include
+#include "util.h" // EXPECT: fix/unused-include
+
+void copy(const char *src) {
+    char name[16];
+    char *alias = name;
+    strcpy(name, src); // EXPECT: fix/strcpy
+    strcpy(alias, src);
+}
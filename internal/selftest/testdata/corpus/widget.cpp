@@ -0,0 +1,6 @@
+#include "widget.h"
+
+void Widget::paint(Canvas *canvas) {
+    canvas_ = canvas;
+    canvas_->draw();
+}
@@ -0,0 +1,11 @@
+#pragma once
+
+#include "canvas.h" // EXPECT: includes/forward_declare
+
+class Widget {
+public:
+    void paint(Canvas *canvas);
+
+private:
+    Canvas *canvas_;
+};
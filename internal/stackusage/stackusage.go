@@ -0,0 +1,821 @@
+package stackusage
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/errorcheck"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/registry"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
+)
+
+type Config struct {
+	Language       string
+	Include        []string
+	Exclude        []string
+	Recursive      bool
+	Depth          int
+	Jobs           int
+	Verbose        bool
+	Entries        []string
+	Budget         int64
+	PointerSize    int64
+	CallOverhead   int64
+	OutputFile     string
+	Format         string
+	SkipGenerated  bool
+	SkipVendor     bool
+	MaxFileSize    int64
+	FollowSymlinks bool
+	IncludeHidden  bool
+	NoProgress     bool
+	Manifest       *manifest.Manifest
+}
+
+// Frame is the estimated stack frame of a function: its locals plus the call
+// overhead. Unknown lists the locals whose size could not be resolved, such
+// as arrays sized by an unknown macro, which are counted as zero.
+type Frame struct {
+	Name    string   `json:"name"`
+	File    string   `json:"file"`
+	Line    int      `json:"line"`
+	Bytes   int64    `json:"bytes"`
+	Unknown []string `json:"unknown,omitempty"`
+
+	callees []string
+}
+
+// EntryPoint is the worst-case stack depth reached from a function nobody
+// calls, or from a function given with --entry.
+type EntryPoint struct {
+	Name      string   `json:"name"`
+	File      string   `json:"file"`
+	Line      int      `json:"line"`
+	Bytes     int64    `json:"bytes"`
+	Path      []string `json:"path"`
+	Recursive bool     `json:"recursive"`
+	// Approximate is set when a frame on the path has locals of unknown size
+	Approximate bool `json:"approximate"`
+	OverBudget  bool `json:"over_budget"`
+}
+
+type Report struct {
+	PointerSize  int64        `json:"pointer_size"`
+	CallOverhead int64        `json:"call_overhead"`
+	Budget       int64        `json:"budget,omitempty"`
+	EntryPoints  []EntryPoint `json:"entry_points"`
+	Recursion    [][]string   `json:"recursion,omitempty"`
+	Frames       []Frame      `json:"frames"`
+}
+
+// Frames listed in the markdown report, JSON has all of them.
+const topFrames = 10
+
+func Run(config Config) error {
+	logInfo(config.Verbose, "Starting stack usage estimation")
+
+	if config.Language == "" {
+		config.Language = "c"
+	}
+	if config.Language != "c" && config.Language != "cpp" {
+		return fmt.Errorf("stack usage is not supported for language: %s (expected c or cpp)", config.Language)
+	}
+	if config.PointerSize == 0 {
+		config.PointerSize = 4
+	}
+	format := outputFormat(config)
+	if format != "markdown" && format != "json" && format != "csv" {
+		return fmt.Errorf("unsupported format: %s (expected markdown, json or csv)", format)
+	}
+
+	extensions := registry.GetParser(config.Language).GetExtensions()
+	opts := utils.ScanOptions{
+		Include:        config.Include,
+		Exclude:        config.Exclude,
+		Recursive:      config.Recursive,
+		Depth:          config.Depth,
+		SkipGenerated:  config.SkipGenerated,
+		SkipVendor:     config.SkipVendor,
+		MaxFileSize:    config.MaxFileSize,
+		FollowSymlinks: config.FollowSymlinks,
+		IncludeHidden:  config.IncludeHidden,
+		Verbose:        config.Verbose,
+	}
+
+	files, err := utils.GetFilesToProcess(opts, func(path string) bool {
+		ext := filepath.Ext(path)
+		for _, validExt := range extensions {
+			if ext == validExt {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		logError(fmt.Sprintf("Failed to collect files: %v", err))
+		return err
+	}
+
+	if len(files) == 0 {
+		logWarning("No files found matching criteria")
+		return nil
+	}
+
+	logInfo(config.Verbose, fmt.Sprintf("Found %d files to analyze", len(files)))
+	config.Manifest.AddFiles(files)
+
+	// Types and macros are usually declared in headers, so every file is
+	// read before any frame is sized
+	sources := worker.Run(files, config.Jobs, progress.New("Reading functions", len(files), config.NoProgress), func(ctx context.Context, idx int, filePath string) Source {
+		content, err := utils.ReadSourceFile(filePath)
+		if err != nil {
+			failures.Record(filePath, err)
+			return Source{}
+		}
+		return Source{Scan: errorcheck.Scan(filePath, content), Content: content}
+	})
+
+	report, err := Analyze(sources, config)
+	if err != nil {
+		return err
+	}
+
+	if err := writeOutput(report, format, config); err != nil {
+		return err
+	}
+
+	over := 0
+	for _, entry := range report.EntryPoints {
+		if entry.OverBudget {
+			over++
+		}
+	}
+	if over > 0 {
+		return fmt.Errorf("%d entry point(s) exceed the stack budget of %s", over, utils.HumanSize(config.Budget))
+	}
+	return nil
+}
+
+// Source is a scanned file with its text, which holds the #define and type
+// declarations sizing the locals.
+type Source struct {
+	Scan    errorcheck.FileResult
+	Content string
+}
+
+// Analyze sizes every function frame and walks the call graph from each
+// entry point. Functions defined more than once, such as static helpers in
+// several files, are merged under their name with the largest frame.
+func Analyze(sources []Source, config Config) (*Report, error) {
+	types := newTypeTable(config.PointerSize)
+	for _, source := range sources {
+		types.collect(source.Content)
+	}
+
+	frames := make(map[string]*Frame)
+	for _, source := range sources {
+		for _, def := range source.Scan.Definitions {
+			name := unqualified(def.Name)
+			size, unknown := types.localsSize(def.Body)
+			frame, ok := frames[name]
+			if !ok || size+config.CallOverhead > frame.Bytes {
+				callees := []string(nil)
+				if ok {
+					callees = frame.callees
+				}
+				frame = &Frame{Name: name, File: def.File, Line: def.Line, Bytes: size + config.CallOverhead, Unknown: unknown, callees: callees}
+				frames[name] = frame
+			}
+		}
+	}
+
+	called := make(map[string]bool)
+	for _, source := range sources {
+		for _, call := range source.Scan.Calls {
+			function := unqualified(call.Function)
+			caller, ok := frames[function]
+			if !ok || frames[call.Name] == nil {
+				continue
+			}
+			caller.callees = append(caller.callees, call.Name)
+			if call.Name != function {
+				called[call.Name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(frames))
+	for name, frame := range frames {
+		names = append(names, name)
+		frame.callees = dedupe(frame.callees)
+	}
+	sort.Strings(names)
+
+	entries := config.Entries
+	if len(entries) == 0 {
+		for _, name := range names {
+			if !called[name] {
+				entries = append(entries, name)
+			}
+		}
+	}
+
+	walker := &graphWalker{frames: frames, memo: make(map[string]walk), onStack: make(map[string]int), cycles: make(map[string][]string)}
+	report := &Report{PointerSize: config.PointerSize, CallOverhead: config.CallOverhead, Budget: config.Budget}
+
+	for _, name := range entries {
+		frame, ok := frames[name]
+		if !ok {
+			return nil, fmt.Errorf("entry point %s is not defined in the scanned files", name)
+		}
+		result := walker.visit(name)
+		entry := EntryPoint{
+			Name:       name,
+			File:       frame.File,
+			Line:       frame.Line,
+			Bytes:      result.bytes,
+			Path:       result.path,
+			Recursive:  result.recursive,
+			OverBudget: config.Budget > 0 && result.bytes > config.Budget,
+		}
+		for _, step := range result.path {
+			if len(frames[step].Unknown) > 0 {
+				entry.Approximate = true
+			}
+		}
+		report.EntryPoints = append(report.EntryPoints, entry)
+	}
+	sort.SliceStable(report.EntryPoints, func(i, j int) bool {
+		return report.EntryPoints[i].Bytes > report.EntryPoints[j].Bytes
+	})
+
+	var keys []string
+	for key := range walker.cycles {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		report.Recursion = append(report.Recursion, walker.cycles[key])
+	}
+
+	for _, name := range names {
+		report.Frames = append(report.Frames, *frames[name])
+	}
+	sort.SliceStable(report.Frames, func(i, j int) bool {
+		return report.Frames[i].Bytes > report.Frames[j].Bytes
+	})
+
+	return report, nil
+}
+
+type walk struct {
+	bytes     int64
+	path      []string
+	recursive bool
+}
+
+// graphWalker finds the deepest path below each function. A call back into
+// a function on the current path is recursion: the cycle is recorded and the
+// path is marked unbounded, its size covering a single pass.
+type graphWalker struct {
+	frames  map[string]*Frame
+	memo    map[string]walk
+	stack   []string
+	onStack map[string]int
+	cycles  map[string][]string
+}
+
+func (w *graphWalker) visit(name string) walk {
+	if result, ok := w.memo[name]; ok {
+		return result
+	}
+
+	w.onStack[name] = len(w.stack)
+	w.stack = append(w.stack, name)
+
+	var deepest walk
+	recursive := false
+	for _, callee := range w.frames[name].callees {
+		if start, ok := w.onStack[callee]; ok {
+			recursive = true
+			w.addCycle(append(append([]string{}, w.stack[start:]...), callee))
+			continue
+		}
+		result := w.visit(callee)
+		recursive = recursive || result.recursive
+		if deepest.path == nil || result.bytes > deepest.bytes {
+			deepest = result
+		}
+	}
+
+	w.stack = w.stack[:len(w.stack)-1]
+	delete(w.onStack, name)
+
+	result := walk{
+		bytes:     w.frames[name].Bytes + deepest.bytes,
+		path:      append([]string{name}, deepest.path...),
+		recursive: recursive,
+	}
+	w.memo[name] = result
+	return result
+}
+
+// addCycle records a cycle once, whichever function it was entered from.
+func (w *graphWalker) addCycle(cycle []string) {
+	members := append([]string{}, cycle[:len(cycle)-1]...)
+	sort.Strings(members)
+	key := strings.Join(members, ",")
+	if _, ok := w.cycles[key]; !ok {
+		w.cycles[key] = cycle
+	}
+}
+
+var (
+	defineRegex        = regexp.MustCompile(`(?m)^[ \t]*#[ \t]*define[ \t]+(\w+)[ \t]+([^/\n]+?)[ \t]*(?:/[/*].*)?$`)
+	structRegex        = regexp.MustCompile(`\b(?:struct|union|class)\s+(\w+)\s*(?::[^{;]*)?\{`)
+	typedefStructRegex = regexp.MustCompile(`\btypedef\s+(?:struct|union)\s*\w*\s*\{`)
+	typedefRegex       = regexp.MustCompile(`\btypedef\s+([\w\s]+?)\s*(\**)\s*(\w+)\s*;`)
+	declaratorRegex    = regexp.MustCompile(`^(\**)\s*([A-Za-z_]\w*)\s*((?:\[[^\]]*\]\s*)*)(?:=.*)?$`)
+	dimensionRegex     = regexp.MustCompile(`\[([^\]]*)\]`)
+	termRegex          = regexp.MustCompile(`^\s*(\w+)\s*$`)
+	forRegex           = regexp.MustCompile(`^\s*for\s*\(`)
+)
+
+// Words that may precede or make up a builtin type name.
+var (
+	qualifiers   = map[string]bool{"const": true, "volatile": true, "register": true, "auto": true, "restrict": true}
+	storageWords = map[string]bool{"static": true, "extern": true, "thread_local": true, "_Thread_local": true}
+	tagWords     = map[string]bool{"struct": true, "union": true, "enum": true, "class": true}
+	builtinWords = map[string]bool{"char": true, "short": true, "int": true, "long": true, "float": true, "double": true,
+		"signed": true, "unsigned": true, "_Bool": true, "bool": true}
+)
+
+type typeTable struct {
+	pointer int64
+	sizes   map[string]int64
+	defines map[string]string
+	// Struct bodies are sized on first use, so a member type may be declared
+	// after the struct using it
+	structs map[string]string
+	sizing  map[string]bool
+}
+
+func newTypeTable(pointer int64) *typeTable {
+	return &typeTable{
+		pointer: pointer,
+		sizes: map[string]int64{
+			"int8_t": 1, "uint8_t": 1, "int16_t": 2, "uint16_t": 2, "int32_t": 4, "uint32_t": 4,
+			"int64_t": 8, "uint64_t": 8, "size_t": pointer, "ssize_t": pointer, "ptrdiff_t": pointer,
+			"intptr_t": pointer, "uintptr_t": pointer, "wchar_t": 4, "char16_t": 2, "char32_t": 4,
+			"float32_t": 4, "float64_t": 8, "BaseType_t": pointer, "UBaseType_t": pointer, "TickType_t": 4,
+		},
+		defines: make(map[string]string),
+		structs: make(map[string]string),
+		sizing:  make(map[string]bool),
+	}
+}
+
+// collect records the #define constants, structs and typedefs of a file.
+func (t *typeTable) collect(content string) {
+	for _, match := range defineRegex.FindAllStringSubmatch(content, -1) {
+		t.defines[match[1]] = match[2]
+	}
+
+	code := utils.BlankCommentsAndLiterals(content)
+	for _, loc := range structRegex.FindAllStringSubmatchIndex(code, -1) {
+		end := utils.MatchingBracket(code, loc[1]-1, '{', '}')
+		if end > 0 {
+			t.structs[code[loc[2]:loc[3]]] = code[loc[1]:end]
+		}
+	}
+	for _, loc := range typedefStructRegex.FindAllStringIndex(code, -1) {
+		end := utils.MatchingBracket(code, loc[1]-1, '{', '}')
+		if end < 0 {
+			continue
+		}
+		rest := code[end+1:]
+		if semicolon := strings.IndexByte(rest, ';'); semicolon >= 0 {
+			for _, name := range strings.Split(rest[:semicolon], ",") {
+				name = strings.TrimSpace(name)
+				if termRegex.MatchString(name) {
+					t.structs[name] = code[loc[1]:end]
+				}
+			}
+		}
+	}
+	for _, match := range typedefRegex.FindAllStringSubmatch(code, -1) {
+		if match[2] != "" {
+			t.sizes[match[3]] = t.pointer
+		} else if size, ok := t.typeSize(strings.Fields(match[1])); ok {
+			t.sizes[match[3]] = size
+		}
+	}
+}
+
+// typeSize returns the size of a type spelled as words, e.g. "unsigned long"
+// or "struct packet".
+func (t *typeTable) typeSize(words []string) (int64, bool) {
+	var builtin []string
+	tag := ""
+	for _, word := range words {
+		switch {
+		case qualifiers[word]:
+		case tagWords[word]:
+			tag = word
+		case builtinWords[word]:
+			builtin = append(builtin, word)
+		default:
+			if tag == "enum" {
+				return 4, true
+			}
+			if size, ok := t.sizes[word]; ok {
+				return size, true
+			}
+			if body, ok := t.structs[word]; ok && !t.sizing[word] {
+				t.sizing[word] = true
+				size, _ := t.localsSize(body)
+				delete(t.sizing, word)
+				// Round up to the pointer size for the padding at the end
+				size = (size + t.pointer - 1) / t.pointer * t.pointer
+				t.sizes[word] = size
+				return size, true
+			}
+			return 0, false
+		}
+	}
+
+	longs := 0
+	for _, word := range builtin {
+		if word == "long" {
+			longs++
+		}
+	}
+	switch {
+	case len(builtin) == 0:
+		return 0, false
+	case contains(builtin, "char"), contains(builtin, "_Bool"), contains(builtin, "bool"):
+		return 1, true
+	case contains(builtin, "short"):
+		return 2, true
+	case contains(builtin, "float"):
+		return 4, true
+	case contains(builtin, "double"):
+		return 8, true
+	case longs >= 2:
+		return 8, true
+	case longs == 1:
+		return t.pointer, true
+	default:
+		return 4, true
+	}
+}
+
+// localsSize adds up the variables declared in a block of code, skipping
+// statics. Variables of nested blocks are all added, so the result is an
+// upper bound. Only statements starting with a known type count as
+// declarations.
+func (t *typeTable) localsSize(code string) (int64, []string) {
+	var total int64
+	var unknown []string
+
+	for _, statement := range statements(code) {
+		statement = forRegex.ReplaceAllString(statement, "")
+		words := strings.Fields(strings.NewReplacer("*", " * ", "[", " [", "=", " = ").Replace(statement))
+		if len(words) < 2 {
+			continue
+		}
+
+		typeEnd, static := 0, false
+		for typeEnd < len(words) {
+			word := words[typeEnd]
+			if storageWords[word] {
+				static = true
+			} else if !qualifiers[word] && !tagWords[word] && !builtinWords[word] {
+				break
+			}
+			typeEnd++
+		}
+		// A type name that is not a builtin word, e.g. uint8_t or a struct tag
+		if typeEnd < len(words) && (typeEnd == 0 || tagWords[words[typeEnd-1]] || !hasBuiltin(words[:typeEnd])) {
+			typeEnd++
+		}
+		if static || typeEnd >= len(words) {
+			continue
+		}
+
+		size, ok := t.typeSize(words[:typeEnd])
+		if !ok {
+			continue
+		}
+
+		for _, declarator := range splitTopLevel(strings.Join(words[typeEnd:], " ")) {
+			match := declaratorRegex.FindStringSubmatch(strings.TrimSpace(declarator))
+			if match == nil {
+				continue
+			}
+			elem := size
+			if match[1] != "" {
+				elem = t.pointer
+			}
+			count := int64(1)
+			for _, dim := range dimensionRegex.FindAllStringSubmatch(match[3], -1) {
+				value, ok := t.evaluate(dim[1])
+				if !ok {
+					unknown = append(unknown, match[2]+"["+strings.TrimSpace(dim[1])+"]")
+					count = 0
+					break
+				}
+				count *= value
+			}
+			total += elem * count
+		}
+	}
+
+	return total, unknown
+}
+
+// evaluate computes an array dimension made of numbers, #define constants,
+// + and *. Anything else, including a variable length, is unknown.
+func (t *typeTable) evaluate(expression string) (int64, bool) {
+	return t.evaluateDepth(expression, 0)
+}
+
+// Nesting of #define constants followed before giving up, which also stops
+// self-referencing macros.
+const maxDefineDepth = 8
+
+func (t *typeTable) evaluateDepth(expression string, depth int) (int64, bool) {
+	var sum int64
+	for _, term := range strings.Split(expression, "+") {
+		product := int64(1)
+		for _, factor := range strings.Split(term, "*") {
+			match := termRegex.FindStringSubmatch(strings.Trim(factor, " ()"))
+			if match == nil {
+				return 0, false
+			}
+			value, err := strconv.ParseInt(strings.TrimRight(match[1], "uUlL"), 0, 64)
+			if err != nil {
+				define, ok := t.defines[match[1]]
+				if !ok || depth == maxDefineDepth {
+					return 0, false
+				}
+				if value, ok = t.evaluateDepth(define, depth+1); !ok {
+					return 0, false
+				}
+			}
+			product *= value
+		}
+		sum += product
+	}
+	return sum, true
+}
+
+// statements splits code at semicolons and braces, keeping brace
+// initializers such as = {1, 2} inside their statement.
+func statements(code string) []string {
+	var result []string
+	start := 0
+	for i := 0; i < len(code); i++ {
+		switch code[i] {
+		case '=':
+			next := i + 1
+			for next < len(code) && (code[next] == ' ' || code[next] == '\t' || code[next] == '\n' || code[next] == '\r') {
+				next++
+			}
+			if next < len(code) && code[next] == '{' {
+				if end := utils.MatchingBracket(code, next, '{', '}'); end > 0 {
+					i = end
+				}
+			}
+		case ';', '{', '}':
+			result = append(result, code[start:i])
+			start = i + 1
+		}
+	}
+	return append(result, code[start:])
+}
+
+func splitTopLevel(text string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, text[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, text[start:])
+}
+
+func hasBuiltin(words []string) bool {
+	for _, word := range words {
+		if builtinWords[word] {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(words []string, word string) bool {
+	for _, w := range words {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+func dedupe(names []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func unqualified(name string) string {
+	if i := strings.LastIndex(name, "::"); i >= 0 {
+		return name[i+2:]
+	}
+	return name
+}
+
+func outputFormat(config Config) string {
+	if config.Format != "" {
+		return config.Format
+	}
+	switch filepath.Ext(config.OutputFile) {
+	case ".json":
+		return "json"
+	case ".csv":
+		return "csv"
+	}
+	return "markdown"
+}
+
+func writeOutput(report *Report, format string, config Config) error {
+	report = displayPaths(report)
+	var output []byte
+	var err error
+
+	switch format {
+	case "json":
+		output, err = json.MarshalIndent(report, "", "  ")
+		output = append(output, '\n')
+	case "csv":
+		output, err = formatCSV(report)
+	default:
+		output = []byte(formatMarkdown(report))
+	}
+	if err != nil {
+		return err
+	}
+
+	if config.OutputFile != "" {
+		config.Manifest.AddResult(config.OutputFile, output)
+		if err := os.WriteFile(config.OutputFile, output, 0644); err != nil {
+			logError(fmt.Sprintf("Failed to write report: %v", err))
+			return err
+		}
+		logSuccess(fmt.Sprintf("Stack usage report written to %s", config.OutputFile))
+	} else {
+		config.Manifest.AddResult("stdout", output)
+		fmt.Print(string(output))
+	}
+	return nil
+}
+
+// displayPaths returns a copy of the report with the frame and entry point
+// files in the selected path style.
+func displayPaths(report *Report) *Report {
+	displayed := *report
+	displayed.Frames = make([]Frame, len(report.Frames))
+	for i, frame := range report.Frames {
+		frame.File = utils.DisplayPath(frame.File)
+		displayed.Frames[i] = frame
+	}
+	displayed.EntryPoints = make([]EntryPoint, len(report.EntryPoints))
+	for i, entry := range report.EntryPoints {
+		entry.File = utils.DisplayPath(entry.File)
+		displayed.EntryPoints[i] = entry
+	}
+	return &displayed
+}
+
+func formatMarkdown(report *Report) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Stack Usage\n\n")
+	sb.WriteString(fmt.Sprintf("Estimated with %d-byte pointers and %d bytes of overhead per call", report.PointerSize, report.CallOverhead))
+	if report.Budget > 0 {
+		sb.WriteString(fmt.Sprintf(", against a budget of %s B", utils.FormatCount(int(report.Budget))))
+	}
+	sb.WriteString(".\n\n")
+
+	sb.WriteString("| Entry Point | Worst Case | Deepest Path | Notes |\n")
+	sb.WriteString("|-------------|------------|--------------|-------|\n")
+	for _, entry := range report.EntryPoints {
+		sb.WriteString(fmt.Sprintf("| `%s` (%s:%d) | %s B | %s | %s |\n",
+			entry.Name, entry.File, entry.Line, utils.FormatCount(int(entry.Bytes)), strings.Join(entry.Path, " → "), strings.Join(entryNotes(entry), ", ")))
+	}
+
+	if len(report.Recursion) > 0 {
+		sb.WriteString("\n## Recursion\n")
+		for _, cycle := range report.Recursion {
+			sb.WriteString(fmt.Sprintf("- %s\n", strings.Join(cycle, " → ")))
+		}
+	}
+
+	sb.WriteString("\n## Largest Frames\n")
+	for i, frame := range report.Frames {
+		if i == topFrames {
+			break
+		}
+		sb.WriteString(fmt.Sprintf("1. `%s` (%s:%d) - %s B", frame.Name, frame.File, frame.Line, utils.FormatCount(int(frame.Bytes))))
+		if len(frame.Unknown) > 0 {
+			sb.WriteString(fmt.Sprintf(", not counting %s", strings.Join(frame.Unknown, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func entryNotes(entry EntryPoint) []string {
+	var notes []string
+	if entry.OverBudget {
+		notes = append(notes, "over budget")
+	}
+	if entry.Recursive {
+		notes = append(notes, "recursive, unbounded")
+	}
+	if entry.Approximate {
+		notes = append(notes, "approximate")
+	}
+	return notes
+}
+
+func formatCSV(report *Report) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	writer.Write([]string{"entry_point", "file", "line", "bytes", "path", "recursive", "approximate", "over_budget"})
+	for _, entry := range report.EntryPoints {
+		writer.Write([]string{entry.Name, entry.File, strconv.Itoa(entry.Line), strconv.FormatInt(entry.Bytes, 10),
+			strings.Join(entry.Path, " > "), strconv.FormatBool(entry.Recursive), strconv.FormatBool(entry.Approximate),
+			strconv.FormatBool(entry.OverBudget)})
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+func logInfo(verbose bool, msg string) {
+	if verbose {
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
+	}
+}
+
+func logSuccess(msg string) {
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
+}
+
+func logWarning(msg string) {
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
+}
+
+func logError(msg string) {
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
@@ -0,0 +1,90 @@
+package stackusage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vitruves/gop/internal/errorcheck"
+)
+
+func TestAnalyze(t *testing.T) {
+	header := `
+#define RX_LEN 64
+#define LINE_MAX (RX_LEN * 2)
+
+typedef struct {
+    uint8_t id;
+    uint16_t len;
+    char payload[RX_LEN];
+} packet_t;
+`
+	source := `
+static int parse(const char *line)
+{
+    packet_t pkt;
+    int i, values[4] = {0, 1, 2, 3};
+    static char cache[1024];
+    for (int n = 0; n < 4; n++) {
+        values[n] += line[n];
+    }
+    return send(&pkt);
+}
+
+int send(packet_t *pkt) {
+    char frame[LINE_MAX + 2];
+    return write_frame(frame, pkt->len);
+}
+
+int eval(const char *expr) {
+    double stack[8];
+    if (*expr == '(')
+        return eval(expr + 1);
+    return 0;
+}
+
+int main(void) {
+    char buf[RX_LEN];
+    char dynamic[n];
+    parse(buf);
+    eval(buf);
+    return 0;
+}
+`
+	sources := []Source{
+		{Scan: errorcheck.Scan("board.h", header), Content: header},
+		{Scan: errorcheck.Scan("main.c", source), Content: source},
+	}
+	report, err := Analyze(sources, Config{PointerSize: 4, CallOverhead: 8, Budget: 256})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frames := make(map[string]Frame)
+	for _, frame := range report.Frames {
+		frames[frame.Name] = frame
+	}
+	// packet_t: 1 + 2 + 64 rounded up to 68, plus i, values and n
+	if frames["parse"].Bytes != 68+4+16+4+8 {
+		t.Errorf("parse frame = %d", frames["parse"].Bytes)
+	}
+	if frames["send"].Bytes != 130+8 || frames["eval"].Bytes != 64+8 {
+		t.Errorf("send frame = %d, eval frame = %d", frames["send"].Bytes, frames["eval"].Bytes)
+	}
+	if frames["main"].Bytes != 64+8 || strings.Join(frames["main"].Unknown, ",") != "dynamic[n]" {
+		t.Errorf("main frame = %+v", frames["main"])
+	}
+
+	if len(report.EntryPoints) != 1 {
+		t.Fatalf("expected main as the only entry point, got %+v", report.EntryPoints)
+	}
+	main := report.EntryPoints[0]
+	if main.Bytes != 72+100+138 || strings.Join(main.Path, ">") != "main>parse>send" {
+		t.Errorf("main entry = %+v", main)
+	}
+	if !main.Recursive || !main.OverBudget || !main.Approximate {
+		t.Errorf("main entry flags = %+v", main)
+	}
+	if len(report.Recursion) != 1 || strings.Join(report.Recursion[0], ">") != "eval>eval" {
+		t.Errorf("recursion = %v", report.Recursion)
+	}
+}
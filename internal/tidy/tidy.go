@@ -0,0 +1,373 @@
+package tidy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/errorcheck"
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/progress"
+	"github.com/vitruves/gop/internal/utils"
+	"github.com/vitruves/gop/internal/worker"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	// BuildDir holds compile_commands.json
+	BuildDir  string
+	ClangTidy string
+	// Checks is passed to clang-tidy --checks, its .clang-tidy files apply
+	// when empty
+	Checks     string
+	GopChecks  bool
+	Include    []string
+	Exclude    []string
+	Jobs       int
+	Verbose    bool
+	OutputFile string
+	Format     string
+	NoProgress bool
+	Manifest   *manifest.Manifest
+}
+
+// CompileCommand is an entry of a compilation database.
+type CompileCommand struct {
+	Directory string `json:"directory"`
+	File      string `json:"file"`
+	// Command is the command line as one string, when Arguments is empty
+	Command   string   `json:"command,omitempty"`
+	Arguments []string `json:"arguments,omitempty"`
+}
+
+// fixes is the document clang-tidy writes with --export-fixes. Versions
+// before 9 put the message fields directly on the diagnostic.
+type fixes struct {
+	Diagnostics []struct {
+		DiagnosticName    string `yaml:"DiagnosticName"`
+		Level             string `yaml:"Level"`
+		Message           string `yaml:"Message"`
+		FilePath          string `yaml:"FilePath"`
+		FileOffset        int    `yaml:"FileOffset"`
+		DiagnosticMessage struct {
+			Message    string `yaml:"Message"`
+			FilePath   string `yaml:"FilePath"`
+			FileOffset int    `yaml:"FileOffset"`
+		} `yaml:"DiagnosticMessage"`
+	} `yaml:"Diagnostics"`
+}
+
+func Run(config Config) error {
+	logInfo(config.Verbose, "Starting clang-tidy run")
+
+	if config.Format == "" {
+		config.Format = "text"
+	}
+	if config.Format != "text" && !diagnostics.IsFormat(config.Format) {
+		return fmt.Errorf("unsupported format: %s (expected text, %s)", config.Format, strings.Join(diagnostics.Formats, ", "))
+	}
+	if config.BuildDir == "" {
+		config.BuildDir = "."
+		if _, err := os.Stat(filepath.Join("build", "compile_commands.json")); err == nil {
+			config.BuildDir = "build"
+		}
+	}
+	if config.ClangTidy == "" {
+		config.ClangTidy = "clang-tidy"
+	}
+
+	database := filepath.Join(config.BuildDir, "compile_commands.json")
+	commands, err := LoadDatabase(database)
+	if err != nil {
+		return fmt.Errorf("%w (generate it with -DCMAKE_EXPORT_COMPILE_COMMANDS=ON or bear, and point -p at its directory)", err)
+	}
+
+	files := SourceFiles(commands, config.Include, config.Exclude)
+	if len(files) == 0 {
+		logWarning("No files of the compilation database match")
+		return nil
+	}
+	logInfo(config.Verbose, fmt.Sprintf("Found %d files in %s", len(files), database))
+	config.Manifest.AddFiles(files)
+
+	var results [][]diagnostics.Diagnostic
+	if binary, err := exec.LookPath(config.ClangTidy); err != nil {
+		if !config.GopChecks {
+			return fmt.Errorf("%s not found in PATH", config.ClangTidy)
+		}
+		logWarning(fmt.Sprintf("%s not found in PATH, install clang-tidy to include its checks. Running gop's checks only", config.ClangTidy))
+	} else {
+		tempDir, err := os.MkdirTemp("", "gop-tidy-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tempDir)
+
+		results = worker.Run(files, config.Jobs, progress.New("Running clang-tidy", len(files), config.NoProgress), func(ctx context.Context, idx int, filePath string) []diagnostics.Diagnostic {
+			fixesFile := filepath.Join(tempDir, fmt.Sprintf("%d.yaml", idx))
+			findings, err := runClangTidy(ctx, binary, config, filePath, fixesFile)
+			if err != nil {
+				logError(fmt.Sprintf("clang-tidy failed on %s: %v", filePath, err))
+			}
+			return findings
+		})
+	}
+
+	var findings []diagnostics.Diagnostic
+	for _, fileFindings := range results {
+		findings = append(findings, fileFindings...)
+	}
+
+	if config.GopChecks {
+		scans := worker.Run(files, config.Jobs, progress.New("Checking error handling", len(files), config.NoProgress), func(ctx context.Context, idx int, filePath string) errorcheck.FileResult {
+			content, err := utils.ReadSourceFile(filePath)
+			if err != nil {
+				failures.Record(filePath, err)
+				return errorcheck.FileResult{}
+			}
+			return errorcheck.Scan(filePath, content)
+		})
+		findings = append(findings, errorcheck.Check(scans, errorcheck.DefaultFunctions)...)
+	}
+
+	return writeOutput(Normalize(findings, utils.NewPathResolver(".")), config)
+}
+
+// LoadDatabase reads a compile_commands.json file.
+func LoadDatabase(path string) ([]CompileCommand, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var commands []CompileCommand
+	if err := json.Unmarshal(content, &commands); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return commands, nil
+}
+
+// SourceFiles lists the files of a compilation database once each, relative
+// to the current directory when below it. Include and exclude patterns are
+// globs or directories.
+func SourceFiles(commands []CompileCommand, include, exclude []string) []string {
+	cwd, _ := os.Getwd()
+	seen := make(map[string]bool)
+	var files []string
+
+	for _, command := range commands {
+		path := command.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(command.Directory, path)
+		}
+		path = filepath.Clean(path)
+		if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if len(include) > 0 && !matchesPath(path, include) {
+			continue
+		}
+		if matchesPath(path, exclude) {
+			continue
+		}
+		files = append(files, path)
+	}
+
+	sort.Strings(files)
+	return files
+}
+
+// matchesPath reports whether path matches one of the patterns or lies
+// below a directory named by one.
+func matchesPath(path string, patterns []string) bool {
+	if utils.MatchPath(path, patterns) {
+		return true
+	}
+	for _, pattern := range patterns {
+		if strings.HasPrefix(path, filepath.Clean(pattern)+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func runClangTidy(ctx context.Context, binary string, config Config, filePath, fixesFile string) ([]diagnostics.Diagnostic, error) {
+	args := []string{"-p", config.BuildDir, "--quiet", "--export-fixes=" + fixesFile}
+	if config.Checks != "" {
+		args = append(args, "--checks="+config.Checks)
+	}
+	args = append(args, filePath)
+
+	var stderr bytes.Buffer
+	command := exec.CommandContext(ctx, binary, args...)
+	command.Stderr = &stderr
+	// Findings go to the fixes file, the exit status is only set for
+	// compiler errors, which are part of the findings as well
+	runErr := command.Run()
+
+	content, err := os.ReadFile(fixesFile)
+	if os.IsNotExist(err) {
+		if runErr != nil {
+			return nil, fmt.Errorf("%v: %s", runErr, strings.TrimSpace(stderr.String()))
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseFixes(content)
+}
+
+// ParseFixes converts an --export-fixes document into diagnostics named
+// "clang-tidy/<check>", with offsets turned into lines and columns.
+func ParseFixes(content []byte) ([]diagnostics.Diagnostic, error) {
+	var document fixes
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+
+	indexes := make(map[string]utils.LineIndex)
+	var findings []diagnostics.Diagnostic
+	for _, entry := range document.Diagnostics {
+		message, file, offset := entry.DiagnosticMessage.Message, entry.DiagnosticMessage.FilePath, entry.DiagnosticMessage.FileOffset
+		if message == "" {
+			message, file, offset = entry.Message, entry.FilePath, entry.FileOffset
+		}
+		if file == "" {
+			continue
+		}
+
+		index, ok := indexes[file]
+		if !ok {
+			// Offsets count bytes of the file as it is on disk
+			if data, err := os.ReadFile(file); err == nil {
+				index = utils.NewLineIndex(string(data))
+			}
+			indexes[file] = index
+		}
+
+		finding := diagnostics.Diagnostic{
+			File:     file,
+			Severity: diagnostics.SeverityWarning,
+			Rule:     "clang-tidy/" + entry.DiagnosticName,
+			Message:  message,
+		}
+		if index != nil {
+			finding.Line, finding.Column = index.Position(offset)
+		}
+		switch entry.Level {
+		case "Error":
+			finding.Severity = diagnostics.SeverityError
+		case "Remark":
+			finding.Severity = diagnostics.SeverityInfo
+		}
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
+
+// Normalize makes paths relative to the current directory and drops the
+// findings outside it, along with the header findings repeated for every
+// file including the header.
+func Normalize(findings []diagnostics.Diagnostic, resolver *utils.PathResolver) []diagnostics.Diagnostic {
+	seen := make(map[string]bool)
+	var result []diagnostics.Diagnostic
+
+	for _, finding := range findings {
+		resolved, ok := resolver.Resolve(finding.File)
+		if !ok {
+			continue
+		}
+		finding.File = resolved
+
+		key := fmt.Sprintf("%s:%d:%d:%s:%s", finding.File, finding.Line, finding.Column, finding.Rule, finding.Message)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, finding)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].File != result[j].File {
+			return result[i].File < result[j].File
+		}
+		if result[i].Line != result[j].Line {
+			return result[i].Line < result[j].Line
+		}
+		return result[i].Column < result[j].Column
+	})
+	return result
+}
+
+func writeOutput(findings []diagnostics.Diagnostic, config Config) error {
+	findings = diagnostics.DisplayPaths(diagnostics.Filtered(findings))
+	var sb strings.Builder
+
+	if config.Format == "text" {
+		if len(findings) == 0 {
+			logSuccess("No findings")
+			return nil
+		}
+
+		for _, finding := range findings {
+			sb.WriteString(fmt.Sprintf("%s:%d:%d: %s: %s [%s]\n", finding.File, finding.Line, finding.Column, finding.Severity, finding.Message, finding.Rule))
+		}
+	} else if err := diagnostics.WriteReport(&sb, config.Format, findings); err != nil {
+		return err
+	}
+	output := []byte(sb.String())
+
+	if config.OutputFile != "" {
+		config.Manifest.AddResult(config.OutputFile, output)
+		if err := os.WriteFile(config.OutputFile, output, 0644); err != nil {
+			logError(fmt.Sprintf("Failed to write report: %v", err))
+			return err
+		}
+	} else {
+		config.Manifest.AddResult("stdout", output)
+		fmt.Print(string(output))
+	}
+
+	if config.Format == "text" {
+		logWarning(fmt.Sprintf("Found %d findings", len(findings)))
+	}
+	return nil
+}
+
+func logInfo(verbose bool, msg string) {
+	if verbose {
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
+	}
+}
+
+func logSuccess(msg string) {
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
+}
+
+func logWarning(msg string) {
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
+}
+
+func logError(msg string) {
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
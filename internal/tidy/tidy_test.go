@@ -0,0 +1,76 @@
+package tidy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/vitruves/gop/internal/diagnostics"
+)
+
+func TestParseFixes(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "parse.c")
+	if err := os.WriteFile(source, []byte("int main(void) {\n  int unused = 0;\n  return 0;\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fixes := `---
+MainSourceFile: '` + source + `'
+Diagnostics:
+  - DiagnosticName: clang-analyzer-deadcode.DeadStores
+    DiagnosticMessage:
+      Message: 'Value stored to ''unused'' during its initialization is never read'
+      FilePath: '` + source + `'
+      FileOffset: 23
+      Replacements: []
+    Level: Warning
+    BuildDirectory: '` + dir + `'
+  - DiagnosticName: clang-diagnostic-error
+    Message: 'unknown type name ''foo_t'''
+    FilePath: '` + source + `'
+    FileOffset: 0
+    Replacements: []
+    Level: Error
+...
+`
+	findings, err := ParseFixes([]byte(fixes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []diagnostics.Diagnostic{
+		{File: source, Line: 2, Column: 7, Severity: diagnostics.SeverityWarning, Rule: "clang-tidy/clang-analyzer-deadcode.DeadStores",
+			Message: "Value stored to 'unused' during its initialization is never read"},
+		{File: source, Line: 1, Column: 1, Severity: diagnostics.SeverityError, Rule: "clang-tidy/clang-diagnostic-error",
+			Message: "unknown type name 'foo_t'"},
+	}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("Expected %+v, got %+v", want, findings)
+	}
+}
+
+func TestSourceFiles(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	build := filepath.Join(cwd, "build")
+	commands := []CompileCommand{
+		{Directory: build, File: "../src/main.c"},
+		{Directory: build, File: filepath.Join(cwd, "src", "main.c")},
+		{Directory: build, File: filepath.Join(cwd, "third_party", "zlib", "inflate.c")},
+		{Directory: build, File: filepath.Join(cwd, "src", "util.c")},
+	}
+
+	files := SourceFiles(commands, nil, []string{"third_party"})
+	want := []string{filepath.Join("src", "main.c"), filepath.Join("src", "util.c")}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("Expected %v, got %v", want, files)
+	}
+
+	if files := SourceFiles(commands, []string{"util.c"}, nil); len(files) != 1 || files[0] != want[1] {
+		t.Errorf("Expected only util.c to be included, got %v", files)
+	}
+}
@@ -0,0 +1,392 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/vitruves/gop/internal/editor"
+	"golang.org/x/term"
+)
+
+// Item is one entry of a pane, opened in the editor at File, Line and
+// Column. Kind is what the kind sort groups by, e.g. a severity.
+type Item struct {
+	File   string
+	Line   int
+	Column int
+	Kind   string
+	Title  string
+	Detail string
+}
+
+type Pane struct {
+	Name  string
+	Items []Item
+}
+
+type Config struct {
+	Panes []Pane
+	// Editor is the command line of the editor, see editor.Editor
+	Editor string
+}
+
+// Sorts are the orders a pane cycles through, default is the order the
+// items were given in.
+var Sorts = []string{"default", "file", "kind"}
+
+type action int
+
+const (
+	actionNone action = iota
+	actionQuit
+	actionOpen
+)
+
+const help = "↑↓ move  ←→ tab pane  enter open  / filter  s sort  q quit"
+
+// model is the state of the browser, kept apart from the terminal so key
+// handling and rendering can be tested.
+type model struct {
+	panes   []Pane
+	active  int
+	cursor  []int
+	offset  []int
+	filter  []string
+	sortBy  []int
+	editing bool
+	input   string
+	width   int
+	height  int
+}
+
+func newModel(panes []Pane, width, height int) *model {
+	return &model{
+		panes:  panes,
+		cursor: make([]int, len(panes)),
+		offset: make([]int, len(panes)),
+		filter: make([]string, len(panes)),
+		sortBy: make([]int, len(panes)),
+		width:  width,
+		height: height,
+	}
+}
+
+func Run(config Config) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		writeItems(os.Stdout, config.Panes)
+		return nil
+	}
+
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return err
+	}
+	m := newModel(config.Panes, width, height)
+	command := editor.Editor(config.Editor)
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		term.Restore(fd, state)
+		fmt.Print("\x1b[?25h\x1b[2J\x1b[H")
+	}()
+	fmt.Print("\x1b[?25l")
+
+	in := bufio.NewReader(os.Stdin)
+	for {
+		if width, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+			m.width, m.height = width, height
+		}
+		fmt.Print("\x1b[2J\x1b[H" + m.view())
+
+		key, err := readKey(in)
+		if err != nil {
+			return err
+		}
+		switch m.handle(key) {
+		case actionQuit:
+			return nil
+		case actionOpen:
+			item, ok := m.selected()
+			if !ok {
+				continue
+			}
+			// The editor may be a terminal one, it gets the terminal back
+			term.Restore(fd, state)
+			fmt.Print("\x1b[?25h\x1b[2J\x1b[H")
+			program, args := editor.Command(command, item.File, item.Line, item.Column)
+			run := exec.Command(program, args...)
+			run.Stdin, run.Stdout, run.Stderr = os.Stdin, os.Stdout, os.Stderr
+			runErr := run.Run()
+			if state, err = term.MakeRaw(fd); err != nil {
+				return err
+			}
+			fmt.Print("\x1b[?25l")
+			if runErr != nil {
+				return fmt.Errorf("%s: %w", program, runErr)
+			}
+		}
+	}
+}
+
+// readKey reads one key press, naming the special keys.
+func readKey(in *bufio.Reader) (string, error) {
+	r, _, err := in.ReadRune()
+	if err != nil {
+		return "", err
+	}
+	switch r {
+	case '\r', '\n':
+		return "enter", nil
+	case '\t':
+		return "tab", nil
+	case 127, 8:
+		return "backspace", nil
+	case 3:
+		return "ctrl-c", nil
+	case 27:
+		if in.Buffered() == 0 {
+			return "esc", nil
+		}
+		next, _ := in.ReadByte()
+		if next != '[' && next != 'O' {
+			return "esc", nil
+		}
+		code, _ := in.ReadByte()
+		switch code {
+		case 'A':
+			return "up", nil
+		case 'B':
+			return "down", nil
+		case 'C':
+			return "right", nil
+		case 'D':
+			return "left", nil
+		case 'Z':
+			return "backtab", nil
+		case '5', '6':
+			in.ReadByte() // the trailing ~
+			if code == '5' {
+				return "pgup", nil
+			}
+			return "pgdown", nil
+		}
+		return "", nil
+	}
+	return string(r), nil
+}
+
+func (m *model) handle(key string) action {
+	if key == "ctrl-c" {
+		return actionQuit
+	}
+	if m.editing {
+		switch key {
+		case "enter":
+			m.editing = false
+			m.filter[m.active] = m.input
+			m.cursor[m.active], m.offset[m.active] = 0, 0
+		case "esc":
+			m.editing = false
+		case "backspace":
+			if runes := []rune(m.input); len(runes) > 0 {
+				m.input = string(runes[:len(runes)-1])
+			}
+		default:
+			if len([]rune(key)) == 1 {
+				m.input += key
+			}
+		}
+		return actionNone
+	}
+
+	if len(m.panes) == 0 {
+		if key == "q" || key == "esc" {
+			return actionQuit
+		}
+		return actionNone
+	}
+
+	count := len(m.visible())
+	switch key {
+	case "q", "esc":
+		return actionQuit
+	case "up", "k":
+		m.move(-1, count)
+	case "down", "j":
+		m.move(1, count)
+	case "pgup":
+		m.move(-m.listHeight(), count)
+	case "pgdown":
+		m.move(m.listHeight(), count)
+	case "g":
+		m.move(-count, count)
+	case "G":
+		m.move(count, count)
+	case "right", "tab", "l":
+		m.active = (m.active + 1) % len(m.panes)
+	case "left", "backtab", "h":
+		m.active = (m.active + len(m.panes) - 1) % len(m.panes)
+	case "/":
+		m.editing = true
+		m.input = m.filter[m.active]
+	case "s":
+		m.sortBy[m.active] = (m.sortBy[m.active] + 1) % len(Sorts)
+		m.cursor[m.active], m.offset[m.active] = 0, 0
+	case "enter", "o":
+		if count > 0 {
+			return actionOpen
+		}
+	default:
+		if len(key) == 1 && key[0] >= '1' && key[0] <= '9' && int(key[0]-'1') < len(m.panes) {
+			m.active = int(key[0] - '1')
+		}
+	}
+	return actionNone
+}
+
+func (m *model) move(delta, count int) {
+	if count == 0 {
+		return
+	}
+	cursor := min(max(m.cursor[m.active]+delta, 0), count-1)
+	m.cursor[m.active] = cursor
+
+	height := m.listHeight()
+	if cursor < m.offset[m.active] {
+		m.offset[m.active] = cursor
+	} else if cursor >= m.offset[m.active]+height {
+		m.offset[m.active] = cursor - height + 1
+	}
+}
+
+// listHeight is the number of rows left for items below the tabs and the
+// status line and above the detail and help lines.
+func (m *model) listHeight() int {
+	return max(m.height-4, 1)
+}
+
+// visible returns the items of the active pane matching its filter, in its
+// sort order.
+func (m *model) visible() []Item {
+	if len(m.panes) == 0 {
+		return nil
+	}
+	filter := strings.ToLower(m.filter[m.active])
+	var items []Item
+	for _, item := range m.panes[m.active].Items {
+		text := strings.ToLower(item.File + " " + item.Kind + " " + item.Title + " " + item.Detail)
+		if filter == "" || strings.Contains(text, filter) {
+			items = append(items, item)
+		}
+	}
+
+	switch Sorts[m.sortBy[m.active]] {
+	case "file":
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].File != items[j].File {
+				return items[i].File < items[j].File
+			}
+			return items[i].Line < items[j].Line
+		})
+	case "kind":
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Kind < items[j].Kind })
+	}
+	return items
+}
+
+func (m *model) selected() (Item, bool) {
+	items := m.visible()
+	if len(items) == 0 {
+		return Item{}, false
+	}
+	return items[min(m.cursor[m.active], len(items)-1)], true
+}
+
+// view renders the screen, with the CRLF line ends raw mode needs.
+func (m *model) view() string {
+	var lines []string
+
+	var tabs []string
+	for i, pane := range m.panes {
+		tab := fmt.Sprintf(" %d %s (%d) ", i+1, pane.Name, len(pane.Items))
+		if i == m.active {
+			tab = "\x1b[7m" + tab + "\x1b[0m"
+		}
+		tabs = append(tabs, tab)
+	}
+	lines = append(lines, strings.Join(tabs, " "))
+
+	if len(m.panes) == 0 {
+		lines = append(lines, "Nothing to show", "", help)
+		return strings.Join(lines, "\r\n")
+	}
+
+	items := m.visible()
+	status := fmt.Sprintf("%d of %d  sort: %s", len(items), len(m.panes[m.active].Items), Sorts[m.sortBy[m.active]])
+	if m.editing {
+		status = "filter: " + m.input + "█"
+	} else if m.filter[m.active] != "" {
+		status = fmt.Sprintf("filter: %s  %s", m.filter[m.active], status)
+	}
+	lines = append(lines, truncate(status, m.width))
+
+	height := m.listHeight()
+	offset := m.offset[m.active]
+	for row := 0; row < height; row++ {
+		i := offset + row
+		if i >= len(items) {
+			lines = append(lines, "")
+			continue
+		}
+		line := truncate(fmt.Sprintf("%s  %s", location(items[i]), items[i].Title), m.width)
+		if i == m.cursor[m.active] {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		lines = append(lines, line)
+	}
+
+	detail := ""
+	if item, ok := m.selected(); ok {
+		detail = item.Detail
+	}
+	lines = append(lines, truncate(detail, m.width), truncate(help, m.width))
+	return strings.Join(lines, "\r\n")
+}
+
+func location(item Item) string {
+	if item.Line == 0 {
+		return item.File
+	}
+	return fmt.Sprintf("%s:%d", item.File, item.Line)
+}
+
+func truncate(text string, width int) string {
+	runes := []rune(text)
+	if width <= 0 || len(runes) <= width {
+		return text
+	}
+	if width == 1 {
+		return "…"
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// writeItems lists items as text, for output that is not a terminal.
+func writeItems(w io.Writer, panes []Pane) {
+	for _, pane := range panes {
+		fmt.Fprintf(w, "## %s (%d)\n", pane.Name, len(pane.Items))
+		for _, item := range pane.Items {
+			fmt.Fprintf(w, "%s  %s\n", location(item), item.Title)
+		}
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func testPanes() []Pane {
+	return []Pane{
+		{Name: "Placeholders", Items: []Item{
+			{File: "b.c", Line: 9, Kind: "comment", Title: "TODO: free buffer"},
+			{File: "a.c", Line: 3, Kind: "debug_print", Title: "printf(\"here\")"},
+			{File: "a.c", Line: 1, Kind: "comment", Title: "FIXME: overflow"},
+		}},
+		{Name: "Hotspots", Items: []Item{{File: "big.c", Kind: "god file", Title: "score 90.0"}}},
+	}
+}
+
+func TestModelKeys(t *testing.T) {
+	m := newModel(testPanes(), 80, 20)
+
+	for _, key := range []string{"down", "down", "down"} {
+		m.handle(key)
+	}
+	if item, _ := m.selected(); item.Line != 1 {
+		t.Errorf("cursor should stop at the last item, got line %d", item.Line)
+	}
+
+	m.handle("s")
+	if got := Sorts[m.sortBy[0]]; got != "file" {
+		t.Fatalf("sort = %s, want file", got)
+	}
+	if item, _ := m.selected(); item.File != "a.c" || item.Line != 1 {
+		t.Errorf("file sort should select a.c:1 first, got %s:%d", item.File, item.Line)
+	}
+
+	for _, key := range []string{"/", "t", "o", "d", "o", "enter"} {
+		m.handle(key)
+	}
+	if items := m.visible(); len(items) != 1 || items[0].File != "b.c" {
+		t.Errorf("filter todo should keep b.c only, got %v", items)
+	}
+
+	m.handle("tab")
+	if m.active != 1 || m.filter[1] != "" {
+		t.Errorf("tab should switch to the unfiltered hotspots pane, got pane %d filter %q", m.active, m.filter[1])
+	}
+	m.handle("1")
+	if m.active != 0 {
+		t.Errorf("1 should select the first pane, got %d", m.active)
+	}
+
+	if got := m.handle("enter"); got != actionOpen {
+		t.Errorf("enter = %v, want open", got)
+	}
+	if got := m.handle("q"); got != actionQuit {
+		t.Errorf("q = %v, want quit", got)
+	}
+}
+
+func TestModelView(t *testing.T) {
+	m := newModel(testPanes(), 30, 8)
+	view := m.view()
+
+	lines := strings.Split(view, "\r\n")
+	if len(lines) != 8 {
+		t.Fatalf("view has %d lines, want the height 8", len(lines))
+	}
+	if !strings.Contains(lines[0], "1 Placeholders (3)") || !strings.Contains(lines[0], "2 Hotspots (1)") {
+		t.Errorf("tabs = %q", lines[0])
+	}
+	if !strings.Contains(lines[2], "\x1b[7mb.c:9  TODO: free buffer") {
+		t.Errorf("first item should be selected, got %q", lines[2])
+	}
+	if len([]rune(lines[7])) != 30 {
+		t.Errorf("help should be truncated to the width, got %q", lines[7])
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	in := bufio.NewReader(strings.NewReader("\x1b[Aj\r\x1b[6~\x7f"))
+	var keys []string
+	for range 5 {
+		key, err := readKey(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+	}
+	if got := strings.Join(keys, " "); got != "up j enter pgdown backspace" {
+		t.Errorf("keys = %s", got)
+	}
+}
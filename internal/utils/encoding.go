@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/vitruves/gop/internal/color"
+)
+
+const (
+	EncodingUTF8    = "utf-8"
+	EncodingUTF8BOM = "utf-8-bom"
+	EncodingUTF16LE = "utf-16le"
+	EncodingUTF16BE = "utf-16be"
+	EncodingLatin1  = "latin-1"
+	EncodingCP1252  = "windows-1252"
+	EncodingUnknown = "unknown"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// Above this share of non-ASCII bytes, invalid UTF-8 is more likely a
+// multi-byte legacy encoding (Shift-JIS, GBK, ...) than Latin-1.
+const maxLegacyHighBytes = 0.3
+
+// Windows-1252 characters for bytes 0x80-0x9F, zero where the byte is unassigned.
+var cp1252High = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
+
+// ReadSourceFile reads a file as UTF-8 text, converting UTF-16 and legacy
+// 8-bit encodings and dropping byte order marks. Files whose encoding cannot
+// be determined are decoded as Latin-1 and reported with a warning.
+func ReadSourceFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	text, encoding := DecodeText(data)
+	if encoding == EncodingUnknown {
+		now := time.Now()
+		fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%02d:%02d - WARNING: Could not determine the encoding of %s, decoded as Latin-1", now.Hour(), now.Minute(), path)))
+	}
+
+	return text, nil
+}
+
+// DecodeText converts data to a UTF-8 string and names the encoding it was
+// detected as.
+func DecodeText(data []byte) (string, string) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return string(data[len(bomUTF8):]), EncodingUTF8BOM
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return decodeUTF16(data[len(bomUTF16LE):], false), EncodingUTF16LE
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return decodeUTF16(data[len(bomUTF16BE):], true), EncodingUTF16BE
+	}
+
+	if encoding := detectUTF16(data); encoding != "" {
+		return decodeUTF16(data, encoding == EncodingUTF16BE), encoding
+	}
+
+	if utf8.Valid(data) {
+		return string(data), EncodingUTF8
+	}
+
+	return decodeLegacy(data)
+}
+
+// detectUTF16 recognises BOM-less UTF-16 from the zero bytes that mostly-ASCII
+// text leaves in every other position.
+func detectUTF16(data []byte) string {
+	sample := data
+	if len(sample) > 4096 {
+		sample = sample[:4096]
+	}
+	if len(sample) < 4 {
+		return ""
+	}
+
+	evenZeros, oddZeros := 0, 0
+	for i, b := range sample {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+
+	half := len(sample) / 2
+	switch {
+	case oddZeros > half*4/10 && evenZeros <= half/10:
+		return EncodingUTF16LE
+	case evenZeros > half*4/10 && oddZeros <= half/10:
+		return EncodingUTF16BE
+	}
+	return ""
+}
+
+func decodeUTF16(data []byte, bigEndian bool) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+		} else {
+			units = append(units, uint16(data[i+1])<<8|uint16(data[i]))
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// decodeLegacy maps single bytes to runes. Windows-1252 is preferred over
+// Latin-1 when the C1 range is used, since Latin-1 has only control codes there.
+func decodeLegacy(data []byte) (string, string) {
+	highBytes, c1Bytes := 0, 0
+	for _, b := range data {
+		if b >= 0x80 {
+			highBytes++
+		}
+		if b >= 0x80 && b <= 0x9F {
+			c1Bytes++
+		}
+	}
+
+	encoding := EncodingLatin1
+	if c1Bytes > 0 {
+		encoding = EncodingCP1252
+	}
+	if float64(highBytes)/float64(len(data)) > maxLegacyHighBytes {
+		encoding = EncodingUnknown
+	}
+
+	var sb bytes.Buffer
+	sb.Grow(len(data) + highBytes)
+	for _, b := range data {
+		r := rune(b)
+		if encoding == EncodingCP1252 && b >= 0x80 && b <= 0x9F && cp1252High[b-0x80] != 0 {
+			r = cp1252High[b-0x80]
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String(), encoding
+}
@@ -0,0 +1,26 @@
+package utils
+
+import "testing"
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		text     string
+		encoding string
+	}{
+		{"utf-8", []byte("café"), "café", EncodingUTF8},
+		{"utf-8 bom", []byte("\xEF\xBB\xBFint x;"), "int x;", EncodingUTF8BOM},
+		{"utf-16le bom", []byte("\xFF\xFEi\x00n\x00t\x00"), "int", EncodingUTF16LE},
+		{"utf-16be no bom", []byte("\x00i\x00n\x00t\x00 \x00x\x00;"), "int x;", EncodingUTF16BE},
+		{"latin-1", []byte("caf\xE9 = 1"), "café = 1", EncodingLatin1},
+		{"windows-1252", []byte("\x93quoted\x94 text"), "“quoted” text", EncodingCP1252},
+	}
+
+	for _, tt := range tests {
+		text, encoding := DecodeText(tt.data)
+		if text != tt.text || encoding != tt.encoding {
+			t.Errorf("%s: got %q (%s), want %q (%s)", tt.name, text, encoding, tt.text, tt.encoding)
+		}
+	}
+}
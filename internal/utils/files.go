@@ -0,0 +1,349 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/failures"
+)
+
+type ScanOptions struct {
+	// Root is the directory to scan, the current directory when empty.
+	// Include, exclude and depth are all relative to it.
+	Root          string
+	Include       []string
+	Exclude       []string
+	Recursive     bool
+	Depth         int
+	SkipGenerated bool
+	SkipVendor    bool
+	// MaxFileSize skips larger files, 0 disables the limit
+	MaxFileSize int64
+	// FollowSymlinks walks symlinked directories, symlinked files are always
+	// scanned
+	FollowSymlinks bool
+	// IncludeHidden walks dot files and directories, otherwise they are skipped
+	IncludeHidden bool
+	Verbose       bool
+}
+
+var defaultExcludeDirs = []string{".git", "node_modules", "__pycache__", ".pytest_cache", "target", "build", "dist"}
+
+var vendorDirs = []string{"vendor", "third_party", "third-party", "thirdparty", "3rdparty", "external"}
+
+// generatedMarkers match the header comments generators write, such as Go's
+// "// Code generated by stringer; DO NOT EDIT." or "# @generated". They only
+// match at the start of a comment line, so that prose mentioning generated
+// code is not taken for one.
+var generatedMarkers = []*regexp.Regexp{
+	regexp.MustCompile(`(?m)^[ \t]*(?://+|#+|/?\*+|--|;+|<!--)[ \t]*@generated\b`),
+	regexp.MustCompile(`(?m)^[ \t]*(?://+|#+|/?\*+|--|;+|<!--)[ \t]*DO NOT EDIT\b`),
+	regexp.MustCompile(`(?m)^[ \t]*(?://+|#+|/?\*+|--|;+|<!--)[ \t]*(?:Code generated|Generated|Auto-?generated|Automatically generated)\b.*\bDO NOT EDIT\b`),
+}
+
+var generatedSuffixes = []string{".pb.go", ".pb.h", ".pb.cc", "_pb2.py", "_pb2_grpc.py", ".generated.h", ".generated.cpp", ".g.cs"}
+
+// Only the head of a file is checked for markers, generators always put them there.
+const generatedHeaderSize = 1024
+
+// Binary and minified files are recognised from their first bytes.
+const sniffSize = 64 * 1024
+
+// Lines longer than this only occur in minified or machine-written files.
+const maxLineLength = 5000
+
+// GetFilesToProcess expands the include globs when given, otherwise walks the
+// root directory honouring the recursion, depth and exclusion options.
+// accept decides which of the remaining files are relevant to the caller.
+// The files are the total failures compares the skipped files with.
+func GetFilesToProcess(opts ScanOptions, accept func(path string) bool) ([]string, error) {
+	var files []string
+
+	startDir := "."
+	if opts.Root != "" {
+		startDir = opts.Root
+	}
+
+	if len(opts.Include) > 0 {
+		for _, path := range opts.Include {
+			if opts.Root != "" && !filepath.IsAbs(path) {
+				path = filepath.Join(opts.Root, path)
+			}
+			matches, err := filepath.Glob(path)
+			if err != nil {
+				return nil, err
+			}
+			for _, match := range matches {
+				if accept(match) && !skipUnreadable(match, opts) {
+					files = append(files, match)
+				}
+			}
+		}
+		failures.Attempt(files)
+		return files, nil
+	}
+
+	w := &walker{opts: opts, accept: accept, root: startDir, visited: make(map[string]bool)}
+	if opts.FollowSymlinks {
+		if realPath, err := filepath.EvalSymlinks(startDir); err == nil {
+			w.visited[realPath] = true
+		}
+	}
+
+	err := w.walkDir(startDir, 0)
+	failures.Attempt(w.files)
+	return w.files, err
+}
+
+// walker descends from the root one directory level at a time, so depth is
+// always counted from the root and symlinked directories can be followed.
+type walker struct {
+	opts   ScanOptions
+	accept func(path string) bool
+	root   string
+	files  []string
+	// Resolved directories already walked, guards against symlink cycles
+	visited map[string]bool
+}
+
+func (w *walker) walkDir(dir string, level int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+
+		// Patterns are matched against the path below the root
+		relPath, _ := filepath.Rel(w.root, path)
+
+		if !w.opts.IncludeHidden && isHidden(entry.Name()) {
+			continue
+		}
+
+		// Symlinked files are always scanned, symlinked directories only
+		// with FollowSymlinks
+		isDir := entry.IsDir()
+		if entry.Type()&fs.ModeSymlink != 0 {
+			info, err := os.Stat(path)
+			if err != nil {
+				logSkipped(w.opts.Verbose, path, "broken symlink")
+				continue
+			}
+			isDir = info.IsDir()
+			if isDir && !w.opts.FollowSymlinks {
+				logSkipped(w.opts.Verbose, path, "symlinked directory")
+				continue
+			}
+		}
+
+		if !isDir {
+			w.addFile(path, relPath)
+			continue
+		}
+
+		if !w.opts.Recursive || ShouldExcludeDir(relPath, w.opts) {
+			continue
+		}
+		if w.opts.Depth > 0 && level+1 > w.opts.Depth {
+			continue
+		}
+
+		if w.opts.FollowSymlinks {
+			realPath, err := filepath.EvalSymlinks(path)
+			if err != nil || w.visited[realPath] {
+				logSkipped(w.opts.Verbose, path, "already visited through a symlink")
+				continue
+			}
+			w.visited[realPath] = true
+		}
+
+		if err := w.walkDir(path, level+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *walker) addFile(path, relPath string) {
+	if MatchesAny(relPath, w.opts.Exclude) || !w.accept(path) {
+		return
+	}
+
+	if w.opts.SkipGenerated && IsGeneratedFile(path) {
+		return
+	}
+
+	if skipUnreadable(path, w.opts) {
+		return
+	}
+
+	w.files = append(w.files, path)
+}
+
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
+
+func ShouldExcludeDir(path string, opts ScanOptions) bool {
+	if MatchesAny(path, opts.Exclude) {
+		return true
+	}
+
+	if DefaultExcludedDir(path) != "" {
+		return true
+	}
+
+	if opts.SkipVendor && IsVendorDir(path) {
+		return true
+	}
+
+	return false
+}
+
+// DefaultExcludedDir returns the always-skipped directory name (.git,
+// node_modules, build, ...) found in path, or an empty string. The match is
+// on substrings, so "distributed" is skipped for containing "dist".
+func DefaultExcludedDir(path string) string {
+	for _, excludeDir := range defaultExcludeDirs {
+		if strings.Contains(path, excludeDir) {
+			return excludeDir
+		}
+	}
+	return ""
+}
+
+// IsVendorDir reports whether any component of path is a conventional
+// location for third-party code.
+func IsVendorDir(path string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		for _, vendorDir := range vendorDirs {
+			if strings.EqualFold(part, vendorDir) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsGeneratedFile reports whether path looks like generator output, either by
+// a well-known file name suffix or by a marker comment near the top of the file.
+func IsGeneratedFile(path string) bool {
+	name := filepath.Base(path)
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	head := make([]byte, generatedHeaderSize)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false
+	}
+
+	return hasGeneratedMarker(head[:n])
+}
+
+func hasGeneratedMarker(head []byte) bool {
+	for _, marker := range generatedMarkers {
+		if marker.Match(head) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipUnreadable reports whether a file is too large, binary or minified for
+// the line-based analyzers, logging the reason in verbose mode.
+func skipUnreadable(path string, opts ScanOptions) bool {
+	if opts.MaxFileSize > 0 {
+		if info, err := os.Stat(path); err == nil && info.Size() > opts.MaxFileSize {
+			logSkipped(opts.Verbose, path, fmt.Sprintf("larger than %s", FormatSize(opts.MaxFileSize)))
+			return true
+		}
+	}
+
+	if reason := SniffContent(path); reason != "" {
+		logSkipped(opts.Verbose, path, reason)
+		return true
+	}
+
+	return false
+}
+
+// SniffContent returns why a file is not text worth analyzing, "binary" or
+// "minified", or an empty string for ordinary source files.
+func SniffContent(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	head := make([]byte, sniffSize)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return ""
+	}
+	head = head[:n]
+
+	// UTF-16 text is full of zero bytes but is decoded by ReadSourceFile
+	if bytes.HasPrefix(head, bomUTF16LE) || bytes.HasPrefix(head, bomUTF16BE) || detectUTF16(head) != "" {
+		return ""
+	}
+
+	if bytes.IndexByte(head, 0) != -1 {
+		return "binary"
+	}
+
+	for len(head) > 0 {
+		lineEnd := bytes.IndexByte(head, '\n')
+		if lineEnd == -1 {
+			lineEnd = len(head)
+		}
+		if lineEnd > maxLineLength {
+			return "minified"
+		}
+		head = head[min(lineEnd+1, len(head)):]
+	}
+
+	return ""
+}
+
+func logSkipped(verbose bool, path, reason string) {
+	if verbose {
+		now := time.Now()
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%02d:%02d - INFO: Skipping %s (%s)", now.Hour(), now.Minute(), path, reason)))
+	}
+}
+
+// MatchesAny reports whether path matches one of the glob patterns. Both are
+// compared with forward slashes, so patterns written as "src/*.c" also apply
+// to Windows paths.
+func MatchesAny(path string, patterns []string) bool {
+	path = filepath.ToSlash(path)
+	for _, pattern := range patterns {
+		if matched, _ := pathpkg.Match(filepath.ToSlash(pattern), path); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSniffContent(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"source.py":  "def main():\n    pass\n",
+		"data.py":    "header\x00\x01\x02",
+		"bundle.min": "var a=1;" + strings.Repeat("b=2;", maxLineLength),
+	}
+	expected := map[string]string{
+		"source.py":  "",
+		"data.py":    "binary",
+		"bundle.min": "minified",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		if reason := SniffContent(path); reason != expected[name] {
+			t.Errorf("SniffContent(%s) = %q, expected %q", name, reason, expected[name])
+		}
+	}
+}
+
+func TestGetFilesToProcessWalk(t *testing.T) {
+	root := t.TempDir()
+
+	for _, name := range []string{"top.py", "a/one.py", "a/b/two.py", ".hidden/secret.py"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("x = 1\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	if err := os.Symlink("..", filepath.Join(root, "a", "loop")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(filepath.Join("b", "two.py"), filepath.Join(root, "a", "linked.py")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("missing.py", filepath.Join(root, "broken.py")); err != nil {
+		t.Fatal(err)
+	}
+
+	walk := func(opts ScanOptions) []string {
+		opts.Root = root
+		files, err := GetFilesToProcess(opts, func(path string) bool { return true })
+		if err != nil {
+			t.Fatalf("GetFilesToProcess failed: %v", err)
+		}
+		var relFiles []string
+		for _, file := range files {
+			relPath, _ := filepath.Rel(root, file)
+			relFiles = append(relFiles, filepath.ToSlash(relPath))
+		}
+		return relFiles
+	}
+
+	if files := walk(ScanOptions{Recursive: true}); strings.Join(files, ",") != "a/b/two.py,a/linked.py,a/one.py,top.py" {
+		t.Errorf("Expected hidden files, broken symlinks and symlinked directories to be skipped, got %v", files)
+	}
+
+	if files := walk(ScanOptions{Recursive: true, Depth: 1}); strings.Join(files, ",") != "a/linked.py,a/one.py,top.py" {
+		t.Errorf("Expected depth 1 to stop below a/, got %v", files)
+	}
+
+	if files := walk(ScanOptions{Recursive: true, IncludeHidden: true}); len(files) != 5 {
+		t.Errorf("Expected hidden directory to be walked, got %v", files)
+	}
+
+	if files := walk(ScanOptions{Recursive: true, FollowSymlinks: true}); len(files) != 4 {
+		t.Errorf("Expected the symlink cycle to be walked once, got %v", files)
+	}
+}
+
+func TestIsGeneratedFile(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name      string
+		content   string
+		generated bool
+	}{
+		{"stringer.go", "// Code generated by \"stringer -type=Kind\"; DO NOT EDIT.\n\npackage kind\n", true},
+		{"schema.rs", "// @generated by diesel\n\nuse diesel::table;\n", true},
+		{"config.h", "/* DO NOT EDIT: written by configure */\n#define HAVE_UNISTD_H 1\n", true},
+		{"parser.py", "#!/usr/bin/env python\n# Generated by pegen from python.gram, DO NOT EDIT\n", true},
+		{"opcodes.h", "/*\n * Auto-generated by Tools/build/generate_opcodes.py. DO NOT EDIT.\n */\n", true},
+		{"lexer.sql", "-- @generated\nSELECT 1;\n", true},
+		{"message.pb.h", "#pragma once\n", true},
+		// Hand-written files that talk about generated code
+		{"ast.py", "\"\"\"\nThe `ast` module helps Python applications to process trees of the Python\nabstract syntax grammar. The abstract syntax itself might change with\neach Python release; this module helps to find out programmatically what\nthe current grammar looks like. The node classes are auto-generated\nfrom the ASDL description.\n\"\"\"\n", false},
+		{"ctrl_c.rs", "//! Asynchronous signal handling for Tokio.\n//!\n//! Note that the future is autogenerated by the runtime and completes\n//! once the signal was generated by the OS.\n", false},
+		{"Automaton.h", "//===- Automaton.h - Support for driving TableGen-produced DFAs ----------===//\n//\n// This file implements a class that drives an automaton generated by\n// TableGen's -gen-automata backend.\n", false},
+		{"notes.c", "int x = 1; // do not edit this value by hand, see docs\n", false},
+		{"main.c", "int main(void) { return 0; }\n", false},
+	}
+
+	for _, test := range tests {
+		path := filepath.Join(tempDir, test.name)
+		if err := os.WriteFile(path, []byte(test.content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		if generated := IsGeneratedFile(path); generated != test.generated {
+			t.Errorf("IsGeneratedFile(%s) = %v, expected %v", test.name, generated, test.generated)
+		}
+	}
+}
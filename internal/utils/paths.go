@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PathResolver maps the file paths found in tool logs to files under a
+// source root. Paths from another machine or build directory match on their
+// trailing components. It is not safe for concurrent use.
+type PathResolver struct {
+	root    string
+	absRoot string
+	cache   map[string]string
+}
+
+func NewPathResolver(root string) *PathResolver {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		absRoot = root
+	}
+	return &PathResolver{root: root, absRoot: absRoot, cache: make(map[string]string)}
+}
+
+// Path returns the path of a file Resolve resolved, under the root as given.
+func (r *PathResolver) Path(resolved string) string {
+	return filepath.Join(r.root, resolved)
+}
+
+// Resolve returns the path relative to the root, or false when the file is
+// not part of it. Windows separators are accepted on every platform.
+func (r *PathResolver) Resolve(path string) (string, bool) {
+	if path == "" {
+		return "", false
+	}
+	if resolved, ok := r.cache[path]; ok {
+		return resolved, resolved != ""
+	}
+
+	resolved := ""
+	clean := filepath.Clean(path)
+	if filepath.IsAbs(clean) {
+		if rel, err := filepath.Rel(r.absRoot, clean); err == nil && !strings.HasPrefix(rel, "..") && fileExists(clean) {
+			resolved = rel
+		}
+	} else if !strings.HasPrefix(clean, "..") && fileExists(filepath.Join(r.root, clean)) {
+		resolved = clean
+	}
+
+	if resolved == "" {
+		parts := strings.Split(strings.ReplaceAll(filepath.ToSlash(clean), `\`, "/"), "/")
+		for i := 1; i < len(parts); i++ {
+			candidate := filepath.FromSlash(strings.Join(parts[i:], "/"))
+			if fileExists(filepath.Join(r.root, candidate)) {
+				resolved = candidate
+				break
+			}
+		}
+	}
+
+	r.cache[path] = resolved
+	return resolved, resolved != ""
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// Path styles for the file paths written in reports.
+const (
+	PathStyleRelative = "relative"
+	PathStyleAbsolute = "absolute"
+	PathStyleFromRoot = "from-root"
+)
+
+var PathStyles = []string{PathStyleRelative, PathStyleAbsolute, PathStyleFromRoot}
+
+// The style is set once from the global flag, before any command runs.
+// Until then paths are written as they were scanned.
+var (
+	pathStyle string
+	pathRoot  string
+	pathWd    string
+)
+
+// SetPathStyle selects how DisplayPath writes paths. root is the directory
+// from-root paths and ProjectPath are relative to, usually the repository
+// root.
+func SetPathStyle(style, root string) error {
+	switch style {
+	case PathStyleRelative, PathStyleAbsolute, PathStyleFromRoot:
+	default:
+		return fmt.Errorf("unsupported path style: %s (expected %s)", style, strings.Join(PathStyles, ", "))
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if root == "" {
+		root = wd
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	pathStyle, pathRoot, pathWd = style, absRoot, wd
+	return nil
+}
+
+// DisplayPath writes a scanned path in the selected style: relative to the
+// working directory, absolute, or relative to the root, whatever form it was
+// given in on the command line. Paths outside the root stay absolute, and
+// names such as "stdin" that are not paths are returned unchanged.
+func DisplayPath(path string) string {
+	if pathStyle == "" || path == "" || path == "-" || path == "stdin" {
+		return path
+	}
+	abs := filepath.Clean(path)
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(pathWd, abs)
+	}
+
+	switch pathStyle {
+	case PathStyleAbsolute:
+		return abs
+	case PathStyleFromRoot:
+		if rel, err := filepath.Rel(pathRoot, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return rel
+		}
+		return abs
+	default:
+		if rel, err := filepath.Rel(pathWd, abs); err == nil {
+			return rel
+		}
+		return abs
+	}
+}
+
+// ProjectPath writes a scanned path relative to the root with forward
+// slashes, so that it names a file the same way whatever the path style and
+// working directory. Files outside the root keep their absolute path. Until
+// SetPathStyle is called the path is only cleaned.
+func ProjectPath(path string) string {
+	if path == "" || path == "-" || path == "stdin" {
+		return path
+	}
+	clean := filepath.Clean(path)
+	if pathStyle == "" {
+		return filepath.ToSlash(clean)
+	}
+	if !filepath.IsAbs(clean) {
+		clean = filepath.Join(pathWd, clean)
+	}
+	if rel, err := filepath.Rel(pathRoot, clean); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(clean)
+}
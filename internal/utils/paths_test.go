@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDisplayPath(t *testing.T) {
+	defer func() { pathStyle = "" }()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Dir(wd)
+	relative := filepath.Join("src", "a.c")
+	absolute := filepath.Join(wd, "src", "a.c")
+	fromRoot := filepath.Join(filepath.Base(wd), "src", "a.c")
+
+	tests := []struct {
+		style string
+		path  string
+		want  string
+	}{
+		{PathStyleRelative, relative, relative},
+		{PathStyleRelative, absolute, relative},
+		{PathStyleRelative, "./" + relative, relative},
+		{PathStyleAbsolute, relative, absolute},
+		{PathStyleAbsolute, absolute, absolute},
+		{PathStyleFromRoot, relative, fromRoot},
+		{PathStyleFromRoot, absolute, fromRoot},
+		{PathStyleFromRoot, "stdin", "stdin"},
+	}
+	for _, tt := range tests {
+		if err := SetPathStyle(tt.style, root); err != nil {
+			t.Fatal(err)
+		}
+		if got := DisplayPath(tt.path); got != tt.want {
+			t.Errorf("%s: DisplayPath(%q) = %q, want %q", tt.style, tt.path, got, tt.want)
+		}
+	}
+
+	if err := SetPathStyle("basename", ""); err == nil {
+		t.Error("expected an error for an unknown path style")
+	}
+}
+
+func TestProjectPath(t *testing.T) {
+	defer func() { pathStyle = "" }()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Dir(wd)
+	want := filepath.Base(wd) + "/src/a.c"
+
+	for _, style := range PathStyles {
+		if err := SetPathStyle(style, root); err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range []string{"src/a.c", "./src/a.c", filepath.Join(wd, "src", "a.c")} {
+			if got := ProjectPath(path); got != want {
+				t.Errorf("%s: ProjectPath(%q) = %q, want %q", style, path, got, want)
+			}
+		}
+	}
+
+	outside := filepath.Join(filepath.Dir(root), "other", "b.c")
+	if got := ProjectPath(outside); got != filepath.ToSlash(outside) {
+		t.Errorf("Expected a file outside the root to stay absolute, got %q", got)
+	}
+}
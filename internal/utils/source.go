@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+)
+
+// BlankCommentsAndLiterals replaces comments and string or character
+// literals with spaces, keeping newlines so offsets still map to lines.
+func BlankCommentsAndLiterals(content string) string {
+	code := []byte(content)
+
+	for i := 0; i < len(code); i++ {
+		switch {
+		case code[i] == '/' && i+1 < len(code) && code[i+1] == '/':
+			for ; i < len(code) && code[i] != '\n'; i++ {
+				code[i] = ' '
+			}
+		case code[i] == '/' && i+1 < len(code) && code[i+1] == '*':
+			start := i
+			for i += 2; i < len(code) && !(code[i-1] == '*' && code[i] == '/'); i++ {
+			}
+			blank(code, start, min(i+1, len(code)))
+		case code[i] == '"' || code[i] == '\'':
+			quote := code[i]
+			start := i
+			for i++; i < len(code) && code[i] != quote && code[i] != '\n'; i++ {
+				if code[i] == '\\' {
+					i++
+				}
+			}
+			blank(code, start, min(i+1, len(code)))
+		}
+	}
+
+	return string(code)
+}
+
+func blank(code []byte, start, end int) {
+	for i := start; i < end; i++ {
+		if code[i] != '\n' {
+			code[i] = ' '
+		}
+	}
+}
+
+// SplitLines splits content into lines, dropping the carriage return of
+// CRLF line endings so Windows checkouts match the same patterns.
+func SplitLines(content string) []string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// LineIndex holds the offset at which each line of a text starts.
+type LineIndex []int
+
+func NewLineIndex(content string) LineIndex {
+	index := LineIndex{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			index = append(index, i+1)
+		}
+	}
+	return index
+}
+
+// Position converts a byte offset into a 1-based line and column.
+func (index LineIndex) Position(offset int) (int, int) {
+	line := sort.Search(len(index), func(i int) bool { return index[i] > offset }) - 1
+	return line + 1, offset - index[line] + 1
+}
+
+// MatchingBracket returns the offset of the bracket closing the one at open,
+// or -1 when it is unbalanced.
+func MatchingBracket(code string, open int, opener, closer byte) int {
+	depth := 0
+	for i := open; i < len(code); i++ {
+		switch code[i] {
+		case opener:
+			depth++
+		case closer:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// DefaultTestPatterns recognize the test file and directory conventions of
+// the supported languages.
+var DefaultTestPatterns = []string{
+	"*_test.*", "test_*.*", "*_tests.*", "*.test.*", "*.spec.*", "*Test.*", "*Tests.*",
+	"test/**", "tests/**", "testing/**", "__tests__/**", "spec/**",
+}
+
+// IsTestFile reports whether path matches one of the test patterns.
+func IsTestFile(path string, patterns []string) bool {
+	return MatchPath(path, patterns)
+}
+
+// MatchPath reports whether path matches one of the patterns. Patterns
+// without a slash match the file name, others match any trailing part of the
+// path where "**" stands for any number of directories, so "tests/**"
+// matches every file below a tests directory. A leading slash anchors the
+// pattern at the start of the path.
+func MatchPath(path string, patterns []string) bool {
+	path = filepath.ToSlash(filepath.Clean(path))
+	segments := strings.Split(strings.TrimPrefix(path, "./"), "/")
+
+	for _, pattern := range patterns {
+		pattern = filepath.ToSlash(pattern)
+
+		if !strings.Contains(pattern, "/") {
+			if matched, _ := filepath.Match(pattern, segments[len(segments)-1]); matched {
+				return true
+			}
+			continue
+		}
+
+		patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+		if strings.HasPrefix(pattern, "/") {
+			if matchSegments(patternSegments, segments) {
+				return true
+			}
+			continue
+		}
+		for start := range segments {
+			if matchSegments(patternSegments, segments[start:]) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func matchSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+
+	if pattern[0] == "**" {
+		for skip := 0; skip <= len(path); skip++ {
+			if matchSegments(pattern[1:], path[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(path) == 0 {
+		return false
+	}
+	if matched, _ := filepath.Match(pattern[0], path[0]); !matched {
+		return false
+	}
+	return matchSegments(pattern[1:], path[1:])
+}
@@ -0,0 +1,33 @@
+package utils
+
+import "testing"
+
+func TestIsTestFile(t *testing.T) {
+	cases := map[string]bool{
+		"src/parser.cpp":                false,
+		"src/parser_test.cpp":           true,
+		"test_parser.py":                true,
+		"tests/fixtures/big_input.c":    true,
+		"./lib/tests/helpers.h":         true,
+		"web/__tests__/app.js":          true,
+		"src/contest/score.go":          false,
+		"src/latest.rs":                 false,
+		"internal/cmd/stats_test.go":    true,
+		"benchmarks/ParserTest.java":    true,
+		"src/protest/tests_helper.c":    false,
+		"integration/testdata/input.go": false,
+	}
+
+	for path, want := range cases {
+		if got := IsTestFile(path, DefaultTestPatterns); got != want {
+			t.Errorf("IsTestFile(%q) = %v, expected %v", path, got, want)
+		}
+	}
+
+	if !IsTestFile("qa/suite/run.cpp", []string{"qa/**/*.cpp"}) {
+		t.Error("Expected qa/**/*.cpp to match a file two levels below qa")
+	}
+	if IsTestFile("src/qa/run.cpp", []string{"/qa/**"}) {
+		t.Error("Expected a leading slash to anchor the pattern at the root")
+	}
+}
@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var sizeUnits = []struct {
+	suffix string
+	value  int64
+}{
+	{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+	{"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+}
+
+// ParseSize reads sizes such as "512", "200KB", "5M" or "1.5GB".
+func ParseSize(input string) (int64, error) {
+	size := strings.ToUpper(strings.TrimSpace(input))
+
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(size, unit.suffix) {
+			multiplier = unit.value
+			size = strings.TrimSpace(strings.TrimSuffix(size, unit.suffix))
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(size, 64)
+	if err != nil || value < 0 || math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, fmt.Errorf("invalid size: %q", input)
+	}
+
+	return int64(math.Round(value * float64(multiplier))), nil
+}
+
+// FormatSize prints a size exactly, in the largest unit dividing it, so it
+// reads back with ParseSize.
+func FormatSize(size int64) string {
+	switch {
+	case size >= 1<<30 && size%(1<<30) == 0:
+		return fmt.Sprintf("%dGB", size>>30)
+	case size >= 1<<20 && size%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", size>>20)
+	case size >= 1<<10 && size%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", size>>10)
+	default:
+		return fmt.Sprintf("%dB", size)
+	}
+}
+
+// HumanSize prints a size rounded for reading, e.g. "1.4 MB".
+func HumanSize(size int64) string {
+	for _, unit := range sizeUnits[:3] {
+		if size >= unit.value {
+			return fmt.Sprintf("%.1f %s", float64(size)/float64(unit.value), unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%d B", size)
+}
+
+var durationUnits = []struct {
+	suffix string
+	value  time.Duration
+}{
+	{"y", 365 * 24 * time.Hour}, {"w", 7 * 24 * time.Hour}, {"d", 24 * time.Hour},
+}
+
+// ParseDuration extends time.ParseDuration with days, weeks and years, as in
+// "90d", "2w" or "1y", the units ages are usually given in.
+func ParseDuration(input string) (time.Duration, error) {
+	text := strings.ToLower(strings.TrimSpace(input))
+
+	for _, unit := range durationUnits {
+		if number, ok := strings.CutSuffix(text, unit.suffix); ok {
+			value, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+			if err != nil || value < 0 {
+				return 0, fmt.Errorf("invalid duration: %q", input)
+			}
+			return time.Duration(value * float64(unit.value)), nil
+		}
+	}
+
+	duration, err := time.ParseDuration(text)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid duration: %q (e.g. 90d, 2w, 1y, 36h)", input)
+	}
+	return duration, nil
+}
+
+// HumanDuration prints the two most significant units of a duration, e.g.
+// "1y 20d", "3h 5m" or "850ms".
+func HumanDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+
+	units := []struct {
+		suffix string
+		value  time.Duration
+	}{
+		{"y", 365 * 24 * time.Hour}, {"d", 24 * time.Hour}, {"h", time.Hour}, {"m", time.Minute}, {"s", time.Second},
+	}
+
+	var parts []string
+	for _, unit := range units {
+		if d >= unit.value || len(parts) > 0 {
+			n := d / unit.value
+			d -= n * unit.value
+			if n > 0 {
+				parts = append(parts, fmt.Sprintf("%d%s", n, unit.suffix))
+			}
+			if len(parts) == 2 || (len(parts) > 0 && n == 0) {
+				break
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// FormatCount groups the digits of large counts by thousands, e.g. "12,345".
+func FormatCount(n int) string {
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var sb strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(digit)
+	}
+	return sign + sb.String()
+}
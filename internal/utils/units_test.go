@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	cases := map[string]int64{
+		"0":     0,
+		"512":   512,
+		"200KB": 200 << 10,
+		"5mb":   5 << 20,
+		"1 GB":  1 << 30,
+		"2M":    2 << 20,
+		"1.5KB": 1536,
+	}
+
+	for input, want := range cases {
+		got, err := ParseSize(input)
+		if err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v, expected %d", input, got, err, want)
+		}
+	}
+
+	if _, err := ParseSize("five"); err == nil {
+		t.Error("Expected an error for a size without digits")
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	day := 24 * time.Hour
+	cases := map[string]time.Duration{
+		"90d":   90 * day,
+		"2w":    14 * day,
+		"1y":    365 * day,
+		"36h":   36 * time.Hour,
+		"1.5d":  36 * time.Hour,
+		"1h30m": 90 * time.Minute,
+	}
+
+	for input, want := range cases {
+		got, err := ParseDuration(input)
+		if err != nil || got != want {
+			t.Errorf("ParseDuration(%q) = %s, %v, expected %s", input, got, err, want)
+		}
+	}
+
+	if _, err := ParseDuration("soon"); err == nil {
+		t.Error("Expected an error for a duration without a number")
+	}
+}
+
+func TestHumanFormatting(t *testing.T) {
+	durations := map[time.Duration]string{
+		850 * time.Millisecond:           "850ms",
+		42 * time.Second:                 "42s",
+		3*time.Hour + 5*time.Minute + 9:  "3h 5m",
+		2 * time.Hour:                    "2h",
+		400 * 24 * time.Hour:             "1y 35d",
+		26*time.Hour + 10*time.Second:    "1d 2h",
+		time.Minute + 30*time.Second + 1: "1m 30s",
+	}
+	for input, want := range durations {
+		if got := HumanDuration(input); got != want {
+			t.Errorf("HumanDuration(%s) = %q, expected %q", input, got, want)
+		}
+	}
+
+	sizes := map[int64]string{
+		512:       "512 B",
+		1536:      "1.5 KB",
+		5 << 20:   "5.0 MB",
+		3<<30 + 1: "3.0 GB",
+	}
+	for input, want := range sizes {
+		if got := HumanSize(input); got != want {
+			t.Errorf("HumanSize(%d) = %q, expected %q", input, got, want)
+		}
+	}
+
+	counts := map[int]string{0: "0", 999: "999", 1000: "1,000", 1234567: "1,234,567", -45678: "-45,678"}
+	for input, want := range counts {
+		if got := FormatCount(input); got != want {
+			t.Errorf("FormatCount(%d) = %q, expected %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package warnings
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vitruves/gop/internal/color"
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/manifest"
+	"github.com/vitruves/gop/internal/utils"
+)
+
+type Config struct {
+	// Inputs are the build logs to read, standard input when empty
+	Inputs   []string
+	Compiler string
+	// Root is the source tree diagnostics are made relative to
+	Root string
+	// External keeps diagnostics in files outside the root, such as
+	// system and third-party headers
+	External    bool
+	HistoryFile string
+	OutputFile  string
+	Format      string
+	Verbose     bool
+	Manifest    *manifest.Manifest
+}
+
+// Compilers lists the values Config.Compiler accepts; gcc and clang share
+// their output format.
+var Compilers = []string{"gcc", "clang", "msvc"}
+
+// HistoryEntry is the count of one run, appended to the history file.
+type HistoryEntry struct {
+	Date     string         `json:"date"`
+	Total    int            `json:"total"`
+	Errors   int            `json:"errors"`
+	Warnings int            `json:"warnings"`
+	Rules    map[string]int `json:"rules"`
+}
+
+var (
+	// file:line[:col]: warning: message [-Wflag]
+	gccRegex = regexp.MustCompile(`^\s*(.+?):(\d+):(?:(\d+):)?\s+(warning|error|fatal error):\s+(.*?)(?:\s+\[([^\]\s]+)\])?\s*$`)
+	// file(line[,col]): warning C4996: message [project.vcxproj]
+	msvcRegex = regexp.MustCompile(`^\s*(?:\d+>)?(.+?)\((\d+)(?:,(\d+))?\)\s*:\s+(warning|error|fatal error)\s+([A-Z]+\d+)\s*:\s+(.*?)(?:\s+\[[^\]]+\.(?:vcxproj|csproj)\])?\s*$`)
+	// [-Wunused-variable], [-Werror=format=], [-Werror,-Wunused-variable]
+	flagRegex = regexp.MustCompile(`^(?:-Werror,)?-W(?:error=)?([\w+-]+?)(?:=\d*)?(?:,-Werror(?:=[\w=-]+)?)?$`)
+)
+
+func Run(config Config) error {
+	logInfo(config.Verbose, "Starting compiler warning ingestion")
+
+	if config.Format == "" {
+		config.Format = "text"
+	}
+	if config.Format != "text" && !diagnostics.IsFormat(config.Format) {
+		return fmt.Errorf("unsupported format: %s (expected text, %s)", config.Format, strings.Join(diagnostics.Formats, ", "))
+	}
+	if config.Compiler != "" && !isCompiler(config.Compiler) {
+		return fmt.Errorf("unsupported compiler: %s (expected %s)", config.Compiler, strings.Join(Compilers, ", "))
+	}
+	if config.Root == "" {
+		config.Root = "."
+	}
+
+	var findings []diagnostics.Diagnostic
+	if len(config.Inputs) == 0 {
+		parsed, err := Parse(os.Stdin, config.Compiler)
+		if err != nil {
+			return err
+		}
+		findings = parsed
+	}
+	for _, input := range config.Inputs {
+		file, err := os.Open(input)
+		if err != nil {
+			return err
+		}
+		parsed, err := Parse(file, config.Compiler)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", input, err)
+		}
+		logInfo(config.Verbose, fmt.Sprintf("Found %d diagnostics in %s", len(parsed), input))
+		findings = append(findings, parsed...)
+	}
+	config.Manifest.AddFiles(config.Inputs)
+
+	findings = diagnostics.Filtered(Normalize(findings, utils.NewPathResolver(config.Root), config.External))
+
+	if err := writeOutput(findings, config); err != nil {
+		return err
+	}
+
+	if config.HistoryFile != "" {
+		previous, err := appendHistory(config.HistoryFile, Count(findings, time.Now()))
+		if err != nil {
+			logError(fmt.Sprintf("Failed to update history: %v", err))
+			return err
+		}
+		if previous != nil {
+			trend := fmt.Sprintf("%d compiler diagnostics, %+d since %s", len(findings), len(findings)-previous.Total, previous.Date)
+			if len(findings) > previous.Total {
+				logWarning(trend)
+			} else {
+				logSuccess(trend)
+			}
+		}
+	}
+	return nil
+}
+
+func isCompiler(name string) bool {
+	for _, compiler := range Compilers {
+		if compiler == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse reads the warnings and errors of a build log. Notes are left out,
+// they only add context to the diagnostic before them. Without a compiler,
+// both the gcc/clang and the msvc formats are recognized.
+func Parse(r io.Reader, compiler string) ([]diagnostics.Diagnostic, error) {
+	var findings []diagnostics.Diagnostic
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if compiler != "msvc" {
+			if match := gccRegex.FindStringSubmatch(line); match != nil {
+				findings = append(findings, newDiagnostic(match[1], match[2], match[3], match[4], gccRule(match[4], match[6]), match[5]))
+				continue
+			}
+		}
+		if compiler == "" || compiler == "msvc" {
+			if match := msvcRegex.FindStringSubmatch(line); match != nil {
+				findings = append(findings, newDiagnostic(match[1], match[2], match[3], match[4], "compiler/"+match[5], match[6]))
+			}
+		}
+	}
+
+	return findings, scanner.Err()
+}
+
+func newDiagnostic(file, line, column, severity, rule, message string) diagnostics.Diagnostic {
+	finding := diagnostics.Diagnostic{
+		File:     strings.TrimSpace(file),
+		Severity: diagnostics.SeverityWarning,
+		Rule:     rule,
+		Message:  message,
+	}
+	finding.Line, _ = strconv.Atoi(line)
+	finding.Column, _ = strconv.Atoi(column)
+	if severity != "warning" {
+		finding.Severity = diagnostics.SeverityError
+	}
+	return finding
+}
+
+// gccRule names a diagnostic after its warning flag, "-Wunused-variable" or
+// "-Werror=unused-variable" both giving "compiler/unused-variable".
+// Diagnostics without a flag are "compiler/warning" or "compiler/error".
+func gccRule(severity, flag string) string {
+	if match := flagRegex.FindStringSubmatch(flag); match != nil {
+		return "compiler/" + match[1]
+	}
+	if severity == "warning" {
+		return "compiler/warning"
+	}
+	return "compiler/error"
+}
+
+// Normalize makes paths relative to the source root and drops duplicates,
+// such as a header warning repeated for every file including it. Findings
+// outside the root are dropped unless external is set.
+func Normalize(findings []diagnostics.Diagnostic, resolver *utils.PathResolver, external bool) []diagnostics.Diagnostic {
+	seen := make(map[string]bool)
+	var result []diagnostics.Diagnostic
+
+	for _, finding := range findings {
+		if resolved, ok := resolver.Resolve(finding.File); ok {
+			finding.File = resolved
+			finding.ScannedFile = resolver.Path(resolved)
+		} else if !external {
+			continue
+		}
+
+		key := fmt.Sprintf("%s:%d:%d:%s:%s", finding.File, finding.Line, finding.Column, finding.Rule, finding.Message)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, finding)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].File != result[j].File {
+			return result[i].File < result[j].File
+		}
+		if result[i].Line != result[j].Line {
+			return result[i].Line < result[j].Line
+		}
+		return result[i].Column < result[j].Column
+	})
+	return result
+}
+
+// Count summarizes findings for the history file.
+func Count(findings []diagnostics.Diagnostic, date time.Time) HistoryEntry {
+	entry := HistoryEntry{Date: date.Format(time.RFC3339), Total: len(findings), Rules: make(map[string]int)}
+	for _, finding := range findings {
+		if finding.Severity == diagnostics.SeverityError {
+			entry.Errors++
+		} else {
+			entry.Warnings++
+		}
+		entry.Rules[finding.Rule]++
+	}
+	return entry
+}
+
+// appendHistory adds entry to the JSON history file, creating it when
+// missing, and returns the entry before it.
+func appendHistory(path string, entry HistoryEntry) (*HistoryEntry, error) {
+	var history []HistoryEntry
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if len(content) > 0 {
+		if err := json.Unmarshal(content, &history); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	var previous *HistoryEntry
+	if len(history) > 0 {
+		last := history[len(history)-1]
+		previous = &last
+	}
+
+	history = append(history, entry)
+	output, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return previous, os.WriteFile(path, append(output, '\n'), 0644)
+}
+
+func writeOutput(findings []diagnostics.Diagnostic, config Config) error {
+	findings = displayPaths(findings, config.Root)
+	var sb strings.Builder
+
+	if config.Format == "text" {
+		if len(findings) == 0 {
+			logSuccess("No compiler warnings found")
+			return nil
+		}
+
+		for _, finding := range findings {
+			sb.WriteString(fmt.Sprintf("%s:%d:%d: %s: %s [%s]\n", finding.File, finding.Line, finding.Column, finding.Severity, finding.Message, finding.Rule))
+		}
+	} else if err := diagnostics.WriteReport(&sb, config.Format, findings); err != nil {
+		return err
+	}
+	output := []byte(sb.String())
+
+	if config.OutputFile != "" {
+		config.Manifest.AddResult(config.OutputFile, output)
+		if err := os.WriteFile(config.OutputFile, output, 0644); err != nil {
+			logError(fmt.Sprintf("Failed to write report: %v", err))
+			return err
+		}
+	} else {
+		config.Manifest.AddResult("stdout", output)
+		fmt.Print(string(output))
+	}
+
+	if config.Format == "text" {
+		logWarning(fmt.Sprintf("Found %d compiler diagnostics", len(findings)))
+	}
+	return nil
+}
+
+// displayPaths writes the paths of project files, relative to root after
+// Normalize, in the selected path style. External paths are left as the
+// compiler wrote them.
+func displayPaths(findings []diagnostics.Diagnostic, root string) []diagnostics.Diagnostic {
+	resolver := utils.NewPathResolver(root)
+	displayed := make([]diagnostics.Diagnostic, len(findings))
+	for i, finding := range findings {
+		if resolved, ok := resolver.Resolve(finding.File); ok {
+			finding.File = utils.DisplayPath(filepath.Join(root, resolved))
+		}
+		displayed[i] = finding
+	}
+	return displayed
+}
+
+func logInfo(verbose bool, msg string) {
+	if verbose {
+		fmt.Println(color.Wrap(color.Blue, fmt.Sprintf("%s - INFO: %s", getCurrentTime(), msg)))
+	}
+}
+
+func logSuccess(msg string) {
+	fmt.Println(color.Wrap(color.Green, fmt.Sprintf("%s - SUCCESS: %s", getCurrentTime(), msg)))
+}
+
+func logWarning(msg string) {
+	fmt.Println(color.Wrap(color.Yellow, fmt.Sprintf("%s - WARNING: %s", getCurrentTime(), msg)))
+}
+
+func logError(msg string) {
+	fmt.Println(color.Wrap(color.Red, fmt.Sprintf("%s - ERROR: %s", getCurrentTime(), msg)))
+}
+
+func getCurrentTime() string {
+	now := time.Now()
+	return fmt.Sprintf("%02d:%02d", now.Hour(), now.Minute())
+}
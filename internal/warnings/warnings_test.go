@@ -0,0 +1,99 @@
+package warnings
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vitruves/gop/internal/diagnostics"
+	"github.com/vitruves/gop/internal/utils"
+)
+
+func TestParse(t *testing.T) {
+	log := `[3/12] Building C object src/CMakeFiles/app.dir/parse.c.o
+/ci/work/src/parse.c:42:13: warning: unused variable 'n' [-Wunused-variable]
+   42 |     int n = 0;
+      |         ^
+/ci/work/src/parse.c: In function 'parse':
+/ci/work/src/parse.c:50:5: error: implicit declaration of function 'foo' [-Werror=implicit-function-declaration]
+/ci/work/src/util.h:7:1: warning: format '%d' expects argument of type 'int' [-Wformat=]
+src/main.c:10:3: warning: unused variable 'x' [-Werror,-Wunused-variable]
+src/main.c:12: warning: no newline at end of file
+/ci/work/src/parse.c:44:2: note: declared here
+C:\ci\work\src\win.c(12,5): warning C4996: 'strcpy': This function may be unsafe. [C:\ci\work\app.vcxproj]
+2>src\win.c(20): error C2065: 'y': undeclared identifier
+ld: warning: directory not found for option '-L/opt/lib'
+`
+	findings, err := Parse(strings.NewReader(log), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		file string
+		line int
+		rule string
+		sev  diagnostics.Severity
+	}{
+		{"/ci/work/src/parse.c", 42, "compiler/unused-variable", diagnostics.SeverityWarning},
+		{"/ci/work/src/parse.c", 50, "compiler/implicit-function-declaration", diagnostics.SeverityError},
+		{"/ci/work/src/util.h", 7, "compiler/format", diagnostics.SeverityWarning},
+		{"src/main.c", 10, "compiler/unused-variable", diagnostics.SeverityWarning},
+		{"src/main.c", 12, "compiler/warning", diagnostics.SeverityWarning},
+		{`C:\ci\work\src\win.c`, 12, "compiler/C4996", diagnostics.SeverityWarning},
+		{`src\win.c`, 20, "compiler/C2065", diagnostics.SeverityError},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("Expected %d findings, got %d: %+v", len(want), len(findings), findings)
+	}
+	for i, finding := range findings {
+		if finding.File != want[i].file || finding.Line != want[i].line || finding.Rule != want[i].rule || finding.Severity != want[i].sev {
+			t.Errorf("Finding %d: expected %+v, got %+v", i, want[i], finding)
+		}
+	}
+	if findings[5].Message != "'strcpy': This function may be unsafe." {
+		t.Errorf("Expected the project suffix stripped from the msvc message, got %q", findings[5].Message)
+	}
+
+	gccOnly, _ := Parse(strings.NewReader(log), "gcc")
+	if len(gccOnly) != 5 {
+		t.Errorf("Expected 5 gcc findings, got %d", len(gccOnly))
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "src", "util.h"), []byte("int x;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	header := diagnostics.Diagnostic{File: "/ci/work/src/util.h", Line: 7, Column: 1, Rule: "compiler/format", Message: "format"}
+	system := diagnostics.Diagnostic{File: "/usr/include/stdio.h", Line: 3, Rule: "compiler/warning", Message: "deprecated"}
+	findings := []diagnostics.Diagnostic{header, header, system}
+
+	normalized := Normalize(findings, utils.NewPathResolver(root), false)
+	if len(normalized) != 1 || normalized[0].File != filepath.Join("src", "util.h") {
+		t.Errorf("Expected the header warning once under src, got %+v", normalized)
+	}
+	if all := Normalize(findings, utils.NewPathResolver(root), true); len(all) != 2 {
+		t.Errorf("Expected the system header kept with external, got %+v", all)
+	}
+
+	history := filepath.Join(root, "history.json")
+	date := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if previous, err := appendHistory(history, Count(normalized, date)); err != nil || previous != nil {
+		t.Fatalf("Expected a new history, got %v, %v", previous, err)
+	}
+	previous, err := appendHistory(history, Count(nil, date.AddDate(0, 0, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous == nil || previous.Total != 1 || previous.Rules["compiler/format"] != 1 {
+		t.Errorf("Expected the first entry back, got %+v", previous)
+	}
+}
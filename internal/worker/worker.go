@@ -0,0 +1,141 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/progress"
+	"golang.org/x/sync/semaphore"
+)
+
+// ErrTimeout reports a run that reached its deadline before processing
+// every file.
+var ErrTimeout = errors.New("run timed out")
+
+var (
+	deadline    time.Time
+	fileTimeout time.Duration
+	timedOut    atomic.Bool
+)
+
+// SetTimeouts bounds the runs that follow: all of them together to run from
+// now, and each file to file. Zero disables a bound.
+func SetTimeouts(run, file time.Duration) {
+	deadline = time.Time{}
+	if run > 0 {
+		deadline = time.Now().Add(run)
+	}
+	fileTimeout = file
+	timedOut.Store(false)
+}
+
+// TimedOut reports whether a run reached the deadline SetTimeouts set.
+func TimedOut() bool {
+	return timedOut.Load()
+}
+
+// Run calls fn for every file on at most jobs goroutines, reporting each file
+// to reporter once fn returns, and returns the result of each file at its
+// index. Files that fail leave the zero value.
+//
+// Files that exceed the file timeout, or are still running or waiting when
+// the run deadline passes, are recorded as skipped in failures and keep the
+// zero value. fn receives a context canceled when its file times out. Go
+// cannot interrupt fn, so a call that ignores it is left running in the
+// background until gop exits, and its result is dropped.
+func Run[T any](files []string, jobs int, reporter *progress.Reporter, fn func(ctx context.Context, idx int, filePath string) T) []T {
+	results := make([]T, len(files))
+	Each(files, jobs, reporter, fn, func(idx int, result T) {
+		results[idx] = result
+	})
+	return results
+}
+
+// Each is Run for callers handling the results as they come, such as
+// streaming output. emit is called with the result of every file that
+// finished in time, one call at a time, and never after Each returns.
+func Each[T any](files []string, jobs int, reporter *progress.Reporter, fn func(ctx context.Context, idx int, filePath string) T, emit func(idx int, result T)) {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	ctx := context.Background()
+	if !deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+
+	sem := semaphore.NewWeighted(int64(jobs))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for i, file := range files {
+		wg.Add(1)
+		go func(idx int, filePath string) {
+			defer wg.Done()
+			defer reporter.Add(filePath)
+			if err := sem.Acquire(ctx, 1); err != nil {
+				skipTimedOut(filePath)
+				return
+			}
+			defer sem.Release(1)
+
+			if result, ok := call(ctx, idx, filePath, fn); ok {
+				mu.Lock()
+				emit(idx, result)
+				mu.Unlock()
+			}
+		}(i, file)
+	}
+
+	wg.Wait()
+	reporter.Finish()
+}
+
+// call runs fn and returns its result, or false when the file timed out.
+// The result of a late call goes to a buffered channel nobody reads, so it
+// never reaches the caller.
+func call[T any](ctx context.Context, idx int, filePath string, fn func(ctx context.Context, idx int, filePath string) T) (T, bool) {
+	var zero T
+	if ctx.Err() != nil {
+		skipTimedOut(filePath)
+		return zero, false
+	}
+	if fileTimeout <= 0 && deadline.IsZero() {
+		return fn(ctx, idx, filePath), true
+	}
+
+	fileCtx, cancel := context.WithCancel(ctx)
+	if fileTimeout > 0 {
+		fileCtx, cancel = context.WithTimeout(ctx, fileTimeout)
+	}
+	defer cancel()
+
+	done := make(chan T, 1)
+	go func() {
+		done <- fn(fileCtx, idx, filePath)
+	}()
+
+	select {
+	case result := <-done:
+		return result, true
+	case <-fileCtx.Done():
+		if ctx.Err() != nil {
+			skipTimedOut(filePath)
+		} else {
+			failures.Record(filePath, fmt.Errorf("timed out after %s", fileTimeout))
+		}
+		return zero, false
+	}
+}
+
+func skipTimedOut(filePath string) {
+	timedOut.Store(true)
+	failures.Record(filePath, ErrTimeout)
+}
@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vitruves/gop/internal/failures"
+	"github.com/vitruves/gop/internal/progress"
+)
+
+func TestRunTimeouts(t *testing.T) {
+	defer SetTimeouts(0, 0)
+	defer failures.Reset()
+
+	failures.Reset()
+	SetTimeouts(0, 50*time.Millisecond)
+	var processed atomic.Int32
+	Run([]string{"a.c", "slow.c", "b.c"}, 2, progress.New("", 3, true), func(ctx context.Context, idx int, filePath string) bool {
+		if filePath == "slow.c" {
+			time.Sleep(300 * time.Millisecond)
+		}
+		processed.Add(1)
+		return true
+	})
+	skipped := failures.Failures()
+	if processed.Load() != 2 || len(skipped) != 1 || skipped[0].File != "slow.c" || !strings.Contains(skipped[0].Error, "timed out after 50ms") {
+		t.Errorf("Expected only slow.c to time out, processed %d, skipped %+v", processed.Load(), skipped)
+	}
+	if TimedOut() {
+		t.Error("Expected a file timeout not to time the run out")
+	}
+
+	failures.Reset()
+	SetTimeouts(100*time.Millisecond, 0)
+	files := []string{"1.c", "2.c", "3.c", "4.c", "5.c", "6.c"}
+	Run(files, 1, progress.New("", len(files), true), func(ctx context.Context, idx int, filePath string) bool {
+		time.Sleep(40 * time.Millisecond)
+		return true
+	})
+	skipped = failures.Failures()
+	if !TimedOut() || len(skipped) == 0 || len(skipped) == len(files) || skipped[0].Error != ErrTimeout.Error() {
+		t.Errorf("Expected the run to time out after a few files, skipped %+v", skipped)
+	}
+}
+
+// Run with -race: a call that ignores its context keeps running after Run
+// returns, and its result must not reach the caller.
+func TestRunDropsLateResults(t *testing.T) {
+	defer SetTimeouts(0, 0)
+	defer failures.Reset()
+
+	failures.Reset()
+	SetTimeouts(0, 50*time.Millisecond)
+	finished := make(chan struct{})
+	var canceled atomic.Bool
+	files := []string{"a.c", "slow.c", "b.c"}
+	results := Run(files, 3, progress.New("", len(files), true), func(ctx context.Context, idx int, filePath string) []string {
+		if filePath == "slow.c" {
+			defer close(finished)
+			<-ctx.Done()
+			canceled.Store(true)
+			time.Sleep(100 * time.Millisecond)
+		}
+		return []string{filePath}
+	})
+	<-finished
+
+	if !canceled.Load() {
+		t.Error("Expected the context of the slow call to be canceled")
+	}
+	if len(results) != 3 || len(results[0]) != 1 || results[1] != nil || len(results[2]) != 1 {
+		t.Errorf("Expected the timed-out file to have no result, got %v", results)
+	}
+
+	var emitted []int
+	Each(files, 3, progress.New("", len(files), true), func(ctx context.Context, idx int, filePath string) int {
+		if filePath == "slow.c" {
+			time.Sleep(150 * time.Millisecond)
+		}
+		return idx
+	}, func(idx int, result int) {
+		emitted = append(emitted, result)
+	})
+	time.Sleep(200 * time.Millisecond)
+	if len(emitted) != 2 {
+		t.Errorf("Expected only the files done in time to be emitted, got %v", emitted)
+	}
+}